@@ -3,19 +3,127 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
 
 type Config struct {
-	Environment string
-	Name        string
-	Version     string
-	HTTP        HTTPConfig
-	Postgres    PostgresConfig
-	JWT         JWTConfig
-	S3          S3Config
-	CORS        CORSConfig
+	Environment   string
+	Name          string
+	Version       string
+	HTTP          HTTPConfig
+	Postgres      PostgresConfig
+	JWT           JWTConfig
+	S3            S3Config
+	CORS          CORSConfig
+	Tracing       TracingConfig
+	Cache         CacheConfig
+	WebSocket     WebSocketConfig
+	API           APIConfig
+	RateLimit     RateLimitConfig
+	Calendar      ExternalCalendarConfig
+	Billing       BillingConfig
+	Webhook       WebhookConfig
+	ReviewMedia   ReviewMediaConfig
+	Appointment   AppointmentConfig
+	Invite        InviteConfig
+	PasswordReset PasswordResetConfig
+}
+
+// InviteConfig приглашения пользователей, которых администратор создает без пароля
+type InviteConfig struct {
+	// TTL срок действия ссылки из приглашения
+	TTL time.Duration
+	// AcceptURL страница фронтенда, где пользователь задает пароль; токен добавляется параметром token.
+	// Если пустой, в уведомление попадает только сам токен
+	AcceptURL string
+}
+
+// PasswordResetConfig восстановление пароля по ссылке из письма
+type PasswordResetConfig struct {
+	// TTL срок действия ссылки для восстановления пароля
+	TTL time.Duration
+	// ResetURL страница фронтенда, где пользователь задает новый пароль; токен добавляется параметром token.
+	// Если пустой, в уведомление попадает только сам токен
+	ResetURL string
+}
+
+// AppointmentConfig ограничения на запись клиентов к специалистам и ссылки в уведомлениях о записях
+type AppointmentConfig struct {
+	// MaxActivePerClient максимальное число активных (ожидающих и оплаченных) записей одного клиента
+	MaxActivePerClient int
+	// PublicURL внешний адрес API для ссылок в уведомлениях (например, на .ics файл записи);
+	// если пустой, в уведомление попадает относительная ссылка
+	PublicURL string
+	// AppURL адрес фронтенда для ссылок на страницу записи в уведомлениях; если пустой, ссылка относительная
+	AppURL string
+	// ReviewRequestDelay через сколько после завершения записи клиенту приходит просьба оставить отзыв
+	ReviewRequestDelay time.Duration
+	// SecondaryWindow сколько после последней завершенной консультации следующая считается повторной;
+	// 0 снимает ограничение по времени
+	SecondaryWindow time.Duration
+}
+
+// ReviewMediaConfig ограничивает изображения, прикладываемые к отзывам
+type ReviewMediaConfig struct {
+	// MaxAttachments максимальное число изображений в одном отзыве
+	MaxAttachments int
+	// MaxFileSize максимальный размер одного изображения в байтах
+	MaxFileSize int64
+}
+
+// WebhookConfig управляет отправкой событий записей во внешние системы (CRM, аналитика)
+type WebhookConfig struct {
+	// URLs адреса, на которые отправляется каждое событие; пустой список отключает отправку
+	URLs []string
+	// Secret ключ HMAC-SHA256 подписи тела запроса
+	Secret       string
+	Timeout      time.Duration
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts число попыток доставки, после которого событие помечается неотправленным
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// BillingConfig параметры счетов за консультации
+type BillingConfig struct {
+	// Currency код валюты цен специалистов (ISO 4217)
+	Currency string
+}
+
+// ExternalCalendarConfig управляет импортом занятости из ICS-календарей специалистов
+type ExternalCalendarConfig struct {
+	RefreshInterval time.Duration
+	FetchTimeout    time.Duration
+	MaxFeedBytes    int64
+	HorizonDays     int
+	// AllowPrivateHosts разрешает загрузку календарей из локальной сети (только для разработки)
+	AllowPrivateHosts bool
+}
+
+// RateLimitConfig задает лимиты запросов к API для чтения (GET, HEAD) и записи.
+// Лимиты считаются отдельно для каждой группы маршрутов (/specialists, /reviews, ...)
+type RateLimitConfig struct {
+	Enabled bool
+	Driver  string
+	Read    RateLimit
+	Write   RateLimit
+}
+
+// RateLimit token bucket: RPS запросов в секунду с допустимым всплеском Burst
+type RateLimit struct {
+	RPS   float64
+	Burst int
+}
+
+// APIConfig управляет объявлением устаревших маршрутов API v1.
+// V1DeprecatedRoutes содержит шаблоны маршрутов без префикса (/specialists/:id) или "*"
+type APIConfig struct {
+	V1DeprecatedRoutes []string
+	V1Sunset           string
 }
 
 type HTTPConfig struct {
@@ -23,18 +131,39 @@ type HTTPConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	MaxHeaderMB  int
+	CacheMaxAge  CacheMaxAgeConfig
+	Compression  CompressionConfig
+	// TrustedProxies адреса или подсети прокси, которым доверяется X-Forwarded-For
+	TrustedProxies []string
+}
+
+type CompressionConfig struct {
+	Enabled      bool
+	Level        int
+	MinSizeBytes int
+}
+
+// CacheMaxAgeConfig задает max-age в заголовке Cache-Control для маршрутов с ETag
+type CacheMaxAgeConfig struct {
+	Specialist      time.Duration
+	Specializations time.Duration
+	ScheduleWeek    time.Duration
 }
 
 type PostgresConfig struct {
-	Host               string
-	Port               string
-	Username           string
-	Password           string
-	DBName             string
-	SSLMode            string
-	MaxConnections     int
-	MaxIdleConnections int
-	MaxLifetime        time.Duration
+	Host     string
+	Port     string
+	Username string
+	Password string
+	DBName   string
+	SSLMode  string
+	// MaxConnections и MinConnections границы размера пула подключений
+	MaxConnections int
+	MinConnections int
+	// MaxLifetime через сколько подключение закрывается и пересоздается
+	MaxLifetime time.Duration
+	// MaxIdleTime через сколько простаивающее подключение сверх MinConnections закрывается
+	MaxIdleTime time.Duration
 }
 
 type JWTConfig struct {
@@ -53,7 +182,43 @@ type S3Config struct {
 }
 
 type CORSConfig struct {
+	// AllowedOrigins источники, которым разрешены кросс-доменные запросы; сравниваются с заголовком Origin целиком
 	AllowedOrigins []string
+	// AllowAllOrigins разрешает любой источник. Включается записью "*" в списке источников
+	// или, вне release-режима gin, если список источников пуст
+	AllowAllOrigins bool
+}
+
+type CacheConfig struct {
+	Driver        string
+	TTL           time.Duration
+	MaxEntries    int
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+type WebSocketConfig struct {
+	MaxMessageSizeBytes int64
+	MaxConsecutiveDrops int
+	// MaxConnections лимит открытых соединений на сервер. Отдельного лимита на пользователя нет:
+	// у пользователя одно соединение, новое закрывает предыдущее с кодом duplicate_connection
+	MaxConnections int
+	// ReconnectGracePeriod сколько пользователь с оборвавшимся соединением считается подключенным;
+	// 0 отключает ожидание переподключения
+	ReconnectGracePeriod time.Duration
+	// ReconnectBufferSize сколько сообщений копится для пользователя, пока он переподключается
+	ReconnectBufferSize int
+	// MessageRate сколько сообщений в секунду принимается от одного соединения, MessageBurst — сколько
+	// можно отправить разом сверх этого темпа. Сообщения сверх лимита обрабатываются с задержкой; 0 отключает лимит
+	MessageRate  float64
+	MessageBurst int
+}
+
+type TracingConfig struct {
+	OTLPEndpoint string
+	Insecure     bool
+	SampleRatio  float64
 }
 
 func NewConfig() (*Config, error) {
@@ -67,7 +232,13 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
-	postgresMaxLifetime, err := time.ParseDuration(getEnv("POSTGRES_MAX_LIFETIME", "5m"))
+	// Переменные POSTGRES_* читаются для совместимости со старыми окружениями, DB_* имеют приоритет
+	postgresMaxLifetime, err := time.ParseDuration(getEnv("DB_MAX_CONN_LIFETIME", getEnv("POSTGRES_MAX_LIFETIME", "5m")))
+	if err != nil {
+		return nil, err
+	}
+
+	postgresMaxIdleTime, err := time.ParseDuration(getEnv("DB_MAX_CONN_IDLE_TIME", "15m"))
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +253,68 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	cacheTTL, err := time.ParseDuration(getEnv("CACHE_TTL", "5m"))
+	if err != nil {
+		return nil, err
+	}
+
+	calendarRefreshInterval, err := time.ParseDuration(getEnv("EXTERNAL_CALENDAR_REFRESH_INTERVAL", "1h"))
+	if err != nil {
+		return nil, err
+	}
+
+	calendarFetchTimeout, err := time.ParseDuration(getEnv("EXTERNAL_CALENDAR_FETCH_TIMEOUT", "30s"))
+	if err != nil {
+		return nil, err
+	}
+
+	webhookTimeout, err := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, err
+	}
+
+	webhookPollInterval, err := time.ParseDuration(getEnv("WEBHOOK_POLL_INTERVAL", "5s"))
+	if err != nil {
+		return nil, err
+	}
+
+	webhookInitialBackoff, err := time.ParseDuration(getEnv("WEBHOOK_RETRY_INITIAL_BACKOFF", "30s"))
+	if err != nil {
+		return nil, err
+	}
+
+	webhookMaxBackoff, err := time.ParseDuration(getEnv("WEBHOOK_RETRY_MAX_BACKOFF", "1h"))
+	if err != nil {
+		return nil, err
+	}
+
+	inviteTTL, err := time.ParseDuration(getEnv("INVITE_TTL", "168h"))
+	if err != nil {
+		return nil, err
+	}
+
+	corsAllowedOrigins := getEnvAsSlice("CORS_ALLOWED_ORIGINS", nil)
+
+	passwordResetTTL, err := time.ParseDuration(getEnv("PASSWORD_RESET_TTL", "1h"))
+	if err != nil {
+		return nil, err
+	}
+
+	wsReconnectGracePeriod, err := time.ParseDuration(getEnv("WS_RECONNECT_GRACE_PERIOD", "20s"))
+	if err != nil {
+		return nil, err
+	}
+
+	reviewRequestDelay, err := time.ParseDuration(getEnv("REVIEW_REQUEST_DELAY", "2h"))
+	if err != nil {
+		return nil, err
+	}
+
+	secondaryWindow, err := time.ParseDuration(getEnv("SECONDARY_CONSULTATION_WINDOW", "4320h"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		Environment: getEnv("APP_ENV", "development"),
 		Name:        getEnv("APP_NAME", "laps"),
@@ -91,6 +324,17 @@ func NewConfig() (*Config, error) {
 			ReadTimeout:  httpReadTimeout,
 			WriteTimeout: httpWriteTimeout,
 			MaxHeaderMB:  getEnvAsInt("HTTP_MAX_HEADER_MB", 1),
+			CacheMaxAge: CacheMaxAgeConfig{
+				Specialist:      time.Duration(getEnvAsInt("HTTP_CACHE_MAX_AGE_SPECIALIST", 60)) * time.Second,
+				Specializations: time.Duration(getEnvAsInt("HTTP_CACHE_MAX_AGE_SPECIALIZATIONS", 300)) * time.Second,
+				ScheduleWeek:    time.Duration(getEnvAsInt("HTTP_CACHE_MAX_AGE_SCHEDULE_WEEK", 30)) * time.Second,
+			},
+			Compression: CompressionConfig{
+				Enabled:      getEnv("HTTP_COMPRESSION_ENABLED", "true") == "true",
+				Level:        getEnvAsInt("HTTP_COMPRESSION_LEVEL", -1),
+				MinSizeBytes: getEnvAsInt("HTTP_COMPRESSION_MIN_SIZE_BYTES", 1024),
+			},
+			TrustedProxies: getEnvAsSlice("HTTP_TRUSTED_PROXIES", nil),
 		},
 		Postgres: PostgresConfig{
 			Host:               getEnv("POSTGRES_HOST", "localhost"),
@@ -99,9 +343,10 @@ func NewConfig() (*Config, error) {
 			Password:           getEnv("POSTGRES_PASSWORD", "postgres"),
 			DBName:             getEnv("POSTGRES_DB", "laps"),
 			SSLMode:            getEnv("POSTGRES_SSL_MODE", "disable"),
-			MaxConnections:     getEnvAsInt("POSTGRES_MAX_CONNECTIONS", 10),
-			MaxIdleConnections: getEnvAsInt("POSTGRES_MAX_IDLE_CONNECTIONS", 5),
+			MaxConnections:     getEnvAsInt("DB_MAX_CONNS", getEnvAsInt("POSTGRES_MAX_CONNECTIONS", 10)),
+			MinConnections:     getEnvAsInt("DB_MIN_CONNS", getEnvAsInt("POSTGRES_MAX_IDLE_CONNECTIONS", 5)),
 			MaxLifetime:        postgresMaxLifetime,
+			MaxIdleTime:        postgresMaxIdleTime,
 		},
 		JWT: JWTConfig{
 			SigningKey:      getEnv("JWT_SIGNING_KEY", "your_secret_key"),
@@ -117,7 +362,85 @@ func NewConfig() (*Config, error) {
 			UseSSL:          getEnv("S3_USE_SSL", "true") == "true",
 		},
 		CORS: CORSConfig{
-			AllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
+			AllowedOrigins:  corsAllowedOrigins,
+			AllowAllOrigins: slices.Contains(corsAllowedOrigins, "*") || (len(corsAllowedOrigins) == 0 && os.Getenv("GIN_MODE") != "release"),
+		},
+		Tracing: TracingConfig{
+			OTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", ""),
+			Insecure:     getEnv("TRACING_INSECURE", "false") == "true",
+			SampleRatio:  getEnvAsFloat("TRACING_SAMPLE_RATIO", 1.0),
+		},
+		Cache: CacheConfig{
+			Driver:        getEnv("CACHE_DRIVER", "memory"),
+			TTL:           cacheTTL,
+			MaxEntries:    getEnvAsInt("CACHE_MAX_ENTRIES", 1000),
+			RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),
+			RedisPassword: getEnv("REDIS_PASSWORD", ""),
+			RedisDB:       getEnvAsInt("REDIS_DB", 0),
+		},
+		API: APIConfig{
+			V1DeprecatedRoutes: getEnvAsSlice("API_V1_DEPRECATED_ROUTES", []string{}),
+			V1Sunset:           getEnv("API_V1_SUNSET", ""),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: getEnv("RATE_LIMIT_ENABLED", "true") == "true",
+			Driver:  getEnv("RATE_LIMIT_DRIVER", "memory"),
+			Read: RateLimit{
+				RPS:   getEnvAsFloat("RATE_LIMIT_READ_RPS", 10),
+				Burst: getEnvAsInt("RATE_LIMIT_READ_BURST", 20),
+			},
+			Write: RateLimit{
+				RPS:   getEnvAsFloat("RATE_LIMIT_WRITE_RPS", 2),
+				Burst: getEnvAsInt("RATE_LIMIT_WRITE_BURST", 5),
+			},
+		},
+		Calendar: ExternalCalendarConfig{
+			RefreshInterval:   calendarRefreshInterval,
+			FetchTimeout:      calendarFetchTimeout,
+			MaxFeedBytes:      int64(getEnvAsInt("EXTERNAL_CALENDAR_MAX_FEED_BYTES", 5*1024*1024)),
+			HorizonDays:       getEnvAsInt("EXTERNAL_CALENDAR_HORIZON_DAYS", 90),
+			AllowPrivateHosts: getEnv("EXTERNAL_CALENDAR_ALLOW_PRIVATE_HOSTS", "false") == "true",
+		},
+		Billing: BillingConfig{
+			Currency: getEnv("BILLING_CURRENCY", "RUB"),
+		},
+		Webhook: WebhookConfig{
+			URLs:           getEnvAsSlice("WEBHOOK_URLS", nil),
+			Secret:         getEnv("WEBHOOK_SECRET", ""),
+			Timeout:        webhookTimeout,
+			PollInterval:   webhookPollInterval,
+			BatchSize:      getEnvAsInt("WEBHOOK_BATCH_SIZE", 50),
+			MaxAttempts:    getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 10),
+			InitialBackoff: webhookInitialBackoff,
+			MaxBackoff:     webhookMaxBackoff,
+		},
+		Appointment: AppointmentConfig{
+			MaxActivePerClient: getEnvAsInt("MAX_ACTIVE_APPOINTMENTS_PER_CLIENT", 10),
+			PublicURL:          getEnv("PUBLIC_API_URL", ""),
+			AppURL:             getEnv("APP_URL", ""),
+			ReviewRequestDelay: reviewRequestDelay,
+			SecondaryWindow:    secondaryWindow,
+		},
+		Invite: InviteConfig{
+			TTL:       inviteTTL,
+			AcceptURL: getEnv("INVITE_ACCEPT_URL", ""),
+		},
+		PasswordReset: PasswordResetConfig{
+			TTL:      passwordResetTTL,
+			ResetURL: getEnv("PASSWORD_RESET_URL", ""),
+		},
+		ReviewMedia: ReviewMediaConfig{
+			MaxAttachments: getEnvAsInt("REVIEW_MEDIA_MAX_ATTACHMENTS", 3),
+			MaxFileSize:    int64(getEnvAsInt("REVIEW_MEDIA_MAX_FILE_BYTES", 5*1024*1024)),
+		},
+		WebSocket: WebSocketConfig{
+			MaxMessageSizeBytes:  int64(getEnvAsInt("WS_MAX_MESSAGE_SIZE_BYTES", 10*1024*1024)),
+			MaxConsecutiveDrops:  getEnvAsInt("WS_MAX_CONSECUTIVE_DROPS", 3),
+			MaxConnections:       getEnvAsInt("WS_MAX_CONNECTIONS", 10000),
+			ReconnectGracePeriod: wsReconnectGracePeriod,
+			ReconnectBufferSize:  getEnvAsInt("WS_RECONNECT_BUFFER_SIZE", 50),
+			MessageRate:          getEnvAsFloat("WS_MESSAGE_RATE", 10),
+			MessageBurst:         getEnvAsInt("WS_MESSAGE_BURST", 10),
 		},
 	}, nil
 }
@@ -158,4 +481,19 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 
 	return value
-}
\ No newline at end of file
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value := 0.0
+	_, err := fmt.Sscanf(valueStr, "%g", &value)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"laps/config"
+)
+
+// Cache хранит сериализованные значения по ключу с ограниченным временем жизни
+type Cache interface {
+	// Get загружает значение в dest и сообщает, было ли оно найдено
+	Get(ctx context.Context, key string, dest interface{}) (bool, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// DeletePrefix удаляет все ключи, начинающиеся с prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// Pinger реализуют кэши с внешним хранилищем, доступность которого стоит проверять
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+var (
+	hits   = expvar.NewMap("cache_hits")
+	misses = expvar.NewMap("cache_misses")
+)
+
+// namespace возвращает первую часть ключа (до ":"), по которой группируются метрики
+func namespace(key string) string {
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+func recordLookup(key string, found bool) {
+	if found {
+		hits.Add(namespace(key), 1)
+		return
+	}
+	misses.Add(namespace(key), 1)
+}
+
+// New создает реализацию кэша, выбранную в конфигурации ("memory" или "redis")
+func New(cfg config.CacheConfig) (Cache, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return NewMemoryCache(cfg.MaxEntries), nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			return nil, fmt.Errorf("не удалось подключиться к redis: %w", err)
+		}
+		return NewRedisCache(client, "laps:"), nil
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер кэша: %s", cfg.Driver)
+	}
+}
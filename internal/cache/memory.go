@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache - LRU кэш в памяти процесса с TTL на каждую запись
+type MemoryCache struct {
+	maxEntries int
+	mu         sync.Mutex
+	order      *list.List
+	items      map[string]*list.Element
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	c.mu.Lock()
+	elem, ok := c.items[key]
+	if ok && time.Now().After(elem.Value.(*memoryEntry).expiresAt) {
+		c.removeElement(elem)
+		ok = false
+	}
+	var value []byte
+	if ok {
+		c.order.MoveToFront(elem)
+		value = elem.Value.(*memoryEntry).value
+	}
+	c.mu.Unlock()
+
+	recordLookup(key, ok)
+	if !ok {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(value, dest); err != nil {
+		return false, fmt.Errorf("ошибка десериализации значения кэша: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации значения кэша: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: data, expiresAt: time.Now().Add(ttl)}
+	if elem, ok := c.items[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+
+	return nil
+}
+
+func (c *MemoryCache) DeletePrefix(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(elem)
+		}
+	}
+
+	return nil
+}
+
+func (c *MemoryCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*memoryEntry).key)
+}
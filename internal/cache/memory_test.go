@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheExpires(t *testing.T) {
+	c := NewMemoryCache(10)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "short", 1, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "long", 2, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	var value int
+	if found, _ := c.Get(ctx, "short", &value); found {
+		t.Error("expired entry is still returned")
+	}
+	if found, _ := c.Get(ctx, "long", &value); !found || value != 2 {
+		t.Errorf("Get(long) = %d, %v; want 2, true", value, found)
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryCache(2)
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "a", 1, time.Minute)
+	_ = c.Set(ctx, "b", 2, time.Minute)
+	var value int
+	// Чтение делает "a" свежее "b", поэтому вытесняется "b"
+	_, _ = c.Get(ctx, "a", &value)
+	_ = c.Set(ctx, "c", 3, time.Minute)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if found, _ := c.Get(ctx, key, &value); found != want {
+			t.Errorf("Get(%s) found = %v, want %v", key, found, want)
+		}
+	}
+}
+
+func TestMemoryCacheDeletePrefix(t *testing.T) {
+	c := NewMemoryCache(10)
+	ctx := context.Background()
+
+	for _, key := range []string{"specialists:list:limit=10", "specialists:list:limit=20", "specializations:tree"} {
+		_ = c.Set(ctx, key, key, time.Minute)
+	}
+	if err := c.DeletePrefix(ctx, "specialists:"); err != nil {
+		t.Fatal(err)
+	}
+
+	var value string
+	for key, want := range map[string]bool{
+		"specialists:list:limit=10": false,
+		"specialists:list:limit=20": false,
+		"specializations:tree":      true,
+	} {
+		if found, _ := c.Get(ctx, key, &value); found != want {
+			t.Errorf("Get(%s) found = %v, want %v", key, found, want)
+		}
+	}
+}
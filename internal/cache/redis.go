@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache - кэш, разделяемый между несколькими экземплярами приложения
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisCache(client *redis.Client, prefix string) *RedisCache {
+	return &RedisCache{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		recordLookup(key, false)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("ошибка чтения из redis: %w", err)
+	}
+
+	recordLookup(key, true)
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, fmt.Errorf("ошибка десериализации значения кэша: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации значения кэша: %w", err)
+	}
+
+	if err := c.client.Set(ctx, c.prefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("ошибка записи в redis: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := c.client.Scan(ctx, 0, c.prefix+prefix+"*", 100).Iterator()
+
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("ошибка поиска ключей в redis: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("ошибка удаления ключей из redis: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) Ping(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis недоступен: %w", err)
+	}
+	return nil
+}
@@ -9,6 +9,8 @@ type AppointmentStatus string
 const (
 	AppointmentStatusPending   AppointmentStatus = "pending"
 	AppointmentStatusPaid      AppointmentStatus = "paid"
+	// AppointmentStatusInProgress консультация начата специалистом; в этот статус запись переходит только через Start
+	AppointmentStatusInProgress AppointmentStatus = "in_progress"
 	AppointmentStatusCompleted AppointmentStatus = "completed"
 	AppointmentStatusCancelled AppointmentStatus = "cancelled"
 )
@@ -41,24 +43,186 @@ type Appointment struct {
 	CommunicationMethod CommunicationMethod `json:"communication_method"`
 	CreatedAt           time.Time           `json:"created_at"`
 	UpdatedAt           time.Time           `json:"updated_at"`
+	// CallDurationSeconds суммарная длительность звонков по записи
+	CallDurationSeconds int                 `json:"call_duration_seconds"`
+	// CheckedInAt время, когда клиент отметился, что готов к консультации
+	CheckedInAt *time.Time `json:"checked_in_at"`
+	// ActualStartAt и ActualEndAt фактические начало и окончание консультации
+	ActualStartAt *time.Time `json:"actual_start_at"`
+	ActualEndAt   *time.Time `json:"actual_end_at"`
 	ClientName          string              `json:"client_name,omitempty"`
 	ClientPhone         string              `json:"client_phone,omitempty"`
 	SpecialistName      string              `json:"specialist_name,omitempty"`
 	SpecialistPhone     string              `json:"specialist_phone,omitempty"`
+	SpecialistPhotoURL  string              `json:"specialist_photo_url,omitempty"`
+	// SpecialistRemoved профиль специалиста удален; имя остается, чтобы запись отображалась в истории
+	SpecialistRemoved bool `json:"specialist_removed"`
+	ChatSessionID       *int64              `json:"chat_session_id,omitempty"`
+}
+
+type PaymentStatus string
+
+const (
+	PaymentStatusUnpaid PaymentStatus = "unpaid"
+	PaymentStatusPaid   PaymentStatus = "paid"
+)
+
+// AppointmentDetails подробный ответ по записи: имена участников, цена, оплата и связанный чат
+type AppointmentDetails struct {
+	Appointment
+	PaymentStatus PaymentStatus `json:"payment_status"`
+	// ClientProfile заполняется только для специалиста записи
+	ClientProfile *ClientProfile `json:"client_profile,omitempty"`
+}
+
+// SupportsCalls сообщает, предполагает ли способ связи звонок внутри приложения
+func (m CommunicationMethod) SupportsCalls() bool {
+	return m == CommunicationMethodVideoCall
+}
+
+// Окно, в котором по записи можно начать звонок: за 15 минут до начала и до 2 часов после
+const (
+	CallWindowBefore = 15 * time.Minute
+	CallWindowAfter  = 2 * time.Hour
+)
+
+// CheckInWindowBefore за сколько до начала записи клиент может отметиться, а специалист — начать консультацию
+const CheckInWindowBefore = 15 * time.Minute
+
+// Причины, по которым звонок по записи недоступен
+const (
+	CallNotAllowedCommunicationMethod = "communication_method"
+	CallNotAllowedStatus              = "status"
+	CallNotAllowedTooEarly            = "too_early"
+	CallNotAllowedTooLate             = "too_late"
+	// Звонок не привязан к чату записи или чат недоступен пользователю
+	CallNotAllowedNoAppointment = "appointment_not_found"
+	// Участники звонка не являются клиентом и специалистом записи
+	CallNotAllowedParticipants = "participant_mismatch"
+	// Профиль специалиста записи удален
+	CallNotAllowedSpecialistUnavailable = "specialist_unavailable"
+	// Запись не удалось проверить (например, база недоступна); звонок отклоняется
+	CallNotAllowedCheckFailed = "check_failed"
+)
+
+// MinCompletedCallDuration минимальная длительность звонка, после которой консультация считается состоявшейся
+const MinCompletedCallDuration = time.Minute
+
+// CallNotAllowedReason возвращает причину, по которой звонок по записи сейчас недоступен, или пустую строку.
+// Звонок возможен только для подтвержденной (оплаченной) или начатой записи со способом связи video_call и в окне вокруг ее начала
+func (a Appointment) CallNotAllowedReason(now time.Time) string {
+	if !a.CommunicationMethod.SupportsCalls() {
+		return CallNotAllowedCommunicationMethod
+	}
+	if a.Status != AppointmentStatusPaid && a.Status != AppointmentStatusInProgress {
+		return CallNotAllowedStatus
+	}
+	if now.Before(a.AppointmentDate.Add(-CallWindowBefore)) {
+		return CallNotAllowedTooEarly
+	}
+	if now.After(a.AppointmentDate.Add(CallWindowAfter)) {
+		return CallNotAllowedTooLate
+	}
+	return ""
+}
+
+// PaymentStatus считает запись оплаченной, если есть платеж или статус paid/in_progress/completed
+func (a Appointment) PaymentStatus() PaymentStatus {
+	if a.PaymentID != nil || a.Status == AppointmentStatusPaid || a.Status == AppointmentStatusInProgress ||
+		a.Status == AppointmentStatusCompleted {
+		return PaymentStatusPaid
+	}
+	return PaymentStatusUnpaid
+}
+
+// AppointmentInvoice счет за консультацию
+type AppointmentInvoice struct {
+	AppointmentID  int64         `json:"appointment_id"`
+	ClientName     string        `json:"client_name"`
+	SpecialistName string        `json:"specialist_name"`
+	Service        string        `json:"service"`
+	Price          float64       `json:"price"`
+	Currency       string        `json:"currency"`
+	Status         PaymentStatus `json:"status"`
+}
+
+// Invoice формирует счет за консультацию в указанной валюте
+func (a Appointment) Invoice(currency string) AppointmentInvoice {
+	service := "Первичная консультация"
+	if a.ConsultationType == ConsultationTypeSecondary {
+		service = "Повторная консультация"
+	}
+
+	return AppointmentInvoice{
+		AppointmentID:  a.ID,
+		ClientName:     a.ClientName,
+		SpecialistName: a.SpecialistName,
+		Service:        service,
+		Price:          a.Price,
+		Currency:       currency,
+		Status:         a.PaymentStatus(),
+	}
+}
+
+// AppointmentExportFilter выбирает записи для выгрузки порциями по возрастанию (appointment_date, id).
+// AfterDate и AfterID задают позицию после последней выгруженной записи
+type AppointmentExportFilter struct {
+	SpecialistID *int64
+	From         time.Time
+	To           time.Time
+	AfterDate    *time.Time
+	AfterID      int64
+	Limit        int
 }
 
 type CreateAppointmentDTO struct {
 	SpecialistID        int64               `json:"specialist_id" binding:"required"`
-	ConsultationType    ConsultationType    `json:"consultation_type" binding:"required,oneof=primary secondary"`
+	ConsultationType    ConsultationType    `json:"consultation_type" binding:"omitempty,oneof=primary secondary"`
 	SpecializationID    *int64              `json:"specialization_id"`
 	AppointmentDate     time.Time           `json:"appointment_date" binding:"required"`
 	CommunicationMethod CommunicationMethod `json:"communication_method" binding:"required,oneof=phone whatsapp video_call"`
+	// HoldID или ReservationToken превращают удержание слота в запись; время и специалист
+	// должны совпадать с удержанием
+	HoldID           *int64  `json:"hold_id"`
+	ReservationToken *string `json:"reservation_token"`
 }
 
 type UpdateAppointmentDTO struct {
 	Status          *AppointmentStatus `json:"status" binding:"omitempty,oneof=pending paid completed cancelled"`
 	AppointmentDate *time.Time         `json:"appointment_date"`
 	PaymentID       *string            `json:"payment_id"`
+	// CancelledBy роль инициатора отмены, заполняется сервисом
+	CancelledBy *UserRole `json:"-"`
+	// ExpectedUpdatedAt updated_at записи, которую видел клиент; если запись с тех пор изменили, обновление отклоняется
+	ExpectedUpdatedAt *time.Time `json:"expected_updated_at"`
+}
+
+// AppointmentConfirmation ответ на подтверждение (оплату) записи с ID чата, созданного для записи
+type AppointmentConfirmation struct {
+	ChatSessionID int64 `json:"chat_session_id"`
+}
+
+// ConsultationTypeCheck тип консультации клиента у специалиста и его основание. Для повторной консультации
+// BasisAppointmentID указывает на последнюю завершенную запись, а WindowEndsAt — до какого момента консультация
+// остается повторной (nil, если срок не ограничен). Для первичной оба поля пустые
+type ConsultationTypeCheck struct {
+	Type               ConsultationType `json:"consultation_type"`
+	BasisAppointmentID *int64           `json:"basis_appointment_id"`
+	WindowEndsAt       *time.Time       `json:"window_ends_at"`
+}
+
+// TransferAppointmentDTO передача записи другому специалисту
+type TransferAppointmentDTO struct {
+	SpecialistID int64 `json:"specialist_id" binding:"required,gt=0"`
+}
+
+// CancelAppointmentRangeDTO отмена всех записей специалиста в диапазоне дат (включительно).
+// SpecialistID учитывается только для администратора
+type CancelAppointmentRangeDTO struct {
+	From         string `json:"from" binding:"required"`
+	To           string `json:"to" binding:"required"`
+	SpecialistID *int64 `json:"specialist_id,omitempty"`
+	Reason       string `json:"reason,omitempty"`
 }
 
 type AppointmentFilter struct {
@@ -70,4 +234,16 @@ type AppointmentFilter struct {
 	EndDate       *time.Time         `json:"end_date"`
 	Limit         int                `json:"limit"`
 	Offset        int                `json:"offset"`
+
+	// Statuses выбирает записи с любым из перечисленных статусов
+	Statuses []AppointmentStatus `json:"statuses"`
+
+	// Search подстроки ФИО через пробел: запись подходит, если все слова есть в ФИО клиента или все в ФИО специалиста
+	Search string `json:"search"`
+
+	// PendingReview выбирает записи без отзыва клиента, о которых еще можно оставить отзыв: специалист не удален
+	PendingReview bool `json:"pending_review"`
+
+	// SortAscending сортирует записи от ранних к поздним; по умолчанию сначала идут поздние
+	SortAscending bool `json:"sort_ascending"`
 }
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionSpecialistVerify    AuditAction = "specialist.verify"
+	AuditActionUserDataExport      AuditAction = "user.data_export"
+	AuditActionUserAnonymize       AuditAction = "user.anonymize"
+	AuditActionUserInvite          AuditAction = "user.invite"
+	AuditActionUserRoleChange      AuditAction = "user.role_change"
+	AuditActionAppointmentTransfer AuditAction = "appointment.transfer"
+)
+
+type AuditEntityType string
+
+const (
+	AuditEntitySpecialist  AuditEntityType = "specialist"
+	AuditEntityUser        AuditEntityType = "user"
+	AuditEntityAppointment AuditEntityType = "appointment"
+)
+
+// AuditEntry запись журнала аудита об изменении сущности; OldValue и NewValue хранятся как JSON.
+// ActorID равен null, если пользователь, внесший изменение, удален
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	ActorID    *int64          `json:"actor_id"`
+	Action     AuditAction     `json:"action"`
+	EntityType AuditEntityType `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	OldValue   json.RawMessage `json:"old_value,omitempty" swaggertype:"object"`
+	NewValue   json.RawMessage `json:"new_value,omitempty" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditFilter struct {
+	EntityType *AuditEntityType `json:"entity_type"`
+	EntityID   *int64           `json:"entity_id"`
+	ActorID    *int64           `json:"actor_id"`
+	Action     *AuditAction     `json:"action"`
+	StartDate  *time.Time       `json:"start_date"`
+	EndDate    *time.Time       `json:"end_date"`
+	Limit      int              `json:"limit"`
+	Offset     int              `json:"offset"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// BlockedClient клиент, которому специалист запретил записываться, писать в чат и звонить.
+// Причина видна только специалисту
+type BlockedClient struct {
+	ID           int64     `json:"id"`
+	SpecialistID int64     `json:"specialist_id"`
+	ClientID     int64     `json:"client_id"`
+	ClientName   string    `json:"client_name"`
+	Reason       *string   `json:"reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type BlockClientDTO struct {
+	ClientID int64  `json:"client_id" binding:"required"`
+	Reason   string `json:"reason"`
+}
@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// MaxCallFeedbackCommentLength максимальная длина комментария к оценке звонка
+const MaxCallFeedbackCommentLength = 1000
+
+// CallRecord завершенный звонок, сохраненный из сессии сигналинга. SpecialistUserID — ID пользователя
+// специалиста, как в сессии сигналинга. Сохраняются и неотвеченные звонки: по ним считается доля
+// звонков клиентов, принятых специалистом. CallerID равен 0 у звонков, сохраненных до учета звонившего
+type CallRecord struct {
+	ID               string    `json:"id"`
+	ClientID         int64     `json:"client_id"`
+	SpecialistUserID int64     `json:"specialist_user_id"`
+	CallerID         int64     `json:"caller_id,omitempty"`
+	AppointmentID    *int64    `json:"appointment_id,omitempty"`
+	Answered         bool      `json:"answered"`
+	StartedAt        time.Time `json:"started_at"`
+	EndedAt          time.Time `json:"ended_at"`
+	DurationSeconds  int       `json:"duration_seconds"`
+}
+
+// HasParticipant сообщает, участвовал ли пользователь в звонке
+func (c *CallRecord) HasParticipant(userID int64) bool {
+	return c.ClientID == userID || c.SpecialistUserID == userID
+}
+
+// CallIssue проблема со связью, отмеченная участником звонка
+type CallIssue string
+
+const (
+	CallIssueAudio      CallIssue = "audio"
+	CallIssueVideo      CallIssue = "video"
+	CallIssueDropped    CallIssue = "dropped"
+	CallIssueLatency    CallIssue = "latency"
+	CallIssueEcho       CallIssue = "echo"
+	CallIssueConnecting CallIssue = "connecting"
+)
+
+func (i CallIssue) IsValid() bool {
+	switch i {
+	case CallIssueAudio, CallIssueVideo, CallIssueDropped, CallIssueLatency, CallIssueEcho, CallIssueConnecting:
+		return true
+	}
+	return false
+}
+
+// CallFeedback оценка качества звонка участником
+type CallFeedback struct {
+	ID            int64       `json:"id"`
+	CallSessionID string      `json:"call_session_id"`
+	UserID        int64       `json:"user_id"`
+	Quality       int         `json:"quality"`
+	Issues        []CallIssue `json:"issues"`
+	Comment       *string     `json:"comment,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// CallFeedbackDTO оценка качества звонка от 1 до 5, отмеченные проблемы со связью и комментарий
+type CallFeedbackDTO struct {
+	Quality int         `json:"quality" binding:"required,min=1,max=5"`
+	Issues  []CallIssue `json:"issues"`
+	Comment string      `json:"comment"`
+}
+
+// CallFeedbackSummary сводка оценок звонков за период для наблюдения за качеством WebRTC.
+// QualityCounts — число оценок по баллам, IssueCounts — сколько раз отмечена каждая проблема
+type CallFeedbackSummary struct {
+	From           time.Time         `json:"from"`
+	To             time.Time         `json:"to"`
+	Total          int               `json:"total"`
+	AverageQuality float64           `json:"average_quality"`
+	QualityCounts  map[int]int       `json:"quality_counts"`
+	IssueCounts    map[CallIssue]int `json:"issue_counts"`
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"regexp"
 	"time"
 )
 
@@ -62,6 +63,33 @@ type ChatMessage struct {
 	// Optional fields populated by joins
 	SenderName  *string `json:"sender_name,omitempty" db:"sender_name"`
 	SenderRole  *string `json:"sender_role,omitempty" db:"sender_role"`
+
+	// Reactions counts reactions on the message by emoji
+	Reactions map[string]int `json:"reactions,omitempty"`
+}
+
+// emojiComponent matches one pictographic code point with an optional
+// variation selector or skin tone modifier
+const emojiComponent = `[\x{00A9}\x{00AE}\x{203C}\x{2049}\x{2122}\x{2139}\x{2194}-\x{21AA}\x{231A}-\x{23FF}` +
+	`\x{24C2}\x{25AA}-\x{27BF}\x{2934}\x{2935}\x{2B05}-\x{2B55}\x{3030}\x{303D}\x{3297}\x{3299}` +
+	`\x{1F000}-\x{1F1E5}\x{1F200}-\x{1FAFF}][\x{FE0F}\x{1F3FB}-\x{1F3FF}]?`
+
+// singleEmojiPattern matches exactly one emoji: a flag (pair of regional
+// indicators), a keycap or a pictograph, possibly joined into a ZWJ sequence
+var singleEmojiPattern = regexp.MustCompile(`^(?:[\x{1F1E6}-\x{1F1FF}]{2}|[0-9#*]\x{FE0F}?\x{20E3}|` +
+	emojiComponent + `(?:\x{200D}` + emojiComponent + `)*)$`)
+
+// maxEmojiLength bounds the reaction in bytes so that it fits chat_message_reactions.emoji
+const maxEmojiLength = 32
+
+// IsSingleEmoji reports whether s is exactly one emoji
+func IsSingleEmoji(s string) bool {
+	return len(s) <= maxEmojiLength && singleEmojiPattern.MatchString(s)
+}
+
+// ChatReactionDTO represents a reaction to add to a chat message
+type ChatReactionDTO struct {
+	Emoji string `json:"emoji" binding:"required"`
 }
 
 // ChatParticipant represents a participant in a chat session
@@ -77,12 +105,14 @@ type ChatParticipant struct {
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
 }
 
-// CreateChatSessionDTO represents the data required to create a chat session
+// CreateChatSessionDTO represents the data required to create a chat session.
+// Over REST the participants are always taken from the appointment: ClientID and
+// SpecialistID may be omitted, and a request with IDs of other users is rejected
 type CreateChatSessionDTO struct {
 	AppointmentID    int64             `json:"appointment_id" binding:"required"`
-	ClientID         int64             `json:"client_id" binding:"required"`
-	SpecialistID     int64             `json:"specialist_id" binding:"required"`
-	SpecializationID int64             `json:"specialization_id" binding:"required"`
+	ClientID         int64             `json:"client_id,omitempty"`
+	SpecialistID     int64             `json:"specialist_id,omitempty"`
+	SpecializationID int64             `json:"specialization_id,omitempty"`
 	Status           ChatSessionStatus `json:"status,omitempty"`
 }
 
@@ -123,4 +153,34 @@ type ChatMessageFilter struct {
 	IsRead    *bool       `json:"is_read"`
 	Limit     int         `json:"limit"`
 	Offset    int         `json:"offset"`
-}
\ No newline at end of file
+}
+
+// ChatSessionStats represents message volume and participant activity of a chat session.
+// System messages are not counted
+type ChatSessionStats struct {
+	SessionID          int64 `json:"session_id"`
+	TotalMessages      int64 `json:"total_messages"`
+	ClientMessages     int64 `json:"client_messages"`
+	SpecialistMessages int64 `json:"specialist_messages"`
+	// DurationSeconds spans started_at to ended_at (or now while the session is open),
+	// falling back to the first and last message when the session was never started
+	DurationSeconds int64 `json:"duration_seconds"`
+	// AvgResponseTimeSeconds is the average delay between consecutive messages
+	// of different senders, nil until one participant has replied to the other
+	AvgResponseTimeSeconds *float64 `json:"avg_response_time_seconds"`
+}
+
+// SpecialistResponseStats represents aggregated chat responsiveness of a specialist
+type SpecialistResponseStats struct {
+	SpecialistID               int64    `json:"specialist_id"`
+	TotalSessions              int64    `json:"total_sessions"`
+	RespondedSessions          int64    `json:"responded_sessions"`
+	MedianFirstResponseSeconds *float64 `json:"median_first_response_seconds,omitempty"`
+	// RespondsWithin is a coarse SLA bucket ("15m", "1h", "3h", "24h", "over_24h"),
+	// empty until enough sessions have been answered
+	RespondsWithin string `json:"responds_within,omitempty"`
+	// CallPickupRate is the share of calls placed by clients that the specialist answered,
+	// nil until a client has called the specialist
+	CallPickupRate *float64  `json:"call_pickup_rate,omitempty"`
+	CalculatedAt   time.Time `json:"calculated_at"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "testing"
+
+func TestIsSingleEmoji(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"👍", true},
+		{"❤️", true},
+		{"👍🏽", true},
+		{"👨‍👩‍👧", true},
+		{"🇰🇿", true},
+		{"1️⃣", true},
+		{"", false},
+		{"ok", false},
+		{"👍👍", false},
+		{"👍 ", false},
+		{"a👍", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSingleEmoji(tt.s); got != tt.want {
+			t.Errorf("IsSingleEmoji(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type Gender string
+
+const (
+	GenderMale   Gender = "male"
+	GenderFemale Gender = "female"
+	GenderOther  Gender = "other"
+)
+
+// Допустимый возраст клиента по дате рождения
+const (
+	ClientMinAge = 16
+	ClientMaxAge = 120
+)
+
+// ClientProfile сведения о клиенте, полезные специалисту на приеме. Все поля необязательны;
+// BirthDate в формате YYYY-MM-DD
+type ClientProfile struct {
+	UserID                 int64                `json:"user_id"`
+	BirthDate              *string              `json:"birth_date"`
+	Gender                 *Gender              `json:"gender"`
+	City                   *string              `json:"city"`
+	PreferredContactMethod *CommunicationMethod `json:"preferred_contact_method"`
+	UpdatedAt              *time.Time           `json:"updated_at,omitempty"`
+}
+
+// UpdateClientProfileDTO полностью заменяет профиль клиента; не переданные поля очищаются
+type UpdateClientProfileDTO struct {
+	BirthDate              *string              `json:"birth_date"`
+	Gender                 *Gender              `json:"gender" binding:"omitempty,oneof=male female other"`
+	City                   *string              `json:"city" binding:"omitempty,max=100"`
+	PreferredContactMethod *CommunicationMethod `json:"preferred_contact_method" binding:"omitempty,oneof=phone whatsapp video_call"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventAggregateType string
+
+const (
+	EventAggregateSpecialist EventAggregateType = "specialist"
+)
+
+type EventType string
+
+const (
+	EventSpecialistUpdated EventType = "specialist_updated"
+)
+
+// Event событие изменения агрегата. Для specialist_updated Payload содержит только изменившиеся поля
+// в виде {"поле": {"old": ..., "new": ...}}. ActorID равен null, если автор изменения неизвестен или удален
+type Event struct {
+	ID            int64              `json:"id"`
+	AggregateType EventAggregateType `json:"aggregate_type"`
+	AggregateID   int64              `json:"aggregate_id"`
+	EventType     EventType          `json:"event_type"`
+	Payload       json.RawMessage    `json:"payload" swaggertype:"object"`
+	ActorID       *int64             `json:"actor_id"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// EventFieldChange старое и новое значение поля в событии изменения
+type EventFieldChange struct {
+	Old json.RawMessage `json:"old" swaggertype:"object"`
+	New json.RawMessage `json:"new" swaggertype:"object"`
+}
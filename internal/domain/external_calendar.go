@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// ExternalCalendarFeed ICS-календарь специалиста, занятость из которого блокирует запись
+type ExternalCalendarFeed struct {
+	SpecialistID int64      `json:"specialist_id"`
+	URL          string     `json:"url"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    *string    `json:"last_error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ExternalBusyBlock интервал занятости, импортированный из внешнего календаря
+type ExternalBusyBlock struct {
+	ID           int64     `json:"id"`
+	SpecialistID int64     `json:"specialist_id"`
+	UID          string    `json:"uid"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+}
+
+// Overlaps сообщает, пересекается ли блок с интервалом [start, end)
+func (b ExternalBusyBlock) Overlaps(start, end time.Time) bool {
+	return b.StartsAt.Before(end) && b.EndsAt.After(start)
+}
+
+// SetExternalCalendarDTO пустой URL отключает внешний календарь
+type SetExternalCalendarDTO struct {
+	URL string `json:"url"`
+}
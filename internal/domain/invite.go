@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// UserInvite приглашение пользователя задать пароль; токен хранится только в виде хеша
+type UserInvite struct {
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// InviteUserDTO данные пользователя, которого администратор приглашает без пароля
+type InviteUserDTO struct {
+	FirstName  string   `json:"first_name" binding:"required"`
+	LastName   string   `json:"last_name" binding:"required"`
+	MiddleName string   `json:"middle_name"`
+	Email      string   `json:"email" binding:"required,email"`
+	Phone      string   `json:"phone" binding:"required"`
+	Role       UserRole `json:"role" binding:"required,oneof=client specialist"`
+}
+
+// UserInviteResult результат отправки приглашения
+type UserInviteResult struct {
+	UserID    int64     `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AcceptInviteRequest установка пароля по токену из приглашения
+type AcceptInviteRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+// knownLanguageCodes коды языков ISO 639-1, которые можно указать в профиле специалиста
+var knownLanguageCodes = map[string]struct{}{
+	"ar": {}, "az": {}, "be": {}, "bg": {}, "cs": {}, "da": {}, "de": {}, "el": {},
+	"en": {}, "es": {}, "et": {}, "fa": {}, "fi": {}, "fr": {}, "he": {}, "hi": {},
+	"hr": {}, "hu": {}, "hy": {}, "id": {}, "it": {}, "ja": {}, "ka": {}, "kk": {},
+	"ko": {}, "ky": {}, "lt": {}, "lv": {}, "mn": {}, "nl": {}, "no": {}, "pl": {},
+	"pt": {}, "ro": {}, "ru": {}, "sk": {}, "sl": {}, "sr": {}, "sv": {}, "tg": {},
+	"th": {}, "tk": {}, "tr": {}, "tt": {}, "uk": {}, "uz": {}, "vi": {}, "zh": {},
+}
+
+// IsKnownLanguageCode проверяет, что код языка входит в список поддерживаемых
+func IsKnownLanguageCode(code string) bool {
+	_, ok := knownLanguageCodes[code]
+	return ok
+}
@@ -0,0 +1,183 @@
+package domain
+
+import "time"
+
+type NotificationType string
+
+const (
+	NotificationTypeAppointmentCancelled NotificationType = "appointment_cancelled"
+	NotificationTypeAppointmentConfirmed NotificationType = "appointment_confirmed"
+	NotificationTypeAppointmentReminder  NotificationType = "appointment_reminder"
+	NotificationTypeAppointmentTransfer  NotificationType = "appointment_transferred"
+	NotificationTypeChatMessage          NotificationType = "chat_message"
+	NotificationTypeMarketing            NotificationType = "marketing"
+	NotificationTypeUserInvite           NotificationType = "user_invite"
+	NotificationTypePasswordReset        NotificationType = "password_reset"
+	NotificationTypeReviewRequest        NotificationType = "review_request"
+)
+
+// NotificationCategory группа уведомлений, которую пользователь может отключить
+type NotificationCategory string
+
+const (
+	NotificationCategoryReminders     NotificationCategory = "reminders"
+	NotificationCategoryConfirmations NotificationCategory = "confirmations"
+	NotificationCategoryChat          NotificationCategory = "chat"
+	NotificationCategoryMarketing     NotificationCategory = "marketing"
+)
+
+// Category возвращает группу уведомления. Служебные уведомления (например, приглашение)
+// не входят ни в одну группу и отправляются всегда
+func (t NotificationType) Category() NotificationCategory {
+	switch t {
+	case NotificationTypeAppointmentReminder, NotificationTypeReviewRequest:
+		return NotificationCategoryReminders
+	case NotificationTypeAppointmentConfirmed, NotificationTypeAppointmentCancelled, NotificationTypeAppointmentTransfer:
+		return NotificationCategoryConfirmations
+	case NotificationTypeChatMessage:
+		return NotificationCategoryChat
+	case NotificationTypeMarketing:
+		return NotificationCategoryMarketing
+	default:
+		return ""
+	}
+}
+
+// NotificationChannel канал доставки уведомлений
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelSMS   NotificationChannel = "sms"
+	NotificationChannelPush  NotificationChannel = "push"
+)
+
+// DefaultNotificationChannel используется, пока пользователь не выбрал канал
+const DefaultNotificationChannel = NotificationChannelEmail
+
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case NotificationChannelEmail, NotificationChannelSMS, NotificationChannelPush:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notification уведомление пользователю; Data содержит поля, специфичные для типа.
+// Channel заполняется по настройкам пользователя, если не задан явно
+type Notification struct {
+	UserID  int64                  `json:"user_id"`
+	Type    NotificationType       `json:"type"`
+	Channel NotificationChannel    `json:"channel,omitempty"`
+	Title   string                 `json:"title"`
+	Body    string                 `json:"body"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// NotificationPreferences настройки уведомлений пользователя: предпочитаемый канал, группы уведомлений
+// и разрешенные каналы. Push-уведомления отдельно не отключаются
+type NotificationPreferences struct {
+	UserID        int64               `json:"user_id"`
+	Channel       NotificationChannel `json:"channel"`
+	Reminders     bool                `json:"reminders"`
+	Confirmations bool                `json:"confirmations"`
+	Chat          bool                `json:"chat"`
+	Marketing     bool                `json:"marketing"`
+	EmailEnabled  bool                `json:"email_enabled"`
+	SMSEnabled    bool                `json:"sms_enabled"`
+	UpdatedAt     *time.Time          `json:"updated_at,omitempty"`
+}
+
+// DefaultNotificationPreferences настройки пользователя, который их не менял:
+// транзакционные уведомления включены, рекламные выключены
+func DefaultNotificationPreferences(userID int64) NotificationPreferences {
+	return NotificationPreferences{
+		UserID:        userID,
+		Channel:       DefaultNotificationChannel,
+		Reminders:     true,
+		Confirmations: true,
+		Chat:          true,
+		Marketing:     false,
+		EmailEnabled:  true,
+		SMSEnabled:    true,
+	}
+}
+
+// Allows сообщает, хочет ли пользователь получать уведомления этой группы
+func (p *NotificationPreferences) Allows(category NotificationCategory) bool {
+	switch category {
+	case NotificationCategoryReminders:
+		return p.Reminders
+	case NotificationCategoryConfirmations:
+		return p.Confirmations
+	case NotificationCategoryChat:
+		return p.Chat
+	case NotificationCategoryMarketing:
+		return p.Marketing
+	default:
+		return true
+	}
+}
+
+// ChannelEnabled сообщает, разрешена ли доставка через канал
+func (p *NotificationPreferences) ChannelEnabled(channel NotificationChannel) bool {
+	switch channel {
+	case NotificationChannelEmail:
+		return p.EmailEnabled
+	case NotificationChannelSMS:
+		return p.SMSEnabled
+	default:
+		return true
+	}
+}
+
+// DeliveryChannel выбирает канал доставки: предпочитаемый, если он разрешен, иначе первый разрешенный
+// из email и SMS. Если все отключены, уведомление доставляется через push
+func (p *NotificationPreferences) DeliveryChannel() NotificationChannel {
+	if p.ChannelEnabled(p.Channel) {
+		return p.Channel
+	}
+	for _, channel := range []NotificationChannel{NotificationChannelEmail, NotificationChannelSMS} {
+		if p.ChannelEnabled(channel) {
+			return channel
+		}
+	}
+	return NotificationChannelPush
+}
+
+// UpdateNotificationPreferencesDTO изменение настроек уведомлений; не переданные поля не меняются
+type UpdateNotificationPreferencesDTO struct {
+	Channel       NotificationChannel `json:"channel" binding:"omitempty,oneof=email sms push"`
+	Reminders     *bool               `json:"reminders"`
+	Confirmations *bool               `json:"confirmations"`
+	Chat          *bool               `json:"chat"`
+	Marketing     *bool               `json:"marketing"`
+	EmailEnabled  *bool               `json:"email_enabled"`
+	SMSEnabled    *bool               `json:"sms_enabled"`
+}
+
+// Apply переносит переданные поля в настройки
+func (d UpdateNotificationPreferencesDTO) Apply(prefs *NotificationPreferences) {
+	if d.Channel != "" {
+		prefs.Channel = d.Channel
+	}
+	if d.Reminders != nil {
+		prefs.Reminders = *d.Reminders
+	}
+	if d.Confirmations != nil {
+		prefs.Confirmations = *d.Confirmations
+	}
+	if d.Chat != nil {
+		prefs.Chat = *d.Chat
+	}
+	if d.Marketing != nil {
+		prefs.Marketing = *d.Marketing
+	}
+	if d.EmailEnabled != nil {
+		prefs.EmailEnabled = *d.EmailEnabled
+	}
+	if d.SMSEnabled != nil {
+		prefs.SMSEnabled = *d.SMSEnabled
+	}
+}
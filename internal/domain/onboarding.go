@@ -0,0 +1,25 @@
+package domain
+
+// OnboardingStep шаг заполнения профиля специалиста после регистрации
+type OnboardingStep string
+
+const (
+	OnboardingStepAddEducation      OnboardingStep = "add_education"
+	OnboardingStepAddWorkExperience OnboardingStep = "add_work_experience"
+	OnboardingStepAddPhoto          OnboardingStep = "add_photo"
+	OnboardingStepAddSchedule       OnboardingStep = "add_schedule"
+	OnboardingStepAddSpecialization OnboardingStep = "add_specialization"
+	OnboardingStepGetVerified       OnboardingStep = "get_verified"
+)
+
+// ChecklistItem шаг онбординга и признак его выполнения
+type ChecklistItem struct {
+	Step OnboardingStep `json:"step"`
+	Done bool           `json:"done"`
+}
+
+// OnboardingChecklist состояние онбординга специалиста; Completed равен true, когда выполнены все шаги
+type OnboardingChecklist struct {
+	Items     []ChecklistItem `json:"items"`
+	Completed bool            `json:"completed"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AppointmentEventType тип события жизненного цикла записи для внешних систем
+type AppointmentEventType string
+
+const (
+	AppointmentEventCreated AppointmentEventType = "appointment.created"
+	// AppointmentEventConfirmed запись оплачена (статус paid)
+	AppointmentEventConfirmed AppointmentEventType = "appointment.confirmed"
+	// AppointmentEventStarted специалист начал консультацию (статус in_progress)
+	AppointmentEventStarted   AppointmentEventType = "appointment.started"
+	AppointmentEventCancelled AppointmentEventType = "appointment.cancelled"
+	AppointmentEventCompleted AppointmentEventType = "appointment.completed"
+	// AppointmentEventTransferred запись передана другому специалисту
+	AppointmentEventTransferred AppointmentEventType = "appointment.transferred"
+)
+
+// AppointmentEventForStatus возвращает событие, соответствующее переходу записи в статус
+func AppointmentEventForStatus(status AppointmentStatus) (AppointmentEventType, bool) {
+	switch status {
+	case AppointmentStatusPaid:
+		return AppointmentEventConfirmed, true
+	case AppointmentStatusInProgress:
+		return AppointmentEventStarted, true
+	case AppointmentStatusCancelled:
+		return AppointmentEventCancelled, true
+	case AppointmentStatusCompleted:
+		return AppointmentEventCompleted, true
+	default:
+		return "", false
+	}
+}
+
+// AppointmentEventPayload тело webhook-запроса: тип события, снимок записи и время события
+type AppointmentEventPayload struct {
+	Event       AppointmentEventType `json:"event"`
+	OccurredAt  time.Time            `json:"occurred_at"`
+	Appointment Appointment          `json:"appointment"`
+}
+
+// OutboxEvent событие, ожидающее доставки во внешние системы
+type OutboxEvent struct {
+	ID            int64                `json:"id"`
+	EventType     AppointmentEventType `json:"event_type"`
+	AppointmentID int64                `json:"appointment_id"`
+	Payload       json.RawMessage      `json:"payload"`
+	Attempts      int                  `json:"attempts"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// PasswordResetToken одноразовый токен для установки нового пароля без старого. Хранится только хеш токена
+type PasswordResetToken struct {
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// ForgotPasswordRequest запрос ссылки для восстановления пароля
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest установка нового пароля по токену из письма
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
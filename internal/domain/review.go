@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type Review struct {
@@ -22,10 +24,27 @@ type Review struct {
 	SpecialistExperience *int `json:"specialist_experience"`
 	Grammar              *int `json:"grammar"`
 
-	ReplyID    *int64    `json:"reply_id"`
-	ClientName string    `json:"client_name,omitempty"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ReplyID    *int64 `json:"reply_id"`
+	ClientName string `json:"client_name,omitempty"`
+
+	// Имя, фамилия и аватар автора отзыва, чтобы показать его без отдельного запроса пользователя
+	AuthorFirstName string `json:"author_first_name"`
+	AuthorLastName  string `json:"author_last_name"`
+	AuthorAvatarURL string `json:"author_avatar_url"`
+
+	// SpecialistRemoved профиль специалиста, о котором отзыв, удален
+	SpecialistRemoved bool `json:"specialist_removed"`
+
+	Media     []ReviewMedia `json:"media"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ReviewMedia изображение, приложенное к отзыву
+type ReviewMedia struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Reply struct {
@@ -52,6 +71,9 @@ type CreateReviewDTO struct {
 	Attentiveness        *int `json:"attentiveness" binding:"omitempty,min=1,max=5"`
 	SpecialistExperience *int `json:"specialist_experience" binding:"omitempty,min=1,max=5"`
 	Grammar              *int `json:"grammar" binding:"omitempty,min=1,max=5"`
+
+	// MediaURLs адреса изображений, загруженных клиентом через POST /reviews/media
+	MediaURLs []string `json:"media_urls"`
 }
 
 type CreateReplyDTO struct {
@@ -61,6 +83,8 @@ type CreateReplyDTO struct {
 type UpdateReviewDTO struct {
 	Rating *int    `json:"rating" binding:"omitempty,min=1,max=5"`
 	Text   *string `json:"text" binding:"omitempty"`
+	// ExpectedUpdatedAt updated_at отзыва, который видел клиент; если отзыв с тех пор изменили, обновление отклоняется
+	ExpectedUpdatedAt *time.Time `json:"expected_updated_at"`
 }
 
 type ReviewFilter struct {
@@ -71,3 +95,68 @@ type ReviewFilter struct {
 	Limit        int    `json:"limit"`
 	Offset       int    `json:"offset"`
 }
+
+type RatingSummary struct {
+	SpecialistID       int64       `json:"specialist_id"`
+	AverageRating      float64     `json:"average_rating"`
+	ReviewsCount       int         `json:"reviews_count"`
+	RecommendationRate int         `json:"recommendation_rate"`
+	RatingDistribution map[int]int `json:"rating_distribution"`
+
+	ServiceRating        *float64 `json:"service_rating"`
+	MeetingEfficiency    *float64 `json:"meeting_efficiency"`
+	Professionalism      *float64 `json:"professionalism"`
+	PriceQuality         *float64 `json:"price_quality"`
+	Cleanliness          *float64 `json:"cleanliness"`
+	Attentiveness        *float64 `json:"attentiveness"`
+	SpecialistExperience *float64 `json:"specialist_experience"`
+	Grammar              *float64 `json:"grammar"`
+}
+
+// ReviewScopePublicRecent режим GET /reviews для ленты последних отзывов на главной странице
+const ReviewScopePublicRecent = "public_recent"
+
+// PublicReviewTextMaxLength максимальная длина текста отзыва в публичной ленте, в символах
+const PublicReviewTextMaxLength = 200
+
+// PublicReview отзыв в публичной ленте. Идентификатор клиента не раскрывается,
+// имя автора сокращено до имени и инициала фамилии
+type PublicReview struct {
+	ID         int64                  `json:"id"`
+	Rating     int                    `json:"rating"`
+	Text       string                 `json:"text"`
+	AuthorName string                 `json:"author_name"`
+	Specialist PublicReviewSpecialist `json:"specialist"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// PublicReviewSpecialist карточка специалиста в публичной ленте отзывов
+type PublicReviewSpecialist struct {
+	ID              int64          `json:"id"`
+	FirstName       string         `json:"first_name"`
+	LastName        string         `json:"last_name"`
+	Type            SpecialistType `json:"type"`
+	Specialization  string         `json:"specialization"`
+	ProfilePhotoURL string         `json:"profile_photo_url"`
+	Rating          float64        `json:"rating"`
+	ReviewsCount    int            `json:"reviews_count"`
+}
+
+// ShortAuthorName возвращает имя автора отзыва в виде "Имя Ф."
+func ShortAuthorName(firstName, lastName string) string {
+	name := strings.TrimSpace(firstName)
+	initial, _ := utf8.DecodeRuneInString(strings.TrimSpace(lastName))
+	if initial == utf8.RuneError {
+		return name
+	}
+	return strings.TrimSpace(name + " " + strings.ToUpper(string(initial)) + ".")
+}
+
+// TruncateReviewText обрезает текст до maxLen символов, добавляя многоточие
+func TruncateReviewText(text string, maxLen int) string {
+	if utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:maxLen])) + "…"
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// ReviewRequest запланированная просьба оставить отзыв о завершенной записи
+type ReviewRequest struct {
+	AppointmentID int64
+	ClientID      int64
+	SendAfter     time.Time
+	// Reviewed клиент уже оставил отзыв о записи, просьба не нужна
+	Reviewed bool
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestShortAuthorName(t *testing.T) {
+	tests := []struct {
+		firstName, lastName, want string
+	}{
+		{"Анна", "Петрова", "Анна П."},
+		{" Иван ", " сидоров", "Иван С."},
+		{"John", "smith", "John S."},
+		{"Мария", "", "Мария"},
+		{"", "Петрова", "П."},
+	}
+
+	for _, tt := range tests {
+		if got := ShortAuthorName(tt.firstName, tt.lastName); got != tt.want {
+			t.Errorf("ShortAuthorName(%q, %q) = %q, want %q", tt.firstName, tt.lastName, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateReviewText(t *testing.T) {
+	exact := strings.Repeat("я", PublicReviewTextMaxLength)
+	if got := TruncateReviewText(exact, PublicReviewTextMaxLength); got != exact {
+		t.Errorf("text of exactly %d runes was changed", PublicReviewTextMaxLength)
+	}
+
+	long := strings.Repeat("слов ", 100)
+	got := TruncateReviewText(long, PublicReviewTextMaxLength)
+	if !strings.HasSuffix(got, "…") {
+		t.Errorf("truncated text %q has no ellipsis", got)
+	}
+	if n := utf8.RuneCountInString(strings.TrimSuffix(got, "…")); n > PublicReviewTextMaxLength {
+		t.Errorf("truncated text is %d runes long", n)
+	}
+	if !utf8.ValidString(got) {
+		t.Error("truncation split a multibyte character")
+	}
+	// Обрезка по границе слова не оставляет пробел перед многоточием
+	if strings.Contains(got, " …") {
+		t.Errorf("truncated text %q keeps a trailing space", got)
+	}
+}
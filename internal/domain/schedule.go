@@ -11,11 +11,19 @@ type Schedule struct {
 	StartTime    string    `json:"start_time"`
 	EndTime      string    `json:"end_time"`
 	SlotTime     int       `json:"slot_time"`
+	// SlotAlignment кратность начала слотов в минутах от начала часа, 0 — слоты идут от StartTime
+	SlotAlignment int `json:"slot_alignment"`
+	// SlotOffset сдвиг начала слотов в минутах относительно сетки SlotAlignment или StartTime
+	SlotOffset   int       `json:"slot_offset"`
 	ExcludeTimes []string  `json:"exclude_times"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	Version      int       `json:"version"`
 }
 
+// SlotAlignments допустимые значения кратности начала слотов в минутах; 0 отключает выравнивание
+var SlotAlignments = []int{0, 5, 10, 15, 20, 30, 60}
+
 type WorkTimeSlot struct {
 	StartTime string `json:"start_time" binding:"required"`
 	EndTime   string `json:"end_time" binding:"required"`
@@ -36,15 +44,72 @@ type WeekSchedule struct {
 }
 
 type CreateScheduleDTO struct {
-	WeekSchedule WeekSchedule `json:"week_schedule" binding:"required"`
-	SlotTime     int          `json:"slot_time" binding:"required"`
+	WeekSchedule  WeekSchedule `json:"week_schedule" binding:"required"`
+	SlotTime      int          `json:"slot_time" binding:"required"`
+	SlotAlignment int          `json:"slot_alignment"`
+	SlotOffset    int          `json:"slot_offset"`
 }
 
 type UpdateScheduleDTO struct {
-	WeekSchedule WeekSchedule `json:"week_schedule" binding:"required"`
-	SlotTime     *int         `json:"slot_time,omitempty"`
+	WeekSchedule  WeekSchedule `json:"week_schedule" binding:"required"`
+	SlotTime      *int         `json:"slot_time,omitempty"`
+	SlotAlignment int          `json:"slot_alignment"`
+	SlotOffset    int          `json:"slot_offset"`
+	// ExpectedVersion версия недельного расписания из GET /schedules/week; если она устарела, обновление отклоняется
+	ExpectedVersion *int `json:"expected_version"`
+}
+
+// ScheduleOverride заменяет недельное расписание специалиста на конкретную дату
+type ScheduleOverride struct {
+	ID           int64     `json:"id"`
+	SpecialistID int64     `json:"specialist_id"`
+	Date         time.Time `json:"date"`
+	IsDayOff     bool      `json:"is_day_off"`
+	StartTime    string    `json:"start_time,omitempty"`
+	EndTime      string    `json:"end_time,omitempty"`
+	SlotTime     int       `json:"slot_time,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type SetScheduleOverrideDTO struct {
+	IsDayOff  bool   `json:"is_day_off"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	SlotTime  int    `json:"slot_time"`
 }
 
+// AvailableSlot ближайшее свободное время записи к специалисту
+type AvailableSlot struct {
+	SpecialistID int64     `json:"specialist_id"`
+	Date         string    `json:"date"`
+	Time         string    `json:"time"`
+	DateTime     time.Time `json:"date_time"`
+}
+
+// DayAvailability свободное время специалиста на дату
+type DayAvailability struct {
+	Date      string   `json:"date"`
+	FreeSlots int      `json:"free_slots"`
+	Slots     []string `json:"slots,omitempty"`
+}
+
+// MaxFreeSlotsBatchSize максимальное число специалистов в одном запросе свободных слотов
+const MaxFreeSlotsBatchSize = 50
+
+// FreeSlotsBatchDTO запрос свободных слотов нескольких специалистов на одну дату
+type FreeSlotsBatchDTO struct {
+	SpecialistIDs []int64 `json:"specialist_ids" binding:"required,min=1,max=50,dive,gt=0"`
+	Date          string  `json:"date" binding:"required"`
+}
+
+// Статусы дня в месячном календаре специалиста
+const (
+	CalendarDayFree       = "free"
+	CalendarDayBooked     = "booked"
+	CalendarDayNoSchedule = "no_schedule"
+)
+
 type ScheduleFilter struct {
 	SpecialistID *int64     `json:"specialist_id"`
 	StartDate    *time.Time `json:"start_date"`
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SitemapMaxURLs максимальное число ссылок в одном файле sitemap по протоколу sitemaps.org;
+// при большем числе профилей sitemap.xml становится индексом файлов
+const SitemapMaxURLs = 50000
+
+// Границы оценки в отзывах для aggregateRating
+const (
+	structuredWorstRating = 1
+	structuredBestRating  = 5
+)
+
+// SitemapEntry профиль специалиста, попадающий в sitemap.xml
+type SitemapEntry struct {
+	SpecialistID int64     `json:"specialist_id"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SitemapPage страница профилей для sitemap и общее число профилей
+type SitemapPage struct {
+	Entries []SitemapEntry `json:"entries"`
+	Total   int            `json:"total"`
+}
+
+// StructuredData разметка schema.org в формате JSON-LD для страницы профиля специалиста
+type StructuredData struct {
+	Context string        `json:"@context"`
+	Graph   []interface{} `json:"@graph"`
+}
+
+// StructuredPerson специалист как schema.org Person
+type StructuredPerson struct {
+	Type          string   `json:"@type"`
+	ID            string   `json:"@id"`
+	Name          string   `json:"name"`
+	JobTitle      string   `json:"jobTitle,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	URL           string   `json:"url"`
+	KnowsLanguage []string `json:"knowsLanguage,omitempty"`
+	KnowsAbout    []string `json:"knowsAbout,omitempty"`
+}
+
+// StructuredBusiness практика специалиста как schema.org MedicalBusiness с рейтингом по отзывам
+type StructuredBusiness struct {
+	Type            string                     `json:"@type"`
+	ID              string                     `json:"@id"`
+	Name            string                     `json:"name"`
+	Description     string                     `json:"description,omitempty"`
+	Image           string                     `json:"image,omitempty"`
+	URL             string                     `json:"url"`
+	PriceRange      string                     `json:"priceRange,omitempty"`
+	Employee        StructuredReference        `json:"employee"`
+	AggregateRating *StructuredAggregateRating `json:"aggregateRating,omitempty"`
+}
+
+// StructuredReference ссылка на другой узел графа по @id
+type StructuredReference struct {
+	ID string `json:"@id"`
+}
+
+// StructuredAggregateRating средняя оценка по отзывам
+type StructuredAggregateRating struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	ReviewCount int     `json:"reviewCount"`
+	BestRating  int     `json:"bestRating"`
+	WorstRating int     `json:"worstRating"`
+}
+
+// NewSpecialistStructuredData собирает JSON-LD профиля специалиста, доступного по адресу profileURL;
+// цены указываются в валюте currency. Рейтинг добавляется только при наличии отзывов:
+// поисковики не принимают aggregateRating без них
+func NewSpecialistStructuredData(specialist *Specialist, profileURL, currency string) *StructuredData {
+	name := strings.Join(strings.Fields(specialist.User.FirstName+" "+specialist.User.LastName), " ")
+
+	knowsAbout := make([]string, 0, len(specialist.Specializations)+1)
+	if specialist.Specialization != "" {
+		knowsAbout = append(knowsAbout, specialist.Specialization)
+	}
+	for _, specialization := range specialist.Specializations {
+		if specialization.Name != specialist.Specialization {
+			knowsAbout = append(knowsAbout, specialization.Name)
+		}
+	}
+
+	person := StructuredPerson{
+		Type:          "Person",
+		ID:            profileURL + "#person",
+		Name:          name,
+		JobTitle:      specialist.Specialization,
+		Description:   specialist.Description,
+		Image:         specialist.ProfilePhotoURL,
+		URL:           profileURL,
+		KnowsLanguage: specialist.Languages,
+		KnowsAbout:    knowsAbout,
+	}
+
+	business := StructuredBusiness{
+		Type:        "MedicalBusiness",
+		ID:          profileURL + "#business",
+		Name:        name,
+		Description: specialist.Description,
+		Image:       specialist.ProfilePhotoURL,
+		URL:         profileURL,
+		PriceRange:  structuredPriceRange(currency, specialist.PrimaryConsultPrice, specialist.SecondaryConsultPrice),
+		Employee:    StructuredReference{ID: person.ID},
+	}
+	if specialist.ReviewsCount > 0 {
+		business.AggregateRating = &StructuredAggregateRating{
+			Type:        "AggregateRating",
+			RatingValue: specialist.Rating,
+			ReviewCount: specialist.ReviewsCount,
+			BestRating:  structuredBestRating,
+			WorstRating: structuredWorstRating,
+		}
+	}
+
+	return &StructuredData{
+		Context: "https://schema.org",
+		Graph:   []interface{}{person, business},
+	}
+}
+
+// structuredPriceRange возвращает диапазон цен консультаций, например "5000-8000 RUB"
+func structuredPriceRange(currency string, prices ...float64) string {
+	var low, high float64
+	for _, price := range prices {
+		if price <= 0 {
+			continue
+		}
+		if low == 0 || price < low {
+			low = price
+		}
+		if price > high {
+			high = price
+		}
+	}
+
+	switch {
+	case high == 0:
+		return ""
+	case low == high:
+		return strconv.FormatFloat(low, 'f', -1, 64) + " " + currency
+	default:
+		return strconv.FormatFloat(low, 'f', -1, 64) + "-" + strconv.FormatFloat(high, 'f', -1, 64) + " " + currency
+	}
+}
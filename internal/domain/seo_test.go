@@ -0,0 +1,49 @@
+package domain
+
+import "testing"
+
+func TestStructuredPriceRange(t *testing.T) {
+	tests := []struct {
+		prices []float64
+		want   string
+	}{
+		{nil, ""},
+		{[]float64{0, 0}, ""},
+		{[]float64{5000, 0}, "5000 RUB"},
+		{[]float64{8000, 5000}, "5000-8000 RUB"},
+		{[]float64{4500.5, 4500.5}, "4500.5 RUB"},
+	}
+
+	for _, tt := range tests {
+		if got := structuredPriceRange("RUB", tt.prices...); got != tt.want {
+			t.Errorf("structuredPriceRange(%v) = %q, want %q", tt.prices, got, tt.want)
+		}
+	}
+}
+
+func TestSpecialistStructuredData(t *testing.T) {
+	specialist := &Specialist{
+		User:            User{FirstName: " Анна ", LastName: "Петрова"},
+		Specialization:  "Психолог",
+		Specializations: []Specialization{{Name: "Психолог"}, {Name: "Семейная терапия"}},
+	}
+
+	data := NewSpecialistStructuredData(specialist, "https://laps.test/specialists/7", "RUB")
+	person := data.Graph[0].(StructuredPerson)
+	business := data.Graph[1].(StructuredBusiness)
+	if person.Name != "Анна Петрова" || business.Employee.ID != person.ID {
+		t.Errorf("person = %+v, business employee = %+v", person, business.Employee)
+	}
+	if len(person.KnowsAbout) != 2 || person.KnowsAbout[1] != "Семейная терапия" {
+		t.Errorf("knowsAbout = %v, want each specialization once", person.KnowsAbout)
+	}
+	if business.AggregateRating != nil {
+		t.Errorf("aggregateRating = %+v, want none without reviews", business.AggregateRating)
+	}
+
+	specialist.Rating, specialist.ReviewsCount = 4.5, 12
+	business = NewSpecialistStructuredData(specialist, "https://laps.test/specialists/7", "RUB").Graph[1].(StructuredBusiness)
+	if rating := business.AggregateRating; rating == nil || rating.RatingValue != 4.5 || rating.ReviewCount != 12 {
+		t.Errorf("aggregateRating = %+v, want 4.5 from 12 reviews", rating)
+	}
+}
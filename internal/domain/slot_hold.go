@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// SlotHold временное удержание слота клиентом на время оформления записи.
+// Пока удержание не истекло, слот не показывается свободным и не доступен другим клиентам.
+// Token непредсказуемый идентификатор резервирования, который передается в reservation_token при создании записи
+type SlotHold struct {
+	ID           int64     `json:"id"`
+	Token        string    `json:"reservation_token"`
+	ClientID     int64     `json:"client_id"`
+	SpecialistID int64     `json:"specialist_id"`
+	SlotAt       time.Time `json:"slot_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateSlotHoldDTO struct {
+	SpecialistID    int64     `json:"specialist_id" binding:"required"`
+	AppointmentDate time.Time `json:"appointment_date" binding:"required"`
+}
+
+// ReserveSlotDTO резервирование слота конкретного специалиста
+type ReserveSlotDTO struct {
+	AppointmentDate time.Time `json:"appointment_date" binding:"required"`
+}
@@ -6,38 +6,71 @@ import (
 
 type SpecialistType string
 
+// Базовые типы специалистов; полный список хранится в справочнике specialist_types
 const (
 	SpecialistTypeLawyer       SpecialistType = "lawyer"
 	SpecialistTypePsychologist SpecialistType = "psychologist"
 )
 
-func (t SpecialistType) IsValid() bool {
-	return t == SpecialistTypeLawyer || t == SpecialistTypePsychologist
+// SpecialistTypeInfo запись справочника типов специалистов. Неактивный тип нельзя выбрать для нового
+// или измененного профиля, но существующие профили этого типа остаются
+type SpecialistTypeInfo struct {
+	Code     SpecialistType `json:"code"`
+	Label    string         `json:"label"`
+	IsActive bool           `json:"is_active"`
 }
 
 type Specialist struct {
-	ID                    int64          `json:"id"`
-	UserID                int64          `json:"user_id"`
-	Type                  SpecialistType `json:"type"`
-	Specialization        string         `json:"specialization"`
-	SpecializationID      *int64         `json:"specialization_id"`
-	Experience            int            `json:"experience"`
-	Description           string         `json:"description"`
-	ExperienceYears       int            `json:"experience_years"`
-	Education             []Education    `json:"education"`
-	WorkExperience        []WorkPlace    `json:"work_experience"`
-	AssociationMember     bool           `json:"association_member"`
-	Rating                float64        `json:"rating"`
-	ReviewsCount          int            `json:"reviews_count"`
-	RecommendationRate    int            `json:"recommendation_rate"`
-	PrimaryConsultPrice   float64        `json:"primary_consult_price"`
-	SecondaryConsultPrice float64        `json:"secondary_consult_price"`
-	IsVerified            bool           `json:"is_verified"`
-	ProfilePhotoURL       string         `json:"profile_photo_url"`
-	FreeSlots             []string       `json:"free_slots,omitempty"`
-	User                  User           `json:"user"`
-	CreatedAt             time.Time      `json:"created_at"`
-	UpdatedAt             time.Time      `json:"updated_at"`
+	ID                    int64                    `json:"id"`
+	UserID                int64                    `json:"user_id"`
+	Type                  SpecialistType           `json:"type"`
+	Specialization        string                   `json:"specialization"`
+	SpecializationID      *int64                   `json:"specialization_id"`
+	Experience            int                      `json:"experience"`
+	Description           string                   `json:"description"`
+	ExperienceYears       int                      `json:"experience_years"`
+	Education             []Education              `json:"education"`
+	WorkExperience        []WorkPlace              `json:"work_experience"`
+	Specializations       []Specialization         `json:"specializations"`
+	AssociationMember     bool                     `json:"association_member"`
+	Rating                float64                  `json:"rating"`
+	ReviewsCount          int                      `json:"reviews_count"`
+	RecommendationRate    int                      `json:"recommendation_rate"`
+	PrimaryConsultPrice   float64                  `json:"primary_consult_price"`
+	SecondaryConsultPrice float64                  `json:"secondary_consult_price"`
+	IsVerified            bool                     `json:"is_verified"`
+	AcceptingClients      bool                     `json:"accepting_clients"`
+	ProfilePhotoURL       string                   `json:"profile_photo_url"`
+	Languages             []string                 `json:"languages"`
+	Tags                  []string                 `json:"tags"`
+	FreeSlots             []string                 `json:"free_slots,omitempty"`
+	ResponseStats         *SpecialistResponseStats `json:"response_stats,omitempty"`
+	ActivityStats         *SpecialistActivityStats `json:"activity_stats,omitempty"`
+	User                  User                     `json:"user"`
+	CreatedAt             time.Time                `json:"created_at"`
+	UpdatedAt             time.Time                `json:"updated_at"`
+	// Version увеличивается при каждом обновлении профиля; передается в expected_version при изменении
+	Version int `json:"version"`
+	// Translations переводы описания профиля на другие языки
+	Translations Translations `json:"translations,omitempty"`
+	// DeletedAt время мягкого удаления; удаленный профиль виден только администратору
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// SpecialistActivityStats показатели активности специалиста за последние PeriodDays дней.
+// Показатель равен null, если выборка меньше минимальной
+type SpecialistActivityStats struct {
+	PeriodDays int `json:"period_days"`
+	// MedianFirstResponseSeconds медиана времени от первого сообщения клиента до первого ответа специалиста
+	MedianFirstResponseSeconds *float64 `json:"median_first_response_seconds"`
+	ResponseSampleSize         int      `json:"response_sample_size"`
+	// ConfirmationRate доля записей, дошедших до оплаты или завершения, среди уже решенных записей
+	ConfirmationRate       *float64 `json:"confirmation_rate"`
+	ConfirmationSampleSize int      `json:"confirmation_sample_size"`
+	// SpecialistCancellationRate доля записей, отмененных самим специалистом
+	SpecialistCancellationRate *float64  `json:"specialist_cancellation_rate"`
+	CancellationSampleSize     int       `json:"cancellation_sample_size"`
+	CalculatedAt               time.Time `json:"calculated_at"`
 }
 
 type Education struct {
@@ -65,7 +98,7 @@ type WorkPlace struct {
 
 type CreateSpecialistDTO struct {
 	UserID                int64               `json:"user_id,omitempty"`
-	Type                  SpecialistType      `json:"type" binding:"required,oneof=lawyer psychologist"`
+	Type                  SpecialistType      `json:"type" binding:"required"`
 	SpecializationID      int64               `json:"specialization_id" binding:"required"`
 	Experience            int                 `json:"experience,omitempty" binding:"min=0"`
 	Description           string              `json:"description,omitempty"`
@@ -76,10 +109,12 @@ type CreateSpecialistDTO struct {
 	ProfilePhoto          []byte              `json:"-"`
 	Education             []EducationDTO      `json:"education,omitempty"`
 	WorkExperience        []WorkExperienceDTO `json:"work_experience,omitempty"`
+	// Languages коды языков ISO 639-1, на которых специалист ведет консультации
+	Languages []string `json:"languages,omitempty"`
 }
 
 type UpdateSpecialistDTO struct {
-	Type                  *SpecialistType `json:"type" binding:"omitempty,oneof=lawyer psychologist"`
+	Type                  *SpecialistType `json:"type"`
 	SpecializationID      *int64          `json:"specialization_id"`
 	Experience            *int            `json:"experience" binding:"omitempty,min=0"`
 	Description           *string         `json:"description"`
@@ -87,7 +122,59 @@ type UpdateSpecialistDTO struct {
 	AssociationMember     *bool           `json:"association_member"`
 	PrimaryConsultPrice   *float64        `json:"primary_consult_price" binding:"omitempty,min=0"`
 	SecondaryConsultPrice *float64        `json:"secondary_consult_price" binding:"omitempty,min=0"`
-	ProfilePhoto          []byte          `json:"-"`
+	// AcceptingClients false закрывает запись для новых клиентов
+	AcceptingClients *bool  `json:"accepting_clients"`
+	ProfilePhoto     []byte `json:"-"`
+	// Languages заменяет список языков специалиста целиком, если передан
+	Languages *[]string `json:"languages"`
+	// ChangedBy пользователь, изменивший профиль; записывается в историю цен и в событие изменения профиля
+	ChangedBy *int64 `json:"-"`
+	// ExpectedVersion версия профиля, которую видел клиент; если она устарела, обновление отклоняется
+	ExpectedVersion *int `json:"expected_version"`
+}
+
+// SpecialistPriceChange запись истории изменения цен консультаций специалиста
+type SpecialistPriceChange struct {
+	ID                int64     `json:"id"`
+	SpecialistID      int64     `json:"specialist_id"`
+	OldPrimaryPrice   float64   `json:"old_primary_price"`
+	NewPrimaryPrice   float64   `json:"new_primary_price"`
+	OldSecondaryPrice float64   `json:"old_secondary_price"`
+	NewSecondaryPrice float64   `json:"new_secondary_price"`
+	ChangedAt         time.Time `json:"changed_at"`
+	ChangedByUserID   *int64    `json:"changed_by_user_id"`
+}
+
+// SpecialistFilter параметры выборки списка специалистов
+type SpecialistFilter struct {
+	Type             *SpecialistType
+	SpecializationID *int64
+	// Language код языка ISO 639-1
+	Language *string
+	// Tags нормализованные метки; специалист должен иметь все перечисленные метки
+	Tags []string
+	// AcceptingClients отбирает специалистов, которые принимают (true) или не принимают (false) новых клиентов
+	AcceptingClients *bool
+	// MinExperienceYears отбирает специалистов со стажем не меньше указанного числа лет
+	MinExperienceYears *int
+	// IncludeDeleted включает в выборку удаленных специалистов и специалистов с деактивированным аккаунтом; доступно только администратору
+	IncludeDeleted bool
+	Limit          int
+	Offset         int
+}
+
+// SetAcceptingClientsDTO быстрое переключение приема новых клиентов
+type SetAcceptingClientsDTO struct {
+	AcceptingClients *bool `json:"accepting_clients" binding:"required"`
+}
+
+type VerifySpecialistDTO struct {
+	IsVerified *bool `json:"is_verified" binding:"required"`
+}
+
+// SetSpecializationsDTO полный набор дополнительных специализаций специалиста; пустой список удаляет все
+type SetSpecializationsDTO struct {
+	SpecializationIDs []int64 `json:"specialization_ids" binding:"required,dive,gt=0"`
 }
 
 type EducationDTO struct {
@@ -97,6 +184,7 @@ type EducationDTO struct {
 	GraduationYear int    `json:"graduation_year" binding:"required"`
 }
 
+// WorkExperienceDTO место работы специалиста; EndYear не указывается для текущего места работы
 type WorkExperienceDTO struct {
 	Company     string `json:"company" binding:"required"`
 	Position    string `json:"position" binding:"required"`
@@ -104,3 +192,8 @@ type WorkExperienceDTO struct {
 	EndYear     *int   `json:"end_year"`
 	Description string `json:"description"`
 }
+
+// HasValidPeriod проверяет, что год окончания работы, если указан, не раньше года начала
+func (d WorkExperienceDTO) HasValidPeriod() bool {
+	return d.EndYear == nil || *d.EndYear >= d.StartYear
+}
@@ -6,12 +6,21 @@ import (
 
 type Specialization struct {
 	ID          int64          `json:"id"`
+	ParentID    *int64         `json:"parent_id"`
 	Name        string         `json:"name"`
 	Description string         `json:"description"`
 	Type        SpecialistType `json:"type"`
 	IsActive    bool           `json:"is_active"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	// Translations переводы названия и описания на другие языки
+	Translations Translations `json:"translations,omitempty"`
+}
+
+// SpecializationNode специализация с дочерними специализациями для отображения дерева категорий
+type SpecializationNode struct {
+	Specialization
+	Children []*SpecializationNode `json:"children"`
 }
 
 type SpecialistSpecialization struct {
@@ -23,14 +32,19 @@ type SpecialistSpecialization struct {
 type CreateSpecializationDTO struct {
 	Name        string         `json:"name" binding:"required"`
 	Description string         `json:"description" binding:"required"`
-	Type        SpecialistType `json:"type" binding:"required,oneof=lawyer psychologist"`
+	Type        SpecialistType `json:"type" binding:"required"`
 	IsActive    bool           `json:"is_active"`
+	ParentID    *int64         `json:"parent_id"`
 }
 
+// UpdateSpecializationDTO: ParentID переносит специализацию в другую категорию,
+// RemoveParent делает ее категорией верхнего уровня
 type UpdateSpecializationDTO struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	IsActive    *bool   `json:"is_active"`
+	Name         *string `json:"name"`
+	Description  *string `json:"description"`
+	IsActive     *bool   `json:"is_active"`
+	ParentID     *int64  `json:"parent_id"`
+	RemoveParent bool    `json:"remove_parent"`
 }
 
 type SpecializationFilter struct {
@@ -38,6 +52,7 @@ type SpecializationFilter struct {
 	IsActive     *bool           `json:"is_active"`
 	SearchTerm   *string         `json:"search_term"`
 	SpecialistID *int64          `json:"specialist_id"`
+	ParentID     *int64          `json:"parent_id"`
 	Limit        int             `json:"limit"`
 	Offset       int             `json:"offset"`
 }
@@ -0,0 +1,13 @@
+package domain
+
+// Tag произвольная метка специалиста (например, «кпт»); UsageCount число специалистов с меткой
+type Tag struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	UsageCount int    `json:"usage_count"`
+}
+
+// SetSpecialistTagsDTO заменяет набор меток специалиста целиком
+type SetSpecialistTagsDTO struct {
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultContentLanguage язык, на котором хранятся основные поля специализаций и профилей специалистов
+const DefaultContentLanguage = "ru"
+
+// contentLanguages языки, на которые можно перевести контент, кроме DefaultContentLanguage
+var contentLanguages = map[string]struct{}{
+	"en": {},
+	"kk": {},
+}
+
+// IsTranslationLanguage проверяет, что на язык code можно сохранить перевод
+func IsTranslationLanguage(code string) bool {
+	_, ok := contentLanguages[code]
+	return ok
+}
+
+// Translation перевод текстовых полей на один язык; пустое поле означает, что перевода нет
+type Translation struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// IsEmpty сообщает, что в переводе нет ни одного поля
+func (t Translation) IsEmpty() bool {
+	return t.Name == "" && t.Description == ""
+}
+
+// Translations переводы по кодам языков
+type Translations map[string]Translation
+
+// pick возвращает значение поля на первом из языков langs, для которого оно переведено.
+// Если раньше перевода встречается DefaultContentLanguage или перевода нет, возвращается original
+func (t Translations) pick(langs []string, original string, field func(Translation) string) string {
+	for _, lang := range langs {
+		if lang == DefaultContentLanguage {
+			return original
+		}
+		if value := field(t[lang]); value != "" {
+			return value
+		}
+	}
+	return original
+}
+
+// SetTranslationsDTO переводы для сохранения; язык с пустыми полями удаляет ранее сохраненный перевод
+type SetTranslationsDTO struct {
+	Translations Translations `json:"translations" binding:"required"`
+}
+
+// PreferredContentLanguages разбирает заголовок Accept-Language и возвращает поддерживаемые языки контента
+// в порядке убывания веса. Региональные варианты сводятся к основному языку (kk-KZ → kk),
+// языки с q=0 и неподдерживаемые языки пропускаются. Для пустого заголовка возвращается nil
+func PreferredContentLanguages(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []weighted
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.ToLower(strings.TrimSpace(params[0]))
+		if i := strings.IndexByte(lang, '-'); i >= 0 {
+			lang = lang[:i]
+		}
+		if lang != DefaultContentLanguage && !IsTranslationLanguage(lang) {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		candidates = append(candidates, weighted{lang: lang, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	var langs []string
+	seen := make(map[string]struct{}, len(candidates))
+	for _, candidate := range candidates {
+		if _, ok := seen[candidate.lang]; ok {
+			continue
+		}
+		seen[candidate.lang] = struct{}{}
+		langs = append(langs, candidate.lang)
+	}
+	return langs
+}
+
+// Localize заменяет название и описание специализации переводами на предпочтительных языках langs
+func (s *Specialization) Localize(langs []string) {
+	if len(langs) == 0 {
+		return
+	}
+	s.Name = s.Translations.pick(langs, s.Name, func(t Translation) string { return t.Name })
+	s.Description = s.Translations.pick(langs, s.Description, func(t Translation) string { return t.Description })
+}
+
+// Localize заменяет описание специалиста и названия его специализаций переводами на предпочтительных языках langs
+func (s *Specialist) Localize(langs []string) {
+	if len(langs) == 0 {
+		return
+	}
+	s.Description = s.Translations.pick(langs, s.Description, func(t Translation) string { return t.Description })
+	for i := range s.Specializations {
+		s.Specializations[i].Localize(langs)
+	}
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPreferredContentLanguages(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", nil},
+		{"en", []string{"en"}},
+		{"kk-KZ,ru;q=0.8,en;q=0.5", []string{"kk", "ru", "en"}},
+		{"en;q=0.3, kk;q=0.9", []string{"kk", "en"}},
+		{"de,fr;q=0.9", nil},
+		{"en;q=0,kk", []string{"kk"}},
+		{"EN-us, en-GB;q=0.9", []string{"en"}},
+	}
+
+	for _, tt := range tests {
+		if got := PreferredContentLanguages(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PreferredContentLanguages(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSpecializationLocalizeFallback(t *testing.T) {
+	newSpecialization := func() *Specialization {
+		return &Specialization{Name: "Психолог", Description: "Описание", Translations: Translations{
+			"en": {Name: "Psychologist"},
+			"kk": {Name: "Психолог (kk)", Description: "Сипаттама"},
+		}}
+	}
+
+	tests := []struct {
+		name                      string
+		langs                     []string
+		wantName, wantDescription string
+	}{
+		{"no preference", nil, "Психолог", "Описание"},
+		{"full translation", []string{"kk"}, "Психолог (kk)", "Сипаттама"},
+		{"missing field falls back to the original", []string{"en"}, "Psychologist", "Описание"},
+		{"missing field uses the next language", []string{"en", "kk"}, "Psychologist", "Сипаттама"},
+		{"russian before translations", []string{"ru", "en"}, "Психолог", "Описание"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSpecialization()
+			s.Localize(tt.langs)
+			if s.Name != tt.wantName || s.Description != tt.wantDescription {
+				t.Errorf("Localize(%v) = %q, %q; want %q, %q", tt.langs, s.Name, s.Description, tt.wantName, tt.wantDescription)
+			}
+		})
+	}
+}
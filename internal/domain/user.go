@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -16,6 +17,13 @@ type User struct {
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	// LastSeenAt время последней активности в WebSocket-соединении; null, если пользователь ни разу не подключался
+	LastSeenAt *time.Time `json:"last_seen_at"`
+}
+
+// HasPassword сообщает, задал ли пользователь пароль; у приглашенных пользователей его нет до принятия приглашения
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
 }
 
 type UserRole string
@@ -26,6 +34,20 @@ const (
 	UserRoleAdmin      UserRole = "admin"
 )
 
+// IsValid проверяет, что роль входит в список известных ролей
+func (r UserRole) IsValid() bool {
+	switch r {
+	case UserRoleClient, UserRoleSpecialist, UserRoleAdmin:
+		return true
+	}
+	return false
+}
+
+// ChangeRoleDTO новая роль пользователя; меняет ее только администратор
+type ChangeRoleDTO struct {
+	Role UserRole `json:"role" binding:"required"`
+}
+
 type CreateUserDTO struct {
 	FirstName  string   `json:"first_name" binding:"required"`
 	LastName   string   `json:"last_name" binding:"required"`
@@ -54,3 +76,24 @@ type PasswordUpdateDTO struct {
 	OldPassword string `json:"old_password" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,min=6"`
 }
+
+// DeleteAccountDTO подтверждение удаления аккаунта текущим паролем
+type DeleteAccountDTO struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Имя, которое получает пользователь после удаления аккаунта; под ним остаются его отзывы и записи
+const (
+	AnonymizedFirstName = "Удаленный"
+	AnonymizedLastName  = "пользователь"
+)
+
+// AnonymizedEmail уникальный адрес-заглушка, освобождающий email удаленного пользователя
+func AnonymizedEmail(userID int64) string {
+	return fmt.Sprintf("deleted-%d@deleted.invalid", userID)
+}
+
+// AnonymizedPhone уникальный телефон-заглушка, освобождающий телефон удаленного пользователя
+func AnonymizedPhone(userID int64) string {
+	return fmt.Sprintf("deleted-%d", userID)
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventType событие, на которое подписывается адрес внешней системы
+type WebhookEventType string
+
+const (
+	WebhookEventAppointmentCreated WebhookEventType = "appointment.created"
+	// WebhookEventAppointmentStatusChanged запись оплачена, начата, отменена или завершена
+	WebhookEventAppointmentStatusChanged WebhookEventType = "appointment.status_changed"
+	WebhookEventReviewCreated            WebhookEventType = "review.created"
+	// WebhookEventSpecialistVerified администратор подтвердил профиль специалиста
+	WebhookEventSpecialistVerified WebhookEventType = "specialist.verified"
+)
+
+func (t WebhookEventType) IsValid() bool {
+	switch t {
+	case WebhookEventAppointmentCreated, WebhookEventAppointmentStatusChanged,
+		WebhookEventReviewCreated, WebhookEventSpecialistVerified:
+		return true
+	}
+	return false
+}
+
+// WebhookEventForAppointmentEvent возвращает событие для адресов внешних систем, соответствующее
+// событию записи из outbox. Передача записи другому специалисту адресам не отправляется
+func WebhookEventForAppointmentEvent(eventType AppointmentEventType) (WebhookEventType, bool) {
+	switch eventType {
+	case AppointmentEventCreated:
+		return WebhookEventAppointmentCreated, true
+	case AppointmentEventConfirmed, AppointmentEventStarted, AppointmentEventCancelled, AppointmentEventCompleted:
+		return WebhookEventAppointmentStatusChanged, true
+	default:
+		return "", false
+	}
+}
+
+// WebhookEndpoint адрес внешней системы, зарегистрированный администратором. Секрет в ответах не отдается
+type WebhookEndpoint struct {
+	ID         int64              `json:"id"`
+	URL        string             `json:"url"`
+	Secret     string             `json:"-"`
+	EventTypes []WebhookEventType `json:"event_types"`
+	IsActive   bool               `json:"is_active"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// CreateWebhookEndpointDTO адрес http(s), секрет для подписи тела запроса и события, которые на него отправляются
+type CreateWebhookEndpointDTO struct {
+	URL        string             `json:"url" binding:"required"`
+	Secret     string             `json:"secret" binding:"required,min=16"`
+	EventTypes []WebhookEventType `json:"event_types" binding:"required,min=1"`
+}
+
+// WebhookDeliveryStatus состояние доставки события на адрес
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryFailed попытки доставки исчерпаны
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery доставка события на зарегистрированный адрес. EventID общий для всех адресов события
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	EndpointID     int64                 `json:"endpoint_id"`
+	EventID        string                `json:"event_id"`
+	EventType      WebhookEventType      `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty"`
+	LastError      *string               `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	FailedAt       *time.Time            `json:"failed_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+// WebhookDeliveryJob доставка, выбранная для отправки, с адресом и секретом для подписи
+type WebhookDeliveryJob struct {
+	ID        int64
+	EventID   string
+	EventType WebhookEventType
+	Payload   json.RawMessage
+	Attempts  int
+	URL       string
+	Secret    string
+}
+
+// WebhookEventPayload тело запроса на зарегистрированный адрес
+type WebhookEventPayload struct {
+	ID         string           `json:"id"`
+	Event      WebhookEventType `json:"event"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       interface{}      `json:"data"`
+}
+
+// AppointmentWebhookData данные событий записи; Status заполняется только для appointment.status_changed
+type AppointmentWebhookData struct {
+	Appointment Appointment       `json:"appointment"`
+	Status      AppointmentStatus `json:"status,omitempty"`
+}
+
+// ReviewWebhookData данные события review.created
+type ReviewWebhookData struct {
+	ReviewID      int64 `json:"review_id"`
+	SpecialistID  int64 `json:"specialist_id"`
+	ClientID      int64 `json:"client_id"`
+	AppointmentID int64 `json:"appointment_id"`
+	Rating        int   `json:"rating"`
+}
+
+// SpecialistVerifiedWebhookData данные события specialist.verified
+type SpecialistVerifiedWebhookData struct {
+	SpecialistID int64     `json:"specialist_id"`
+	VerifiedAt   time.Time `json:"verified_at"`
+}
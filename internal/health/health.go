@@ -0,0 +1,83 @@
+// Package health проверяет доступность внешних зависимостей приложения.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check проверка одной зависимости
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Report результат проверки: статус каждой зависимости и список непрошедших проверок
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+	Failed []string          `json:"failed,omitempty"`
+}
+
+// Healthy сообщает, прошли ли все проверки
+func (r Report) Healthy() bool {
+	return len(r.Failed) == 0
+}
+
+// Run выполняет проверки параллельно; проверка, не ответившая за timeout, считается проваленной
+func Run(ctx context.Context, checks []Check, timeout time.Duration) Report {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]error, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = runWithDeadline(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]string, len(checks)),
+	}
+	for i, check := range checks {
+		if results[i] != nil {
+			report.Checks[check.Name] = results[i].Error()
+			report.Failed = append(report.Failed, check.Name)
+			continue
+		}
+		report.Checks[check.Name] = StatusOK
+	}
+	if !report.Healthy() {
+		report.Status = StatusFail
+	}
+
+	return report
+}
+
+// runWithDeadline не ждет проверку дольше срока ctx, даже если она не учитывает отмену контекста
+func runWithDeadline(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Run(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("нет ответа: %w", ctx.Err())
+	}
+}
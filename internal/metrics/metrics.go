@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry реестр метрик Prometheus приложения, отдается на /metrics. Отдельный реестр вместо
+// глобального, чтобы на /metrics попадали только метрики приложения, процесса и рантайма Go
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler отдает метрики Registry в формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RateLimitThrottled считает запросы, отклоненные ограничением частоты, по группе маршрутов
+// и способу определения клиента (user — по ID пользователя, ip — по адресу)
+var RateLimitThrottled = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+	Name: "rate_limit_throttled_total",
+	Help: "Requests rejected by the rate limiter, by route group and client key type.",
+}, []string{"group", "key_type"})
+
+// PanicsRecovered считает перехваченные паники по источнику (http, websocket)
+var PanicsRecovered = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+	Name: "panics_recovered_total",
+	Help: "Panics recovered by HTTP and WebSocket handlers, by source.",
+}, []string{"source"})
+
+const (
+	PanicSourceHTTP      = "http"
+	PanicSourceWebSocket = "websocket"
+)
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestStore(now *time.Time) *MemoryStore {
+	s := NewMemoryStore()
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	s := newTestStore(&now)
+	limit := Limit{Rate: 2, Burst: 5}
+	ctx := context.Background()
+
+	for i := 0; i < limit.Burst; i++ {
+		if result, _ := s.Allow(ctx, "ip:1.2.3.4", limit); !result.Allowed {
+			t.Fatalf("request %d of the burst was rejected", i+1)
+		}
+	}
+
+	result, _ := s.Allow(ctx, "ip:1.2.3.4", limit)
+	if result.Allowed {
+		t.Fatal("request over the burst was allowed")
+	}
+	if result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("RetryAfter = %v, want 500ms at 2 req/s", result.RetryAfter)
+	}
+
+	// Другой клиент расходует свою корзину
+	if result, _ := s.Allow(ctx, "ip:5.6.7.8", limit); !result.Allowed {
+		t.Error("another key shares the exhausted bucket")
+	}
+
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if result, _ := s.Allow(ctx, "ip:1.2.3.4", limit); !result.Allowed {
+			t.Fatalf("request %d after a second of refill was rejected", i+1)
+		}
+	}
+	if result, _ := s.Allow(ctx, "ip:1.2.3.4", limit); result.Allowed {
+		t.Error("refill gave more than rate × elapsed tokens")
+	}
+}
+
+func TestMemoryStoreRefillIsCappedAtBurst(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	s := newTestStore(&now)
+	limit := Limit{Rate: 10, Burst: 3}
+	ctx := context.Background()
+
+	_, _ = s.Allow(ctx, "user:1", limit)
+	now = now.Add(time.Hour)
+
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if result, _ := s.Allow(ctx, "user:1", limit); result.Allowed {
+			allowed++
+		}
+	}
+	if allowed != limit.Burst {
+		t.Errorf("allowed %d requests after a long pause, want the burst of %d", allowed, limit.Burst)
+	}
+}
+
+func TestMemoryStoreUnlimited(t *testing.T) {
+	s := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		if result, _ := s.Allow(context.Background(), "ip:1.2.3.4", Limit{}); !result.Allowed {
+			t.Fatal("zero limit throttled a request")
+		}
+	}
+}
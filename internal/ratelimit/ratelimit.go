@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"laps/config"
+)
+
+// Limit описывает token bucket: Rate токенов в секунду и емкость Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result результат проверки лимита; RetryAfter заполняется, если запрос отклонен
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние лимитов по ключу клиента
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// New создает хранилище лимитов, выбранное в конфигурации
+func New(cfg config.RateLimitConfig) (Store, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер ограничения запросов: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+// Медиана времени первого ответа и доли подтвержденных и отмененных специалистом записей
+// считаются по данным за период; записи и чаты до since не учитываются
+func TestSpecialistActivityStats(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	var chatTables bool
+	if err := db.QueryRow(ctx, "SELECT to_regclass('chat_messages') IS NOT NULL").Scan(&chatTables); err != nil {
+		t.Fatal(err)
+	}
+	if !chatTables {
+		// Таблицы чата создаются вне миграций
+		t.Skip("chat tables are not present in the test database")
+	}
+
+	appointments := NewAppointmentRepository(db)
+	chats := NewChatRepository(db)
+	specialists := NewSpecialistRepository(db)
+	specialistID := createTestSpecialist(t, db)
+	clientID := createTestUser(t, db, "client")
+
+	var specialistUserID, specializationID int64
+	err := db.QueryRow(ctx, "SELECT user_id, specialization_id FROM specialists WHERE id = $1", specialistID).
+		Scan(&specialistUserID, &specializationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -90)
+	slot := 0
+	book := func(status domain.AppointmentStatus, cancelledBy string, createdAt time.Time) int64 {
+		t.Helper()
+		slot++
+		id, err := appointments.Create(ctx, clientID, bookingDTO(specialistID, testSlot(48+24*slot)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = db.Exec(ctx, "UPDATE appointments SET status = $2, cancelled_by = NULLIF($3, ''), created_at = $4 WHERE id = $1",
+			id, status, cancelledBy, createdAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	message := func(sessionID, senderID int64, at time.Time) {
+		t.Helper()
+		_, err := db.Exec(ctx, `
+			INSERT INTO chat_messages (session_id, sender_id, message_type, content, created_at, updated_at)
+			VALUES ($1, $2, 'text', 'сообщение', $3, $3)
+		`, sessionID, senderID, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Ответы через 1, 2, 3, 4 и 5 минут, один чат без ответа и один вне периода
+	recent := now.Add(-24 * time.Hour)
+	for i, reply := range []time.Duration{3, 1, 5, 2, 4, 0, 1} {
+		firstAt := recent.Add(time.Duration(i) * time.Hour)
+		if i == 6 {
+			firstAt = since.Add(-24 * time.Hour)
+		}
+		appointmentID := book(domain.AppointmentStatusCompleted, "", recent)
+		session, err := chats.CreateChatSession(ctx, domain.CreateChatSessionDTO{
+			AppointmentID:    appointmentID,
+			ClientID:         clientID,
+			SpecialistID:     specialistID,
+			SpecializationID: specializationID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		message(session.ID, clientID, firstAt)
+		message(session.ID, clientID, firstAt.Add(30*time.Second))
+		if reply > 0 {
+			message(session.ID, specialistUserID, firstAt.Add(reply*time.Minute))
+		}
+	}
+
+	// К 7 завершенным записям чатов добавляются отмены, будущая неоплаченная запись
+	// (еще не решена) и запись вне периода
+	book(domain.AppointmentStatusCancelled, "specialist", recent)
+	book(domain.AppointmentStatusCancelled, "client", recent)
+	book(domain.AppointmentStatusPending, "", recent)
+	book(domain.AppointmentStatusCancelled, "specialist", since.Add(-time.Hour))
+
+	stats, err := specialists.GetActivityStats(ctx, specialistID, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.ResponseSampleSize != 5 || stats.MedianFirstResponseSeconds == nil || *stats.MedianFirstResponseSeconds != 180 {
+		t.Errorf("response: sample = %d, median = %v; want 5 and 180s", stats.ResponseSampleSize, stats.MedianFirstResponseSeconds)
+	}
+	if stats.ConfirmationSampleSize != 9 || stats.ConfirmationRate == nil || !approx(*stats.ConfirmationRate, 7.0/9) {
+		t.Errorf("confirmation: sample = %d, rate = %v; want 9 and 7/9", stats.ConfirmationSampleSize, stats.ConfirmationRate)
+	}
+	if stats.CancellationSampleSize != 10 || stats.SpecialistCancellationRate == nil || !approx(*stats.SpecialistCancellationRate, 0.1) {
+		t.Errorf("cancellation: sample = %d, rate = %v; want 10 and 0.1", stats.CancellationSampleSize, stats.SpecialistCancellationRate)
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
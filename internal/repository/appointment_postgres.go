@@ -24,47 +24,54 @@ func NewAppointmentRepository(db *pgxpool.Pool) *AppointmentRepo {
 }
 
 func (r *AppointmentRepo) Create(ctx context.Context, clientID int64, dto domain.CreateAppointmentDTO) (int64, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.Create")
+	defer span.End()
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	checkQuery := `
-		SELECT COUNT(*) 
-		FROM appointments 
-		WHERE specialist_id = $1 
-		AND appointment_date = $2
-		AND status != 'cancelled'
-	`
+	if err := lockSlot(ctx, tx, dto.SpecialistID, dto.AppointmentDate); err != nil {
+		return 0, err
+	}
 
-	var count int
-	err = tx.QueryRow(ctx, checkQuery, dto.SpecialistID, dto.AppointmentDate).Scan(&count)
+	taken, err := slotTaken(ctx, tx, clientID, dto.SpecialistID, dto.AppointmentDate)
 	if err != nil {
-		return 0, fmt.Errorf("ошибка проверки доступности слота: %w", err)
+		return 0, err
+	}
+	if taken {
+		return 0, ErrSlotTaken
 	}
 
-	if count > 0 {
-		return 0, errors.New("выбранный слот времени уже занят")
+	if dto.HoldID != nil || dto.ReservationToken != nil {
+		tag, err := tx.Exec(ctx, `
+			DELETE FROM slot_holds
+			WHERE ($1::bigint IS NULL OR id = $1)
+			AND ($2::varchar IS NULL OR token = $2)
+			AND client_id = $3 AND specialist_id = $4 AND slot_at = $5 AND expires_at > NOW()
+		`, dto.HoldID, dto.ReservationToken, clientID, dto.SpecialistID, dto.AppointmentDate)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка использования удержания слота: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return 0, ErrHoldNotFound
+		}
 	}
 
-	var price float64
-	priceQuery := `
-		SELECT CASE 
-			WHEN $1 = 'primary' THEN primary_consult_price 
-			WHEN $1 = 'secondary' THEN secondary_consult_price 
-			ELSE primary_consult_price 
-		END 
-		FROM specialists 
-		WHERE id = $2
-	`
-	err = tx.QueryRow(ctx, priceQuery, dto.ConsultationType, dto.SpecialistID).Scan(&price)
+	// Остальные удержания клиентом этого же слота больше не нужны
+	_, err = tx.Exec(ctx,
+		"DELETE FROM slot_holds WHERE client_id = $1 AND specialist_id = $2 AND slot_at = $3",
+		clientID, dto.SpecialistID, dto.AppointmentDate,
+	)
 	if err != nil {
-		return 0, fmt.Errorf("ошибка получения цены консультации: %w", err)
+		return 0, fmt.Errorf("ошибка удаления удержаний слота: %w", err)
 	}
 
-	if price <= 0 {
-		return 0, fmt.Errorf("некорректная цена консультации: %f", price)
+	price, err := consultationPrice(ctx, tx, dto.SpecialistID, dto.ConsultationType)
+	if err != nil {
+		return 0, err
 	}
 
 	query := `
@@ -91,6 +98,14 @@ func (r *AppointmentRepo) Create(ctx context.Context, clientID int64, dto domain
 		return 0, fmt.Errorf("ошибка создания записи на прием: %w", err)
 	}
 
+	appointment, err := appointmentSnapshot(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := insertAppointmentEvent(ctx, tx, domain.AppointmentEventCreated, *appointment); err != nil {
+		return 0, err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
 	}
@@ -99,11 +114,17 @@ func (r *AppointmentRepo) Create(ctx context.Context, clientID int64, dto domain
 }
 
 func (r *AppointmentRepo) GetByID(ctx context.Context, id int64) (*domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.GetByID")
+	defer span.End()
+
 	query := `
-		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.price, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at,
+		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.price, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at, a.call_duration_seconds,
+		       a.checked_in_at, a.actual_start_at, a.actual_end_at,
+		       a.payment_id,
 		       u.first_name AS user_first_name, u.last_name AS user_last_name,
-		       s.type AS specialist_type,
-		       su.first_name AS specialist_first_name, su.last_name AS specialist_last_name
+		       s.type AS specialist_type, s.deleted_at IS NOT NULL AS specialist_removed,
+		       su.first_name AS specialist_first_name, su.last_name AS specialist_last_name,
+		       (SELECT cs.id FROM chat_sessions cs WHERE cs.appointment_id = a.id ORDER BY cs.id DESC LIMIT 1) AS chat_session_id
 		FROM appointments a
 		JOIN users u ON a.client_id = u.id
 		JOIN specialists s ON a.specialist_id = s.id
@@ -127,11 +148,18 @@ func (r *AppointmentRepo) GetByID(ctx context.Context, id int64) (*domain.Appoin
 		&appointment.CommunicationMethod,
 		&appointment.CreatedAt,
 		&appointment.UpdatedAt,
+		&appointment.CallDurationSeconds,
+		&appointment.CheckedInAt,
+		&appointment.ActualStartAt,
+		&appointment.ActualEndAt,
+		&appointment.PaymentID,
 		&userFirstName,
 		&userLastName,
 		&specialistType,
+		&appointment.SpecialistRemoved,
 		&specialistFirstName,
 		&specialistLastName,
+		&appointment.ChatSessionID,
 	)
 
 	if err != nil {
@@ -141,21 +169,147 @@ func (r *AppointmentRepo) GetByID(ctx context.Context, id int64) (*domain.Appoin
 		return nil, fmt.Errorf("ошибка получения записи на прием: %w", err)
 	}
 
+	appointment.ClientName = strings.TrimSpace(userFirstName + " " + userLastName)
+	appointment.SpecialistName = strings.TrimSpace(specialistFirstName + " " + specialistLastName)
+
 	return &appointment, nil
 }
 
-func (r *AppointmentRepo) UpdateStatus(ctx context.Context, id int64, status domain.AppointmentStatus) error {
+// GetNextUpcoming возвращает ближайшую будущую неотмененную и незавершенную запись клиента
+// с именем и фото специалиста или nil, если таких записей нет
+func (r *AppointmentRepo) GetNextUpcoming(ctx context.Context, clientID int64) (*domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.GetNextUpcoming")
+	defer span.End()
+
 	query := `
-		UPDATE appointments
-		SET status = $1, updated_at = $2
-		WHERE id = $3
+		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.price, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at, a.call_duration_seconds,
+		       a.checked_in_at, a.actual_start_at, a.actual_end_at,
+		       a.payment_id,
+		       su.first_name AS specialist_first_name, su.last_name AS specialist_last_name,
+		       COALESCE(s.profile_photo_url, '') AS specialist_photo_url,
+		       s.deleted_at IS NOT NULL AS specialist_removed
+		FROM appointments a
+		JOIN specialists s ON a.specialist_id = s.id
+		JOIN users su ON s.user_id = su.id
+		WHERE a.client_id = $1 AND a.appointment_date > NOW() AND a.status IN ($2, $3, $4)
+		ORDER BY a.appointment_date ASC
+		LIMIT 1
 	`
 
-	_, err := r.db.Exec(ctx, query, status, time.Now(), id)
+	var appointment domain.Appointment
+	var specialistFirstName, specialistLastName string
+
+	err := r.db.QueryRow(ctx, query, clientID, domain.AppointmentStatusPending, domain.AppointmentStatusPaid, domain.AppointmentStatusInProgress).Scan(
+		&appointment.ID,
+		&appointment.ClientID,
+		&appointment.SpecialistID,
+		&appointment.SpecializationID,
+		&appointment.Price,
+		&appointment.AppointmentDate,
+		&appointment.Status,
+		&appointment.ConsultationType,
+		&appointment.CommunicationMethod,
+		&appointment.CreatedAt,
+		&appointment.UpdatedAt,
+		&appointment.CallDurationSeconds,
+		&appointment.CheckedInAt,
+		&appointment.ActualStartAt,
+		&appointment.ActualEndAt,
+		&appointment.PaymentID,
+		&specialistFirstName,
+		&specialistLastName,
+		&appointment.SpecialistPhotoURL,
+		&appointment.SpecialistRemoved,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ближайшей записи клиента: %w", err)
+	}
+
+	appointment.SpecialistName = strings.TrimSpace(specialistFirstName + " " + specialistLastName)
+
+	return &appointment, nil
+}
+
+// GetLastCompleted возвращает последнюю по времени завершенную запись клиента к специалисту или nil, если таких
+// записей нет. Если specializationID задан, учитываются только записи по этой специализации. Заполняются
+// только ID, участники, специализация, тип консультации и время записи
+func (r *AppointmentRepo) GetLastCompleted(ctx context.Context, clientID, specialistID int64, specializationID *int64) (*domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.GetLastCompleted")
+	defer span.End()
+
+	query := `
+		SELECT id, client_id, specialist_id, specialization_id, consultation_type, appointment_date
+		FROM appointments
+		WHERE client_id = $1 AND specialist_id = $2 AND status = $3
+		  AND ($4::BIGINT IS NULL OR specialization_id = $4)
+		ORDER BY appointment_date DESC, id DESC
+		LIMIT 1
+	`
+
+	var appointment domain.Appointment
+	err := r.db.QueryRow(ctx, query, clientID, specialistID, domain.AppointmentStatusCompleted, specializationID).Scan(
+		&appointment.ID,
+		&appointment.ClientID,
+		&appointment.SpecialistID,
+		&appointment.SpecializationID,
+		&appointment.ConsultationType,
+		&appointment.AppointmentDate,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения последней завершенной записи: %w", err)
+	}
+
+	return &appointment, nil
+}
+
+func (r *AppointmentRepo) UpdateStatus(ctx context.Context, id int64, status domain.AppointmentStatus) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previousStatus domain.AppointmentStatus
+	err = tx.QueryRow(ctx, "SELECT status FROM appointments WHERE id = $1 FOR UPDATE", id).Scan(&previousStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
 	if err != nil {
+		return fmt.Errorf("ошибка получения текущего статуса записи: %w", err)
+	}
+
+	// Завершение фиксирует фактическое окончание консультации
+	endField := ""
+	if status == domain.AppointmentStatusCompleted {
+		endField = ", actual_end_at = COALESCE(actual_end_at, $2)"
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE appointments
+		SET status = $1, updated_at = $2%s
+		WHERE id = $3
+	`, endField)
+
+	if _, err := tx.Exec(ctx, query, status, time.Now(), id); err != nil {
 		return fmt.Errorf("ошибка обновления статуса записи: %w", err)
 	}
 
+	if status != previousStatus {
+		if err := insertAppointmentStatusEvent(ctx, tx, id, status); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
 	return nil
 }
 
@@ -168,14 +322,26 @@ func (r *AppointmentRepo) Update(ctx context.Context, id int64, dto domain.Updat
 
 	if dto.AppointmentDate != nil {
 		var currentAppointmentDate time.Time
-		var specialistID int64
+		var specialistID, clientID int64
 
-		query := `SELECT specialist_id, appointment_date FROM appointments WHERE id = $1`
-		err := tx.QueryRow(ctx, query, id).Scan(&specialistID, &currentAppointmentDate)
+		query := `SELECT specialist_id, client_id, appointment_date FROM appointments WHERE id = $1`
+		err := tx.QueryRow(ctx, query, id).Scan(&specialistID, &clientID, &currentAppointmentDate)
 		if err != nil {
 			return fmt.Errorf("ошибка получения текущих данных записи: %w", err)
 		}
 
+		if err := lockSlot(ctx, tx, specialistID, *dto.AppointmentDate); err != nil {
+			return err
+		}
+
+		held, err := slotHeldByOthers(ctx, tx, clientID, specialistID, *dto.AppointmentDate)
+		if err != nil {
+			return err
+		}
+		if held {
+			return ErrSlotTaken
+		}
+
 		checkQuery := `
 			SELECT COUNT(*) 
 			FROM appointments 
@@ -192,7 +358,7 @@ func (r *AppointmentRepo) Update(ctx context.Context, id int64, dto domain.Updat
 		}
 
 		if count > 0 {
-			return errors.New("выбранный слот времени уже занят")
+			return ErrSlotTaken
 		}
 	}
 
@@ -211,6 +377,10 @@ func (r *AppointmentRepo) Update(ctx context.Context, id int64, dto domain.Updat
 		updateFields = append(updateFields, fmt.Sprintf("status = $%d", argCount))
 		args = append(args, *dto.Status)
 		argCount++
+
+		if *dto.Status == domain.AppointmentStatusCompleted {
+			updateFields = append(updateFields, "actual_end_at = COALESCE(actual_end_at, NOW())")
+		}
 	}
 
 	if dto.PaymentID != nil {
@@ -219,6 +389,12 @@ func (r *AppointmentRepo) Update(ctx context.Context, id int64, dto domain.Updat
 		argCount++
 	}
 
+	if dto.CancelledBy != nil {
+		updateFields = append(updateFields, fmt.Sprintf("cancelled_by = $%d", argCount))
+		args = append(args, *dto.CancelledBy)
+		argCount++
+	}
+
 	updateFields = append(updateFields, fmt.Sprintf("updated_at = $%d", argCount))
 	args = append(args, time.Now())
 	argCount++
@@ -234,10 +410,128 @@ func (r *AppointmentRepo) Update(ctx context.Context, id int64, dto domain.Updat
 		WHERE id = $%d
 	`, strings.Join(updateFields, ", "), argCount)
 
-	_, err = tx.Exec(ctx, query, args...)
+	// Оптимистичная блокировка: запись не должна была меняться после того, как ее прочитал клиент
+	if dto.ExpectedUpdatedAt != nil {
+		argCount++
+		query += fmt.Sprintf(" AND updated_at = $%d", argCount)
+		args = append(args, *dto.ExpectedUpdatedAt)
+	}
+
+	// Прежний статус нужен, чтобы событие отправлялось только при реальной смене статуса
+	var previousStatus domain.AppointmentStatus
+	if dto.Status != nil {
+		err = tx.QueryRow(ctx, "SELECT status FROM appointments WHERE id = $1 FOR UPDATE", id).Scan(&previousStatus)
+		if err != nil {
+			return fmt.Errorf("ошибка получения текущего статуса записи: %w", err)
+		}
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("ошибка обновления записи на прием: %w", err)
 	}
+	if tag.RowsAffected() == 0 && dto.ExpectedUpdatedAt != nil {
+		return ErrVersionConflict
+	}
+
+	if dto.Status != nil && *dto.Status != previousStatus {
+		if err := insertAppointmentStatusEvent(ctx, tx, id, *dto.Status); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// Transfer передает запись специалисту specialistID с типом консультации consultationType и ценой
+// из его прайса. Под блокировкой слота проверяется, что записи и чужие удержания нового специалиста
+// не пересекаются с интервалом записи длительностью slotDuration; при пересечении возвращается ErrSlotTaken,
+// для завершенной или отмененной записи — ErrAppointmentClosed. Чат записи переходит к новому специалисту
+func (r *AppointmentRepo) Transfer(ctx context.Context, id, specialistID int64, consultationType domain.ConsultationType, slotDuration time.Duration) error {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.Transfer")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var clientID int64
+	var appointmentDate time.Time
+	var status domain.AppointmentStatus
+	err = tx.QueryRow(ctx,
+		"SELECT client_id, appointment_date, status FROM appointments WHERE id = $1 FOR UPDATE",
+		id,
+	).Scan(&clientID, &appointmentDate, &status)
+	if err != nil {
+		return fmt.Errorf("ошибка получения текущих данных записи: %w", err)
+	}
+	if status == domain.AppointmentStatusCompleted || status == domain.AppointmentStatusCancelled ||
+		status == domain.AppointmentStatusInProgress {
+		return ErrAppointmentClosed
+	}
+
+	if err := lockSlot(ctx, tx, specialistID, appointmentDate); err != nil {
+		return err
+	}
+
+	// Записи нового специалиста считаются той же длительности, поэтому пересекаются те,
+	// что начинаются меньше чем за slotDuration до или после начала переносимой записи
+	from, to := appointmentDate.Add(-slotDuration), appointmentDate.Add(slotDuration)
+	var overlaps bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM appointments
+			WHERE specialist_id = $1 AND id != $2 AND status != 'cancelled'
+			AND appointment_date > $3 AND appointment_date < $4
+		) OR EXISTS (
+			SELECT 1 FROM slot_holds
+			WHERE specialist_id = $1 AND client_id != $5 AND expires_at > NOW()
+			AND slot_at > $3 AND slot_at < $4
+		)
+	`, specialistID, id, from, to, clientID).Scan(&overlaps)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки доступности слота: %w", err)
+	}
+	if overlaps {
+		return ErrSlotTaken
+	}
+
+	price, err := consultationPrice(ctx, tx, specialistID, consultationType)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(ctx, `
+		UPDATE appointments
+		SET specialist_id = $1, consultation_type = $2, price = $3, updated_at = $4
+		WHERE id = $5
+	`, specialistID, consultationType, price, now, id)
+	if err != nil {
+		return fmt.Errorf("ошибка передачи записи специалисту: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE chat_sessions SET specialist_id = $1, updated_at = $2 WHERE appointment_id = $3",
+		specialistID, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка передачи чата записи специалисту: %w", err)
+	}
+
+	appointment, err := appointmentSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := insertAppointmentEvent(ctx, tx, domain.AppointmentEventTransferred, *appointment); err != nil {
+		return err
+	}
 
 	if err = tx.Commit(ctx); err != nil {
 		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
@@ -246,6 +540,31 @@ func (r *AppointmentRepo) Update(ctx context.Context, id int64, dto domain.Updat
 	return nil
 }
 
+// consultationPrice возвращает цену консультации указанного типа по прайсу специалиста.
+// У удаленного специалиста цены нет: новые записи к нему не создаются
+func consultationPrice(ctx context.Context, tx pgx.Tx, specialistID int64, consultationType domain.ConsultationType) (float64, error) {
+	var price float64
+	priceQuery := `
+		SELECT CASE 
+			WHEN $1 = 'primary' THEN primary_consult_price 
+			WHEN $1 = 'secondary' THEN secondary_consult_price 
+			ELSE primary_consult_price 
+		END 
+		FROM specialists 
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+	err := tx.QueryRow(ctx, priceQuery, consultationType, specialistID).Scan(&price)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения цены консультации: %w", err)
+	}
+
+	if price <= 0 {
+		return 0, fmt.Errorf("некорректная цена консультации: %f", price)
+	}
+
+	return price, nil
+}
+
 func (r *AppointmentRepo) Delete(ctx context.Context, id int64) error {
 	return r.UpdateStatus(ctx, id, domain.AppointmentStatusCancelled)
 }
@@ -284,9 +603,10 @@ func (r *AppointmentRepo) GetByUserID(ctx context.Context, userID int64, filter
 	args = append(args, filter.Limit, filter.Offset)
 
 	query := fmt.Sprintf(`
-		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at,
+		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.price, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at, a.call_duration_seconds,
+		       a.checked_in_at, a.actual_start_at, a.actual_end_at,
 		       u.first_name AS user_first_name, u.last_name AS user_last_name,
-		       s.type AS specialist_type,
+		       s.type AS specialist_type, s.deleted_at IS NOT NULL AS specialist_removed,
 		       su.first_name AS specialist_first_name, su.last_name AS specialist_last_name
 		FROM appointments a
 		JOIN users u ON a.client_id = u.id
@@ -314,15 +634,21 @@ func (r *AppointmentRepo) GetByUserID(ctx context.Context, userID int64, filter
 			&appointment.ClientID,
 			&appointment.SpecialistID,
 			&appointment.SpecializationID,
+			&appointment.Price,
 			&appointment.AppointmentDate,
 			&appointment.Status,
 			&appointment.ConsultationType,
 			&appointment.CommunicationMethod,
 			&appointment.CreatedAt,
 			&appointment.UpdatedAt,
+			&appointment.CallDurationSeconds,
+			&appointment.CheckedInAt,
+			&appointment.ActualStartAt,
+			&appointment.ActualEndAt,
 			&userFirstName,
 			&userLastName,
 			&specialistType,
+			&appointment.SpecialistRemoved,
 			&specialistFirstName,
 			&specialistLastName,
 		); err != nil {
@@ -373,9 +699,10 @@ func (r *AppointmentRepo) GetBySpecialistID(ctx context.Context, specialistID in
 	args = append(args, filter.Limit, filter.Offset)
 
 	query := fmt.Sprintf(`
-		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at,
+		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.price, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at, a.call_duration_seconds,
+		       a.checked_in_at, a.actual_start_at, a.actual_end_at,
 		       u.first_name AS user_first_name, u.last_name AS user_last_name,
-		       s.type AS specialist_type,
+		       s.type AS specialist_type, s.deleted_at IS NOT NULL AS specialist_removed,
 		       su.first_name AS specialist_first_name, su.last_name AS specialist_last_name
 		FROM appointments a
 		JOIN users u ON a.client_id = u.id
@@ -403,15 +730,21 @@ func (r *AppointmentRepo) GetBySpecialistID(ctx context.Context, specialistID in
 			&appointment.ClientID,
 			&appointment.SpecialistID,
 			&appointment.SpecializationID,
+			&appointment.Price,
 			&appointment.AppointmentDate,
 			&appointment.Status,
 			&appointment.ConsultationType,
 			&appointment.CommunicationMethod,
 			&appointment.CreatedAt,
 			&appointment.UpdatedAt,
+			&appointment.CallDurationSeconds,
+			&appointment.CheckedInAt,
+			&appointment.ActualStartAt,
+			&appointment.ActualEndAt,
 			&userFirstName,
 			&userLastName,
 			&specialistType,
+			&appointment.SpecialistRemoved,
 			&specialistFirstName,
 			&specialistLastName,
 		); err != nil {
@@ -428,13 +761,187 @@ func (r *AppointmentRepo) GetBySpecialistID(ctx context.Context, specialistID in
 	return appointments, nil
 }
 
-func (r *AppointmentRepo) GetFreeSlots(ctx context.Context, specialistID int64, date string) ([]string, error) {
+// MarkConfirmationSent отмечает, что клиенту отправлено уведомление о подтверждении записи.
+// Возвращает false, если отметка уже стояла, чтобы повторное подтверждение не дублировало уведомление
+func (r *AppointmentRepo) MarkConfirmationSent(ctx context.Context, id int64) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.MarkConfirmationSent")
+	defer span.End()
+
+	query := `
+		UPDATE appointments
+		SET confirmation_sent_at = NOW()
+		WHERE id = $1 AND confirmation_sent_at IS NULL
+	`
+
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("ошибка отметки уведомления о подтверждении записи: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// AddCallDuration прибавляет длительность завершенного отрезка звонка к длительности звонков по записи
+func (r *AppointmentRepo) AddCallDuration(ctx context.Context, id int64, seconds int) error {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.AddCallDuration")
+	defer span.End()
+
+	query := `
+		UPDATE appointments
+		SET call_duration_seconds = call_duration_seconds + $2
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, id, seconds)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения длительности звонка: %w", err)
+	}
+
+	return nil
+}
+
+// ListCompletableByCall возвращает оплаченные и начатые записи, время которых прошло раньше endedBefore
+// и по которым звонки длились не меньше minDuration секунд
+func (r *AppointmentRepo) ListCompletableByCall(ctx context.Context, minDuration int, endedBefore time.Time) ([]int64, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.ListCompletableByCall")
+	defer span.End()
+
+	query := `
+		SELECT id
+		FROM appointments
+		WHERE status IN ($1, $4) AND call_duration_seconds >= $2 AND appointment_date < $3
+		ORDER BY appointment_date
+	`
+
+	rows, err := r.db.Query(ctx, query, domain.AppointmentStatusPaid, minDuration, endedBefore, domain.AppointmentStatusInProgress)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записей для завершения: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования записи: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по записям: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CheckIn отмечает, что клиент готов к консультации; повторная отметка сохраняет время первой.
+// Для записи не в статусе paid возвращается ErrAppointmentNotPaid
+func (r *AppointmentRepo) CheckIn(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.CheckIn")
+	defer span.End()
+
+	query := `
+		UPDATE appointments
+		SET checked_in_at = COALESCE(checked_in_at, $1), updated_at = $1
+		WHERE id = $2 AND status = $3
+	`
+
+	tag, err := r.db.Exec(ctx, query, time.Now(), id, domain.AppointmentStatusPaid)
+	if err != nil {
+		return fmt.Errorf("ошибка отметки клиента: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAppointmentNotPaid
+	}
+
+	return nil
+}
+
+// Start переводит оплаченную запись в статус in_progress и фиксирует фактическое начало консультации.
+// Без force запись должна быть отмечена клиентом, иначе возвращается ErrNotCheckedIn;
+// для записи не в статусе paid возвращается ErrAppointmentNotPaid
+func (r *AppointmentRepo) Start(ctx context.Context, id int64, force bool) error {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.Start")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status domain.AppointmentStatus
+	var checkedInAt *time.Time
+	err = tx.QueryRow(ctx, "SELECT status, checked_in_at FROM appointments WHERE id = $1 FOR UPDATE", id).Scan(&status, &checkedInAt)
+	if err != nil {
+		return fmt.Errorf("ошибка получения текущего статуса записи: %w", err)
+	}
+	if status != domain.AppointmentStatusPaid {
+		return ErrAppointmentNotPaid
+	}
+	if checkedInAt == nil && !force {
+		return ErrNotCheckedIn
+	}
+
+	now := time.Now()
+	query := `
+		UPDATE appointments
+		SET status = $1, actual_start_at = $2, updated_at = $2
+		WHERE id = $3
+	`
+	if _, err := tx.Exec(ctx, query, domain.AppointmentStatusInProgress, now, id); err != nil {
+		return fmt.Errorf("ошибка начала консультации: %w", err)
+	}
+
+	if err := insertAppointmentStatusEvent(ctx, tx, id, domain.AppointmentStatusInProgress); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// HasActiveAppointment проверяет, есть ли у клиента хотя бы одна неотмененная запись к специалисту
+func (r *AppointmentRepo) HasActiveAppointment(ctx context.Context, specialistID, clientID int64) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.HasActiveAppointment")
+	defer span.End()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM appointments
+			WHERE specialist_id = $1 AND client_id = $2 AND status <> $3
+		)
+	`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, specialistID, clientID, domain.AppointmentStatusCancelled).Scan(&exists); err != nil {
+		return false, fmt.Errorf("ошибка проверки записей клиента: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetBookedSlots возвращает время (HH:MM) неотмененных записей и активных удержаний специалиста на дату
+func (r *AppointmentRepo) GetBookedSlots(ctx context.Context, specialistID int64, date string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.GetBookedSlots")
+	defer span.End()
+
 	query := `
 		SELECT TO_CHAR(appointment_date, 'HH24:MI') as time_slot
 		FROM appointments 
 		WHERE specialist_id = $1 
 		AND DATE(appointment_date) = $2
 		AND status != 'cancelled'
+		UNION
+		SELECT TO_CHAR(slot_at, 'HH24:MI')
+		FROM slot_holds
+		WHERE specialist_id = $1
+		AND DATE(slot_at) = $2
+		AND expires_at > NOW()
 	`
 
 	rows, err := r.db.Query(ctx, query, specialistID, date)
@@ -443,31 +950,165 @@ func (r *AppointmentRepo) GetFreeSlots(ctx context.Context, specialistID int64,
 	}
 	defer rows.Close()
 
-	busySlots := make(map[string]bool)
+	var bookedSlots []string
 	for rows.Next() {
 		var slot string
 		if err := rows.Scan(&slot); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования слотов: %w", err)
 		}
-		busySlots[slot] = true
+		bookedSlots = append(bookedSlots, slot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return bookedSlots, nil
+}
+
+// GetBookedSlotsForSpecialists возвращает занятые слоты нескольких специалистов на дату одним запросом,
+// сгруппированные по ID специалиста: неотмененные записи и активные удержания
+func (r *AppointmentRepo) GetBookedSlotsForSpecialists(ctx context.Context, specialistIDs []int64, date string) (map[int64][]string, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.GetBookedSlotsForSpecialists")
+	defer span.End()
+
+	query := `
+		SELECT specialist_id, TO_CHAR(appointment_date, 'HH24:MI')
+		FROM appointments
+		WHERE specialist_id = ANY($1)
+		AND DATE(appointment_date) = $2
+		AND status != 'cancelled'
+		UNION
+		SELECT specialist_id, TO_CHAR(slot_at, 'HH24:MI')
+		FROM slot_holds
+		WHERE specialist_id = ANY($1)
+		AND DATE(slot_at) = $2
+		AND expires_at > NOW()
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения занятых слотов: %w", err)
+	}
+	defer rows.Close()
+
+	bookedSlots := make(map[int64][]string)
+	for rows.Next() {
+		var specialistID int64
+		var slot string
+		if err := rows.Scan(&specialistID, &slot); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования слотов: %w", err)
+		}
+		bookedSlots[specialistID] = append(bookedSlots[specialistID], slot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return bookedSlots, nil
+}
+
+// GetBookedSlotsInRange возвращает занятые слоты специалиста за даты с startDate по endDate включительно,
+// сгруппированные по дате (YYYY-MM-DD): неотмененные записи и активные удержания
+func (r *AppointmentRepo) GetBookedSlotsInRange(ctx context.Context, specialistID int64, startDate, endDate string) (map[string][]string, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.GetBookedSlotsInRange")
+	defer span.End()
+
+	query := `
+		SELECT TO_CHAR(appointment_date, 'YYYY-MM-DD'), TO_CHAR(appointment_date, 'HH24:MI')
+		FROM appointments
+		WHERE specialist_id = $1
+		AND DATE(appointment_date) BETWEEN $2 AND $3
+		AND status != 'cancelled'
+		UNION
+		SELECT TO_CHAR(slot_at, 'YYYY-MM-DD'), TO_CHAR(slot_at, 'HH24:MI')
+		FROM slot_holds
+		WHERE specialist_id = $1
+		AND DATE(slot_at) BETWEEN $2 AND $3
+		AND expires_at > NOW()
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения занятых слотов: %w", err)
+	}
+	defer rows.Close()
+
+	bookedSlots := make(map[string][]string)
+	for rows.Next() {
+		var date, slot string
+		if err := rows.Scan(&date, &slot); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования слотов: %w", err)
+		}
+		bookedSlots[date] = append(bookedSlots[date], slot)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
 	}
 
-	allSlots := []string{
-		"09:00", "10:00", "11:00", "12:00", "13:00", "14:00", "15:00", "16:00", "17:00",
+	return bookedSlots, nil
+}
+
+// CancelRange отменяет предстоящие ожидающие и оплаченные записи специалиста с from (включительно)
+// до to (не включительно) и возвращает отмененные записи. Прошедшие, начатые и завершенные записи не меняются
+func (r *AppointmentRepo) CancelRange(ctx context.Context, specialistID int64, from, to time.Time) ([]domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.CancelRange")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE appointments
+		SET status = 'cancelled', cancelled_by = 'specialist', updated_at = $4
+		WHERE specialist_id = $1
+		AND appointment_date >= $2
+		AND appointment_date < $3
+		AND appointment_date >= now()
+		AND status IN ('pending', 'paid')
+		RETURNING id, client_id, specialist_id, consultation_type, specialization_id, price,
+			appointment_date, status, payment_id, communication_method, created_at, updated_at
+	`
+
+	rows, err := tx.Query(ctx, query, specialistID, from, to, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отмены записей: %w", err)
+	}
+
+	var appointments []domain.Appointment
+	for rows.Next() {
+		var a domain.Appointment
+		if err := rows.Scan(
+			&a.ID, &a.ClientID, &a.SpecialistID, &a.ConsultationType, &a.SpecializationID, &a.Price,
+			&a.AppointmentDate, &a.Status, &a.PaymentID, &a.CommunicationMethod, &a.CreatedAt, &a.UpdatedAt,
+		); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("ошибка сканирования отмененной записи: %w", err)
+		}
+		appointments = append(appointments, a)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
 	}
 
-	var freeSlots []string
-	for _, slot := range allSlots {
-		if !busySlots[slot] {
-			freeSlots = append(freeSlots, slot)
+	for _, a := range appointments {
+		if err := insertAppointmentEvent(ctx, tx, domain.AppointmentEventCancelled, a); err != nil {
+			return nil, err
 		}
 	}
 
-	return freeSlots, nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return appointments, nil
 }
 
 func (r *AppointmentRepo) CountByFilter(ctx context.Context, filter domain.AppointmentFilter) (int, error) {
@@ -504,6 +1145,12 @@ func (r *AppointmentRepo) CountByFilter(ctx context.Context, filter domain.Appoi
 		argCount++
 	}
 
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, fmt.Sprintf("status = ANY($%d)", argCount))
+		args = append(args, filter.Statuses)
+		argCount++
+	}
+
 	if filter.StartDate != nil {
 		conditions = append(conditions, fmt.Sprintf("appointment_date >= $%d", argCount))
 		args = append(args, filter.StartDate)
@@ -516,6 +1163,16 @@ func (r *AppointmentRepo) CountByFilter(ctx context.Context, filter domain.Appoi
 		argCount++
 	}
 
+	if condition, searchArgs := appointmentSearchCondition(filter.Search, "client_id", "specialist_id", argCount); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argCount += len(searchArgs)
+	}
+
+	if filter.PendingReview {
+		conditions = append(conditions, pendingReviewCondition("appointments.id", "appointments.specialist_id"))
+	}
+
 	query := baseQuery
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
@@ -532,9 +1189,10 @@ func (r *AppointmentRepo) CountByFilter(ctx context.Context, filter domain.Appoi
 
 func (r *AppointmentRepo) List(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, error) {
 	baseQuery := `
-		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.price, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at,
+		SELECT a.id, a.client_id, a.specialist_id, a.specialization_id, a.price, a.appointment_date, a.status, a.consultation_type, a.communication_method, a.created_at, a.updated_at, a.call_duration_seconds,
+		       a.checked_in_at, a.actual_start_at, a.actual_end_at,
 		       u.first_name AS user_first_name, u.last_name AS user_last_name,
-		       s.type AS specialist_type,
+		       s.type AS specialist_type, s.deleted_at IS NOT NULL AS specialist_removed,
 		       su.first_name AS specialist_first_name, su.last_name AS specialist_last_name
 		FROM appointments a
 		JOIN users u ON a.client_id = u.id
@@ -564,6 +1222,12 @@ func (r *AppointmentRepo) List(ctx context.Context, filter domain.AppointmentFil
 		argCount++
 	}
 
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, fmt.Sprintf("a.status = ANY($%d)", argCount))
+		args = append(args, filter.Statuses)
+		argCount++
+	}
+
 	if filter.StartDate != nil {
 		conditions = append(conditions, fmt.Sprintf("a.appointment_date >= $%d", argCount))
 		args = append(args, filter.StartDate)
@@ -582,12 +1246,26 @@ func (r *AppointmentRepo) List(ctx context.Context, filter domain.AppointmentFil
 		argCount++
 	}
 
+	if condition, searchArgs := appointmentSearchCondition(filter.Search, "a.client_id", "a.specialist_id", argCount); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+		argCount += len(searchArgs)
+	}
+
+	if filter.PendingReview {
+		conditions = append(conditions, pendingReviewCondition("a.id", "a.specialist_id"))
+	}
+
 	query := baseQuery
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	query += " ORDER BY a.appointment_date DESC"
+	if filter.SortAscending {
+		query += " ORDER BY a.appointment_date ASC, a.id ASC"
+	} else {
+		query += " ORDER BY a.appointment_date DESC, a.id DESC"
+	}
 
 	if filter.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
@@ -620,9 +1298,14 @@ func (r *AppointmentRepo) List(ctx context.Context, filter domain.AppointmentFil
 			&appointment.CommunicationMethod,
 			&appointment.CreatedAt,
 			&appointment.UpdatedAt,
+			&appointment.CallDurationSeconds,
+			&appointment.CheckedInAt,
+			&appointment.ActualStartAt,
+			&appointment.ActualEndAt,
 			&userFirstName,
 			&userLastName,
 			&specialistType,
+			&appointment.SpecialistRemoved,
 			&specialistFirstName,
 			&specialistLastName,
 		); err != nil {
@@ -638,3 +1321,108 @@ func (r *AppointmentRepo) List(ctx context.Context, filter domain.AppointmentFil
 
 	return appointments, nil
 }
+
+// pendingReviewCondition строит условие для записей без отзыва, специалист которых не удален
+func pendingReviewCondition(idColumn, specialistColumn string) string {
+	return fmt.Sprintf(
+		"NOT EXISTS (SELECT 1 FROM reviews rv WHERE rv.appointment_id = %s) AND EXISTS (SELECT 1 FROM specialists sp WHERE sp.id = %s AND sp.deleted_at IS NULL)",
+		idColumn, specialistColumn)
+}
+
+// ФИО пользователя u для поиска; совпадает с выражением индекса idx_users_full_name_trgm
+const userFullNameExpr = `(u.last_name || ' ' || u.first_name || ' ' || COALESCE(u.middle_name, ''))`
+
+// Наибольшее число слов поискового запроса; остальные слова отбрасываются
+const maxAppointmentSearchWords = 5
+
+// appointmentSearchCondition строит условие поиска записей по ФИО клиента или специалиста. Каждое слово ищется
+// подстрокой без учета регистра; спецсимволы LIKE в словах экранируются. Плейсхолдеры нумеруются с argIndex
+func appointmentSearchCondition(search, clientColumn, specialistColumn string, argIndex int) (string, []interface{}) {
+	words := strings.Fields(search)
+	if len(words) == 0 {
+		return "", nil
+	}
+	if len(words) > maxAppointmentSearchWords {
+		words = words[:maxAppointmentSearchWords]
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	matches := make([]string, 0, len(words))
+	args := make([]interface{}, 0, len(words))
+	for i, word := range words {
+		matches = append(matches, fmt.Sprintf("%s ILIKE $%d", userFullNameExpr, argIndex+i))
+		args = append(args, "%"+escaper.Replace(word)+"%")
+	}
+	match := strings.Join(matches, " AND ")
+
+	condition := fmt.Sprintf(`(%s IN (SELECT u.id FROM users u WHERE %s)
+		OR %s IN (SELECT s.id FROM specialists s JOIN users u ON u.id = s.user_id WHERE %s))`,
+		clientColumn, match, specialistColumn, match)
+
+	return condition, args
+}
+
+// ListForExport возвращает очередную порцию записей с appointment_date в [From, To) вместе с именами участников
+func (r *AppointmentRepo) ListForExport(ctx context.Context, filter domain.AppointmentExportFilter) ([]domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.ListForExport")
+	defer span.End()
+
+	conditions := []string{"a.appointment_date >= $1", "a.appointment_date < $2"}
+	args := []interface{}{filter.From, filter.To}
+	argCount := 3
+
+	if filter.SpecialistID != nil {
+		conditions = append(conditions, fmt.Sprintf("a.specialist_id = $%d", argCount))
+		args = append(args, *filter.SpecialistID)
+		argCount++
+	}
+
+	if filter.AfterDate != nil {
+		conditions = append(conditions, fmt.Sprintf("(a.appointment_date, a.id) > ($%d, $%d)", argCount, argCount+1))
+		args = append(args, *filter.AfterDate, filter.AfterID)
+		argCount += 2
+	}
+
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.client_id, a.specialist_id, a.consultation_type, a.price, a.appointment_date,
+		       a.status, a.payment_id, a.created_at,
+		       u.first_name, u.last_name, su.first_name, su.last_name
+		FROM appointments a
+		JOIN users u ON a.client_id = u.id
+		JOIN specialists s ON a.specialist_id = s.id
+		JOIN users su ON s.user_id = su.id
+		WHERE %s
+		ORDER BY a.appointment_date, a.id
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), argCount)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки записей: %w", err)
+	}
+	defer rows.Close()
+
+	var appointments []domain.Appointment
+	for rows.Next() {
+		var a domain.Appointment
+		var clientFirstName, clientLastName, specialistFirstName, specialistLastName string
+		if err := rows.Scan(
+			&a.ID, &a.ClientID, &a.SpecialistID, &a.ConsultationType, &a.Price, &a.AppointmentDate,
+			&a.Status, &a.PaymentID, &a.CreatedAt,
+			&clientFirstName, &clientLastName, &specialistFirstName, &specialistLastName,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования записи для выгрузки: %w", err)
+		}
+		a.ClientName = strings.TrimSpace(clientFirstName + " " + clientLastName)
+		a.SpecialistName = strings.TrimSpace(specialistFirstName + " " + specialistLastName)
+		appointments = append(appointments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return appointments, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+// Клиент и специалист прочитали одну и ту же запись; второе сохранение с прежним updated_at
+// должно получить ErrVersionConflict, а не затереть первое
+func TestAppointmentUpdateLostUpdate(t *testing.T) {
+	db := testDB(t)
+	repo := NewAppointmentRepository(db)
+	ctx := context.Background()
+	specialistID := createTestSpecialist(t, db)
+	clientID := createTestUser(t, db, "client")
+
+	id, err := repo.Create(ctx, clientID, bookingDTO(specialistID, testSlot(72)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := read.UpdatedAt
+
+	paid := domain.AppointmentStatusPaid
+	if err := repo.Update(ctx, id, domain.UpdateAppointmentDTO{Status: &paid, ExpectedUpdatedAt: &seen}); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+
+	moved := testSlot(96)
+	err = repo.Update(ctx, id, domain.UpdateAppointmentDTO{AppointmentDate: &moved, ExpectedUpdatedAt: &seen})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale save: err = %v, want ErrVersionConflict", err)
+	}
+
+	current, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Status != paid || !current.AppointmentDate.Equal(read.AppointmentDate) {
+		t.Errorf("appointment = %s at %s, want the first save only", current.Status, current.AppointmentDate)
+	}
+
+	// Без ожидаемого updated_at проверка не выполняется
+	if err := repo.Update(ctx, id, domain.UpdateAppointmentDTO{AppointmentDate: &moved}); err != nil {
+		t.Errorf("unconditional save: %v", err)
+	}
+}
+
+func TestReviewUpdateLostUpdate(t *testing.T) {
+	db := testDB(t)
+	appointments := NewAppointmentRepository(db)
+	reviews := NewReviewRepository(db)
+	ctx := context.Background()
+	specialistID := createTestSpecialist(t, db)
+	clientID := createTestUser(t, db, "client")
+
+	appointmentID, err := appointments.Create(ctx, clientID, bookingDTO(specialistID, testSlot(-48)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	var reviewID int64
+	err = db.QueryRow(ctx, `
+		INSERT INTO reviews (client_id, specialist_id, appointment_id, rating, text, created_at, updated_at)
+		VALUES ($1, $2, $3, 4, 'Хорошая консультация', $4, $4)
+		RETURNING id
+	`, clientID, specialistID, appointmentID, seen).Scan(&reviewID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := 5, 2
+	if err := reviews.Update(ctx, reviewID, domain.UpdateReviewDTO{Rating: &first, ExpectedUpdatedAt: &seen}); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	if err := reviews.Update(ctx, reviewID, domain.UpdateReviewDTO{Rating: &second, ExpectedUpdatedAt: &seen}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale save: err = %v, want ErrVersionConflict", err)
+	}
+
+	var rating int
+	if err := db.QueryRow(ctx, "SELECT rating FROM reviews WHERE id = $1", reviewID).Scan(&rating); err != nil {
+		t.Fatal(err)
+	}
+	if rating != first {
+		t.Errorf("rating = %d, want %d from the first save", rating, first)
+	}
+}
+
+// Запись с видеозвонком сохраняется и после оплаты проходит проверку звонка
+func TestAppointmentVideoCall(t *testing.T) {
+	db := testDB(t)
+	repo := NewAppointmentRepository(db)
+	ctx := context.Background()
+	specialistID := createTestSpecialist(t, db)
+	clientID := createTestUser(t, db, "client")
+
+	dto := bookingDTO(specialistID, testSlot(120))
+	dto.CommunicationMethod = domain.CommunicationMethodVideoCall
+	id, err := repo.Create(ctx, clientID, dto)
+	if err != nil {
+		t.Fatalf("create video call appointment: %v", err)
+	}
+	if err := repo.UpdateStatus(ctx, id, domain.AppointmentStatusPaid); err != nil {
+		t.Fatal(err)
+	}
+
+	appointment, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appointment.CommunicationMethod != domain.CommunicationMethodVideoCall {
+		t.Fatalf("communication method = %s, want video_call", appointment.CommunicationMethod)
+	}
+	if reason := appointment.CallNotAllowedReason(appointment.AppointmentDate); reason != "" {
+		t.Errorf("call at the appointment time not allowed: %s", reason)
+	}
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type AuditRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) AuditRepository {
+	return &AuditRepo{db: db}
+}
+
+func (r *AuditRepo) Log(ctx context.Context, entry domain.AuditEntry) error {
+	ctx, span := tracer.Start(ctx, "AuditRepo.Log")
+	defer span.End()
+
+	createdAt := entry.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO audit_log (actor_id, action, entity_type, entity_id, old_value, new_value, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		entry.ActorID, entry.Action, entry.EntityType, entry.EntityID,
+		nullableJSON(entry.OldValue), nullableJSON(entry.NewValue), createdAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка записи в журнал аудита: %w", err)
+	}
+
+	return nil
+}
+
+// List возвращает записи журнала аудита по фильтру, новые первыми.
+// EndDate включает весь указанный день
+func (r *AuditRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, int, error) {
+	ctx, span := tracer.Start(ctx, "AuditRepo.List")
+	defer span.End()
+
+	countQuery := `SELECT COUNT(*) FROM audit_log WHERE 1=1`
+	selectQuery := `
+		SELECT id, actor_id, action, entity_type, entity_id, old_value, new_value, created_at
+		FROM audit_log
+		WHERE 1=1
+	`
+
+	var conditions string
+	var args []interface{}
+	argPos := 1
+
+	if filter.EntityType != nil {
+		conditions += fmt.Sprintf(" AND entity_type = $%d", argPos)
+		args = append(args, *filter.EntityType)
+		argPos++
+	}
+
+	if filter.EntityID != nil {
+		conditions += fmt.Sprintf(" AND entity_id = $%d", argPos)
+		args = append(args, *filter.EntityID)
+		argPos++
+	}
+
+	if filter.ActorID != nil {
+		conditions += fmt.Sprintf(" AND actor_id = $%d", argPos)
+		args = append(args, *filter.ActorID)
+		argPos++
+	}
+
+	if filter.Action != nil {
+		conditions += fmt.Sprintf(" AND action = $%d", argPos)
+		args = append(args, *filter.Action)
+		argPos++
+	}
+
+	if filter.StartDate != nil {
+		conditions += fmt.Sprintf(" AND created_at >= $%d", argPos)
+		args = append(args, *filter.StartDate)
+		argPos++
+	}
+
+	if filter.EndDate != nil {
+		conditions += fmt.Sprintf(" AND created_at < $%d", argPos)
+		args = append(args, filter.EndDate.AddDate(0, 0, 1))
+		argPos++
+	}
+
+	countQuery += conditions
+	selectQuery += conditions
+
+	selectQuery += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, args[:argPos-1]...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения количества записей аудита: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения журнала аудита: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0)
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var oldValue, newValue []byte
+		if err := rows.Scan(
+			&entry.ID, &entry.ActorID, &entry.Action, &entry.EntityType, &entry.EntityID,
+			&oldValue, &newValue, &entry.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("ошибка сканирования записи аудита: %w", err)
+		}
+		entry.OldValue = oldValue
+		entry.NewValue = newValue
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// nullableJSON передает пустое значение как NULL, а не как пустую строку
+func nullableJSON(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type BlockListRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewBlockListRepository(db *pgxpool.Pool) BlockListRepository {
+	return &BlockListRepo{db: db}
+}
+
+// Block добавляет клиента в список блокировки специалиста; повторная блокировка обновляет причину
+func (r *BlockListRepo) Block(ctx context.Context, specialistID, clientID int64, reason *string) error {
+	ctx, span := tracer.Start(ctx, "BlockListRepo.Block")
+	defer span.End()
+
+	query := `
+		INSERT INTO blocked_clients (specialist_id, client_id, reason, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (specialist_id, client_id) DO UPDATE SET reason = EXCLUDED.reason
+	`
+
+	if _, err := r.db.Exec(ctx, query, specialistID, clientID, reason, time.Now()); err != nil {
+		return fmt.Errorf("ошибка блокировки клиента: %w", err)
+	}
+
+	return nil
+}
+
+// Unblock возвращает false, если клиент не был заблокирован
+func (r *BlockListRepo) Unblock(ctx context.Context, specialistID, clientID int64) (bool, error) {
+	ctx, span := tracer.Start(ctx, "BlockListRepo.Unblock")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx,
+		"DELETE FROM blocked_clients WHERE specialist_id = $1 AND client_id = $2",
+		specialistID, clientID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("ошибка разблокировки клиента: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *BlockListRepo) List(ctx context.Context, specialistID int64) ([]domain.BlockedClient, error) {
+	ctx, span := tracer.Start(ctx, "BlockListRepo.List")
+	defer span.End()
+
+	query := `
+		SELECT b.id, b.specialist_id, b.client_id, u.first_name, u.last_name, b.reason, b.created_at
+		FROM blocked_clients b
+		JOIN users u ON b.client_id = u.id
+		WHERE b.specialist_id = $1
+		ORDER BY b.created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения заблокированных клиентов: %w", err)
+	}
+	defer rows.Close()
+
+	blocked := make([]domain.BlockedClient, 0)
+	for rows.Next() {
+		var b domain.BlockedClient
+		var firstName, lastName string
+		if err := rows.Scan(&b.ID, &b.SpecialistID, &b.ClientID, &firstName, &lastName, &b.Reason, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования заблокированного клиента: %w", err)
+		}
+		b.ClientName = strings.TrimSpace(firstName + " " + lastName)
+		blocked = append(blocked, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return blocked, nil
+}
+
+func (r *BlockListRepo) IsBlocked(ctx context.Context, specialistID, clientID int64) (bool, error) {
+	ctx, span := tracer.Start(ctx, "BlockListRepo.IsBlocked")
+	defer span.End()
+
+	var blocked bool
+	err := r.db.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM blocked_clients WHERE specialist_id = $1 AND client_id = $2)",
+		specialistID, clientID,
+	).Scan(&blocked)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки блокировки клиента: %w", err)
+	}
+
+	return blocked, nil
+}
+
+// IsBlockedBetweenUsers проверяет блокировку между двумя пользователями в любом направлении:
+// один из них заблокированный клиент, другой пользователь специалиста
+func (r *BlockListRepo) IsBlockedBetweenUsers(ctx context.Context, userA, userB int64) (bool, error) {
+	ctx, span := tracer.Start(ctx, "BlockListRepo.IsBlockedBetweenUsers")
+	defer span.End()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM blocked_clients b
+			JOIN specialists s ON b.specialist_id = s.id
+			WHERE (b.client_id = $1 AND s.user_id = $2) OR (b.client_id = $2 AND s.user_id = $1)
+		)
+	`
+
+	var blocked bool
+	if err := r.db.QueryRow(ctx, query, userA, userB).Scan(&blocked); err != nil {
+		return false, fmt.Errorf("ошибка проверки блокировки: %w", err)
+	}
+
+	return blocked, nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type CallRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewCallRepository(db *pgxpool.Pool) CallRepository {
+	return &CallRepo{db: db}
+}
+
+// SaveSession сохраняет завершенный звонок. ID сессии может повториться, если звонок возобновили после
+// завершения, поэтому повторное сохранение обновляет время окончания и длительность; принятый однажды
+// звонок остается принятым
+func (r *CallRepo) SaveSession(ctx context.Context, call domain.CallRecord) error {
+	ctx, span := tracer.Start(ctx, "CallRepo.SaveSession")
+	defer span.End()
+
+	query := `
+		INSERT INTO call_sessions (id, client_id, specialist_user_id, caller_id, appointment_id, answered,
+			started_at, ended_at, duration_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			ended_at = EXCLUDED.ended_at,
+			duration_seconds = EXCLUDED.duration_seconds,
+			answered = call_sessions.answered OR EXCLUDED.answered
+	`
+
+	var callerID *int64
+	if call.CallerID != 0 {
+		callerID = &call.CallerID
+	}
+
+	_, err := r.db.Exec(ctx, query,
+		call.ID, call.ClientID, call.SpecialistUserID, callerID, call.AppointmentID, call.Answered,
+		call.StartedAt, call.EndedAt, call.DurationSeconds)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения звонка: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CallRepo) GetSession(ctx context.Context, id string) (*domain.CallRecord, error) {
+	ctx, span := tracer.Start(ctx, "CallRepo.GetSession")
+	defer span.End()
+
+	query := `
+		SELECT id, client_id, specialist_user_id, COALESCE(caller_id, 0), appointment_id, answered,
+			started_at, ended_at, duration_seconds
+		FROM call_sessions
+		WHERE id = $1
+	`
+
+	var call domain.CallRecord
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&call.ID,
+		&call.ClientID,
+		&call.SpecialistUserID,
+		&call.CallerID,
+		&call.AppointmentID,
+		&call.Answered,
+		&call.StartedAt,
+		&call.EndedAt,
+		&call.DurationSeconds,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCallSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения звонка: %w", err)
+	}
+
+	return &call, nil
+}
+
+// CreateFeedback сохраняет оценку звонка и заполняет ее ID и время создания.
+// Если пользователь уже оценил этот звонок, возвращается ErrCallFeedbackExists
+func (r *CallRepo) CreateFeedback(ctx context.Context, feedback *domain.CallFeedback) error {
+	ctx, span := tracer.Start(ctx, "CallRepo.CreateFeedback")
+	defer span.End()
+
+	issues := make([]string, 0, len(feedback.Issues))
+	for _, issue := range feedback.Issues {
+		issues = append(issues, string(issue))
+	}
+
+	query := `
+		INSERT INTO call_feedback (call_session_id, user_id, quality, issues, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, feedback.CallSessionID, feedback.UserID, feedback.Quality, issues, feedback.Comment).
+		Scan(&feedback.ID, &feedback.CreatedAt)
+	if _, ok := uniqueViolation(err); ok {
+		return ErrCallFeedbackExists
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения оценки звонка: %w", err)
+	}
+
+	return nil
+}
+
+// GetFeedbackSummary собирает оценки звонков, оставленные в интервале [from, to)
+func (r *CallRepo) GetFeedbackSummary(ctx context.Context, from, to time.Time) (*domain.CallFeedbackSummary, error) {
+	ctx, span := tracer.Start(ctx, "CallRepo.GetFeedbackSummary")
+	defer span.End()
+
+	summary := &domain.CallFeedbackSummary{
+		From:          from,
+		To:            to,
+		QualityCounts: make(map[int]int),
+		IssueCounts:   make(map[domain.CallIssue]int),
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT quality, COUNT(*)
+		FROM call_feedback
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY quality
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета оценок звонков: %w", err)
+	}
+	defer rows.Close()
+
+	var qualitySum int
+	for rows.Next() {
+		var quality, count int
+		if err := rows.Scan(&quality, &count); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования оценок звонков: %w", err)
+		}
+		summary.QualityCounts[quality] = count
+		summary.Total += count
+		qualitySum += quality * count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке оценок звонков: %w", err)
+	}
+
+	if summary.Total > 0 {
+		summary.AverageQuality = float64(qualitySum) / float64(summary.Total)
+	}
+
+	issueRows, err := r.db.Query(ctx, `
+		SELECT issue, COUNT(*)
+		FROM call_feedback, unnest(issues) AS issue
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY issue
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета проблем со связью: %w", err)
+	}
+	defer issueRows.Close()
+
+	for issueRows.Next() {
+		var issue string
+		var count int
+		if err := issueRows.Scan(&issue, &count); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования проблем со связью: %w", err)
+		}
+		summary.IssueCounts[domain.CallIssue(issue)] = count
+	}
+	if err := issueRows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке проблем со связью: %w", err)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+// Доля принятых звонков считается только по звонкам клиентов: звонки специалиста и звонки,
+// сохраненные без звонившего, не учитываются
+func TestSpecialistCallPickupRate(t *testing.T) {
+	db := testDB(t)
+	calls := NewCallRepository(db)
+	chats := NewChatRepository(db)
+	ctx := context.Background()
+	specialistID := createTestSpecialist(t, db)
+	clientID := createTestUser(t, db, "client")
+
+	var specialistUserID int64
+	if err := db.QueryRow(ctx, `SELECT user_id FROM specialists WHERE id = $1`, specialistID).Scan(&specialistUserID); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := chats.GetSpecialistResponseStats(ctx, specialistID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.CallPickupRate != nil {
+		t.Errorf("pickup rate without calls = %v, want nil", *stats.CallPickupRate)
+	}
+
+	suffix := uniqueSuffix()
+	now := time.Now()
+	for _, call := range []domain.CallRecord{
+		{ID: "answered-" + suffix, CallerID: clientID, Answered: true, DurationSeconds: 60},
+		{ID: "missed-" + suffix, CallerID: clientID},
+		{ID: "rejected-" + suffix, CallerID: clientID},
+		{ID: "outgoing-" + suffix, CallerID: specialistUserID},
+		{ID: "legacy-" + suffix, Answered: false},
+	} {
+		call.ClientID, call.SpecialistUserID = clientID, specialistUserID
+		call.StartedAt, call.EndedAt = now.Add(-time.Minute), now
+		if err := calls.SaveSession(ctx, call); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Звонок, возобновленный после обрыва, сохраняется повторно и остается принятым
+	if err := calls.SaveSession(ctx, domain.CallRecord{ID: "answered-" + suffix, ClientID: clientID, SpecialistUserID: specialistUserID,
+		CallerID: clientID, StartedAt: now.Add(-time.Minute), EndedAt: now, DurationSeconds: 90}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err = chats.GetSpecialistResponseStats(ctx, specialistID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.CallPickupRate == nil || *stats.CallPickupRate < 0.33 || *stats.CallPickupRate > 0.34 {
+		t.Errorf("pickup rate = %v, want 1 of 3 calls from the client", stats.CallPickupRate)
+	}
+
+	saved, err := calls.GetSession(ctx, "answered-"+suffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.Answered || saved.CallerID != clientID || saved.DurationSeconds != 90 {
+		t.Errorf("saved call = %+v, want the answered call with the new duration", saved)
+	}
+}
@@ -2,12 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"laps/internal/domain"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -124,9 +126,18 @@ func (r *ChatRepositoryImpl) GetChatSessionByAppointmentID(ctx context.Context,
 }
 
 func (r *ChatRepositoryImpl) ListChatSessions(ctx context.Context, filter domain.ChatSessionFilter) ([]domain.ChatSession, error) {
-	var conditions []string
-	var args []interface{}
-	argCount := 1
+	return r.listChatSessions(ctx, nil, filter)
+}
+
+// ListChatSessionsByUserID lists sessions where the user takes part either as the client
+// or through their specialist profile
+func (r *ChatRepositoryImpl) ListChatSessionsByUserID(ctx context.Context, userID int64, filter domain.ChatSessionFilter) ([]domain.ChatSession, error) {
+	return r.listChatSessions(ctx, &userID, filter)
+}
+
+func (r *ChatRepositoryImpl) listChatSessions(ctx context.Context, participantUserID *int64, filter domain.ChatSessionFilter) ([]domain.ChatSession, error) {
+	conditions, args := chatSessionConditions(participantUserID, filter)
+	argCount := len(args) + 1
 
 	baseQuery := `
 		SELECT 
@@ -141,36 +152,6 @@ func (r *ChatRepositoryImpl) ListChatSessions(ctx context.Context, filter domain
 		LEFT JOIN users us ON s.user_id = us.id
 		LEFT JOIN specializations sp ON cs.specialization_id = sp.id`
 
-	if filter.ClientID != nil {
-		conditions = append(conditions, fmt.Sprintf("cs.client_id = $%d", argCount))
-		args = append(args, *filter.ClientID)
-		argCount++
-	}
-
-	if filter.SpecialistID != nil {
-		conditions = append(conditions, fmt.Sprintf("cs.specialist_id = $%d", argCount))
-		args = append(args, *filter.SpecialistID)
-		argCount++
-	}
-
-	if filter.SpecializationID != nil {
-		conditions = append(conditions, fmt.Sprintf("cs.specialization_id = $%d", argCount))
-		args = append(args, *filter.SpecializationID)
-		argCount++
-	}
-
-	if filter.Status != nil {
-		conditions = append(conditions, fmt.Sprintf("cs.status = $%d", argCount))
-		args = append(args, *filter.Status)
-		argCount++
-	}
-
-	if filter.AppointmentID != nil {
-		conditions = append(conditions, fmt.Sprintf("cs.appointment_id = $%d", argCount))
-		args = append(args, *filter.AppointmentID)
-		argCount++
-	}
-
 	query := baseQuery
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
@@ -226,11 +207,41 @@ func (r *ChatRepositoryImpl) ListChatSessions(ctx context.Context, filter domain
 }
 
 func (r *ChatRepositoryImpl) CountChatSessions(ctx context.Context, filter domain.ChatSessionFilter) (int64, error) {
+	return r.countChatSessions(ctx, nil, filter)
+}
+
+// CountChatSessionsByUserID counts sessions matched by ListChatSessionsByUserID
+func (r *ChatRepositoryImpl) CountChatSessionsByUserID(ctx context.Context, userID int64, filter domain.ChatSessionFilter) (int64, error) {
+	return r.countChatSessions(ctx, &userID, filter)
+}
+
+func (r *ChatRepositoryImpl) countChatSessions(ctx context.Context, participantUserID *int64, filter domain.ChatSessionFilter) (int64, error) {
+	conditions, args := chatSessionConditions(participantUserID, filter)
+
+	query := "SELECT COUNT(*) FROM chat_sessions cs"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	err := r.db.QueryRow(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// chatSessionConditions builds WHERE conditions for chat session queries. When participantUserID
+// is set, only sessions where that user is the client or the specialist are matched
+func chatSessionConditions(participantUserID *int64, filter domain.ChatSessionFilter) ([]string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 	argCount := 1
 
-	baseQuery := "SELECT COUNT(*) FROM chat_sessions cs"
+	if participantUserID != nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"(cs.client_id = $%d OR cs.specialist_id IN (SELECT id FROM specialists WHERE user_id = $%d))",
+			argCount, argCount))
+		args = append(args, *participantUserID)
+		argCount++
+	}
 
 	if filter.ClientID != nil {
 		conditions = append(conditions, fmt.Sprintf("cs.client_id = $%d", argCount))
@@ -262,14 +273,7 @@ func (r *ChatRepositoryImpl) CountChatSessions(ctx context.Context, filter domai
 		argCount++
 	}
 
-	query := baseQuery
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	var count int64
-	err := r.db.QueryRow(ctx, query, args...).Scan(&count)
-	return count, err
+	return conditions, args
 }
 
 func (r *ChatRepositoryImpl) UpdateChatSession(ctx context.Context, id int64, dto domain.UpdateChatSessionDTO) (*domain.ChatSession, error) {
@@ -452,6 +456,61 @@ func (r *ChatRepositoryImpl) ListChatMessages(ctx context.Context, filter domain
 	return messages, rows.Err()
 }
 
+// StreamChatMessages calls fn for every message of the session, oldest first,
+// without loading the whole history into memory
+func (r *ChatRepositoryImpl) StreamChatMessages(ctx context.Context, sessionID int64, fn func(message domain.ChatMessage) error) error {
+	query := `
+		SELECT 
+			cm.id, cm.session_id, cm.sender_id, cm.message_type, cm.content, 
+			cm.file_url, cm.file_name, cm.file_size, cm.is_read, cm.read_at, 
+			cm.created_at, cm.updated_at,
+			NULLIF(TRIM(CONCAT(u.first_name, ' ', u.last_name)), '') as sender_name,
+			CASE 
+				WHEN cs.client_id = cm.sender_id THEN 'client'
+				WHEN cs.specialist_id = cm.sender_id THEN 'specialist'
+				ELSE 'system'
+			END as sender_role
+		FROM chat_messages cm
+		LEFT JOIN users u ON cm.sender_id = u.id
+		LEFT JOIN chat_sessions cs ON cm.session_id = cs.id
+		WHERE cm.session_id = $1
+		ORDER BY cm.created_at ASC, cm.id ASC`
+
+	rows, err := r.db.Query(ctx, query, sessionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message domain.ChatMessage
+		err := rows.Scan(
+			&message.ID,
+			&message.SessionID,
+			&message.SenderID,
+			&message.Type,
+			&message.Content,
+			&message.FileURL,
+			&message.FileName,
+			&message.FileSize,
+			&message.IsRead,
+			&message.ReadAt,
+			&message.CreatedAt,
+			&message.UpdatedAt,
+			&message.SenderName,
+			&message.SenderRole,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(message); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (r *ChatRepositoryImpl) CountChatMessages(ctx context.Context, filter domain.ChatMessageFilter) (int64, error) {
 	var conditions []string
 	var args []interface{}
@@ -493,6 +552,101 @@ func (r *ChatRepositoryImpl) CountChatMessages(ctx context.Context, filter domai
 	return count, err
 }
 
+// GetChatMessageByID returns a message without the joined sender fields
+func (r *ChatRepositoryImpl) GetChatMessageByID(ctx context.Context, id int64) (*domain.ChatMessage, error) {
+	query := `
+		SELECT id, session_id, sender_id, message_type, content, file_url, file_name, file_size,
+		       is_read, read_at, created_at, updated_at
+		FROM chat_messages
+		WHERE id = $1`
+
+	var message domain.ChatMessage
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&message.ID,
+		&message.SessionID,
+		&message.SenderID,
+		&message.Type,
+		&message.Content,
+		&message.FileURL,
+		&message.FileName,
+		&message.FileSize,
+		&message.IsRead,
+		&message.ReadAt,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrChatMessageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat message: %w", err)
+	}
+
+	return &message, nil
+}
+
+// Reactions
+
+// AddReaction stores the user's reaction; adding the same emoji twice is a no-op
+func (r *ChatRepositoryImpl) AddReaction(ctx context.Context, messageID, userID int64, emoji string) error {
+	query := `
+		INSERT INTO chat_message_reactions (message_id, user_id, emoji)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (message_id, user_id, emoji) DO NOTHING`
+
+	if _, err := r.db.Exec(ctx, query, messageID, userID, emoji); err != nil {
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+	return nil
+}
+
+// RemoveReaction deletes the user's reaction and reports whether it existed
+func (r *ChatRepositoryImpl) RemoveReaction(ctx context.Context, messageID, userID int64, emoji string) (bool, error) {
+	query := `DELETE FROM chat_message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3`
+
+	tag, err := r.db.Exec(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetReactionCounts aggregates reactions of the given messages by emoji.
+// Messages without reactions are absent from the result
+func (r *ChatRepositoryImpl) GetReactionCounts(ctx context.Context, messageIDs []int64) (map[int64]map[string]int, error) {
+	counts := make(map[int64]map[string]int)
+	if len(messageIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT message_id, emoji, COUNT(*)
+		FROM chat_message_reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji`
+
+	rows, err := r.db.Query(ctx, query, messageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID int64
+		var emoji string
+		var count int
+		if err := rows.Scan(&messageID, &emoji, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction: %w", err)
+		}
+		if counts[messageID] == nil {
+			counts[messageID] = make(map[string]int)
+		}
+		counts[messageID][emoji] = count
+	}
+
+	return counts, rows.Err()
+}
+
 func (r *ChatRepositoryImpl) MarkMessagesAsRead(ctx context.Context, sessionID int64, userID int64) error {
 	query := `
 		UPDATE chat_messages 
@@ -512,4 +666,108 @@ func (r *ChatRepositoryImpl) GetUnreadMessageCount(ctx context.Context, sessionI
 	var count int64
 	err := r.db.QueryRow(ctx, query, sessionID, userID).Scan(&count)
 	return count, err
-} 
\ No newline at end of file
+}
+
+// Statistics
+
+// GetSpecialistResponseStats aggregates, over all chat sessions of the specialist,
+// the delay between the client's first message and the specialist's first reply,
+// and the share of persisted calls placed by clients that the specialist answered.
+// Calls saved before the caller was recorded are not counted.
+func (r *ChatRepositoryImpl) GetSpecialistResponseStats(ctx context.Context, specialistID int64) (*domain.SpecialistResponseStats, error) {
+	query := `
+		WITH client_first AS (
+			SELECT cs.id AS session_id, s.user_id AS specialist_user_id, MIN(cm.created_at) AS first_at
+			FROM chat_sessions cs
+			JOIN specialists s ON cs.specialist_id = s.id
+			JOIN chat_messages cm ON cm.session_id = cs.id AND cm.sender_id = cs.client_id
+			WHERE cs.specialist_id = $1 AND cm.message_type <> 'system'
+			GROUP BY cs.id, s.user_id
+		),
+		responses AS (
+			SELECT cf.session_id, cf.first_at,
+				(
+					SELECT MIN(cm.created_at)
+					FROM chat_messages cm
+					WHERE cm.session_id = cf.session_id
+						AND cm.sender_id = cf.specialist_user_id
+						AND cm.message_type <> 'system'
+						AND cm.created_at >= cf.first_at
+				) AS reply_at
+			FROM client_first cf
+		)
+		SELECT
+			COUNT(*),
+			COUNT(reply_at),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (reply_at - first_at))),
+			(
+				SELECT AVG(CASE WHEN cl.answered THEN 1 ELSE 0 END)::float8
+				FROM call_sessions cl
+				JOIN specialists s ON s.user_id = cl.specialist_user_id
+				WHERE s.id = $1 AND cl.caller_id = cl.client_id
+			)
+		FROM responses`
+
+	stats := domain.SpecialistResponseStats{
+		SpecialistID: specialistID,
+		CalculatedAt: time.Now(),
+	}
+	err := r.db.QueryRow(ctx, query, specialistID).Scan(
+		&stats.TotalSessions,
+		&stats.RespondedSessions,
+		&stats.MedianFirstResponseSeconds,
+		&stats.CallPickupRate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// GetSessionStats counts the messages of each participant of a chat session and
+// averages the delay between consecutive messages from different senders.
+func (r *ChatRepositoryImpl) GetSessionStats(ctx context.Context, sessionID int64) (*domain.ChatSessionStats, error) {
+	query := `
+		WITH session AS (
+			SELECT cs.id, cs.client_id, s.user_id AS specialist_user_id, cs.started_at, cs.ended_at
+			FROM chat_sessions cs
+			JOIN specialists s ON cs.specialist_id = s.id
+			WHERE cs.id = $1
+		),
+		messages AS (
+			SELECT cm.sender_id, cm.created_at,
+				LAG(cm.sender_id) OVER (ORDER BY cm.created_at, cm.id) AS prev_sender_id,
+				LAG(cm.created_at) OVER (ORDER BY cm.created_at, cm.id) AS prev_created_at
+			FROM chat_messages cm
+			WHERE cm.session_id = $1 AND cm.message_type <> 'system'
+		)
+		SELECT
+			COUNT(m.created_at),
+			COUNT(m.created_at) FILTER (WHERE m.sender_id = se.client_id),
+			COUNT(m.created_at) FILTER (WHERE m.sender_id = se.specialist_user_id),
+			COALESCE(
+				EXTRACT(EPOCH FROM (COALESCE(se.ended_at, NOW()) - se.started_at)),
+				EXTRACT(EPOCH FROM (MAX(m.created_at) - MIN(m.created_at))),
+				0
+			)::bigint,
+			(AVG(EXTRACT(EPOCH FROM (m.created_at - m.prev_created_at)))
+				FILTER (WHERE m.prev_sender_id <> m.sender_id))::float8
+		FROM session se
+		LEFT JOIN messages m ON TRUE
+		GROUP BY se.id, se.client_id, se.specialist_user_id, se.started_at, se.ended_at`
+
+	stats := domain.ChatSessionStats{SessionID: sessionID}
+	err := r.db.QueryRow(ctx, query, sessionID).Scan(
+		&stats.TotalMessages,
+		&stats.ClientMessages,
+		&stats.SpecialistMessages,
+		&stats.DurationSeconds,
+		&stats.AvgResponseTimeSeconds,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
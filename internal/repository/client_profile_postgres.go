@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type ClientProfileRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewClientProfileRepository(db *pgxpool.Pool) ClientProfileRepository {
+	return &ClientProfileRepo{db: db}
+}
+
+// Get возвращает nil, если клиент еще не заполнял профиль
+func (r *ClientProfileRepo) Get(ctx context.Context, userID int64) (*domain.ClientProfile, error) {
+	ctx, span := tracer.Start(ctx, "ClientProfileRepo.Get")
+	defer span.End()
+
+	query := `
+		SELECT user_id, birth_date, gender, city, preferred_contact_method, updated_at
+		FROM client_profiles
+		WHERE user_id = $1
+	`
+
+	var profile domain.ClientProfile
+	var birthDate *time.Time
+	var updatedAt time.Time
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&profile.UserID, &birthDate, &profile.Gender, &profile.City, &profile.PreferredContactMethod, &updatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения профиля клиента: %w", err)
+	}
+
+	if birthDate != nil {
+		formatted := birthDate.Format("2006-01-02")
+		profile.BirthDate = &formatted
+	}
+	profile.UpdatedAt = &updatedAt
+
+	return &profile, nil
+}
+
+// Upsert сохраняет профиль клиента целиком; BirthDate должна быть уже проверена
+func (r *ClientProfileRepo) Upsert(ctx context.Context, profile domain.ClientProfile) error {
+	ctx, span := tracer.Start(ctx, "ClientProfileRepo.Upsert")
+	defer span.End()
+
+	var birthDate *time.Time
+	if profile.BirthDate != nil {
+		parsed, err := time.Parse("2006-01-02", *profile.BirthDate)
+		if err != nil {
+			return fmt.Errorf("некорректная дата рождения: %w", err)
+		}
+		birthDate = &parsed
+	}
+
+	query := `
+		INSERT INTO client_profiles (user_id, birth_date, gender, city, preferred_contact_method, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET birth_date = EXCLUDED.birth_date, gender = EXCLUDED.gender, city = EXCLUDED.city,
+		    preferred_contact_method = EXCLUDED.preferred_contact_method, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		profile.UserID, birthDate, profile.Gender, profile.City, profile.PreferredContactMethod, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения профиля клиента: %w", err)
+	}
+
+	return nil
+}
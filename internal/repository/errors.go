@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrEmailTaken   = errors.New("email уже зарегистрирован")
+	ErrPhoneTaken   = errors.New("телефон уже используется")
+	ErrUserNotFound = errors.New("пользователь не найден")
+
+	ErrSlotTaken    = errors.New("выбранный слот времени уже занят")
+	ErrHoldLimit    = errors.New("превышено количество активных удержаний слотов")
+	ErrHoldNotFound = errors.New("удержание слота не найдено или истекло")
+
+	ErrAppointmentClosed  = errors.New("запись уже завершена или отменена")
+	ErrAppointmentNotPaid = errors.New("запись не оплачена, консультация уже начата или завершена")
+	ErrNotCheckedIn       = errors.New("клиент еще не отметился")
+
+	ErrSpecialistNotFound = errors.New("специалист не найден")
+	ErrSpecialistExists   = errors.New("у пользователя уже есть профиль специалиста")
+
+	ErrSpecializationCycle    = errors.New("специализация не может быть вложена в саму себя или своего потомка")
+	ErrSpecializationNotFound = errors.New("специализация не найдена")
+
+	ErrUnknownSpecialistType = errors.New("неизвестный тип специалиста")
+
+	ErrReviewMediaNotFound = errors.New("изображение не найдено или уже приложено к другому отзыву")
+	ErrReviewMediaLimit    = errors.New("достигнуто максимальное число изображений отзыва")
+
+	ErrVersionConflict = errors.New("данные были изменены другим пользователем")
+
+	ErrInviteNotFound = errors.New("приглашение не найдено или уже использовано")
+	ErrInviteExpired  = errors.New("срок действия приглашения истек")
+
+	ErrPasswordResetNotFound = errors.New("ссылка для восстановления пароля недействительна или уже использована")
+	ErrPasswordResetExpired  = errors.New("срок действия ссылки для восстановления пароля истек")
+
+	ErrChatMessageNotFound = errors.New("сообщение не найдено")
+
+	ErrCallSessionNotFound = errors.New("звонок не найден")
+	ErrCallFeedbackExists  = errors.New("оценка звонка уже оставлена")
+
+	ErrWebhookEndpointNotFound = errors.New("адрес webhook не найден")
+)
+
+// Код ошибки PostgreSQL unique_violation
+const uniqueViolationCode = "23505"
+
+// Код ошибки PostgreSQL foreign_key_violation
+const foreignKeyViolationCode = "23503"
+
+// foreignKeyViolation возвращает имя нарушенного внешнего ключа
+func foreignKeyViolation(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+// uniqueViolation возвращает имя нарушенного ограничения уникальности
+func uniqueViolation(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+// userUniqueError переводит нарушение уникальности email или телефона пользователя в ErrEmailTaken или ErrPhoneTaken
+func userUniqueError(err error) error {
+	constraint, ok := uniqueViolation(err)
+	if !ok {
+		return nil
+	}
+
+	switch constraint {
+	case "users_email_key":
+		return ErrEmailTaken
+	case "users_phone_key":
+		return ErrPhoneTaken
+	default:
+		return nil
+	}
+}
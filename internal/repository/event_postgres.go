@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+const insertEventQuery = `
+	INSERT INTO events (aggregate_type, aggregate_id, event_type, payload, actor_id, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type EventRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewEventRepository(db *pgxpool.Pool) EventRepository {
+	return &EventRepo{db: db}
+}
+
+func (r *EventRepo) Append(ctx context.Context, event domain.Event) error {
+	ctx, span := tracer.Start(ctx, "EventRepo.Append")
+	defer span.End()
+
+	createdAt := event.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err := r.db.Exec(ctx, insertEventQuery,
+		event.AggregateType, event.AggregateID, event.EventType, string(event.Payload), event.ActorID, createdAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка записи события: %w", err)
+	}
+
+	return nil
+}
+
+// GetByAggregate возвращает события агрегата в порядке их появления и общее количество событий
+func (r *EventRepo) GetByAggregate(ctx context.Context, aggregateType domain.EventAggregateType, aggregateID int64, limit, offset int) ([]domain.Event, int, error) {
+	ctx, span := tracer.Start(ctx, "EventRepo.GetByAggregate")
+	defer span.End()
+
+	var total int
+	err := r.db.QueryRow(ctx,
+		"SELECT COUNT(*) FROM events WHERE aggregate_type = $1 AND aggregate_id = $2",
+		aggregateType, aggregateID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка подсчета событий: %w", err)
+	}
+
+	query := `
+		SELECT id, aggregate_type, aggregate_id, event_type, payload, actor_id, created_at
+		FROM events
+		WHERE aggregate_type = $1 AND aggregate_id = $2
+		ORDER BY created_at, id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(ctx, query, aggregateType, aggregateID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения событий: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]domain.Event, 0)
+	for rows.Next() {
+		var event domain.Event
+		var payload []byte
+		if err := rows.Scan(
+			&event.ID, &event.AggregateType, &event.AggregateID, &event.EventType,
+			&payload, &event.ActorID, &event.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("ошибка сканирования события: %w", err)
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка при итерации по событиям: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// appendEventTx пишет событие в той же транзакции, что и изменение агрегата
+func appendEventTx(ctx context.Context, tx pgx.Tx, event domain.Event) error {
+	_, err := tx.Exec(ctx, insertEventQuery,
+		event.AggregateType, event.AggregateID, event.EventType, string(event.Payload), event.ActorID, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка записи события: %w", err)
+	}
+
+	return nil
+}
+
+// jsonDiff сравнивает два JSON-объекта поле за полем и возвращает только изменившиеся поля
+// в виде {"поле": {"old": ..., "new": ...}}. Если изменений нет, возвращает nil
+func jsonDiff(oldValue, newValue interface{}) (json.RawMessage, error) {
+	oldFields, err := jsonFields(oldValue)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := jsonFields(newValue)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]domain.EventFieldChange)
+	for name, newField := range newFields {
+		oldField, ok := oldFields[name]
+		if !ok {
+			oldField = json.RawMessage("null")
+		}
+		if !bytes.Equal(oldField, newField) {
+			diff[name] = domain.EventFieldChange{Old: oldField, New: newField}
+		}
+	}
+	for name, oldField := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			diff[name] = domain.EventFieldChange{Old: oldField, New: json.RawMessage("null")}
+		}
+	}
+
+	if len(diff) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(diff)
+}
+
+func jsonFields(value interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации состояния для события: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("ошибка разбора состояния для события: %w", err)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type ExternalCalendarRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExternalCalendarRepository(db *pgxpool.Pool) ExternalCalendarRepository {
+	return &ExternalCalendarRepo{db: db}
+}
+
+func (r *ExternalCalendarRepo) SetFeed(ctx context.Context, specialistID int64, url string) error {
+	ctx, span := tracer.Start(ctx, "ExternalCalendarRepo.SetFeed")
+	defer span.End()
+
+	query := `
+		INSERT INTO external_calendar_feeds (specialist_id, url, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (specialist_id) DO UPDATE
+		SET url = EXCLUDED.url, last_error = NULL, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.Exec(ctx, query, specialistID, url, time.Now()); err != nil {
+		return fmt.Errorf("ошибка сохранения внешнего календаря: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFeed отключает внешний календарь и удаляет импортированную из него занятость
+func (r *ExternalCalendarRepo) DeleteFeed(ctx context.Context, specialistID int64) error {
+	ctx, span := tracer.Start(ctx, "ExternalCalendarRepo.DeleteFeed")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM external_busy_blocks WHERE specialist_id = $1", specialistID); err != nil {
+		return fmt.Errorf("ошибка удаления занятости из внешнего календаря: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM external_calendar_feeds WHERE specialist_id = $1", specialistID); err != nil {
+		return fmt.Errorf("ошибка удаления внешнего календаря: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// GetFeed возвращает nil, если внешний календарь не подключен
+func (r *ExternalCalendarRepo) GetFeed(ctx context.Context, specialistID int64) (*domain.ExternalCalendarFeed, error) {
+	ctx, span := tracer.Start(ctx, "ExternalCalendarRepo.GetFeed")
+	defer span.End()
+
+	query := `
+		SELECT specialist_id, url, last_synced_at, last_error, created_at, updated_at
+		FROM external_calendar_feeds
+		WHERE specialist_id = $1
+	`
+
+	var feed domain.ExternalCalendarFeed
+	err := r.db.QueryRow(ctx, query, specialistID).Scan(
+		&feed.SpecialistID, &feed.URL, &feed.LastSyncedAt, &feed.LastError, &feed.CreatedAt, &feed.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения внешнего календаря: %w", err)
+	}
+
+	return &feed, nil
+}
+
+func (r *ExternalCalendarRepo) ListFeeds(ctx context.Context) ([]domain.ExternalCalendarFeed, error) {
+	ctx, span := tracer.Start(ctx, "ExternalCalendarRepo.ListFeeds")
+	defer span.End()
+
+	query := `
+		SELECT specialist_id, url, last_synced_at, last_error, created_at, updated_at
+		FROM external_calendar_feeds
+		ORDER BY specialist_id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения внешних календарей: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []domain.ExternalCalendarFeed
+	for rows.Next() {
+		var feed domain.ExternalCalendarFeed
+		if err := rows.Scan(
+			&feed.SpecialistID, &feed.URL, &feed.LastSyncedAt, &feed.LastError, &feed.CreatedAt, &feed.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования внешнего календаря: %w", err)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// ReplaceBlocks атомарно заменяет импортированную занятость специалиста и отмечает успешную синхронизацию
+func (r *ExternalCalendarRepo) ReplaceBlocks(ctx context.Context, specialistID int64, blocks []domain.ExternalBusyBlock) error {
+	ctx, span := tracer.Start(ctx, "ExternalCalendarRepo.ReplaceBlocks")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM external_busy_blocks WHERE specialist_id = $1", specialistID); err != nil {
+		return fmt.Errorf("ошибка удаления занятости из внешнего календаря: %w", err)
+	}
+
+	now := time.Now()
+	rows := make([][]interface{}, 0, len(blocks))
+	for _, block := range blocks {
+		rows = append(rows, []interface{}{specialistID, block.UID, block.StartsAt, block.EndsAt, now})
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"external_busy_blocks"},
+		[]string{"specialist_id", "uid", "starts_at", "ends_at", "imported_at"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения занятости из внешнего календаря: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE external_calendar_feeds SET last_synced_at = $1, last_error = NULL WHERE specialist_id = $2",
+		now, specialistID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления состояния синхронизации: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSyncFailed сохраняет ошибку синхронизации, не трогая ранее импортированную занятость
+func (r *ExternalCalendarRepo) MarkSyncFailed(ctx context.Context, specialistID int64, message string) error {
+	ctx, span := tracer.Start(ctx, "ExternalCalendarRepo.MarkSyncFailed")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx,
+		"UPDATE external_calendar_feeds SET last_error = $1 WHERE specialist_id = $2",
+		message, specialistID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения ошибки синхронизации: %w", err)
+	}
+
+	return nil
+}
+
+// ListBlocks возвращает интервалы занятости, пересекающие [from, to)
+func (r *ExternalCalendarRepo) ListBlocks(ctx context.Context, specialistID int64, from, to time.Time) ([]domain.ExternalBusyBlock, error) {
+	ctx, span := tracer.Start(ctx, "ExternalCalendarRepo.ListBlocks")
+	defer span.End()
+
+	query := `
+		SELECT id, specialist_id, uid, starts_at, ends_at
+		FROM external_busy_blocks
+		WHERE specialist_id = $1 AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения занятости из внешнего календаря: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []domain.ExternalBusyBlock
+	for rows.Next() {
+		var block domain.ExternalBusyBlock
+		if err := rows.Scan(&block.ID, &block.SpecialistID, &block.UID, &block.StartsAt, &block.EndsAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования занятости: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type InviteRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewInviteRepository(db *pgxpool.Pool) InviteRepository {
+	return &InviteRepo{db: db}
+}
+
+// Upsert сохраняет приглашение пользователя, заменяя предыдущее: старый токен перестает действовать
+func (r *InviteRepo) Upsert(ctx context.Context, invite domain.UserInvite) error {
+	ctx, span := tracer.Start(ctx, "InviteRepo.Upsert")
+	defer span.End()
+
+	query := `
+		INSERT INTO user_invites (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.Exec(ctx, query, invite.UserID, invite.TokenHash, invite.ExpiresAt, invite.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения приглашения: %w", err)
+	}
+
+	return nil
+}
+
+// Accept задает пароль пользователю по хешу токена приглашения и удаляет приглашение в одной транзакции,
+// поэтому токен можно использовать только один раз. Возвращает ID пользователя
+func (r *InviteRepo) Accept(ctx context.Context, tokenHash, passwordHash string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "InviteRepo.Accept")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int64
+	var expiresAt time.Time
+	err = tx.QueryRow(ctx, `DELETE FROM user_invites WHERE token_hash = $1 RETURNING user_id, expires_at`, tokenHash).
+		Scan(&userID, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrInviteNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения приглашения: %w", err)
+	}
+
+	// Истекшее приглашение не удаляется, чтобы при повторной попытке пользователь снова увидел, что срок истек
+	if expiresAt.Before(time.Now()) {
+		return 0, ErrInviteExpired
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users SET password_hash = $1, updated_at = $2
+		WHERE id = $3 AND password_hash = ''
+	`, passwordHash, time.Now(), userID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка установки пароля: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, ErrInviteNotFound
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ошибка коммита транзакции: %w", err)
+	}
+
+	return userID, nil
+}
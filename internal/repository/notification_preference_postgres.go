@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type NotificationPreferenceRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationPreferenceRepository(db *pgxpool.Pool) NotificationPreferenceRepository {
+	return &NotificationPreferenceRepo{db: db}
+}
+
+// Get возвращает nil, если пользователь еще не менял настройки уведомлений
+func (r *NotificationPreferenceRepo) Get(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+	ctx, span := tracer.Start(ctx, "NotificationPreferenceRepo.Get")
+	defer span.End()
+
+	query := `
+		SELECT user_id, channel, reminders, confirmations, chat, marketing, email_enabled, sms_enabled, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`
+
+	var prefs domain.NotificationPreferences
+	var updatedAt time.Time
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&prefs.UserID,
+		&prefs.Channel,
+		&prefs.Reminders,
+		&prefs.Confirmations,
+		&prefs.Chat,
+		&prefs.Marketing,
+		&prefs.EmailEnabled,
+		&prefs.SMSEnabled,
+		&updatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения настроек уведомлений: %w", err)
+	}
+	prefs.UpdatedAt = &updatedAt
+
+	return &prefs, nil
+}
+
+func (r *NotificationPreferenceRepo) Upsert(ctx context.Context, prefs domain.NotificationPreferences) error {
+	ctx, span := tracer.Start(ctx, "NotificationPreferenceRepo.Upsert")
+	defer span.End()
+
+	query := `
+		INSERT INTO notification_preferences (
+			user_id, channel, reminders, confirmations, chat, marketing, email_enabled, sms_enabled, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (user_id) DO UPDATE
+		SET channel = EXCLUDED.channel,
+		    reminders = EXCLUDED.reminders,
+		    confirmations = EXCLUDED.confirmations,
+		    chat = EXCLUDED.chat,
+		    marketing = EXCLUDED.marketing,
+		    email_enabled = EXCLUDED.email_enabled,
+		    sms_enabled = EXCLUDED.sms_enabled,
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		prefs.UserID,
+		prefs.Channel,
+		prefs.Reminders,
+		prefs.Confirmations,
+		prefs.Chat,
+		prefs.Marketing,
+		prefs.EmailEnabled,
+		prefs.SMSEnabled,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения настроек уведомлений: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type OutboxRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(db *pgxpool.Pool) OutboxRepository {
+	return &OutboxRepo{db: db}
+}
+
+// appointmentSnapshot читает текущее состояние записи внутри транзакции
+func appointmentSnapshot(ctx context.Context, tx pgx.Tx, id int64) (*domain.Appointment, error) {
+	query := `
+		SELECT id, client_id, specialist_id, consultation_type, specialization_id, price,
+			appointment_date, status, payment_id, communication_method, created_at, updated_at,
+			checked_in_at, actual_start_at, actual_end_at
+		FROM appointments
+		WHERE id = $1
+	`
+
+	var a domain.Appointment
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&a.ID, &a.ClientID, &a.SpecialistID, &a.ConsultationType, &a.SpecializationID, &a.Price,
+		&a.AppointmentDate, &a.Status, &a.PaymentID, &a.CommunicationMethod, &a.CreatedAt, &a.UpdatedAt,
+		&a.CheckedInAt, &a.ActualStartAt, &a.ActualEndAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записи для события: %w", err)
+	}
+
+	return &a, nil
+}
+
+// insertAppointmentEvent пишет событие записи в outbox в той же транзакции, что и изменение записи,
+// поэтому событие не теряется и не отправляется для отмененного изменения
+func insertAppointmentEvent(ctx context.Context, tx pgx.Tx, eventType domain.AppointmentEventType, appointment domain.Appointment) error {
+	now := time.Now()
+	payload, err := json.Marshal(domain.AppointmentEventPayload{
+		Event:       eventType,
+		OccurredAt:  now,
+		Appointment: appointment,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации события записи: %w", err)
+	}
+
+	query := `
+		INSERT INTO outbox_events (event_type, appointment_id, payload, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $4)
+	`
+	if _, err := tx.Exec(ctx, query, eventType, appointment.ID, payload, now); err != nil {
+		return fmt.Errorf("ошибка сохранения события записи: %w", err)
+	}
+
+	return nil
+}
+
+// insertAppointmentStatusEvent пишет событие, если новый статус записи его предполагает
+func insertAppointmentStatusEvent(ctx context.Context, tx pgx.Tx, id int64, status domain.AppointmentStatus) error {
+	eventType, ok := domain.AppointmentEventForStatus(status)
+	if !ok {
+		return nil
+	}
+
+	appointment, err := appointmentSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	return insertAppointmentEvent(ctx, tx, eventType, *appointment)
+}
+
+// ClaimDue выбирает до limit событий, готовых к отправке, и откладывает их следующую попытку на lease,
+// чтобы несколько экземпляров сервиса не отправляли одно событие одновременно
+func (r *OutboxRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEvent, error) {
+	ctx, span := tracer.Start(ctx, "OutboxRepo.ClaimDue")
+	defer span.End()
+
+	query := `
+		UPDATE outbox_events
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, appointment_id, payload, attempts, created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, limit, time.Now().Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выборки событий для отправки: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.OutboxEvent
+	for rows.Next() {
+		var event domain.OutboxEvent
+		if err := rows.Scan(
+			&event.ID, &event.EventType, &event.AppointmentID, &event.Payload, &event.Attempts, &event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования события: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepo) MarkDelivered(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "OutboxRepo.MarkDelivered")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx,
+		"UPDATE outbox_events SET attempts = attempts + 1, delivered_at = $2, last_error = NULL WHERE id = $1",
+		id, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка отметки доставки события: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed сохраняет ошибку отправки. nextAttemptAt == nil означает, что попытки исчерпаны
+func (r *OutboxRepo) MarkFailed(ctx context.Context, id int64, message string, nextAttemptAt *time.Time) error {
+	ctx, span := tracer.Start(ctx, "OutboxRepo.MarkFailed")
+	defer span.End()
+
+	var err error
+	if nextAttemptAt != nil {
+		_, err = r.db.Exec(ctx,
+			"UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1",
+			id, message, *nextAttemptAt,
+		)
+	} else {
+		_, err = r.db.Exec(ctx,
+			"UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, failed_at = $3 WHERE id = $1",
+			id, message, time.Now(),
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения ошибки отправки события: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeDelivered удаляет доставленные до before события и возвращает их количество
+func (r *OutboxRepo) PurgeDelivered(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "OutboxRepo.PurgeDelivered")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM outbox_events WHERE delivered_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления доставленных событий: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
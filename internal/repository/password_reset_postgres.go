@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type PasswordResetRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPasswordResetRepository(db *pgxpool.Pool) PasswordResetRepository {
+	return &PasswordResetRepo{db: db}
+}
+
+// Upsert сохраняет токен восстановления пароля, заменяя предыдущий токен пользователя
+func (r *PasswordResetRepo) Upsert(ctx context.Context, token domain.PasswordResetToken) error {
+	ctx, span := tracer.Start(ctx, "PasswordResetRepo.Upsert")
+	defer span.End()
+
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.Exec(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения токена восстановления пароля: %w", err)
+	}
+
+	return nil
+}
+
+// Consume удаляет токен по его хешу и возвращает ID его пользователя. Удаление и чтение выполняются одним запросом,
+// поэтому токен нельзя использовать дважды. Истекший токен тоже удаляется: он уже бесполезен
+func (r *PasswordResetRepo) Consume(ctx context.Context, tokenHash string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "PasswordResetRepo.Consume")
+	defer span.End()
+
+	var userID int64
+	var expiresAt time.Time
+	err := r.db.QueryRow(ctx, `DELETE FROM password_reset_tokens WHERE token_hash = $1 RETURNING user_id, expires_at`, tokenHash).
+		Scan(&userID, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrPasswordResetNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения токена восстановления пароля: %w", err)
+	}
+
+	if expiresAt.Before(time.Now()) {
+		return 0, ErrPasswordResetExpired
+	}
+
+	return userID, nil
+}
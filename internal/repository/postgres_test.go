@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"laps/pkg/database"
+)
+
+// Тесты хранилищ идут на настоящей базе: TEST_DATABASE_URL указывает на пустую базу,
+// к которой применяются миграции. Без переменной тесты пропускаются
+
+var (
+	testPoolOnce sync.Once
+	testPool     *pgxpool.Pool
+	testPoolErr  error
+	fixtureSeq   int64
+)
+
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	testPoolOnce.Do(func() {
+		testPool, testPoolErr = pgxpool.New(context.Background(), url)
+		if testPoolErr != nil {
+			return
+		}
+		testPoolErr = database.RunMigrations(testPool, "../../migrations", zap.NewNop())
+	})
+	if testPoolErr != nil {
+		t.Fatalf("test database: %v", testPoolErr)
+	}
+	return testPool
+}
+
+// uniqueSuffix различает фикстуры разных тестов и разных запусков в одной базе
+func uniqueSuffix() string {
+	return fmt.Sprintf("%d%d", time.Now().UnixNano()%1e9, atomic.AddInt64(&fixtureSeq, 1))
+}
+
+func createTestUser(t *testing.T, db *pgxpool.Pool, role string) int64 {
+	t.Helper()
+
+	suffix := uniqueSuffix()
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO users (first_name, last_name, email, phone, password_hash, role, is_active, created_at, updated_at)
+		VALUES ('Test', 'User', $1, $2, 'hash', $3, true, NOW(), NOW())
+		RETURNING id
+	`, "user"+suffix+"@example.com", "+7"+suffix, role).Scan(&id)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return id
+}
+
+func createTestSpecialist(t *testing.T, db *pgxpool.Pool) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	var specializationID int64
+	err := db.QueryRow(ctx, `
+		INSERT INTO specializations (name, type, is_active, created_at, updated_at)
+		VALUES ($1, 'lawyer', true, NOW(), NOW())
+		RETURNING id
+	`, "Specialization "+uniqueSuffix()).Scan(&specializationID)
+	if err != nil {
+		t.Fatalf("create specialization: %v", err)
+	}
+
+	userID := createTestUser(t, db, "specialist")
+	var id int64
+	err = db.QueryRow(ctx, `
+		INSERT INTO specialists (user_id, type, specialization_id, experience, primary_consult_price, secondary_consult_price, created_at, updated_at)
+		VALUES ($1, 'lawyer', $2, 5, 3000, 2000, NOW(), NOW())
+		RETURNING id
+	`, userID, specializationID).Scan(&id)
+	if err != nil {
+		t.Fatalf("create specialist: %v", err)
+	}
+	return id
+}
@@ -18,6 +18,20 @@ type Repositories struct {
 	Auth           AuthRepository
 	Schedule       ScheduleRepository
 	Chat           ChatRepository
+	Audit          AuditRepository
+	Calendar       ExternalCalendarRepository
+	BlockList      BlockListRepository
+	Outbox         OutboxRepository
+	Tag            TagRepository
+	ClientProfile  ClientProfileRepository
+	Notification   NotificationPreferenceRepository
+	Invite         InviteRepository
+	Event          EventRepository
+	PasswordReset  PasswordResetRepository
+	ReviewRequest  ReviewRequestRepository
+	SpecialistType SpecialistTypeRepository
+	Call           CallRepository
+	Webhook        WebhookRepository
 }
 
 func NewRepositories(db *pgxpool.Pool) *Repositories {
@@ -30,9 +44,80 @@ func NewRepositories(db *pgxpool.Pool) *Repositories {
 		Review:         NewReviewRepository(db),
 		Schedule:       NewScheduleRepository(db),
 		Chat:           NewChatRepository(db),
+		Audit:          NewAuditRepository(db),
+		Calendar:       NewExternalCalendarRepository(db),
+		BlockList:      NewBlockListRepository(db),
+		Outbox:         NewOutboxRepository(db),
+		Tag:            NewTagRepository(db),
+		ClientProfile:  NewClientProfileRepository(db),
+		Notification:   NewNotificationPreferenceRepository(db),
+		Invite:         NewInviteRepository(db),
+		Event:          NewEventRepository(db),
+		PasswordReset:  NewPasswordResetRepository(db),
+		ReviewRequest:  NewReviewRequestRepository(db),
+		SpecialistType: NewSpecialistTypeRepository(db),
+		Call:           NewCallRepository(db),
+		Webhook:        NewWebhookRepository(db),
 	}
 }
 
+// CallRepository хранит завершенные звонки и оценки их качества участниками
+type CallRepository interface {
+	SaveSession(ctx context.Context, call domain.CallRecord) error
+	GetSession(ctx context.Context, id string) (*domain.CallRecord, error)
+	CreateFeedback(ctx context.Context, feedback *domain.CallFeedback) error
+	GetFeedbackSummary(ctx context.Context, from, to time.Time) (*domain.CallFeedbackSummary, error)
+}
+
+type ExternalCalendarRepository interface {
+	SetFeed(ctx context.Context, specialistID int64, url string) error
+	DeleteFeed(ctx context.Context, specialistID int64) error
+	GetFeed(ctx context.Context, specialistID int64) (*domain.ExternalCalendarFeed, error)
+	ListFeeds(ctx context.Context) ([]domain.ExternalCalendarFeed, error)
+	ReplaceBlocks(ctx context.Context, specialistID int64, blocks []domain.ExternalBusyBlock) error
+	MarkSyncFailed(ctx context.Context, specialistID int64, message string) error
+	ListBlocks(ctx context.Context, specialistID int64, from, to time.Time) ([]domain.ExternalBusyBlock, error)
+}
+
+type OutboxRepository interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, message string, nextAttemptAt *time.Time) error
+	PurgeDelivered(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WebhookRepository хранит адреса внешних систем, зарегистрированные администратором, и очередь доставки событий на них
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id int64) (*domain.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id int64) error
+	Enqueue(ctx context.Context, eventID string, eventType domain.WebhookEventType, payload []byte) (int64, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDeliveryJob, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int) error
+	MarkFailed(ctx context.Context, id int64, statusCode *int, message string, nextAttemptAt *time.Time) error
+	ListDeliveries(ctx context.Context, endpointID int64, limit, offset int) ([]domain.WebhookDelivery, int, error)
+	PurgeDelivered(ctx context.Context, before time.Time) (int64, error)
+}
+
+type EventRepository interface {
+	Append(ctx context.Context, event domain.Event) error
+	GetByAggregate(ctx context.Context, aggregateType domain.EventAggregateType, aggregateID int64, limit, offset int) ([]domain.Event, int, error)
+}
+
+type BlockListRepository interface {
+	Block(ctx context.Context, specialistID, clientID int64, reason *string) error
+	Unblock(ctx context.Context, specialistID, clientID int64) (bool, error)
+	List(ctx context.Context, specialistID int64) ([]domain.BlockedClient, error)
+	IsBlocked(ctx context.Context, specialistID, clientID int64) (bool, error)
+	IsBlockedBetweenUsers(ctx context.Context, userA, userB int64) (bool, error)
+}
+
+type AuditRepository interface {
+	Log(ctx context.Context, entry domain.AuditEntry) error
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, int, error)
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user domain.CreateUserDTO) (int64, error)
 	GetByID(ctx context.Context, id int64) (*domain.User, error)
@@ -40,20 +125,48 @@ type UserRepository interface {
 	GetByPhone(ctx context.Context, phone string) (*domain.User, error)
 	Update(ctx context.Context, id int64, user domain.UpdateUserDTO) error
 	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	UpdateLastSeen(ctx context.Context, id int64, at time.Time) error
+	Anonymize(ctx context.Context, id int64) (*int64, error)
+	ChangeRole(ctx context.Context, id int64, role domain.UserRole) (domain.UserRole, *int64, error)
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, limit, offset int) ([]domain.User, error)
 }
 
+type NotificationPreferenceRepository interface {
+	Get(ctx context.Context, userID int64) (*domain.NotificationPreferences, error)
+	Upsert(ctx context.Context, prefs domain.NotificationPreferences) error
+}
+
+type ClientProfileRepository interface {
+	Get(ctx context.Context, userID int64) (*domain.ClientProfile, error)
+	Upsert(ctx context.Context, profile domain.ClientProfile) error
+}
+
+type TagRepository interface {
+	SetSpecialistTags(ctx context.Context, specialistID int64, names []string) error
+	GetSpecialistTags(ctx context.Context, specialistID int64) ([]domain.Tag, error)
+	Search(ctx context.Context, query string, limit int) ([]domain.Tag, error)
+}
+
 type SpecialistRepository interface {
 	Create(ctx context.Context, userID int64, specialist domain.CreateSpecialistDTO) (int64, error)
 	GetByID(ctx context.Context, id int64) (*domain.Specialist, error)
+	GetByIDWithDeleted(ctx context.Context, id int64) (*domain.Specialist, error)
 	GetByUserID(ctx context.Context, userID int64) (*domain.Specialist, error)
 	Update(ctx context.Context, id int64, specialist domain.UpdateSpecialistDTO) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, specialistType *domain.SpecialistType, specializationID *int64, limit, offset int) ([]domain.Specialist, error)
-	CountByFilter(ctx context.Context, specialistType *domain.SpecialistType, specializationID *int64) (int, error)
+	Restore(ctx context.Context, id int64) error
+	DeletePermanently(ctx context.Context, id int64) error
+	List(ctx context.Context, filter domain.SpecialistFilter) ([]domain.Specialist, error)
+	CountByFilter(ctx context.Context, filter domain.SpecialistFilter) (int, error)
+	ListActiveIDs(ctx context.Context) ([]int64, error)
+	CountForSitemap(ctx context.Context) (int, error)
+	ListForSitemap(ctx context.Context, limit, offset int) ([]domain.SitemapEntry, error)
+	GetActivityStats(ctx context.Context, specialistID int64, since time.Time) (*domain.SpecialistActivityStats, error)
+	GetPriceHistory(ctx context.Context, specialistID int64) ([]domain.SpecialistPriceChange, error)
 
 	UpdateProfilePhoto(ctx context.Context, id int64, photoURL string) error
+	SetVerified(ctx context.Context, id int64, isVerified bool) (bool, error)
 
 	AddEducation(ctx context.Context, specialistID int64, education domain.EducationDTO) (int64, error)
 	UpdateEducation(ctx context.Context, id int64, education domain.EducationDTO) error
@@ -69,7 +182,9 @@ type SpecialistRepository interface {
 
 	AddSpecialization(ctx context.Context, specialistID, specializationID int64) error
 	RemoveSpecialization(ctx context.Context, specialistID, specializationID int64) error
+	SetSpecializations(ctx context.Context, specialistID int64, ids []int64) error
 	GetSpecializationsBySpecialistID(ctx context.Context, specialistID int64) ([]domain.Specialization, error)
+	SetTranslations(ctx context.Context, id int64, translations domain.Translations, removed []string) error
 	GetDB() *pgxpool.Pool
 }
 
@@ -77,10 +192,28 @@ type AppointmentRepository interface {
 	Create(ctx context.Context, clientID int64, appointment domain.CreateAppointmentDTO) (int64, error)
 	GetByID(ctx context.Context, id int64) (*domain.Appointment, error)
 	Update(ctx context.Context, id int64, appointment domain.UpdateAppointmentDTO) error
+	UpdateStatus(ctx context.Context, id int64, status domain.AppointmentStatus) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, error)
 	CountByFilter(ctx context.Context, filter domain.AppointmentFilter) (int, error)
-	GetFreeSlots(ctx context.Context, specialistID int64, date string) ([]string, error)
+	GetBookedSlots(ctx context.Context, specialistID int64, date string) ([]string, error)
+	GetBookedSlotsForSpecialists(ctx context.Context, specialistIDs []int64, date string) (map[int64][]string, error)
+	GetBookedSlotsInRange(ctx context.Context, specialistID int64, startDate, endDate string) (map[string][]string, error)
+	HasActiveAppointment(ctx context.Context, specialistID, clientID int64) (bool, error)
+	GetNextUpcoming(ctx context.Context, clientID int64) (*domain.Appointment, error)
+	GetLastCompleted(ctx context.Context, clientID, specialistID int64, specializationID *int64) (*domain.Appointment, error)
+	MarkConfirmationSent(ctx context.Context, id int64) (bool, error)
+	AddCallDuration(ctx context.Context, id int64, seconds int) error
+	ListCompletableByCall(ctx context.Context, minDuration int, endedBefore time.Time) ([]int64, error)
+	CancelRange(ctx context.Context, specialistID int64, from, to time.Time) ([]domain.Appointment, error)
+	Transfer(ctx context.Context, id, specialistID int64, consultationType domain.ConsultationType, slotDuration time.Duration) error
+	CheckIn(ctx context.Context, id int64) error
+	Start(ctx context.Context, id int64, force bool) error
+	CreateHold(ctx context.Context, token string, clientID, specialistID int64, slotAt, expiresAt time.Time, maxActive int) (*domain.SlotHold, error)
+	GetHeldSlots(ctx context.Context, specialistID int64, date string) ([]string, error)
+	ReleaseHold(ctx context.Context, clientID, holdID int64) (bool, error)
+	PurgeExpiredHolds(ctx context.Context) (int64, error)
+	ListForExport(ctx context.Context, filter domain.AppointmentExportFilter) ([]domain.Appointment, error)
 }
 
 type ReviewRepository interface {
@@ -93,10 +226,14 @@ type ReviewRepository interface {
 	CountBySpecialistID(ctx context.Context, specialistID int64) (int, error)
 	CountByFilter(ctx context.Context, filter domain.ReviewFilter) (int, error)
 	List(ctx context.Context, filter domain.ReviewFilter) ([]domain.Review, error)
+	ListPublicRecent(ctx context.Context, minRating, limit int) ([]domain.PublicReview, error)
+	CreateMedia(ctx context.Context, uploadedBy int64, url string) (*domain.ReviewMedia, error)
+	AddMedia(ctx context.Context, reviewID, uploadedBy int64, url string, maxAttachments int) (*domain.ReviewMedia, error)
 	CreateReply(ctx context.Context, userID int64, reviewID int64, reply domain.CreateReplyDTO) (int64, error)
 	GetReplyByID(ctx context.Context, id int64) (*domain.Reply, error)
 	DeleteReply(ctx context.Context, id int64) error
 	GetRepliesByReviewID(ctx context.Context, reviewID int64) ([]domain.Reply, error)
+	GetRatingSummary(ctx context.Context, specialistID int64) (*domain.RatingSummary, error)
 }
 
 type SpecializationRepository interface {
@@ -106,6 +243,27 @@ type SpecializationRepository interface {
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, filter domain.SpecializationFilter) ([]domain.Specialization, error)
 	CountByFilter(ctx context.Context, filter domain.SpecializationFilter) (int, error)
+	HasChildren(ctx context.Context, id int64) (bool, error)
+	SetTranslations(ctx context.Context, id int64, translations domain.Translations, removed []string) error
+}
+
+type InviteRepository interface {
+	Upsert(ctx context.Context, invite domain.UserInvite) error
+	Accept(ctx context.Context, tokenHash, passwordHash string) (int64, error)
+}
+
+type PasswordResetRepository interface {
+	Upsert(ctx context.Context, token domain.PasswordResetToken) error
+	Consume(ctx context.Context, tokenHash string) (int64, error)
+}
+
+type SpecialistTypeRepository interface {
+	GetAll(ctx context.Context) ([]domain.SpecialistTypeInfo, error)
+}
+
+type ReviewRequestRepository interface {
+	Schedule(ctx context.Context, appointmentID, clientID int64, sendAfter time.Time) (bool, error)
+	ClaimDue(ctx context.Context, limit int) ([]domain.ReviewRequest, error)
 }
 
 type AuthRepository interface {
@@ -119,9 +277,16 @@ type ScheduleRepository interface {
 	Create(ctx context.Context, schedule domain.Schedule) (int64, error)
 	GetByID(ctx context.Context, id int64) (*domain.Schedule, error)
 	Update(ctx context.Context, schedule domain.Schedule) error
+	ReplaceWeek(ctx context.Context, specialistID int64, startDate, endDate time.Time, expectedVersion *int, schedules []domain.Schedule) (int, error)
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, filter domain.ScheduleFilter) ([]domain.Schedule, int, error)
 	GetBySpecialistAndDate(ctx context.Context, specialistID int64, date time.Time) (*domain.Schedule, error)
+	ListBySpecialistsAndDate(ctx context.Context, specialistIDs []int64, date time.Time) ([]domain.Schedule, error)
+	SetOverride(ctx context.Context, override domain.ScheduleOverride) (*domain.ScheduleOverride, error)
+	GetOverride(ctx context.Context, specialistID int64, date time.Time) (*domain.ScheduleOverride, error)
+	ListOverrides(ctx context.Context, specialistID int64, startDate, endDate time.Time) ([]domain.ScheduleOverride, error)
+	ListOverridesForSpecialists(ctx context.Context, specialistIDs []int64, date time.Time) ([]domain.ScheduleOverride, error)
+	DeleteOverride(ctx context.Context, specialistID int64, date time.Time) error
 }
 
 type ChatRepository interface {
@@ -131,6 +296,8 @@ type ChatRepository interface {
 	GetChatSessionByAppointmentID(ctx context.Context, appointmentID int64) (*domain.ChatSession, error)
 	ListChatSessions(ctx context.Context, filter domain.ChatSessionFilter) ([]domain.ChatSession, error)
 	CountChatSessions(ctx context.Context, filter domain.ChatSessionFilter) (int64, error)
+	ListChatSessionsByUserID(ctx context.Context, userID int64, filter domain.ChatSessionFilter) ([]domain.ChatSession, error)
+	CountChatSessionsByUserID(ctx context.Context, userID int64, filter domain.ChatSessionFilter) (int64, error)
 	UpdateChatSession(ctx context.Context, id int64, dto domain.UpdateChatSessionDTO) (*domain.ChatSession, error)
 	
 	// Chat Messages
@@ -139,4 +306,15 @@ type ChatRepository interface {
 	CountChatMessages(ctx context.Context, filter domain.ChatMessageFilter) (int64, error)
 	MarkMessagesAsRead(ctx context.Context, sessionID int64, userID int64) error
 	GetUnreadMessageCount(ctx context.Context, sessionID int64, userID int64) (int64, error)
+	StreamChatMessages(ctx context.Context, sessionID int64, fn func(message domain.ChatMessage) error) error
+	GetChatMessageByID(ctx context.Context, id int64) (*domain.ChatMessage, error)
+
+	// Reactions
+	AddReaction(ctx context.Context, messageID, userID int64, emoji string) error
+	RemoveReaction(ctx context.Context, messageID, userID int64, emoji string) (bool, error)
+	GetReactionCounts(ctx context.Context, messageIDs []int64) (map[int64]map[string]int, error)
+
+	// Statistics
+	GetSpecialistResponseStats(ctx context.Context, specialistID int64) (*domain.SpecialistResponseStats, error)
+	GetSessionStats(ctx context.Context, sessionID int64) (*domain.ChatSessionStats, error)
 }
@@ -64,20 +64,22 @@ func (r *ReviewRepo) Create(ctx context.Context, clientID int64, review domain.C
 		return 0, fmt.Errorf("ошибка создания отзыва: %w", err)
 	}
 
-	updateRatingQuery := `
-		UPDATE specialists
-		SET rating = (
-			SELECT AVG(rating) FROM reviews WHERE specialist_id = $1
-		),
-		reviews_count = (
-			SELECT COUNT(*) FROM reviews WHERE specialist_id = $1
-		)
-		WHERE id = $1
-	`
+	if len(review.MediaURLs) > 0 {
+		// Привязываются только изображения, загруженные этим клиентом и еще не приложенные к другому отзыву
+		tag, err := tx.Exec(ctx, `
+			UPDATE review_media SET review_id = $1
+			WHERE url = ANY($2) AND uploaded_by = $3 AND review_id IS NULL
+		`, id, review.MediaURLs, clientID)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка привязки изображений к отзыву: %w", err)
+		}
+		if tag.RowsAffected() != int64(len(review.MediaURLs)) {
+			return 0, ErrReviewMediaNotFound
+		}
+	}
 
-	_, err = tx.Exec(ctx, updateRatingQuery, review.SpecialistID)
-	if err != nil {
-		return 0, fmt.Errorf("ошибка обновления рейтинга специалиста: %w", err)
+	if err = r.recalculateSpecialistRating(ctx, tx, review.SpecialistID); err != nil {
+		return 0, err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
@@ -93,14 +95,15 @@ func (r *ReviewRepo) GetByID(ctx context.Context, id int64) (*domain.Review, err
 		       r.service_rating, r.meeting_efficiency, r.professionalism, r.price_quality,
 		       r.cleanliness, r.attentiveness, r.specialist_experience, r.grammar,
 		       r.created_at, r.updated_at, r.reply_id,
-		       u.first_name, u.last_name
+		       u.first_name, u.last_name, COALESCE(u.avatar_url, ''),
+		       s.deleted_at IS NOT NULL
 		FROM reviews r
 		JOIN users u ON r.client_id = u.id
+		JOIN specialists s ON r.specialist_id = s.id
 		WHERE r.id = $1
 	`
 
 	var review domain.Review
-	var userName, userLastName string
 
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&review.ID,
@@ -121,8 +124,10 @@ func (r *ReviewRepo) GetByID(ctx context.Context, id int64) (*domain.Review, err
 		&review.CreatedAt,
 		&review.UpdatedAt,
 		&review.ReplyID,
-		&userName,
-		&userLastName,
+		&review.AuthorFirstName,
+		&review.AuthorLastName,
+		&review.AuthorAvatarURL,
+		&review.SpecialistRemoved,
 	)
 
 	if err != nil {
@@ -132,7 +137,12 @@ func (r *ReviewRepo) GetByID(ctx context.Context, id int64) (*domain.Review, err
 		return nil, fmt.Errorf("ошибка получения отзыва: %w", err)
 	}
 
-	return &review, nil
+	reviews := []domain.Review{review}
+	if err := r.loadMedia(ctx, reviews); err != nil {
+		return nil, err
+	}
+
+	return &reviews[0], nil
 }
 
 func (r *ReviewRepo) Update(ctx context.Context, id int64, dto domain.UpdateReviewDTO) error {
@@ -175,11 +185,30 @@ func (r *ReviewRepo) Update(ctx context.Context, id int64, dto domain.UpdateRevi
 	query += fmt.Sprintf(" WHERE id = $%d", argCount)
 	args = append(args, id)
 
-	_, err = tx.Exec(ctx, query, args...)
+	// Оптимистичная блокировка: отзыв не должен был меняться после того, как его прочитал клиент
+	if dto.ExpectedUpdatedAt != nil {
+		argCount++
+		query += fmt.Sprintf(" AND updated_at = $%d", argCount)
+		args = append(args, *dto.ExpectedUpdatedAt)
+	}
+	query += " RETURNING specialist_id"
+
+	var specialistID int64
+	err = tx.QueryRow(ctx, query, args...).Scan(&specialistID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) && dto.ExpectedUpdatedAt != nil {
+			return ErrVersionConflict
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("отзыв с id %d не найден", id)
+		}
 		return fmt.Errorf("ошибка обновления отзыва: %w", err)
 	}
 
+	if err = r.recalculateSpecialistRating(ctx, tx, specialistID); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
 	}
@@ -210,26 +239,138 @@ func (r *ReviewRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("ошибка удаления отзыва: %w", err)
 	}
 
-	updateRatingQuery := `
+	if err = r.recalculateSpecialistRating(ctx, tx, specialistID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// CreateMedia сохраняет загруженное клиентом изображение, еще не привязанное к отзыву
+func (r *ReviewRepo) CreateMedia(ctx context.Context, uploadedBy int64, url string) (*domain.ReviewMedia, error) {
+	media := domain.ReviewMedia{URL: url}
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO review_media (uploaded_by, url, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, uploadedBy, url, time.Now()).Scan(&media.ID, &media.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения изображения отзыва: %w", err)
+	}
+
+	return &media, nil
+}
+
+// AddMedia прикладывает изображение к существующему отзыву. Строка отзыва блокируется,
+// чтобы параллельные загрузки не превысили maxAttachments
+func (r *ReviewRepo) AddMedia(ctx context.Context, reviewID, uploadedBy int64, url string, maxAttachments int) (*domain.ReviewMedia, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var lockedID int64
+	if err := tx.QueryRow(ctx, `SELECT id FROM reviews WHERE id = $1 FOR UPDATE`, reviewID).Scan(&lockedID); err != nil {
+		return nil, fmt.Errorf("ошибка получения отзыва: %w", err)
+	}
+
+	var count int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM review_media WHERE review_id = $1`, reviewID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("ошибка подсчета изображений отзыва: %w", err)
+	}
+	if count >= maxAttachments {
+		return nil, ErrReviewMediaLimit
+	}
+
+	media := domain.ReviewMedia{URL: url}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO review_media (review_id, uploaded_by, url, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, reviewID, uploadedBy, url, time.Now()).Scan(&media.ID, &media.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения изображения отзыва: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return &media, nil
+}
+
+// loadMedia загружает изображения отзывов одним запросом
+func (r *ReviewRepo) loadMedia(ctx context.Context, reviews []domain.Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(reviews))
+	index := make(map[int64]int, len(reviews))
+	for i := range reviews {
+		ids[i] = reviews[i].ID
+		index[reviews[i].ID] = i
+		reviews[i].Media = []domain.ReviewMedia{}
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT review_id, id, url, created_at
+		FROM review_media
+		WHERE review_id = ANY($1)
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("ошибка получения изображений отзывов: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reviewID int64
+		var media domain.ReviewMedia
+		if err := rows.Scan(&reviewID, &media.ID, &media.URL, &media.CreatedAt); err != nil {
+			return fmt.Errorf("ошибка сканирования изображения отзыва: %w", err)
+		}
+		i := index[reviewID]
+		reviews[i].Media = append(reviews[i].Media, media)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при итерации по строкам: %w", err)
+	}
+
+	return nil
+}
+
+// recalculateSpecialistRating пересчитывает агрегаты специалиста по его отзывам
+// в рамках переданной транзакции, чтобы они менялись атомарно вместе с отзывом
+func (r *ReviewRepo) recalculateSpecialistRating(ctx context.Context, tx pgx.Tx, specialistID int64) error {
+	query := `
 		UPDATE specialists
 		SET rating = (
 			SELECT COALESCE(AVG(rating), 0) FROM reviews WHERE specialist_id = $1
 		),
 		reviews_count = (
 			SELECT COUNT(*) FROM reviews WHERE specialist_id = $1
-		)
+		),
+		recommendation_rate = (
+			SELECT COALESCE(ROUND((COUNT(*) FILTER (WHERE is_recommended = true) * 100.0) / NULLIF(COUNT(*), 0)), 0)
+			FROM reviews
+			WHERE specialist_id = $1
+		),
+		updated_at = NOW()
 		WHERE id = $1
 	`
 
-	_, err = tx.Exec(ctx, updateRatingQuery, specialistID)
+	_, err := tx.Exec(ctx, query, specialistID)
 	if err != nil {
 		return fmt.Errorf("ошибка обновления рейтинга специалиста: %w", err)
 	}
 
-	if err = tx.Commit(ctx); err != nil {
-		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
-	}
-
 	return nil
 }
 
@@ -239,9 +380,11 @@ func (r *ReviewRepo) GetBySpecialistID(ctx context.Context, specialistID int64,
 		       r.service_rating, r.meeting_efficiency, r.professionalism, r.price_quality,
 		       r.cleanliness, r.attentiveness, r.specialist_experience, r.grammar,
 		       r.created_at, r.updated_at, r.reply_id,
-		       u.first_name, u.last_name
+		       u.first_name, u.last_name, COALESCE(u.avatar_url, ''),
+		       s.deleted_at IS NOT NULL
 		FROM reviews r
 		JOIN users u ON r.client_id = u.id
+		JOIN specialists s ON r.specialist_id = s.id
 		WHERE r.specialist_id = $1
 		ORDER BY r.created_at DESC
 		LIMIT $2 OFFSET $3
@@ -256,7 +399,6 @@ func (r *ReviewRepo) GetBySpecialistID(ctx context.Context, specialistID int64,
 	reviews := make([]domain.Review, 0)
 	for rows.Next() {
 		var review domain.Review
-		var userName, userLastName string
 
 		if err := rows.Scan(
 			&review.ID,
@@ -277,8 +419,10 @@ func (r *ReviewRepo) GetBySpecialistID(ctx context.Context, specialistID int64,
 			&review.CreatedAt,
 			&review.UpdatedAt,
 			&review.ReplyID,
-			&userName,
-			&userLastName,
+			&review.AuthorFirstName,
+			&review.AuthorLastName,
+			&review.AuthorAvatarURL,
+			&review.SpecialistRemoved,
 		); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования строки отзыва: %w", err)
 		}
@@ -290,6 +434,10 @@ func (r *ReviewRepo) GetBySpecialistID(ctx context.Context, specialistID int64,
 		return nil, fmt.Errorf("ошибка при итерации по строкам: %w", err)
 	}
 
+	if err := r.loadMedia(ctx, reviews); err != nil {
+		return nil, err
+	}
+
 	return reviews, nil
 }
 
@@ -299,9 +447,11 @@ func (r *ReviewRepo) GetByUserID(ctx context.Context, userID int64, limit, offse
 		       r.service_rating, r.meeting_efficiency, r.professionalism, r.price_quality,
 		       r.cleanliness, r.attentiveness, r.specialist_experience, r.grammar,
 		       r.created_at, r.updated_at, r.reply_id,
-		       u.first_name, u.last_name
+		       u.first_name, u.last_name, COALESCE(u.avatar_url, ''),
+		       s.deleted_at IS NOT NULL
 		FROM reviews r
 		JOIN users u ON r.client_id = u.id
+		JOIN specialists s ON r.specialist_id = s.id
 		WHERE r.client_id = $1
 		ORDER BY r.created_at DESC
 		LIMIT $2 OFFSET $3
@@ -316,7 +466,6 @@ func (r *ReviewRepo) GetByUserID(ctx context.Context, userID int64, limit, offse
 	reviews := make([]domain.Review, 0)
 	for rows.Next() {
 		var review domain.Review
-		var userName, userLastName string
 
 		if err := rows.Scan(
 			&review.ID,
@@ -337,8 +486,10 @@ func (r *ReviewRepo) GetByUserID(ctx context.Context, userID int64, limit, offse
 			&review.CreatedAt,
 			&review.UpdatedAt,
 			&review.ReplyID,
-			&userName,
-			&userLastName,
+			&review.AuthorFirstName,
+			&review.AuthorLastName,
+			&review.AuthorAvatarURL,
+			&review.SpecialistRemoved,
 		); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования строки отзыва: %w", err)
 		}
@@ -350,6 +501,10 @@ func (r *ReviewRepo) GetByUserID(ctx context.Context, userID int64, limit, offse
 		return nil, fmt.Errorf("ошибка при итерации по строкам: %w", err)
 	}
 
+	if err := r.loadMedia(ctx, reviews); err != nil {
+		return nil, err
+	}
+
 	return reviews, nil
 }
 
@@ -465,9 +620,11 @@ func (r *ReviewRepo) List(ctx context.Context, filter domain.ReviewFilter) ([]do
 		       r.service_rating, r.meeting_efficiency, r.professionalism, r.price_quality,
 		       r.cleanliness, r.attentiveness, r.specialist_experience, r.grammar,
 		       r.created_at, r.updated_at, r.reply_id,
-		       u.first_name, u.last_name
+		       u.first_name, u.last_name, COALESCE(u.avatar_url, ''),
+		       s.deleted_at IS NOT NULL
 		FROM reviews r
 		JOIN users u ON r.client_id = u.id
+		JOIN specialists s ON r.specialist_id = s.id
 	`
 
 	query := baseQuery
@@ -488,7 +645,6 @@ func (r *ReviewRepo) List(ctx context.Context, filter domain.ReviewFilter) ([]do
 	reviews := make([]domain.Review, 0)
 	for rows.Next() {
 		var review domain.Review
-		var userName, userLastName string
 
 		if err := rows.Scan(
 			&review.ID,
@@ -509,8 +665,10 @@ func (r *ReviewRepo) List(ctx context.Context, filter domain.ReviewFilter) ([]do
 			&review.CreatedAt,
 			&review.UpdatedAt,
 			&review.ReplyID,
-			&userName,
-			&userLastName,
+			&review.AuthorFirstName,
+			&review.AuthorLastName,
+			&review.AuthorAvatarURL,
+			&review.SpecialistRemoved,
 		); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования строки отзыва: %w", err)
 		}
@@ -522,6 +680,71 @@ func (r *ReviewRepo) List(ctx context.Context, filter domain.ReviewFilter) ([]do
 		return nil, fmt.Errorf("ошибка при итерации по строкам: %w", err)
 	}
 
+	if err := r.loadMedia(ctx, reviews); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// ListPublicRecent возвращает последние отзывы с рейтингом не ниже minRating для публичной ленты.
+// Полная фамилия автора и полный текст отзыва за пределы репозитория не передаются
+func (r *ReviewRepo) ListPublicRecent(ctx context.Context, minRating, limit int) ([]domain.PublicReview, error) {
+	query := `
+		SELECT r.id, r.rating, r.text, r.created_at,
+		       cu.first_name, cu.last_name,
+		       s.id, su.first_name, su.last_name, s.type, COALESCE(sp.name, ''),
+		       s.profile_photo_url, s.rating, s.reviews_count
+		FROM reviews r
+		JOIN users cu ON r.client_id = cu.id
+		JOIN specialists s ON r.specialist_id = s.id
+		JOIN users su ON s.user_id = su.id
+		LEFT JOIN specializations sp ON s.specialization_id = sp.id
+		WHERE r.rating >= $1
+		ORDER BY r.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, minRating, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения публичной ленты отзывов: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]domain.PublicReview, 0)
+	for rows.Next() {
+		var review domain.PublicReview
+		var authorFirstName, authorLastName string
+
+		if err := rows.Scan(
+			&review.ID,
+			&review.Rating,
+			&review.Text,
+			&review.CreatedAt,
+			&authorFirstName,
+			&authorLastName,
+			&review.Specialist.ID,
+			&review.Specialist.FirstName,
+			&review.Specialist.LastName,
+			&review.Specialist.Type,
+			&review.Specialist.Specialization,
+			&review.Specialist.ProfilePhotoURL,
+			&review.Specialist.Rating,
+			&review.Specialist.ReviewsCount,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования строки отзыва: %w", err)
+		}
+
+		review.AuthorName = domain.ShortAuthorName(authorFirstName, authorLastName)
+		review.Text = domain.TruncateReviewText(review.Text, domain.PublicReviewTextMaxLength)
+
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %w", err)
+	}
+
 	return reviews, nil
 }
 
@@ -663,3 +886,63 @@ func (r *ReviewRepo) GetRepliesByReviewID(ctx context.Context, reviewID int64) (
 
 	return replies, nil
 }
+
+func (r *ReviewRepo) GetRatingSummary(ctx context.Context, specialistID int64) (*domain.RatingSummary, error) {
+	query := `
+		SELECT COALESCE(AVG(rating), 0), COUNT(*),
+		       COALESCE(ROUND(100.0 * COUNT(*) FILTER (WHERE is_recommended) / NULLIF(COUNT(*), 0)), 0)::int,
+		       AVG(service_rating), AVG(meeting_efficiency), AVG(professionalism), AVG(price_quality),
+		       AVG(cleanliness), AVG(attentiveness), AVG(specialist_experience), AVG(grammar)
+		FROM reviews
+		WHERE specialist_id = $1
+	`
+
+	summary := domain.RatingSummary{
+		SpecialistID:       specialistID,
+		RatingDistribution: make(map[int]int),
+	}
+
+	err := r.db.QueryRow(ctx, query, specialistID).Scan(
+		&summary.AverageRating,
+		&summary.ReviewsCount,
+		&summary.RecommendationRate,
+		&summary.ServiceRating,
+		&summary.MeetingEfficiency,
+		&summary.Professionalism,
+		&summary.PriceQuality,
+		&summary.Cleanliness,
+		&summary.Attentiveness,
+		&summary.SpecialistExperience,
+		&summary.Grammar,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения сводки рейтинга: %w", err)
+	}
+
+	distributionQuery := `
+		SELECT rating, COUNT(*)
+		FROM reviews
+		WHERE specialist_id = $1
+		GROUP BY rating
+	`
+
+	rows, err := r.db.Query(ctx, distributionQuery, specialistID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения распределения оценок: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rating, count int
+		if err := rows.Scan(&rating, &count); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования распределения оценок: %w", err)
+		}
+		summary.RatingDistribution[rating] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %w", err)
+	}
+
+	return &summary, nil
+}
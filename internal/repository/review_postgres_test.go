@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+// specialistAggregates агрегаты специалиста, которые пересчитываются по его отзывам
+type specialistAggregates struct {
+	Rating             float64
+	ReviewsCount       int
+	RecommendationRate int
+}
+
+func readSpecialistAggregates(t *testing.T, db *pgxpool.Pool, specialistID int64) specialistAggregates {
+	t.Helper()
+	var got specialistAggregates
+	err := db.QueryRow(context.Background(),
+		"SELECT rating::float8, reviews_count, recommendation_rate FROM specialists WHERE id = $1", specialistID,
+	).Scan(&got.Rating, &got.ReviewsCount, &got.RecommendationRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+// createTestReview создает прошедшую запись клиента и отзыв на нее
+func createTestReview(t *testing.T, db *pgxpool.Pool, specialistID, clientID int64, hoursAgo, rating int, recommended bool) int64 {
+	t.Helper()
+	ctx := context.Background()
+	appointmentID, err := NewAppointmentRepository(db).Create(ctx, clientID, bookingDTO(specialistID, testSlot(-hoursAgo)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := NewReviewRepository(db).Create(ctx, clientID, domain.CreateReviewDTO{
+		SpecialistID:  specialistID,
+		AppointmentID: appointmentID,
+		Rating:        rating,
+		Text:          "Консультация",
+		IsRecommended: recommended,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// Создание и удаление отзыва пересчитывают rating, reviews_count и recommendation_rate
+// в той же транзакции, без отдельного пересчета в сервисе
+func TestReviewCreateDeleteKeepSpecialistAggregates(t *testing.T) {
+	db := testDB(t)
+	reviews := NewReviewRepository(db)
+	ctx := context.Background()
+	specialistID := createTestSpecialist(t, db)
+	clientID := createTestUser(t, db, "client")
+
+	first := createTestReview(t, db, specialistID, clientID, 48, 5, true)
+	if got, want := readSpecialistAggregates(t, db, specialistID), (specialistAggregates{5, 1, 100}); got != want {
+		t.Fatalf("after the first review: %+v, want %+v", got, want)
+	}
+
+	second := createTestReview(t, db, specialistID, clientID, 72, 2, false)
+	if got, want := readSpecialistAggregates(t, db, specialistID), (specialistAggregates{3.5, 2, 50}); got != want {
+		t.Fatalf("after the second review: %+v, want %+v", got, want)
+	}
+
+	if err := reviews.Delete(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readSpecialistAggregates(t, db, specialistID), (specialistAggregates{2, 1, 0}); got != want {
+		t.Errorf("after deleting the first review: %+v, want %+v", got, want)
+	}
+
+	if err := reviews.Delete(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readSpecialistAggregates(t, db, specialistID), (specialistAggregates{}); got != want {
+		t.Errorf("after deleting every review: %+v, want %+v", got, want)
+	}
+}
+
+// Правка оценки в отзыве сразу пересчитывает рейтинг специалиста в той же транзакции
+func TestReviewUpdateRecalculatesSpecialistRating(t *testing.T) {
+	db := testDB(t)
+	reviews := NewReviewRepository(db)
+	ctx := context.Background()
+	specialistID := createTestSpecialist(t, db)
+	clientID := createTestUser(t, db, "client")
+
+	createTestReview(t, db, specialistID, clientID, 48, 5, true)
+	edited := createTestReview(t, db, specialistID, clientID, 72, 3, false)
+	if got, want := readSpecialistAggregates(t, db, specialistID), (specialistAggregates{4, 2, 50}); got != want {
+		t.Fatalf("after create: %+v, want %+v", got, want)
+	}
+
+	lowered := 1
+	if err := reviews.Update(ctx, edited, domain.UpdateReviewDTO{Rating: &lowered}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readSpecialistAggregates(t, db, specialistID), (specialistAggregates{3, 2, 50}); got != want {
+		t.Errorf("after update: %+v, want %+v", got, want)
+	}
+}
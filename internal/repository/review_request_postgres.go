@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type ReviewRequestRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewReviewRequestRepository(db *pgxpool.Pool) ReviewRequestRepository {
+	return &ReviewRequestRepo{db: db}
+}
+
+// Schedule планирует просьбу об отзыве о записи на sendAfter. Если отзыв о записи уже есть или просьба
+// уже запланирована (или отправлена), ничего не делает и возвращает false
+func (r *ReviewRequestRepo) Schedule(ctx context.Context, appointmentID, clientID int64, sendAfter time.Time) (bool, error) {
+	ctx, span := tracer.Start(ctx, "ReviewRequestRepo.Schedule")
+	defer span.End()
+
+	query := `
+		INSERT INTO review_requests (appointment_id, client_id, send_after)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (SELECT 1 FROM reviews WHERE appointment_id = $1)
+		ON CONFLICT (appointment_id) DO NOTHING
+	`
+
+	tag, err := r.db.Exec(ctx, query, appointmentID, clientID, sendAfter)
+	if err != nil {
+		return false, fmt.Errorf("ошибка планирования просьбы об отзыве: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClaimDue отмечает отправленными до limit просьб, время которых наступило, и возвращает их. Просьба отмечается
+// до отправки, поэтому даже при сбое уведомления или нескольких экземплярах сервиса клиент не получит ее дважды
+func (r *ReviewRequestRepo) ClaimDue(ctx context.Context, limit int) ([]domain.ReviewRequest, error) {
+	ctx, span := tracer.Start(ctx, "ReviewRequestRepo.ClaimDue")
+	defer span.End()
+
+	query := `
+		UPDATE review_requests rr
+		SET sent_at = NOW()
+		WHERE rr.appointment_id IN (
+			SELECT appointment_id FROM review_requests
+			WHERE sent_at IS NULL AND send_after <= NOW()
+			ORDER BY send_after
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING rr.appointment_id, rr.client_id, rr.send_after,
+		          EXISTS (SELECT 1 FROM reviews WHERE appointment_id = rr.appointment_id)
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выборки просьб об отзыве: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []domain.ReviewRequest
+	for rows.Next() {
+		var request domain.ReviewRequest
+		if err := rows.Scan(&request.AppointmentID, &request.ClientID, &request.SendAfter, &request.Reviewed); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования просьбы об отзыве: %w", err)
+		}
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке просьб об отзыве: %w", err)
+	}
+
+	return requests, nil
+}
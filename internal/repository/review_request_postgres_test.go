@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+// claimFor забирает наступившие просьбы и возвращает только просьбы о записях теста:
+// база общая, в ней могут быть просьбы других тестов
+func claimFor(t *testing.T, repo ReviewRequestRepository, appointmentIDs ...int64) map[int64]domain.ReviewRequest {
+	t.Helper()
+	requests, err := repo.ClaimDue(context.Background(), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed := make(map[int64]domain.ReviewRequest)
+	for _, request := range requests {
+		for _, id := range appointmentIDs {
+			if request.AppointmentID == id {
+				claimed[id] = request
+			}
+		}
+	}
+	return claimed
+}
+
+func TestReviewRequestDeduplication(t *testing.T) {
+	db := testDB(t)
+	appointments := NewAppointmentRepository(db)
+	reviews := NewReviewRepository(db)
+	requests := NewReviewRequestRepository(db)
+	ctx := context.Background()
+	specialistID := createTestSpecialist(t, db)
+	clientID := createTestUser(t, db, "client")
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, err := appointments.Create(ctx, clientID, bookingDTO(specialistID, testSlot(-48-24*i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := appointments.UpdateStatus(ctx, id, domain.AppointmentStatusCompleted); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	pending, reviewedLater, reviewedBefore := ids[0], ids[1], ids[2]
+
+	if _, err := reviews.Create(ctx, clientID, domain.CreateReviewDTO{SpecialistID: specialistID, AppointmentID: reviewedBefore, Rating: 5, Text: "Спасибо"}); err != nil {
+		t.Fatal(err)
+	}
+
+	due := time.Now().Add(-time.Minute)
+	for _, tt := range []struct {
+		appointmentID int64
+		want          bool
+	}{
+		{pending, true},
+		{pending, false},
+		{reviewedLater, true},
+		{reviewedBefore, false},
+	} {
+		scheduled, err := requests.Schedule(ctx, tt.appointmentID, clientID, due)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scheduled != tt.want {
+			t.Errorf("Schedule(%d) = %v, want %v", tt.appointmentID, scheduled, tt.want)
+		}
+	}
+
+	// Отзыв оставлен после планирования: просьба забирается, но помечена как ненужная
+	if _, err := reviews.Create(ctx, clientID, domain.CreateReviewDTO{SpecialistID: specialistID, AppointmentID: reviewedLater, Rating: 4, Text: "Хорошо"}); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed := claimFor(t, requests, ids...)
+	if len(claimed) != 2 {
+		t.Fatalf("claimed = %+v, want requests for the pending and the later reviewed appointments", claimed)
+	}
+	if claimed[pending].Reviewed || !claimed[reviewedLater].Reviewed {
+		t.Errorf("reviewed flags = %v, %v; want false, true", claimed[pending].Reviewed, claimed[reviewedLater].Reviewed)
+	}
+
+	if again := claimFor(t, requests, ids...); len(again) != 0 {
+		t.Errorf("second claim = %+v, want nothing", again)
+	}
+	if scheduled, err := requests.Schedule(ctx, pending, clientID, due); err != nil || scheduled {
+		t.Errorf("Schedule() after sending = %v, %v; want false", scheduled, err)
+	}
+
+	status := domain.AppointmentStatusCompleted
+	list, err := appointments.List(ctx, domain.AppointmentFilter{ClientID: &clientID, Status: &status, PendingReview: true, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != pending {
+		t.Errorf("pending reviews = %+v, want only appointment %d", list, pending)
+	}
+}
@@ -24,8 +24,8 @@ func (r *ScheduleRepo) Create(ctx context.Context, schedule domain.Schedule) (in
 
 	query := `
 		INSERT INTO schedules (
-			specialist_id, date, start_time, end_time, slot_time, exclude_times, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			specialist_id, date, start_time, end_time, slot_time, slot_alignment, slot_offset, exclude_times, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
 	`
 
@@ -37,6 +37,8 @@ func (r *ScheduleRepo) Create(ctx context.Context, schedule domain.Schedule) (in
 		schedule.StartTime,
 		schedule.EndTime,
 		schedule.SlotTime,
+		schedule.SlotAlignment,
+		schedule.SlotOffset,
 		schedule.ExcludeTimes,
 		schedule.CreatedAt,
 		schedule.UpdatedAt,
@@ -51,7 +53,7 @@ func (r *ScheduleRepo) Create(ctx context.Context, schedule domain.Schedule) (in
 
 func (r *ScheduleRepo) GetByID(ctx context.Context, id int64) (*domain.Schedule, error) {
 	query := `
-		SELECT id, specialist_id, date, start_time, end_time, slot_time, exclude_times, created_at, updated_at
+		SELECT id, specialist_id, date, start_time, end_time, slot_time, slot_alignment, slot_offset, exclude_times, created_at, updated_at, version
 		FROM schedules
 		WHERE id = $1
 	`
@@ -64,9 +66,12 @@ func (r *ScheduleRepo) GetByID(ctx context.Context, id int64) (*domain.Schedule,
 		&schedule.StartTime,
 		&schedule.EndTime,
 		&schedule.SlotTime,
+		&schedule.SlotAlignment,
+		&schedule.SlotOffset,
 		&schedule.ExcludeTimes,
 		&schedule.CreatedAt,
 		&schedule.UpdatedAt,
+		&schedule.Version,
 	)
 
 	if err != nil {
@@ -79,14 +84,17 @@ func (r *ScheduleRepo) GetByID(ctx context.Context, id int64) (*domain.Schedule,
 	return &schedule, nil
 }
 
+// Update обновляет строку расписания и увеличивает ее версию. Если schedule.Version задана,
+// строка обновляется только при совпадении версии, иначе возвращается ErrVersionConflict
 func (r *ScheduleRepo) Update(ctx context.Context, schedule domain.Schedule) error {
 	query := `
 		UPDATE schedules
-		SET start_time = $1, end_time = $2, slot_time = $3, exclude_times = $4, updated_at = $5
-		WHERE id = $6
+		SET start_time = $1, end_time = $2, slot_time = $3, exclude_times = $4, updated_at = $5,
+		    version = version + 1, slot_alignment = $8, slot_offset = $9
+		WHERE id = $6 AND ($7 = 0 OR version = $7)
 	`
 
-	_, err := r.db.Exec(
+	tag, err := r.db.Exec(
 		ctx,
 		query,
 		schedule.StartTime,
@@ -95,15 +103,90 @@ func (r *ScheduleRepo) Update(ctx context.Context, schedule domain.Schedule) err
 		schedule.ExcludeTimes,
 		schedule.UpdatedAt,
 		schedule.ID,
+		schedule.Version,
+		schedule.SlotAlignment,
+		schedule.SlotOffset,
 	)
 
 	if err != nil {
 		return fmt.Errorf("ошибка обновления расписания: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 && schedule.Version != 0 {
+		return ErrVersionConflict
+	}
+
 	return nil
 }
 
+// ReplaceWeek атомарно заменяет расписание специалиста за даты с startDate по endDate на schedules
+// и возвращает новую версию недели. Версия недели — наибольшая версия ее строк (0, если строк нет);
+// новые строки получают следующую версию. Если expectedVersion задана и не совпадает с текущей
+// версией недели, возвращается ErrVersionConflict
+func (r *ScheduleRepo) ReplaceWeek(ctx context.Context, specialistID int64, startDate, endDate time.Time, expectedVersion *int, schedules []domain.Schedule) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка строки специалиста упорядочивает параллельные замены его расписания
+	if _, err := tx.Exec(ctx, `SELECT id FROM specialists WHERE id = $1 FOR UPDATE`, specialistID); err != nil {
+		return 0, fmt.Errorf("ошибка блокировки специалиста: %w", err)
+	}
+
+	var version int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(version), 0) FROM schedules
+		WHERE specialist_id = $1 AND date >= $2 AND date <= $3
+	`, specialistID, startDate, endDate).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения версии расписания: %w", err)
+	}
+
+	if expectedVersion != nil && *expectedVersion != version {
+		return 0, ErrVersionConflict
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM schedules
+		WHERE specialist_id = $1 AND date >= $2 AND date <= $3
+	`, specialistID, startDate, endDate)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления расписания: %w", err)
+	}
+
+	version++
+	for _, schedule := range schedules {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO schedules (
+				specialist_id, date, start_time, end_time, slot_time, slot_alignment, slot_offset, exclude_times, created_at, updated_at, version
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`,
+			specialistID,
+			schedule.Date,
+			schedule.StartTime,
+			schedule.EndTime,
+			schedule.SlotTime,
+			schedule.SlotAlignment,
+			schedule.SlotOffset,
+			schedule.ExcludeTimes,
+			schedule.CreatedAt,
+			schedule.UpdatedAt,
+			version,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка создания расписания: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	return version, nil
+}
+
 func (r *ScheduleRepo) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM schedules WHERE id = $1`
 
@@ -118,7 +201,7 @@ func (r *ScheduleRepo) Delete(ctx context.Context, id int64) error {
 func (r *ScheduleRepo) List(ctx context.Context, filter domain.ScheduleFilter) ([]domain.Schedule, int, error) {
 	countQuery := `SELECT COUNT(*) FROM schedules WHERE 1=1`
 	selectQuery := `
-		SELECT id, specialist_id, date, start_time, end_time, slot_time, exclude_times, created_at, updated_at
+		SELECT id, specialist_id, date, start_time, end_time, slot_time, slot_alignment, slot_offset, exclude_times, created_at, updated_at, version
 		FROM schedules
 		WHERE 1=1
 	`
@@ -173,9 +256,12 @@ func (r *ScheduleRepo) List(ctx context.Context, filter domain.ScheduleFilter) (
 			&schedule.StartTime,
 			&schedule.EndTime,
 			&schedule.SlotTime,
+			&schedule.SlotAlignment,
+			&schedule.SlotOffset,
 			&schedule.ExcludeTimes,
 			&schedule.CreatedAt,
 			&schedule.UpdatedAt,
+			&schedule.Version,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("ошибка сканирования строки расписания: %w", err)
@@ -188,7 +274,7 @@ func (r *ScheduleRepo) List(ctx context.Context, filter domain.ScheduleFilter) (
 
 func (r *ScheduleRepo) GetBySpecialistAndDate(ctx context.Context, specialistID int64, date time.Time) (*domain.Schedule, error) {
 	query := `
-		SELECT id, specialist_id, date, start_time, end_time, slot_time, exclude_times, created_at, updated_at
+		SELECT id, specialist_id, date, start_time, end_time, slot_time, slot_alignment, slot_offset, exclude_times, created_at, updated_at, version
 		FROM schedules
 		WHERE specialist_id = $1 AND date = $2
 	`
@@ -201,9 +287,12 @@ func (r *ScheduleRepo) GetBySpecialistAndDate(ctx context.Context, specialistID
 		&schedule.StartTime,
 		&schedule.EndTime,
 		&schedule.SlotTime,
+		&schedule.SlotAlignment,
+		&schedule.SlotOffset,
 		&schedule.ExcludeTimes,
 		&schedule.CreatedAt,
 		&schedule.UpdatedAt,
+		&schedule.Version,
 	)
 
 	if err != nil {
@@ -215,3 +304,202 @@ func (r *ScheduleRepo) GetBySpecialistAndDate(ctx context.Context, specialistID
 
 	return &schedule, nil
 }
+
+// ListBySpecialistsAndDate возвращает расписания нескольких специалистов на дату одним запросом
+func (r *ScheduleRepo) ListBySpecialistsAndDate(ctx context.Context, specialistIDs []int64, date time.Time) ([]domain.Schedule, error) {
+	query := `
+		SELECT id, specialist_id, date, start_time, end_time, slot_time, slot_alignment, slot_offset, exclude_times, created_at, updated_at, version
+		FROM schedules
+		WHERE specialist_id = ANY($1) AND date = $2
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписаний: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []domain.Schedule
+	for rows.Next() {
+		var schedule domain.Schedule
+		err := rows.Scan(
+			&schedule.ID,
+			&schedule.SpecialistID,
+			&schedule.Date,
+			&schedule.StartTime,
+			&schedule.EndTime,
+			&schedule.SlotTime,
+			&schedule.SlotAlignment,
+			&schedule.SlotOffset,
+			&schedule.ExcludeTimes,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+			&schedule.Version,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования расписания: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по расписаниям: %w", err)
+	}
+
+	return schedules, nil
+}
+
+func (r *ScheduleRepo) SetOverride(ctx context.Context, override domain.ScheduleOverride) (*domain.ScheduleOverride, error) {
+	query := `
+		INSERT INTO schedule_overrides (
+			specialist_id, date, is_day_off, start_time, end_time, slot_time, created_at, updated_at
+		) VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, 0), $7, $7)
+		ON CONFLICT (specialist_id, date) DO UPDATE SET
+			is_day_off = EXCLUDED.is_day_off,
+			start_time = EXCLUDED.start_time,
+			end_time = EXCLUDED.end_time,
+			slot_time = EXCLUDED.slot_time,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		override.SpecialistID,
+		override.Date,
+		override.IsDayOff,
+		override.StartTime,
+		override.EndTime,
+		override.SlotTime,
+		time.Now(),
+	).Scan(&override.ID, &override.CreatedAt, &override.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения исключения расписания: %w", err)
+	}
+
+	return &override, nil
+}
+
+func (r *ScheduleRepo) GetOverride(ctx context.Context, specialistID int64, date time.Time) (*domain.ScheduleOverride, error) {
+	query := `
+		SELECT id, specialist_id, date, is_day_off, COALESCE(start_time, ''), COALESCE(end_time, ''),
+		       COALESCE(slot_time, 0), created_at, updated_at
+		FROM schedule_overrides
+		WHERE specialist_id = $1 AND date = $2
+	`
+
+	var override domain.ScheduleOverride
+	err := r.db.QueryRow(ctx, query, specialistID, date).Scan(
+		&override.ID,
+		&override.SpecialistID,
+		&override.Date,
+		&override.IsDayOff,
+		&override.StartTime,
+		&override.EndTime,
+		&override.SlotTime,
+		&override.CreatedAt,
+		&override.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка получения исключения расписания: %w", err)
+	}
+
+	return &override, nil
+}
+
+// ListOverridesForSpecialists возвращает исключения расписания нескольких специалистов на дату одним запросом
+func (r *ScheduleRepo) ListOverridesForSpecialists(ctx context.Context, specialistIDs []int64, date time.Time) ([]domain.ScheduleOverride, error) {
+	query := `
+		SELECT id, specialist_id, date, is_day_off, COALESCE(start_time, ''), COALESCE(end_time, ''),
+		       COALESCE(slot_time, 0), created_at, updated_at
+		FROM schedule_overrides
+		WHERE specialist_id = ANY($1) AND date = $2
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения исключений расписания: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []domain.ScheduleOverride
+	for rows.Next() {
+		var override domain.ScheduleOverride
+		err := rows.Scan(
+			&override.ID,
+			&override.SpecialistID,
+			&override.Date,
+			&override.IsDayOff,
+			&override.StartTime,
+			&override.EndTime,
+			&override.SlotTime,
+			&override.CreatedAt,
+			&override.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования исключения расписания: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по исключениям расписания: %w", err)
+	}
+
+	return overrides, nil
+}
+
+func (r *ScheduleRepo) ListOverrides(ctx context.Context, specialistID int64, startDate, endDate time.Time) ([]domain.ScheduleOverride, error) {
+	query := `
+		SELECT id, specialist_id, date, is_day_off, COALESCE(start_time, ''), COALESCE(end_time, ''),
+		       COALESCE(slot_time, 0), created_at, updated_at
+		FROM schedule_overrides
+		WHERE specialist_id = $1 AND date >= $2 AND date <= $3
+		ORDER BY date
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения исключений расписания: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []domain.ScheduleOverride
+	for rows.Next() {
+		var override domain.ScheduleOverride
+		err := rows.Scan(
+			&override.ID,
+			&override.SpecialistID,
+			&override.Date,
+			&override.IsDayOff,
+			&override.StartTime,
+			&override.EndTime,
+			&override.SlotTime,
+			&override.CreatedAt,
+			&override.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка сканирования исключения расписания: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+func (r *ScheduleRepo) DeleteOverride(ctx context.Context, specialistID int64, date time.Time) error {
+	query := `DELETE FROM schedule_overrides WHERE specialist_id = $1 AND date = $2`
+
+	_, err := r.db.Exec(ctx, query, specialistID, date)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления исключения расписания: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"laps/internal/domain"
+)
+
+// lockSlot сериализует транзакции, занимающие один и тот же слот специалиста:
+// удержание и запись проверяют занятость и пишут под одной блокировкой до конца транзакции
+func lockSlot(ctx context.Context, tx pgx.Tx, specialistID int64, slotAt time.Time) error {
+	_, err := tx.Exec(ctx,
+		"SELECT pg_advisory_xact_lock($1::int, $2::int)",
+		int32(specialistID), int32(slotAt.Unix()/60),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка блокировки слота: %w", err)
+	}
+	return nil
+}
+
+// slotHeldByOthers проверяет, удерживает ли слот другой клиент
+func slotHeldByOthers(ctx context.Context, tx pgx.Tx, clientID, specialistID int64, slotAt time.Time) (bool, error) {
+	var held bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM slot_holds
+			WHERE specialist_id = $1 AND slot_at = $2 AND client_id != $3 AND expires_at > NOW()
+		)
+	`, specialistID, slotAt, clientID).Scan(&held)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки удержаний слота: %w", err)
+	}
+	return held, nil
+}
+
+// slotTaken проверяет, занят ли слот неотмененной записью или удержанием другого клиента
+func slotTaken(ctx context.Context, tx pgx.Tx, clientID, specialistID int64, slotAt time.Time) (bool, error) {
+	var booked bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM appointments
+			WHERE specialist_id = $1 AND appointment_date = $2 AND status != 'cancelled'
+		)
+	`, specialistID, slotAt).Scan(&booked)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки доступности слота: %w", err)
+	}
+	if booked {
+		return true, nil
+	}
+
+	return slotHeldByOthers(ctx, tx, clientID, specialistID, slotAt)
+}
+
+// CreateHold удерживает слот за клиентом до expiresAt. Возвращает ErrSlotTaken, если слот занят
+// записью или чужим удержанием, и ErrHoldLimit, если у клиента уже maxActive активных удержаний
+func (r *AppointmentRepo) CreateHold(ctx context.Context, token string, clientID, specialistID int64, slotAt, expiresAt time.Time, maxActive int) (*domain.SlotHold, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.CreateHold")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка строки клиента не дает параллельным запросам превысить лимит удержаний
+	if _, err := tx.Exec(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", clientID); err != nil {
+		return nil, fmt.Errorf("ошибка блокировки клиента: %w", err)
+	}
+
+	var active int
+	err = tx.QueryRow(ctx,
+		"SELECT COUNT(*) FROM slot_holds WHERE client_id = $1 AND expires_at > NOW()",
+		clientID,
+	).Scan(&active)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета удержаний клиента: %w", err)
+	}
+	if active >= maxActive {
+		return nil, ErrHoldLimit
+	}
+
+	if err := lockSlot(ctx, tx, specialistID, slotAt); err != nil {
+		return nil, err
+	}
+
+	taken, err := slotTaken(ctx, tx, clientID, specialistID, slotAt)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrSlotTaken
+	}
+
+	// Повторное удержание того же слота продлевает его, а не создает второе
+	_, err = tx.Exec(ctx,
+		"DELETE FROM slot_holds WHERE client_id = $1 AND specialist_id = $2 AND slot_at = $3",
+		clientID, specialistID, slotAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка удаления прежнего удержания слота: %w", err)
+	}
+
+	hold := domain.SlotHold{
+		Token:        token,
+		ClientID:     clientID,
+		SpecialistID: specialistID,
+		SlotAt:       slotAt,
+		ExpiresAt:    expiresAt,
+		CreatedAt:    time.Now(),
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO slot_holds (token, client_id, specialist_id, slot_at, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, hold.Token, hold.ClientID, hold.SpecialistID, hold.SlotAt, hold.ExpiresAt, hold.CreatedAt).Scan(&hold.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания удержания слота: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return &hold, nil
+}
+
+// ReleaseHold снимает удержание клиента; false, если удержания с таким ID у клиента нет
+func (r *AppointmentRepo) ReleaseHold(ctx context.Context, clientID, holdID int64) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.ReleaseHold")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM slot_holds WHERE id = $1 AND client_id = $2", holdID, clientID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка снятия удержания слота: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// PurgeExpiredHolds удаляет истекшие удержания и возвращает их количество
+func (r *AppointmentRepo) PurgeExpiredHolds(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.PurgeExpiredHolds")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM slot_holds WHERE expires_at <= NOW()")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления истекших удержаний слотов: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetHeldSlots возвращает время (HH:MM) активных удержаний специалиста на дату
+func (r *AppointmentRepo) GetHeldSlots(ctx context.Context, specialistID int64, date string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentRepo.GetHeldSlots")
+	defer span.End()
+
+	query := `
+		SELECT DISTINCT TO_CHAR(slot_at, 'HH24:MI')
+		FROM slot_holds
+		WHERE specialist_id = $1 AND DATE(slot_at) = $2 AND expires_at > NOW()
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistID, date)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения удержанных слотов: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []string
+	for rows.Next() {
+		var slot string
+		if err := rows.Scan(&slot); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования слотов: %w", err)
+		}
+		slots = append(slots, slot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return slots, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"laps/internal/domain"
+)
+
+func testSlot(hoursAhead int) time.Time {
+	return time.Now().Add(time.Duration(hoursAhead) * time.Hour).Truncate(time.Hour)
+}
+
+func bookingDTO(specialistID int64, slotAt time.Time) domain.CreateAppointmentDTO {
+	return domain.CreateAppointmentDTO{
+		SpecialistID:        specialistID,
+		AppointmentDate:     slotAt,
+		ConsultationType:    domain.ConsultationTypePrimary,
+		CommunicationMethod: domain.CommunicationMethodPhone,
+	}
+}
+
+// Удержание одного клиента и запись другого на тот же слот приходят одновременно:
+// ровно одна операция должна пройти, вторая получает ErrSlotTaken
+func TestSlotHoldRacesBooking(t *testing.T) {
+	db := testDB(t)
+	repo := NewAppointmentRepository(db)
+	specialistID := createTestSpecialist(t, db)
+	holder := createTestUser(t, db, "client")
+	booker := createTestUser(t, db, "client")
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		slotAt := testSlot(48 + i)
+
+		var (
+			wg               sync.WaitGroup
+			holdErr, bookErr error
+			start            = make(chan struct{})
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, holdErr = repo.CreateHold(ctx, uuid.NewString(), holder, specialistID, slotAt, time.Now().Add(10*time.Minute), 100)
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			_, bookErr = repo.Create(ctx, booker, bookingDTO(specialistID, slotAt))
+		}()
+		close(start)
+		wg.Wait()
+
+		switch {
+		case holdErr == nil && errors.Is(bookErr, ErrSlotTaken):
+		case bookErr == nil && errors.Is(holdErr, ErrSlotTaken):
+		default:
+			t.Fatalf("slot %s: hold err = %v, booking err = %v; want exactly one ErrSlotTaken", slotAt, holdErr, bookErr)
+		}
+	}
+}
+
+func TestBookingFromHold(t *testing.T) {
+	db := testDB(t)
+	repo := NewAppointmentRepository(db)
+	specialistID := createTestSpecialist(t, db)
+	holder := createTestUser(t, db, "client")
+	other := createTestUser(t, db, "client")
+	ctx := context.Background()
+	slotAt := testSlot(24)
+
+	hold, err := repo.CreateHold(ctx, uuid.NewString(), holder, specialistID, slotAt, time.Now().Add(10*time.Minute), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Create(ctx, other, bookingDTO(specialistID, slotAt)); !errors.Is(err, ErrSlotTaken) {
+		t.Fatalf("booking a held slot: err = %v, want ErrSlotTaken", err)
+	}
+
+	dto := bookingDTO(specialistID, slotAt)
+	dto.HoldID = &hold.ID
+	if _, err := repo.Create(ctx, holder, dto); err != nil {
+		t.Fatalf("booking from own hold: %v", err)
+	}
+
+	// Удержание израсходовано записью
+	if _, err := repo.Create(ctx, holder, dto); !errors.Is(err, ErrSlotTaken) {
+		t.Fatalf("booking the slot twice: err = %v, want ErrSlotTaken", err)
+	}
+	if released, err := repo.ReleaseHold(ctx, holder, hold.ID); err != nil || released {
+		t.Errorf("hold left after booking: released = %v, err = %v", released, err)
+	}
+}
+
+func TestCreateHoldLimit(t *testing.T) {
+	db := testDB(t)
+	repo := NewAppointmentRepository(db)
+	specialistID := createTestSpecialist(t, db)
+	client := createTestUser(t, db, "client")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.CreateHold(ctx, uuid.NewString(), client, specialistID, testSlot(24+i), time.Now().Add(10*time.Minute), 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repo.CreateHold(ctx, uuid.NewString(), client, specialistID, testSlot(30), time.Now().Add(10*time.Minute), 2); !errors.Is(err, ErrHoldLimit) {
+		t.Errorf("third hold: err = %v, want ErrHoldLimit", err)
+	}
+}
@@ -69,10 +69,18 @@ func (r *SpecialistRepo) Create(ctx context.Context, userID int64, dto domain.Cr
 		now,
 	).Scan(&id)
 
+	// Профиль может существовать, но быть удаленным: такие профили не находит GetByUserID
+	if _, ok := uniqueViolation(err); ok {
+		return 0, ErrSpecialistExists
+	}
 	if err != nil {
 		return 0, fmt.Errorf("ошибка создания специалиста: %w", err)
 	}
 
+	if err = replaceSpecialistLanguages(ctx, tx, id, dto.Languages); err != nil {
+		return 0, err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
 	}
@@ -80,20 +88,40 @@ func (r *SpecialistRepo) Create(ctx context.Context, userID int64, dto domain.Cr
 	return id, nil
 }
 
+// GetByID возвращает специалиста, если он не удален
 func (r *SpecialistRepo) GetByID(ctx context.Context, id int64) (*domain.Specialist, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.GetByID")
+	defer span.End()
+
+	return r.getByID(ctx, id, false)
+}
+
+// GetByIDWithDeleted возвращает специалиста, в том числе удаленного. Используется для истории:
+// записи и отзывы удаленного специалиста сохраняются и должны отображаться с его данными
+func (r *SpecialistRepo) GetByIDWithDeleted(ctx context.Context, id int64) (*domain.Specialist, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.GetByIDWithDeleted")
+	defer span.End()
+
+	return r.getByID(ctx, id, true)
+}
+
+func (r *SpecialistRepo) getByID(ctx context.Context, id int64, includeDeleted bool) (*domain.Specialist, error) {
 	query := `
 		SELECT s.id, s.user_id, s.type, s.experience, s.description, 
 		       s.experience_years, s.association_member, s.rating, s.reviews_count, 
 		       s.recommendation_rate, s.primary_consult_price, s.secondary_consult_price, 
-		       s.is_verified, s.profile_photo_url, s.created_at, s.updated_at,
-		       s.specialization_id,
-			   u.id, u.email, u.phone, u.first_name, u.last_name, u.middle_name, u.role, u.created_at, u.updated_at,
+		       s.is_verified, s.accepting_clients, s.profile_photo_url, s.created_at, s.updated_at, s.version, s.deleted_at, s.translations,
+		       s.specialization_id, ` + specialistLanguagesColumn + `, ` + specialistTagsColumn + `,
+			   u.id, u.email, u.phone, u.first_name, u.last_name, u.middle_name, u.role, u.is_active, u.created_at, u.updated_at, u.last_seen_at,
 			   sp.name
 		FROM specialists s
 		JOIN users u ON s.user_id = u.id
 		LEFT JOIN specializations sp ON s.specialization_id = sp.id
 		WHERE s.id = $1
 	`
+	if !includeDeleted {
+		query += " AND s.deleted_at IS NULL AND u.is_active = true"
+	}
 
 	var specialist domain.Specialist
 	var user domain.User
@@ -114,10 +142,16 @@ func (r *SpecialistRepo) GetByID(ctx context.Context, id int64) (*domain.Special
 		&specialist.PrimaryConsultPrice,
 		&specialist.SecondaryConsultPrice,
 		&specialist.IsVerified,
+		&specialist.AcceptingClients,
 		&specialist.ProfilePhotoURL,
 		&specialist.CreatedAt,
 		&specialist.UpdatedAt,
+		&specialist.Version,
+		&specialist.DeletedAt,
+		&specialist.Translations,
 		&specializationID,
+		&specialist.Languages,
+		&specialist.Tags,
 		&user.ID,
 		&user.Email,
 		&user.Phone,
@@ -125,8 +159,10 @@ func (r *SpecialistRepo) GetByID(ctx context.Context, id int64) (*domain.Special
 		&user.LastName,
 		&user.MiddleName,
 		&user.Role,
+		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.LastSeenAt,
 		&specializationName,
 	)
 
@@ -153,12 +189,185 @@ func (r *SpecialistRepo) GetByID(ctx context.Context, id int64) (*domain.Special
 		return nil, fmt.Errorf("ошибка получения опыта работы: %w", err)
 	}
 
+	specialist.Specializations, err = r.GetSpecializationsBySpecialistID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &specialist, nil
 }
 
+// ListActiveIDs возвращает идентификаторы специалистов с активной учетной записью
+func (r *SpecialistRepo) ListActiveIDs(ctx context.Context) ([]int64, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.ListActiveIDs")
+	defer span.End()
+
+	query := `
+		SELECT s.id
+		FROM specialists s
+		JOIN users u ON s.user_id = u.id
+		WHERE u.is_active = true AND s.deleted_at IS NULL
+		ORDER BY s.id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения активных специалистов: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования идентификатора специалиста: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return ids, nil
+}
+
+// sitemapSpecialistsWhere отбирает профили, открытые для поисковиков: проверенные, не удаленные,
+// с активной учетной записью
+const sitemapSpecialistsWhere = `
+		FROM specialists s
+		JOIN users u ON s.user_id = u.id
+		WHERE u.is_active = true AND s.is_verified = true AND s.deleted_at IS NULL
+`
+
+// CountForSitemap возвращает число профилей специалистов для sitemap.xml
+func (r *SpecialistRepo) CountForSitemap(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.CountForSitemap")
+	defer span.End()
+
+	var count int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*)`+sitemapSpecialistsWhere).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка подсчета специалистов для sitemap: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListForSitemap возвращает профили специалистов для sitemap.xml по возрастанию id
+func (r *SpecialistRepo) ListForSitemap(ctx context.Context, limit, offset int) ([]domain.SitemapEntry, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.ListForSitemap")
+	defer span.End()
+
+	query := `SELECT s.id, s.updated_at` + sitemapSpecialistsWhere + `ORDER BY s.id LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения специалистов для sitemap: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]domain.SitemapEntry, 0)
+	for rows.Next() {
+		var entry domain.SitemapEntry
+		if err := rows.Scan(&entry.SpecialistID, &entry.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования специалиста для sitemap: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetActivityStats считает показатели активности специалиста по чатам и записям, созданным после since.
+// Доли рассчитываются при ненулевой выборке, порог минимальной выборки применяет сервис
+func (r *SpecialistRepo) GetActivityStats(ctx context.Context, specialistID int64, since time.Time) (*domain.SpecialistActivityStats, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.GetActivityStats")
+	defer span.End()
+
+	responseQuery := `
+		WITH client_first AS (
+			SELECT cs.id AS session_id, s.user_id AS specialist_user_id, MIN(cm.created_at) AS first_at
+			FROM chat_sessions cs
+			JOIN specialists s ON cs.specialist_id = s.id
+			JOIN chat_messages cm ON cm.session_id = cs.id AND cm.sender_id = cs.client_id
+			WHERE cs.specialist_id = $1 AND cm.message_type <> 'system'
+			GROUP BY cs.id, s.user_id
+			HAVING MIN(cm.created_at) >= $2
+		),
+		responses AS (
+			SELECT cf.first_at,
+				(
+					SELECT MIN(cm.created_at)
+					FROM chat_messages cm
+					WHERE cm.session_id = cf.session_id
+						AND cm.sender_id = cf.specialist_user_id
+						AND cm.message_type <> 'system'
+						AND cm.created_at >= cf.first_at
+				) AS reply_at
+			FROM client_first cf
+		)
+		SELECT
+			COUNT(reply_at),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (reply_at - first_at)))
+		FROM responses
+		WHERE reply_at IS NOT NULL
+	`
+
+	now := time.Now()
+	stats := domain.SpecialistActivityStats{
+		PeriodDays:   int(now.Sub(since).Hours() / 24),
+		CalculatedAt: now,
+	}
+
+	err := r.db.QueryRow(ctx, responseQuery, specialistID, since).Scan(
+		&stats.ResponseSampleSize,
+		&stats.MedianFirstResponseSeconds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчета времени ответа специалиста: %w", err)
+	}
+
+	// Запись считается решенной, если она вышла из статуса pending или ее время уже прошло
+	appointmentQuery := `
+		SELECT
+			COUNT(*) FILTER (WHERE status <> 'pending' OR appointment_date < $3),
+			COUNT(*) FILTER (WHERE status IN ('paid', 'in_progress', 'completed')),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'cancelled' AND cancelled_by = 'specialist')
+		FROM appointments
+		WHERE specialist_id = $1 AND created_at >= $2
+	`
+
+	var confirmed, cancelledBySpecialist int
+	err = r.db.QueryRow(ctx, appointmentQuery, specialistID, since, now).Scan(
+		&stats.ConfirmationSampleSize,
+		&confirmed,
+		&stats.CancellationSampleSize,
+		&cancelledBySpecialist,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчета статистики записей специалиста: %w", err)
+	}
+
+	if stats.ConfirmationSampleSize > 0 {
+		rate := float64(confirmed) / float64(stats.ConfirmationSampleSize)
+		stats.ConfirmationRate = &rate
+	}
+	if stats.CancellationSampleSize > 0 {
+		rate := float64(cancelledBySpecialist) / float64(stats.CancellationSampleSize)
+		stats.SpecialistCancellationRate = &rate
+	}
+
+	return &stats, nil
+}
+
 func (r *SpecialistRepo) GetByUserID(ctx context.Context, userID int64) (*domain.Specialist, error) {
 	query := `
-		SELECT id FROM specialists WHERE user_id = $1
+		SELECT id FROM specialists WHERE user_id = $1 AND deleted_at IS NULL
 	`
 
 	var specialistID int64
@@ -227,22 +436,100 @@ func (r *SpecialistRepo) Update(ctx context.Context, id int64, dto domain.Update
 		argIndex++
 	}
 
+	if dto.AcceptingClients != nil {
+		setClauses = append(setClauses, fmt.Sprintf("accepting_clients = $%d", argIndex))
+		args = append(args, *dto.AcceptingClients)
+		argIndex++
+	}
+
 	setClauses = append(setClauses, fmt.Sprintf("updated_at = $%d", argIndex))
 	args = append(args, time.Now())
 	argIndex++
 
-	if len(setClauses) == 1 {
+	if len(setClauses) == 1 && dto.Languages == nil {
 		return nil
 	}
 
+	setClauses = append(setClauses, "version = version + 1")
+
+	// Текущее состояние читается под блокировкой строки, чтобы история цен и событие изменения
+	// не потеряли параллельное изменение
+	oldState, err := specialistStateForEvent(ctx, tx, id, true)
+	if err != nil {
+		return err
+	}
+	oldPrimaryPrice, oldSecondaryPrice := oldState.PrimaryConsultPrice, oldState.SecondaryConsultPrice
+
 	query += strings.Join(setClauses, ", ")
 	query += fmt.Sprintf(" WHERE id = $%d", argIndex)
 	args = append(args, id)
+	argIndex++
 
-	_, err = tx.Exec(ctx, query, args...)
+	// При переданной версии строка обновляется, только если ее никто не изменил с момента чтения
+	if dto.ExpectedVersion != nil {
+		query += fmt.Sprintf(" AND version = $%d", argIndex)
+		args = append(args, *dto.ExpectedVersion)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("ошибка обновления специалиста: %w", err)
 	}
+	if tag.RowsAffected() == 0 && dto.ExpectedVersion != nil {
+		return ErrVersionConflict
+	}
+
+	if dto.Languages != nil {
+		if err = replaceSpecialistLanguages(ctx, tx, id, *dto.Languages); err != nil {
+			return err
+		}
+	}
+
+	newPrimaryPrice, newSecondaryPrice := oldPrimaryPrice, oldSecondaryPrice
+	if dto.PrimaryConsultPrice != nil {
+		newPrimaryPrice = *dto.PrimaryConsultPrice
+	}
+	if dto.SecondaryConsultPrice != nil {
+		newSecondaryPrice = *dto.SecondaryConsultPrice
+	}
+
+	if newPrimaryPrice != oldPrimaryPrice || newSecondaryPrice != oldSecondaryPrice {
+		historyQuery := `
+			INSERT INTO specialist_price_history (
+				specialist_id, old_primary_price, new_primary_price,
+				old_secondary_price, new_secondary_price, changed_at, changed_by_user_id
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		_, err = tx.Exec(ctx, historyQuery,
+			id, oldPrimaryPrice, newPrimaryPrice, oldSecondaryPrice, newSecondaryPrice, time.Now(), dto.ChangedBy,
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения истории цен специалиста: %w", err)
+		}
+	}
+
+	newState, err := specialistStateForEvent(ctx, tx, id, false)
+	if err != nil {
+		return err
+	}
+
+	payload, err := jsonDiff(oldState, newState)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		err = appendEventTx(ctx, tx, domain.Event{
+			AggregateType: domain.EventAggregateSpecialist,
+			AggregateID:   id,
+			EventType:     domain.EventSpecialistUpdated,
+			Payload:       payload,
+			ActorID:       dto.ChangedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
 	updateRatingQuery := `
 		UPDATE specialists
@@ -263,54 +550,140 @@ func (r *SpecialistRepo) Update(ctx context.Context, id int64, dto domain.Update
 	return nil
 }
 
+// GetPriceHistory возвращает историю изменения цен специалиста, начиная с последнего изменения
+func (r *SpecialistRepo) GetPriceHistory(ctx context.Context, specialistID int64) ([]domain.SpecialistPriceChange, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.GetPriceHistory")
+	defer span.End()
+
+	query := `
+		SELECT id, specialist_id, old_primary_price, new_primary_price,
+		       old_secondary_price, new_secondary_price, changed_at, changed_by_user_id
+		FROM specialist_price_history
+		WHERE specialist_id = $1
+		ORDER BY changed_at DESC, id DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, specialistID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории цен специалиста: %w", err)
+	}
+	defer rows.Close()
+
+	history := []domain.SpecialistPriceChange{}
+	for rows.Next() {
+		var change domain.SpecialistPriceChange
+		if err := rows.Scan(
+			&change.ID, &change.SpecialistID, &change.OldPrimaryPrice, &change.NewPrimaryPrice,
+			&change.OldSecondaryPrice, &change.NewSecondaryPrice, &change.ChangedAt, &change.ChangedByUserID,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования истории цен: %w", err)
+		}
+		history = append(history, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return history, nil
+}
+
+// Delete мягко удаляет специалиста: профиль скрывается из каталога и недоступен для записи,
+// а его записи, отзывы и чаты сохраняются
 func (r *SpecialistRepo) Delete(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.Delete")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE specialists SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления специалиста: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSpecialistNotFound
+	}
+
+	return nil
+}
+
+// Restore восстанавливает мягко удаленного специалиста
+func (r *SpecialistRepo) Restore(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.Restore")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, `
+		UPDATE specialists SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка восстановления специалиста: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSpecialistNotFound
+	}
+
+	return nil
+}
+
+// DeletePermanently удаляет специалиста из базы вместе со всеми связанными данными, включая записи и отзывы
+func (r *SpecialistRepo) DeletePermanently(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.DeletePermanently")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Метки специалиста удаляются каскадно, поэтому счетчики их использования уменьшаются заранее
+	_, err = tx.Exec(ctx, `
+		UPDATE tags SET usage_count = GREATEST(usage_count - 1, 0)
+		WHERE id IN (SELECT tag_id FROM specialist_tags WHERE specialist_id = $1)
+	`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления счетчиков меток: %w", err)
+	}
+
 	query := `DELETE FROM specialists WHERE id = $1`
 
-	_, err := r.db.Exec(ctx, query, id)
+	_, err = tx.Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("ошибка удаления специалиста: %w", err)
 	}
 
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
 	return nil
 }
 
-func (r *SpecialistRepo) List(ctx context.Context, specialistType *domain.SpecialistType, specializationID *int64, limit, offset int) ([]domain.Specialist, error) {
+func (r *SpecialistRepo) List(ctx context.Context, filter domain.SpecialistFilter) ([]domain.Specialist, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.List")
+	defer span.End()
+
 	baseQuery := `
 		SELECT s.id, s.user_id, s.type, s.experience, s.description, 
 		       s.experience_years, s.association_member, s.rating, s.reviews_count, 
 		       s.recommendation_rate, s.primary_consult_price, s.secondary_consult_price, 
-		       s.is_verified, s.profile_photo_url, s.created_at, s.updated_at, s.specialization_id,
+		       s.is_verified, s.accepting_clients, s.profile_photo_url, s.created_at, s.updated_at, s.version, s.deleted_at, s.translations, s.specialization_id,
+		       ` + specialistLanguagesColumn + `, ` + specialistTagsColumn + `,
 			   u.id, u.email, u.phone, u.first_name, u.last_name, u.middle_name, u.role, 
-			   u.is_active, u.created_at, u.updated_at,
+			   u.is_active, u.created_at, u.updated_at, u.last_seen_at,
                sp.name
 		FROM specialists s
 		JOIN users u ON s.user_id = u.id
         LEFT JOIN specializations sp ON s.specialization_id = sp.id
 	`
 
-	var whereClauseConditions []string
-	var args []interface{}
-	argIndex := 1
-
-	if specialistType != nil {
-		whereClauseConditions = append(whereClauseConditions, fmt.Sprintf("s.type = $%d", argIndex))
-		args = append(args, *specialistType)
-		argIndex++
-	}
-
-	if specializationID != nil {
-		whereClauseConditions = append(whereClauseConditions, fmt.Sprintf("s.specialization_id = $%d", argIndex))
-		args = append(args, *specializationID)
-		argIndex++
-	}
-
-	var whereClause string
-	if len(whereClauseConditions) > 0 {
-		whereClause = " WHERE " + strings.Join(whereClauseConditions, " AND ")
-	}
+	whereClause, args := specialistFilterWhere(filter)
+	argIndex := len(args) + 1
 
 	orderLimitClause := fmt.Sprintf(" ORDER BY s.id LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
+	args = append(args, filter.Limit, filter.Offset)
 
 	query := baseQuery + whereClause + orderLimitClause
 
@@ -341,10 +714,16 @@ func (r *SpecialistRepo) List(ctx context.Context, specialistType *domain.Specia
 			&specialist.PrimaryConsultPrice,
 			&specialist.SecondaryConsultPrice,
 			&specialist.IsVerified,
+			&specialist.AcceptingClients,
 			&specialist.ProfilePhotoURL,
 			&specialist.CreatedAt,
 			&specialist.UpdatedAt,
+			&specialist.Version,
+			&specialist.DeletedAt,
+			&specialist.Translations,
 			&specialist.SpecializationID,
+			&specialist.Languages,
+			&specialist.Tags,
 			&user.ID,
 			&user.Email,
 			&user.Phone,
@@ -355,6 +734,7 @@ func (r *SpecialistRepo) List(ctx context.Context, specialistType *domain.Specia
 			&isActive,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.LastSeenAt,
 			&specializationName,
 		)
 
@@ -385,48 +765,177 @@ func (r *SpecialistRepo) List(ctx context.Context, specialistType *domain.Specia
 		if err == nil {
 			specialists[i].WorkExperience = workExperience
 		}
+
+		specializations, err := r.GetSpecializationsBySpecialistID(ctx, specialist.ID)
+		if err == nil {
+			specialists[i].Specializations = specializations
+		}
 	}
 
 	return specialists, nil
 }
 
-func (r *SpecialistRepo) CountByFilter(ctx context.Context, specialistType *domain.SpecialistType, specializationID *int64) (int, error) {
+func (r *SpecialistRepo) CountByFilter(ctx context.Context, filter domain.SpecialistFilter) (int, error) {
 	baseQuery := `
 		SELECT COUNT(*)
 		FROM specialists s
 		JOIN users u ON s.user_id = u.id
 	`
 
-	var whereClauseConditions []string
+	whereClause, args := specialistFilterWhere(filter)
+
+	query := baseQuery + whereClause
+
+	var count int
+	err := r.db.QueryRow(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта специалистов: %w", err)
+	}
+
+	return count, nil
+}
+
+// specialistFilterWhere строит условие WHERE для выборки специалистов по фильтру
+func specialistFilterWhere(filter domain.SpecialistFilter) (string, []interface{}) {
+	var conditions []string
 	var args []interface{}
 	argIndex := 1
 
-	if specialistType != nil {
-		whereClauseConditions = append(whereClauseConditions, fmt.Sprintf("s.type = $%d", argIndex))
-		args = append(args, *specialistType)
+	// Профили деактивированных и удаленных аккаунтов не показываются в каталоге
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "s.deleted_at IS NULL", "u.is_active = true")
+	}
+
+	if filter.Type != nil {
+		conditions = append(conditions, fmt.Sprintf("s.type = $%d", argIndex))
+		args = append(args, *filter.Type)
 		argIndex++
 	}
 
-	if specializationID != nil {
-		whereClauseConditions = append(whereClauseConditions, fmt.Sprintf("s.specialization_id = $%d", argIndex))
-		args = append(args, *specializationID)
+	if filter.SpecializationID != nil {
+		conditions = append(conditions, specialistInSpecializationSubtree(fmt.Sprintf("$%d", argIndex)))
+		args = append(args, *filter.SpecializationID)
 		argIndex++
 	}
 
-	var whereClause string
-	if len(whereClauseConditions) > 0 {
-		whereClause = " WHERE " + strings.Join(whereClauseConditions, " AND ")
+	if filter.AcceptingClients != nil {
+		conditions = append(conditions, fmt.Sprintf("s.accepting_clients = $%d", argIndex))
+		args = append(args, *filter.AcceptingClients)
+		argIndex++
 	}
 
-	query := baseQuery + whereClause
+	if filter.MinExperienceYears != nil {
+		conditions = append(conditions, fmt.Sprintf("s.experience_years >= $%d", argIndex))
+		args = append(args, *filter.MinExperienceYears)
+		argIndex++
+	}
 
-	var count int
-	err := r.db.QueryRow(ctx, query, args...).Scan(&count)
+	if filter.Language != nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM specialist_languages sl WHERE sl.specialist_id = s.id AND sl.language_code = $%d)", argIndex))
+		args = append(args, *filter.Language)
+		argIndex++
+	}
+
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, fmt.Sprintf(`s.id IN (
+			SELECT st.specialist_id
+			FROM specialist_tags st
+			JOIN tags t ON t.id = st.tag_id
+			WHERE t.name = ANY($%d::text[])
+			GROUP BY st.specialist_id
+			HAVING COUNT(*) = $%d
+		)`, argIndex, argIndex+1))
+		args = append(args, filter.Tags, len(filter.Tags))
+		argIndex += 2
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// Подзапрос, возвращающий отсортированные коды языков специалиста s
+const specialistLanguagesColumn = `COALESCE((
+			SELECT array_agg(sl.language_code ORDER BY sl.language_code)
+			FROM specialist_languages sl WHERE sl.specialist_id = s.id
+		), '{}')`
+
+// Подзапрос, возвращающий отсортированные метки специалиста s
+const specialistTagsColumn = `COALESCE((
+			SELECT array_agg(t.name ORDER BY t.name)
+			FROM specialist_tags st JOIN tags t ON t.id = st.tag_id
+			WHERE st.specialist_id = s.id
+		), '{}')`
+
+// specialistEventState изменяемые через Update поля профиля специалиста, из которых строится событие изменения
+type specialistEventState struct {
+	SpecializationID      int64    `json:"specialization_id"`
+	Experience            int      `json:"experience"`
+	Description           *string  `json:"description"`
+	ExperienceYears       int      `json:"experience_years"`
+	AssociationMember     bool     `json:"association_member"`
+	PrimaryConsultPrice   float64  `json:"primary_consult_price"`
+	SecondaryConsultPrice float64  `json:"secondary_consult_price"`
+	AcceptingClients      bool     `json:"accepting_clients"`
+	Languages             []string `json:"languages"`
+}
+
+// specialistStateForEvent читает состояние профиля специалиста внутри транзакции;
+// при forUpdate строка специалиста блокируется до конца транзакции
+func specialistStateForEvent(ctx context.Context, tx pgx.Tx, id int64, forUpdate bool) (*specialistEventState, error) {
+	query := `
+		SELECT s.specialization_id, s.experience, s.description, s.experience_years, s.association_member,
+		       s.primary_consult_price, s.secondary_consult_price, s.accepting_clients,
+		       COALESCE((
+		           SELECT array_agg(sl.language_code ORDER BY sl.language_code)
+		           FROM specialist_languages sl
+		           WHERE sl.specialist_id = s.id
+		       ), '{}')
+		FROM specialists s
+		WHERE s.id = $1
+	`
+	if forUpdate {
+		query += " FOR UPDATE OF s"
+	}
+
+	var state specialistEventState
+	err := tx.QueryRow(ctx, query, id).Scan(
+		&state.SpecializationID, &state.Experience, &state.Description, &state.ExperienceYears,
+		&state.AssociationMember, &state.PrimaryConsultPrice, &state.SecondaryConsultPrice, &state.AcceptingClients,
+		&state.Languages,
+	)
 	if err != nil {
-		return 0, fmt.Errorf("ошибка подсчёта специалистов: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("специалист с id %d не найден", id)
+		}
+		return nil, fmt.Errorf("ошибка получения текущего состояния специалиста: %w", err)
 	}
 
-	return count, nil
+	return &state, nil
+}
+
+// replaceSpecialistLanguages заменяет список языков специалиста в рамках транзакции
+func replaceSpecialistLanguages(ctx context.Context, tx pgx.Tx, specialistID int64, languages []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM specialist_languages WHERE specialist_id = $1`, specialistID); err != nil {
+		return fmt.Errorf("ошибка удаления языков специалиста: %w", err)
+	}
+
+	if len(languages) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO specialist_languages (specialist_id, language_code, created_at)
+		SELECT $1, code, $3 FROM unnest($2::text[]) AS code
+		ON CONFLICT DO NOTHING
+	`, specialistID, languages, time.Now())
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения языков специалиста: %w", err)
+	}
+
+	return nil
 }
 
 func (r *SpecialistRepo) AddEducation(ctx context.Context, specialistID int64, education domain.EducationDTO) (int64, error) {
@@ -730,9 +1239,108 @@ func (r *SpecialistRepo) RemoveSpecialization(ctx context.Context, specialistID,
 	return nil
 }
 
+// SetSpecializations заменяет все дополнительные специализации специалиста набором ids в одной транзакции.
+// Если каких-то специализаций нет, ничего не меняется и возвращается ErrSpecializationNotFound с их ID
+func (r *SpecialistRepo) SetSpecializations(ctx context.Context, specialistID int64, ids []int64) error {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.SetSpecializations")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка строк не дает удалить специализацию между проверкой и вставкой
+	rows, err := tx.Query(ctx, "SELECT id FROM specializations WHERE id = ANY($1) FOR SHARE", ids)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки специализаций: %w", err)
+	}
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("ошибка проверки специализаций: %w", err)
+	}
+
+	found := make(map[int64]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+	var missing []int64
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %v", ErrSpecializationNotFound, missing)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM specialist_specializations WHERE specialist_id = $1", specialistID); err != nil {
+		return fmt.Errorf("ошибка удаления специализаций: %w", err)
+	}
+
+	query := `
+		INSERT INTO specialist_specializations (specialist_id, specialization_id, created_at)
+		SELECT $1, id, $3 FROM unnest($2::bigint[]) AS id
+		ON CONFLICT (specialist_id, specialization_id) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, specialistID, ids, time.Now()); err != nil {
+		return fmt.Errorf("ошибка добавления специализаций: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка подтверждения транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// specialistInSpecializationSubtree возвращает условие: основная или дополнительная специализация
+// специалиста s входит в поддерево специализации из параметра param
+func specialistInSpecializationSubtree(param string) string {
+	subtree := specializationSubtreeSQL(param)
+	return `(s.specialization_id IN (` + subtree + `)
+		OR EXISTS (
+			SELECT 1 FROM specialist_specializations sps
+			WHERE sps.specialist_id = s.id AND sps.specialization_id IN (` + subtree + `)
+		))`
+}
+
+// SetTranslations добавляет или заменяет переводы профиля специалиста по языкам из translations,
+// удаляет переводы на языки removed и увеличивает версию профиля
+func (r *SpecialistRepo) SetTranslations(ctx context.Context, id int64, translations domain.Translations, removed []string) error {
+	ctx, span := tracer.Start(ctx, "SpecialistRepo.SetTranslations")
+	defer span.End()
+
+	query := `
+		UPDATE specialists
+		SET translations = (translations || $2::jsonb) - $3::text[], version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	// NULL в любом из аргументов обнулил бы все переводы
+	if translations == nil {
+		translations = domain.Translations{}
+	}
+	if removed == nil {
+		removed = []string{}
+	}
+
+	tag, err := r.db.Exec(ctx, query, id, translations, removed)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения переводов специалиста: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSpecialistNotFound
+	}
+
+	return nil
+}
+
+// GetSpecializationsBySpecialistID возвращает специализации, связанные со специалистом через specialist_specializations
 func (r *SpecialistRepo) GetSpecializationsBySpecialistID(ctx context.Context, specialistID int64) ([]domain.Specialization, error) {
 	query := `
-		SELECT s.id, s.name, s.description, s.type, s.is_active, s.created_at, s.updated_at
+		SELECT s.id, s.parent_id, s.name, s.description, s.type, s.is_active, s.created_at, s.updated_at, s.translations
 		FROM specializations s
 		JOIN specialist_specializations ss ON s.id = ss.specialization_id
 		WHERE ss.specialist_id = $1
@@ -750,12 +1358,14 @@ func (r *SpecialistRepo) GetSpecializationsBySpecialistID(ctx context.Context, s
 		var spec domain.Specialization
 		if err := rows.Scan(
 			&spec.ID,
+			&spec.ParentID,
 			&spec.Name,
 			&spec.Description,
 			&spec.Type,
 			&spec.IsActive,
 			&spec.CreatedAt,
 			&spec.UpdatedAt,
+			&spec.Translations,
 		); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования строки специализации: %w", err)
 		}
@@ -784,3 +1394,32 @@ func (r *SpecialistRepo) UpdateProfilePhoto(ctx context.Context, id int64, photo
 
 	return nil
 }
+
+// SetVerified меняет признак верификации специалиста и возвращает предыдущее значение
+func (r *SpecialistRepo) SetVerified(ctx context.Context, id int64, isVerified bool) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var oldValue bool
+	err = tx.QueryRow(ctx, "SELECT is_verified FROM specialists WHERE id = $1 FOR UPDATE", id).Scan(&oldValue)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, fmt.Errorf("специалист с id %d не найден", id)
+		}
+		return false, fmt.Errorf("ошибка получения специалиста: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE specialists SET is_verified = $1, updated_at = $2 WHERE id = $3", isVerified, time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления верификации специалиста: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return oldValue, nil
+}
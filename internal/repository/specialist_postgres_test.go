@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+// Две вкладки администратора прочитали профиль одной версии: первое сохранение проходит
+// и увеличивает версию, второе с устаревшей версией получает ErrVersionConflict
+func TestSpecialistUpdateLostUpdate(t *testing.T) {
+	db := testDB(t)
+	repo := NewSpecialistRepository(db)
+	ctx := context.Background()
+	id := createTestSpecialist(t, db)
+
+	read, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := read.Version
+
+	firstPrice, secondPrice := 4000.0, 5000.0
+	if err := repo.Update(ctx, id, domain.UpdateSpecialistDTO{PrimaryConsultPrice: &firstPrice, ExpectedVersion: &seen}); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	err = repo.Update(ctx, id, domain.UpdateSpecialistDTO{PrimaryConsultPrice: &secondPrice, ExpectedVersion: &seen})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale save: err = %v, want ErrVersionConflict", err)
+	}
+
+	current, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Version != seen+1 || current.PrimaryConsultPrice != firstPrice {
+		t.Errorf("version = %d, price = %v; want %d and %v", current.Version, current.PrimaryConsultPrice, seen+1, firstPrice)
+	}
+
+	// После перечитывания сохранение проходит
+	if err := repo.Update(ctx, id, domain.UpdateSpecialistDTO{PrimaryConsultPrice: &secondPrice, ExpectedVersion: &current.Version}); err != nil {
+		t.Errorf("save after refetch: %v", err)
+	}
+}
+
+func TestScheduleReplaceWeekLostUpdate(t *testing.T) {
+	db := testDB(t)
+	repo := NewScheduleRepository(db)
+	ctx := context.Background()
+	specialistID := createTestSpecialist(t, db)
+
+	start := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 7)
+	end := start.AddDate(0, 0, 6)
+	week := func(startTime string) []domain.Schedule {
+		return []domain.Schedule{{
+			SpecialistID: specialistID,
+			Date:         start,
+			StartTime:    startTime,
+			EndTime:      "18:00",
+			SlotTime:     60,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}}
+	}
+
+	empty := 0
+	version, err := repo.ReplaceWeek(ctx, specialistID, start, end, &empty, week("09:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := version
+	if _, err := repo.ReplaceWeek(ctx, specialistID, start, end, &seen, week("10:00")); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	if _, err := repo.ReplaceWeek(ctx, specialistID, start, end, &seen, week("11:00")); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale save: err = %v, want ErrVersionConflict", err)
+	}
+
+	schedule, err := repo.GetBySpecialistAndDate(ctx, specialistID, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(schedule.StartTime, "10:00") || schedule.Version != seen+1 {
+		t.Errorf("schedule = %s at version %d, want 10:00 at version %d", schedule.StartTime, schedule.Version, seen+1)
+	}
+}
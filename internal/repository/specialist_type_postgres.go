@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type SpecialistTypeRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSpecialistTypeRepository(db *pgxpool.Pool) SpecialistTypeRepository {
+	return &SpecialistTypeRepo{db: db}
+}
+
+// GetAll возвращает весь справочник типов специалистов, включая неактивные типы
+func (r *SpecialistTypeRepo) GetAll(ctx context.Context) ([]domain.SpecialistTypeInfo, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistTypeRepo.GetAll")
+	defer span.End()
+
+	rows, err := r.db.Query(ctx, `SELECT code, label, is_active FROM specialist_types ORDER BY label, code`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения типов специалистов: %w", err)
+	}
+	defer rows.Close()
+
+	var types []domain.SpecialistTypeInfo
+	for rows.Next() {
+		var specialistType domain.SpecialistTypeInfo
+		if err := rows.Scan(&specialistType.Code, &specialistType.Label, &specialistType.IsActive); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования типа специалиста: %w", err)
+		}
+		types = append(types, specialistType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке типов специалистов: %w", err)
+	}
+
+	return types, nil
+}
@@ -17,6 +17,20 @@ type SpecializationRepo struct {
 	db *pgxpool.Pool
 }
 
+// Ключ advisory-блокировки для изменений иерархии специализаций
+const specializationTreeLockKey = 7340001
+
+// specializationSubtreeSQL возвращает подзапрос, выбирающий id специализации из параметра param
+// и id всех ее потомков
+func specializationSubtreeSQL(param string) string {
+	return `WITH RECURSIVE subtree AS (
+			SELECT id FROM specializations WHERE id = ` + param + `
+			UNION
+			SELECT c.id FROM specializations c JOIN subtree ON c.parent_id = subtree.id
+		)
+		SELECT id FROM subtree`
+}
+
 func NewSpecializationRepository(db *pgxpool.Pool) *SpecializationRepo {
 	return &SpecializationRepo{
 		db: db,
@@ -25,8 +39,8 @@ func NewSpecializationRepository(db *pgxpool.Pool) *SpecializationRepo {
 
 func (r *SpecializationRepo) Create(ctx context.Context, dto domain.CreateSpecializationDTO) (int64, error) {
 	query := `
-		INSERT INTO specializations (name, description, type, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $5)
+		INSERT INTO specializations (name, description, type, is_active, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
 		RETURNING id
 	`
 
@@ -37,9 +51,13 @@ func (r *SpecializationRepo) Create(ctx context.Context, dto domain.CreateSpecia
 		dto.Description,
 		dto.Type,
 		dto.IsActive,
+		dto.ParentID,
 		now,
 	).Scan(&id)
 
+	if constraint, ok := foreignKeyViolation(err); ok && constraint == "specializations_type_fkey" {
+		return 0, ErrUnknownSpecialistType
+	}
 	if err != nil {
 		return 0, fmt.Errorf("ошибка создания специализации: %w", err)
 	}
@@ -49,7 +67,7 @@ func (r *SpecializationRepo) Create(ctx context.Context, dto domain.CreateSpecia
 
 func (r *SpecializationRepo) GetByID(ctx context.Context, id int64) (*domain.Specialization, error) {
 	query := `
-		SELECT id, name, description, type, is_active, created_at, updated_at
+		SELECT id, parent_id, name, description, type, is_active, created_at, updated_at, translations
 		FROM specializations
 		WHERE id = $1
 	`
@@ -57,12 +75,14 @@ func (r *SpecializationRepo) GetByID(ctx context.Context, id int64) (*domain.Spe
 	var specialization domain.Specialization
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&specialization.ID,
+		&specialization.ParentID,
 		&specialization.Name,
 		&specialization.Description,
 		&specialization.Type,
 		&specialization.IsActive,
 		&specialization.CreatedAt,
 		&specialization.UpdatedAt,
+		&specialization.Translations,
 	)
 
 	if err != nil {
@@ -98,6 +118,14 @@ func (r *SpecializationRepo) Update(ctx context.Context, id int64, dto domain.Up
 		argID++
 	}
 
+	if dto.RemoveParent {
+		setValues = append(setValues, "parent_id = NULL")
+	} else if dto.ParentID != nil {
+		setValues = append(setValues, fmt.Sprintf("parent_id = $%d", argID))
+		args = append(args, *dto.ParentID)
+		argID++
+	}
+
 	setValues = append(setValues, fmt.Sprintf("updated_at = $%d", argID))
 	args = append(args, time.Now())
 	argID++
@@ -110,14 +138,81 @@ func (r *SpecializationRepo) Update(ctx context.Context, id int64, dto domain.Up
 		WHERE id = $%d
 	`, strings.Join(setValues, ", "), argID)
 
-	_, err := r.db.Exec(ctx, query, args...)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if dto.ParentID != nil && !dto.RemoveParent {
+		// Сериализуем изменения иерархии, чтобы два параллельных переноса не образовали цикл
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, specializationTreeLockKey); err != nil {
+			return fmt.Errorf("ошибка блокировки дерева специализаций: %w", err)
+		}
+
+		var cyclic bool
+		cycleQuery := `SELECT $2 IN (` + specializationSubtreeSQL("$1") + `)`
+		if err := tx.QueryRow(ctx, cycleQuery, id, *dto.ParentID).Scan(&cyclic); err != nil {
+			return fmt.Errorf("ошибка проверки иерархии специализаций: %w", err)
+		}
+		if cyclic {
+			return ErrSpecializationCycle
+		}
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
 		return fmt.Errorf("ошибка обновления специализации: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// HasChildren проверяет, есть ли у специализации дочерние специализации
+// SetTranslations добавляет или заменяет переводы специализации по языкам из translations
+// и удаляет переводы на языки removed
+func (r *SpecializationRepo) SetTranslations(ctx context.Context, id int64, translations domain.Translations, removed []string) error {
+	ctx, span := tracer.Start(ctx, "SpecializationRepo.SetTranslations")
+	defer span.End()
+
+	query := `
+		UPDATE specializations
+		SET translations = (translations || $2::jsonb) - $3::text[], updated_at = NOW()
+		WHERE id = $1
+	`
+
+	// NULL в любом из аргументов обнулил бы все переводы
+	if translations == nil {
+		translations = domain.Translations{}
+	}
+	if removed == nil {
+		removed = []string{}
+	}
+
+	tag, err := r.db.Exec(ctx, query, id, translations, removed)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения переводов специализации: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("специализация с id %d не найдена", id)
+	}
+
 	return nil
 }
 
+func (r *SpecializationRepo) HasChildren(ctx context.Context, id int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM specializations WHERE parent_id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки дочерних специализаций: %w", err)
+	}
+
+	return exists, nil
+}
+
 func (r *SpecializationRepo) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM specializations WHERE id = $1`
 
@@ -131,13 +226,13 @@ func (r *SpecializationRepo) Delete(ctx context.Context, id int64) error {
 
 func (r *SpecializationRepo) List(ctx context.Context, filter domain.SpecializationFilter) ([]domain.Specialization, error) {
 	baseQuery := `
-		SELECT s.id, s.name, s.description, s.type, s.is_active, s.created_at, s.updated_at
+		SELECT s.id, s.parent_id, s.name, s.description, s.type, s.is_active, s.created_at, s.updated_at, s.translations
 		FROM specializations s
 	`
 
 	if filter.SpecialistID != nil {
 		baseQuery = `
-			SELECT s.id, s.name, s.description, s.type, s.is_active, s.created_at, s.updated_at
+			SELECT s.id, s.parent_id, s.name, s.description, s.type, s.is_active, s.created_at, s.updated_at, s.translations
 			FROM specializations s
 			JOIN specialist_specializations ss ON ss.specialization_id = s.id
 			WHERE ss.specialist_id = $1
@@ -183,6 +278,12 @@ func (r *SpecializationRepo) List(ctx context.Context, filter domain.Specializat
 		argID++
 	}
 
+	if filter.ParentID != nil {
+		conditions = append(conditions, fmt.Sprintf("s.parent_id = $%d", argID))
+		args = append(args, *filter.ParentID)
+		argID++
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		if filter.SpecialistID != nil {
@@ -192,9 +293,13 @@ func (r *SpecializationRepo) List(ctx context.Context, filter domain.Specializat
 		}
 	}
 
-	limitOffset := fmt.Sprintf("LIMIT $%d OFFSET $%d", argID, argID+1)
-	args = append(args, filter.Limit, filter.Offset)
-	argID += 2
+	// Limit <= 0 означает выборку без пагинации (используется для построения дерева)
+	limitOffset := ""
+	if filter.Limit > 0 {
+		limitOffset = fmt.Sprintf("LIMIT $%d OFFSET $%d", argID, argID+1)
+		args = append(args, filter.Limit, filter.Offset)
+		argID += 2
+	}
 
 	orderClause := "ORDER BY name ASC"
 
@@ -211,12 +316,14 @@ func (r *SpecializationRepo) List(ctx context.Context, filter domain.Specializat
 		var specialization domain.Specialization
 		if err := rows.Scan(
 			&specialization.ID,
+			&specialization.ParentID,
 			&specialization.Name,
 			&specialization.Description,
 			&specialization.Type,
 			&specialization.IsActive,
 			&specialization.CreatedAt,
 			&specialization.UpdatedAt,
+			&specialization.Translations,
 		); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования строки специализации: %w", err)
 		}
@@ -284,6 +391,12 @@ func (r *SpecializationRepo) CountByFilter(ctx context.Context, filter domain.Sp
 		argID++
 	}
 
+	if filter.ParentID != nil {
+		conditions = append(conditions, fmt.Sprintf("s.parent_id = $%d", argID))
+		args = append(args, *filter.ParentID)
+		argID++
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		if filter.SpecialistID != nil {
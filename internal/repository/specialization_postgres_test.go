@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"laps/internal/domain"
+)
+
+func TestSpecializationHierarchy(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	specializations := NewSpecializationRepository(db)
+	specialists := NewSpecialistRepository(db)
+
+	create := func(name string, parentID *int64) int64 {
+		t.Helper()
+		id, err := specializations.Create(ctx, domain.CreateSpecializationDTO{
+			Name:     name + " " + uniqueSuffix(),
+			Type:     domain.SpecialistTypeLawyer,
+			IsActive: true,
+			ParentID: parentID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	root := create("Право", nil)
+	child := create("Семейное право", &root)
+	grandchild := create("Алименты", &child)
+	other := create("Налоги", nil)
+
+	t.Run("cycle is rejected", func(t *testing.T) {
+		for _, parentID := range []int64{root, grandchild} {
+			err := specializations.Update(ctx, root, domain.UpdateSpecializationDTO{ParentID: &parentID})
+			if !errors.Is(err, ErrSpecializationCycle) {
+				t.Errorf("move under %d: err = %v, want ErrSpecializationCycle", parentID, err)
+			}
+		}
+		if hasChildren, err := specializations.HasChildren(ctx, root); err != nil || !hasChildren {
+			t.Errorf("HasChildren(root) = %v, %v", hasChildren, err)
+		}
+	})
+
+	t.Run("filter includes descendants", func(t *testing.T) {
+		setSpecialization := func(specialistID, specializationID int64) {
+			t.Helper()
+			if _, err := db.Exec(ctx, "UPDATE specialists SET specialization_id = $2 WHERE id = $1", specialistID, specializationID); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		primary := createTestSpecialist(t, db)
+		setSpecialization(primary, grandchild)
+
+		additional := createTestSpecialist(t, db)
+		setSpecialization(additional, other)
+		if err := specialists.SetSpecializations(ctx, additional, []int64{child}); err != nil {
+			t.Fatal(err)
+		}
+
+		unrelated := createTestSpecialist(t, db)
+		setSpecialization(unrelated, other)
+
+		found, err := specialists.List(ctx, domain.SpecialistFilter{SpecializationID: &root, Limit: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[int64]bool, len(found))
+		for _, specialist := range found {
+			got[specialist.ID] = true
+		}
+		if !got[primary] || !got[additional] || got[unrelated] || len(got) != 2 {
+			t.Errorf("specialists under root = %v, want %d and %d", got, primary, additional)
+		}
+	})
+}
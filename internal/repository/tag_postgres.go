@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type TagRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTagRepository(db *pgxpool.Pool) TagRepository {
+	return &TagRepo{db: db}
+}
+
+// SetSpecialistTags заменяет метки специалиста и обновляет счетчики использования меток
+func (r *TagRepo) SetSpecialistTags(ctx context.Context, specialistID int64, names []string) error {
+	ctx, span := tracer.Start(ctx, "TagRepo.SetSpecialistTags")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка специалиста сериализует параллельные изменения его меток
+	var locked int64
+	if err := tx.QueryRow(ctx, "SELECT id FROM specialists WHERE id = $1 FOR UPDATE", specialistID).Scan(&locked); err != nil {
+		return fmt.Errorf("ошибка блокировки специалиста: %w", err)
+	}
+
+	now := time.Now()
+	if len(names) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO tags (name, created_at)
+			SELECT name, $2 FROM unnest($1::text[]) AS name
+			ON CONFLICT (name) DO NOTHING
+		`, names, now)
+		if err != nil {
+			return fmt.Errorf("ошибка создания меток: %w", err)
+		}
+	}
+
+	removedQuery := `
+		DELETE FROM specialist_tags st
+		USING tags t
+		WHERE st.tag_id = t.id AND st.specialist_id = $1 AND NOT (t.name = ANY($2::text[]))
+		RETURNING st.tag_id
+	`
+	rows, err := tx.Query(ctx, removedQuery, specialistID, names)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления меток специалиста: %w", err)
+	}
+	removed, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("ошибка удаления меток специалиста: %w", err)
+	}
+
+	addedQuery := `
+		INSERT INTO specialist_tags (specialist_id, tag_id, created_at)
+		SELECT $1, id, $3 FROM tags WHERE name = ANY($2::text[])
+		ON CONFLICT (specialist_id, tag_id) DO NOTHING
+		RETURNING tag_id
+	`
+	rows, err = tx.Query(ctx, addedQuery, specialistID, names, now)
+	if err != nil {
+		return fmt.Errorf("ошибка добавления меток специалиста: %w", err)
+	}
+	added, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("ошибка добавления меток специалиста: %w", err)
+	}
+
+	if len(removed) > 0 {
+		if _, err := tx.Exec(ctx,
+			"UPDATE tags SET usage_count = GREATEST(usage_count - 1, 0) WHERE id = ANY($1)", removed,
+		); err != nil {
+			return fmt.Errorf("ошибка обновления счетчиков меток: %w", err)
+		}
+	}
+	if len(added) > 0 {
+		if _, err := tx.Exec(ctx, "UPDATE tags SET usage_count = usage_count + 1 WHERE id = ANY($1)", added); err != nil {
+			return fmt.Errorf("ошибка обновления счетчиков меток: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+// GetSpecialistTags возвращает метки специалиста в алфавитном порядке
+func (r *TagRepo) GetSpecialistTags(ctx context.Context, specialistID int64) ([]domain.Tag, error) {
+	ctx, span := tracer.Start(ctx, "TagRepo.GetSpecialistTags")
+	defer span.End()
+
+	query := `
+		SELECT t.id, t.name, t.usage_count
+		FROM specialist_tags st
+		JOIN tags t ON t.id = st.tag_id
+		WHERE st.specialist_id = $1
+		ORDER BY t.name
+	`
+
+	return r.queryTags(ctx, query, specialistID)
+}
+
+// Search возвращает используемые метки, содержащие query: сначала совпадения по началу, затем по популярности.
+// Пустой query возвращает самые популярные метки
+func (r *TagRepo) Search(ctx context.Context, query string, limit int) ([]domain.Tag, error) {
+	ctx, span := tracer.Start(ctx, "TagRepo.Search")
+	defer span.End()
+
+	pattern := escapeLike(query)
+	sql := `
+		SELECT id, name, usage_count
+		FROM tags
+		WHERE usage_count > 0 AND name LIKE '%' || $1 || '%'
+		ORDER BY (name LIKE $1 || '%') DESC, usage_count DESC, name
+		LIMIT $2
+	`
+
+	return r.queryTags(ctx, sql, pattern, limit)
+}
+
+func (r *TagRepo) queryTags(ctx context.Context, query string, args ...interface{}) ([]domain.Tag, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения меток: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]domain.Tag, 0)
+	for rows.Next() {
+		var tag domain.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.UsageCount); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования метки: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %w", err)
+	}
+
+	return tags, nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"laps/internal/domain"
+)
+
+func TestSpecialistTagsFilterAndUsage(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	tags := NewTagRepository(db)
+	specialists := NewSpecialistRepository(db)
+
+	// Метки глобальные, поэтому у каждого запуска свой набор имен
+	suffix := uniqueSuffix()
+	cbt, anxiety, family := "кпт "+suffix, "тревожность "+suffix, "семья "+suffix
+
+	both := createTestSpecialist(t, db)
+	onlyCBT := createTestSpecialist(t, db)
+	all := createTestSpecialist(t, db)
+	for specialistID, names := range map[int64][]string{
+		both:    {cbt, anxiety},
+		onlyCBT: {cbt},
+		all:     {cbt, anxiety, family},
+	} {
+		if err := tags.SetSpecialistTags(ctx, specialistID, names); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found := func(names ...string) map[int64]bool {
+		t.Helper()
+		list, err := specialists.List(ctx, domain.SpecialistFilter{Tags: names, Limit: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make(map[int64]bool, len(list))
+		for _, specialist := range list {
+			ids[specialist.ID] = true
+		}
+		return ids
+	}
+
+	if got := found(cbt); len(got) != 3 {
+		t.Errorf("one tag matched %v, want all three specialists", got)
+	}
+	if got := found(cbt, anxiety); len(got) != 2 || !got[both] || !got[all] {
+		t.Errorf("two tags matched %v, want %d and %d", got, both, all)
+	}
+	if got := found(cbt, anxiety, family); len(got) != 1 || !got[all] {
+		t.Errorf("three tags matched %v, want only %d", got, all)
+	}
+
+	usage := func() map[string]int {
+		t.Helper()
+		result, err := tags.Search(ctx, suffix, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts := make(map[string]int, len(result))
+		for _, tag := range result {
+			counts[tag.Name] = tag.UsageCount
+		}
+		return counts
+	}
+
+	if got := usage(); got[cbt] != 3 || got[anxiety] != 2 || got[family] != 1 {
+		t.Errorf("usage counts = %v", got)
+	}
+
+	// Замена набора меток уменьшает счетчики снятых и не трогает оставшиеся
+	if err := tags.SetSpecialistTags(ctx, all, []string{cbt}); err != nil {
+		t.Fatal(err)
+	}
+	got := usage()
+	if got[cbt] != 3 || got[anxiety] != 1 {
+		t.Errorf("usage counts after replacing tags = %v", got)
+	}
+	if _, ok := got[family]; ok {
+		t.Errorf("unused tag %q is still suggested", family)
+	}
+}
@@ -0,0 +1,5 @@
+package repository
+
+import "go.opentelemetry.io/otel"
+
+var tracer = otel.Tracer("laps/internal/repository")
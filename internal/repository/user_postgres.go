@@ -52,6 +52,9 @@ func (r *UserRepo) Create(ctx context.Context, dto domain.CreateUserDTO) (int64,
 	).Scan(&id)
 
 	if err != nil {
+		if uniqueErr := userUniqueError(err); uniqueErr != nil {
+			return 0, uniqueErr
+		}
 		return 0, fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
 
@@ -64,7 +67,7 @@ func (r *UserRepo) Create(ctx context.Context, dto domain.CreateUserDTO) (int64,
 
 func (r *UserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	query := `
-		SELECT id, first_name, last_name, middle_name, email, phone, password_hash, role, is_active, created_at, updated_at
+		SELECT id, first_name, last_name, middle_name, email, phone, password_hash, role, is_active, created_at, updated_at, last_seen_at
 		FROM users
 		WHERE id = $1
 	`
@@ -82,6 +85,7 @@ func (r *UserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error)
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.LastSeenAt,
 	)
 
 	if err != nil {
@@ -96,7 +100,7 @@ func (r *UserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error)
 
 func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, first_name, last_name, middle_name, email, phone, password_hash, role, is_active, created_at, updated_at
+		SELECT id, first_name, last_name, middle_name, email, phone, password_hash, role, is_active, created_at, updated_at, last_seen_at
 		FROM users
 		WHERE email = $1
 	`
@@ -114,6 +118,7 @@ func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*domain.User,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.LastSeenAt,
 	)
 
 	if err != nil {
@@ -128,7 +133,7 @@ func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*domain.User,
 
 func (r *UserRepo) GetByPhone(ctx context.Context, phone string) (*domain.User, error) {
 	query := `
-		SELECT id, first_name, last_name, middle_name, email, phone, password_hash, role, is_active, created_at, updated_at
+		SELECT id, first_name, last_name, middle_name, email, phone, password_hash, role, is_active, created_at, updated_at, last_seen_at
 		FROM users
 		WHERE phone = $1
 	`
@@ -146,6 +151,7 @@ func (r *UserRepo) GetByPhone(ctx context.Context, phone string) (*domain.User,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.LastSeenAt,
 	)
 
 	if err != nil {
@@ -216,6 +222,115 @@ func (r *UserRepo) Update(ctx context.Context, id int64, dto domain.UpdateUserDT
 	return nil
 }
 
+// Anonymize заменяет персональные данные пользователя заглушками и деактивирует аккаунт. В той же транзакции
+// удаляется профиль клиента и мягко удаляется профиль специалиста; возвращается ID удаленного профиля специалиста.
+// Строка пользователя сохраняется, чтобы не нарушать внешние ключи записей и отзывов
+func (r *UserRepo) Anonymize(ctx context.Context, id int64) (*int64, error) {
+	ctx, span := tracer.Start(ctx, "UserRepo.Anonymize")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE users
+		SET first_name = $2, last_name = $3, middle_name = '', email = $4, phone = $5,
+		    password_hash = '', is_active = false, updated_at = $6
+		WHERE id = $1
+	`
+
+	tag, err := tx.Exec(ctx, query, id,
+		domain.AnonymizedFirstName, domain.AnonymizedLastName,
+		domain.AnonymizedEmail(id), domain.AnonymizedPhone(id), time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка анонимизации пользователя: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("пользователь с ID %d не найден", id)
+	}
+
+	// Сведения о клиенте (дата рождения, пол, город) — тоже персональные данные
+	if _, err := tx.Exec(ctx, "DELETE FROM client_profiles WHERE user_id = $1", id); err != nil {
+		return nil, fmt.Errorf("ошибка удаления профиля клиента: %w", err)
+	}
+
+	var removedSpecialistID *int64
+	var specialistID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE specialists SET deleted_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1 AND deleted_at IS NULL
+		RETURNING id
+	`, id).Scan(&specialistID)
+	switch {
+	case err == nil:
+		removedSpecialistID = &specialistID
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("ошибка удаления профиля специалиста: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	return removedSpecialistID, nil
+}
+
+// ChangeRole меняет роль пользователя и возвращает прежнюю. Если пользователь перестает быть специалистом,
+// его профиль специалиста мягко удаляется в той же транзакции; второе значение — ID удаленного профиля.
+// Профиль при назначении роли specialist не создается
+func (r *UserRepo) ChangeRole(ctx context.Context, id int64, role domain.UserRole) (domain.UserRole, *int64, error) {
+	ctx, span := tracer.Start(ctx, "UserRepo.ChangeRole")
+	defer span.End()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previous domain.UserRole
+	err = tx.QueryRow(ctx, "SELECT role FROM users WHERE id = $1 FOR UPDATE", id).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, ErrUserNotFound
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка получения роли пользователя: %w", err)
+	}
+	if previous == role {
+		return previous, nil, nil
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE users SET role = $1, updated_at = $2 WHERE id = $3", role, time.Now(), id); err != nil {
+		return "", nil, fmt.Errorf("ошибка изменения роли пользователя: %w", err)
+	}
+
+	var removedSpecialistID *int64
+	if previous == domain.UserRoleSpecialist {
+		var specialistID int64
+		err = tx.QueryRow(ctx, `
+			UPDATE specialists SET deleted_at = NOW(), updated_at = NOW()
+			WHERE user_id = $1 AND deleted_at IS NULL
+			RETURNING id
+		`, id).Scan(&specialistID)
+		switch {
+		case err == nil:
+			removedSpecialistID = &specialistID
+		case !errors.Is(err, pgx.ErrNoRows):
+			return "", nil, fmt.Errorf("ошибка удаления профиля специалиста: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", nil, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	return previous, removedSpecialistID, nil
+}
+
 func (r *UserRepo) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
 	query := `
 		UPDATE users
@@ -246,9 +361,23 @@ func (r *UserRepo) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// UpdateLastSeen сдвигает время последней активности пользователя вперед; более ранняя отметка не перезаписывает позднюю
+func (r *UserRepo) UpdateLastSeen(ctx context.Context, id int64, at time.Time) error {
+	query := `
+		UPDATE users SET last_seen_at = $2
+		WHERE id = $1 AND (last_seen_at IS NULL OR last_seen_at < $2)
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, at); err != nil {
+		return fmt.Errorf("ошибка обновления времени активности пользователя: %w", err)
+	}
+
+	return nil
+}
+
 func (r *UserRepo) List(ctx context.Context, limit, offset int) ([]domain.User, error) {
 	query := `
-		SELECT id, first_name, last_name, middle_name, email, phone, password_hash, role, is_active, created_at, updated_at
+		SELECT id, first_name, last_name, middle_name, email, phone, password_hash, role, is_active, created_at, updated_at, last_seen_at
 		FROM users
 		ORDER BY id
 		LIMIT $1 OFFSET $2
@@ -275,6 +404,7 @@ func (r *UserRepo) List(ctx context.Context, limit, offset int) ([]domain.User,
 			&user.IsActive,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.LastSeenAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка чтения данных пользователя: %w", err)
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"laps/internal/domain"
+)
+
+type WebhookRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) WebhookRepository {
+	return &WebhookRepo{db: db}
+}
+
+func webhookEventTypesToStrings(eventTypes []domain.WebhookEventType) []string {
+	result := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		result = append(result, string(eventType))
+	}
+	return result
+}
+
+func webhookEventTypesFromStrings(values []string) []domain.WebhookEventType {
+	result := make([]domain.WebhookEventType, 0, len(values))
+	for _, value := range values {
+		result = append(result, domain.WebhookEventType(value))
+	}
+	return result
+}
+
+// CreateEndpoint сохраняет адрес и заполняет его ID и время создания
+func (r *WebhookRepo) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.CreateEndpoint")
+	defer span.End()
+
+	query := `
+		INSERT INTO webhook_endpoints (url, secret, event_types, is_active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		endpoint.URL, endpoint.Secret, webhookEventTypesToStrings(endpoint.EventTypes), endpoint.IsActive,
+	).Scan(&endpoint.ID, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения адреса webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) GetEndpoint(ctx context.Context, id int64) (*domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.GetEndpoint")
+	defer span.End()
+
+	query := `
+		SELECT id, url, secret, event_types, is_active, created_at, updated_at
+		FROM webhook_endpoints
+		WHERE id = $1
+	`
+
+	var endpoint domain.WebhookEndpoint
+	var eventTypes []string
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&endpoint.ID, &endpoint.URL, &endpoint.Secret, &eventTypes, &endpoint.IsActive,
+		&endpoint.CreatedAt, &endpoint.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookEndpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения адреса webhook: %w", err)
+	}
+	endpoint.EventTypes = webhookEventTypesFromStrings(eventTypes)
+
+	return &endpoint, nil
+}
+
+func (r *WebhookRepo) ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.ListEndpoints")
+	defer span.End()
+
+	query := `
+		SELECT id, url, secret, event_types, is_active, created_at, updated_at
+		FROM webhook_endpoints
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения адресов webhook: %w", err)
+	}
+	defer rows.Close()
+
+	endpoints := make([]domain.WebhookEndpoint, 0)
+	for rows.Next() {
+		var endpoint domain.WebhookEndpoint
+		var eventTypes []string
+		if err := rows.Scan(
+			&endpoint.ID, &endpoint.URL, &endpoint.Secret, &eventTypes, &endpoint.IsActive,
+			&endpoint.CreatedAt, &endpoint.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования адреса webhook: %w", err)
+		}
+		endpoint.EventTypes = webhookEventTypesFromStrings(eventTypes)
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+// DeleteEndpoint удаляет адрес вместе с историей его доставок
+func (r *WebhookRepo) DeleteEndpoint(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.DeleteEndpoint")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM webhook_endpoints WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления адреса webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookEndpointNotFound
+	}
+
+	return nil
+}
+
+// Enqueue ставит событие в очередь доставки на все активные адреса, подписанные на eventType,
+// и возвращает число созданных доставок. Если событие с eventID уже в очереди адреса, оно пропускается
+func (r *WebhookRepo) Enqueue(ctx context.Context, eventID string, eventType domain.WebhookEventType, payload []byte) (int64, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.Enqueue")
+	defer span.End()
+
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, next_attempt_at, created_at)
+		SELECT id, $1, $2, $3, $4, $4
+		FROM webhook_endpoints
+		WHERE is_active AND $2 = ANY(event_types)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING
+	`
+
+	tag, err := r.db.Exec(ctx, query, eventID, string(eventType), payload, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("ошибка постановки события webhook в очередь: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDue выбирает до limit доставок, готовых к отправке, и откладывает их следующую попытку на lease,
+// чтобы несколько экземпляров сервиса не отправляли одну доставку одновременно
+func (r *WebhookRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDeliveryJob, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.ClaimDue")
+	defer span.End()
+
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhook_endpoints e
+		WHERE e.id = d.endpoint_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.event_id::text, d.event_type, d.payload, d.attempts, e.url, e.secret
+	`
+
+	rows, err := r.db.Query(ctx, query, limit, time.Now().Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выборки доставок webhook: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []domain.WebhookDeliveryJob
+	for rows.Next() {
+		var job domain.WebhookDeliveryJob
+		if err := rows.Scan(
+			&job.ID, &job.EventID, &job.EventType, &job.Payload, &job.Attempts, &job.URL, &job.Secret,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования доставки webhook: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *WebhookRepo) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.MarkDelivered")
+	defer span.End()
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, last_status_code = $2, last_error = NULL, delivered_at = $3
+		WHERE id = $1
+	`, id, statusCode, time.Now())
+	if err != nil {
+		return fmt.Errorf("ошибка отметки доставки webhook: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed сохраняет ошибку отправки и код ответа, если он получен. nextAttemptAt == nil означает,
+// что попытки исчерпаны
+func (r *WebhookRepo) MarkFailed(ctx context.Context, id int64, statusCode *int, message string, nextAttemptAt *time.Time) error {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.MarkFailed")
+	defer span.End()
+
+	var err error
+	if nextAttemptAt != nil {
+		_, err = r.db.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1, last_status_code = $2, last_error = $3, next_attempt_at = $4
+			WHERE id = $1
+		`, id, statusCode, message, *nextAttemptAt)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1, last_status_code = $2, last_error = $3, failed_at = $4
+			WHERE id = $1
+		`, id, statusCode, message, time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения ошибки доставки webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries возвращает доставки на адрес от новых к старым и их общее число
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, endpointID int64, limit, offset int) ([]domain.WebhookDelivery, int, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.ListDeliveries")
+	defer span.End()
+
+	var total int
+	if err := r.db.QueryRow(ctx,
+		"SELECT COUNT(*) FROM webhook_deliveries WHERE endpoint_id = $1", endpointID,
+	).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("ошибка подсчета доставок webhook: %w", err)
+	}
+
+	query := `
+		SELECT id, endpoint_id, event_id::text, event_type, payload, attempts, next_attempt_at,
+			last_status_code, last_error, delivered_at, failed_at, created_at
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(ctx, query, endpointID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения доставок webhook: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		var nextAttemptAt time.Time
+		if err := rows.Scan(
+			&delivery.ID, &delivery.EndpointID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+			&delivery.Attempts, &nextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+			&delivery.DeliveredAt, &delivery.FailedAt, &delivery.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("ошибка сканирования доставки webhook: %w", err)
+		}
+
+		switch {
+		case delivery.DeliveredAt != nil:
+			delivery.Status = domain.WebhookDeliveryDelivered
+		case delivery.FailedAt != nil:
+			delivery.Status = domain.WebhookDeliveryFailed
+		default:
+			delivery.Status = domain.WebhookDeliveryPending
+			delivery.NextAttemptAt = &nextAttemptAt
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка при обработке результатов: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+// PurgeDelivered удаляет доставленные до before доставки и возвращает их количество
+func (r *WebhookRepo) PurgeDelivered(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "WebhookRepo.PurgeDelivered")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM webhook_deliveries WHERE delivered_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления доставленных webhook: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+)
+
+func TestActivityStatsHideSmallSamples(t *testing.T) {
+	median, confirmation, cancellation := 180.0, 0.8, 0.1
+
+	tests := []struct {
+		name       string
+		sampleSize int
+		wantShown  bool
+	}{
+		{"four cases are hidden", 4, false},
+		{"five cases are shown", 5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSpecialistRepo{activityStats: domain.SpecialistActivityStats{
+				MedianFirstResponseSeconds: &median,
+				ResponseSampleSize:         tt.sampleSize,
+				ConfirmationRate:           &confirmation,
+				ConfirmationSampleSize:     tt.sampleSize,
+				SpecialistCancellationRate: &cancellation,
+				CancellationSampleSize:     tt.sampleSize,
+			}}
+			specialists := NewSpecialistService(repo, &fakeUserRepo{}, nil, nil, nil, nil, nil, cache.NewMemoryCache(10), time.Minute, zap.NewNop())
+
+			stats, err := specialists.GetActivityStats(context.Background(), 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range map[string]*float64{
+				"median_first_response_seconds": stats.MedianFirstResponseSeconds,
+				"confirmation_rate":             stats.ConfirmationRate,
+				"specialist_cancellation_rate":  stats.SpecialistCancellationRate,
+			} {
+				if (value != nil) != tt.wantShown {
+					t.Errorf("%s = %v with sample size %d", name, value, tt.sampleSize)
+				}
+			}
+			if stats.ResponseSampleSize != tt.sampleSize {
+				t.Errorf("sample size = %d, want it reported even when hidden", stats.ResponseSampleSize)
+			}
+		})
+	}
+}
@@ -1,79 +1,113 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"laps/config"
 	"laps/internal/domain"
 	"laps/internal/repository"
+	"laps/pkg/ical"
 )
 
+// Максимальный горизонт записи на консультацию
+const maxBookingAdvance = 90 * 24 * time.Hour
+
 type AppointmentServiceImpl struct {
 	repo           repository.AppointmentRepository
+	scheduleRepo   repository.ScheduleRepository
 	specialistRepo repository.SpecialistRepository
 	userRepo       repository.UserRepository
+	calendarRepo   repository.ExternalCalendarRepository
+	blockListRepo  repository.BlockListRepository
+	auditRepo      repository.AuditRepository
+	reviewReqRepo  repository.ReviewRequestRepository
 	chatService    ChatService
+	notifier       Notifier
+	realtime       RealtimePublisher
+	cfg            config.AppointmentConfig
 	logger         *zap.Logger
 }
 
 func NewAppointmentService(
 	repo repository.AppointmentRepository,
+	scheduleRepo repository.ScheduleRepository,
 	specialistRepo repository.SpecialistRepository,
 	userRepo repository.UserRepository,
+	calendarRepo repository.ExternalCalendarRepository,
+	blockListRepo repository.BlockListRepository,
+	auditRepo repository.AuditRepository,
+	reviewReqRepo repository.ReviewRequestRepository,
 	chatService ChatService,
+	notifier Notifier,
+	realtime RealtimePublisher,
+	cfg config.AppointmentConfig,
 	logger *zap.Logger,
 ) *AppointmentServiceImpl {
 	return &AppointmentServiceImpl{
 		repo:           repo,
+		scheduleRepo:   scheduleRepo,
 		specialistRepo: specialistRepo,
 		userRepo:       userRepo,
+		calendarRepo:   calendarRepo,
+		blockListRepo:  blockListRepo,
+		auditRepo:      auditRepo,
+		reviewReqRepo:  reviewReqRepo,
 		chatService:    chatService,
+		notifier:       notifier,
+		realtime:       realtime,
+		cfg:            cfg,
 		logger:         logger,
 	}
 }
 
-func (s *AppointmentServiceImpl) Create(ctx context.Context, clientID int64, dto domain.CreateAppointmentDTO) (int64, error) {
-	_, err := s.userRepo.GetByID(ctx, clientID)
-	if err != nil {
-		s.logger.Error("клиент не найден при создании записи", zap.Int64("clientID", clientID), zap.Error(err))
-		return 0, errors.New("клиент не найден")
+// Create создает запись клиента к специалисту. Тип консультации определяется по завершенным записям
+// (см. CheckConsultationType) и заменяет переданный клиентом; примененный тип возвращается вместе с ID
+func (s *AppointmentServiceImpl) Create(ctx context.Context, clientID int64, dto domain.CreateAppointmentDTO) (int64, domain.ConsultationType, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.Create")
+	defer span.End()
+
+	// Слот под удержанием уже проверен при его создании и показывается занятым,
+	// поэтому при оформлении удержания проверяется только само удержание
+	checkSlot := dto.HoldID == nil && dto.ReservationToken == nil
+	if err := s.checkBookable(ctx, clientID, dto.SpecialistID, dto.AppointmentDate, checkSlot); err != nil {
+		return 0, "", err
 	}
 
-	_, err = s.specialistRepo.GetByID(ctx, dto.SpecialistID)
-	if err != nil {
-		s.logger.Error("специалист не найден при создании записи", zap.Int64("specialistID", dto.SpecialistID), zap.Error(err))
-		return 0, errors.New("специалист не найден")
+	if err := s.checkActiveLimit(ctx, clientID); err != nil {
+		return 0, "", err
 	}
 
-	dateStr := dto.AppointmentDate.Format("2006-01-02")
-	timeStr := dto.AppointmentDate.Format("15:04")
-
-	freeSlots, err := s.repo.GetFreeSlots(ctx, dto.SpecialistID, dateStr)
+	check, err := s.CheckConsultationType(ctx, clientID, dto.SpecialistID, dto.SpecializationID, dto.AppointmentDate)
 	if err != nil {
-		s.logger.Error("ошибка получения свободных слотов", zap.Error(err))
-		return 0, errors.New("ошибка при проверке доступности времени")
+		return 0, "", errors.New("ошибка при создании записи")
 	}
-
-	timeIsAvailable := false
-	for _, slot := range freeSlots {
-		if slot == timeStr {
-			timeIsAvailable = true
-			break
-		}
-	}
-
-	if !timeIsAvailable {
-		s.logger.Error("выбранное время недоступно", zap.String("time", timeStr))
-		return 0, errors.New("выбранное время недоступно")
+	consultationType := check.Type
+	if dto.ConsultationType != "" && dto.ConsultationType != consultationType {
+		s.logger.Info("тип консультации заменен по истории записей",
+			zap.Int64("clientID", clientID),
+			zap.Int64("specialistID", dto.SpecialistID),
+			zap.String("requested", string(dto.ConsultationType)),
+			zap.String("applied", string(consultationType)))
 	}
+	dto.ConsultationType = consultationType
 
 	id, err := s.repo.Create(ctx, clientID, dto)
+	if errors.Is(err, repository.ErrSlotTaken) {
+		return 0, "", fmt.Errorf("%w: выбранное время уже занято", ErrConflict)
+	}
+	if errors.Is(err, repository.ErrHoldNotFound) {
+		return 0, "", fmt.Errorf("%w: удержание слота не найдено или истекло", ErrInvalid)
+	}
 	if err != nil {
 		s.logger.Error("ошибка создания записи", zap.Error(err))
-		return 0, errors.New("ошибка при создании записи")
+		return 0, "", errors.New("ошибка при создании записи")
 	}
 
 	// Create chat session automatically for this appointment
@@ -94,10 +128,187 @@ func (s *AppointmentServiceImpl) Create(ctx context.Context, clientID int64, dto
 		// Just log the error and continue
 	}
 
-	return id, nil
+	s.publishNewAppointment(ctx, id)
+
+	return id, dto.ConsultationType, nil
+}
+
+// publishNewAppointment сообщает специалисту по WebSocket о новой записи к нему, если он в сети.
+// Запись уже создана, поэтому ошибки только логируются
+func (s *AppointmentServiceImpl) publishNewAppointment(ctx context.Context, id int64) {
+	appointment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("запись не найдена при уведомлении специалиста", zap.Int64("appointmentID", id), zap.Error(err))
+		return
+	}
+
+	specialist, err := s.specialistRepo.GetByID(ctx, appointment.SpecialistID)
+	if err != nil {
+		s.logger.Error("специалист не найден при уведомлении о новой записи",
+			zap.Int64("specialistID", appointment.SpecialistID), zap.Error(err))
+		return
+	}
+
+	s.realtime.Publish(specialist.UserID, RealtimeEventNewAppointment, map[string]interface{}{
+		"appointment_id":    appointment.ID,
+		"appointment_date":  appointment.AppointmentDate,
+		"client_name":       appointment.ClientName,
+		"consultation_type": appointment.ConsultationType,
+	})
+}
+
+// checkActiveLimit не дает клиенту держать больше MaxActivePerClient ожидающих, оплаченных и начатых записей
+func (s *AppointmentServiceImpl) checkActiveLimit(ctx context.Context, clientID int64) error {
+	if s.cfg.MaxActivePerClient <= 0 {
+		return nil
+	}
+
+	active, err := s.repo.CountByFilter(ctx, domain.AppointmentFilter{
+		ClientID: &clientID,
+		Statuses: []domain.AppointmentStatus{domain.AppointmentStatusPending, domain.AppointmentStatusPaid, domain.AppointmentStatusInProgress},
+	})
+	if err != nil {
+		s.logger.Error("ошибка подсчета активных записей клиента", zap.Int64("clientID", clientID), zap.Error(err))
+		return errors.New("ошибка при создании записи")
+	}
+
+	if active >= s.cfg.MaxActivePerClient {
+		return fmt.Errorf("%w: нельзя иметь больше %d активных записей одновременно", ErrLimitExceeded, s.cfg.MaxActivePerClient)
+	}
+
+	return nil
+}
+
+// checkReturningClient пропускает к специалисту, закрывшему запись для новых клиентов, только клиентов,
+// у которых уже была неотмененная запись к нему
+func (s *AppointmentServiceImpl) checkReturningClient(ctx context.Context, clientID, specialistID int64) error {
+	previous, err := s.repo.CountByFilter(ctx, domain.AppointmentFilter{
+		ClientID:     &clientID,
+		SpecialistID: &specialistID,
+		Statuses: []domain.AppointmentStatus{
+			domain.AppointmentStatusPending,
+			domain.AppointmentStatusPaid,
+			domain.AppointmentStatusInProgress,
+			domain.AppointmentStatusCompleted,
+		},
+	})
+	if err != nil {
+		s.logger.Error("ошибка при проверке истории записей", zap.Int64("clientID", clientID), zap.Error(err))
+		return errors.New("ошибка при проверке доступности записи")
+	}
+
+	if previous == 0 {
+		return ErrNotAcceptingClients
+	}
+
+	return nil
+}
+
+// checkBookable проверяет, что клиент может записаться к специалисту на указанное время.
+// checkSlot дополнительно требует, чтобы слот был среди свободных
+func (s *AppointmentServiceImpl) checkBookable(ctx context.Context, clientID, specialistID int64, date time.Time, checkSlot bool) error {
+	now := time.Now()
+	if date.Before(now) {
+		return fmt.Errorf("%w: appointment date must be in the future", ErrInvalid)
+	}
+	if date.After(now.Add(maxBookingAdvance)) {
+		return fmt.Errorf("%w: appointment date must be within 90 days", ErrInvalid)
+	}
+
+	_, err := s.userRepo.GetByID(ctx, clientID)
+	if err != nil {
+		s.logger.Error("клиент не найден при создании записи", zap.Int64("clientID", clientID), zap.Error(err))
+		return errors.New("клиент не найден")
+	}
+
+	specialist, err := s.specialistRepo.GetByIDWithDeleted(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("специалист не найден при создании записи", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return errors.New("специалист не найден")
+	}
+	if specialist.DeletedAt != nil || !specialist.User.IsActive {
+		s.logger.Info("попытка записи к удаленному специалисту",
+			zap.Int64("clientID", clientID),
+			zap.Int64("specialistID", specialistID))
+		return ErrSpecialistUnavailable
+	}
+
+	if !specialist.AcceptingClients {
+		if err := s.checkReturningClient(ctx, clientID, specialistID); err != nil {
+			return err
+		}
+	}
+
+	blocked, err := s.blockListRepo.IsBlocked(ctx, specialistID, clientID)
+	if err != nil {
+		s.logger.Error("ошибка проверки блокировки клиента", zap.Int64("clientID", clientID), zap.Error(err))
+		return errors.New("ошибка при проверке доступности записи")
+	}
+	if blocked {
+		s.logger.Info("попытка записи заблокированного клиента",
+			zap.Int64("clientID", clientID),
+			zap.Int64("specialistID", specialistID))
+		return ErrClientBlocked
+	}
+
+	// Время проверяется по расписанию и при оформлении удержания: удержание могло пережить изменение расписания
+	if err := s.checkScheduledSlot(ctx, specialistID, date); err != nil {
+		return err
+	}
+
+	if !checkSlot {
+		return nil
+	}
+
+	dateStr := date.Format("2006-01-02")
+	timeStr := date.Format("15:04")
+
+	freeSlots, err := s.freeSlots(ctx, specialistID, dateStr, date.Location())
+	if err != nil {
+		s.logger.Error("ошибка получения свободных слотов", zap.Error(err))
+		return errors.New("ошибка при проверке доступности времени")
+	}
+
+	for _, slot := range freeSlots {
+		if slot == timeStr {
+			return nil
+		}
+	}
+
+	s.logger.Error("выбранное время недоступно", zap.String("time", timeStr))
+	return errors.New("выбранное время недоступно")
+}
+
+// checkScheduledSlot проверяет, что время записи совпадает с началом слота из рабочего времени специалиста
+// на эту дату с учетом исключений расписания и перерывов. Время с секундами или между слотами отклоняется
+func (s *AppointmentServiceImpl) checkScheduledSlot(ctx context.Context, specialistID int64, date time.Time) error {
+	if date.Second() != 0 || date.Nanosecond() != 0 {
+		return fmt.Errorf("%w: время записи должно совпадать с началом слота расписания", ErrInvalid)
+	}
+
+	slots, _, err := scheduledSlotsForDate(ctx, s.scheduleRepo, specialistID, date.Format("2006-01-02"))
+	if err != nil {
+		s.logger.Error("ошибка получения слотов расписания", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return errors.New("ошибка при проверке доступности времени")
+	}
+
+	timeStr := date.Format("15:04")
+	for _, slot := range slots {
+		if slot == timeStr {
+			return nil
+		}
+	}
+
+	s.logger.Info("попытка записи вне расписания специалиста",
+		zap.Int64("specialistID", specialistID),
+		zap.Time("date", date))
+	return fmt.Errorf("%w: специалист не работает в выбранное время", ErrInvalid)
 }
 
 func (s *AppointmentServiceImpl) GetByID(ctx context.Context, id int64) (*domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.GetByID")
+	defer span.End()
+
 	appointment, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("ошибка получения записи", zap.Int64("id", id), zap.Error(err))
@@ -106,47 +317,216 @@ func (s *AppointmentServiceImpl) GetByID(ctx context.Context, id int64) (*domain
 	return appointment, nil
 }
 
-func (s *AppointmentServiceImpl) Update(ctx context.Context, id int64, dto domain.UpdateAppointmentDTO) error {
+// GetNextUpcoming возвращает ближайшую предстоящую запись клиента в статусе pending или paid либо nil
+func (s *AppointmentServiceImpl) GetNextUpcoming(ctx context.Context, clientID int64) (*domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.GetNextUpcoming")
+	defer span.End()
+
+	appointment, err := s.repo.GetNextUpcoming(ctx, clientID)
+	if err != nil {
+		s.logger.Error("ошибка получения ближайшей записи клиента", zap.Int64("clientID", clientID), zap.Error(err))
+		return nil, errors.New("ошибка при получении ближайшей записи")
+	}
+	return appointment, nil
+}
+
+// Update изменяет запись. При подтверждении (переводе в статус paid) у записи гарантированно появляется чат,
+// его ID возвращается в AppointmentConfirmation; повторное подтверждение возвращает тот же чат.
+// Для остальных изменений, а также если чат создать не удалось, AppointmentConfirmation равен nil
+func (s *AppointmentServiceImpl) Update(ctx context.Context, id int64, dto domain.UpdateAppointmentDTO) (*domain.AppointmentConfirmation, error) {
 	appointment, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("запись для обновления не найдена", zap.Int64("id", id), zap.Error(err))
-		return errors.New("запись не найдена")
+		return nil, errors.New("запись не найдена")
 	}
 
-	if dto.AppointmentDate != nil {
-		dateStr := dto.AppointmentDate.Format("2006-01-02")
-		timeStr := dto.AppointmentDate.Format("15:04")
-
-		freeSlots, err := s.repo.GetFreeSlots(ctx, appointment.SpecialistID, dateStr)
-		if err != nil {
-			s.logger.Error("ошибка получения свободных слотов", zap.Error(err))
-			return errors.New("ошибка при проверке доступности времени")
+	// Начатую консультацию можно только завершить или отменить
+	if appointment.Status == domain.AppointmentStatusInProgress {
+		if dto.AppointmentDate != nil {
+			return nil, fmt.Errorf("%w: нельзя перенести начатую консультацию", ErrConflict)
 		}
-
-		timeIsAvailable := false
-		for _, slot := range freeSlots {
-			if slot == timeStr {
-				timeIsAvailable = true
-				break
-			}
+		if dto.Status != nil && *dto.Status != domain.AppointmentStatusCompleted && *dto.Status != domain.AppointmentStatusCancelled {
+			return nil, fmt.Errorf("%w: начатую консультацию можно только завершить или отменить", ErrConflict)
 		}
+	}
 
-		if !timeIsAvailable {
-			s.logger.Error("выбранное время недоступно", zap.String("time", timeStr))
-			return errors.New("выбранное время недоступно")
+	// Перенос проверяется теми же правилами, что и новая запись: окно записи, блокировка клиента,
+	// расписание специалиста и свободный слот
+	if dto.AppointmentDate != nil {
+		if err := s.checkBookable(ctx, appointment.ClientID, appointment.SpecialistID, *dto.AppointmentDate, true); err != nil {
+			return nil, err
 		}
 	}
 
 	err = s.repo.Update(ctx, id, dto)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, fmt.Errorf("%w: запись была изменена, обновите данные и повторите", ErrVersionConflict)
+	}
 	if err != nil {
 		s.logger.Error("ошибка обновления записи", zap.Int64("id", id), zap.Error(err))
-		return errors.New("ошибка при обновлении записи")
+		return nil, errors.New("ошибка при обновлении записи")
+	}
+
+	// Оплаченная запись считается подтвержденной
+	if dto.Status == nil || *dto.Status != domain.AppointmentStatusPaid {
+		return nil, nil
+	}
+
+	if appointment.Status != domain.AppointmentStatusPaid {
+		if dto.AppointmentDate != nil {
+			appointment.AppointmentDate = *dto.AppointmentDate
+		}
+		s.sendConfirmation(ctx, appointment)
+	}
+
+	return s.ensureChatSession(ctx, appointment), nil
+}
+
+// ensureChatSession создает чат подтвержденной записи, если его еще нет, и возвращает его ID.
+// Запись уже подтверждена, поэтому ошибка только логируется и возвращается nil
+func (s *AppointmentServiceImpl) ensureChatSession(ctx context.Context, appointment *domain.Appointment) *domain.AppointmentConfirmation {
+	session, err := s.chatService.CreateChatSession(ctx, domain.CreateChatSessionDTO{
+		AppointmentID: appointment.ID,
+		ClientID:      appointment.ClientID,
+		SpecialistID:  appointment.SpecialistID,
+		Status:        domain.ChatSessionStatusPending,
+	})
+	if err != nil {
+		s.logger.Error("ошибка создания чат-сессии при подтверждении записи",
+			zap.Int64("appointmentID", appointment.ID),
+			zap.Error(err))
+		return nil
+	}
+
+	return &domain.AppointmentConfirmation{ChatSessionID: session.ID}
+}
+
+// sendConfirmation уведомляет клиента о подтверждении записи. Отметка об отправке ставится до уведомления,
+// поэтому повторное подтверждение той же записи не приводит к повторной рассылке. Ошибки только логируются
+func (s *AppointmentServiceImpl) sendConfirmation(ctx context.Context, appointment *domain.Appointment) {
+	marked, err := s.repo.MarkConfirmationSent(ctx, appointment.ID)
+	if err != nil {
+		s.logger.Error("ошибка отметки об отправке подтверждения записи", zap.Int64("appointmentID", appointment.ID), zap.Error(err))
+		return
+	}
+	if !marked {
+		return
+	}
+
+	specialistName := ""
+	specialist, err := s.specialistRepo.GetByIDWithDeleted(ctx, appointment.SpecialistID)
+	if err != nil {
+		s.logger.Warn("специалист не найден при отправке подтверждения записи",
+			zap.Int64("specialistID", appointment.SpecialistID), zap.Error(err))
+	} else {
+		specialistName = strings.TrimSpace(specialist.User.FirstName + " " + specialist.User.LastName)
+	}
+
+	body := fmt.Sprintf("Запись на %s подтверждена", appointment.AppointmentDate.Format("02.01.2006 15:04"))
+	if specialistName != "" {
+		body += ", специалист: " + specialistName
+	}
+
+	calendarURL := fmt.Sprintf("%s/api/v1/appointments/%d/calendar.ics", strings.TrimRight(s.cfg.PublicURL, "/"), appointment.ID)
+
+	err = s.notifier.Notify(ctx, domain.Notification{
+		UserID: appointment.ClientID,
+		Type:   domain.NotificationTypeAppointmentConfirmed,
+		Title:  "Запись подтверждена",
+		Body:   body,
+		Data: map[string]interface{}{
+			"appointment_id":   appointment.ID,
+			"specialist_id":    appointment.SpecialistID,
+			"specialist_name":  specialistName,
+			"appointment_date": appointment.AppointmentDate,
+			"calendar_url":     calendarURL,
+		},
+	})
+	if err != nil {
+		s.logger.Error("ошибка уведомления клиента о подтверждении записи",
+			zap.Int64("appointmentID", appointment.ID),
+			zap.Error(err))
+	}
+}
+
+// CheckCallAllowed проверяет, можно ли сейчас начать звонок по записи между callerID и calleeID:
+// участники должны быть клиентом и специалистом записи, также проверяются способ связи, статус и время.
+// Если нельзя, возвращает *CallNotAllowedError с причиной
+func (s *AppointmentServiceImpl) CheckCallAllowed(ctx context.Context, appointmentID, callerID, calleeID int64) error {
+	ctx, span := tracer.Start(ctx, "AppointmentService.CheckCallAllowed")
+	defer span.End()
+
+	appointment, err := s.repo.GetByID(ctx, appointmentID)
+	if err != nil {
+		s.logger.Error("запись для звонка не найдена", zap.Int64("appointmentID", appointmentID), zap.Error(err))
+		return fmt.Errorf("%w: запись не найдена", ErrNotFound)
+	}
+
+	specialist, err := s.specialistRepo.GetByIDWithDeleted(ctx, appointment.SpecialistID)
+	if err != nil {
+		s.logger.Error("специалист записи для звонка не найден",
+			zap.Int64("appointmentID", appointmentID),
+			zap.Int64("specialistID", appointment.SpecialistID),
+			zap.Error(err))
+		return errors.New("ошибка при проверке звонка")
+	}
+
+	isClientToSpecialist := callerID == appointment.ClientID && calleeID == specialist.UserID
+	isSpecialistToClient := callerID == specialist.UserID && calleeID == appointment.ClientID
+	if !isClientToSpecialist && !isSpecialistToClient {
+		return &CallNotAllowedError{Reason: domain.CallNotAllowedParticipants}
+	}
+
+	if specialist.DeletedAt != nil {
+		return &CallNotAllowedError{Reason: domain.CallNotAllowedSpecialistUnavailable}
+	}
+
+	if reason := appointment.CallNotAllowedReason(time.Now()); reason != "" {
+		return &CallNotAllowedError{Reason: reason}
 	}
 
 	return nil
 }
 
-func (s *AppointmentServiceImpl) Cancel(ctx context.Context, id int64) error {
+// CalendarFile возвращает запись в формате iCalendar для добавления в календарь клиента.
+// Длительность события — длительность слота в расписании специалиста на день записи с учетом исключений
+func (s *AppointmentServiceImpl) CalendarFile(ctx context.Context, appointment *domain.Appointment) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.CalendarFile")
+	defer span.End()
+
+	duration := defaultSlotDuration
+	day, _ := time.Parse("2006-01-02", appointment.AppointmentDate.Format("2006-01-02"))
+	if override, schedule, err := scheduleForDate(ctx, s.scheduleRepo, appointment.SpecialistID, day); err == nil {
+		duration = slotDurationForDay(override, schedule)
+	}
+
+	summary := "Консультация"
+	specialist, err := s.specialistRepo.GetByIDWithDeleted(ctx, appointment.SpecialistID)
+	if err == nil {
+		if name := strings.TrimSpace(specialist.User.FirstName + " " + specialist.User.LastName); name != "" {
+			summary += ": " + name
+		}
+	}
+
+	var buf bytes.Buffer
+	err = ical.Write(&buf, ical.Event{
+		UID:     fmt.Sprintf("appointment-%d@laps", appointment.ID),
+		Summary: summary,
+		Start:   appointment.AppointmentDate,
+		End:     appointment.AppointmentDate.Add(duration),
+	})
+	if err != nil {
+		s.logger.Error("ошибка формирования календаря записи", zap.Int64("appointmentID", appointment.ID), zap.Error(err))
+		return nil, errors.New("ошибка при формировании календаря")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *AppointmentServiceImpl) Cancel(ctx context.Context, id int64, cancelledBy domain.UserRole) error {
+	ctx, span := tracer.Start(ctx, "AppointmentService.Cancel")
+	defer span.End()
+
 	_, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("запись для отмены не найдена", zap.Int64("id", id), zap.Error(err))
@@ -154,7 +534,8 @@ func (s *AppointmentServiceImpl) Cancel(ctx context.Context, id int64) error {
 	}
 
 	dto := domain.UpdateAppointmentDTO{
-		Status: PointerTo(domain.AppointmentStatusCancelled),
+		Status:      PointerTo(domain.AppointmentStatusCancelled),
+		CancelledBy: &cancelledBy,
 	}
 
 	err = s.repo.Update(ctx, id, dto)
@@ -176,6 +557,88 @@ func (s *AppointmentServiceImpl) Cancel(ctx context.Context, id int64) error {
 	return nil
 }
 
+// CancelRange отменяет предстоящие ожидающие и оплаченные записи специалиста в диапазоне дат и уведомляет клиентов.
+// Отмененные записи перестают занимать слоты, поэтому время снова доступно для записи
+func (s *AppointmentServiceImpl) CancelRange(ctx context.Context, specialistID int64, dto domain.CancelAppointmentRangeDTO) ([]int64, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.CancelRange")
+	defer span.End()
+
+	from, err := time.ParseInLocation("2006-01-02", dto.From, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: неверный формат даты from, ожидается YYYY-MM-DD", ErrInvalid)
+	}
+	to, err := time.ParseInLocation("2006-01-02", dto.To, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: неверный формат даты to, ожидается YYYY-MM-DD", ErrInvalid)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: дата to не может быть раньше from", ErrInvalid)
+	}
+	now := time.Now()
+	if from.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)) {
+		return nil, fmt.Errorf("%w: дата from не может быть в прошлом", ErrInvalid)
+	}
+
+	cancelled, err := s.repo.CancelRange(ctx, specialistID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		s.logger.Error("ошибка массовой отмены записей", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при отмене записей")
+	}
+
+	ids := make([]int64, 0, len(cancelled))
+	for _, appointment := range cancelled {
+		ids = append(ids, appointment.ID)
+
+		if err := s.chatService.ArchiveChatSession(ctx, appointment.ID); err != nil {
+			s.logger.Error("ошибка архивации чат-сессии при отмене записи",
+				zap.Int64("appointmentID", appointment.ID),
+				zap.Error(err))
+		}
+
+		body := fmt.Sprintf("Запись на %s отменена специалистом", appointment.AppointmentDate.Format("02.01.2006 15:04"))
+		if dto.Reason != "" {
+			body += ": " + dto.Reason
+		}
+
+		err := s.notifier.Notify(ctx, domain.Notification{
+			UserID: appointment.ClientID,
+			Type:   domain.NotificationTypeAppointmentCancelled,
+			Title:  "Запись отменена",
+			Body:   body,
+			Data: map[string]interface{}{
+				"appointment_id":   appointment.ID,
+				"specialist_id":    appointment.SpecialistID,
+				"appointment_date": appointment.AppointmentDate,
+			},
+		})
+		if err != nil {
+			s.logger.Error("ошибка уведомления клиента об отмене записи",
+				zap.Int64("appointmentID", appointment.ID),
+				zap.Error(err))
+		}
+	}
+
+	s.logger.Info("записи специалиста отменены",
+		zap.Int64("specialistID", specialistID),
+		zap.Int("count", len(ids)))
+
+	return ids, nil
+}
+
+// ListUpcoming возвращает неотмененные записи из filter, время которых еще не наступило, от ближайшей к поздней
+func (s *AppointmentServiceImpl) ListUpcoming(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, int, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.ListUpcoming")
+	defer span.End()
+
+	now := time.Now()
+	cancelled := domain.AppointmentStatusCancelled
+	filter.StartDate = &now
+	filter.ExcludeStatus = &cancelled
+	filter.SortAscending = true
+
+	return s.List(ctx, filter)
+}
+
 func (s *AppointmentServiceImpl) List(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, int, error) {
 	appointments, err := s.repo.List(ctx, filter)
 	if err != nil {
@@ -205,7 +668,7 @@ func (s *AppointmentServiceImpl) List(ctx context.Context, filter domain.Appoint
 		}
 		appt.ClientPhone = user.Phone
 
-		specialist, err := s.specialistRepo.GetByID(ctx, appointment.SpecialistID)
+		specialist, err := s.specialistRepo.GetByIDWithDeleted(ctx, appointment.SpecialistID)
 		if err != nil {
 			s.logger.Warn("не удалось получить данные специалиста",
 				zap.Int64("specialistID", appointment.SpecialistID),
@@ -232,7 +695,10 @@ func (s *AppointmentServiceImpl) List(ctx context.Context, filter domain.Appoint
 }
 
 func (s *AppointmentServiceImpl) GetFreeSlots(ctx context.Context, specialistID int64, date string) ([]string, error) {
-	slots, err := s.repo.GetFreeSlots(ctx, specialistID, date)
+	ctx, span := tracer.Start(ctx, "AppointmentService.GetFreeSlots")
+	defer span.End()
+
+	slots, err := s.freeSlots(ctx, specialistID, date, time.Local)
 	if err != nil {
 		s.logger.Error("ошибка получения свободных слотов", zap.Error(err))
 		return nil, err
@@ -240,31 +706,139 @@ func (s *AppointmentServiceImpl) GetFreeSlots(ctx context.Context, specialistID
 	return slots, nil
 }
 
-func (s *AppointmentServiceImpl) CheckConsultationType(ctx context.Context, clientID int64, specialistID int64) (domain.ConsultationType, error) {
-	filter := domain.AppointmentFilter{
-		ClientID:     &clientID,
-		SpecialistID: &specialistID,
+// freeSlots возвращает слоты расписания специалиста без записей, удержаний и занятости во внешнем календаре
+func (s *AppointmentServiceImpl) freeSlots(ctx context.Context, specialistID int64, date string, location *time.Location) ([]string, error) {
+	slots, slotDuration, err := scheduledSlotsForDate(ctx, s.scheduleRepo, specialistID, date)
+	if err != nil || len(slots) == 0 {
+		return slots, err
 	}
 
-	appointments, err := s.repo.List(ctx, filter)
+	bookedSlots, err := s.repo.GetBookedSlots(ctx, specialistID, date)
 	if err != nil {
-		s.logger.Error("ошибка при проверке истории записей", zap.Error(err))
-		return "", fmt.Errorf("ошибка при проверке истории записей: %w", err)
+		return nil, err
+	}
+	slots = excludeSlots(slots, bookedSlots)
+
+	blocks, err := externalBlocksForDate(ctx, s.calendarRepo, specialistID, date, location)
+	if err != nil {
+		return nil, err
 	}
 
-	hasActiveAppointments := false
-	for _, appointment := range appointments {
-		if appointment.Status != domain.AppointmentStatusCancelled {
-			hasActiveAppointments = true
-			break
+	return filterExternallyBusy(slots, date, location, slotDuration, blocks), nil
+}
+
+// GetFreeSlotsBatch возвращает свободные слоты нескольких специалистов на дату. Расписания, исключения
+// и занятые слоты читаются общими запросами для всех специалистов, внешняя занятость — по каждому
+func (s *AppointmentServiceImpl) GetFreeSlotsBatch(ctx context.Context, specialistIDs []int64, date string) (map[int64][]string, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.GetFreeSlotsBatch")
+	defer span.End()
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: неверный формат даты, ожидается YYYY-MM-DD", ErrInvalid)
+	}
+	if len(specialistIDs) > domain.MaxFreeSlotsBatchSize {
+		return nil, fmt.Errorf("%w: можно запросить не больше %d специалистов", ErrInvalid, domain.MaxFreeSlotsBatchSize)
+	}
+
+	seen := make(map[int64]bool, len(specialistIDs))
+	unique := make([]int64, 0, len(specialistIDs))
+	for _, specialistID := range specialistIDs {
+		if !seen[specialistID] {
+			seen[specialistID] = true
+			unique = append(unique, specialistID)
 		}
 	}
+	specialistIDs = unique
 
-	if !hasActiveAppointments {
-		return domain.ConsultationTypePrimary, nil
+	schedules, err := s.scheduleRepo.ListBySpecialistsAndDate(ctx, specialistIDs, day)
+	if err != nil {
+		s.logger.Error("ошибка получения расписаний специалистов", zap.Error(err))
+		return nil, errors.New("ошибка получения свободных слотов")
+	}
+	overrides, err := s.scheduleRepo.ListOverridesForSpecialists(ctx, specialistIDs, day)
+	if err != nil {
+		s.logger.Error("ошибка получения исключений расписаний специалистов", zap.Error(err))
+		return nil, errors.New("ошибка получения свободных слотов")
+	}
+	bookedSlots, err := s.repo.GetBookedSlotsForSpecialists(ctx, specialistIDs, date)
+	if err != nil {
+		s.logger.Error("ошибка получения занятых слотов специалистов", zap.Error(err))
+		return nil, errors.New("ошибка получения свободных слотов")
+	}
+
+	schedulesByID := make(map[int64]*domain.Schedule, len(schedules))
+	for i := range schedules {
+		schedulesByID[schedules[i].SpecialistID] = &schedules[i]
+	}
+	overridesByID := make(map[int64]*domain.ScheduleOverride, len(overrides))
+	for i := range overrides {
+		overridesByID[overrides[i].SpecialistID] = &overrides[i]
+	}
+
+	result := make(map[int64][]string, len(specialistIDs))
+	for _, specialistID := range specialistIDs {
+		override, schedule := overridesByID[specialistID], schedulesByID[specialistID]
+		slots := slotsForDay(override, schedule)
+		slots = excludeSlots(slots, bookedSlots[specialistID])
+
+		if len(slots) > 0 {
+			blocks, err := externalBlocksForDate(ctx, s.calendarRepo, specialistID, date, time.Local)
+			if err != nil {
+				s.logger.Error("ошибка получения внешней занятости специалиста",
+					zap.Int64("specialistID", specialistID),
+					zap.Error(err))
+				return nil, errors.New("ошибка получения свободных слотов")
+			}
+			slots = filterExternallyBusy(slots, date, time.Local, slotDurationForDay(override, schedule), blocks)
+		}
+
+		if slots == nil {
+			slots = []string{}
+		}
+		result[specialistID] = slots
+	}
+
+	return result, nil
+}
+
+// CheckConsultationType определяет тип консультации клиента у специалиста, назначенной на время at.
+// Повторной считается консультация не позже SecondaryWindow после последней завершенной записи клиента
+// к специалисту; если specializationID задан, учитываются только записи по этой специализации, и первое
+// обращение по новой специализации снова первичное. Отмененные и незавершенные записи не учитываются
+func (s *AppointmentServiceImpl) CheckConsultationType(ctx context.Context, clientID, specialistID int64, specializationID *int64, at time.Time) (*domain.ConsultationTypeCheck, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.CheckConsultationType")
+	defer span.End()
+
+	last, err := s.repo.GetLastCompleted(ctx, clientID, specialistID, specializationID)
+	if err != nil {
+		s.logger.Error("ошибка при проверке истории записей", zap.Error(err))
+		return nil, fmt.Errorf("ошибка при проверке истории записей: %w", err)
+	}
+
+	return consultationTypeFor(last, at, s.cfg.SecondaryWindow), nil
+}
+
+// consultationTypeFor определяет тип консультации во время at по последней завершенной записи last (nil, если ее
+// не было). Граница окна включается: консультация ровно через window после last еще повторная
+func consultationTypeFor(last *domain.Appointment, at time.Time, window time.Duration) *domain.ConsultationTypeCheck {
+	if last == nil {
+		return &domain.ConsultationTypeCheck{Type: domain.ConsultationTypePrimary}
+	}
+
+	check := &domain.ConsultationTypeCheck{
+		Type:               domain.ConsultationTypeSecondary,
+		BasisAppointmentID: &last.ID,
+	}
+	if window > 0 {
+		windowEndsAt := last.AppointmentDate.Add(window)
+		if at.After(windowEndsAt) {
+			return &domain.ConsultationTypeCheck{Type: domain.ConsultationTypePrimary}
+		}
+		check.WindowEndsAt = &windowEndsAt
 	}
 
-	return domain.ConsultationTypeSecondary, nil
+	return check
 }
 
 func PointerTo[T any](v T) *T {
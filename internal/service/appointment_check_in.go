@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// Текст системного сообщения, которое появляется в чате записи при начале консультации
+const consultationStartedMessage = "Консультация началась"
+
+// CheckIn отмечает, что клиент clientID готов к консультации. Отметиться можно в подтвержденной записи
+// не раньше чем за domain.CheckInWindowBefore до начала и не позже domain.CallWindowAfter после него
+func (s *AppointmentServiceImpl) CheckIn(ctx context.Context, id, clientID int64) (*domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.CheckIn")
+	defer span.End()
+
+	appointment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Warn("запись для отметки клиента не найдена", zap.Int64("id", id), zap.Error(err))
+		return nil, fmt.Errorf("%w: запись не найдена", ErrNotFound)
+	}
+	if appointment.ClientID != clientID {
+		return nil, ErrForbidden
+	}
+
+	if err := checkStartWindow(appointment, time.Now()); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.CheckIn(ctx, id)
+	if errors.Is(err, repository.ErrAppointmentNotPaid) {
+		return nil, fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+	if err != nil {
+		s.logger.Error("ошибка отметки клиента", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New("ошибка при отметке")
+	}
+
+	s.logger.Info("клиент отметился перед консультацией", zap.Int64("id", id), zap.Int64("clientID", clientID))
+
+	return s.reloadAppointment(ctx, id, "ошибка при отметке")
+}
+
+// Start начинает консультацию: специалист записи userID переводит ее в статус in_progress, фактическое
+// начало сохраняется, а в чат записи отправляется системное сообщение. Без force клиент должен быть отмечен.
+// Это единственный способ перевести запись в статус in_progress
+func (s *AppointmentServiceImpl) Start(ctx context.Context, id, userID int64, force bool) (*domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.Start")
+	defer span.End()
+
+	appointment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Warn("запись для начала консультации не найдена", zap.Int64("id", id), zap.Error(err))
+		return nil, fmt.Errorf("%w: запись не найдена", ErrNotFound)
+	}
+
+	specialist, err := s.specialistRepo.GetByUserID(ctx, userID)
+	if err != nil || specialist.ID != appointment.SpecialistID {
+		return nil, ErrForbidden
+	}
+
+	if err := checkStartWindow(appointment, time.Now()); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Start(ctx, id, force)
+	switch {
+	case errors.Is(err, repository.ErrNotCheckedIn):
+		return nil, ErrNotCheckedIn
+	case errors.Is(err, repository.ErrAppointmentNotPaid):
+		return nil, fmt.Errorf("%w: %v", ErrConflict, err)
+	case err != nil:
+		s.logger.Error("ошибка начала консультации", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New("ошибка при начале консультации")
+	}
+
+	s.logger.Info("консультация начата",
+		zap.Int64("id", id),
+		zap.Int64("specialistID", specialist.ID),
+		zap.Bool("force", force))
+
+	s.postStartedMessage(ctx, appointment, userID)
+
+	return s.reloadAppointment(ctx, id, "ошибка при начале консультации")
+}
+
+// checkStartWindow проверяет, что время записи позволяет отметиться или начать консультацию
+func checkStartWindow(appointment *domain.Appointment, now time.Time) error {
+	if now.Before(appointment.AppointmentDate.Add(-domain.CheckInWindowBefore)) {
+		return fmt.Errorf("%w: консультация еще не началась, это можно сделать не раньше чем за %d минут до начала",
+			ErrConflict, int(domain.CheckInWindowBefore.Minutes()))
+	}
+	if now.After(appointment.AppointmentDate.Add(domain.CallWindowAfter)) {
+		return fmt.Errorf("%w: время консультации уже прошло", ErrConflict)
+	}
+	return nil
+}
+
+// postStartedMessage отправляет в чат записи системное сообщение о начале консультации от имени специалиста.
+// Консультация уже начата, поэтому ошибка только логируется
+func (s *AppointmentServiceImpl) postStartedMessage(ctx context.Context, appointment *domain.Appointment, senderID int64) {
+	confirmation := s.ensureChatSession(ctx, appointment)
+	if confirmation == nil {
+		return
+	}
+
+	_, err := s.chatService.CreateSystemMessage(ctx, confirmation.ChatSessionID, senderID, consultationStartedMessage)
+	if err != nil {
+		s.logger.Error("ошибка отправки системного сообщения о начале консультации",
+			zap.Int64("appointmentID", appointment.ID), zap.Error(err))
+	}
+}
+
+// reloadAppointment возвращает запись после изменения; errMessage отдается клиенту, если прочитать ее не удалось
+func (s *AppointmentServiceImpl) reloadAppointment(ctx context.Context, id int64, errMessage string) (*domain.Appointment, error) {
+	appointment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("ошибка получения записи после изменения", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New(errMessage)
+	}
+	return appointment, nil
+}
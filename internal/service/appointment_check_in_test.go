@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+// paidAppointment добавляет оплаченную запись клиента 10 к специалисту 7, начинающуюся через startsIn
+func (f *appointmentFixture) paidAppointment(startsIn time.Duration) *domain.Appointment {
+	appointment := &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7,
+		AppointmentDate: time.Now().Add(startsIn), Status: domain.AppointmentStatusPaid}
+	f.repo.appointments[1] = appointment
+	return appointment
+}
+
+func TestCheckInWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		startsIn time.Duration
+		wantErr  error
+	}{
+		{name: "too early", startsIn: domain.CheckInWindowBefore + time.Minute, wantErr: ErrConflict},
+		{name: "window opened", startsIn: domain.CheckInWindowBefore - time.Minute},
+		{name: "running late", startsIn: -time.Hour},
+		{name: "window closed", startsIn: -domain.CallWindowAfter - time.Minute, wantErr: ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAppointmentFixture()
+			f.paidAppointment(tt.startsIn)
+
+			appointment, err := f.service.CheckIn(context.Background(), 1, 10)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || f.repo.appointments[1].CheckedInAt != nil {
+					t.Errorf("CheckIn() = %+v, %v; want %v", appointment, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || appointment.CheckedInAt == nil {
+				t.Errorf("CheckIn() = %+v, %v", appointment, err)
+			}
+		})
+	}
+}
+
+func TestCheckInRules(t *testing.T) {
+	f := newAppointmentFixture()
+	f.paidAppointment(5 * time.Minute)
+
+	if _, err := f.service.CheckIn(context.Background(), 1, 11); !errors.Is(err, ErrForbidden) {
+		t.Errorf("another client: err = %v, want ErrForbidden", err)
+	}
+
+	f.repo.appointments[1].Status = domain.AppointmentStatusPending
+	if _, err := f.service.CheckIn(context.Background(), 1, 10); !errors.Is(err, ErrConflict) {
+		t.Errorf("unpaid appointment: err = %v, want ErrConflict", err)
+	}
+}
+
+func TestStartConsultation(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkedIn bool
+		force     bool
+		userID    int64
+		wantErr   error
+	}{
+		{name: "client checked in", checkedIn: true, userID: 70},
+		{name: "client not checked in", userID: 70, wantErr: ErrNotCheckedIn},
+		{name: "forced without check-in", force: true, userID: 70},
+		{name: "another user", checkedIn: true, userID: 71, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAppointmentFixture()
+			appointment := f.paidAppointment(5 * time.Minute)
+			if tt.checkedIn {
+				now := time.Now()
+				appointment.CheckedInAt = &now
+			}
+
+			started, err := f.service.Start(context.Background(), 1, tt.userID, tt.force)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || f.repo.appointments[1].Status != domain.AppointmentStatusPaid {
+					t.Errorf("Start() = %+v, %v; want %v", started, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || started.Status != domain.AppointmentStatusInProgress || started.ActualStartAt == nil {
+				t.Fatalf("Start() = %+v, %v", started, err)
+			}
+
+			session := f.chat.sessions[1]
+			if session == nil || len(f.chat.systemMessages[session.ID]) != 1 || f.chat.systemMessages[session.ID][0] != consultationStartedMessage {
+				t.Errorf("chat messages = %v, want one system message about the start", f.chat.systemMessages)
+			}
+		})
+	}
+}
+
+func TestUpdateStartedConsultation(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7,
+		AppointmentDate: tomorrowAt(10, 0), Status: domain.AppointmentStatusInProgress}
+
+	paid := domain.AppointmentStatusPaid
+	if _, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{Status: &paid}); !errors.Is(err, ErrConflict) {
+		t.Errorf("back to paid: err = %v, want ErrConflict", err)
+	}
+	moved := tomorrowAt(11, 0)
+	if _, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{AppointmentDate: &moved}); !errors.Is(err, ErrConflict) {
+		t.Errorf("reschedule: err = %v, want ErrConflict", err)
+	}
+
+	completed := domain.AppointmentStatusCompleted
+	if _, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{Status: &completed}); err != nil {
+		t.Errorf("complete: %v", err)
+	}
+}
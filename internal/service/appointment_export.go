@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+)
+
+// Количество записей, читаемых из базы за один запрос при выгрузке
+const exportBatchSize = 500
+
+// UTF-8 BOM: без него Excel открывает CSV с кириллицей в системной кодировке
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ExportCSV построчно пишет в w записи с appointment_date в [from, to), читая базу порциями.
+// specialistID == nil выгружает записи всех специалистов и добавляет колонку со специалистом.
+// Если ошибка возникла после начала записи, выгрузка обрывается на последней полной строке
+func (s *AppointmentServiceImpl) ExportCSV(ctx context.Context, w io.Writer, specialistID *int64, from, to time.Time) error {
+	ctx, span := tracer.Start(ctx, "AppointmentService.ExportCSV")
+	defer span.End()
+
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	withSpecialist := specialistID == nil
+
+	header := []string{"Дата", "Клиент", "Тип консультации", "Статус", "Цена", "Статус оплаты"}
+	if withSpecialist {
+		header = append(header, "Специалист")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	filter := domain.AppointmentExportFilter{
+		SpecialistID: specialistID,
+		From:         from,
+		To:           to,
+		Limit:        exportBatchSize,
+	}
+
+	for {
+		batch, err := s.repo.ListForExport(ctx, filter)
+		if err != nil {
+			s.logger.Error("ошибка выгрузки записей", zap.Error(err))
+			return errors.New("ошибка при выгрузке записей")
+		}
+
+		for _, a := range batch {
+			record := []string{
+				a.AppointmentDate.Format("2006-01-02 15:04"),
+				csvSafe(a.ClientName),
+				string(a.ConsultationType),
+				string(a.Status),
+				strconv.FormatFloat(a.Price, 'f', 2, 64),
+				string(a.PaymentStatus()),
+			}
+			if withSpecialist {
+				record = append(record, csvSafe(a.SpecialistName))
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+
+		// Сбрасываем каждую порцию, чтобы клиент получал файл по мере чтения базы
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		filter.AfterDate = &last.AppointmentDate
+		filter.AfterID = last.ID
+	}
+}
+
+// csvSafe экранирует значение, которое табличный редактор принял бы за формулу
+// (начинается с =, +, -, @, табуляции или возврата каретки), префиксом-апострофом
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func exportFixture() []domain.Appointment {
+	paymentID := "pay_1"
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	return []domain.Appointment{
+		{ID: 1, AppointmentDate: at(2, 9), ClientName: "Иванов Иван", SpecialistName: "Петрова Анна",
+			ConsultationType: domain.ConsultationTypePrimary, Status: domain.AppointmentStatusCompleted, Price: 3000},
+		{ID: 2, AppointmentDate: at(2, 10), ClientName: "Smith, John", SpecialistName: "Петрова Анна",
+			ConsultationType: domain.ConsultationTypeSecondary, Status: domain.AppointmentStatusPending, Price: 2000, PaymentID: &paymentID},
+		{ID: 3, AppointmentDate: at(3, 11), ClientName: `ООО "Ромашка"`, SpecialistName: `Сидоров "Юрист", Петр`,
+			ConsultationType: domain.ConsultationTypePrimary, Status: domain.AppointmentStatusCancelled, Price: 3500.5},
+		{ID: 4, AppointmentDate: at(4, 12), ClientName: "=HYPERLINK(\"http://evil\")", SpecialistName: "+7 999",
+			ConsultationType: domain.ConsultationTypePrimary, Status: domain.AppointmentStatusPaid, Price: 3000},
+		{ID: 5, AppointmentDate: at(5, 13), ClientName: "Анна\nс переносом", SpecialistName: "-Минус",
+			ConsultationType: domain.ConsultationTypeSecondary, Status: domain.AppointmentStatusInProgress, Price: 1999.99},
+	}
+}
+
+// assertGolden сравнивает got с testdata/name; go test -run Export -update перезаписывает файл
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestExportCSVGolden(t *testing.T) {
+	specialistID := int64(7)
+	tests := []struct {
+		name         string
+		specialistID *int64
+		golden       string
+	}{
+		{"specialist", &specialistID, "appointments_export_specialist.csv"},
+		{"admin", nil, "appointments_export_admin.csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAppointmentFixture()
+			f.repo.exported = exportFixture()
+
+			var buf bytes.Buffer
+			err := f.service.ExportCSV(context.Background(), &buf, tt.specialistID,
+				time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestExportCSVReadsInBatches(t *testing.T) {
+	f := newAppointmentFixture()
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	total := 2*exportBatchSize + 1
+	for i := 0; i < total; i++ {
+		// Несколько записей на одно время проверяют курсор по (appointment_date, id)
+		f.repo.exported = append(f.repo.exported, domain.Appointment{
+			ID:              int64(i + 1),
+			AppointmentDate: start.Add(time.Duration(i/3) * time.Hour),
+			ClientName:      "Клиент",
+			Status:          domain.AppointmentStatusPending,
+			Price:           1000,
+		})
+	}
+
+	var buf bytes.Buffer
+	specialistID := int64(7)
+	if err := f.service.ExportCSV(context.Background(), &buf, &specialistID, start, start.AddDate(1, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), utf8BOM) {
+		t.Fatal("export does not start with a UTF-8 BOM")
+	}
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes()[len(utf8BOM):])).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != total+1 {
+		t.Errorf("rows = %d, want header and %d appointments", len(records), total)
+	}
+	if f.repo.exportCalls != 3 {
+		t.Errorf("repository reads = %d, want 3 batches", f.repo.exportCalls)
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := map[string]string{
+		"":             "",
+		"Иванов":       "Иванов",
+		"=1+1":         "'=1+1",
+		"+79990000000": "'+79990000000",
+		"-5":           "'-5",
+		"@SUM(A1)":     "'@SUM(A1)",
+		"\tname":       "'\tname",
+		"a=b":          "a=b",
+	}
+
+	for in, want := range tests {
+		if got := csvSafe(in); got != want {
+			t.Errorf("csvSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+func TestConsultationTypeForWindowBoundary(t *testing.T) {
+	const window = 4320 * time.Hour // значение SECONDARY_CONSULTATION_WINDOW по умолчанию, около 6 месяцев
+	last := &domain.Appointment{ID: 5, AppointmentDate: time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)}
+	windowEndsAt := last.AppointmentDate.Add(window)
+
+	tests := []struct {
+		name string
+		last *domain.Appointment
+		at   time.Time
+		want domain.ConsultationType
+	}{
+		{"first visit", nil, windowEndsAt, domain.ConsultationTypePrimary},
+		{"right after the last visit", last, last.AppointmentDate.Add(24 * time.Hour), domain.ConsultationTypeSecondary},
+		{"a second before the window ends", last, windowEndsAt.Add(-time.Second), domain.ConsultationTypeSecondary},
+		{"exactly at the window end", last, windowEndsAt, domain.ConsultationTypeSecondary},
+		{"a second after the window ends", last, windowEndsAt.Add(time.Second), domain.ConsultationTypePrimary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := consultationTypeFor(tt.last, tt.at, window)
+			if check.Type != tt.want {
+				t.Fatalf("type = %s, want %s", check.Type, tt.want)
+			}
+
+			if tt.want == domain.ConsultationTypePrimary {
+				if check.BasisAppointmentID != nil || check.WindowEndsAt != nil {
+					t.Errorf("primary check carries a basis: %+v", check)
+				}
+				return
+			}
+			if check.BasisAppointmentID == nil || *check.BasisAppointmentID != last.ID {
+				t.Errorf("basis appointment = %v, want %d", check.BasisAppointmentID, last.ID)
+			}
+			if check.WindowEndsAt == nil || !check.WindowEndsAt.Equal(windowEndsAt) {
+				t.Errorf("window ends at = %v, want %v", check.WindowEndsAt, windowEndsAt)
+			}
+		})
+	}
+}
+
+func TestConsultationTypeForWithoutWindow(t *testing.T) {
+	last := &domain.Appointment{ID: 5, AppointmentDate: time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC)}
+
+	check := consultationTypeFor(last, time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC), 0)
+	if check.Type != domain.ConsultationTypeSecondary {
+		t.Errorf("type = %s, want secondary without a window", check.Type)
+	}
+	if check.WindowEndsAt != nil {
+		t.Errorf("window ends at = %v, want none", check.WindowEndsAt)
+	}
+}
+
+func TestCheckConsultationTypeUsesLastCompleted(t *testing.T) {
+	f := newAppointmentFixture()
+	at := tomorrowAt(10, 0)
+
+	check, err := f.service.CheckConsultationType(context.Background(), 1, 7, nil, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Type != domain.ConsultationTypePrimary {
+		t.Errorf("without history type = %s, want primary", check.Type)
+	}
+
+	f.repo.lastCompleted = &domain.Appointment{ID: 3, AppointmentDate: at.Add(-f.service.cfg.SecondaryWindow)}
+	check, err = f.service.CheckConsultationType(context.Background(), 1, 7, nil, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Type != domain.ConsultationTypeSecondary {
+		t.Errorf("at the window end type = %s, want secondary", check.Type)
+	}
+
+	f.repo.lastCompleted.AppointmentDate = at.Add(-f.service.cfg.SecondaryWindow - time.Minute)
+	check, err = f.service.CheckConsultationType(context.Background(), 1, 7, nil, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Type != domain.ConsultationTypePrimary {
+		t.Errorf("after the window type = %s, want primary", check.Type)
+	}
+}
+
+func TestCheckConsultationTypeBySpecialization(t *testing.T) {
+	f := newAppointmentFixture()
+	specializationID := int64(12)
+
+	if _, err := f.service.CheckConsultationType(context.Background(), 1, 7, &specializationID, tomorrowAt(10, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if f.repo.lastCompletedScope == nil || *f.repo.lastCompletedScope != specializationID {
+		t.Errorf("history scoped to %v, want specialization %d", f.repo.lastCompletedScope, specializationID)
+	}
+}
+
+func TestCreateAppliesConsultationTypeFromHistory(t *testing.T) {
+	at := tomorrowAt(10, 0)
+	tests := []struct {
+		name      string
+		last      *domain.Appointment
+		requested domain.ConsultationType
+		want      domain.ConsultationType
+	}{
+		{"first visit asking for the secondary price", nil, domain.ConsultationTypeSecondary, domain.ConsultationTypePrimary},
+		{"first visit without a type", nil, "", domain.ConsultationTypePrimary},
+		{"returning client asking for the primary price", &domain.Appointment{ID: 3, AppointmentDate: at.AddDate(0, -1, 0)}, domain.ConsultationTypePrimary, domain.ConsultationTypeSecondary},
+		{"returning client without a type", &domain.Appointment{ID: 3, AppointmentDate: at.AddDate(0, -1, 0)}, "", domain.ConsultationTypeSecondary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAppointmentFixture()
+			f.repo.lastCompleted = tt.last
+
+			_, applied, err := f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+				SpecialistID:        7,
+				ConsultationType:    tt.requested,
+				AppointmentDate:     at,
+				CommunicationMethod: domain.CommunicationMethodPhone,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if applied != tt.want {
+				t.Errorf("applied type = %s, want %s", applied, tt.want)
+			}
+			if len(f.repo.created) != 1 || f.repo.created[0].ConsultationType != tt.want {
+				t.Errorf("stored appointments = %+v, want type %s", f.repo.created, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateRejectsTimeOutsideSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		at       time.Time
+		override *domain.ScheduleOverride
+	}{
+		{"between slots", tomorrowAt(10, 30), nil},
+		{"before the working day", tomorrowAt(8, 0), nil},
+		{"at the end of the working day", tomorrowAt(13, 0), nil},
+		{"with seconds", tomorrowAt(10, 0).Add(15 * time.Second), nil},
+		{"on a day off", tomorrowAt(10, 0), &domain.ScheduleOverride{SpecialistID: 7, IsDayOff: true}},
+		{"outside shortened hours", tomorrowAt(12, 0), &domain.ScheduleOverride{SpecialistID: 7, StartTime: "09:00", EndTime: "11:00", SlotTime: 60}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAppointmentFixture()
+			f.schedules.override = tt.override
+
+			_, _, err := f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+				SpecialistID:        7,
+				AppointmentDate:     tt.at,
+				CommunicationMethod: domain.CommunicationMethodPhone,
+			})
+			if !errors.Is(err, ErrInvalid) {
+				t.Fatalf("err = %v, want ErrInvalid", err)
+			}
+			if len(f.repo.created) != 0 {
+				t.Errorf("stored appointments = %+v, want none", f.repo.created)
+			}
+		})
+	}
+}
+
+func TestCreateFromHoldRechecksSchedule(t *testing.T) {
+	f := newAppointmentFixture()
+	// Удержание оформлено до того, как специалист взял выходной
+	f.schedules.override = &domain.ScheduleOverride{SpecialistID: 7, IsDayOff: true}
+	holdID := int64(1)
+
+	_, _, err := f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+		SpecialistID:        7,
+		AppointmentDate:     tomorrowAt(10, 0),
+		CommunicationMethod: domain.CommunicationMethodPhone,
+		HoldID:              &holdID,
+	})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestUpdateRejectsRescheduleOutsideSchedule(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.appointments[1] = &domain.Appointment{
+		ID:              1,
+		ClientID:        1,
+		SpecialistID:    7,
+		AppointmentDate: tomorrowAt(10, 0),
+		Status:          domain.AppointmentStatusPending,
+	}
+
+	outside := tomorrowAt(11, 30)
+	if _, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{AppointmentDate: &outside}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("err = %v, want ErrInvalid", err)
+	}
+	if len(f.repo.updated) != 0 {
+		t.Fatalf("updates = %+v, want none", f.repo.updated)
+	}
+
+	inside := tomorrowAt(11, 0)
+	if _, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{AppointmentDate: &inside}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.repo.updated) != 1 {
+		t.Errorf("updates = %+v, want one reschedule", f.repo.updated)
+	}
+}
+
+func TestCreateWhenNotAcceptingClients(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []domain.AppointmentStatus
+		wantErr error
+	}{
+		{name: "new client", wantErr: ErrNotAcceptingClients},
+		{name: "only cancelled appointments", history: []domain.AppointmentStatus{domain.AppointmentStatusCancelled}, wantErr: ErrNotAcceptingClients},
+		{name: "returning client", history: []domain.AppointmentStatus{domain.AppointmentStatusCompleted}},
+		{name: "upcoming appointment", history: []domain.AppointmentStatus{domain.AppointmentStatusPending}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAppointmentFixture()
+			f.specialist.AcceptingClients = false
+			for i, status := range tt.history {
+				id := int64(100 + i)
+				f.repo.appointments[id] = &domain.Appointment{ID: id, ClientID: 1, SpecialistID: 7, Status: status}
+			}
+			// Запись того же клиента к другому специалисту не делает его постоянным клиентом
+			f.repo.appointments[200] = &domain.Appointment{ID: 200, ClientID: 1, SpecialistID: 8, Status: domain.AppointmentStatusCompleted}
+
+			_, _, err := f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+				SpecialistID:        7,
+				AppointmentDate:     tomorrowAt(10, 0),
+				CommunicationMethod: domain.CommunicationMethodPhone,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || len(f.repo.created) != 0 {
+					t.Errorf("err = %v, created = %d; want %v", err, len(f.repo.created), tt.wantErr)
+				}
+				return
+			}
+			if err != nil || len(f.repo.created) != 1 {
+				t.Errorf("err = %v, created = %d; want the booking accepted", err, len(f.repo.created))
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// appointmentTransferAudit состояние записи, сохраняемое в журнал аудита до и после передачи
+type appointmentTransferAudit struct {
+	SpecialistID     int64                   `json:"specialist_id"`
+	ConsultationType domain.ConsultationType `json:"consultation_type"`
+	Price            float64                 `json:"price"`
+}
+
+// Transfer передает запись другому специалисту по решению администратора actorID. Новый специалист должен
+// вести специализацию записи и быть свободен в ее время с учетом длительности своих слотов. Тип консультации
+// и цена пересчитываются по истории клиента и прайсу нового специалиста, клиент и оба специалиста получают уведомление
+func (s *AppointmentServiceImpl) Transfer(ctx context.Context, id, actorID int64, dto domain.TransferAppointmentDTO) (*domain.Appointment, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.Transfer")
+	defer span.End()
+
+	appointment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("запись для передачи не найдена", zap.Int64("id", id), zap.Error(err))
+		return nil, fmt.Errorf("%w: запись не найдена", ErrNotFound)
+	}
+
+	if appointment.Status == domain.AppointmentStatusCompleted || appointment.Status == domain.AppointmentStatusCancelled {
+		return nil, fmt.Errorf("%w: нельзя передать завершенную или отмененную запись", ErrConflict)
+	}
+	if appointment.Status == domain.AppointmentStatusInProgress {
+		return nil, fmt.Errorf("%w: нельзя передать начатую консультацию", ErrConflict)
+	}
+	if appointment.SpecialistID == dto.SpecialistID {
+		return nil, fmt.Errorf("%w: запись уже назначена этому специалисту", ErrInvalid)
+	}
+
+	previous, err := s.specialistRepo.GetByIDWithDeleted(ctx, appointment.SpecialistID)
+	if err != nil {
+		s.logger.Error("текущий специалист записи не найден",
+			zap.Int64("specialistID", appointment.SpecialistID), zap.Error(err))
+		return nil, errors.New("ошибка при передаче записи")
+	}
+
+	target, err := s.specialistRepo.GetByID(ctx, dto.SpecialistID)
+	if err != nil {
+		s.logger.Warn("специалист для передачи записи не найден", zap.Int64("specialistID", dto.SpecialistID), zap.Error(err))
+		return nil, fmt.Errorf("%w: специалист не найден", ErrNotFound)
+	}
+
+	if err := s.checkOffersSpecialization(ctx, appointment, previous, target); err != nil {
+		return nil, err
+	}
+
+	blocked, err := s.blockListRepo.IsBlocked(ctx, target.ID, appointment.ClientID)
+	if err != nil {
+		s.logger.Error("ошибка проверки блокировки клиента", zap.Int64("clientID", appointment.ClientID), zap.Error(err))
+		return nil, errors.New("ошибка при передаче записи")
+	}
+	if blocked {
+		return nil, fmt.Errorf("%w: специалист заблокировал клиента", ErrConflict)
+	}
+
+	slotDuration, err := s.checkTransferSlot(ctx, target.ID, appointment.AppointmentDate)
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := s.CheckConsultationType(ctx, appointment.ClientID, target.ID, appointment.SpecializationID, appointment.AppointmentDate)
+	if err != nil {
+		return nil, errors.New("ошибка при передаче записи")
+	}
+
+	err = s.repo.Transfer(ctx, id, target.ID, check.Type, slotDuration)
+	if errors.Is(err, repository.ErrSlotTaken) {
+		return nil, fmt.Errorf("%w: у специалиста уже есть запись в это время", ErrConflict)
+	}
+	if errors.Is(err, repository.ErrAppointmentClosed) {
+		return nil, fmt.Errorf("%w: нельзя передать начатую, завершенную или отмененную запись", ErrConflict)
+	}
+	if err != nil {
+		s.logger.Error("ошибка передачи записи", zap.Int64("id", id), zap.Int64("specialistID", target.ID), zap.Error(err))
+		return nil, errors.New("ошибка при передаче записи")
+	}
+
+	transferred, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("ошибка получения переданной записи", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New("ошибка при передаче записи")
+	}
+
+	s.auditTransfer(ctx, actorID, appointment, transferred)
+	s.notifyTransfer(ctx, transferred, previous, target)
+
+	s.logger.Info("запись передана другому специалисту",
+		zap.Int64("id", id),
+		zap.Int64("fromSpecialistID", previous.ID),
+		zap.Int64("toSpecialistID", target.ID),
+		zap.Int64("actorID", actorID))
+
+	return transferred, nil
+}
+
+// checkOffersSpecialization проверяет, что новый специалист ведет специализацию записи: основную или одну
+// из дополнительных. Для записи без специализации достаточно, чтобы тип специалистов совпадал
+func (s *AppointmentServiceImpl) checkOffersSpecialization(ctx context.Context, appointment *domain.Appointment, previous, target *domain.Specialist) error {
+	if appointment.SpecializationID == nil {
+		if target.Type != previous.Type {
+			return fmt.Errorf("%w: специалист другого типа не может принять запись", ErrInvalid)
+		}
+		return nil
+	}
+
+	specializationID := *appointment.SpecializationID
+	if target.SpecializationID != nil && *target.SpecializationID == specializationID {
+		return nil
+	}
+
+	specializations, err := s.specialistRepo.GetSpecializationsBySpecialistID(ctx, target.ID)
+	if err != nil {
+		s.logger.Error("ошибка получения специализаций специалиста", zap.Int64("specialistID", target.ID), zap.Error(err))
+		return errors.New("ошибка при передаче записи")
+	}
+	for _, specialization := range specializations {
+		if specialization.ID == specializationID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: специалист не ведет специализацию записи", ErrInvalid)
+}
+
+// checkTransferSlot проверяет, что время записи совпадает с началом слота в расписании специалиста
+// и не пересекается с его занятостью во внешнем календаре. Возвращает длительность слота специалиста
+func (s *AppointmentServiceImpl) checkTransferSlot(ctx context.Context, specialistID int64, date time.Time) (time.Duration, error) {
+	day, _ := time.Parse("2006-01-02", date.Format("2006-01-02"))
+
+	override, err := s.scheduleRepo.GetOverride(ctx, specialistID, day)
+	if err != nil {
+		s.logger.Error("ошибка получения расписания специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return 0, errors.New("ошибка при проверке доступности времени")
+	}
+
+	var schedule *domain.Schedule
+	if override == nil {
+		schedule, err = s.scheduleRepo.GetBySpecialistAndDate(ctx, specialistID, day)
+		if err != nil {
+			s.logger.Error("ошибка получения расписания специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+			return 0, errors.New("ошибка при проверке доступности времени")
+		}
+	}
+
+	slotDuration := slotDurationForDay(override, schedule)
+
+	timeStr := date.Format("15:04")
+	scheduled := false
+	for _, slot := range slotsForDay(override, schedule) {
+		if slot == timeStr {
+			scheduled = true
+			break
+		}
+	}
+	if !scheduled {
+		return 0, fmt.Errorf("%w: у специалиста нет приема в это время", ErrConflict)
+	}
+
+	blocks, err := externalBlocksForDate(ctx, s.calendarRepo, specialistID, date.Format("2006-01-02"), date.Location())
+	if err != nil {
+		s.logger.Error("ошибка получения внешней занятости специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return 0, errors.New("ошибка при проверке доступности времени")
+	}
+	if isExternallyBusy(blocks, date, date.Add(slotDuration)) {
+		return 0, fmt.Errorf("%w: специалист занят в это время", ErrConflict)
+	}
+
+	return slotDuration, nil
+}
+
+// auditTransfer записывает передачу в журнал аудита. Передача уже выполнена, поэтому ошибка только логируется
+func (s *AppointmentServiceImpl) auditTransfer(ctx context.Context, actorID int64, before, after *domain.Appointment) {
+	oldValue, _ := json.Marshal(appointmentTransferAudit{
+		SpecialistID:     before.SpecialistID,
+		ConsultationType: before.ConsultationType,
+		Price:            before.Price,
+	})
+	newValue, _ := json.Marshal(appointmentTransferAudit{
+		SpecialistID:     after.SpecialistID,
+		ConsultationType: after.ConsultationType,
+		Price:            after.Price,
+	})
+
+	err := s.auditRepo.Log(ctx, domain.AuditEntry{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionAppointmentTransfer,
+		EntityType: domain.AuditEntityAppointment,
+		EntityID:   before.ID,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+	})
+	if err != nil {
+		s.logger.Error("ошибка записи передачи записи в журнал аудита", zap.Int64("appointmentID", before.ID), zap.Error(err))
+	}
+}
+
+// notifyTransfer уведомляет клиента, прежнего и нового специалиста о передаче записи. Ошибки только логируются
+func (s *AppointmentServiceImpl) notifyTransfer(ctx context.Context, appointment *domain.Appointment, previous, target *domain.Specialist) {
+	date := appointment.AppointmentDate.Format("02.01.2006 15:04")
+	targetName := strings.TrimSpace(target.User.FirstName + " " + target.User.LastName)
+
+	clientBody := fmt.Sprintf("Запись на %s передана другому специалисту", date)
+	if targetName != "" {
+		clientBody += ": " + targetName
+	}
+
+	data := map[string]interface{}{
+		"appointment_id":         appointment.ID,
+		"specialist_id":          target.ID,
+		"previous_specialist_id": previous.ID,
+		"appointment_date":       appointment.AppointmentDate,
+		"price":                  appointment.Price,
+		"consultation_type":      appointment.ConsultationType,
+	}
+
+	notifications := []domain.Notification{
+		{
+			UserID: appointment.ClientID,
+			Type:   domain.NotificationTypeAppointmentTransfer,
+			Title:  "Запись передана другому специалисту",
+			Body:   clientBody,
+			Data:   data,
+		},
+		{
+			UserID: target.UserID,
+			Type:   domain.NotificationTypeAppointmentTransfer,
+			Title:  "Вам передана запись",
+			Body:   fmt.Sprintf("Вам передана запись на %s", date),
+			Data:   data,
+		},
+		{
+			UserID: previous.UserID,
+			Type:   domain.NotificationTypeAppointmentTransfer,
+			Title:  "Запись передана другому специалисту",
+			Body:   fmt.Sprintf("Ваша запись на %s передана другому специалисту", date),
+			Data:   data,
+		},
+	}
+
+	for _, notification := range notifications {
+		if err := s.notifier.Notify(ctx, notification); err != nil {
+			s.logger.Error("ошибка уведомления о передаче записи",
+				zap.Int64("appointmentID", appointment.ID),
+				zap.Int64("userID", notification.UserID),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+type AuditServiceImpl struct {
+	repo   repository.AuditRepository
+	logger *zap.Logger
+}
+
+func NewAuditService(repo repository.AuditRepository, logger *zap.Logger) *AuditServiceImpl {
+	return &AuditServiceImpl{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *AuditServiceImpl) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, int, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.List")
+	defer span.End()
+
+	entries, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("ошибка получения журнала аудита", zap.Error(err))
+		return nil, 0, errors.New("ошибка при получении журнала аудита")
+	}
+
+	return entries, total, nil
+}
@@ -41,12 +41,12 @@ func NewAuthService(authRepo repository.AuthRepository, userRepo repository.User
 func (s *AuthServiceImpl) Register(ctx context.Context, dto domain.RegisterRequest) (int64, error) {
 	existingUser, err := s.userRepo.GetByEmail(ctx, dto.Email)
 	if err == nil && existingUser != nil {
-		return 0, errors.New("пользователь с таким email уже существует")
+		return 0, fmt.Errorf("%w: %v", ErrConflict, repository.ErrEmailTaken)
 	}
 
 	existingUser, err = s.userRepo.GetByPhone(ctx, dto.Phone)
 	if err == nil && existingUser != nil {
-		return 0, errors.New("пользователь с таким телефоном уже существует")
+		return 0, fmt.Errorf("%w: %v", ErrConflict, repository.ErrPhoneTaken)
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(dto.Password), bcrypt.DefaultCost)
@@ -65,7 +65,11 @@ func (s *AuthServiceImpl) Register(ctx context.Context, dto domain.RegisterReque
 		Role:       dto.Role,
 	}
 
+	// Проверки выше не защищают от одновременной регистрации, поэтому ограничение БД тоже учитывается
 	userID, err := s.userRepo.Create(ctx, createUserDTO)
+	if errors.Is(err, repository.ErrEmailTaken) || errors.Is(err, repository.ErrPhoneTaken) {
+		return 0, fmt.Errorf("%w: %v", ErrConflict, err)
+	}
 	if err != nil {
 		s.logger.Error("ошибка при создании пользователя", zap.Error(err))
 		return 0, errors.New("ошибка при регистрации пользователя")
@@ -87,6 +91,10 @@ func (s *AuthServiceImpl) Login(ctx context.Context, dto domain.LoginRequest, us
 		}
 	}
 
+	if !user.HasPassword() {
+		return nil, ErrPasswordNotSet
+	}
+
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(dto.Password))
 	if err != nil {
 		s.logger.Error("неверный пароль", zap.Error(err))
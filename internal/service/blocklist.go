@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+const maxBlockReasonLength = 500
+
+type BlockListServiceImpl struct {
+	repo     repository.BlockListRepository
+	userRepo repository.UserRepository
+	logger   *zap.Logger
+}
+
+func NewBlockListService(repo repository.BlockListRepository, userRepo repository.UserRepository, logger *zap.Logger) *BlockListServiceImpl {
+	return &BlockListServiceImpl{
+		repo:     repo,
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+func (s *BlockListServiceImpl) BlockClient(ctx context.Context, specialistID int64, dto domain.BlockClientDTO) error {
+	ctx, span := tracer.Start(ctx, "BlockListService.BlockClient")
+	defer span.End()
+
+	client, err := s.userRepo.GetByID(ctx, dto.ClientID)
+	if err != nil || client == nil {
+		return fmt.Errorf("%w: клиент не найден", ErrInvalid)
+	}
+	if client.Role != domain.UserRoleClient {
+		return fmt.Errorf("%w: заблокировать можно только клиента", ErrInvalid)
+	}
+
+	var reason *string
+	if trimmed := strings.TrimSpace(dto.Reason); trimmed != "" {
+		if utf8.RuneCountInString(trimmed) > maxBlockReasonLength {
+			return fmt.Errorf("%w: причина блокировки не должна превышать %d символов", ErrInvalid, maxBlockReasonLength)
+		}
+		reason = &trimmed
+	}
+
+	if err := s.repo.Block(ctx, specialistID, dto.ClientID, reason); err != nil {
+		s.logger.Error("ошибка блокировки клиента",
+			zap.Int64("specialistID", specialistID),
+			zap.Int64("clientID", dto.ClientID),
+			zap.Error(err))
+		return errors.New("ошибка при блокировке клиента")
+	}
+
+	s.logger.Info("специалист заблокировал клиента",
+		zap.Int64("specialistID", specialistID),
+		zap.Int64("clientID", dto.ClientID))
+
+	return nil
+}
+
+func (s *BlockListServiceImpl) UnblockClient(ctx context.Context, specialistID, clientID int64) error {
+	ctx, span := tracer.Start(ctx, "BlockListService.UnblockClient")
+	defer span.End()
+
+	removed, err := s.repo.Unblock(ctx, specialistID, clientID)
+	if err != nil {
+		s.logger.Error("ошибка разблокировки клиента",
+			zap.Int64("specialistID", specialistID),
+			zap.Int64("clientID", clientID),
+			zap.Error(err))
+		return errors.New("ошибка при разблокировке клиента")
+	}
+	if !removed {
+		return fmt.Errorf("%w: клиент не заблокирован", ErrNotFound)
+	}
+
+	return nil
+}
+
+func (s *BlockListServiceImpl) ListBlockedClients(ctx context.Context, specialistID int64) ([]domain.BlockedClient, error) {
+	ctx, span := tracer.Start(ctx, "BlockListService.ListBlockedClients")
+	defer span.End()
+
+	blocked, err := s.repo.List(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("ошибка получения заблокированных клиентов", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении заблокированных клиентов")
+	}
+
+	return blocked, nil
+}
+
+// IsBlockedBetweenUsers сообщает, заблокировал ли один из пользователей (специалист) другого (клиента)
+func (s *BlockListServiceImpl) IsBlockedBetweenUsers(ctx context.Context, userA, userB int64) (bool, error) {
+	blocked, err := s.repo.IsBlockedBetweenUsers(ctx, userA, userB)
+	if err != nil {
+		s.logger.Error("ошибка проверки блокировки", zap.Int64("userA", userA), zap.Int64("userB", userB), zap.Error(err))
+		return false, errors.New("ошибка при проверке блокировки")
+	}
+	return blocked, nil
+}
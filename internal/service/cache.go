@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+)
+
+const (
+	specializationsCachePrefix = "specializations:"
+	specialistsCachePrefix     = "specialists:"
+	ratingSummaryCachePrefix   = "ratings:"
+	activityStatsCachePrefix   = "activity:"
+	responseStatsCachePrefix   = "response_stats:"
+	reviewsCachePrefix         = "reviews:"
+)
+
+func specializationListCacheKey(filter domain.SpecializationFilter) string {
+	key := fmt.Sprintf("%slist:limit=%d:offset=%d", specializationsCachePrefix, filter.Limit, filter.Offset)
+	if filter.Type != nil {
+		key += ":type=" + string(*filter.Type)
+	}
+	if filter.IsActive != nil {
+		key += fmt.Sprintf(":active=%t", *filter.IsActive)
+	}
+	if filter.SpecialistID != nil {
+		key += fmt.Sprintf(":specialist=%d", *filter.SpecialistID)
+	}
+	if filter.SearchTerm != nil {
+		key += ":search=" + *filter.SearchTerm
+	}
+	if filter.ParentID != nil {
+		key += fmt.Sprintf(":parent=%d", *filter.ParentID)
+	}
+	return key
+}
+
+func specializationTreeCacheKey(filter domain.SpecializationFilter) string {
+	key := specializationsCachePrefix + "tree"
+	if filter.Type != nil {
+		key += ":type=" + string(*filter.Type)
+	}
+	if filter.IsActive != nil {
+		key += fmt.Sprintf(":active=%t", *filter.IsActive)
+	}
+	if filter.SpecialistID != nil {
+		key += fmt.Sprintf(":specialist=%d", *filter.SpecialistID)
+	}
+	if filter.SearchTerm != nil {
+		key += ":search=" + *filter.SearchTerm
+	}
+	return key
+}
+
+func specialistListCacheKey(filter domain.SpecialistFilter) string {
+	key := fmt.Sprintf("%slist:limit=%d", specialistsCachePrefix, filter.Limit)
+	if filter.Type != nil {
+		key += ":type=" + string(*filter.Type)
+	}
+	if filter.SpecializationID != nil {
+		key += fmt.Sprintf(":specialization=%d", *filter.SpecializationID)
+	}
+	if filter.Language != nil {
+		key += ":language=" + *filter.Language
+	}
+	if len(filter.Tags) > 0 {
+		tags := append([]string(nil), filter.Tags...)
+		sort.Strings(tags)
+		key += ":tags=" + strings.Join(tags, ",")
+	}
+	if filter.AcceptingClients != nil {
+		key += fmt.Sprintf(":accepting=%t", *filter.AcceptingClients)
+	}
+	if filter.MinExperienceYears != nil {
+		key += fmt.Sprintf(":min_experience=%d", *filter.MinExperienceYears)
+	}
+	if filter.IncludeDeleted {
+		key += ":deleted=1"
+	}
+	return key
+}
+
+func activityStatsCacheKey(specialistID int64) string {
+	return fmt.Sprintf("%s%d", activityStatsCachePrefix, specialistID)
+}
+
+func responseStatsCacheKey(specialistID int64) string {
+	return fmt.Sprintf("%s%d", responseStatsCachePrefix, specialistID)
+}
+
+func ratingSummaryCacheKey(specialistID int64) string {
+	return fmt.Sprintf("%ssummary:%d", ratingSummaryCachePrefix, specialistID)
+}
+
+func publicRecentReviewsCacheKey(limit int) string {
+	return fmt.Sprintf("%spublic_recent:limit=%d", reviewsCachePrefix, limit)
+}
+
+// invalidateCache сбрасывает закэшированные ответы; ошибки только логируются,
+// так как запись в БД к этому моменту уже выполнена
+func invalidateCache(ctx context.Context, c cache.Cache, logger *zap.Logger, prefixes ...string) {
+	for _, prefix := range prefixes {
+		if err := c.DeletePrefix(ctx, prefix); err != nil {
+			logger.Warn("ошибка инвалидации кэша", zap.String("prefix", prefix), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+)
+
+func TestSpecialistListCacheKeysDoNotCollide(t *testing.T) {
+	lawyer := domain.SpecialistTypeLawyer
+	specializationID := int64(3)
+	language := "en"
+	accepting := true
+	notAccepting := false
+	experience := 5
+
+	filters := map[string]domain.SpecialistFilter{
+		"empty":          {Limit: 10},
+		"other limit":    {Limit: 20},
+		"type":           {Limit: 10, Type: &lawyer},
+		"specialization": {Limit: 10, SpecializationID: &specializationID},
+		"language":       {Limit: 10, Language: &language},
+		"tags":           {Limit: 10, Tags: []string{"family"}},
+		"more tags":      {Limit: 10, Tags: []string{"family", "tax"}},
+		"accepting":      {Limit: 10, AcceptingClients: &accepting},
+		"not accepting":  {Limit: 10, AcceptingClients: &notAccepting},
+		"experience":     {Limit: 10, MinExperienceYears: &experience},
+		"with deleted":   {Limit: 10, IncludeDeleted: true},
+	}
+
+	seen := make(map[string]string, len(filters))
+	for name, filter := range filters {
+		key := specialistListCacheKey(filter)
+		if other, ok := seen[key]; ok {
+			t.Errorf("filters %q and %q share cache key %q", name, other, key)
+		}
+		seen[key] = name
+	}
+
+	// Порядок тегов в запросе не влияет на результат и не должен плодить записи кэша
+	a := specialistListCacheKey(domain.SpecialistFilter{Limit: 10, Tags: []string{"tax", "family"}})
+	b := specialistListCacheKey(domain.SpecialistFilter{Limit: 10, Tags: []string{"family", "tax"}})
+	if a != b {
+		t.Errorf("tag order changes the key: %q != %q", a, b)
+	}
+}
+
+func TestSpecializationListCacheKeysDoNotCollide(t *testing.T) {
+	psychologist := domain.SpecialistTypePsychologist
+	active := true
+	specialistID := int64(7)
+	search := "семья"
+	parentID := int64(1)
+
+	filters := map[string]domain.SpecializationFilter{
+		"empty":      {Limit: 10},
+		"next page":  {Limit: 10, Offset: 10},
+		"type":       {Limit: 10, Type: &psychologist},
+		"active":     {Limit: 10, IsActive: &active},
+		"specialist": {Limit: 10, SpecialistID: &specialistID},
+		"search":     {Limit: 10, SearchTerm: &search},
+		"parent":     {Limit: 10, ParentID: &parentID},
+	}
+
+	seen := make(map[string]string, len(filters))
+	for name, filter := range filters {
+		key := specializationListCacheKey(filter)
+		if other, ok := seen[key]; ok {
+			t.Errorf("filters %q and %q share cache key %q", name, other, key)
+		}
+		seen[key] = name
+	}
+	if specializationListCacheKey(domain.SpecializationFilter{}) == specializationTreeCacheKey(domain.SpecializationFilter{}) {
+		t.Error("list and tree share a cache key")
+	}
+}
+
+func TestSpecializationCacheInvalidatedOnWrite(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeSpecializationRepo{items: []domain.Specialization{
+		{ID: 1, Name: "Семейное право", Type: domain.SpecialistTypeLawyer, IsActive: true},
+	}}
+	s := NewSpecializationService(repo, cache.NewMemoryCache(100), time.Minute, zap.NewNop())
+	filter := domain.SpecializationFilter{Limit: 10}
+
+	list := func() []domain.Specialization {
+		t.Helper()
+		items, _, err := s.List(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+
+	list()
+	list()
+	if repo.listCalls != 1 {
+		t.Fatalf("repository reads = %d, want the second list served from cache", repo.listCalls)
+	}
+
+	name := "Наследственное право"
+	if err := s.Update(ctx, 1, domain.UpdateSpecializationDTO{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if items := list(); len(items) != 1 || items[0].Name != name {
+		t.Errorf("list after update = %+v, want the new name", items)
+	}
+
+	if _, err := s.Create(ctx, domain.CreateSpecializationDTO{Name: "Налоги", Type: domain.SpecialistTypeLawyer}); err != nil {
+		t.Fatal(err)
+	}
+	if items := list(); len(items) != 2 {
+		t.Errorf("list after create has %d items, want 2", len(items))
+	}
+	if repo.listCalls != 3 {
+		t.Errorf("repository reads = %d, want one per write", repo.listCalls)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// Период сводки оценок звонков по умолчанию
+const defaultCallFeedbackPeriod = 7 * 24 * time.Hour
+
+type CallServiceImpl struct {
+	repo   repository.CallRepository
+	logger *zap.Logger
+}
+
+func NewCallService(repo repository.CallRepository, logger *zap.Logger) *CallServiceImpl {
+	return &CallServiceImpl{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// RecordCall сохраняет завершенный звонок, чтобы его участники могли оставить оценку
+func (s *CallServiceImpl) RecordCall(ctx context.Context, call domain.CallRecord) error {
+	ctx, span := tracer.Start(ctx, "CallService.RecordCall")
+	defer span.End()
+
+	if err := s.repo.SaveSession(ctx, call); err != nil {
+		s.logger.Error("ошибка сохранения звонка", zap.String("sessionID", call.ID), zap.Error(err))
+		return errors.New("ошибка при сохранении звонка")
+	}
+
+	return nil
+}
+
+// SubmitFeedback сохраняет оценку качества завершенного звонка от его участника.
+// Каждый участник оценивает звонок один раз, повторная оценка возвращает ErrConflict
+func (s *CallServiceImpl) SubmitFeedback(ctx context.Context, userID int64, sessionID string, dto domain.CallFeedbackDTO) (*domain.CallFeedback, error) {
+	ctx, span := tracer.Start(ctx, "CallService.SubmitFeedback")
+	defer span.End()
+
+	if dto.Quality < 1 || dto.Quality > 5 {
+		return nil, fmt.Errorf("%w: оценка должна быть от 1 до 5", ErrInvalid)
+	}
+
+	issues := make([]domain.CallIssue, 0, len(dto.Issues))
+	seen := make(map[domain.CallIssue]bool, len(dto.Issues))
+	for _, issue := range dto.Issues {
+		if !issue.IsValid() {
+			return nil, fmt.Errorf("%w: неизвестная проблема со связью %q", ErrInvalid, issue)
+		}
+		if !seen[issue] {
+			seen[issue] = true
+			issues = append(issues, issue)
+		}
+	}
+
+	var comment *string
+	if trimmed := strings.TrimSpace(dto.Comment); trimmed != "" {
+		if utf8.RuneCountInString(trimmed) > domain.MaxCallFeedbackCommentLength {
+			return nil, fmt.Errorf("%w: комментарий не должен превышать %d символов", ErrInvalid, domain.MaxCallFeedbackCommentLength)
+		}
+		comment = &trimmed
+	}
+
+	call, err := s.repo.GetSession(ctx, sessionID)
+	if errors.Is(err, repository.ErrCallSessionNotFound) {
+		return nil, fmt.Errorf("%w: завершенный звонок не найден", ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error("ошибка получения звонка", zap.String("sessionID", sessionID), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении оценки звонка")
+	}
+	if !call.HasParticipant(userID) {
+		return nil, fmt.Errorf("%w: оценить звонок может только его участник", ErrForbidden)
+	}
+	if !call.Answered {
+		return nil, fmt.Errorf("%w: звонок не был принят", ErrConflict)
+	}
+
+	feedback := &domain.CallFeedback{
+		CallSessionID: sessionID,
+		UserID:        userID,
+		Quality:       dto.Quality,
+		Issues:        issues,
+		Comment:       comment,
+	}
+
+	err = s.repo.CreateFeedback(ctx, feedback)
+	if errors.Is(err, repository.ErrCallFeedbackExists) {
+		return nil, fmt.Errorf("%w: вы уже оценили этот звонок", ErrConflict)
+	}
+	if err != nil {
+		s.logger.Error("ошибка сохранения оценки звонка", zap.String("sessionID", sessionID), zap.Int64("userID", userID), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении оценки звонка")
+	}
+
+	return feedback, nil
+}
+
+// GetFeedbackSummary возвращает сводку оценок звонков за [from, to). Без границ берется последняя неделя
+func (s *CallServiceImpl) GetFeedbackSummary(ctx context.Context, from, to *time.Time) (*domain.CallFeedbackSummary, error) {
+	ctx, span := tracer.Start(ctx, "CallService.GetFeedbackSummary")
+	defer span.End()
+
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+	start := end.Add(-defaultCallFeedbackPeriod)
+	if from != nil {
+		start = *from
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("%w: начало периода должно быть раньше конца", ErrInvalid)
+	}
+
+	summary, err := s.repo.GetFeedbackSummary(ctx, start, end)
+	if err != nil {
+		s.logger.Error("ошибка получения сводки оценок звонков", zap.Error(err))
+		return nil, errors.New("ошибка при получении сводки оценок звонков")
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+)
+
+// Как часто завершаются записи, консультация по которым состоялась по звонку
+const callCompletionInterval = 5 * time.Minute
+
+// RecordCallDuration прибавляет длительность отрезка звонка к записи.
+// Звонок может прерываться и возобновляться, поэтому длительность накапливается
+func (s *AppointmentServiceImpl) RecordCallDuration(ctx context.Context, appointmentID int64, seconds int) error {
+	ctx, span := tracer.Start(ctx, "AppointmentService.RecordCallDuration")
+	defer span.End()
+
+	if seconds <= 0 {
+		return nil
+	}
+
+	if err := s.repo.AddCallDuration(ctx, appointmentID, seconds); err != nil {
+		s.logger.Error("ошибка сохранения длительности звонка",
+			zap.Int64("appointmentID", appointmentID),
+			zap.Int("seconds", seconds),
+			zap.Error(err))
+		return errors.New("ошибка при сохранении длительности звонка")
+	}
+
+	return nil
+}
+
+// CompleteCalledAppointments переводит в completed оплаченные записи, окно звонка которых закрылось
+// и по которым звонок длился не меньше domain.MinCompletedCallDuration
+func (s *AppointmentServiceImpl) CompleteCalledAppointments(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.CompleteCalledAppointments")
+	defer span.End()
+
+	minDuration := int(domain.MinCompletedCallDuration / time.Second)
+	ids, err := s.repo.ListCompletableByCall(ctx, minDuration, time.Now().Add(-domain.CallWindowAfter))
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("ошибка получения записей для завершения", zap.Error(err))
+		}
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.repo.UpdateStatus(ctx, id, domain.AppointmentStatusCompleted); err != nil {
+			s.logger.Error("ошибка завершения записи после звонка", zap.Int64("appointmentID", id), zap.Error(err))
+			continue
+		}
+		s.logger.Info("запись завершена после звонка", zap.Int64("appointmentID", id))
+		s.scheduleReviewRequest(ctx, id)
+	}
+}
+
+// RunCallCompletion периодически завершает состоявшиеся по звонку консультации, пока не отменен ctx
+func (s *AppointmentServiceImpl) RunCallCompletion(ctx context.Context) {
+	ticker := time.NewTicker(callCompletionInterval)
+	defer ticker.Stop()
+
+	for {
+		s.CompleteCalledAppointments(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+type fakeCallRepo struct {
+	repository.CallRepository
+
+	calls    map[string]domain.CallRecord
+	feedback []domain.CallFeedback
+}
+
+func (r *fakeCallRepo) GetSession(ctx context.Context, id string) (*domain.CallRecord, error) {
+	call, ok := r.calls[id]
+	if !ok {
+		return nil, repository.ErrCallSessionNotFound
+	}
+	return &call, nil
+}
+
+func (r *fakeCallRepo) CreateFeedback(ctx context.Context, feedback *domain.CallFeedback) error {
+	r.feedback = append(r.feedback, *feedback)
+	return nil
+}
+
+func TestSubmitCallFeedback(t *testing.T) {
+	repo := &fakeCallRepo{calls: map[string]domain.CallRecord{
+		"answered": {ID: "answered", ClientID: 1, SpecialistUserID: 2, CallerID: 1, Answered: true, DurationSeconds: 60},
+		"missed":   {ID: "missed", ClientID: 1, SpecialistUserID: 2, CallerID: 1},
+	}}
+	calls := NewCallService(repo, zap.NewNop())
+
+	tests := []struct {
+		name      string
+		userID    int64
+		sessionID string
+		wantErr   error
+	}{
+		{name: "participant", userID: 2, sessionID: "answered"},
+		{name: "outsider", userID: 3, sessionID: "answered", wantErr: ErrForbidden},
+		{name: "unanswered call", userID: 1, sessionID: "missed", wantErr: ErrConflict},
+		{name: "unknown call", userID: 1, sessionID: "unknown", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := calls.SubmitFeedback(context.Background(), tt.userID, tt.sessionID, domain.CallFeedbackDTO{Quality: 4})
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(repo.feedback) != 1 {
+		t.Errorf("feedback = %+v, want only the participant's rating", repo.feedback)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"laps/internal/cache"
 	"laps/internal/domain"
 	"laps/internal/repository"
 )
@@ -15,14 +16,26 @@ type ChatServiceImpl struct {
 	appointmentRepo repository.AppointmentRepository
 	userRepo        repository.UserRepository
 	specialistRepo  repository.SpecialistRepository
+	blockListRepo   repository.BlockListRepository
+
+	// Response stats are expensive to compute, so they are cached per specialist
+	cache cache.Cache
 }
 
-func NewChatService(repos *repository.Repositories) *ChatServiceImpl {
+// responseStatsTTL defines how long computed response stats are served from cache
+const responseStatsTTL = 15 * time.Minute
+
+// minRespondedSessionsForSLA is the sample size required before an SLA bucket is shown
+const minRespondedSessionsForSLA = 3
+
+func NewChatService(repos *repository.Repositories, c cache.Cache) *ChatServiceImpl {
 	return &ChatServiceImpl{
 		chatRepo:        repos.Chat,
 		appointmentRepo: repos.Appointment,
 		userRepo:        repos.User,
 		specialistRepo:  repos.Specialist,
+		blockListRepo:   repos.BlockList,
+		cache:           c,
 	}
 }
 
@@ -63,10 +76,70 @@ func (s *ChatServiceImpl) CreateChatSession(ctx context.Context, dto domain.Crea
 		return existingSession, nil
 	}
 
+	// A consultation cannot be started for a cancelled or finished appointment.
+	// Sessions created at booking stay pending until the appointment is confirmed,
+	// so an immediately active session is only allowed while a call is allowed
+	if appointment.Status == domain.AppointmentStatusCancelled || appointment.Status == domain.AppointmentStatusCompleted {
+		return nil, &CallNotAllowedError{Reason: domain.CallNotAllowedStatus}
+	}
+	if dto.Status == domain.ChatSessionStatusActive {
+		if reason := appointment.CallNotAllowedReason(time.Now()); reason != "" {
+			return nil, &CallNotAllowedError{Reason: reason}
+		}
+	}
+
+	// A removed specialist cannot be reached through new conversations
+	specialist, err := s.specialistRepo.GetByIDWithDeleted(ctx, dto.SpecialistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment specialist: %w", err)
+	}
+	if specialist.DeletedAt != nil {
+		return nil, ErrSpecialistUnavailable
+	}
+
+	// A blocked client cannot start new conversations with the specialist
+	blocked, err := s.blockListRepo.IsBlocked(ctx, dto.SpecialistID, dto.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check block list: %w", err)
+	}
+	if blocked {
+		return nil, ErrClientBlocked
+	}
+
 	// Create new chat session
 	return s.chatRepo.CreateChatSession(ctx, dto)
 }
 
+// CreateChatSessionForUser creates (or returns the existing) chat session of an appointment
+// on behalf of userID, who must be the client or the specialist of the appointment.
+// The participants are always taken from the appointment; IDs in the DTO that point
+// to other users are rejected with ErrForbidden
+func (s *ChatServiceImpl) CreateChatSessionForUser(ctx context.Context, dto domain.CreateChatSessionDTO, userID int64) (*domain.ChatSession, error) {
+	appointment, err := s.appointmentRepo.GetByID(ctx, dto.AppointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: appointment not found", ErrNotFound)
+	}
+
+	specialist, err := s.specialistRepo.GetByIDWithDeleted(ctx, appointment.SpecialistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment specialist: %w", err)
+	}
+	if userID != appointment.ClientID && userID != specialist.UserID {
+		return nil, fmt.Errorf("%w: only appointment participants can create its chat", ErrForbidden)
+	}
+
+	if dto.ClientID != 0 && dto.ClientID != appointment.ClientID {
+		return nil, fmt.Errorf("%w: client ID does not match appointment", ErrForbidden)
+	}
+	if dto.SpecialistID != 0 && dto.SpecialistID != appointment.SpecialistID {
+		return nil, fmt.Errorf("%w: specialist ID does not match appointment", ErrForbidden)
+	}
+
+	dto.ClientID = appointment.ClientID
+	dto.SpecialistID = appointment.SpecialistID
+	return s.CreateChatSession(ctx, dto)
+}
+
 func (s *ChatServiceImpl) GetChatSessionByID(ctx context.Context, id int64, userID int64) (*domain.ChatSession, error) {
 	session, err := s.chatRepo.GetChatSessionByID(ctx, id)
 	if err != nil {
@@ -122,32 +195,27 @@ func (s *ChatServiceImpl) GetChatSessionByAppointmentID(ctx context.Context, app
 }
 
 func (s *ChatServiceImpl) ListChatSessions(ctx context.Context, userID int64, filter domain.ChatSessionFilter) ([]domain.ChatSession, int64, error) {
-	// Get user to determine role
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("user not found: %w", err)
 	}
 
-	// Set appropriate filter based on user role
-	if user.Role == domain.UserRoleClient {
-		filter.ClientID = &userID
-	} else if user.Role == domain.UserRoleSpecialist {
-		// For specialists, we need to find their specialist_id, not use their user_id
-		specialist, err := s.specialistRepo.GetByUserID(ctx, userID)
-		if err != nil {
-			return nil, 0, fmt.Errorf("specialist not found for user_id %d: %w", userID, err)
-		}
-		filter.SpecialistID = &specialist.ID
-	} else {
+	if user.Role != domain.UserRoleClient && user.Role != domain.UserRoleSpecialist {
 		return nil, 0, errors.New("invalid user role for chat access")
 	}
 
-	sessions, err := s.chatRepo.ListChatSessions(ctx, filter)
+	return s.ListChatSessionsByUserID(ctx, userID, filter)
+}
+
+// ListChatSessionsByUserID returns sessions the user takes part in, whether as a client
+// or as a specialist, without any role check
+func (s *ChatServiceImpl) ListChatSessionsByUserID(ctx context.Context, userID int64, filter domain.ChatSessionFilter) ([]domain.ChatSession, int64, error) {
+	sessions, err := s.chatRepo.ListChatSessionsByUserID(ctx, userID, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	count, err := s.chatRepo.CountChatSessions(ctx, filter)
+	count, err := s.chatRepo.CountChatSessionsByUserID(ctx, userID, filter)
 	if err != nil {
 		return sessions, 0, err
 	}
@@ -198,6 +266,31 @@ func (s *ChatServiceImpl) ArchiveChatSession(ctx context.Context, appointmentID
 	return err
 }
 
+// ArchiveChatSessionByID ends a chat session on behalf of its specialist or an admin.
+// Pending sessions have not started yet and cannot be archived; ended ones are left as is.
+func (s *ChatServiceImpl) ArchiveChatSessionByID(ctx context.Context, sessionID int64, userID int64, role domain.UserRole) error {
+	session, err := s.chatRepo.GetChatSessionByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("%w: chat session not found", ErrNotFound)
+	}
+
+	if role != domain.UserRoleAdmin {
+		specialist, err := s.specialistRepo.GetByUserID(ctx, userID)
+		if err != nil || specialist.ID != session.SpecialistID {
+			return fmt.Errorf("%w: only the session specialist can archive it", ErrForbidden)
+		}
+	}
+
+	switch session.Status {
+	case domain.ChatSessionStatusPending:
+		return fmt.Errorf("%w: chat session has not started yet", ErrConflict)
+	case domain.ChatSessionStatusEnded:
+		return nil
+	}
+
+	return s.ArchiveChatSession(ctx, session.AppointmentID)
+}
+
 // Chat Messages
 
 func (s *ChatServiceImpl) CreateChatMessage(ctx context.Context, dto domain.CreateChatMessageDTO, userID int64) (*domain.ChatMessage, error) {
@@ -232,6 +325,17 @@ func (s *ChatServiceImpl) CreateChatMessage(ctx context.Context, dto domain.Crea
 
 	// Auto-activate session if it's pending and this is the first message
 	if session.Status == domain.ChatSessionStatusPending {
+		// Only new conversations are blocked; already active sessions stay usable
+		if session.ClientID == userID {
+			blocked, err := s.blockListRepo.IsBlocked(ctx, session.SpecialistID, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check block list: %w", err)
+			}
+			if blocked {
+				return nil, ErrClientBlocked
+			}
+		}
+
 		now := time.Now()
 		updateDTO := domain.UpdateChatSessionDTO{
 			Status:    &[]domain.ChatSessionStatus{domain.ChatSessionStatusActive}[0],
@@ -246,6 +350,35 @@ func (s *ChatServiceImpl) CreateChatMessage(ctx context.Context, dto domain.Crea
 	return s.chatRepo.CreateChatMessage(ctx, dto)
 }
 
+// CreateSystemMessage posts a system message on behalf of senderID without
+// access checks; callers must have verified the sender. A pending session is
+// activated, since a system message marks the conversation as started
+func (s *ChatServiceImpl) CreateSystemMessage(ctx context.Context, sessionID, senderID int64, content string) (*domain.ChatMessage, error) {
+	session, err := s.chatRepo.GetChatSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status == domain.ChatSessionStatusPending {
+		now := time.Now()
+		status := domain.ChatSessionStatusActive
+		_, err = s.chatRepo.UpdateChatSession(ctx, session.ID, domain.UpdateChatSessionDTO{
+			Status:    &status,
+			StartedAt: &now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to activate chat session: %w", err)
+		}
+	}
+
+	return s.chatRepo.CreateChatMessage(ctx, domain.CreateChatMessageDTO{
+		SessionID: session.ID,
+		SenderID:  senderID,
+		Type:      domain.MessageTypeSystem,
+		Content:   content,
+	})
+}
+
 func (s *ChatServiceImpl) ListChatMessages(ctx context.Context, sessionID int64, userID int64, filter domain.ChatMessageFilter) ([]domain.ChatMessage, int64, error) {
 	// Verify user has access to the chat session
 	_, err := s.GetChatSessionByID(ctx, sessionID, userID)
@@ -261,6 +394,10 @@ func (s *ChatServiceImpl) ListChatMessages(ctx context.Context, sessionID int64,
 		return nil, 0, err
 	}
 
+	if err := s.attachReactions(ctx, messages); err != nil {
+		return nil, 0, err
+	}
+
 	count, err := s.chatRepo.CountChatMessages(ctx, filter)
 	if err != nil {
 		return messages, 0, err
@@ -269,6 +406,110 @@ func (s *ChatServiceImpl) ListChatMessages(ctx context.Context, sessionID int64,
 	return messages, count, nil
 }
 
+// attachReactions fills in reaction counts of the listed messages with a single query
+func (s *ChatServiceImpl) attachReactions(ctx context.Context, messages []domain.ChatMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+
+	counts, err := s.chatRepo.GetReactionCounts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		messages[i].Reactions = counts[messages[i].ID]
+	}
+	return nil
+}
+
+// Reactions
+
+// AddReaction adds the user's emoji reaction to a message of a session they
+// participate in and returns the updated reaction counts of the message.
+// Repeating a reaction the user has already left changes nothing
+func (s *ChatServiceImpl) AddReaction(ctx context.Context, messageID, userID int64, emoji string) (map[string]int, error) {
+	if !domain.IsSingleEmoji(emoji) {
+		return nil, fmt.Errorf("%w: reaction must be a single emoji", ErrInvalid)
+	}
+
+	if err := s.checkMessageAccess(ctx, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.chatRepo.AddReaction(ctx, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+
+	return s.reactionCounts(ctx, messageID)
+}
+
+// RemoveReaction removes the user's own reaction from a message and returns
+// the updated reaction counts of the message
+func (s *ChatServiceImpl) RemoveReaction(ctx context.Context, messageID, userID int64, emoji string) (map[string]int, error) {
+	if err := s.checkMessageAccess(ctx, messageID, userID); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.chatRepo.RemoveReaction(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, fmt.Errorf("%w: reaction not found", ErrNotFound)
+	}
+
+	return s.reactionCounts(ctx, messageID)
+}
+
+// checkMessageAccess verifies that the message exists and the user
+// participates in its chat session
+func (s *ChatServiceImpl) checkMessageAccess(ctx context.Context, messageID, userID int64) error {
+	message, err := s.chatRepo.GetChatMessageByID(ctx, messageID)
+	if errors.Is(err, repository.ErrChatMessageNotFound) {
+		return fmt.Errorf("%w: message not found", ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.GetChatSessionByID(ctx, message.SessionID, userID); err != nil {
+		return fmt.Errorf("%w: access denied to chat session", ErrForbidden)
+	}
+	return nil
+}
+
+func (s *ChatServiceImpl) reactionCounts(ctx context.Context, messageID int64) (map[string]int, error) {
+	counts, err := s.chatRepo.GetReactionCounts(ctx, []int64{messageID})
+	if err != nil {
+		return nil, err
+	}
+	if counts[messageID] == nil {
+		return map[string]int{}, nil
+	}
+	return counts[messageID], nil
+}
+
+// GetChatSessionForTranscript checks that the user may export the session:
+// participants of the chat and admins are allowed
+func (s *ChatServiceImpl) GetChatSessionForTranscript(ctx context.Context, sessionID int64, userID int64, role domain.UserRole) (*domain.ChatSession, error) {
+	if role == domain.UserRoleAdmin {
+		return s.chatRepo.GetChatSessionByID(ctx, sessionID)
+	}
+
+	return s.GetChatSessionByID(ctx, sessionID, userID)
+}
+
+// StreamChatMessages passes every message of the session to fn, oldest first.
+// Access must be checked by the caller beforehand.
+func (s *ChatServiceImpl) StreamChatMessages(ctx context.Context, sessionID int64, fn func(message domain.ChatMessage) error) error {
+	return s.chatRepo.StreamChatMessages(ctx, sessionID, fn)
+}
+
 func (s *ChatServiceImpl) MarkMessagesAsRead(ctx context.Context, sessionID int64, userID int64) error {
 	// Verify user has access to the chat session
 	_, err := s.GetChatSessionByID(ctx, sessionID, userID)
@@ -344,4 +585,60 @@ func getOtherPartyName(session *domain.ChatSession, userID int64) *string {
 		return session.SpecialistName
 	}
 	return session.ClientName
-}
\ No newline at end of file
+}
+
+// Statistics
+
+func (s *ChatServiceImpl) GetSpecialistResponseStats(ctx context.Context, specialistID int64) (*domain.SpecialistResponseStats, error) {
+	// A cache failure only costs a recalculation, so it is not reported
+	cacheKey := responseStatsCacheKey(specialistID)
+	var cached domain.SpecialistResponseStats
+	if found, err := s.cache.Get(ctx, cacheKey, &cached); err == nil && found {
+		return &cached, nil
+	}
+
+	stats, err := s.chatRepo.GetSpecialistResponseStats(ctx, specialistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate response stats: %w", err)
+	}
+
+	if stats.MedianFirstResponseSeconds != nil && stats.RespondedSessions >= minRespondedSessionsForSLA {
+		stats.RespondsWithin = respondsWithinBucket(time.Duration(*stats.MedianFirstResponseSeconds * float64(time.Second)))
+	}
+
+	_ = s.cache.Set(ctx, cacheKey, stats, responseStatsTTL)
+
+	return stats, nil
+}
+
+// GetSessionStats returns message counts, duration and average response time of a chat session
+// the user participates in
+func (s *ChatServiceImpl) GetSessionStats(ctx context.Context, sessionID int64, userID int64) (*domain.ChatSessionStats, error) {
+	// Verify user has access to the chat session
+	if _, err := s.GetChatSessionByID(ctx, sessionID, userID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	stats, err := s.chatRepo.GetSessionStats(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat session stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Helper function to map a median response time onto a coarse SLA bucket
+func respondsWithinBucket(median time.Duration) string {
+	switch {
+	case median <= 15*time.Minute:
+		return "15m"
+	case median <= time.Hour:
+		return "1h"
+	case median <= 3*time.Hour:
+		return "3h"
+	case median <= 24*time.Hour:
+		return "24h"
+	default:
+		return "over_24h"
+	}
+}
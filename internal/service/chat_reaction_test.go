@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// fakeReactionRepo keeps one chat session with message 1 and stores reactions
+// by message, user and emoji the way the unique key of the table does
+type fakeReactionRepo struct {
+	repository.ChatRepository
+
+	session   domain.ChatSession
+	reactions map[int64]map[int64]map[string]bool
+}
+
+func newFakeReactionRepo() *fakeReactionRepo {
+	return &fakeReactionRepo{
+		session:   domain.ChatSession{ID: 5, ClientID: 10, SpecialistID: 7},
+		reactions: make(map[int64]map[int64]map[string]bool),
+	}
+}
+
+func (r *fakeReactionRepo) GetChatSessionByID(ctx context.Context, id int64) (*domain.ChatSession, error) {
+	if id != r.session.ID {
+		return nil, errors.New("chat session not found")
+	}
+	session := r.session
+	return &session, nil
+}
+
+func (r *fakeReactionRepo) GetChatMessageByID(ctx context.Context, id int64) (*domain.ChatMessage, error) {
+	if id != 1 {
+		return nil, repository.ErrChatMessageNotFound
+	}
+	return &domain.ChatMessage{ID: 1, SessionID: r.session.ID}, nil
+}
+
+func (r *fakeReactionRepo) AddReaction(ctx context.Context, messageID, userID int64, emoji string) error {
+	if r.reactions[messageID] == nil {
+		r.reactions[messageID] = make(map[int64]map[string]bool)
+	}
+	if r.reactions[messageID][userID] == nil {
+		r.reactions[messageID][userID] = make(map[string]bool)
+	}
+	r.reactions[messageID][userID][emoji] = true
+	return nil
+}
+
+func (r *fakeReactionRepo) RemoveReaction(ctx context.Context, messageID, userID int64, emoji string) (bool, error) {
+	if !r.reactions[messageID][userID][emoji] {
+		return false, nil
+	}
+	delete(r.reactions[messageID][userID], emoji)
+	return true, nil
+}
+
+func (r *fakeReactionRepo) GetReactionCounts(ctx context.Context, messageIDs []int64) (map[int64]map[string]int, error) {
+	counts := make(map[int64]map[string]int)
+	for _, id := range messageIDs {
+		for _, emojis := range r.reactions[id] {
+			for emoji := range emojis {
+				if counts[id] == nil {
+					counts[id] = make(map[string]int)
+				}
+				counts[id][emoji]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func newReactionTestService() *ChatServiceImpl {
+	return NewChatService(&repository.Repositories{
+		Chat:       newFakeReactionRepo(),
+		Specialist: &fakeSpecialistRepo{specialist: &domain.Specialist{ID: 7, UserID: 70}},
+	}, cache.NewMemoryCache(10))
+}
+
+func TestAddReaction(t *testing.T) {
+	chat := newReactionTestService()
+	ctx := context.Background()
+
+	for _, userID := range []int64{10, 70, 70} {
+		if _, err := chat.AddReaction(ctx, 1, userID, "👍"); err != nil {
+			t.Fatalf("AddReaction(user %d): %v", userID, err)
+		}
+	}
+	counts, err := chat.AddReaction(ctx, 1, 10, "❤️")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 || counts["👍"] != 2 || counts["❤️"] != 1 {
+		t.Errorf("counts = %v, want 👍 from both participants once and one ❤️", counts)
+	}
+
+	tests := []struct {
+		name      string
+		messageID int64
+		userID    int64
+		emoji     string
+		wantErr   error
+	}{
+		{name: "text", messageID: 1, userID: 10, emoji: "ok", wantErr: ErrInvalid},
+		{name: "two emoji", messageID: 1, userID: 10, emoji: "👍👍", wantErr: ErrInvalid},
+		{name: "outsider", messageID: 1, userID: 11, emoji: "👍", wantErr: ErrForbidden},
+		{name: "unknown message", messageID: 2, userID: 10, emoji: "👍", wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := chat.AddReaction(ctx, tt.messageID, tt.userID, tt.emoji); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRemoveReaction(t *testing.T) {
+	chat := newReactionTestService()
+	ctx := context.Background()
+	for _, userID := range []int64{10, 70} {
+		if _, err := chat.AddReaction(ctx, 1, userID, "👍"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := chat.RemoveReaction(ctx, 1, 10, "👍")
+	if err != nil || counts["👍"] != 1 {
+		t.Fatalf("RemoveReaction() = %v, %v; want the specialist's 👍 left", counts, err)
+	}
+	if _, err := chat.RemoveReaction(ctx, 1, 10, "👍"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("removing twice: err = %v, want ErrNotFound", err)
+	}
+
+	counts, err = chat.RemoveReaction(ctx, 1, 70, "👍")
+	if err != nil || counts == nil || len(counts) != 0 {
+		t.Errorf("RemoveReaction() = %v, %v; want empty counts", counts, err)
+	}
+}
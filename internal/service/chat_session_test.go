@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// fakeChatRepo stores sessions by appointment; the service looks up an existing session before creating one
+type fakeChatRepo struct {
+	repository.ChatRepository
+
+	sessions map[int64]*domain.ChatSession
+}
+
+func (r *fakeChatRepo) GetChatSessionByAppointmentID(ctx context.Context, appointmentID int64) (*domain.ChatSession, error) {
+	session, ok := r.sessions[appointmentID]
+	if !ok {
+		return nil, errors.New("chat session not found")
+	}
+	return session, nil
+}
+
+func (r *fakeChatRepo) CreateChatSession(ctx context.Context, dto domain.CreateChatSessionDTO) (*domain.ChatSession, error) {
+	session := &domain.ChatSession{ID: int64(len(r.sessions) + 1), AppointmentID: dto.AppointmentID,
+		ClientID: dto.ClientID, SpecialistID: dto.SpecialistID, Status: dto.Status}
+	r.sessions[dto.AppointmentID] = session
+	return session, nil
+}
+
+func TestConfirmAppointmentCreatesChat(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7,
+		AppointmentDate: tomorrowAt(10, 0), Status: domain.AppointmentStatusPending}
+	paid := domain.AppointmentStatusPaid
+
+	first, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{Status: &paid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == nil || first.ChatSessionID == 0 {
+		t.Fatalf("confirmation = %+v, want a chat session ID", first)
+	}
+	session := f.chat.sessions[1]
+	if session.ClientID != 10 || session.SpecialistID != 7 || session.Status != domain.ChatSessionStatusPending {
+		t.Errorf("session = %+v, want a pending chat between the appointment participants", session)
+	}
+
+	second, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{Status: &paid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == nil || second.ChatSessionID != first.ChatSessionID || len(f.chat.sessions) != 1 {
+		t.Errorf("repeated confirmation = %+v, want the same chat %d", second, first.ChatSessionID)
+	}
+}
+
+func TestConfirmAppointmentChatFailure(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7,
+		AppointmentDate: tomorrowAt(10, 0), Status: domain.AppointmentStatusPending}
+	f.chat.createErr = errors.New("connection reset")
+	paid := domain.AppointmentStatusPaid
+
+	// The appointment is already confirmed, so a failed chat must not fail the update
+	confirmation, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{Status: &paid})
+	if err != nil || confirmation != nil {
+		t.Errorf("Update() = %+v, %v; want no confirmation and no error", confirmation, err)
+	}
+}
+
+func TestUpdateWithoutConfirmationSkipsChat(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7,
+		AppointmentDate: tomorrowAt(10, 0), Status: domain.AppointmentStatusPending}
+	cancelled := domain.AppointmentStatusCancelled
+
+	confirmation, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{Status: &cancelled})
+	if err != nil || confirmation != nil || len(f.chat.sessions) != 0 {
+		t.Errorf("Update() = %+v, %v, sessions = %d; want no chat", confirmation, err, len(f.chat.sessions))
+	}
+}
+
+func TestCreateChatSessionForUser(t *testing.T) {
+	specializationID := int64(3)
+	appointments := newFakeAppointmentRepo()
+	appointments.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7, SpecializationID: &specializationID,
+		AppointmentDate: tomorrowAt(10, 0), Status: domain.AppointmentStatusPaid}
+
+	tests := []struct {
+		name    string
+		userID  int64
+		dto     domain.CreateChatSessionDTO
+		wantErr error
+	}{
+		{name: "client without participant IDs", userID: 10, dto: domain.CreateChatSessionDTO{AppointmentID: 1}},
+		{name: "specialist with matching IDs", userID: 70, dto: domain.CreateChatSessionDTO{AppointmentID: 1, ClientID: 10, SpecialistID: 7}},
+		{name: "outsider", userID: 11, dto: domain.CreateChatSessionDTO{AppointmentID: 1}, wantErr: ErrForbidden},
+		{name: "client names another client", userID: 10, dto: domain.CreateChatSessionDTO{AppointmentID: 1, ClientID: 11}, wantErr: ErrForbidden},
+		{name: "client names another specialist", userID: 10, dto: domain.CreateChatSessionDTO{AppointmentID: 1, SpecialistID: 8}, wantErr: ErrForbidden},
+		{name: "unknown appointment", userID: 10, dto: domain.CreateChatSessionDTO{AppointmentID: 2}, wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chats := &fakeChatRepo{sessions: make(map[int64]*domain.ChatSession)}
+			chat := NewChatService(&repository.Repositories{
+				Chat:        chats,
+				Appointment: appointments,
+				Specialist:  &fakeSpecialistRepo{specialist: &domain.Specialist{ID: 7, UserID: 70}},
+				BlockList:   &fakeBlockListRepo{},
+			}, cache.NewMemoryCache(10))
+
+			session, err := chat.CreateChatSessionForUser(context.Background(), tt.dto, tt.userID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || len(chats.sessions) != 0 {
+					t.Errorf("CreateChatSessionForUser() = %+v, %v; want %v", session, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if session.ClientID != 10 || session.SpecialistID != 7 {
+				t.Errorf("session = %+v, want participants taken from the appointment", session)
+			}
+		})
+	}
+}
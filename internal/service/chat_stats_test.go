@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// fakeResponseStatsRepo counts stats calculations to show when the cache is used
+type fakeResponseStatsRepo struct {
+	repository.ChatRepository
+
+	stats        domain.SpecialistResponseStats
+	calculations int
+}
+
+func (r *fakeResponseStatsRepo) GetSpecialistResponseStats(ctx context.Context, specialistID int64) (*domain.SpecialistResponseStats, error) {
+	r.calculations++
+	stats := r.stats
+	stats.SpecialistID = specialistID
+	return &stats, nil
+}
+
+func TestSpecialistResponseStatsCached(t *testing.T) {
+	median, pickupRate := 600.0, 0.75
+	chats := &fakeResponseStatsRepo{stats: domain.SpecialistResponseStats{
+		TotalSessions: 5, RespondedSessions: 4, MedianFirstResponseSeconds: &median, CallPickupRate: &pickupRate,
+	}}
+	chat := NewChatService(&repository.Repositories{Chat: chats}, cache.NewMemoryCache(10))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		stats, err := chat.GetSpecialistResponseStats(ctx, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.SpecialistID != 7 || stats.RespondsWithin != "15m" || stats.CallPickupRate == nil || *stats.CallPickupRate != 0.75 {
+			t.Errorf("stats = %+v, want the 15m bucket and the pickup rate", stats)
+		}
+	}
+	if chats.calculations != 1 {
+		t.Errorf("calculations = %d, want the second request served from cache", chats.calculations)
+	}
+
+	if _, err := chat.GetSpecialistResponseStats(ctx, 8); err != nil {
+		t.Fatal(err)
+	}
+	if chats.calculations != 2 {
+		t.Errorf("calculations = %d, want stats cached per specialist", chats.calculations)
+	}
+}
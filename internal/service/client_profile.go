@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+type ClientProfileServiceImpl struct {
+	repo            repository.ClientProfileRepository
+	userRepo        repository.UserRepository
+	specialistRepo  repository.SpecialistRepository
+	appointmentRepo repository.AppointmentRepository
+	logger          *zap.Logger
+}
+
+func NewClientProfileService(
+	repo repository.ClientProfileRepository,
+	userRepo repository.UserRepository,
+	specialistRepo repository.SpecialistRepository,
+	appointmentRepo repository.AppointmentRepository,
+	logger *zap.Logger,
+) *ClientProfileServiceImpl {
+	return &ClientProfileServiceImpl{
+		repo:            repo,
+		userRepo:        userRepo,
+		specialistRepo:  specialistRepo,
+		appointmentRepo: appointmentRepo,
+		logger:          logger,
+	}
+}
+
+// Get возвращает профиль клиента; если он еще не заполнен, возвращается профиль с пустыми полями
+func (s *ClientProfileServiceImpl) Get(ctx context.Context, userID int64) (*domain.ClientProfile, error) {
+	profile, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		s.logger.Error("ошибка получения профиля клиента", zap.Int64("userID", userID), zap.Error(err))
+		return nil, errors.New("ошибка при получении профиля клиента")
+	}
+
+	if profile == nil {
+		profile = &domain.ClientProfile{UserID: userID}
+	}
+
+	return profile, nil
+}
+
+func (s *ClientProfileServiceImpl) Update(ctx context.Context, userID int64, dto domain.UpdateClientProfileDTO) (*domain.ClientProfile, error) {
+	profile := domain.ClientProfile{
+		UserID:                 userID,
+		Gender:                 dto.Gender,
+		PreferredContactMethod: dto.PreferredContactMethod,
+	}
+
+	if dto.BirthDate != nil && *dto.BirthDate != "" {
+		birthDate, err := validateBirthDate(*dto.BirthDate, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		profile.BirthDate = &birthDate
+	}
+
+	if dto.City != nil {
+		if city := strings.TrimSpace(*dto.City); city != "" {
+			profile.City = &city
+		}
+	}
+
+	if err := s.repo.Upsert(ctx, profile); err != nil {
+		s.logger.Error("ошибка сохранения профиля клиента", zap.Int64("userID", userID), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении профиля клиента")
+	}
+
+	return s.Get(ctx, userID)
+}
+
+// GetForViewer возвращает профиль клиента другому пользователю. Специалист видит профиль только тех клиентов,
+// у которых есть хотя бы одна неотмененная запись к нему; администратор видит любой профиль
+func (s *ClientProfileServiceImpl) GetForViewer(ctx context.Context, clientID, viewerID int64, viewerRole domain.UserRole) (*domain.ClientProfile, error) {
+	if viewerID != clientID && viewerRole != domain.UserRoleAdmin {
+		if viewerRole != domain.UserRoleSpecialist {
+			return nil, ErrForbidden
+		}
+
+		specialist, err := s.specialistRepo.GetByUserID(ctx, viewerID)
+		if err != nil {
+			s.logger.Warn("профиль специалиста не найден при запросе профиля клиента", zap.Int64("userID", viewerID), zap.Error(err))
+			return nil, ErrForbidden
+		}
+
+		related, err := s.appointmentRepo.HasActiveAppointment(ctx, specialist.ID, clientID)
+		if err != nil {
+			s.logger.Error("ошибка проверки записей клиента", zap.Int64("clientID", clientID), zap.Error(err))
+			return nil, errors.New("ошибка при получении профиля клиента")
+		}
+		if !related {
+			return nil, ErrForbidden
+		}
+	}
+
+	user, err := s.userRepo.GetByID(ctx, clientID)
+	if err != nil || user.Role != domain.UserRoleClient {
+		return nil, fmt.Errorf("%w: клиент не найден", ErrNotFound)
+	}
+
+	return s.Get(ctx, clientID)
+}
+
+// validateBirthDate проверяет формат YYYY-MM-DD и что возраст на дату now в пределах допустимого
+func validateBirthDate(value string, now time.Time) (string, error) {
+	birthDate, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return "", fmt.Errorf("%w: дата рождения должна быть в формате YYYY-MM-DD", ErrInvalid)
+	}
+
+	age := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		age--
+	}
+
+	if age < domain.ClientMinAge || age > domain.ClientMaxAge {
+		return "", fmt.Errorf("%w: возраст должен быть от %d до %d лет", ErrInvalid, domain.ClientMinAge, domain.ClientMaxAge)
+	}
+
+	return birthDate.Format("2006-01-02"), nil
+}
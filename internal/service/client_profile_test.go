@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// fakeClientProfileRepo хранит профили в памяти, незаполненный профиль возвращается как nil
+type fakeClientProfileRepo struct {
+	repository.ClientProfileRepository
+
+	profiles map[int64]domain.ClientProfile
+}
+
+func (r *fakeClientProfileRepo) Get(ctx context.Context, userID int64) (*domain.ClientProfile, error) {
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &profile, nil
+}
+
+func (r *fakeClientProfileRepo) Upsert(ctx context.Context, profile domain.ClientProfile) error {
+	r.profiles[profile.UserID] = profile
+	return nil
+}
+
+func TestClientProfileGetForViewer(t *testing.T) {
+	const (
+		clientID          = int64(10)
+		specialistUserID  = int64(70)
+		specialistID      = int64(7)
+		otherSpecialistID = int64(8)
+		otherClientID     = int64(11)
+		cancelledClientID = int64(12)
+	)
+
+	appointments := newFakeAppointmentRepo()
+	appointments.appointments[1] = &domain.Appointment{ID: 1, ClientID: clientID, SpecialistID: specialistID, Status: domain.AppointmentStatusCompleted}
+	appointments.appointments[2] = &domain.Appointment{ID: 2, ClientID: otherClientID, SpecialistID: otherSpecialistID, Status: domain.AppointmentStatusPaid}
+	appointments.appointments[3] = &domain.Appointment{ID: 3, ClientID: cancelledClientID, SpecialistID: specialistID, Status: domain.AppointmentStatusCancelled}
+
+	city := "Казань"
+	profiles := &fakeClientProfileRepo{profiles: map[int64]domain.ClientProfile{
+		clientID: {UserID: clientID, City: &city},
+	}}
+	clients := NewClientProfileService(profiles, &fakeUserRepo{role: domain.UserRoleClient},
+		&fakeSpecialistRepo{specialist: &domain.Specialist{ID: specialistID, UserID: specialistUserID}}, appointments, zap.NewNop())
+
+	tests := []struct {
+		name     string
+		clientID int64
+		viewerID int64
+		role     domain.UserRole
+		wantErr  error
+	}{
+		{name: "own profile", clientID: clientID, viewerID: clientID, role: domain.UserRoleClient},
+		{name: "admin", clientID: otherClientID, viewerID: 1, role: domain.UserRoleAdmin},
+		{name: "specialist with an appointment", clientID: clientID, viewerID: specialistUserID, role: domain.UserRoleSpecialist},
+		{name: "specialist of another client", clientID: otherClientID, viewerID: specialistUserID, role: domain.UserRoleSpecialist, wantErr: ErrForbidden},
+		{name: "only cancelled appointments", clientID: cancelledClientID, viewerID: specialistUserID, role: domain.UserRoleSpecialist, wantErr: ErrForbidden},
+		{name: "another client", clientID: clientID, viewerID: otherClientID, role: domain.UserRoleClient, wantErr: ErrForbidden},
+		{name: "user without a specialist profile", clientID: clientID, viewerID: 99, role: domain.UserRoleSpecialist, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := clients.GetForViewer(context.Background(), tt.clientID, tt.viewerID, tt.role)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || profile != nil {
+					t.Errorf("GetForViewer() = %+v, %v; want %v", profile, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || profile == nil || profile.UserID != tt.clientID {
+				t.Errorf("GetForViewer() = %+v, %v", profile, err)
+			}
+		})
+	}
+}
+
+func TestClientProfileGetForViewerRejectsNonClients(t *testing.T) {
+	profiles := &fakeClientProfileRepo{profiles: map[int64]domain.ClientProfile{}}
+	clients := NewClientProfileService(profiles, &fakeUserRepo{role: domain.UserRoleSpecialist}, &fakeSpecialistRepo{},
+		newFakeAppointmentRepo(), zap.NewNop())
+
+	if _, err := clients.GetForViewer(context.Background(), 70, 1, domain.UserRoleAdmin); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound for a specialist account", err)
+	}
+}
+
+func TestClientProfileUpdateReplacesFields(t *testing.T) {
+	city := "Казань"
+	profiles := &fakeClientProfileRepo{profiles: map[int64]domain.ClientProfile{
+		10: {UserID: 10, City: &city},
+	}}
+	clients := NewClientProfileService(profiles, &fakeUserRepo{}, &fakeSpecialistRepo{}, newFakeAppointmentRepo(), zap.NewNop())
+
+	birthDate := "1990-05-01"
+	blank := "   "
+	profile, err := clients.Update(context.Background(), 10, domain.UpdateClientProfileDTO{BirthDate: &birthDate, City: &blank})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.BirthDate == nil || *profile.BirthDate != birthDate {
+		t.Errorf("birth date = %v, want %s", profile.BirthDate, birthDate)
+	}
+	if profile.City != nil {
+		t.Errorf("city = %q, want it cleared", *profile.City)
+	}
+}
+
+func TestValidateBirthDate(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "1990-05-01"},
+		// Шестнадцать лет исполняется ровно сегодня
+		{value: "2010-03-15"},
+		{value: "2010-03-16", wantErr: true},
+		{value: "1906-03-15"},
+		{value: "1905-03-14", wantErr: true},
+		{value: "15.03.1990", wantErr: true},
+		{value: "1990-02-30", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := validateBirthDate(tt.value, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("validateBirthDate() = %q, %v; want ErrInvalid", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.value {
+				t.Errorf("validateBirthDate() = %q, %v", got, err)
+			}
+		})
+	}
+}
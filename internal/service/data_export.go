@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// Количество записей каждого раздела, читаемых из базы за один запрос при выгрузке данных пользователя
+const dataExportBatchSize = 200
+
+type DataExportServiceImpl struct {
+	userRepo        repository.UserRepository
+	specialistRepo  repository.SpecialistRepository
+	appointmentRepo repository.AppointmentRepository
+	reviewRepo      repository.ReviewRepository
+	chatRepo        repository.ChatRepository
+	auditRepo       repository.AuditRepository
+	logger          *zap.Logger
+}
+
+func NewDataExportService(
+	userRepo repository.UserRepository,
+	specialistRepo repository.SpecialistRepository,
+	appointmentRepo repository.AppointmentRepository,
+	reviewRepo repository.ReviewRepository,
+	chatRepo repository.ChatRepository,
+	auditRepo repository.AuditRepository,
+	logger *zap.Logger,
+) *DataExportServiceImpl {
+	return &DataExportServiceImpl{
+		userRepo:        userRepo,
+		specialistRepo:  specialistRepo,
+		appointmentRepo: appointmentRepo,
+		reviewRepo:      reviewRepo,
+		chatRepo:        chatRepo,
+		auditRepo:       auditRepo,
+		logger:          logger,
+	}
+}
+
+// ExportUserData пишет в w все данные пользователя одним JSON-объектом: профиль, профиль специалиста,
+// записи, отзывы и чаты с сообщениями. Разделы читаются из базы порциями и сразу отправляются клиенту,
+// поэтому при ошибке после начала записи документ обрывается. actorID — пользователь, запросивший выгрузку
+func (s *DataExportServiceImpl) ExportUserData(ctx context.Context, w io.Writer, userID, actorID int64) error {
+	ctx, span := tracer.Start(ctx, "DataExportService.ExportUserData")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("пользователь не найден при выгрузке данных", zap.Int64("userID", userID), zap.Error(err))
+		return fmt.Errorf("%w: пользователь не найден", ErrNotFound)
+	}
+
+	var specialist *domain.Specialist
+	if user.Role == domain.UserRoleSpecialist {
+		specialist, err = s.specialistRepo.GetByUserID(ctx, userID)
+		if err != nil {
+			// Профиль специалиста может быть еще не создан
+			s.logger.Warn("профиль специалиста не найден при выгрузке данных", zap.Int64("userID", userID), zap.Error(err))
+			specialist = nil
+		}
+	}
+
+	err = s.auditRepo.Log(ctx, domain.AuditEntry{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionUserDataExport,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   userID,
+	})
+	if err != nil {
+		s.logger.Error("ошибка записи выгрузки данных в журнал аудита", zap.Int64("userID", userID), zap.Error(err))
+		return errors.New("ошибка при выгрузке данных")
+	}
+
+	out := newJSONStreamWriter(w)
+	out.raw(`{"generated_at":`)
+	out.value(time.Now())
+	out.raw(`,"user":`)
+	out.value(user)
+	out.raw(`,"specialist":`)
+	out.value(specialist)
+
+	out.raw(`,"appointments":`)
+	s.writeAppointments(ctx, out, domain.AppointmentFilter{ClientID: &userID})
+	if specialist != nil {
+		out.raw(`,"specialist_appointments":`)
+		s.writeAppointments(ctx, out, domain.AppointmentFilter{SpecialistID: &specialist.ID})
+	}
+
+	out.raw(`,"reviews":`)
+	s.writeReviews(ctx, out, userID)
+
+	out.raw(`,"chat_sessions":`)
+	s.writeChatSessions(ctx, out, userID)
+	out.raw("}\n")
+
+	if err := out.flush(); err != nil {
+		s.logger.Error("ошибка выгрузки данных пользователя", zap.Int64("userID", userID), zap.Error(err))
+		return errors.New("ошибка при выгрузке данных")
+	}
+
+	return nil
+}
+
+func (s *DataExportServiceImpl) writeAppointments(ctx context.Context, out *jsonStreamWriter, filter domain.AppointmentFilter) {
+	filter.Limit = dataExportBatchSize
+	out.array(func() (int, error) {
+		batch, err := s.appointmentRepo.List(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		for _, appointment := range batch {
+			out.element(appointment)
+		}
+		filter.Offset += len(batch)
+		return len(batch), nil
+	})
+}
+
+func (s *DataExportServiceImpl) writeReviews(ctx context.Context, out *jsonStreamWriter, userID int64) {
+	offset := 0
+	out.array(func() (int, error) {
+		batch, err := s.reviewRepo.GetByUserID(ctx, userID, dataExportBatchSize, offset)
+		if err != nil {
+			return 0, err
+		}
+		for _, review := range batch {
+			out.element(review)
+		}
+		offset += len(batch)
+		return len(batch), nil
+	})
+}
+
+// writeChatSessions выгружает чаты, в которых пользователь участвует как клиент или как специалист,
+// вместе с полной историей сообщений каждого чата
+func (s *DataExportServiceImpl) writeChatSessions(ctx context.Context, out *jsonStreamWriter, userID int64) {
+	filter := domain.ChatSessionFilter{Limit: dataExportBatchSize}
+	out.array(func() (int, error) {
+		batch, err := s.chatRepo.ListChatSessionsByUserID(ctx, userID, filter)
+		if err != nil {
+			return 0, err
+		}
+		for _, session := range batch {
+			out.startElement()
+			out.raw(`{"session":`)
+			out.value(session)
+			out.raw(`,"messages":`)
+			out.array(func() (int, error) {
+				err := s.chatRepo.StreamChatMessages(ctx, session.ID, func(message domain.ChatMessage) error {
+					out.element(message)
+					return out.err
+				})
+				return 0, err
+			})
+			out.raw("}")
+		}
+		filter.Offset += len(batch)
+		return len(batch), nil
+	})
+}
+
+// jsonStreamWriter собирает JSON-документ по частям, не держа его целиком в памяти.
+// Первая ошибка запоминается, и все последующие записи пропускаются
+type jsonStreamWriter struct {
+	w     *bufio.Writer
+	enc   *json.Encoder
+	err   error
+	first []bool
+}
+
+func newJSONStreamWriter(w io.Writer) *jsonStreamWriter {
+	bw := bufio.NewWriter(w)
+	return &jsonStreamWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (j *jsonStreamWriter) raw(s string) {
+	if j.err != nil {
+		return
+	}
+	_, j.err = j.w.WriteString(s)
+}
+
+func (j *jsonStreamWriter) value(v interface{}) {
+	if j.err != nil {
+		return
+	}
+	j.err = j.enc.Encode(v)
+}
+
+// array пишет JSON-массив, вызывая next до тех пор, пока он возвращает полную порцию
+func (j *jsonStreamWriter) array(next func() (int, error)) {
+	j.raw("[")
+	j.first = append(j.first, true)
+	for j.err == nil {
+		n, err := next()
+		if err != nil && j.err == nil {
+			j.err = err
+		}
+		if n < dataExportBatchSize {
+			break
+		}
+		// Сбрасываем каждую порцию, чтобы клиент получал файл по мере чтения базы
+		if j.err == nil {
+			j.err = j.w.Flush()
+		}
+	}
+	j.first = j.first[:len(j.first)-1]
+	j.raw("]")
+}
+
+// startElement ставит разделитель перед очередным элементом текущего массива
+func (j *jsonStreamWriter) startElement() {
+	top := len(j.first) - 1
+	if !j.first[top] {
+		j.raw(",")
+	}
+	j.first[top] = false
+}
+
+func (j *jsonStreamWriter) element(v interface{}) {
+	j.startElement()
+	j.value(v)
+}
+
+func (j *jsonStreamWriter) flush() error {
+	if j.err != nil {
+		return j.err
+	}
+	return j.w.Flush()
+}
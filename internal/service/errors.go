@@ -0,0 +1,37 @@
+package service
+
+import "errors"
+
+// CallNotAllowedError звонок или чат по записи недоступен; Reason — одна из причин domain.CallNotAllowed*
+type CallNotAllowedError struct {
+	Reason string
+}
+
+func (e *CallNotAllowedError) Error() string {
+	return "звонок по записи недоступен: " + e.Reason
+}
+
+var (
+	// ErrInvalid оборачивает ошибки валидации входных данных, текст которых можно отдать клиенту
+	ErrInvalid = errors.New("некорректные данные")
+	// ErrConflict оборачивает ошибки уникальности (например, занятый email), текст которых можно отдать клиенту
+	ErrConflict = errors.New("конфликт данных")
+	// ErrClientBlocked специалист заблокировал клиента; причина блокировки клиенту не сообщается
+	ErrClientBlocked = errors.New("запись к специалисту недоступна")
+	// ErrNotAcceptingClients специалист не принимает новых клиентов, а у клиента еще не было записей к нему
+	ErrNotAcceptingClients = errors.New("специалист не принимает новых клиентов")
+	// ErrSpecialistUnavailable профиль специалиста удален: записаться, начать чат или позвонить ему нельзя
+	ErrSpecialistUnavailable = errors.New("профиль специалиста удален")
+	// ErrNotFound запрошенная сущность не найдена
+	ErrNotFound = errors.New("не найдено")
+	// ErrForbidden у пользователя нет доступа к запрошенным данным
+	ErrForbidden = errors.New("доступ запрещен")
+	// ErrLimitExceeded превышен лимит на количество объектов пользователя, текст можно отдать клиенту
+	ErrLimitExceeded = errors.New("превышен лимит")
+	// ErrVersionConflict данные изменили после того, как клиент их прочитал; клиенту нужно перечитать их и повторить
+	ErrVersionConflict = errors.New("конфликт версий")
+	// ErrNotCheckedIn клиент не отметился перед консультацией, а специалист начинает ее без force
+	ErrNotCheckedIn = errors.New("клиент еще не отметился, начать консультацию без него можно с force=true")
+	// ErrPasswordNotSet пользователь приглашен администратором и еще не задал пароль по ссылке из приглашения
+	ErrPasswordNotSet = errors.New("пароль не задан, проверьте письмо с приглашением")
+)
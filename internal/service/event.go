@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+type EventServiceImpl struct {
+	repo           repository.EventRepository
+	specialistRepo repository.SpecialistRepository
+	logger         *zap.Logger
+}
+
+func NewEventService(repo repository.EventRepository, specialistRepo repository.SpecialistRepository, logger *zap.Logger) *EventServiceImpl {
+	return &EventServiceImpl{
+		repo:           repo,
+		specialistRepo: specialistRepo,
+		logger:         logger,
+	}
+}
+
+// ListSpecialistEvents возвращает историю изменений профиля специалиста от старых событий к новым
+func (s *EventServiceImpl) ListSpecialistEvents(ctx context.Context, specialistID int64, limit, offset int) ([]domain.Event, int, error) {
+	ctx, span := tracer.Start(ctx, "EventService.ListSpecialistEvents")
+	defer span.End()
+
+	if _, err := s.specialistRepo.GetByIDWithDeleted(ctx, specialistID); err != nil {
+		s.logger.Warn("специалист для истории изменений не найден", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, 0, fmt.Errorf("%w: специалист не найден", ErrNotFound)
+	}
+
+	events, total, err := s.repo.GetByAggregate(ctx, domain.EventAggregateSpecialist, specialistID, limit, offset)
+	if err != nil {
+		s.logger.Error("ошибка получения событий специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, 0, errors.New("ошибка при получении истории изменений специалиста")
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/repository"
+	"laps/pkg/ical"
+)
+
+type ExternalCalendarServiceImpl struct {
+	repo   repository.ExternalCalendarRepository
+	cfg    config.ExternalCalendarConfig
+	client *http.Client
+	logger *zap.Logger
+}
+
+func NewExternalCalendarService(
+	repo repository.ExternalCalendarRepository,
+	cfg config.ExternalCalendarConfig,
+	logger *zap.Logger,
+) *ExternalCalendarServiceImpl {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !cfg.AllowPrivateHosts {
+		dialer.Control = denyPrivateAddress
+	}
+
+	return &ExternalCalendarServiceImpl{
+		repo: repo,
+		cfg:  cfg,
+		client: &http.Client{
+			Timeout:   cfg.FetchTimeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext, Proxy: http.ProxyFromEnvironment},
+		},
+		logger: logger,
+	}
+}
+
+// denyPrivateAddress запрещает соединения с адресами локальной сети; проверяется уже
+// разрешенный IP, поэтому подмена DNS не помогает обойти ограничение
+func denyPrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("адрес %s недоступен для загрузки календаря", host)
+	}
+
+	return nil
+}
+
+// SetFeed подключает внешний календарь и сразу импортирует его; пустой URL отключает календарь.
+// Ошибка первой загрузки не мешает сохранению: она видна в last_error, а worker повторит попытку
+func (s *ExternalCalendarServiceImpl) SetFeed(ctx context.Context, specialistID int64, dto domain.SetExternalCalendarDTO) (*domain.ExternalCalendarFeed, error) {
+	rawURL := strings.TrimSpace(dto.URL)
+	if rawURL == "" {
+		if err := s.repo.DeleteFeed(ctx, specialistID); err != nil {
+			s.logger.Error("ошибка отключения внешнего календаря", zap.Int64("specialistID", specialistID), zap.Error(err))
+			return nil, errors.New("ошибка при отключении внешнего календаря")
+		}
+		return nil, nil
+	}
+
+	feedURL, err := normalizeFeedURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetFeed(ctx, specialistID, feedURL); err != nil {
+		s.logger.Error("ошибка сохранения внешнего календаря", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении внешнего календаря")
+	}
+
+	s.sync(ctx, specialistID, feedURL)
+
+	feed, err := s.repo.GetFeed(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("ошибка получения внешнего календаря", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении внешнего календаря")
+	}
+
+	return feed, nil
+}
+
+func (s *ExternalCalendarServiceImpl) GetFeed(ctx context.Context, specialistID int64) (*domain.ExternalCalendarFeed, error) {
+	feed, err := s.repo.GetFeed(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("ошибка получения внешнего календаря", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении внешнего календаря")
+	}
+	return feed, nil
+}
+
+// RunSync обновляет все внешние календари сразу и затем с заданным интервалом, пока не отменен ctx
+func (s *ExternalCalendarServiceImpl) RunSync(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.SyncAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ExternalCalendarServiceImpl) SyncAll(ctx context.Context) {
+	feeds, err := s.repo.ListFeeds(ctx)
+	if err != nil {
+		s.logger.Error("ошибка получения внешних календарей", zap.Error(err))
+		return
+	}
+
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			return
+		}
+		s.sync(ctx, feed.SpecialistID, feed.URL)
+	}
+}
+
+// sync загружает календарь и заменяет импортированную занятость. При ошибке загрузки
+// или разбора старая занятость сохраняется, а ошибка записывается в last_error
+func (s *ExternalCalendarServiceImpl) sync(ctx context.Context, specialistID int64, feedURL string) {
+	blocks, err := s.fetchBlocks(ctx, specialistID, feedURL)
+	if err == nil {
+		err = s.repo.ReplaceBlocks(ctx, specialistID, blocks)
+	}
+
+	if err != nil {
+		s.logger.Warn("ошибка синхронизации внешнего календаря",
+			zap.Int64("specialistID", specialistID),
+			zap.Error(err))
+		if markErr := s.repo.MarkSyncFailed(ctx, specialistID, err.Error()); markErr != nil {
+			s.logger.Error("ошибка сохранения состояния синхронизации", zap.Int64("specialistID", specialistID), zap.Error(markErr))
+		}
+		return
+	}
+
+	s.logger.Info("внешний календарь синхронизирован",
+		zap.Int64("specialistID", specialistID),
+		zap.Int("blocks", len(blocks)))
+}
+
+func (s *ExternalCalendarServiceImpl) fetchBlocks(ctx context.Context, specialistID int64, feedURL string) ([]domain.ExternalBusyBlock, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("неверный адрес календаря: %w", err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки календаря: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("календарь вернул статус %d", resp.StatusCode)
+	}
+
+	body := io.LimitReader(resp.Body, s.cfg.MaxFeedBytes+1)
+	limited := &countingReader{r: body}
+
+	now := time.Now()
+	from := now.Add(-24 * time.Hour)
+	to := now.AddDate(0, 0, s.cfg.HorizonDays)
+
+	events, err := ical.Parse(limited, from, to)
+	if limited.n > s.cfg.MaxFeedBytes {
+		return nil, fmt.Errorf("календарь больше %d байт", s.cfg.MaxFeedBytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора календаря: %w", err)
+	}
+
+	blocks := make([]domain.ExternalBusyBlock, 0, len(events))
+	for _, event := range events {
+		if !event.End.After(event.Start) {
+			continue
+		}
+		blocks = append(blocks, domain.ExternalBusyBlock{
+			SpecialistID: specialistID,
+			UID:          truncate(event.UID, 255),
+			StartsAt:     event.Start,
+			EndsAt:       event.End,
+		})
+	}
+
+	return blocks, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
+
+// normalizeFeedURL принимает http(s) и webcal ссылки; webcal загружается по https
+func normalizeFeedURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("%w: неверный адрес календаря", ErrInvalid)
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "webcal", "webcals":
+		parsed.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("%w: поддерживаются только ссылки http, https и webcal", ErrInvalid)
+	}
+
+	return parsed.String(), nil
+}
+
+// filterExternallyBusy убирает слоты даты длительностью slotDuration, пересекающиеся с занятостью во внешнем календаре
+func filterExternallyBusy(slots []string, date string, location *time.Location, slotDuration time.Duration, blocks []domain.ExternalBusyBlock) []string {
+	if len(blocks) == 0 {
+		return slots
+	}
+
+	var free []string
+	for _, slot := range slots {
+		start, err := time.ParseInLocation("2006-01-02 15:04", date+" "+slot, location)
+		if err != nil || !isExternallyBusy(blocks, start, start.Add(slotDuration)) {
+			free = append(free, slot)
+		}
+	}
+
+	return free
+}
+
+func isExternallyBusy(blocks []domain.ExternalBusyBlock, start, end time.Time) bool {
+	for _, block := range blocks {
+		if block.Overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// externalBlocksForDate загружает внешнюю занятость специалиста на календарный день
+func externalBlocksForDate(ctx context.Context, repo repository.ExternalCalendarRepository, specialistID int64, date string, location *time.Location) ([]domain.ExternalBusyBlock, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, location)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListBlocks(ctx, specialistID, day, day.AddDate(0, 0, 1))
+}
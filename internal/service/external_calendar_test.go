@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+)
+
+// recurringFeed календарь с ежедневным 15-минутным созвоном в 10:30, начавшимся неделю назад,
+// и разовой встречей на завтра с 12:00 до 12:30
+func recurringFeed() string {
+	standup := tomorrowAt(10, 30).AddDate(0, 0, -7).UTC()
+	meeting := tomorrowAt(12, 0).UTC()
+	return strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:standup@test",
+		"DTSTART:" + standup.Format("20060102T150405Z"),
+		"DURATION:PT15M",
+		"RRULE:FREQ=DAILY;COUNT=30",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:meeting@test",
+		"DTSTART:" + meeting.Format("20060102T150405Z"),
+		"DTEND:" + meeting.Add(30*time.Minute).Format("20060102T150405Z"),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+}
+
+// feedServer отдает календарь, пока failing не выставлен, и считает обращения
+type feedServer struct {
+	*httptest.Server
+	body     atomic.Value
+	failing  atomic.Bool
+	requests atomic.Int32
+}
+
+func newFeedServer(t *testing.T, body string) *feedServer {
+	t.Helper()
+	s := &feedServer{}
+	s.body.Store(body)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar")
+		fmt.Fprint(w, s.body.Load().(string))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestCalendarService(repo *fakeCalendarRepo, allowPrivate bool) *ExternalCalendarServiceImpl {
+	return NewExternalCalendarService(repo, config.ExternalCalendarConfig{
+		FetchTimeout:      2 * time.Second,
+		MaxFeedBytes:      1 << 20,
+		HorizonDays:       30,
+		AllowPrivateHosts: allowPrivate,
+	}, zap.NewNop())
+}
+
+func TestExternalCalendarBlocksBooking(t *testing.T) {
+	server := newFeedServer(t, recurringFeed())
+	f := newAppointmentFixture()
+	calendar := newTestCalendarService(f.calendar, true)
+
+	feed, err := calendar.SetFeed(context.Background(), 7, domain.SetExternalCalendarDTO{URL: server.URL + "/feed.ics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed == nil || feed.URL != server.URL+"/feed.ics" {
+		t.Fatalf("feed = %+v", feed)
+	}
+	if len(f.calendar.syncErrors) != 0 {
+		t.Fatalf("sync errors = %v", f.calendar.syncErrors)
+	}
+
+	// Созвон в 10:30 попадает внутрь часового слота 10:00, встреча занимает слот 12:00
+	slots, err := f.service.GetFreeSlots(context.Background(), 7, tomorrowAt(0, 0).Format("2006-01-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(slots, ",") != "09:00,11:00" {
+		t.Errorf("free slots = %v, want [09:00 11:00]", slots)
+	}
+
+	for _, at := range []time.Time{tomorrowAt(10, 0), tomorrowAt(12, 0)} {
+		_, _, err := f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+			SpecialistID:        7,
+			AppointmentDate:     at,
+			CommunicationMethod: domain.CommunicationMethodPhone,
+		})
+		if err == nil {
+			t.Errorf("booked %s over an external event", at.Format("15:04"))
+		}
+	}
+	if _, _, err := f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+		SpecialistID:        7,
+		AppointmentDate:     tomorrowAt(11, 0),
+		CommunicationMethod: domain.CommunicationMethodPhone,
+	}); err != nil {
+		t.Errorf("booking a free slot: %v", err)
+	}
+}
+
+func TestExternalCalendarSyncFailureKeepsBlocks(t *testing.T) {
+	server := newFeedServer(t, recurringFeed())
+	repo := &fakeCalendarRepo{}
+	calendar := newTestCalendarService(repo, true)
+	if err := repo.SetFeed(context.Background(), 7, server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	calendar.SyncAll(context.Background())
+	imported := len(repo.blocks)
+	if imported == 0 || len(repo.syncErrors) != 0 {
+		t.Fatalf("blocks = %d, sync errors = %v", imported, repo.syncErrors)
+	}
+
+	failures := []struct {
+		name    string
+		prepare func()
+	}{
+		{"server error", func() { server.failing.Store(true) }},
+		{"not a calendar", func() { server.failing.Store(false); server.body.Store("<html>maintenance</html>") }},
+		{"feed too large", func() {
+			server.body.Store(recurringFeed() + strings.Repeat(" ", 1<<20))
+		}},
+	}
+
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(repo.syncErrors)
+			tt.prepare()
+
+			calendar.SyncAll(context.Background())
+
+			if len(repo.syncErrors) != before+1 {
+				t.Errorf("sync errors = %v, want one more", repo.syncErrors)
+			}
+			if len(repo.blocks) != imported {
+				t.Errorf("blocks = %d after a failed sync, want %d kept", len(repo.blocks), imported)
+			}
+		})
+	}
+}
+
+func TestExternalCalendarDeniesPrivateHosts(t *testing.T) {
+	server := newFeedServer(t, recurringFeed())
+	repo := &fakeCalendarRepo{}
+	calendar := newTestCalendarService(repo, false)
+
+	if _, err := calendar.SetFeed(context.Background(), 7, domain.SetExternalCalendarDTO{URL: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := server.requests.Load(); n != 0 {
+		t.Errorf("loopback feed was fetched %d times", n)
+	}
+	if len(repo.syncErrors) != 1 || len(repo.blocks) != 0 {
+		t.Errorf("sync errors = %v, blocks = %d; want the fetch refused", repo.syncErrors, len(repo.blocks))
+	}
+}
+
+func TestNormalizeFeedURL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "https://calendar.example.com/feed.ics", want: "https://calendar.example.com/feed.ics"},
+		{in: "http://calendar.example.com/feed.ics", want: "http://calendar.example.com/feed.ics"},
+		{in: "webcal://calendar.example.com/feed.ics", want: "https://calendar.example.com/feed.ics"},
+		{in: "WEBCALS://calendar.example.com/feed.ics?key=1", want: "https://calendar.example.com/feed.ics?key=1"},
+		{in: "ftp://calendar.example.com/feed.ics", wantErr: true},
+		{in: "file:///etc/passwd", wantErr: true},
+		{in: "calendar.example.com/feed.ics", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := normalizeFeedURL(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("err = %v, want ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("normalizeFeedURL() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,691 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// Фейки хранилищ встраивают интерфейс и реализуют только методы, которые нужны тестам:
+// вызов любого другого метода завершится паникой и сразу покажет непредусмотренное обращение
+
+type fakeAppointmentRepo struct {
+	repository.AppointmentRepository
+
+	mu            sync.Mutex
+	appointments  map[int64]*domain.Appointment
+	bookedSlots   []string
+	heldSlots     []string
+	lastCompleted *domain.Appointment
+	// lastCompletedScope специализация, переданная в последний вызов GetLastCompleted
+	lastCompletedScope *int64
+	created            []domain.CreateAppointmentDTO
+	updated            []domain.UpdateAppointmentDTO
+	createErr          error
+	updateErr          error
+	holdErr            error
+	exported           []domain.Appointment
+	exportCalls        int
+	confirmationSent   map[int64]bool
+	// completable записи, которые ListCompletableByCall считает состоявшимися по звонку
+	completable []int64
+	// listFilter фильтр последнего запроса списка
+	listFilter *domain.AppointmentFilter
+}
+
+func newFakeAppointmentRepo() *fakeAppointmentRepo {
+	return &fakeAppointmentRepo{appointments: make(map[int64]*domain.Appointment)}
+}
+
+func (r *fakeAppointmentRepo) Create(ctx context.Context, clientID int64, dto domain.CreateAppointmentDTO) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.createErr != nil {
+		return 0, r.createErr
+	}
+	r.created = append(r.created, dto)
+	id := int64(len(r.appointments) + 1)
+	r.appointments[id] = &domain.Appointment{
+		ID:               id,
+		ClientID:         clientID,
+		SpecialistID:     dto.SpecialistID,
+		AppointmentDate:  dto.AppointmentDate,
+		ConsultationType: dto.ConsultationType,
+		Status:           domain.AppointmentStatusPending,
+	}
+	return id, nil
+}
+
+func (r *fakeAppointmentRepo) GetByID(ctx context.Context, id int64) (*domain.Appointment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	appointment, ok := r.appointments[id]
+	if !ok {
+		return nil, errors.New("запись не найдена")
+	}
+	copied := *appointment
+	return &copied, nil
+}
+
+func (r *fakeAppointmentRepo) Update(ctx context.Context, id int64, dto domain.UpdateAppointmentDTO) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	r.updated = append(r.updated, dto)
+	if appointment, ok := r.appointments[id]; ok && dto.Status != nil {
+		appointment.Status = *dto.Status
+	}
+	return nil
+}
+
+func (r *fakeAppointmentRepo) UpdateStatus(ctx context.Context, id int64, status domain.AppointmentStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	appointment, ok := r.appointments[id]
+	if !ok {
+		return errors.New("запись не найдена")
+	}
+	appointment.Status = status
+	return nil
+}
+
+// CheckIn и Start проверяют статус и отметку клиента по тем же правилам, что и хранилище
+func (r *fakeAppointmentRepo) CheckIn(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	appointment, ok := r.appointments[id]
+	if !ok || appointment.Status != domain.AppointmentStatusPaid {
+		return repository.ErrAppointmentNotPaid
+	}
+	if appointment.CheckedInAt == nil {
+		now := time.Now()
+		appointment.CheckedInAt = &now
+	}
+	return nil
+}
+
+func (r *fakeAppointmentRepo) Start(ctx context.Context, id int64, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	appointment, ok := r.appointments[id]
+	if !ok || appointment.Status != domain.AppointmentStatusPaid {
+		return repository.ErrAppointmentNotPaid
+	}
+	if appointment.CheckedInAt == nil && !force {
+		return repository.ErrNotCheckedIn
+	}
+	now := time.Now()
+	appointment.Status = domain.AppointmentStatusInProgress
+	appointment.ActualStartAt = &now
+	return nil
+}
+
+func (r *fakeAppointmentRepo) ListCompletableByCall(ctx context.Context, minDurationSeconds int, endedBefore time.Time) ([]int64, error) {
+	return r.completable, nil
+}
+
+// MarkConfirmationSent отмечает отправку подтверждения один раз, повторные вызовы возвращают false
+func (r *fakeAppointmentRepo) MarkConfirmationSent(ctx context.Context, id int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.confirmationSent == nil {
+		r.confirmationSent = make(map[int64]bool)
+	}
+	if r.confirmationSent[id] {
+		return false, nil
+	}
+	r.confirmationSent[id] = true
+	return true, nil
+}
+
+func (r *fakeAppointmentRepo) List(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listFilter = &filter
+	return []domain.Appointment{}, nil
+}
+
+// CountByFilter учитывает клиента, специалиста и набор статусов; остальные условия фильтра не поддерживаются
+func (r *fakeAppointmentRepo) CountByFilter(ctx context.Context, filter domain.AppointmentFilter) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, a := range r.appointments {
+		if filter.ClientID != nil && a.ClientID != *filter.ClientID ||
+			filter.SpecialistID != nil && a.SpecialistID != *filter.SpecialistID ||
+			len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, a.Status) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (r *fakeAppointmentRepo) GetBookedSlots(ctx context.Context, specialistID int64, date string) ([]string, error) {
+	return r.bookedSlots, nil
+}
+
+func (r *fakeAppointmentRepo) GetHeldSlots(ctx context.Context, specialistID int64, date string) ([]string, error) {
+	return r.heldSlots, nil
+}
+
+func (r *fakeAppointmentRepo) GetBookedSlotsForSpecialists(ctx context.Context, specialistIDs []int64, date string) (map[int64][]string, error) {
+	booked := make(map[int64][]string, len(specialistIDs))
+	for _, specialistID := range specialistIDs {
+		booked[specialistID] = r.bookedSlots
+	}
+	return booked, nil
+}
+
+func (r *fakeAppointmentRepo) GetLastCompleted(ctx context.Context, clientID, specialistID int64, specializationID *int64) (*domain.Appointment, error) {
+	r.lastCompletedScope = specializationID
+	return r.lastCompleted, nil
+}
+
+func (r *fakeAppointmentRepo) HasActiveAppointment(ctx context.Context, specialistID, clientID int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.appointments {
+		if a.SpecialistID == specialistID && a.ClientID == clientID && a.Status != domain.AppointmentStatusCancelled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeAppointmentRepo) CreateHold(ctx context.Context, token string, clientID, specialistID int64, slotAt, expiresAt time.Time, maxActive int) (*domain.SlotHold, error) {
+	if r.holdErr != nil {
+		return nil, r.holdErr
+	}
+	return &domain.SlotHold{ID: 1, Token: token, ClientID: clientID, SpecialistID: specialistID, SlotAt: slotAt, ExpiresAt: expiresAt}, nil
+}
+
+// ListForExport отдает exported порциями после (AfterDate, AfterID), как курсор хранилища;
+// exported должны быть упорядочены по (appointment_date, id)
+func (r *fakeAppointmentRepo) ListForExport(ctx context.Context, filter domain.AppointmentExportFilter) ([]domain.Appointment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exportCalls++
+
+	var batch []domain.Appointment
+	for _, a := range r.exported {
+		if filter.AfterDate != nil && (a.AppointmentDate.Before(*filter.AfterDate) ||
+			a.AppointmentDate.Equal(*filter.AfterDate) && a.ID <= filter.AfterID) {
+			continue
+		}
+		if len(batch) == filter.Limit {
+			break
+		}
+		batch = append(batch, a)
+	}
+	return batch, nil
+}
+
+// fakeScheduleRepo отдает одно недельное расписание и исключение на любую дату
+type fakeScheduleRepo struct {
+	repository.ScheduleRepository
+
+	schedule *domain.Schedule
+	override *domain.ScheduleOverride
+	// week и overrides отдаются при чтении недели целиком
+	week      []domain.Schedule
+	overrides []domain.ScheduleOverride
+	// weekVersion версия недели, которую ReplaceWeek проверяет и увеличивает, как хранилище
+	weekVersion int
+	replaced    []domain.Schedule
+}
+
+func (r *fakeScheduleRepo) ReplaceWeek(ctx context.Context, specialistID int64, startDate, endDate time.Time, expectedVersion *int, schedules []domain.Schedule) (int, error) {
+	if expectedVersion != nil && *expectedVersion != r.weekVersion {
+		return 0, repository.ErrVersionConflict
+	}
+	r.weekVersion++
+	r.replaced = schedules
+	return r.weekVersion, nil
+}
+
+func (r *fakeScheduleRepo) List(ctx context.Context, filter domain.ScheduleFilter) ([]domain.Schedule, int, error) {
+	return r.week, len(r.week), nil
+}
+
+func (r *fakeScheduleRepo) ListOverrides(ctx context.Context, specialistID int64, startDate, endDate time.Time) ([]domain.ScheduleOverride, error) {
+	return r.overrides, nil
+}
+
+func (r *fakeScheduleRepo) SetOverride(ctx context.Context, override domain.ScheduleOverride) (*domain.ScheduleOverride, error) {
+	r.override = &override
+	return &override, nil
+}
+
+func (r *fakeScheduleRepo) DeleteOverride(ctx context.Context, specialistID int64, date time.Time) error {
+	r.override = nil
+	return nil
+}
+
+func (r *fakeScheduleRepo) GetOverride(ctx context.Context, specialistID int64, date time.Time) (*domain.ScheduleOverride, error) {
+	return r.override, nil
+}
+
+func (r *fakeScheduleRepo) GetBySpecialistAndDate(ctx context.Context, specialistID int64, date time.Time) (*domain.Schedule, error) {
+	return r.schedule, nil
+}
+
+func (r *fakeScheduleRepo) ListBySpecialistsAndDate(ctx context.Context, specialistIDs []int64, date time.Time) ([]domain.Schedule, error) {
+	if r.schedule == nil {
+		return nil, nil
+	}
+	return []domain.Schedule{*r.schedule}, nil
+}
+
+func (r *fakeScheduleRepo) ListOverridesForSpecialists(ctx context.Context, specialistIDs []int64, date time.Time) ([]domain.ScheduleOverride, error) {
+	if r.override == nil {
+		return nil, nil
+	}
+	return []domain.ScheduleOverride{*r.override}, nil
+}
+
+// fakeCalendarRepo хранит импортированную занятость в памяти
+type fakeCalendarRepo struct {
+	repository.ExternalCalendarRepository
+
+	mu         sync.Mutex
+	feeds      []domain.ExternalCalendarFeed
+	blocks     []domain.ExternalBusyBlock
+	syncErrors []string
+}
+
+func (r *fakeCalendarRepo) SetFeed(ctx context.Context, specialistID int64, url string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.feeds = []domain.ExternalCalendarFeed{{SpecialistID: specialistID, URL: url}}
+	return nil
+}
+
+func (r *fakeCalendarRepo) GetFeed(ctx context.Context, specialistID int64) (*domain.ExternalCalendarFeed, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, feed := range r.feeds {
+		if feed.SpecialistID == specialistID {
+			return &feed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeCalendarRepo) ListFeeds(ctx context.Context) ([]domain.ExternalCalendarFeed, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.feeds, nil
+}
+
+func (r *fakeCalendarRepo) ReplaceBlocks(ctx context.Context, specialistID int64, blocks []domain.ExternalBusyBlock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blocks = blocks
+	return nil
+}
+
+func (r *fakeCalendarRepo) MarkSyncFailed(ctx context.Context, specialistID int64, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.syncErrors = append(r.syncErrors, message)
+	return nil
+}
+
+func (r *fakeCalendarRepo) ListBlocks(ctx context.Context, specialistID int64, from, to time.Time) ([]domain.ExternalBusyBlock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var blocks []domain.ExternalBusyBlock
+	for _, block := range r.blocks {
+		if block.Overlaps(from, to) {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+// fakeSpecializationRepo хранит специализации в памяти и считает чтения списка,
+// чтобы тесты видели, ответил сервис из кэша или из хранилища
+type fakeSpecializationRepo struct {
+	repository.SpecializationRepository
+
+	items     []domain.Specialization
+	listCalls int
+}
+
+func (r *fakeSpecializationRepo) Create(ctx context.Context, dto domain.CreateSpecializationDTO) (int64, error) {
+	id := int64(len(r.items) + 1)
+	r.items = append(r.items, domain.Specialization{ID: id, ParentID: dto.ParentID, Name: dto.Name, Type: dto.Type, IsActive: true})
+	return id, nil
+}
+
+func (r *fakeSpecializationRepo) GetByID(ctx context.Context, id int64) (*domain.Specialization, error) {
+	for _, item := range r.items {
+		if item.ID == id {
+			return &item, nil
+		}
+	}
+	return nil, repository.ErrSpecializationNotFound
+}
+
+// Update переносит специализацию, отклоняя перенос внутрь собственного поддерева, как хранилище
+func (r *fakeSpecializationRepo) Update(ctx context.Context, id int64, dto domain.UpdateSpecializationDTO) error {
+	if dto.ParentID != nil && !dto.RemoveParent {
+		for parentID := dto.ParentID; parentID != nil; {
+			if *parentID == id {
+				return repository.ErrSpecializationCycle
+			}
+			parent, err := r.GetByID(ctx, *parentID)
+			if err != nil {
+				return err
+			}
+			parentID = parent.ParentID
+		}
+	}
+
+	for i := range r.items {
+		if r.items[i].ID != id {
+			continue
+		}
+		if dto.Name != nil {
+			r.items[i].Name = *dto.Name
+		}
+		if dto.RemoveParent {
+			r.items[i].ParentID = nil
+		} else if dto.ParentID != nil {
+			r.items[i].ParentID = dto.ParentID
+		}
+		return nil
+	}
+	return repository.ErrSpecializationNotFound
+}
+
+func (r *fakeSpecializationRepo) HasChildren(ctx context.Context, id int64) (bool, error) {
+	for _, item := range r.items {
+		if item.ParentID != nil && *item.ParentID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeSpecializationRepo) Delete(ctx context.Context, id int64) error {
+	for i, item := range r.items {
+		if item.ID == id {
+			r.items = append(r.items[:i], r.items[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrSpecializationNotFound
+}
+
+func (r *fakeSpecializationRepo) List(ctx context.Context, filter domain.SpecializationFilter) ([]domain.Specialization, error) {
+	r.listCalls++
+	return r.filter(filter), nil
+}
+
+func (r *fakeSpecializationRepo) CountByFilter(ctx context.Context, filter domain.SpecializationFilter) (int, error) {
+	return len(r.filter(filter)), nil
+}
+
+func (r *fakeSpecializationRepo) filter(filter domain.SpecializationFilter) []domain.Specialization {
+	var items []domain.Specialization
+	for _, item := range r.items {
+		if filter.Type == nil || item.Type == *filter.Type {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// fakeTagRepo запоминает сохраненные метки специалиста
+type fakeTagRepo struct {
+	repository.TagRepository
+
+	saved []string
+}
+
+func (r *fakeTagRepo) SetSpecialistTags(ctx context.Context, specialistID int64, names []string) error {
+	r.saved = names
+	return nil
+}
+
+func (r *fakeTagRepo) GetSpecialistTags(ctx context.Context, specialistID int64) ([]domain.Tag, error) {
+	tags := make([]domain.Tag, 0, len(r.saved))
+	for i, name := range r.saved {
+		tags = append(tags, domain.Tag{ID: int64(i + 1), Name: name, UsageCount: 1})
+	}
+	return tags, nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+
+	// role роль, с которой возвращается любой пользователь
+	role domain.UserRole
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	return &domain.User{ID: id, Role: r.role, IsActive: true}, nil
+}
+
+type fakeSpecialistRepo struct {
+	repository.SpecialistRepository
+
+	specialist    *domain.Specialist
+	updateErr     error
+	activityStats domain.SpecialistActivityStats
+	// listFilter фильтр последнего запроса списка, уже нормализованный сервисом
+	listFilter domain.SpecialistFilter
+}
+
+func (r *fakeSpecialistRepo) List(ctx context.Context, filter domain.SpecialistFilter) ([]domain.Specialist, error) {
+	r.listFilter = filter
+	return []domain.Specialist{}, nil
+}
+
+func (r *fakeSpecialistRepo) CountByFilter(ctx context.Context, filter domain.SpecialistFilter) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeSpecialistRepo) GetActivityStats(ctx context.Context, specialistID int64, since time.Time) (*domain.SpecialistActivityStats, error) {
+	stats := r.activityStats
+	return &stats, nil
+}
+
+func (r *fakeSpecialistRepo) Update(ctx context.Context, id int64, dto domain.UpdateSpecialistDTO) error {
+	return r.updateErr
+}
+
+// GetByID, как и хранилище, не находит удаленного специалиста; GetByIDWithDeleted находит
+func (r *fakeSpecialistRepo) GetByID(ctx context.Context, id int64) (*domain.Specialist, error) {
+	if r.specialist != nil && r.specialist.DeletedAt != nil {
+		return nil, repository.ErrSpecialistNotFound
+	}
+	return r.specialist, nil
+}
+
+func (r *fakeSpecialistRepo) GetByUserID(ctx context.Context, userID int64) (*domain.Specialist, error) {
+	if r.specialist == nil || r.specialist.UserID != userID {
+		return nil, repository.ErrSpecialistNotFound
+	}
+	return r.specialist, nil
+}
+
+func (r *fakeSpecialistRepo) GetByIDWithDeleted(ctx context.Context, id int64) (*domain.Specialist, error) {
+	return r.specialist, nil
+}
+
+type fakeBlockListRepo struct {
+	repository.BlockListRepository
+
+	blocked bool
+}
+
+func (r *fakeBlockListRepo) IsBlocked(ctx context.Context, specialistID, clientID int64) (bool, error) {
+	return r.blocked, nil
+}
+
+// fakeChatService создает не больше одного чата на запись, как хранилище чатов
+type fakeChatService struct {
+	ChatService
+
+	mu        sync.Mutex
+	sessions  map[int64]*domain.ChatSession
+	createErr error
+	// systemMessages системные сообщения по ID чата
+	systemMessages map[int64][]string
+}
+
+func (s *fakeChatService) CreateChatSession(ctx context.Context, dto domain.CreateChatSessionDTO) (*domain.ChatSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	if s.sessions == nil {
+		s.sessions = make(map[int64]*domain.ChatSession)
+	}
+	session, ok := s.sessions[dto.AppointmentID]
+	if !ok {
+		session = &domain.ChatSession{ID: int64(len(s.sessions) + 1), AppointmentID: dto.AppointmentID,
+			ClientID: dto.ClientID, SpecialistID: dto.SpecialistID, Status: dto.Status}
+		s.sessions[dto.AppointmentID] = session
+	}
+	return session, nil
+}
+
+func (s *fakeChatService) CreateSystemMessage(ctx context.Context, sessionID, senderID int64, content string) (*domain.ChatMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.systemMessages == nil {
+		s.systemMessages = make(map[int64][]string)
+	}
+	s.systemMessages[sessionID] = append(s.systemMessages[sessionID], content)
+	return &domain.ChatMessage{SessionID: sessionID, SenderID: senderID, Content: content}, nil
+}
+
+type fakeNotifier struct {
+	mu   sync.Mutex
+	sent []domain.Notification
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// fakeReviewRequestRepo планирует не больше одной просьбы на запись, как первичный ключ review_requests;
+// reviewed — записи, о которых клиент уже оставил отзыв
+type fakeReviewRequestRepo struct {
+	repository.ReviewRequestRepository
+
+	requests map[int64]*domain.ReviewRequest
+	sent     map[int64]bool
+	reviewed map[int64]bool
+}
+
+func newFakeReviewRequestRepo() *fakeReviewRequestRepo {
+	return &fakeReviewRequestRepo{
+		requests: make(map[int64]*domain.ReviewRequest),
+		sent:     make(map[int64]bool),
+		reviewed: make(map[int64]bool),
+	}
+}
+
+func (r *fakeReviewRequestRepo) Schedule(ctx context.Context, appointmentID, clientID int64, sendAfter time.Time) (bool, error) {
+	if r.reviewed[appointmentID] || r.requests[appointmentID] != nil {
+		return false, nil
+	}
+	r.requests[appointmentID] = &domain.ReviewRequest{AppointmentID: appointmentID, ClientID: clientID, SendAfter: sendAfter}
+	return true, nil
+}
+
+func (r *fakeReviewRequestRepo) ClaimDue(ctx context.Context, limit int) ([]domain.ReviewRequest, error) {
+	var due []domain.ReviewRequest
+	for id, request := range r.requests {
+		if r.sent[id] || request.SendAfter.After(time.Now()) || len(due) == limit {
+			continue
+		}
+		r.sent[id] = true
+		claimed := *request
+		claimed.Reviewed = r.reviewed[id]
+		due = append(due, claimed)
+	}
+	return due, nil
+}
+
+type nopRealtime struct{}
+
+func (nopRealtime) Publish(userID int64, eventType string, data interface{}) {}
+
+// appointmentFixture собирает сервис записей на фейках: специалист 7 (пользователь 70) работает
+// каждый день с 09:00 до 13:00 часовыми слотами
+type appointmentFixture struct {
+	service    *AppointmentServiceImpl
+	repo       *fakeAppointmentRepo
+	schedules  *fakeScheduleRepo
+	calendar   *fakeCalendarRepo
+	specialist *domain.Specialist
+	blockList  *fakeBlockListRepo
+	chat       *fakeChatService
+	reviews    *fakeReviewRequestRepo
+	notifier   *fakeNotifier
+}
+
+func newAppointmentFixture() *appointmentFixture {
+	f := &appointmentFixture{
+		repo: newFakeAppointmentRepo(),
+		schedules: &fakeScheduleRepo{schedule: &domain.Schedule{
+			SpecialistID: 7,
+			StartTime:    "09:00",
+			EndTime:      "13:00",
+			SlotTime:     60,
+		}},
+		calendar: &fakeCalendarRepo{},
+		specialist: &domain.Specialist{
+			ID:               7,
+			UserID:           70,
+			AcceptingClients: true,
+			User:             domain.User{ID: 70, IsActive: true},
+		},
+		blockList: &fakeBlockListRepo{},
+		chat:      &fakeChatService{},
+		reviews:   newFakeReviewRequestRepo(),
+		notifier:  &fakeNotifier{},
+	}
+	f.service = NewAppointmentService(
+		f.repo,
+		f.schedules,
+		&fakeSpecialistRepo{specialist: f.specialist},
+		&fakeUserRepo{},
+		f.calendar,
+		f.blockList,
+		nil,
+		f.reviews,
+		f.chat,
+		f.notifier,
+		nopRealtime{},
+		config.AppointmentConfig{SecondaryWindow: 180 * 24 * time.Hour},
+		zap.NewNop(),
+	)
+	return f
+}
+
+// tomorrowAt возвращает время завтрашнего дня в локальной зоне
+func tomorrowAt(hour, minute int) time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day()+1, hour, minute, 0, 0, time.Local)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+// freeSlotsCase задает день специалиста 7 и ожидаемые свободные слоты
+type freeSlotsCase struct {
+	name     string
+	schedule *domain.Schedule
+	override *domain.ScheduleOverride
+	booked   []string
+	blocks   []domain.ExternalBusyBlock
+	want     []string
+}
+
+func freeSlotsCases() []freeSlotsCase {
+	day := tomorrowAt(0, 0)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	return []freeSlotsCase{
+		{
+			name:     "slots follow the schedule, not the former 09:00-17:00 grid",
+			schedule: &domain.Schedule{SpecialistID: 7, StartTime: "18:00", EndTime: "20:00", SlotTime: 30},
+			want:     []string{"18:00", "18:30", "19:00", "19:30"},
+		},
+		{
+			name:     "booked slots and breaks are excluded",
+			schedule: &domain.Schedule{SpecialistID: 7, StartTime: "09:00", EndTime: "13:00", SlotTime: 60, ExcludeTimes: []string{"12:00"}},
+			booked:   []string{"10:00"},
+			want:     []string{"09:00", "11:00"},
+		},
+		{
+			name:     "no schedule means no slots",
+			schedule: nil,
+			want:     []string{},
+		},
+		{
+			name:     "override replaces the weekly schedule",
+			schedule: &domain.Schedule{SpecialistID: 7, StartTime: "09:00", EndTime: "13:00", SlotTime: 60},
+			override: &domain.ScheduleOverride{SpecialistID: 7, StartTime: "14:00", EndTime: "15:00", SlotTime: 20},
+			want:     []string{"14:00", "14:20", "14:40"},
+		},
+		{
+			name:     "day off",
+			schedule: &domain.Schedule{SpecialistID: 7, StartTime: "09:00", EndTime: "13:00", SlotTime: 60},
+			override: &domain.ScheduleOverride{SpecialistID: 7, IsDayOff: true},
+			want:     []string{},
+		},
+		{
+			// Блок с 10:30 до 10:45 попадает внутрь часового слота 10:00, хотя не пересекается с его началом
+			name:     "external block inside a long slot",
+			schedule: &domain.Schedule{SpecialistID: 7, StartTime: "09:00", EndTime: "13:00", SlotTime: 60},
+			blocks:   []domain.ExternalBusyBlock{{SpecialistID: 7, StartsAt: at(10, 30), EndsAt: at(10, 45)}},
+			want:     []string{"09:00", "11:00", "12:00"},
+		},
+		{
+			name:     "external block checked against the override slot length",
+			schedule: &domain.Schedule{SpecialistID: 7, StartTime: "09:00", EndTime: "13:00", SlotTime: 60},
+			override: &domain.ScheduleOverride{SpecialistID: 7, StartTime: "09:00", EndTime: "11:00", SlotTime: 30},
+			blocks:   []domain.ExternalBusyBlock{{SpecialistID: 7, StartsAt: at(9, 30), EndsAt: at(10, 0)}},
+			want:     []string{"09:00", "10:00", "10:30"},
+		},
+		{
+			name:     "block ending at the slot start leaves it free",
+			schedule: &domain.Schedule{SpecialistID: 7, StartTime: "09:00", EndTime: "11:00", SlotTime: 60},
+			blocks:   []domain.ExternalBusyBlock{{SpecialistID: 7, StartsAt: at(8, 0), EndsAt: at(9, 0)}},
+			want:     []string{"09:00", "10:00"},
+		},
+	}
+}
+
+func (tt freeSlotsCase) fixture() *appointmentFixture {
+	f := newAppointmentFixture()
+	f.schedules.schedule = tt.schedule
+	f.schedules.override = tt.override
+	f.repo.bookedSlots = tt.booked
+	f.calendar.blocks = tt.blocks
+	return f
+}
+
+func TestGetFreeSlots(t *testing.T) {
+	date := tomorrowAt(0, 0).Format("2006-01-02")
+
+	for _, tt := range freeSlotsCases() {
+		t.Run(tt.name, func(t *testing.T) {
+			slots, err := tt.fixture().service.GetFreeSlots(context.Background(), 7, date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(slots) != len(tt.want) || (len(slots) > 0 && !reflect.DeepEqual(slots, tt.want)) {
+				t.Errorf("slots = %v, want %v", slots, tt.want)
+			}
+		})
+	}
+}
+
+// Пакетный запрос читает расписания своими запросами и должен давать те же слоты, что и одиночный
+func TestGetFreeSlotsBatchMatchesSingle(t *testing.T) {
+	date := tomorrowAt(0, 0).Format("2006-01-02")
+
+	for _, tt := range freeSlotsCases() {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fixture().service.GetFreeSlotsBatch(context.Background(), []int64{7}, date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slots, ok := result[7]
+			if !ok {
+				t.Fatalf("result = %v, want specialist 7", result)
+			}
+			if len(slots) != len(tt.want) || (len(slots) > 0 && !reflect.DeepEqual(slots, tt.want)) {
+				t.Errorf("slots = %v, want %v", slots, tt.want)
+			}
+		})
+	}
+}
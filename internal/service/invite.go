@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// Длина токена приглашения в байтах до кодирования в hex
+const inviteTokenBytes = 32
+
+type InviteServiceImpl struct {
+	repo      repository.InviteRepository
+	userRepo  repository.UserRepository
+	auditRepo repository.AuditRepository
+	notifier  Notifier
+	cfg       config.InviteConfig
+	logger    *zap.Logger
+}
+
+func NewInviteService(
+	repo repository.InviteRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	notifier Notifier,
+	cfg config.InviteConfig,
+	logger *zap.Logger,
+) *InviteServiceImpl {
+	return &InviteServiceImpl{
+		repo:      repo,
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		notifier:  notifier,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// Invite создает пользователя без пароля и отправляет ему ссылку для установки пароля.
+// Если пользователь с этим email уже приглашен, но пароль еще не задал, создается новый токен,
+// а предыдущий перестает действовать. actorID — администратор, отправивший приглашение
+func (s *InviteServiceImpl) Invite(ctx context.Context, actorID int64, dto domain.InviteUserDTO) (*domain.UserInviteResult, error) {
+	ctx, span := tracer.Start(ctx, "InviteService.Invite")
+	defer span.End()
+
+	userID, err := s.inviteeID(ctx, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		s.logger.Error("ошибка генерации токена приглашения", zap.Error(err))
+		return nil, errors.New("ошибка при отправке приглашения")
+	}
+
+	now := time.Now()
+	invite := domain.UserInvite{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.cfg.TTL),
+		CreatedAt: now,
+	}
+	if err := s.repo.Upsert(ctx, invite); err != nil {
+		s.logger.Error("ошибка сохранения приглашения", zap.Int64("userID", userID), zap.Error(err))
+		return nil, errors.New("ошибка при отправке приглашения")
+	}
+
+	err = s.auditRepo.Log(ctx, domain.AuditEntry{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionUserInvite,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   userID,
+	})
+	if err != nil {
+		s.logger.Error("ошибка записи приглашения в журнал аудита", zap.Int64("userID", userID), zap.Error(err))
+	}
+
+	data := map[string]interface{}{
+		"token":      token,
+		"expires_at": invite.ExpiresAt,
+	}
+	if s.cfg.AcceptURL != "" {
+		data["invite_url"] = s.cfg.AcceptURL + "?token=" + url.QueryEscape(token)
+	}
+
+	// Настроек уведомлений у нового пользователя еще нет, приглашение всегда уходит на email
+	err = s.notifier.Notify(ctx, domain.Notification{
+		UserID:  userID,
+		Type:    domain.NotificationTypeUserInvite,
+		Channel: domain.NotificationChannelEmail,
+		Title:   "Приглашение в LAPS",
+		Body:    fmt.Sprintf("Перейдите по ссылке из письма и задайте пароль до %s", invite.ExpiresAt.Format("02.01.2006 15:04")),
+		Data:    data,
+	})
+	if err != nil {
+		// Токен уже сохранен, поэтому администратор может просто отправить приглашение повторно
+		s.logger.Error("ошибка отправки приглашения", zap.Int64("userID", userID), zap.Error(err))
+		return nil, errors.New("ошибка при отправке приглашения")
+	}
+
+	return &domain.UserInviteResult{UserID: userID, ExpiresAt: invite.ExpiresAt}, nil
+}
+
+// inviteeID возвращает ID приглашаемого пользователя, создавая его при первом приглашении
+func (s *InviteServiceImpl) inviteeID(ctx context.Context, dto domain.InviteUserDTO) (int64, error) {
+	existing, err := s.userRepo.GetByEmail(ctx, dto.Email)
+	if err == nil && existing != nil {
+		if existing.HasPassword() {
+			return 0, fmt.Errorf("%w: пользователь с таким email уже зарегистрирован", ErrConflict)
+		}
+		return existing.ID, nil
+	}
+
+	existing, err = s.userRepo.GetByPhone(ctx, dto.Phone)
+	if err == nil && existing != nil {
+		return 0, fmt.Errorf("%w: %v", ErrConflict, repository.ErrPhoneTaken)
+	}
+
+	userID, err := s.userRepo.Create(ctx, domain.CreateUserDTO{
+		FirstName:  dto.FirstName,
+		LastName:   dto.LastName,
+		MiddleName: dto.MiddleName,
+		Email:      dto.Email,
+		Phone:      dto.Phone,
+		Role:       dto.Role,
+	})
+	if errors.Is(err, repository.ErrEmailTaken) || errors.Is(err, repository.ErrPhoneTaken) {
+		return 0, fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+	if err != nil {
+		s.logger.Error("ошибка создания приглашенного пользователя", zap.Error(err))
+		return 0, errors.New("ошибка при отправке приглашения")
+	}
+
+	return userID, nil
+}
+
+// Accept задает пароль по токену из приглашения и возвращает пользователя, чтобы сразу выполнить вход.
+// Токен одноразовый
+func (s *InviteServiceImpl) Accept(ctx context.Context, dto domain.AcceptInviteRequest) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "InviteService.Accept")
+	defer span.End()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(dto.Password), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.Error("ошибка при хешировании пароля", zap.Error(err))
+		return nil, errors.New("ошибка при принятии приглашения")
+	}
+
+	userID, err := s.repo.Accept(ctx, hashToken(dto.Token), string(hashedPassword))
+	if errors.Is(err, repository.ErrInviteNotFound) || errors.Is(err, repository.ErrInviteExpired) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err != nil {
+		s.logger.Error("ошибка принятия приглашения", zap.Error(err))
+		return nil, errors.New("ошибка при принятии приглашения")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("пользователь не найден после принятия приглашения", zap.Int64("userID", userID), zap.Error(err))
+		return nil, errors.New("ошибка при принятии приглашения")
+	}
+
+	return user, nil
+}
+
+func newInviteToken() (string, error) {
+	buf := make([]byte, inviteTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken хеширует одноразовый токен (приглашения, восстановления пароля) для хранения:
+// токен случайный и длинный, поэтому соль не нужна
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
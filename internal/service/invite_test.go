@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// fakeUserDirectory хранит пользователей в памяти и проверяет уникальность email и телефона, как БД
+type fakeUserDirectory struct {
+	repository.UserRepository
+
+	users map[int64]*domain.User
+}
+
+func newFakeUserDirectory() *fakeUserDirectory {
+	return &fakeUserDirectory{users: make(map[int64]*domain.User)}
+}
+
+func (r *fakeUserDirectory) Create(ctx context.Context, dto domain.CreateUserDTO) (int64, error) {
+	for _, user := range r.users {
+		if user.Email == dto.Email {
+			return 0, repository.ErrEmailTaken
+		}
+		if user.Phone == dto.Phone {
+			return 0, repository.ErrPhoneTaken
+		}
+	}
+	id := int64(len(r.users) + 1)
+	r.users[id] = &domain.User{ID: id, Email: dto.Email, Phone: dto.Phone, PasswordHash: dto.Password, Role: dto.Role, IsActive: true}
+	return id, nil
+}
+
+func (r *fakeUserDirectory) find(match func(*domain.User) bool) (*domain.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *fakeUserDirectory) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserDirectory) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.Email == email })
+}
+
+func (r *fakeUserDirectory) GetByPhone(ctx context.Context, phone string) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.Phone == phone })
+}
+
+// fakeInviteRepo держит по одному приглашению на пользователя и принимает его по правилам хранилища:
+// токен одноразовый, пароль задается только пользователю без пароля
+type fakeInviteRepo struct {
+	repository.InviteRepository
+
+	users   *fakeUserDirectory
+	invites map[int64]domain.UserInvite
+}
+
+func (r *fakeInviteRepo) Upsert(ctx context.Context, invite domain.UserInvite) error {
+	r.invites[invite.UserID] = invite
+	return nil
+}
+
+func (r *fakeInviteRepo) Accept(ctx context.Context, tokenHash, passwordHash string) (int64, error) {
+	for userID, invite := range r.invites {
+		if invite.TokenHash != tokenHash {
+			continue
+		}
+		delete(r.invites, userID)
+		if invite.ExpiresAt.Before(time.Now()) {
+			return 0, repository.ErrInviteExpired
+		}
+		user := r.users.users[userID]
+		if user.HasPassword() {
+			return 0, repository.ErrInviteNotFound
+		}
+		user.PasswordHash = passwordHash
+		return userID, nil
+	}
+	return 0, repository.ErrInviteNotFound
+}
+
+type fakeAuditRepo struct {
+	repository.AuditRepository
+
+	entries []domain.AuditEntry
+}
+
+func (r *fakeAuditRepo) Log(ctx context.Context, entry domain.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+type inviteFixture struct {
+	users    *fakeUserDirectory
+	repo     *fakeInviteRepo
+	audit    *fakeAuditRepo
+	notifier *fakeNotifier
+	service  *InviteServiceImpl
+}
+
+func newInviteFixture() *inviteFixture {
+	users := newFakeUserDirectory()
+	f := &inviteFixture{
+		users:    users,
+		repo:     &fakeInviteRepo{users: users, invites: make(map[int64]domain.UserInvite)},
+		audit:    &fakeAuditRepo{},
+		notifier: &fakeNotifier{},
+	}
+	f.service = NewInviteService(f.repo, users, f.audit, f.notifier,
+		config.InviteConfig{TTL: 72 * time.Hour, AcceptURL: "https://laps.test/invite"}, zap.NewNop())
+	return f
+}
+
+// lastToken возвращает токен из последнего отправленного приглашения
+func (f *inviteFixture) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(f.notifier.sent) == 0 {
+		t.Fatal("no invite was sent")
+	}
+	token, _ := f.notifier.sent[len(f.notifier.sent)-1].Data["token"].(string)
+	if token == "" {
+		t.Fatal("invite carries no token")
+	}
+	return token
+}
+
+func inviteDTO() domain.InviteUserDTO {
+	return domain.InviteUserDTO{FirstName: "Анна", LastName: "Петрова", Email: "anna@laps.test", Phone: "+79990000001", Role: domain.UserRoleSpecialist}
+}
+
+func TestInviteAccept(t *testing.T) {
+	f := newInviteFixture()
+	ctx := context.Background()
+
+	result, err := f.service.Invite(ctx, 1, inviteDTO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := f.lastToken(t)
+
+	sent := f.notifier.sent[0]
+	if sent.UserID != result.UserID || sent.Channel != domain.NotificationChannelEmail {
+		t.Errorf("notification = %+v, want an email to user %d", sent, result.UserID)
+	}
+	if sent.Data["invite_url"] != "https://laps.test/invite?token="+token {
+		t.Errorf("invite_url = %v", sent.Data["invite_url"])
+	}
+	if stored := f.repo.invites[result.UserID]; stored.TokenHash == token || stored.TokenHash != hashToken(token) {
+		t.Errorf("stored token hash = %q, want the SHA-256 of the token", stored.TokenHash)
+	}
+	if len(f.audit.entries) != 1 || *f.audit.entries[0].ActorID != 1 || f.audit.entries[0].Action != domain.AuditActionUserInvite {
+		t.Errorf("audit entries = %+v", f.audit.entries)
+	}
+
+	user, err := f.service.Accept(ctx, domain.AcceptInviteRequest{Token: token, Password: "secret1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != result.UserID || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("secret1")) != nil {
+		t.Errorf("accepted user = %+v, want the invited user with the new password", user)
+	}
+
+	if _, err := f.service.Accept(ctx, domain.AcceptInviteRequest{Token: token, Password: "other12"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("second accept: err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestInviteResendReplacesToken(t *testing.T) {
+	f := newInviteFixture()
+	ctx := context.Background()
+
+	first, err := f.service.Invite(ctx, 1, inviteDTO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken := f.lastToken(t)
+
+	second, err := f.service.Invite(ctx, 1, inviteDTO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.UserID != first.UserID || len(f.users.users) != 1 {
+		t.Fatalf("resend created user %d, want the same user %d", second.UserID, first.UserID)
+	}
+
+	if _, err := f.service.Accept(ctx, domain.AcceptInviteRequest{Token: oldToken, Password: "secret1"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("old token: err = %v, want ErrInvalid", err)
+	}
+	if _, err := f.service.Accept(ctx, domain.AcceptInviteRequest{Token: f.lastToken(t), Password: "secret1"}); err != nil {
+		t.Errorf("new token: %v", err)
+	}
+}
+
+func TestInviteExpired(t *testing.T) {
+	f := newInviteFixture()
+	ctx := context.Background()
+
+	result, err := f.service.Invite(ctx, 1, inviteDTO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	invite := f.repo.invites[result.UserID]
+	invite.ExpiresAt = time.Now().Add(-time.Minute)
+	f.repo.invites[result.UserID] = invite
+
+	if _, err := f.service.Accept(ctx, domain.AcceptInviteRequest{Token: f.lastToken(t), Password: "secret1"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("err = %v, want ErrInvalid", err)
+	}
+	if f.users.users[result.UserID].HasPassword() {
+		t.Error("password was set by an expired invite")
+	}
+}
+
+func TestInviteConflicts(t *testing.T) {
+	f := newInviteFixture()
+	ctx := context.Background()
+	if _, err := f.users.Create(ctx, domain.CreateUserDTO{Email: "registered@laps.test", Phone: "+79990000009", Password: "hash"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		email string
+		phone string
+	}{
+		{"registered email", "registered@laps.test", "+79990000002"},
+		{"taken phone", "new@laps.test", "+79990000009"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto := inviteDTO()
+			dto.Email, dto.Phone = tt.email, tt.phone
+			if _, err := f.service.Invite(ctx, 1, dto); !errors.Is(err, ErrConflict) {
+				t.Errorf("err = %v, want ErrConflict", err)
+			}
+		})
+	}
+	if len(f.notifier.sent) != 0 || len(f.repo.invites) != 0 {
+		t.Errorf("sent = %d, invites = %d; want nothing for conflicting users", len(f.notifier.sent), len(f.repo.invites))
+	}
+}
+
+func TestLoginWithoutPassword(t *testing.T) {
+	f := newInviteFixture()
+	if _, err := f.service.Invite(context.Background(), 1, inviteDTO()); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := NewAuthService(nil, f.users, config.JWTConfig{}, zap.NewNop())
+	if _, err := auth.Login(context.Background(), domain.LoginRequest{Login: "anna@laps.test", Password: ""}, "", ""); !errors.Is(err, ErrPasswordNotSet) {
+		t.Errorf("err = %v, want ErrPasswordNotSet", err)
+	}
+}
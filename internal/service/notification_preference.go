@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+type NotificationPreferenceServiceImpl struct {
+	repo   repository.NotificationPreferenceRepository
+	logger *zap.Logger
+}
+
+func NewNotificationPreferenceService(repo repository.NotificationPreferenceRepository, logger *zap.Logger) *NotificationPreferenceServiceImpl {
+	return &NotificationPreferenceServiceImpl{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Get возвращает настройки уведомлений пользователя; если он их не менял, возвращаются настройки по умолчанию:
+// транзакционные уведомления включены, рекламные выключены
+func (s *NotificationPreferenceServiceImpl) Get(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+	prefs, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		s.logger.Error("ошибка получения настроек уведомлений", zap.Int64("userID", userID), zap.Error(err))
+		return nil, errors.New("ошибка при получении настроек уведомлений")
+	}
+
+	if prefs == nil {
+		defaults := domain.DefaultNotificationPreferences(userID)
+		prefs = &defaults
+	}
+
+	return prefs, nil
+}
+
+func (s *NotificationPreferenceServiceImpl) Update(ctx context.Context, userID int64, dto domain.UpdateNotificationPreferencesDTO) (*domain.NotificationPreferences, error) {
+	if dto.Channel != "" && !dto.Channel.IsValid() {
+		return nil, fmt.Errorf("%w: неизвестный канал уведомлений", ErrInvalid)
+	}
+
+	prefs, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	dto.Apply(prefs)
+
+	err = s.repo.Upsert(ctx, *prefs)
+	if err != nil {
+		s.logger.Error("ошибка сохранения настроек уведомлений", zap.Int64("userID", userID), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении настроек уведомлений")
+	}
+
+	return s.Get(ctx, userID)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// Notifier доставляет уведомления пользователям. Ошибка доставки не должна
+// откатывать бизнес-операцию: вызывающий код только логирует ее
+type Notifier interface {
+	Notify(ctx context.Context, notification domain.Notification) error
+}
+
+// LogNotifier пишет уведомления в лог; используется, пока не подключен канал доставки
+type LogNotifier struct {
+	logger *zap.Logger
+}
+
+func NewLogNotifier(logger *zap.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, notification domain.Notification) error {
+	n.logger.Info("уведомление пользователю",
+		zap.Int64("userID", notification.UserID),
+		zap.String("type", string(notification.Type)),
+		zap.String("channel", string(notification.Channel)),
+		zap.String("title", notification.Title))
+	return nil
+}
+
+// PreferenceNotifier применяет настройки получателя: пропускает уведомления отключенных им групп,
+// выбирает канал доставки и передает уведомление дальше. Явно заданный канал не меняется.
+// Если настройки прочитать не удалось, используются настройки по умолчанию
+type PreferenceNotifier struct {
+	prefsRepo repository.NotificationPreferenceRepository
+	next      Notifier
+	logger    *zap.Logger
+}
+
+func NewPreferenceNotifier(prefsRepo repository.NotificationPreferenceRepository, next Notifier, logger *zap.Logger) *PreferenceNotifier {
+	return &PreferenceNotifier{prefsRepo: prefsRepo, next: next, logger: logger}
+}
+
+func (n *PreferenceNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	prefs := domain.DefaultNotificationPreferences(notification.UserID)
+
+	stored, err := n.prefsRepo.Get(ctx, notification.UserID)
+	if err != nil {
+		n.logger.Warn("не удалось получить настройки уведомлений, используются настройки по умолчанию",
+			zap.Int64("userID", notification.UserID), zap.Error(err))
+	} else if stored != nil {
+		prefs = *stored
+	}
+
+	if category := notification.Type.Category(); !prefs.Allows(category) {
+		n.logger.Debug("уведомление отключено пользователем",
+			zap.Int64("userID", notification.UserID),
+			zap.String("type", string(notification.Type)))
+		return nil
+	}
+
+	if notification.Channel == "" {
+		notification.Channel = prefs.DeliveryChannel()
+	}
+
+	return n.next.Notify(ctx, notification)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+type OnboardingServiceImpl struct {
+	specialistRepo repository.SpecialistRepository
+	scheduleRepo   repository.ScheduleRepository
+	logger         *zap.Logger
+}
+
+func NewOnboardingService(specialistRepo repository.SpecialistRepository, scheduleRepo repository.ScheduleRepository, logger *zap.Logger) *OnboardingServiceImpl {
+	return &OnboardingServiceImpl{
+		specialistRepo: specialistRepo,
+		scheduleRepo:   scheduleRepo,
+		logger:         logger,
+	}
+}
+
+// GetChecklist вычисляет по данным профиля, какие шаги онбординга специалист уже выполнил
+func (s *OnboardingServiceImpl) GetChecklist(ctx context.Context, specialist *domain.Specialist) (*domain.OnboardingChecklist, error) {
+	ctx, span := tracer.Start(ctx, "OnboardingService.GetChecklist")
+	defer span.End()
+
+	education, err := s.specialistRepo.GetEducationBySpecialistID(ctx, specialist.ID)
+	if err != nil {
+		s.logger.Error("ошибка получения образования для онбординга", zap.Int64("specialistID", specialist.ID), zap.Error(err))
+		return nil, errors.New("ошибка при проверке профиля специалиста")
+	}
+
+	workExperience, err := s.specialistRepo.GetWorkExperienceBySpecialistID(ctx, specialist.ID)
+	if err != nil {
+		s.logger.Error("ошибка получения опыта работы для онбординга", zap.Int64("specialistID", specialist.ID), zap.Error(err))
+		return nil, errors.New("ошибка при проверке профиля специалиста")
+	}
+
+	hasSpecialization := specialist.SpecializationID != nil
+	if !hasSpecialization {
+		specializations, err := s.specialistRepo.GetSpecializationsBySpecialistID(ctx, specialist.ID)
+		if err != nil {
+			s.logger.Error("ошибка получения специализаций для онбординга", zap.Int64("specialistID", specialist.ID), zap.Error(err))
+			return nil, errors.New("ошибка при проверке профиля специалиста")
+		}
+		hasSpecialization = len(specializations) > 0
+	}
+
+	_, schedulesCount, err := s.scheduleRepo.List(ctx, domain.ScheduleFilter{SpecialistID: &specialist.ID, Limit: 1})
+	if err != nil {
+		s.logger.Error("ошибка получения расписания для онбординга", zap.Int64("specialistID", specialist.ID), zap.Error(err))
+		return nil, errors.New("ошибка при проверке профиля специалиста")
+	}
+
+	checklist := &domain.OnboardingChecklist{
+		Items: []domain.ChecklistItem{
+			{Step: domain.OnboardingStepAddEducation, Done: len(education) > 0},
+			{Step: domain.OnboardingStepAddWorkExperience, Done: len(workExperience) > 0},
+			{Step: domain.OnboardingStepAddPhoto, Done: specialist.ProfilePhotoURL != ""},
+			{Step: domain.OnboardingStepAddSchedule, Done: schedulesCount > 0},
+			{Step: domain.OnboardingStepAddSpecialization, Done: hasSpecialization},
+			{Step: domain.OnboardingStepGetVerified, Done: specialist.IsVerified},
+		},
+		Completed: true,
+	}
+	for _, item := range checklist.Items {
+		if !item.Done {
+			checklist.Completed = false
+			break
+		}
+	}
+
+	return checklist, nil
+}
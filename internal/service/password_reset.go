@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+type PasswordResetServiceImpl struct {
+	repo        repository.PasswordResetRepository
+	userRepo    repository.UserRepository
+	userService UserService
+	notifier    Notifier
+	cfg         config.PasswordResetConfig
+	logger      *zap.Logger
+}
+
+func NewPasswordResetService(
+	repo repository.PasswordResetRepository,
+	userRepo repository.UserRepository,
+	userService UserService,
+	notifier Notifier,
+	cfg config.PasswordResetConfig,
+	logger *zap.Logger,
+) *PasswordResetServiceImpl {
+	return &PasswordResetServiceImpl{
+		repo:        repo,
+		userRepo:    userRepo,
+		userService: userService,
+		notifier:    notifier,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+// ForgotPassword отправляет на email ссылку для восстановления пароля. Если пользователь с таким email
+// не найден, метод тоже завершается успешно, чтобы по ответу нельзя было проверить, зарегистрирован ли email.
+// Повторный запрос заменяет предыдущий токен
+func (s *PasswordResetServiceImpl) ForgotPassword(ctx context.Context, dto domain.ForgotPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "PasswordResetService.ForgotPassword")
+	defer span.End()
+
+	user, err := s.userRepo.GetByEmail(ctx, dto.Email)
+	if err != nil || user == nil {
+		s.logger.Info("запрошено восстановление пароля для неизвестного email", zap.Error(err))
+		return nil
+	}
+
+	now := time.Now()
+	token := uuid.NewString()
+	reset := domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.cfg.TTL),
+		CreatedAt: now,
+	}
+	if err := s.repo.Upsert(ctx, reset); err != nil {
+		s.logger.Error("ошибка сохранения токена восстановления пароля", zap.Int64("userID", user.ID), zap.Error(err))
+		return errors.New("ошибка при восстановлении пароля")
+	}
+
+	data := map[string]interface{}{
+		"token":      token,
+		"expires_at": reset.ExpiresAt,
+	}
+	if s.cfg.ResetURL != "" {
+		data["reset_url"] = s.cfg.ResetURL + "?token=" + url.QueryEscape(token)
+	}
+
+	// Ссылка всегда уходит на email, независимо от выбранного пользователем канала уведомлений
+	err = s.notifier.Notify(ctx, domain.Notification{
+		UserID:  user.ID,
+		Type:    domain.NotificationTypePasswordReset,
+		Channel: domain.NotificationChannelEmail,
+		Title:   "Восстановление пароля",
+		Body:    fmt.Sprintf("Перейдите по ссылке из письма и задайте новый пароль до %s", reset.ExpiresAt.Format("02.01.2006 15:04")),
+		Data:    data,
+	})
+	if err != nil {
+		s.logger.Error("ошибка отправки ссылки для восстановления пароля", zap.Int64("userID", user.ID), zap.Error(err))
+		return errors.New("ошибка при восстановлении пароля")
+	}
+
+	return nil
+}
+
+// ResetPassword задает новый пароль по токену из письма без проверки текущего пароля. Токен одноразовый
+func (s *PasswordResetServiceImpl) ResetPassword(ctx context.Context, dto domain.ResetPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "PasswordResetService.ResetPassword")
+	defer span.End()
+
+	token, err := uuid.Parse(dto.Token)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, repository.ErrPasswordResetNotFound)
+	}
+
+	userID, err := s.repo.Consume(ctx, hashToken(token.String()))
+	if errors.Is(err, repository.ErrPasswordResetNotFound) || errors.Is(err, repository.ErrPasswordResetExpired) {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err != nil {
+		s.logger.Error("ошибка получения токена восстановления пароля", zap.Error(err))
+		return errors.New("ошибка при восстановлении пароля")
+	}
+
+	if err := s.userService.ResetPassword(ctx, userID, dto.NewPassword); err != nil {
+		return err
+	}
+
+	s.logger.Info("пароль восстановлен по ссылке из письма", zap.Int64("userID", userID))
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"sync"
+)
+
+// Типы событий, которые отправляются пользователям по WebSocket
+const (
+	// RealtimeEventNewAppointment к специалисту записался клиент
+	RealtimeEventNewAppointment = "new-appointment"
+)
+
+// RealtimePublisher доставляет событие пользователю, подключенному по WebSocket.
+// Если пользователь не в сети, событие пропускается
+type RealtimePublisher interface {
+	Publish(userID int64, eventType string, data interface{})
+}
+
+// RealtimeBus передает события сервисов подписчику, который доставляет их по WebSocket. Хаб WebSocket
+// создается после сервисов и подписывается отдельно; до подписки события пропускаются
+type RealtimeBus struct {
+	mu         sync.RWMutex
+	subscriber RealtimePublisher
+}
+
+func NewRealtimeBus() *RealtimeBus {
+	return &RealtimeBus{}
+}
+
+// Subscribe задает подписчика, которому передаются все последующие события
+func (b *RealtimeBus) Subscribe(subscriber RealtimePublisher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriber = subscriber
+}
+
+func (b *RealtimeBus) Publish(userID int64, eventType string, data interface{}) {
+	b.mu.RLock()
+	subscriber := b.subscriber
+	b.mu.RUnlock()
+
+	if subscriber != nil {
+		subscriber.Publish(userID, eventType, data)
+	}
+}
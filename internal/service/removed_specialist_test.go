@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+func TestRemovedSpecialistUnavailable(t *testing.T) {
+	deletedAt := time.Now()
+	ctx := context.Background()
+
+	t.Run("booking", func(t *testing.T) {
+		f := newAppointmentFixture()
+		f.specialist.DeletedAt = &deletedAt
+
+		_, _, err := f.service.Create(ctx, 1, domain.CreateAppointmentDTO{
+			SpecialistID:        7,
+			AppointmentDate:     tomorrowAt(10, 0),
+			CommunicationMethod: domain.CommunicationMethodPhone,
+		})
+		if !errors.Is(err, ErrSpecialistUnavailable) || len(f.repo.created) != 0 {
+			t.Errorf("err = %v, created = %d; want ErrSpecialistUnavailable", err, len(f.repo.created))
+		}
+	})
+
+	t.Run("call", func(t *testing.T) {
+		f := newAppointmentFixture()
+		f.repo.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7, AppointmentDate: time.Now(),
+			Status: domain.AppointmentStatusPaid, CommunicationMethod: domain.CommunicationMethodVideoCall}
+		if err := f.service.CheckCallAllowed(ctx, 1, 10, 70); err != nil {
+			t.Fatalf("call before removal: %v", err)
+		}
+
+		f.specialist.DeletedAt = &deletedAt
+		var notAllowed *CallNotAllowedError
+		err := f.service.CheckCallAllowed(ctx, 1, 10, 70)
+		if !errors.As(err, &notAllowed) || notAllowed.Reason != domain.CallNotAllowedSpecialistUnavailable {
+			t.Errorf("err = %v, want the call refused because the specialist is removed", err)
+		}
+	})
+
+	t.Run("chat", func(t *testing.T) {
+		specializationID := int64(3)
+		appointments := newFakeAppointmentRepo()
+		appointments.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7, SpecializationID: &specializationID,
+			Status: domain.AppointmentStatusPaid}
+		chats := &fakeChatRepo{sessions: make(map[int64]*domain.ChatSession)}
+		chat := NewChatService(&repository.Repositories{
+			Chat:        chats,
+			Appointment: appointments,
+			Specialist:  &fakeSpecialistRepo{specialist: &domain.Specialist{ID: 7, UserID: 70, DeletedAt: &deletedAt}},
+			BlockList:   &fakeBlockListRepo{},
+		}, cache.NewMemoryCache(10))
+
+		_, err := chat.CreateChatSession(ctx, domain.CreateChatSessionDTO{AppointmentID: 1, ClientID: 10, SpecialistID: 7})
+		if !errors.Is(err, ErrSpecialistUnavailable) || len(chats.sessions) != 0 {
+			t.Errorf("err = %v, sessions = %d; want ErrSpecialistUnavailable", err, len(chats.sessions))
+		}
+	})
+}
@@ -4,11 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"laps/config"
+	"laps/internal/cache"
 	"laps/internal/domain"
 	"laps/internal/repository"
+	"laps/internal/storage"
+)
+
+const (
+	// publicReviewsMinRating минимальная оценка отзыва для публичной ленты
+	publicReviewsMinRating = 4
+	// publicReviewsCacheTTL время жизни кэша публичной ленты отзывов
+	publicReviewsCacheTTL = 5 * time.Minute
+
+	defaultPublicReviewsLimit = 10
+	maxPublicReviewsLimit     = 50
 )
 
 type ReviewServiceImpl struct {
@@ -16,6 +31,11 @@ type ReviewServiceImpl struct {
 	specialistRepo  repository.SpecialistRepository
 	userRepo        repository.UserRepository
 	appointmentRepo repository.AppointmentRepository
+	fileStorage     storage.FileStorage
+	mediaCfg        config.ReviewMediaConfig
+	webhooks        WebhookPublisher
+	cache           cache.Cache
+	cacheTTL        time.Duration
 	logger          *zap.Logger
 }
 
@@ -24,6 +44,11 @@ func NewReviewService(
 	specialistRepo repository.SpecialistRepository,
 	userRepo repository.UserRepository,
 	appointmentRepo repository.AppointmentRepository,
+	fileStorage storage.FileStorage,
+	mediaCfg config.ReviewMediaConfig,
+	webhooks WebhookPublisher,
+	c cache.Cache,
+	cacheTTL time.Duration,
 	logger *zap.Logger,
 ) *ReviewServiceImpl {
 	return &ReviewServiceImpl{
@@ -31,6 +56,11 @@ func NewReviewService(
 		specialistRepo:  specialistRepo,
 		userRepo:        userRepo,
 		appointmentRepo: appointmentRepo,
+		fileStorage:     fileStorage,
+		mediaCfg:        mediaCfg,
+		webhooks:        webhooks,
+		cache:           c,
+		cacheTTL:        cacheTTL,
 		logger:          logger,
 	}
 }
@@ -42,7 +72,7 @@ func (s *ReviewServiceImpl) Create(ctx context.Context, clientID int64, dto doma
 		return 0, errors.New("пользователь не найден")
 	}
 
-	_, err = s.specialistRepo.GetByID(ctx, dto.SpecialistID)
+	specialist, err := s.specialistRepo.GetByID(ctx, dto.SpecialistID)
 	if err != nil {
 		s.logger.Error("специалист не найден при создании отзыва", zap.Int64("specialistID", dto.SpecialistID), zap.Error(err))
 		return 0, errors.New("специалист не найден")
@@ -54,6 +84,15 @@ func (s *ReviewServiceImpl) Create(ctx context.Context, clientID int64, dto doma
 		return 0, errors.New("прием не найден")
 	}
 
+	// Специалист не может оценить сам себя, даже если записан к себе как клиент
+	if specialist.UserID == clientID {
+		s.logger.Warn("попытка специалиста оставить отзыв о себе",
+			zap.Int64("userID", clientID),
+			zap.Int64("specialistID", specialist.ID),
+			zap.Int64("appointmentID", appointment.ID))
+		return 0, fmt.Errorf("%w: нельзя оставить отзыв о себе", ErrForbidden)
+	}
+
 	if appointment.ClientID != clientID || appointment.SpecialistID != dto.SpecialistID {
 		s.logger.Error("попытка создать отзыв для чужого приема",
 			zap.Int64("clientID", clientID),
@@ -92,20 +131,136 @@ func (s *ReviewServiceImpl) Create(ctx context.Context, clientID int64, dto doma
 		return 0, errors.New("рейтинг должен быть от 1 до 5")
 	}
 
+	if err := validateSubRatings(dto); err != nil {
+		s.logger.Warn("некорректная оценка по критерию", zap.Error(err))
+		return 0, err
+	}
+
+	dto.MediaURLs = uniqueStrings(dto.MediaURLs)
+	if len(dto.MediaURLs) > s.mediaCfg.MaxAttachments {
+		return 0, fmt.Errorf("%w: к отзыву можно приложить не более %d изображений", ErrInvalid, s.mediaCfg.MaxAttachments)
+	}
+
 	id, err := s.repo.Create(ctx, clientID, dto)
 	if err != nil {
+		if errors.Is(err, repository.ErrReviewMediaNotFound) {
+			return 0, fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+		}
 		s.logger.Error("ошибка создания отзыва", zap.Error(err))
 		return 0, errors.New("ошибка при создании отзыва")
 	}
 
-	err = s.UpdateSpecialistRating(ctx, dto.SpecialistID)
+	s.invalidateRatingCache(ctx, dto.SpecialistID)
+
+	s.webhooks.Publish(ctx, domain.WebhookEventReviewCreated, domain.ReviewWebhookData{
+		ReviewID:      id,
+		SpecialistID:  dto.SpecialistID,
+		ClientID:      clientID,
+		AppointmentID: dto.AppointmentID,
+		Rating:        dto.Rating,
+	})
+
+	return id, nil
+}
+
+// validateSubRatings проверяет, что каждая переданная оценка по отдельному критерию от 1 до 5.
+// Ошибка называет поле, чтобы клиент мог подсветить его в форме
+func validateSubRatings(dto domain.CreateReviewDTO) error {
+	subRatings := []struct {
+		field string
+		value *int
+	}{
+		{"service_rating", dto.ServiceRating},
+		{"meeting_efficiency", dto.MeetingEfficiency},
+		{"professionalism", dto.Professionalism},
+		{"price_quality", dto.PriceQuality},
+		{"cleanliness", dto.Cleanliness},
+		{"attentiveness", dto.Attentiveness},
+		{"specialist_experience", dto.SpecialistExperience},
+		{"grammar", dto.Grammar},
+	}
+
+	for _, rating := range subRatings {
+		if rating.value != nil && (*rating.value < 1 || *rating.value > 5) {
+			return fmt.Errorf("%w: оценка %s должна быть от 1 до 5, получено %d", ErrInvalid, rating.field, *rating.value)
+		}
+	}
+
+	return nil
+}
+
+// UploadMedia загружает изображение для будущего отзыва; ссылка на него передается в media_urls при создании отзыва
+func (s *ReviewServiceImpl) UploadMedia(ctx context.Context, clientID int64, data []byte, filename string) (*domain.ReviewMedia, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: пустой файл изображения", ErrInvalid)
+	}
+
+	if int64(len(data)) > s.mediaCfg.MaxFileSize {
+		return nil, fmt.Errorf("%w: размер изображения превышает %d байт", ErrInvalid, s.mediaCfg.MaxFileSize)
+	}
+
+	url, err := s.fileStorage.UploadFile(ctx, data, filename)
 	if err != nil {
-		s.logger.Error("ошибка обновления рейтинга специалиста",
-			zap.Int64("specialistID", dto.SpecialistID),
-			zap.Error(err))
+		s.logger.Error("ошибка загрузки изображения отзыва в хранилище", zap.Int64("clientID", clientID), zap.Error(err))
+		return nil, errors.New("ошибка загрузки изображения")
 	}
 
-	return id, nil
+	media, err := s.repo.CreateMedia(ctx, clientID, url)
+	if err != nil {
+		s.logger.Error("ошибка сохранения изображения отзыва", zap.Int64("clientID", clientID), zap.Error(err))
+
+		if deleteErr := s.fileStorage.DeleteFile(ctx, url); deleteErr != nil {
+			s.logger.Error("ошибка удаления изображения после неудачного сохранения",
+				zap.String("url", url), zap.Error(deleteErr))
+		}
+
+		return nil, errors.New("ошибка сохранения изображения")
+	}
+
+	return media, nil
+}
+
+// AddMedia загружает изображение и прикладывает его к уже опубликованному отзыву
+func (s *ReviewServiceImpl) AddMedia(ctx context.Context, reviewID, clientID int64, data []byte, filename string) (*domain.ReviewMedia, error) {
+	review, err := s.repo.GetByID(ctx, reviewID)
+	if err != nil {
+		s.logger.Error("отзыв не найден при загрузке изображения", zap.Int64("reviewID", reviewID), zap.Error(err))
+		return nil, fmt.Errorf("%w: отзыв не найден", ErrNotFound)
+	}
+
+	if len(review.Media) >= s.mediaCfg.MaxAttachments {
+		return nil, fmt.Errorf("%w: к отзыву можно приложить не более %d изображений", ErrInvalid, s.mediaCfg.MaxAttachments)
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: пустой файл изображения", ErrInvalid)
+	}
+
+	if int64(len(data)) > s.mediaCfg.MaxFileSize {
+		return nil, fmt.Errorf("%w: размер изображения превышает %d байт", ErrInvalid, s.mediaCfg.MaxFileSize)
+	}
+
+	url, err := s.fileStorage.UploadFile(ctx, data, filename)
+	if err != nil {
+		s.logger.Error("ошибка загрузки изображения отзыва в хранилище", zap.Int64("reviewID", reviewID), zap.Error(err))
+		return nil, errors.New("ошибка загрузки изображения")
+	}
+
+	media, err := s.repo.AddMedia(ctx, reviewID, clientID, url, s.mediaCfg.MaxAttachments)
+	if err != nil {
+		if deleteErr := s.fileStorage.DeleteFile(ctx, url); deleteErr != nil {
+			s.logger.Error("ошибка удаления изображения после неудачного сохранения",
+				zap.String("url", url), zap.Error(deleteErr))
+		}
+
+		if errors.Is(err, repository.ErrReviewMediaLimit) {
+			return nil, fmt.Errorf("%w: к отзыву можно приложить не более %d изображений", ErrInvalid, s.mediaCfg.MaxAttachments)
+		}
+		s.logger.Error("ошибка сохранения изображения отзыва", zap.Int64("reviewID", reviewID), zap.Error(err))
+		return nil, errors.New("ошибка сохранения изображения")
+	}
+
+	return media, nil
 }
 
 func (s *ReviewServiceImpl) GetByID(ctx context.Context, id int64) (*domain.Review, error) {
@@ -131,7 +286,7 @@ func (s *ReviewServiceImpl) GetByID(ctx context.Context, id int64) (*domain.Revi
 }
 
 func (s *ReviewServiceImpl) Update(ctx context.Context, id int64, dto domain.UpdateReviewDTO) error {
-	_, err := s.repo.GetByID(ctx, id)
+	review, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("отзыв для обновления не найден", zap.Int64("id", id), zap.Error(err))
 		return errors.New("отзыв не найден")
@@ -143,11 +298,16 @@ func (s *ReviewServiceImpl) Update(ctx context.Context, id int64, dto domain.Upd
 	}
 
 	err = s.repo.Update(ctx, id, dto)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return fmt.Errorf("%w: отзыв был изменен, обновите данные и повторите", ErrVersionConflict)
+	}
 	if err != nil {
 		s.logger.Error("ошибка обновления отзыва", zap.Int64("id", id), zap.Error(err))
 		return errors.New("ошибка при обновлении отзыва")
 	}
 
+	s.invalidateRatingCache(ctx, review.SpecialistID)
+
 	return nil
 }
 
@@ -166,13 +326,16 @@ func (s *ReviewServiceImpl) Delete(ctx context.Context, id int64) error {
 		return errors.New("ошибка при удалении отзыва")
 	}
 
-	err = s.UpdateSpecialistRating(ctx, specialistID)
-	if err != nil {
-		s.logger.Error("ошибка обновления рейтинга специалиста после удаления отзыва",
-			zap.Int64("specialistID", specialistID),
-			zap.Error(err))
+	// Записи review_media удаляются каскадно, файлы из хранилища удаляем отдельно
+	for _, media := range review.Media {
+		if err := s.fileStorage.DeleteFile(ctx, media.URL); err != nil {
+			s.logger.Error("ошибка удаления изображения отзыва из хранилища",
+				zap.Int64("reviewID", id), zap.String("url", media.URL), zap.Error(err))
+		}
 	}
 
+	s.invalidateRatingCache(ctx, specialistID)
+
 	return nil
 }
 
@@ -204,6 +367,70 @@ func (s *ReviewServiceImpl) GetBySpecialistID(ctx context.Context, specialistID
 	return reviews, count, nil
 }
 
+func (s *ReviewServiceImpl) GetRatingSummary(ctx context.Context, specialistID int64) (*domain.RatingSummary, error) {
+	cacheKey := ratingSummaryCacheKey(specialistID)
+	var cached domain.RatingSummary
+	if found, err := s.cache.Get(ctx, cacheKey, &cached); err != nil {
+		s.logger.Warn("ошибка чтения кэша рейтинга", zap.Int64("specialistID", specialistID), zap.Error(err))
+	} else if found {
+		return &cached, nil
+	}
+
+	_, err := s.specialistRepo.GetByID(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("специалист не найден при получении сводки рейтинга", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("специалист не найден")
+	}
+
+	summary, err := s.repo.GetRatingSummary(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("ошибка получения сводки рейтинга", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении сводки рейтинга")
+	}
+
+	if err := s.cache.Set(ctx, cacheKey, summary, s.cacheTTL); err != nil {
+		s.logger.Warn("ошибка записи кэша рейтинга", zap.Int64("specialistID", specialistID), zap.Error(err))
+	}
+
+	return summary, nil
+}
+
+// invalidateRatingCache сбрасывает сводку рейтинга специалиста, списки специалистов,
+// в которых отображаются rating и reviews_count, и публичную ленту отзывов
+func (s *ReviewServiceImpl) invalidateRatingCache(ctx context.Context, specialistID int64) {
+	invalidateCache(ctx, s.cache, s.logger, ratingSummaryCacheKey(specialistID), specialistsCachePrefix, reviewsCachePrefix)
+}
+
+// ListPublicRecent возвращает последние положительные отзывы для ленты на главной странице
+func (s *ReviewServiceImpl) ListPublicRecent(ctx context.Context, limit int) ([]domain.PublicReview, error) {
+	if limit <= 0 {
+		limit = defaultPublicReviewsLimit
+	}
+	if limit > maxPublicReviewsLimit {
+		limit = maxPublicReviewsLimit
+	}
+
+	cacheKey := publicRecentReviewsCacheKey(limit)
+	var cached []domain.PublicReview
+	if found, err := s.cache.Get(ctx, cacheKey, &cached); err != nil {
+		s.logger.Warn("ошибка чтения кэша публичной ленты отзывов", zap.Error(err))
+	} else if found {
+		return cached, nil
+	}
+
+	reviews, err := s.repo.ListPublicRecent(ctx, publicReviewsMinRating, limit)
+	if err != nil {
+		s.logger.Error("ошибка получения публичной ленты отзывов", zap.Error(err))
+		return nil, errors.New("ошибка при получении отзывов")
+	}
+
+	if err := s.cache.Set(ctx, cacheKey, reviews, publicReviewsCacheTTL); err != nil {
+		s.logger.Warn("ошибка записи кэша публичной ленты отзывов", zap.Error(err))
+	}
+
+	return reviews, nil
+}
+
 func (s *ReviewServiceImpl) GetByUserID(ctx context.Context, userID int64, limit, offset int) ([]domain.Review, error) {
 	_, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -321,22 +548,20 @@ func (s *ReviewServiceImpl) GetRepliesByReviewID(ctx context.Context, reviewID i
 	return replies, nil
 }
 
-func (s *ReviewServiceImpl) UpdateSpecialistRating(ctx context.Context, specialistID int64) error {
-	updateRatingQuery := `
-		UPDATE specialists
-		SET rating = (
-			SELECT COALESCE(AVG(rating), 0) FROM reviews WHERE specialist_id = $1
-		),
-		reviews_count = (
-			SELECT COUNT(*) FROM reviews WHERE specialist_id = $1
-		)
-		WHERE id = $1
-	`
-
-	_, err := s.specialistRepo.(*repository.SpecialistRepo).GetDB().Exec(ctx, updateRatingQuery, specialistID)
-	if err != nil {
-		return fmt.Errorf("ошибка обновления рейтинга специалиста: %w", err)
+// uniqueStrings убирает пустые значения и повторы, сохраняя исходный порядок
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
 	}
-
-	return nil
+	return result
 }
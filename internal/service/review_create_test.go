@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/cache"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// fakeCreateReviewRepo хранит созданные отзывы, чтобы сервис видел повторный отзыв о том же приеме
+type fakeCreateReviewRepo struct {
+	repository.ReviewRepository
+
+	created []domain.Review
+}
+
+func (r *fakeCreateReviewRepo) Create(ctx context.Context, clientID int64, dto domain.CreateReviewDTO) (int64, error) {
+	id := int64(len(r.created) + 1)
+	r.created = append(r.created, domain.Review{ID: id, ClientID: clientID, SpecialistID: dto.SpecialistID,
+		AppointmentID: dto.AppointmentID, Rating: dto.Rating})
+	return id, nil
+}
+
+func (r *fakeCreateReviewRepo) List(ctx context.Context, filter domain.ReviewFilter) ([]domain.Review, error) {
+	var reviews []domain.Review
+	for _, review := range r.created {
+		if filter.ClientID == nil || review.ClientID == *filter.ClientID {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (r *fakeCreateReviewRepo) CountByFilter(ctx context.Context, filter domain.ReviewFilter) (int, error) {
+	reviews, _ := r.List(ctx, filter)
+	return len(reviews), nil
+}
+
+// reviewCreateFixture специалист 7 (пользователь 70); прием 1 клиента 10 завершен, прием 2 еще не начался,
+// на приеме 3 специалист записан сам к себе
+type reviewCreateFixture struct {
+	repo    *fakeCreateReviewRepo
+	service *ReviewServiceImpl
+}
+
+func newReviewCreateFixture() *reviewCreateFixture {
+	appointments := newFakeAppointmentRepo()
+	appointments.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7, Status: domain.AppointmentStatusCompleted}
+	appointments.appointments[2] = &domain.Appointment{ID: 2, ClientID: 10, SpecialistID: 7, Status: domain.AppointmentStatusPaid}
+	appointments.appointments[3] = &domain.Appointment{ID: 3, ClientID: 70, SpecialistID: 7, Status: domain.AppointmentStatusCompleted}
+
+	f := &reviewCreateFixture{repo: &fakeCreateReviewRepo{}}
+	f.service = NewReviewService(f.repo, &fakeSpecialistRepo{specialist: &domain.Specialist{ID: 7, UserID: 70}}, &fakeUserRepo{},
+		appointments, nil, config.ReviewMediaConfig{MaxAttachments: 3}, NewWebhookBus(), cache.NewMemoryCache(100), time.Minute, zap.NewNop())
+	return f
+}
+
+func TestCreateReviewForbidsSelfReview(t *testing.T) {
+	f := newReviewCreateFixture()
+
+	_, err := f.service.Create(context.Background(), 70, domain.CreateReviewDTO{SpecialistID: 7, AppointmentID: 3, Rating: 5})
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("err = %v, want ErrForbidden", err)
+	}
+	if len(f.repo.created) != 0 {
+		t.Errorf("created = %+v, want no review", f.repo.created)
+	}
+}
+
+func TestCreateReviewRequiresOwnCompletedAppointment(t *testing.T) {
+	tests := []struct {
+		name          string
+		clientID      int64
+		appointmentID int64
+		wantCreated   bool
+	}{
+		{name: "completed appointment", clientID: 10, appointmentID: 1, wantCreated: true},
+		{name: "appointment not completed", clientID: 10, appointmentID: 2},
+		{name: "another client's appointment", clientID: 11, appointmentID: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newReviewCreateFixture()
+
+			id, err := f.service.Create(context.Background(), tt.clientID, domain.CreateReviewDTO{SpecialistID: 7, AppointmentID: tt.appointmentID, Rating: 5})
+			if tt.wantCreated {
+				if err != nil || id == 0 || len(f.repo.created) != 1 {
+					t.Errorf("Create() = %d, %v", id, err)
+				}
+				return
+			}
+			if err == nil || len(f.repo.created) != 0 {
+				t.Errorf("Create() = %d, %v; want the review refused", id, err)
+			}
+		})
+	}
+}
+
+func TestCreateReviewOncePerAppointment(t *testing.T) {
+	f := newReviewCreateFixture()
+	dto := domain.CreateReviewDTO{SpecialistID: 7, AppointmentID: 1, Rating: 4}
+
+	if _, err := f.service.Create(context.Background(), 10, dto); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Create(context.Background(), 10, dto); err == nil {
+		t.Error("second review of the same appointment was accepted")
+	}
+	if len(f.repo.created) != 1 {
+		t.Errorf("created = %d reviews, want 1", len(f.repo.created))
+	}
+}
+
+func TestCreateReviewSubRatings(t *testing.T) {
+	rating := func(v int) *int { return &v }
+	tests := []struct {
+		name      string
+		dto       domain.CreateReviewDTO
+		wantField string
+	}{
+		{name: "not rated", dto: domain.CreateReviewDTO{}},
+		{name: "bounds", dto: domain.CreateReviewDTO{ServiceRating: rating(1), Grammar: rating(5)}},
+		{name: "zero", dto: domain.CreateReviewDTO{Professionalism: rating(0)}, wantField: "professionalism"},
+		{name: "above five", dto: domain.CreateReviewDTO{PriceQuality: rating(6)}, wantField: "price_quality"},
+		{name: "negative", dto: domain.CreateReviewDTO{Attentiveness: rating(-1)}, wantField: "attentiveness"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newReviewCreateFixture()
+			dto := tt.dto
+			dto.SpecialistID, dto.AppointmentID, dto.Rating = 7, 1, 5
+
+			_, err := f.service.Create(context.Background(), 10, dto)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Create() err = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tt.wantField) || len(f.repo.created) != 0 {
+				t.Errorf("Create() err = %v, want ErrInvalid naming %s", err, tt.wantField)
+			}
+		})
+	}
+}
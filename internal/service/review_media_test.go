@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/cache"
+	"laps/internal/domain"
+	"laps/internal/repository"
+	"laps/internal/storage"
+)
+
+// fakeFileStorage выдает ссылки по порядку загрузки и запоминает удаленные файлы
+type fakeFileStorage struct {
+	storage.FileStorage
+
+	uploaded []string
+	deleted  []string
+}
+
+func (s *fakeFileStorage) UploadFile(ctx context.Context, data []byte, filename string) (string, error) {
+	url := fmt.Sprintf("https://files.test/%d/%s", len(s.uploaded)+1, filename)
+	s.uploaded = append(s.uploaded, url)
+	return url, nil
+}
+
+func (s *fakeFileStorage) DeleteFile(ctx context.Context, fileURL string) error {
+	s.deleted = append(s.deleted, fileURL)
+	return nil
+}
+
+// fakeMediaReviewRepo хранит один отзыв; saveErr возвращается при сохранении изображения
+type fakeMediaReviewRepo struct {
+	repository.ReviewRepository
+
+	review  domain.Review
+	saveErr error
+	deleted bool
+}
+
+func (r *fakeMediaReviewRepo) GetByID(ctx context.Context, id int64) (*domain.Review, error) {
+	if id != r.review.ID || r.deleted {
+		return nil, errors.New("отзыв не найден")
+	}
+	review := r.review
+	return &review, nil
+}
+
+func (r *fakeMediaReviewRepo) Delete(ctx context.Context, id int64) error {
+	r.deleted = true
+	return nil
+}
+
+func (r *fakeMediaReviewRepo) CreateMedia(ctx context.Context, uploadedBy int64, url string) (*domain.ReviewMedia, error) {
+	if r.saveErr != nil {
+		return nil, r.saveErr
+	}
+	return &domain.ReviewMedia{ID: 1, URL: url}, nil
+}
+
+func (r *fakeMediaReviewRepo) AddMedia(ctx context.Context, reviewID, uploadedBy int64, url string, maxAttachments int) (*domain.ReviewMedia, error) {
+	if r.saveErr != nil {
+		return nil, r.saveErr
+	}
+	media := domain.ReviewMedia{ID: int64(len(r.review.Media) + 1), URL: url}
+	r.review.Media = append(r.review.Media, media)
+	return &media, nil
+}
+
+func newMediaReviewTestService(repo repository.ReviewRepository, files storage.FileStorage) *ReviewServiceImpl {
+	return NewReviewService(repo, &fakeSpecialistRepo{}, &fakeUserRepo{}, newFakeAppointmentRepo(), files,
+		config.ReviewMediaConfig{MaxAttachments: 2, MaxFileSize: 10}, nil, cache.NewMemoryCache(100), time.Minute, zap.NewNop())
+}
+
+func TestAddMediaLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		attached    int
+		data        []byte
+		saveErr     error
+		wantErr     error
+		wantDeleted bool
+	}{
+		{name: "attached", data: []byte("jpeg")},
+		{name: "limit reached", attached: 2, data: []byte("jpeg"), wantErr: ErrInvalid},
+		{name: "empty file", data: nil, wantErr: ErrInvalid},
+		{name: "file too large", data: make([]byte, 11), wantErr: ErrInvalid},
+		// Параллельная загрузка заняла последнее место: файл уже в хранилище и должен быть удален
+		{name: "limit reached concurrently", data: []byte("jpeg"), saveErr: repository.ErrReviewMediaLimit,
+			wantErr: ErrInvalid, wantDeleted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeMediaReviewRepo{review: domain.Review{ID: 5, ClientID: 1}, saveErr: tt.saveErr}
+			for i := 0; i < tt.attached; i++ {
+				repo.review.Media = append(repo.review.Media, domain.ReviewMedia{ID: int64(i + 1)})
+			}
+			files := &fakeFileStorage{}
+
+			media, err := newMediaReviewTestService(repo, files).AddMedia(context.Background(), 5, 1, tt.data, "photo.jpg")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil || media == nil || media.URL != files.uploaded[0] {
+				t.Fatalf("AddMedia() = %+v, %v", media, err)
+			}
+
+			wantUploads := 0
+			if tt.wantErr == nil || tt.wantDeleted {
+				wantUploads = 1
+			}
+			if len(files.uploaded) != wantUploads {
+				t.Errorf("uploads = %d, want %d", len(files.uploaded), wantUploads)
+			}
+			if tt.wantDeleted != (len(files.deleted) == 1) {
+				t.Errorf("deleted files = %v", files.deleted)
+			}
+		})
+	}
+}
+
+func TestUploadMediaCleansUpOnFailure(t *testing.T) {
+	repo := &fakeMediaReviewRepo{saveErr: errors.New("connection reset")}
+	files := &fakeFileStorage{}
+
+	if _, err := newMediaReviewTestService(repo, files).UploadMedia(context.Background(), 1, []byte("jpeg"), "photo.jpg"); err == nil {
+		t.Fatal("UploadMedia() error = nil")
+	}
+	if len(files.uploaded) != 1 || len(files.deleted) != 1 || files.deleted[0] != files.uploaded[0] {
+		t.Errorf("uploaded = %v, deleted = %v; want the orphaned file removed", files.uploaded, files.deleted)
+	}
+}
+
+func TestReviewDeleteRemovesPhotos(t *testing.T) {
+	repo := &fakeMediaReviewRepo{review: domain.Review{ID: 5, ClientID: 1, SpecialistID: 7, Media: []domain.ReviewMedia{
+		{ID: 1, URL: "https://files.test/1/a.jpg"},
+		{ID: 2, URL: "https://files.test/2/b.jpg"},
+	}}}
+	files := &fakeFileStorage{}
+
+	if err := newMediaReviewTestService(repo, files).Delete(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.deleted {
+		t.Error("review was not deleted")
+	}
+	if len(files.deleted) != 2 || files.deleted[0] != "https://files.test/1/a.jpg" || files.deleted[1] != "https://files.test/2/b.jpg" {
+		t.Errorf("deleted files = %v, want both photos", files.deleted)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/cache"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// fakePublicReviewRepo отдает ленту и запоминает параметры последнего запроса
+type fakePublicReviewRepo struct {
+	repository.ReviewRepository
+
+	calls     int
+	minRating int
+	limit     int
+}
+
+func (r *fakePublicReviewRepo) ListPublicRecent(ctx context.Context, minRating, limit int) ([]domain.PublicReview, error) {
+	r.calls++
+	r.minRating, r.limit = minRating, limit
+	return []domain.PublicReview{{ID: int64(r.calls), Rating: 5, AuthorName: "Анна П."}}, nil
+}
+
+func newPublicReviewTestService(repo repository.ReviewRepository) *ReviewServiceImpl {
+	return NewReviewService(repo, &fakeSpecialistRepo{}, &fakeUserRepo{}, newFakeAppointmentRepo(), nil,
+		config.ReviewMediaConfig{}, nil, cache.NewMemoryCache(100), time.Minute, zap.NewNop())
+}
+
+func TestListPublicRecentLimits(t *testing.T) {
+	tests := []struct {
+		requested, want int
+	}{
+		{0, defaultPublicReviewsLimit},
+		{-5, defaultPublicReviewsLimit},
+		{3, 3},
+		{1000, maxPublicReviewsLimit},
+	}
+
+	for _, tt := range tests {
+		repo := &fakePublicReviewRepo{}
+		if _, err := newPublicReviewTestService(repo).ListPublicRecent(context.Background(), tt.requested); err != nil {
+			t.Fatal(err)
+		}
+		if repo.limit != tt.want || repo.minRating != publicReviewsMinRating {
+			t.Errorf("limit %d: repository got limit %d, min rating %d; want %d, %d",
+				tt.requested, repo.limit, repo.minRating, tt.want, publicReviewsMinRating)
+		}
+	}
+}
+
+func TestListPublicRecentCache(t *testing.T) {
+	repo := &fakePublicReviewRepo{}
+	reviews := newPublicReviewTestService(repo)
+	ctx := context.Background()
+
+	first, _ := reviews.ListPublicRecent(ctx, 10)
+	second, _ := reviews.ListPublicRecent(ctx, 10)
+	if repo.calls != 1 || len(second) != 1 || second[0].ID != first[0].ID {
+		t.Fatalf("repository reads = %d, want the repeat served from cache", repo.calls)
+	}
+
+	// Любое изменение отзыва сбрасывает ленту вместе со сводкой рейтинга
+	reviews.invalidateRatingCache(ctx, 7)
+	if third, _ := reviews.ListPublicRecent(ctx, 10); repo.calls != 2 || third[0].ID != 2 {
+		t.Errorf("repository reads = %d after a review change, want a fresh read", repo.calls)
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+)
+
+const (
+	// Как часто отправляются запланированные просьбы об отзыве
+	reviewRequestInterval = 5 * time.Minute
+	// Сколько просьб об отзыве отправляется за один проход
+	reviewRequestBatchSize = 100
+)
+
+// scheduleReviewRequest планирует просьбу об отзыве о только что завершенной записи через cfg.ReviewRequestDelay.
+// Запись уже завершена, поэтому ошибка только логируется
+func (s *AppointmentServiceImpl) scheduleReviewRequest(ctx context.Context, appointmentID int64) {
+	appointment, err := s.repo.GetByID(ctx, appointmentID)
+	if err != nil {
+		s.logger.Error("запись не найдена при планировании просьбы об отзыве", zap.Int64("appointmentID", appointmentID), zap.Error(err))
+		return
+	}
+
+	scheduled, err := s.reviewReqRepo.Schedule(ctx, appointment.ID, appointment.ClientID, time.Now().Add(s.cfg.ReviewRequestDelay))
+	if err != nil {
+		s.logger.Error("ошибка планирования просьбы об отзыве", zap.Int64("appointmentID", appointmentID), zap.Error(err))
+		return
+	}
+	if scheduled {
+		s.logger.Info("запланирована просьба об отзыве", zap.Int64("appointmentID", appointmentID))
+	}
+}
+
+// SendReviewRequests отправляет клиентам просьбы об отзыве, время которых наступило. Просьба не отправляется,
+// если клиент уже оставил отзыв о записи или специалист удален
+func (s *AppointmentServiceImpl) SendReviewRequests(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.SendReviewRequests")
+	defer span.End()
+
+	requests, err := s.reviewReqRepo.ClaimDue(ctx, reviewRequestBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("ошибка получения просьб об отзыве", zap.Error(err))
+		}
+		return
+	}
+
+	for _, request := range requests {
+		if request.Reviewed {
+			continue
+		}
+		s.sendReviewRequest(ctx, request)
+	}
+}
+
+func (s *AppointmentServiceImpl) sendReviewRequest(ctx context.Context, request domain.ReviewRequest) {
+	appointment, err := s.repo.GetByID(ctx, request.AppointmentID)
+	if err != nil {
+		s.logger.Warn("запись для просьбы об отзыве не найдена", zap.Int64("appointmentID", request.AppointmentID), zap.Error(err))
+		return
+	}
+
+	specialist, err := s.specialistRepo.GetByID(ctx, appointment.SpecialistID)
+	if err != nil {
+		s.logger.Info("специалист удален или не найден, просьба об отзыве не отправляется",
+			zap.Int64("appointmentID", appointment.ID), zap.Int64("specialistID", appointment.SpecialistID), zap.Error(err))
+		return
+	}
+	specialistName := strings.TrimSpace(specialist.User.FirstName + " " + specialist.User.LastName)
+
+	body := fmt.Sprintf("Консультация %s завершена", appointment.AppointmentDate.Format("02.01.2006 15:04"))
+	if specialistName != "" {
+		body += ", специалист: " + specialistName
+	}
+	body += ". Оставьте отзыв — он поможет другим клиентам"
+
+	err = s.notifier.Notify(ctx, domain.Notification{
+		UserID: request.ClientID,
+		Type:   domain.NotificationTypeReviewRequest,
+		Title:  "Оцените консультацию",
+		Body:   body,
+		Data: map[string]interface{}{
+			"appointment_id":   appointment.ID,
+			"specialist_id":    appointment.SpecialistID,
+			"specialist_name":  specialistName,
+			"appointment_date": appointment.AppointmentDate,
+			"deep_link":        fmt.Sprintf("%s/appointments/%d", strings.TrimRight(s.cfg.AppURL, "/"), appointment.ID),
+		},
+	})
+	if err != nil {
+		s.logger.Error("ошибка отправки просьбы об отзыве", zap.Int64("appointmentID", appointment.ID), zap.Error(err))
+	}
+}
+
+// RunReviewRequests периодически отправляет запланированные просьбы об отзыве, пока не отменен ctx
+func (s *AppointmentServiceImpl) RunReviewRequests(ctx context.Context) {
+	ticker := time.NewTicker(reviewRequestInterval)
+	defer ticker.Stop()
+
+	for {
+		s.SendReviewRequests(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListPendingReviews возвращает завершенные записи клиента, о которых он еще не оставил отзыв,
+// с именами участников, как в общем списке записей
+func (s *AppointmentServiceImpl) ListPendingReviews(ctx context.Context, clientID int64, limit, offset int) ([]domain.Appointment, int, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.ListPendingReviews")
+	defer span.End()
+
+	status := domain.AppointmentStatusCompleted
+	return s.List(ctx, domain.AppointmentFilter{
+		ClientID:      &clientID,
+		Status:        &status,
+		PendingReview: true,
+		Limit:         limit,
+		Offset:        offset,
+	})
+}
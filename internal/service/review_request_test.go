@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+)
+
+func (f *appointmentFixture) reviewRequestsSent() []domain.Notification {
+	var sent []domain.Notification
+	for _, notification := range f.notifier.sent {
+		if notification.Type == domain.NotificationTypeReviewRequest {
+			sent = append(sent, notification)
+		}
+	}
+	return sent
+}
+
+func TestReviewRequestAfterCallCompletion(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7,
+		AppointmentDate: time.Now().Add(-2 * time.Hour), Status: domain.AppointmentStatusPaid}
+	f.repo.completable = []int64{1}
+	ctx := context.Background()
+
+	f.service.CompleteCalledAppointments(ctx)
+	if f.repo.appointments[1].Status != domain.AppointmentStatusCompleted {
+		t.Fatalf("status = %s, want completed", f.repo.appointments[1].Status)
+	}
+
+	// Воркер может увидеть ту же запись повторно, просьба все равно одна
+	f.service.CompleteCalledAppointments(ctx)
+	if len(f.reviews.requests) != 1 {
+		t.Fatalf("scheduled = %d requests, want 1", len(f.reviews.requests))
+	}
+
+	f.service.SendReviewRequests(ctx)
+	f.service.SendReviewRequests(ctx)
+
+	sent := f.reviewRequestsSent()
+	if len(sent) != 1 {
+		t.Fatalf("sent = %d review requests, want 1", len(sent))
+	}
+	if sent[0].UserID != 10 || sent[0].Data["appointment_id"] != int64(1) || sent[0].Data["deep_link"] != "/appointments/1" {
+		t.Errorf("notification = %+v, want a deep link to appointment 1 for client 10", sent[0])
+	}
+}
+
+func TestReviewRequestSkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(f *appointmentFixture)
+	}{
+		{"reviewed before scheduling", func(f *appointmentFixture) { f.reviews.reviewed[1] = true }},
+		{"reviewed before sending", func(f *appointmentFixture) {
+			f.service.CompleteCalledAppointments(context.Background())
+			f.reviews.reviewed[1] = true
+		}},
+		{"specialist removed", func(f *appointmentFixture) {
+			deletedAt := time.Now()
+			f.specialist.DeletedAt = &deletedAt
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAppointmentFixture()
+			f.repo.appointments[1] = &domain.Appointment{ID: 1, ClientID: 10, SpecialistID: 7,
+				AppointmentDate: time.Now().Add(-2 * time.Hour), Status: domain.AppointmentStatusPaid}
+			f.repo.completable = []int64{1}
+			tt.prepare(f)
+
+			f.service.CompleteCalledAppointments(context.Background())
+			f.service.SendReviewRequests(context.Background())
+
+			if sent := f.reviewRequestsSent(); len(sent) != 0 {
+				t.Errorf("sent = %+v, want no review request", sent)
+			}
+		})
+	}
+}
+
+func TestListPendingReviewsFilter(t *testing.T) {
+	f := newAppointmentFixture()
+
+	if _, _, err := f.service.ListPendingReviews(context.Background(), 10, 20, 40); err != nil {
+		t.Fatal(err)
+	}
+	listed := f.repo.listFilter
+	if listed == nil || listed.ClientID == nil || *listed.ClientID != 10 || !listed.PendingReview ||
+		listed.Status == nil || *listed.Status != domain.AppointmentStatusCompleted || listed.Limit != 20 || listed.Offset != 40 {
+		t.Errorf("filter = %+v, want completed appointments of client 10 without a review", listed)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"time"
 
@@ -14,20 +15,26 @@ import (
 )
 
 type ScheduleServiceImpl struct {
-	repo           repository.ScheduleRepository
-	specialistRepo repository.SpecialistRepository
-	logger         *zap.Logger
+	repo            repository.ScheduleRepository
+	specialistRepo  repository.SpecialistRepository
+	appointmentRepo repository.AppointmentRepository
+	calendarRepo    repository.ExternalCalendarRepository
+	logger          *zap.Logger
 }
 
 func NewScheduleService(
 	repo repository.ScheduleRepository,
 	specialistRepo repository.SpecialistRepository,
+	appointmentRepo repository.AppointmentRepository,
+	calendarRepo repository.ExternalCalendarRepository,
 	logger *zap.Logger,
 ) *ScheduleServiceImpl {
 	return &ScheduleServiceImpl{
-		repo:           repo,
-		specialistRepo: specialistRepo,
-		logger:         logger,
+		repo:            repo,
+		specialistRepo:  specialistRepo,
+		appointmentRepo: appointmentRepo,
+		calendarRepo:    calendarRepo,
+		logger:          logger,
 	}
 }
 
@@ -43,6 +50,10 @@ func (s *ScheduleServiceImpl) Create(ctx context.Context, specialistID int64, dt
 		return 0, errors.New("длительность слота должна быть от 10 до 120 минут")
 	}
 
+	if err := validateSlotAlignment(dto.SlotTime, dto.SlotAlignment, dto.SlotOffset); err != nil {
+		return 0, err
+	}
+
 	now := time.Now()
 	startDate := now.AddDate(0, 0, -int(now.Weekday())+1)
 	var lastID int64
@@ -70,26 +81,32 @@ func (s *ScheduleServiceImpl) Create(ctx context.Context, specialistID int64, dt
 
 		if daySchedule != nil && len(daySchedule.WorkTime) > 0 {
 			for _, slot := range daySchedule.WorkTime {
-				_, err = time.Parse("15:04", slot.StartTime)
+				startTime, err := time.Parse("15:04", slot.StartTime)
 				if err != nil {
 					s.logger.Error("неверный формат времени начала", zap.Error(err))
 					return 0, errors.New("неверный формат времени начала")
 				}
 
-				_, err = time.Parse("15:04", slot.EndTime)
+				endTime, err := time.Parse("15:04", slot.EndTime)
 				if err != nil {
 					s.logger.Error("неверный формат времени окончания", zap.Error(err))
 					return 0, errors.New("неверный формат времени окончания")
 				}
 
+				if err := validateFirstSlotFits(startTime, endTime, dto.SlotTime, dto.SlotAlignment, dto.SlotOffset); err != nil {
+					return 0, err
+				}
+
 				schedule := domain.Schedule{
-					SpecialistID: specialistID,
-					Date:         currentDate,
-					StartTime:    slot.StartTime,
-					EndTime:      slot.EndTime,
-					SlotTime:     dto.SlotTime,
-					CreatedAt:    time.Now(),
-					UpdatedAt:    time.Now(),
+					SpecialistID:  specialistID,
+					Date:          currentDate,
+					StartTime:     slot.StartTime,
+					EndTime:       slot.EndTime,
+					SlotTime:      dto.SlotTime,
+					SlotAlignment: dto.SlotAlignment,
+					SlotOffset:    dto.SlotOffset,
+					CreatedAt:     time.Now(),
+					UpdatedAt:     time.Now(),
 				}
 
 				id, err := s.repo.Create(ctx, schedule)
@@ -114,33 +131,13 @@ func (s *ScheduleServiceImpl) GetByID(ctx context.Context, id int64) (*domain.Sc
 	return schedule, nil
 }
 
+// Update заменяет расписание специалиста на текущую неделю. Если в dto передана ExpectedVersion,
+// а неделю уже изменили, возвращается ErrVersionConflict
 func (s *ScheduleServiceImpl) Update(ctx context.Context, specialistID int64, dto domain.UpdateScheduleDTO) error {
 	now := time.Now()
 	startDate := now.AddDate(0, 0, -int(now.Weekday())+1)
 	endDate := startDate.AddDate(0, 0, 6)
 
-	filter := domain.ScheduleFilter{
-		SpecialistID: &specialistID,
-		StartDate:    &startDate,
-		EndDate:      &endDate,
-		Limit:        100,
-		Offset:       0,
-	}
-
-	schedules, _, err := s.repo.List(ctx, filter)
-	if err != nil {
-		s.logger.Error("ошибка получения расписаний", zap.Error(err))
-		return fmt.Errorf("ошибка получения расписаний: %w", err)
-	}
-
-	for _, schedule := range schedules {
-		err = s.repo.Delete(ctx, schedule.ID)
-		if err != nil {
-			s.logger.Error("ошибка удаления расписания", zap.Error(err))
-			return fmt.Errorf("ошибка удаления расписания: %w", err)
-		}
-	}
-
 	slotTime := 30
 	if dto.SlotTime != nil {
 		slotTime = *dto.SlotTime
@@ -151,6 +148,11 @@ func (s *ScheduleServiceImpl) Update(ctx context.Context, specialistID int64, dt
 		return errors.New("длительность слота должна быть от 10 до 120 минут")
 	}
 
+	if err := validateSlotAlignment(slotTime, dto.SlotAlignment, dto.SlotOffset); err != nil {
+		return err
+	}
+
+	var schedules []domain.Schedule
 	for i := 0; i < 7; i++ {
 		currentDate := startDate.AddDate(0, 0, i)
 		var daySchedule *domain.DaySchedule
@@ -174,37 +176,46 @@ func (s *ScheduleServiceImpl) Update(ctx context.Context, specialistID int64, dt
 
 		if daySchedule != nil && len(daySchedule.WorkTime) > 0 {
 			for _, slot := range daySchedule.WorkTime {
-				_, err = time.Parse("15:04", slot.StartTime)
+				startTime, err := time.Parse("15:04", slot.StartTime)
 				if err != nil {
 					s.logger.Error("неверный формат времени начала", zap.Error(err))
 					return errors.New("неверный формат времени начала")
 				}
 
-				_, err = time.Parse("15:04", slot.EndTime)
+				endTime, err := time.Parse("15:04", slot.EndTime)
 				if err != nil {
 					s.logger.Error("неверный формат времени окончания", zap.Error(err))
 					return errors.New("неверный формат времени окончания")
 				}
 
-				schedule := domain.Schedule{
-					SpecialistID: specialistID,
-					Date:         currentDate,
-					StartTime:    slot.StartTime,
-					EndTime:      slot.EndTime,
-					SlotTime:     slotTime,
-					CreatedAt:    time.Now(),
-					UpdatedAt:    time.Now(),
+				if err := validateFirstSlotFits(startTime, endTime, slotTime, dto.SlotAlignment, dto.SlotOffset); err != nil {
+					return err
 				}
 
-				_, err := s.repo.Create(ctx, schedule)
-				if err != nil {
-					s.logger.Error("ошибка создания расписания", zap.Error(err))
-					return fmt.Errorf("ошибка создания расписания: %w", err)
-				}
+				schedules = append(schedules, domain.Schedule{
+					SpecialistID:  specialistID,
+					Date:          currentDate,
+					StartTime:     slot.StartTime,
+					EndTime:       slot.EndTime,
+					SlotTime:      slotTime,
+					SlotAlignment: dto.SlotAlignment,
+					SlotOffset:    dto.SlotOffset,
+					CreatedAt:     time.Now(),
+					UpdatedAt:     time.Now(),
+				})
 			}
 		}
 	}
 
+	_, err := s.repo.ReplaceWeek(ctx, specialistID, startDate, endDate, dto.ExpectedVersion, schedules)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return fmt.Errorf("%w: расписание было изменено, обновите данные и повторите", ErrVersionConflict)
+	}
+	if err != nil {
+		s.logger.Error("ошибка обновления расписания", zap.Error(err))
+		return fmt.Errorf("ошибка обновления расписания: %w", err)
+	}
+
 	return nil
 }
 
@@ -242,27 +253,313 @@ func (s *ScheduleServiceImpl) GetBySpecialistAndDate(ctx context.Context, specia
 	return schedule, nil
 }
 
+// GenerateTimeSlots возвращает слоты расписания на дату без слотов, зарезервированных клиентами
 func (s *ScheduleServiceImpl) GenerateTimeSlots(ctx context.Context, specialistID int64, dateStr string) ([]string, error) {
-	schedule, err := s.GetBySpecialistAndDate(ctx, specialistID, dateStr)
+	slots, _, err := s.scheduledSlots(ctx, specialistID, dateStr)
+	if err != nil || len(slots) == 0 {
+		return slots, err
+	}
+
+	// Зарезервированные клиентами слоты недоступны, пока резервирование не истекло
+	heldSlots, err := s.appointmentRepo.GetHeldSlots(ctx, specialistID, dateStr)
+	if err != nil {
+		s.logger.Error("ошибка получения зарезервированных слотов", zap.Error(err))
+		return nil, fmt.Errorf("ошибка получения расписания: %w", err)
+	}
+
+	return excludeSlots(slots, heldSlots), nil
+}
+
+// scheduledSlots возвращает все слоты рабочего времени на дату с учетом исключений расписания и их длительность
+func (s *ScheduleServiceImpl) scheduledSlots(ctx context.Context, specialistID int64, dateStr string) ([]string, time.Duration, error) {
+	slots, duration, err := scheduledSlotsForDate(ctx, s.repo, specialistID, dateStr)
+	if err != nil {
+		s.logger.Error("ошибка получения слотов расписания", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, 0, err
+	}
+	return slots, duration, nil
+}
+
+// scheduledSlotsForDate возвращает слоты рабочего времени специалиста на дату и их длительность: исключение
+// на дату имеет приоритет над недельным расписанием, без расписания возвращается пустой список
+func scheduledSlotsForDate(ctx context.Context, repo repository.ScheduleRepository, specialistID int64, dateStr string) ([]string, time.Duration, error) {
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return nil, 0, errors.New("неверный формат даты")
+	}
+
+	override, schedule, err := scheduleForDate(ctx, repo, specialistID, date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return slotsForDay(override, schedule), slotDurationForDay(override, schedule), nil
+}
+
+// scheduleForDate возвращает исключение расписания на дату или, если его нет, недельное расписание этого дня
+func scheduleForDate(ctx context.Context, repo repository.ScheduleRepository, specialistID int64, date time.Time) (*domain.ScheduleOverride, *domain.Schedule, error) {
+	override, err := repo.GetOverride(ctx, specialistID, date)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка получения расписания: %w", err)
+	}
+	if override != nil {
+		return override, nil, nil
+	}
+
+	schedule, err := repo.GetBySpecialistAndDate(ctx, specialistID, date)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("ошибка получения расписания: %w", err)
+	}
+
+	return nil, schedule, nil
+}
+
+// Длительность слота, если у дня нет расписания с заданной длительностью
+// (например, для записи, расписание которой уже удалено)
+const defaultSlotDuration = time.Hour
+
+// slotDurationForDay возвращает длительность слотов дня: из исключения расписания, если оно есть, иначе из расписания
+func slotDurationForDay(override *domain.ScheduleOverride, schedule *domain.Schedule) time.Duration {
+	switch {
+	case override != nil && override.SlotTime > 0:
+		return time.Duration(override.SlotTime) * time.Minute
+	case override == nil && schedule != nil && schedule.SlotTime > 0:
+		return time.Duration(schedule.SlotTime) * time.Minute
+	}
+	return defaultSlotDuration
+}
+
+// slotsForDay строит слоты дня по исключению расписания или, если его нет, по расписанию
+func slotsForDay(override *domain.ScheduleOverride, schedule *domain.Schedule) []string {
+	if override != nil {
+		if override.IsDayOff {
+			return []string{}
+		}
+		return generateSlots(override.StartTime, override.EndTime, override.SlotTime, 0, 0, nil)
 	}
 
 	if schedule == nil {
-		return []string{}, nil
+		return []string{}
 	}
 
-	startTime, _ := time.Parse("15:04", schedule.StartTime)
-	endTime, _ := time.Parse("15:04", schedule.EndTime)
+	return generateSlots(schedule.StartTime, schedule.EndTime, schedule.SlotTime, schedule.SlotAlignment, schedule.SlotOffset, schedule.ExcludeTimes)
+}
+
+// excludeSlots возвращает слоты, которых нет в excluded
+func excludeSlots(slots, excluded []string) []string {
+	if len(excluded) == 0 {
+		return slots
+	}
+
+	skip := make(map[string]bool, len(excluded))
+	for _, slot := range excluded {
+		skip[slot] = true
+	}
+
+	result := make([]string, 0, len(slots))
+	for _, slot := range slots {
+		if !skip[slot] {
+			result = append(result, slot)
+		}
+	}
+
+	return result
+}
+
+// GetNextAvailableSlot ищет первый свободный слот, начиная с текущего момента, в пределах horizonDays дней.
+// Выходные, исключения и полностью занятые дни пропускаются; если слота нет, возвращается nil.
+func (s *ScheduleServiceImpl) GetNextAvailableSlot(ctx context.Context, specialistID int64, horizonDays int) (*domain.AvailableSlot, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	for i := 0; i < horizonDays; i++ {
+		dateStr := today.AddDate(0, 0, i).Format("2006-01-02")
+
+		slots, _, err := s.freeSlotsForDate(ctx, specialistID, dateStr, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(slots) == 0 {
+			continue
+		}
+
+		slotTime, _ := time.ParseInLocation("2006-01-02 15:04", dateStr+" "+slots[0], now.Location())
+		return &domain.AvailableSlot{
+			SpecialistID: specialistID,
+			Date:         dateStr,
+			Time:         slots[0],
+			DateTime:     slotTime,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// GetAvailability возвращает свободное время специалиста по дням в диапазоне [from, to].
+// Дни без расписания, выходные и дни-исключения в ответ не попадают; полностью занятые дни
+// возвращаются с нулевым количеством слотов. Сами слоты заполняются только при withSlots
+func (s *ScheduleServiceImpl) GetAvailability(ctx context.Context, specialistID int64, from, to time.Time, withSlots bool) ([]domain.DayAvailability, error) {
+	now := time.Now()
+
+	days := make([]domain.DayAvailability, 0)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dateStr := day.Format("2006-01-02")
+
+		slots, scheduled, err := s.freeSlotsForDate(ctx, specialistID, dateStr, now)
+		if err != nil {
+			return nil, err
+		}
+		if !scheduled {
+			continue
+		}
+
+		availability := domain.DayAvailability{
+			Date:      dateStr,
+			FreeSlots: len(slots),
+		}
+		if withSlots {
+			availability.Slots = append([]string{}, slots...)
+		}
+		days = append(days, availability)
+	}
+
+	return days, nil
+}
+
+// GetMonthCalendar возвращает статус каждого дня месяца (ключ — дата YYYY-MM-DD): "free", если остался
+// хотя бы один свободный слот, "booked", если все слоты заняты или прошли, и "no_schedule" для дней без
+// расписания, выходных и дней-исключений. Расписание, исключения и занятые слоты читаются за месяц целиком
+func (s *ScheduleServiceImpl) GetMonthCalendar(ctx context.Context, specialistID int64, year, month int) (map[string]string, error) {
+	if month < 1 || month > 12 || year < 1 || year > 9999 {
+		return nil, fmt.Errorf("%w: неверный год или месяц", ErrInvalid)
+	}
+
+	now := time.Now()
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, now.Location())
+	end := start.AddDate(0, 1, -1)
+
+	schedules, _, err := s.repo.List(ctx, domain.ScheduleFilter{
+		SpecialistID: &specialistID,
+		StartDate:    &start,
+		EndDate:      &end,
+		Limit:        end.Day(),
+	})
+	if err != nil {
+		s.logger.Error("ошибка получения расписания за месяц", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении календаря")
+	}
+
+	overrides, err := s.repo.ListOverrides(ctx, specialistID, start, end)
+	if err != nil {
+		s.logger.Error("ошибка получения исключений расписания за месяц", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении календаря")
+	}
+
+	booked, err := s.appointmentRepo.GetBookedSlotsInRange(ctx, specialistID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		s.logger.Error("ошибка получения занятых слотов за месяц", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении календаря")
+	}
+
+	slotsByDate := make(map[string][]string, len(schedules))
+	for _, schedule := range schedules {
+		slotsByDate[schedule.Date.Format("2006-01-02")] = generateSlots(schedule.StartTime, schedule.EndTime, schedule.SlotTime, schedule.SlotAlignment, schedule.SlotOffset, schedule.ExcludeTimes)
+	}
+	// Исключение на дату заменяет обычное расписание этого дня
+	for _, override := range overrides {
+		dateStr := override.Date.Format("2006-01-02")
+		if override.IsDayOff {
+			delete(slotsByDate, dateStr)
+			continue
+		}
+		slotsByDate[dateStr] = generateSlots(override.StartTime, override.EndTime, override.SlotTime, 0, 0, nil)
+	}
+
+	calendar := make(map[string]string, end.Day())
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dateStr := day.Format("2006-01-02")
+
+		slots := slotsByDate[dateStr]
+		if len(slots) == 0 {
+			calendar[dateStr] = domain.CalendarDayNoSchedule
+			continue
+		}
+
+		calendar[dateStr] = domain.CalendarDayBooked
+		for _, slot := range excludeSlots(slots, booked[dateStr]) {
+			slotTime, err := time.ParseInLocation("2006-01-02 15:04", dateStr+" "+slot, now.Location())
+			if err == nil && slotTime.After(now) {
+				calendar[dateStr] = domain.CalendarDayFree
+				break
+			}
+		}
+	}
+
+	return calendar, nil
+}
+
+// freeSlotsForDate возвращает слоты даты, которые не заняты записями, резервированиями и внешним календарем
+// и еще не прошли; scheduled сообщает, работает ли специалист в этот день
+func (s *ScheduleServiceImpl) freeSlotsForDate(ctx context.Context, specialistID int64, dateStr string, now time.Time) ([]string, bool, error) {
+	slots, slotDuration, err := s.scheduledSlots(ctx, specialistID, dateStr)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(slots) == 0 {
+		return nil, false, nil
+	}
+
+	bookedSlots, err := s.appointmentRepo.GetBookedSlots(ctx, specialistID, dateStr)
+	if err != nil {
+		s.logger.Error("ошибка получения занятых слотов", zap.Error(err))
+		return nil, false, errors.New("ошибка при проверке доступности времени")
+	}
+
+	booked := make(map[string]bool, len(bookedSlots))
+	for _, slot := range bookedSlots {
+		booked[slot] = true
+	}
+
+	blocks, err := externalBlocksForDate(ctx, s.calendarRepo, specialistID, dateStr, now.Location())
+	if err != nil {
+		s.logger.Error("ошибка получения занятости из внешнего календаря", zap.Error(err))
+		return nil, false, errors.New("ошибка при проверке доступности времени")
+	}
+
+	var free []string
+	for _, slot := range filterExternallyBusy(slots, dateStr, now.Location(), slotDuration, blocks) {
+		if booked[slot] {
+			continue
+		}
+
+		slotTime, err := time.ParseInLocation("2006-01-02 15:04", dateStr+" "+slot, now.Location())
+		if err != nil || !slotTime.After(now) {
+			continue
+		}
+
+		free = append(free, slot)
+	}
+
+	return free, true, nil
+}
+
+// generateSlots возвращает начала слотов длительностью slotTime минут с start до end.
+// Первый слот выравнивается по alignment и offset (см. alignSlotStart)
+func generateSlots(start, end string, slotTime, alignment, offset int, excludeTimes []string) []string {
+	startTime, _ := time.Parse("15:04", start)
+	endTime, _ := time.Parse("15:04", end)
 
 	excludedSlots := make(map[string]bool)
-	for _, excludeTime := range schedule.ExcludeTimes {
+	for _, excludeTime := range excludeTimes {
 		excludedSlots[excludeTime] = true
 	}
 
 	var slots []string
-	currentTime := startTime
-	duration := time.Duration(schedule.SlotTime) * time.Minute
+	currentTime := alignSlotStart(startTime, alignment, offset)
+	duration := time.Duration(slotTime) * time.Minute
+	if duration <= 0 {
+		return slots
+	}
 
 	for currentTime.Before(endTime) {
 		timeStr := currentTime.Format("15:04")
@@ -276,10 +573,122 @@ func (s *ScheduleServiceImpl) GenerateTimeSlots(ctx context.Context, specialistI
 
 	sort.Strings(slots)
 
-	return slots, nil
+	return slots
+}
+
+// alignSlotStart возвращает начало первого слота. Без выравнивания это start, сдвинутый на offset минут,
+// иначе — ближайшее не раньше start время, у которого минуты от начала суток минус offset кратны alignment:
+// при начале 09:07 и alignment 30 первый слот начнется в 09:30, при offset 5 — в 09:35
+func alignSlotStart(start time.Time, alignment, offset int) time.Time {
+	if alignment <= 0 {
+		return start.Add(time.Duration(offset) * time.Minute)
+	}
+
+	minutes := start.Hour()*60 + start.Minute()
+	remainder := ((minutes-offset)%alignment + alignment) % alignment
+	if remainder == 0 {
+		return start
+	}
+	return start.Add(time.Duration(alignment-remainder) * time.Minute)
+}
+
+// validateSlotAlignment проверяет, что кратность выравнивания допустима, а сдвиг меньше кратности
+// или, без выравнивания, длительности слота
+func validateSlotAlignment(slotTime, alignment, offset int) error {
+	if !slices.Contains(domain.SlotAlignments, alignment) {
+		return fmt.Errorf("%w: выравнивание слотов должно быть одним из значений %v минут", ErrInvalid, domain.SlotAlignments)
+	}
+
+	limit := alignment
+	if limit == 0 {
+		limit = slotTime
+	}
+	if offset < 0 || offset >= limit {
+		return fmt.Errorf("%w: сдвиг начала слотов должен быть от 0 до %d минут", ErrInvalid, limit-1)
+	}
+
+	return nil
+}
+
+// validateFirstSlotFits проверяет, что после выравнивания хотя бы один слот целиком помещается в рабочее время.
+// Без выравнивания и сдвига слоты, как и раньше, начинаются ровно с начала рабочего времени
+func validateFirstSlotFits(start, end time.Time, slotTime, alignment, offset int) error {
+	if alignment == 0 && offset == 0 {
+		return nil
+	}
+
+	first := alignSlotStart(start, alignment, offset)
+	if first.Add(time.Duration(slotTime) * time.Minute).After(end) {
+		return fmt.Errorf("%w: с выбранным выравниванием слоты не помещаются в рабочее время %s-%s",
+			ErrInvalid, start.Format("15:04"), end.Format("15:04"))
+	}
+	return nil
+}
+
+func (s *ScheduleServiceImpl) SetOverride(ctx context.Context, specialistID int64, dateStr string, dto domain.SetScheduleOverrideDTO) (*domain.ScheduleOverride, error) {
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		s.logger.Error("неверный формат даты", zap.Error(err))
+		return nil, errors.New("неверный формат даты")
+	}
+
+	override := domain.ScheduleOverride{
+		SpecialistID: specialistID,
+		Date:         date,
+		IsDayOff:     dto.IsDayOff,
+	}
+
+	if !dto.IsDayOff {
+		startTime, err := time.Parse("15:04", dto.StartTime)
+		if err != nil {
+			return nil, errors.New("неверный формат времени начала")
+		}
+
+		endTime, err := time.Parse("15:04", dto.EndTime)
+		if err != nil {
+			return nil, errors.New("неверный формат времени окончания")
+		}
+
+		if !startTime.Before(endTime) {
+			return nil, errors.New("время начала должно быть раньше времени окончания")
+		}
+
+		if dto.SlotTime < 10 || dto.SlotTime > 120 {
+			return nil, errors.New("длительность слота должна быть от 10 до 120 минут")
+		}
+
+		override.StartTime = dto.StartTime
+		override.EndTime = dto.EndTime
+		override.SlotTime = dto.SlotTime
+	}
+
+	saved, err := s.repo.SetOverride(ctx, override)
+	if err != nil {
+		s.logger.Error("ошибка сохранения исключения расписания", zap.Error(err))
+		return nil, fmt.Errorf("ошибка сохранения исключения расписания: %w", err)
+	}
+
+	return saved, nil
 }
 
-func (s *ScheduleServiceImpl) GetWeekSchedule(ctx context.Context, specialistID int64, startDate time.Time) (*domain.WeekSchedule, int, error) {
+func (s *ScheduleServiceImpl) DeleteOverride(ctx context.Context, specialistID int64, dateStr string) error {
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		s.logger.Error("неверный формат даты", zap.Error(err))
+		return errors.New("неверный формат даты")
+	}
+
+	if err := s.repo.DeleteOverride(ctx, specialistID, date); err != nil {
+		s.logger.Error("ошибка удаления исключения расписания", zap.Error(err))
+		return fmt.Errorf("ошибка удаления исключения расписания: %w", err)
+	}
+
+	return nil
+}
+
+// GetWeekSchedule возвращает недельное расписание, длительность слота и версию недели,
+// которую нужно передать в expected_version при обновлении расписания
+func (s *ScheduleServiceImpl) GetWeekSchedule(ctx context.Context, specialistID int64, startDate time.Time) (*domain.WeekSchedule, int, int, error) {
 	endDate := startDate.AddDate(0, 0, 6)
 
 	filter := domain.ScheduleFilter{
@@ -293,31 +702,48 @@ func (s *ScheduleServiceImpl) GetWeekSchedule(ctx context.Context, specialistID
 	schedules, _, err := s.repo.List(ctx, filter)
 	if err != nil {
 		s.logger.Error("ошибка получения расписаний", zap.Error(err))
-		return nil, 0, fmt.Errorf("ошибка получения расписаний: %w", err)
+		return nil, 0, 0, fmt.Errorf("ошибка получения расписаний: %w", err)
+	}
+
+	overrides, err := s.repo.ListOverrides(ctx, specialistID, startDate, endDate)
+	if err != nil {
+		s.logger.Error("ошибка получения исключений расписания", zap.Error(err))
+		return nil, 0, 0, fmt.Errorf("ошибка получения расписаний: %w", err)
 	}
 
 	weekSchedule := domain.WeekSchedule{}
-	var slotTime int
+	var slotTime, version int
 
-	schedulesByDay := make(map[int][]domain.Schedule)
+	workTimeByDay := make(map[int][]domain.WorkTimeSlot)
 	for _, schedule := range schedules {
-		dayOfWeek := int(schedule.Date.Weekday())
-		if dayOfWeek == 0 {
-			dayOfWeek = 7
-		}
-		schedulesByDay[dayOfWeek] = append(schedulesByDay[dayOfWeek], schedule)
+		dayOfWeek := isoWeekday(schedule.Date)
+		workTimeByDay[dayOfWeek] = append(workTimeByDay[dayOfWeek], domain.WorkTimeSlot{
+			StartTime: schedule.StartTime,
+			EndTime:   schedule.EndTime,
+		})
 		slotTime = schedule.SlotTime
+		if schedule.Version > version {
+			version = schedule.Version
+		}
 	}
 
-	for day, daySchedules := range schedulesByDay {
-		workTimeSlots := make([]domain.WorkTimeSlot, 0, len(daySchedules))
-		for _, schedule := range daySchedules {
-			workTimeSlots = append(workTimeSlots, domain.WorkTimeSlot{
-				StartTime: schedule.StartTime,
-				EndTime:   schedule.EndTime,
-			})
+	// Исключения на конкретные даты заменяют недельное расписание этого дня
+	for _, override := range overrides {
+		dayOfWeek := isoWeekday(override.Date)
+		if override.IsDayOff {
+			delete(workTimeByDay, dayOfWeek)
+			continue
+		}
+		workTimeByDay[dayOfWeek] = []domain.WorkTimeSlot{{
+			StartTime: override.StartTime,
+			EndTime:   override.EndTime,
+		}}
+		if slotTime == 0 {
+			slotTime = override.SlotTime
 		}
+	}
 
+	for day, workTimeSlots := range workTimeByDay {
 		daySchedule := &domain.DaySchedule{
 			WorkTime: workTimeSlots,
 		}
@@ -340,5 +766,146 @@ func (s *ScheduleServiceImpl) GetWeekSchedule(ctx context.Context, specialistID
 		}
 	}
 
-	return &weekSchedule, slotTime, nil
+	return &weekSchedule, slotTime, version, nil
+}
+
+// isoWeekday возвращает номер дня недели, где понедельник - 1, воскресенье - 7
+func isoWeekday(date time.Time) int {
+	day := int(date.Weekday())
+	if day == 0 {
+		return 7
+	}
+	return day
+}
+
+// Клонирование расписания на следующую неделю запускается по воскресеньям в 20:00 UTC
+const (
+	weeklyCloneWeekday = time.Sunday
+	weeklyCloneHour    = 20
+)
+
+// CloneScheduleToNextWeek копирует расписание текущей недели (понедельник–воскресенье, UTC)
+// на следующую неделю. Дни, на которые уже есть расписание, не изменяются
+func (s *ScheduleServiceImpl) CloneScheduleToNextWeek(ctx context.Context, specialistID int64) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	nextWeekStart := weekStart.AddDate(0, 0, 7)
+
+	current, err := s.listWeek(ctx, specialistID, weekStart)
+	if err != nil {
+		s.logger.Error("ошибка получения расписания текущей недели", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return fmt.Errorf("ошибка получения расписания: %w", err)
+	}
+	if len(current) == 0 {
+		return nil
+	}
+
+	next, err := s.listWeek(ctx, specialistID, nextWeekStart)
+	if err != nil {
+		s.logger.Error("ошибка получения расписания следующей недели", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return fmt.Errorf("ошибка получения расписания: %w", err)
+	}
+
+	occupied := make(map[string]bool, len(next))
+	for _, schedule := range next {
+		occupied[schedule.Date.Format("2006-01-02")] = true
+	}
+
+	created := 0
+	for _, schedule := range current {
+		date := schedule.Date.AddDate(0, 0, 7)
+		if occupied[date.Format("2006-01-02")] {
+			continue
+		}
+
+		copied := domain.Schedule{
+			SpecialistID:  specialistID,
+			Date:          date,
+			StartTime:     schedule.StartTime,
+			EndTime:       schedule.EndTime,
+			SlotTime:      schedule.SlotTime,
+			SlotAlignment: schedule.SlotAlignment,
+			SlotOffset:    schedule.SlotOffset,
+			ExcludeTimes:  schedule.ExcludeTimes,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+
+		if _, err := s.repo.Create(ctx, copied); err != nil {
+			s.logger.Error("ошибка копирования расписания", zap.Int64("specialistID", specialistID), zap.Error(err))
+			return fmt.Errorf("ошибка создания расписания: %w", err)
+		}
+		created++
+	}
+
+	if created > 0 {
+		s.logger.Info("расписание скопировано на следующую неделю",
+			zap.Int64("specialistID", specialistID),
+			zap.String("week_start", nextWeekStart.Format("2006-01-02")),
+			zap.Int("created", created))
+	}
+
+	return nil
+}
+
+func (s *ScheduleServiceImpl) listWeek(ctx context.Context, specialistID int64, weekStart time.Time) ([]domain.Schedule, error) {
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	schedules, _, err := s.repo.List(ctx, domain.ScheduleFilter{
+		SpecialistID: &specialistID,
+		StartDate:    &weekStart,
+		EndDate:      &weekEnd,
+		Limit:        1000,
+	})
+	return schedules, err
+}
+
+// RunWeeklyClone каждое воскресенье в 20:00 UTC копирует расписания всех активных
+// специалистов на следующую неделю, пока не отменен ctx
+func (s *ScheduleServiceImpl) RunWeeklyClone(ctx context.Context) {
+	for {
+		timer := time.NewTimer(time.Until(nextWeeklyCloneRun(time.Now())))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.cloneAllToNextWeek(ctx)
+	}
+}
+
+func (s *ScheduleServiceImpl) cloneAllToNextWeek(ctx context.Context) {
+	ids, err := s.specialistRepo.ListActiveIDs(ctx)
+	if err != nil {
+		s.logger.Error("ошибка получения активных специалистов", zap.Error(err))
+		return
+	}
+
+	failed := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.CloneScheduleToNextWeek(ctx, id); err != nil {
+			failed++
+		}
+	}
+
+	s.logger.Info("еженедельное копирование расписаний завершено",
+		zap.Int("specialists", len(ids)),
+		zap.Int("failed", failed))
+}
+
+// nextWeeklyCloneRun возвращает ближайший момент запуска копирования после now
+func nextWeeklyCloneRun(now time.Time) time.Time {
+	now = now.UTC()
+	daysAhead := (int(weeklyCloneWeekday) - int(now.Weekday()) + 7) % 7
+	next := time.Date(now.Year(), now.Month(), now.Day()+daysAhead, weeklyCloneHour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
 }
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateSlotsAlignment(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		slotTime  int
+		alignment int
+		offset    int
+		exclude   []string
+		want      string
+	}{
+		{name: "no alignment starts at work start", start: "09:07", end: "11:00", slotTime: 30,
+			want: "09:07,09:37,10:07,10:37"},
+		{name: "aligned to half an hour", start: "09:07", end: "11:00", slotTime: 30, alignment: 30,
+			want: "09:30,10:00,10:30"},
+		{name: "aligned with offset", start: "09:07", end: "11:00", slotTime: 30, alignment: 30, offset: 5,
+			want: "09:35,10:05,10:35"},
+		{name: "offset without alignment", start: "09:00", end: "11:00", slotTime: 60, offset: 15,
+			want: "09:15,10:15"},
+		{name: "start already on the grid", start: "09:15", end: "10:00", slotTime: 15, alignment: 15,
+			want: "09:15,09:30,09:45"},
+		{name: "hourly grid", start: "09:07", end: "12:15", slotTime: 45, alignment: 60,
+			want: "10:00,10:45,11:30"},
+		{name: "excluded slot", start: "09:07", end: "11:00", slotTime: 30, alignment: 30, exclude: []string{"10:00"},
+			want: "09:30,10:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generateSlots(tt.start, tt.end, tt.slotTime, tt.alignment, tt.offset, tt.exclude)
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("slots = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSlotAlignment(t *testing.T) {
+	tests := []struct {
+		name      string
+		slotTime  int
+		alignment int
+		offset    int
+		wantErr   bool
+	}{
+		{name: "disabled", slotTime: 30},
+		{name: "quarter hour with offset", slotTime: 30, alignment: 15, offset: 14},
+		{name: "offset below slot time without alignment", slotTime: 30, offset: 29},
+		{name: "unsupported alignment", slotTime: 30, alignment: 7, wantErr: true},
+		{name: "offset equals alignment", slotTime: 30, alignment: 15, offset: 15, wantErr: true},
+		{name: "offset equals slot time", slotTime: 30, offset: 30, wantErr: true},
+		{name: "negative offset", slotTime: 30, alignment: 30, offset: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSlotAlignment(tt.slotTime, tt.alignment, tt.offset)
+			if tt.wantErr != errors.Is(err, ErrInvalid) || !tt.wantErr && err != nil {
+				t.Errorf("validateSlotAlignment() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFirstSlotFits(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	// Окно 09:07-10:00: получасовой слот с 09:30 помещается, а со сдвигом на 5 минут уже нет
+	if err := validateFirstSlotFits(at("09:07"), at("10:00"), 30, 30, 0); err != nil {
+		t.Errorf("09:30-10:00 fits, got %v", err)
+	}
+	if err := validateFirstSlotFits(at("09:07"), at("10:00"), 30, 30, 5); !errors.Is(err, ErrInvalid) {
+		t.Errorf("09:35-10:05 does not fit, got %v", err)
+	}
+	if err := validateFirstSlotFits(at("09:07"), at("10:00"), 60, 0, 0); err != nil {
+		t.Errorf("without alignment the work start is kept as is, got %v", err)
+	}
+}
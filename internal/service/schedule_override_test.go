@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+)
+
+// Суббота, на которую у специалиста есть недельное расписание с 9 до 12
+const overrideDate = "2026-03-07"
+
+func newOverrideTestSchedules(repo *fakeScheduleRepo) *ScheduleServiceImpl {
+	return NewScheduleService(repo, &fakeSpecialistRepo{}, newFakeAppointmentRepo(), &fakeCalendarRepo{}, zap.NewNop())
+}
+
+func TestGenerateTimeSlotsOverrideWins(t *testing.T) {
+	repo := &fakeScheduleRepo{schedule: &domain.Schedule{StartTime: "09:00", EndTime: "12:00", SlotTime: 60}}
+	schedules := newOverrideTestSchedules(repo)
+	ctx := context.Background()
+
+	slots := func() string {
+		t.Helper()
+		got, err := schedules.GenerateTimeSlots(ctx, 7, overrideDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(got, ",")
+	}
+
+	if got := slots(); got != "09:00,10:00,11:00" {
+		t.Fatalf("weekly slots = %s", got)
+	}
+
+	if _, err := schedules.SetOverride(ctx, 7, overrideDate, domain.SetScheduleOverrideDTO{StartTime: "10:00", EndTime: "16:00", SlotTime: 120}); err != nil {
+		t.Fatal(err)
+	}
+	if got := slots(); got != "10:00,12:00,14:00" {
+		t.Errorf("slots with extended hours = %s, want the override's 10:00,12:00,14:00", got)
+	}
+
+	if _, err := schedules.SetOverride(ctx, 7, overrideDate, domain.SetScheduleOverrideDTO{IsDayOff: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := slots(); got != "" {
+		t.Errorf("slots on a day off = %s, want none", got)
+	}
+
+	if err := schedules.DeleteOverride(ctx, 7, overrideDate); err != nil {
+		t.Fatal(err)
+	}
+	if got := slots(); got != "09:00,10:00,11:00" {
+		t.Errorf("slots after clearing the override = %s, want the weekly schedule back", got)
+	}
+}
+
+func TestGetWeekScheduleOverrideWins(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	repo := &fakeScheduleRepo{
+		week: []domain.Schedule{
+			{Date: day(2), StartTime: "09:00", EndTime: "18:00", SlotTime: 60, Version: 2},
+			{Date: day(3), StartTime: "09:00", EndTime: "18:00", SlotTime: 60, Version: 2},
+			{Date: day(7), StartTime: "09:00", EndTime: "12:00", SlotTime: 60, Version: 2},
+		},
+		overrides: []domain.ScheduleOverride{
+			{Date: day(3), IsDayOff: true},
+			{Date: day(7), StartTime: "10:00", EndTime: "16:00", SlotTime: 60},
+		},
+	}
+
+	week, slotTime, version, err := newOverrideTestSchedules(repo).GetWeekSchedule(context.Background(), 7, day(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slotTime != 60 || version != 2 {
+		t.Errorf("slot time = %d, version = %d", slotTime, version)
+	}
+
+	if week.Monday == nil || week.Monday.WorkTime[0].StartTime != "09:00" {
+		t.Errorf("Monday = %+v, want the weekly hours", week.Monday)
+	}
+	if week.Tuesday != nil {
+		t.Errorf("Tuesday = %+v, want a day off", week.Tuesday)
+	}
+	want := domain.WorkTimeSlot{StartTime: "10:00", EndTime: "16:00"}
+	if week.Saturday == nil || len(week.Saturday.WorkTime) != 1 || week.Saturday.WorkTime[0] != want {
+		t.Errorf("Saturday = %+v, want the override %+v", week.Saturday, want)
+	}
+}
+
+func TestSetOverrideValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		date string
+		dto  domain.SetScheduleOverrideDTO
+	}{
+		{"bad date", "07.03.2026", domain.SetScheduleOverrideDTO{IsDayOff: true}},
+		{"bad start", overrideDate, domain.SetScheduleOverrideDTO{StartTime: "9am", EndTime: "16:00", SlotTime: 60}},
+		{"end before start", overrideDate, domain.SetScheduleOverrideDTO{StartTime: "16:00", EndTime: "10:00", SlotTime: 60}},
+		{"slot too short", overrideDate, domain.SetScheduleOverrideDTO{StartTime: "10:00", EndTime: "16:00", SlotTime: 5}},
+		{"slot too long", overrideDate, domain.SetScheduleOverrideDTO{StartTime: "10:00", EndTime: "16:00", SlotTime: 180}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeScheduleRepo{}
+			if _, err := newOverrideTestSchedules(repo).SetOverride(context.Background(), 7, tt.date, tt.dto); err == nil {
+				t.Error("SetOverride() error = nil")
+			}
+			if repo.override != nil {
+				t.Errorf("invalid override was stored: %+v", repo.override)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+)
+
+// seoCacheTTL сколько хранятся sitemap и профили для разметки schema.org. Ключи лежат
+// под specialistsCachePrefix, поэтому любое изменение специалистов сбрасывает их раньше
+const seoCacheTTL = time.Hour
+
+func sitemapCacheKey(page int) string {
+	return fmt.Sprintf("%ssitemap:page=%d", specialistsCachePrefix, page)
+}
+
+func publicProfileCacheKey(specialistID int64) string {
+	return fmt.Sprintf("%spublic:%d", specialistsCachePrefix, specialistID)
+}
+
+// GetSitemapPage возвращает страницу page (с 1) профилей для sitemap.xml по domain.SitemapMaxURLs
+// на страницу и общее число профилей. В sitemap попадают только проверенные и не удаленные специалисты
+// с активной учетной записью. Для страницы за пределами списка возвращается ErrNotFound
+func (s *SpecialistServiceImpl) GetSitemapPage(ctx context.Context, page int) (*domain.SitemapPage, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.GetSitemapPage")
+	defer span.End()
+
+	if page < 1 {
+		return nil, fmt.Errorf("%w: страница sitemap не найдена", ErrNotFound)
+	}
+
+	cacheKey := sitemapCacheKey(page)
+	var cached domain.SitemapPage
+	if found, err := s.cache.Get(ctx, cacheKey, &cached); err != nil {
+		s.logger.Warn("ошибка чтения кэша sitemap", zap.Error(err))
+	} else if found {
+		return &cached, nil
+	}
+
+	total, err := s.repo.CountForSitemap(ctx)
+	if err != nil {
+		s.logger.Error("ошибка подсчета специалистов для sitemap", zap.Error(err))
+		return nil, errors.New("ошибка при построении sitemap")
+	}
+
+	// Первая страница существует всегда, даже если в sitemap пока нечего включить
+	offset := (page - 1) * domain.SitemapMaxURLs
+	if page > 1 && offset >= total {
+		return nil, fmt.Errorf("%w: страница sitemap не найдена", ErrNotFound)
+	}
+
+	entries, err := s.repo.ListForSitemap(ctx, domain.SitemapMaxURLs, offset)
+	if err != nil {
+		s.logger.Error("ошибка получения специалистов для sitemap", zap.Int("page", page), zap.Error(err))
+		return nil, errors.New("ошибка при построении sitemap")
+	}
+
+	sitemap := &domain.SitemapPage{Entries: entries, Total: total}
+	if err := s.cache.Set(ctx, cacheKey, sitemap, seoCacheTTL); err != nil {
+		s.logger.Warn("ошибка записи кэша sitemap", zap.Error(err))
+	}
+
+	return sitemap, nil
+}
+
+// GetPublicProfile возвращает профиль специалиста со специализациями для разметки schema.org.
+// Удаленные, непроверенные и заблокированные специалисты не отдаются: для них возвращается ErrNotFound
+func (s *SpecialistServiceImpl) GetPublicProfile(ctx context.Context, id int64) (*domain.Specialist, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.GetPublicProfile")
+	defer span.End()
+
+	cacheKey := publicProfileCacheKey(id)
+	var cached domain.Specialist
+	if found, err := s.cache.Get(ctx, cacheKey, &cached); err != nil {
+		s.logger.Warn("ошибка чтения кэша профиля специалиста", zap.Error(err))
+	} else if found {
+		return &cached, nil
+	}
+
+	specialist, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Warn("специалист для разметки не найден", zap.Int64("id", id), zap.Error(err))
+		return nil, fmt.Errorf("%w: специалист не найден", ErrNotFound)
+	}
+	if !specialist.IsVerified || !specialist.User.IsActive {
+		return nil, fmt.Errorf("%w: специалист не найден", ErrNotFound)
+	}
+
+	if err := s.cache.Set(ctx, cacheKey, specialist, seoCacheTTL); err != nil {
+		s.logger.Warn("ошибка записи кэша профиля специалиста", zap.Error(err))
+	}
+
+	return specialist, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+)
+
+// fakeSitemapRepo отдает total профилей для sitemap, не храня их
+type fakeSitemapRepo struct {
+	fakeSpecialistRepo
+
+	total   int
+	offsets []int
+}
+
+func (r *fakeSitemapRepo) CountForSitemap(ctx context.Context) (int, error) {
+	return r.total, nil
+}
+
+func (r *fakeSitemapRepo) ListForSitemap(ctx context.Context, limit, offset int) ([]domain.SitemapEntry, error) {
+	r.offsets = append(r.offsets, offset)
+	return []domain.SitemapEntry{{SpecialistID: int64(offset + 1)}}, nil
+}
+
+func TestGetSitemapPage(t *testing.T) {
+	repo := &fakeSitemapRepo{total: domain.SitemapMaxURLs + 1}
+	specialists := NewSpecialistService(repo, &fakeUserRepo{}, nil, nil, nil, nil, nil, cache.NewMemoryCache(10), time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	for _, page := range []int{1, 2, 2} {
+		sitemap, err := specialists.GetSitemapPage(ctx, page)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		if sitemap.Total != repo.total {
+			t.Errorf("page %d total = %d, want %d", page, sitemap.Total, repo.total)
+		}
+	}
+	if len(repo.offsets) != 2 || repo.offsets[1] != domain.SitemapMaxURLs {
+		t.Errorf("offsets = %v, want pages 1 and 2 read once each", repo.offsets)
+	}
+
+	for _, page := range []int{0, 3} {
+		if _, err := specialists.GetSitemapPage(ctx, page); !errors.Is(err, ErrNotFound) {
+			t.Errorf("page %d: err = %v, want ErrNotFound", page, err)
+		}
+	}
+
+	empty := NewSpecialistService(&fakeSitemapRepo{}, &fakeUserRepo{}, nil, nil, nil, nil, nil, cache.NewMemoryCache(10), time.Minute, zap.NewNop())
+	if _, err := empty.GetSitemapPage(ctx, 1); err != nil {
+		t.Errorf("empty sitemap: %v", err)
+	}
+}
+
+func TestGetPublicProfileHidesUnlisted(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name       string
+		specialist domain.Specialist
+		wantErr    error
+	}{
+		{name: "verified", specialist: domain.Specialist{ID: 7, IsVerified: true, User: domain.User{IsActive: true}}},
+		{name: "not verified", specialist: domain.Specialist{ID: 7, User: domain.User{IsActive: true}}, wantErr: ErrNotFound},
+		{name: "blocked", specialist: domain.Specialist{ID: 7, IsVerified: true}, wantErr: ErrNotFound},
+		{name: "removed", specialist: domain.Specialist{ID: 7, IsVerified: true, User: domain.User{IsActive: true}, DeletedAt: &deletedAt}, wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specialist := tt.specialist
+			specialists := NewSpecialistService(&fakeSpecialistRepo{specialist: &specialist}, &fakeUserRepo{}, nil, nil, nil, nil, nil,
+				cache.NewMemoryCache(10), time.Minute, zap.NewNop())
+
+			profile, err := specialists.GetPublicProfile(context.Background(), 7)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetPublicProfile() = %+v, %v; want %v", profile, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || profile.ID != 7 {
+				t.Errorf("GetPublicProfile() = %+v, %v", profile, err)
+			}
+		})
+	}
+}
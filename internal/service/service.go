@@ -2,12 +2,14 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"go.uber.org/zap"
 
 	"laps/config"
 
+	"laps/internal/cache"
 	"laps/internal/domain"
 	"laps/internal/repository"
 	"laps/internal/storage"
@@ -16,6 +18,8 @@ import (
 type Deps struct {
 	Repos       *repository.Repositories
 	FileStorage storage.FileStorage
+	Cache       cache.Cache
+	Notifier    Notifier
 	Config      *config.Config
 	Logger      *zap.Logger
 }
@@ -31,34 +35,89 @@ type Services struct {
 	Education      EducationService
 	WorkExperience WorkExperienceService
 	Chat           ChatService
+	Calendar       ExternalCalendarService
+	Audit          AuditService
+	BlockList      BlockListService
+	Webhook        WebhookService
+	Tag            TagService
+	DataExport     DataExportService
+	Onboarding     OnboardingService
+	ClientProfile  ClientProfileService
+	Notification   NotificationPreferenceService
+	Invite         InviteService
+	Event          EventService
+	PasswordReset  PasswordResetService
+	Call           CallService
+	// Realtime события для пользователей, подключенных по WebSocket; хаб подписывается на него после создания
+	Realtime *RealtimeBus
 }
 
 func NewServices(deps Deps) *Services {
 	// Create chat service first since appointment service depends on it
-	chatService := NewChatService(deps.Repos)
+	chatService := NewChatService(deps.Repos, deps.Cache)
+
+	notifier := deps.Notifier
+	if notifier == nil {
+		notifier = NewLogNotifier(deps.Logger)
+	}
+	notifier = NewPreferenceNotifier(deps.Repos.Notification, notifier, deps.Logger)
+
+	realtime := NewRealtimeBus()
+
+	// Доменные события для внешних систем ставятся в очередь доставки сервисом webhook
+	webhookService := NewWebhookService(deps.Repos.Outbox, deps.Repos.Webhook, deps.Config.Webhook, deps.Logger)
+	webhookBus := NewWebhookBus()
+	webhookBus.Subscribe(webhookService)
 	
+	userService := NewUserService(deps.Repos.User, deps.Repos.Auth, deps.Repos.Audit, deps.Cache, deps.Logger)
+
 	return &Services{
-		User:           NewUserService(deps.Repos.User, deps.Logger),
+		User:           userService,
 		Auth:           NewAuthService(deps.Repos.Auth, deps.Repos.User, deps.Config.JWT, deps.Logger),
-		Specialist:     NewSpecialistService(deps.Repos.Specialist, deps.Repos.User, deps.Repos.Specialization, deps.FileStorage, deps.Logger),
-		Specialization: NewSpecializationService(deps.Repos.Specialization, deps.Logger),
-		Schedule:       NewScheduleService(deps.Repos.Schedule, deps.Repos.Specialist, deps.Logger),
-		Appointment:    NewAppointmentService(deps.Repos.Appointment, deps.Repos.Specialist, deps.Repos.User, chatService, deps.Logger),
-		Review:         NewReviewService(deps.Repos.Review, deps.Repos.Specialist, deps.Repos.User, deps.Repos.Appointment, deps.Logger),
+		Specialist:     NewSpecialistService(deps.Repos.Specialist, deps.Repos.User, deps.Repos.Specialization, deps.Repos.Audit, deps.Repos.SpecialistType, deps.FileStorage, webhookBus, deps.Cache, deps.Config.Cache.TTL, deps.Logger),
+		Specialization: NewSpecializationService(deps.Repos.Specialization, deps.Cache, deps.Config.Cache.TTL, deps.Logger),
+		Schedule:       NewScheduleService(deps.Repos.Schedule, deps.Repos.Specialist, deps.Repos.Appointment, deps.Repos.Calendar, deps.Logger),
+		Appointment:    NewAppointmentService(deps.Repos.Appointment, deps.Repos.Schedule, deps.Repos.Specialist, deps.Repos.User, deps.Repos.Calendar, deps.Repos.BlockList, deps.Repos.Audit, deps.Repos.ReviewRequest, chatService, notifier, realtime, deps.Config.Appointment, deps.Logger),
+		Review:         NewReviewService(deps.Repos.Review, deps.Repos.Specialist, deps.Repos.User, deps.Repos.Appointment, deps.FileStorage, deps.Config.ReviewMedia, webhookBus, deps.Cache, deps.Config.Cache.TTL, deps.Logger),
 		Education:      NewEducationService(deps.Repos.Specialist, deps.Logger),
 		WorkExperience: NewWorkExperienceService(deps.Repos.Specialist, deps.Logger),
 		Chat:           chatService,
+		Calendar:       NewExternalCalendarService(deps.Repos.Calendar, deps.Config.Calendar, deps.Logger),
+		Audit:          NewAuditService(deps.Repos.Audit, deps.Logger),
+		BlockList:      NewBlockListService(deps.Repos.BlockList, deps.Repos.User, deps.Logger),
+		Webhook:        webhookService,
+		Tag:            NewTagService(deps.Repos.Tag, deps.Cache, deps.Logger),
+		DataExport:     NewDataExportService(deps.Repos.User, deps.Repos.Specialist, deps.Repos.Appointment, deps.Repos.Review, deps.Repos.Chat, deps.Repos.Audit, deps.Logger),
+		Onboarding:     NewOnboardingService(deps.Repos.Specialist, deps.Repos.Schedule, deps.Logger),
+		ClientProfile:  NewClientProfileService(deps.Repos.ClientProfile, deps.Repos.User, deps.Repos.Specialist, deps.Repos.Appointment, deps.Logger),
+		Notification:   NewNotificationPreferenceService(deps.Repos.Notification, deps.Logger),
+		Invite:         NewInviteService(deps.Repos.Invite, deps.Repos.User, deps.Repos.Audit, notifier, deps.Config.Invite, deps.Logger),
+		Event:          NewEventService(deps.Repos.Event, deps.Repos.Specialist, deps.Logger),
+		PasswordReset:  NewPasswordResetService(deps.Repos.PasswordReset, deps.Repos.User, userService, notifier, deps.Config.PasswordReset, deps.Logger),
+		Call:           NewCallService(deps.Repos.Call, deps.Logger),
+		Realtime:       realtime,
 	}
 }
 
+// CallService хранит завершенные звонки и оценки их качества участниками
+type CallService interface {
+	RecordCall(ctx context.Context, call domain.CallRecord) error
+	SubmitFeedback(ctx context.Context, userID int64, sessionID string, dto domain.CallFeedbackDTO) (*domain.CallFeedback, error)
+	GetFeedbackSummary(ctx context.Context, from, to *time.Time) (*domain.CallFeedbackSummary, error)
+}
+
 type UserService interface {
 	Create(ctx context.Context, dto domain.CreateUserDTO) (int64, error)
 	GetByID(ctx context.Context, id int64) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	Update(ctx context.Context, id int64, dto domain.UpdateUserDTO) error
 	UpdatePassword(ctx context.Context, id int64, dto domain.PasswordUpdateDTO) error
+	ResetPassword(ctx context.Context, id int64, newPassword string) error
+	TouchLastSeen(ctx context.Context, userID int64, final bool) error
+	DeleteAccount(ctx context.Context, id int64, dto domain.DeleteAccountDTO) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, limit, offset int) ([]domain.User, error)
+	ChangeRole(ctx context.Context, actorID, id int64, role domain.UserRole) (*domain.User, error)
 }
 
 type AuthService interface {
@@ -69,20 +128,43 @@ type AuthService interface {
 	ParseToken(ctx context.Context, token string) (int64, domain.UserRole, error)
 }
 
+type InviteService interface {
+	Invite(ctx context.Context, actorID int64, dto domain.InviteUserDTO) (*domain.UserInviteResult, error)
+	Accept(ctx context.Context, dto domain.AcceptInviteRequest) (*domain.User, error)
+}
+
+type PasswordResetService interface {
+	ForgotPassword(ctx context.Context, dto domain.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, dto domain.ResetPasswordRequest) error
+}
+
 type SpecialistService interface {
 	Create(ctx context.Context, userID int64, dto domain.CreateSpecialistDTO) (int64, error)
 	GetByID(ctx context.Context, id int64) (*domain.Specialist, error)
+	GetByIDWithDeleted(ctx context.Context, id int64) (*domain.Specialist, error)
 	GetByUserID(ctx context.Context, userID int64) (*domain.Specialist, error)
+	ListTypes(ctx context.Context) ([]domain.SpecialistTypeInfo, error)
 	Update(ctx context.Context, id int64, dto domain.UpdateSpecialistDTO) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, specialistType *domain.SpecialistType, specializationID *int64, limit, offset int) ([]domain.Specialist, int, error)
+	Restore(ctx context.Context, id int64) error
+	DeletePermanently(ctx context.Context, id int64) error
+	List(ctx context.Context, filter domain.SpecialistFilter) ([]domain.Specialist, int, error)
 
 	AddSpecialization(ctx context.Context, specialistID, specializationID int64) error
 	RemoveSpecialization(ctx context.Context, specialistID, specializationID int64) error
+	SetSpecializations(ctx context.Context, specialistID int64, ids []int64) ([]domain.Specialization, error)
 	GetSpecializationsBySpecialistID(ctx context.Context, specialistID int64) ([]domain.Specialization, error)
+	SetTranslations(ctx context.Context, specialistID int64, translations domain.Translations) (*domain.Specialist, error)
 
 	UploadProfilePhoto(ctx context.Context, specialistID int64, photo []byte, filename string) error
 	DeleteProfilePhoto(ctx context.Context, specialistID int64) error
+
+	SetVerified(ctx context.Context, adminID, specialistID int64, isVerified bool) error
+	GetActivityStats(ctx context.Context, specialistID int64) (*domain.SpecialistActivityStats, error)
+	GetPriceHistory(ctx context.Context, specialistID int64) ([]domain.SpecialistPriceChange, error)
+
+	GetSitemapPage(ctx context.Context, page int) (*domain.SitemapPage, error)
+	GetPublicProfile(ctx context.Context, id int64) (*domain.Specialist, error)
 }
 
 type EducationService interface {
@@ -107,6 +189,8 @@ type SpecializationService interface {
 	Update(ctx context.Context, id int64, dto domain.UpdateSpecializationDTO) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, filter domain.SpecializationFilter) ([]domain.Specialization, int, error)
+	Tree(ctx context.Context, filter domain.SpecializationFilter) ([]*domain.SpecializationNode, error)
+	SetTranslations(ctx context.Context, id int64, translations domain.Translations) (*domain.Specialization, error)
 }
 
 type ScheduleService interface {
@@ -117,27 +201,111 @@ type ScheduleService interface {
 	List(ctx context.Context, filter domain.ScheduleFilter) ([]domain.Schedule, int, error)
 	GetBySpecialistAndDate(ctx context.Context, specialistID int64, date string) (*domain.Schedule, error)
 	GenerateTimeSlots(ctx context.Context, specialistID int64, date string) ([]string, error)
-	GetWeekSchedule(ctx context.Context, specialistID int64, startDate time.Time) (*domain.WeekSchedule, int, error)
+	GetWeekSchedule(ctx context.Context, specialistID int64, startDate time.Time) (*domain.WeekSchedule, int, int, error)
+	SetOverride(ctx context.Context, specialistID int64, date string, dto domain.SetScheduleOverrideDTO) (*domain.ScheduleOverride, error)
+	GetNextAvailableSlot(ctx context.Context, specialistID int64, horizonDays int) (*domain.AvailableSlot, error)
+	GetAvailability(ctx context.Context, specialistID int64, from, to time.Time, withSlots bool) ([]domain.DayAvailability, error)
+	GetMonthCalendar(ctx context.Context, specialistID int64, year, month int) (map[string]string, error)
+	DeleteOverride(ctx context.Context, specialistID int64, date string) error
+	CloneScheduleToNextWeek(ctx context.Context, specialistID int64) error
+	RunWeeklyClone(ctx context.Context)
 }
 
 type AppointmentService interface {
-	Create(ctx context.Context, clientID int64, dto domain.CreateAppointmentDTO) (int64, error)
+	Create(ctx context.Context, clientID int64, dto domain.CreateAppointmentDTO) (int64, domain.ConsultationType, error)
 	GetByID(ctx context.Context, id int64) (*domain.Appointment, error)
-	Update(ctx context.Context, id int64, dto domain.UpdateAppointmentDTO) error
-	Cancel(ctx context.Context, id int64) error
+	GetNextUpcoming(ctx context.Context, clientID int64) (*domain.Appointment, error)
+	Update(ctx context.Context, id int64, dto domain.UpdateAppointmentDTO) (*domain.AppointmentConfirmation, error)
+	Cancel(ctx context.Context, id int64, cancelledBy domain.UserRole) error
+	CalendarFile(ctx context.Context, appointment *domain.Appointment) ([]byte, error)
+	CheckCallAllowed(ctx context.Context, appointmentID, callerID, calleeID int64) error
+	RecordCallDuration(ctx context.Context, appointmentID int64, seconds int) error
+	RunCallCompletion(ctx context.Context)
+	RunReviewRequests(ctx context.Context)
+	ListPendingReviews(ctx context.Context, clientID int64, limit, offset int) ([]domain.Appointment, int, error)
+	CancelRange(ctx context.Context, specialistID int64, dto domain.CancelAppointmentRangeDTO) ([]int64, error)
+	Transfer(ctx context.Context, id, actorID int64, dto domain.TransferAppointmentDTO) (*domain.Appointment, error)
+	CheckIn(ctx context.Context, id, clientID int64) (*domain.Appointment, error)
+	Start(ctx context.Context, id, userID int64, force bool) (*domain.Appointment, error)
 	List(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, int, error)
+	ListUpcoming(ctx context.Context, filter domain.AppointmentFilter) ([]domain.Appointment, int, error)
 	GetFreeSlots(ctx context.Context, specialistID int64, date string) ([]string, error)
-	CheckConsultationType(ctx context.Context, clientID int64, specialistID int64) (domain.ConsultationType, error)
+	GetFreeSlotsBatch(ctx context.Context, specialistIDs []int64, date string) (map[int64][]string, error)
+	CheckConsultationType(ctx context.Context, clientID, specialistID int64, specializationID *int64, at time.Time) (*domain.ConsultationTypeCheck, error)
+	HoldSlot(ctx context.Context, clientID int64, dto domain.CreateSlotHoldDTO) (*domain.SlotHold, error)
+	ReleaseHold(ctx context.Context, clientID, holdID int64) error
+	RunHoldPurge(ctx context.Context)
+	ExportCSV(ctx context.Context, w io.Writer, specialistID *int64, from, to time.Time) error
+}
+
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, dto domain.CreateWebhookEndpointDTO) (*domain.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, endpointID int64, limit, offset int) ([]domain.WebhookDelivery, int, error)
+	RunDispatcher(ctx context.Context)
+}
+
+type ExternalCalendarService interface {
+	SetFeed(ctx context.Context, specialistID int64, dto domain.SetExternalCalendarDTO) (*domain.ExternalCalendarFeed, error)
+	GetFeed(ctx context.Context, specialistID int64) (*domain.ExternalCalendarFeed, error)
+	SyncAll(ctx context.Context)
+	RunSync(ctx context.Context, interval time.Duration)
+}
+
+type BlockListService interface {
+	BlockClient(ctx context.Context, specialistID int64, dto domain.BlockClientDTO) error
+	UnblockClient(ctx context.Context, specialistID, clientID int64) error
+	ListBlockedClients(ctx context.Context, specialistID int64) ([]domain.BlockedClient, error)
+	IsBlockedBetweenUsers(ctx context.Context, userA, userB int64) (bool, error)
+}
+
+type TagService interface {
+	SetSpecialistTags(ctx context.Context, specialistID int64, tags []string) ([]domain.Tag, error)
+	GetSpecialistTags(ctx context.Context, specialistID int64) ([]domain.Tag, error)
+	Search(ctx context.Context, query string, limit int) ([]domain.Tag, error)
+}
+
+// DataExportService выгружает все данные пользователя по запросу субъекта данных
+type DataExportService interface {
+	ExportUserData(ctx context.Context, w io.Writer, userID, actorID int64) error
+}
+
+type NotificationPreferenceService interface {
+	Get(ctx context.Context, userID int64) (*domain.NotificationPreferences, error)
+	Update(ctx context.Context, userID int64, dto domain.UpdateNotificationPreferencesDTO) (*domain.NotificationPreferences, error)
+}
+
+type ClientProfileService interface {
+	Get(ctx context.Context, userID int64) (*domain.ClientProfile, error)
+	Update(ctx context.Context, userID int64, dto domain.UpdateClientProfileDTO) (*domain.ClientProfile, error)
+	GetForViewer(ctx context.Context, clientID, viewerID int64, viewerRole domain.UserRole) (*domain.ClientProfile, error)
+}
+
+type OnboardingService interface {
+	GetChecklist(ctx context.Context, specialist *domain.Specialist) (*domain.OnboardingChecklist, error)
+}
+
+type AuditService interface {
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, int, error)
+}
+
+type EventService interface {
+	ListSpecialistEvents(ctx context.Context, specialistID int64, limit, offset int) ([]domain.Event, int, error)
 }
 
 type ReviewService interface {
 	Create(ctx context.Context, clientID int64, dto domain.CreateReviewDTO) (int64, error)
+	UploadMedia(ctx context.Context, clientID int64, data []byte, filename string) (*domain.ReviewMedia, error)
+	AddMedia(ctx context.Context, reviewID, clientID int64, data []byte, filename string) (*domain.ReviewMedia, error)
 	GetByID(ctx context.Context, id int64) (*domain.Review, error)
 	Update(ctx context.Context, id int64, dto domain.UpdateReviewDTO) error
 	Delete(ctx context.Context, id int64) error
 	GetBySpecialistID(ctx context.Context, specialistID int64, limit, offset int) ([]domain.Review, int, error)
+	GetRatingSummary(ctx context.Context, specialistID int64) (*domain.RatingSummary, error)
 	GetByUserID(ctx context.Context, userID int64, limit, offset int) ([]domain.Review, error)
 	List(ctx context.Context, filter domain.ReviewFilter) ([]domain.Review, int, error)
+	ListPublicRecent(ctx context.Context, limit int) ([]domain.PublicReview, error)
 	CreateReply(ctx context.Context, userID int64, reviewID int64, reply domain.CreateReplyDTO) (int64, error)
 	GetReplyByID(ctx context.Context, id int64) (*domain.Reply, error)
 	DeleteReply(ctx context.Context, replyID int64) error
@@ -147,16 +315,30 @@ type ReviewService interface {
 type ChatService interface {
 	// Chat Sessions
 	CreateChatSession(ctx context.Context, dto domain.CreateChatSessionDTO) (*domain.ChatSession, error)
+	CreateChatSessionForUser(ctx context.Context, dto domain.CreateChatSessionDTO, userID int64) (*domain.ChatSession, error)
 	GetChatSessionByID(ctx context.Context, id int64, userID int64) (*domain.ChatSession, error)
 	GetChatSessionByAppointmentID(ctx context.Context, appointmentID int64, userID int64) (*domain.ChatSession, error)
 	ListChatSessions(ctx context.Context, userID int64, filter domain.ChatSessionFilter) ([]domain.ChatSession, int64, error)
+	ListChatSessionsByUserID(ctx context.Context, userID int64, filter domain.ChatSessionFilter) ([]domain.ChatSession, int64, error)
 	UpdateChatSession(ctx context.Context, id int64, dto domain.UpdateChatSessionDTO, userID int64) (*domain.ChatSession, error)
 	ArchiveChatSession(ctx context.Context, appointmentID int64) error
+	ArchiveChatSessionByID(ctx context.Context, sessionID int64, userID int64, role domain.UserRole) error
 	
 	// Chat Messages
 	CreateChatMessage(ctx context.Context, dto domain.CreateChatMessageDTO, userID int64) (*domain.ChatMessage, error)
+	CreateSystemMessage(ctx context.Context, sessionID, senderID int64, content string) (*domain.ChatMessage, error)
 	ListChatMessages(ctx context.Context, sessionID int64, userID int64, filter domain.ChatMessageFilter) ([]domain.ChatMessage, int64, error)
 	MarkMessagesAsRead(ctx context.Context, sessionID int64, userID int64) error
 	GetUnreadMessageCount(ctx context.Context, sessionID int64, userID int64) (int64, error)
 	GetUserChatSummary(ctx context.Context, userID int64) (map[string]interface{}, error)
+	GetChatSessionForTranscript(ctx context.Context, sessionID int64, userID int64, role domain.UserRole) (*domain.ChatSession, error)
+	StreamChatMessages(ctx context.Context, sessionID int64, fn func(message domain.ChatMessage) error) error
+
+	// Reactions
+	AddReaction(ctx context.Context, messageID, userID int64, emoji string) (map[string]int, error)
+	RemoveReaction(ctx context.Context, messageID, userID int64, emoji string) (map[string]int, error)
+
+	// Statistics
+	GetSpecialistResponseStats(ctx context.Context, specialistID int64) (*domain.SpecialistResponseStats, error)
+	GetSessionStats(ctx context.Context, sessionID int64, userID int64) (*domain.ChatSessionStats, error)
 }
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+const (
+	// Время, на которое слот удерживается за клиентом до оформления записи
+	slotHoldTTL = 10 * time.Minute
+	// Максимальное число одновременно активных удержаний одного клиента
+	maxActiveSlotHolds = 3
+	// Период удаления истекших удержаний
+	slotHoldPurgeInterval = time.Minute
+)
+
+// HoldSlot удерживает свободный слот за клиентом на slotHoldTTL. Запись по удержанию
+// создается через Create с hold_id или reservation_token; истекшее или снятое удержание освобождает слот
+func (s *AppointmentServiceImpl) HoldSlot(ctx context.Context, clientID int64, dto domain.CreateSlotHoldDTO) (*domain.SlotHold, error) {
+	ctx, span := tracer.Start(ctx, "AppointmentService.HoldSlot")
+	defer span.End()
+
+	if err := s.checkBookable(ctx, clientID, dto.SpecialistID, dto.AppointmentDate, true); err != nil {
+		return nil, err
+	}
+
+	hold, err := s.repo.CreateHold(ctx, uuid.New().String(), clientID, dto.SpecialistID, dto.AppointmentDate, time.Now().Add(slotHoldTTL), maxActiveSlotHolds)
+	if errors.Is(err, repository.ErrSlotTaken) {
+		return nil, fmt.Errorf("%w: выбранное время уже занято", ErrConflict)
+	}
+	if errors.Is(err, repository.ErrHoldLimit) {
+		return nil, fmt.Errorf("%w: нельзя удерживать больше %d слотов одновременно", ErrInvalid, maxActiveSlotHolds)
+	}
+	if err != nil {
+		s.logger.Error("ошибка удержания слота",
+			zap.Int64("clientID", clientID),
+			zap.Int64("specialistID", dto.SpecialistID),
+			zap.Error(err))
+		return nil, errors.New("ошибка при удержании слота")
+	}
+
+	return hold, nil
+}
+
+func (s *AppointmentServiceImpl) ReleaseHold(ctx context.Context, clientID, holdID int64) error {
+	ctx, span := tracer.Start(ctx, "AppointmentService.ReleaseHold")
+	defer span.End()
+
+	released, err := s.repo.ReleaseHold(ctx, clientID, holdID)
+	if err != nil {
+		s.logger.Error("ошибка снятия удержания слота", zap.Int64("holdID", holdID), zap.Error(err))
+		return errors.New("ошибка при снятии удержания слота")
+	}
+	if !released {
+		return fmt.Errorf("%w: удержание слота не найдено", ErrNotFound)
+	}
+
+	return nil
+}
+
+// RunHoldPurge периодически удаляет истекшие удержания, пока не отменен ctx.
+// Истекшие удержания и так не учитываются при проверках, очистка только не дает таблице расти
+func (s *AppointmentServiceImpl) RunHoldPurge(ctx context.Context) {
+	ticker := time.NewTicker(slotHoldPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.repo.PurgeExpiredHolds(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("ошибка удаления истекших удержаний слотов", zap.Error(err))
+		} else if purged > 0 {
+			s.logger.Info("удалены истекшие удержания слотов", zap.Int64("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
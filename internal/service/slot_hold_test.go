@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+func TestHoldSlot(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+		want    error
+	}{
+		{"free slot", nil, nil},
+		{"slot booked or held by another client", repository.ErrSlotTaken, ErrConflict},
+		{"too many active holds", repository.ErrHoldLimit, ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAppointmentFixture()
+			f.repo.holdErr = tt.repoErr
+
+			hold, err := f.service.HoldSlot(context.Background(), 1, domain.CreateSlotHoldDTO{
+				SpecialistID:    7,
+				AppointmentDate: tomorrowAt(10, 0),
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (hold == nil || hold.Token == "" || !hold.ExpiresAt.After(time.Now())) {
+				t.Errorf("hold = %+v", hold)
+			}
+		})
+	}
+}
+
+func TestHoldSlotRejectsUnavailableTime(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.bookedSlots = []string{"10:00"}
+
+	_, err := f.service.HoldSlot(context.Background(), 1, domain.CreateSlotHoldDTO{
+		SpecialistID:    7,
+		AppointmentDate: tomorrowAt(10, 0),
+	})
+	if err == nil {
+		t.Fatal("held a booked slot")
+	}
+}
+
+// Занятый слот проверяет только хранилище под блокировкой слота: сервис передает ему запись
+// и сообщает о конфликте, если удержание или запись другого клиента успели раньше
+func TestCreateLosesRaceToHold(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.createErr = repository.ErrSlotTaken
+
+	_, _, err := f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+		SpecialistID:        7,
+		AppointmentDate:     tomorrowAt(10, 0),
+		CommunicationMethod: domain.CommunicationMethodPhone,
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("err = %v, want ErrConflict", err)
+	}
+}
+
+func TestCreateFromHold(t *testing.T) {
+	f := newAppointmentFixture()
+	// Удержанный слот показывается занятым, в том числе самому клиенту
+	f.repo.bookedSlots = []string{"10:00"}
+	holdID := int64(1)
+
+	_, _, err := f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+		SpecialistID:        7,
+		AppointmentDate:     tomorrowAt(10, 0),
+		CommunicationMethod: domain.CommunicationMethodPhone,
+		HoldID:              &holdID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.repo.created) != 1 || f.repo.created[0].HoldID == nil || *f.repo.created[0].HoldID != holdID {
+		t.Errorf("stored appointments = %+v, want one made from hold %d", f.repo.created, holdID)
+	}
+
+	f.repo.createErr = repository.ErrHoldNotFound
+	_, _, err = f.service.Create(context.Background(), 1, domain.CreateAppointmentDTO{
+		SpecialistID:        7,
+		AppointmentDate:     tomorrowAt(10, 0),
+		CommunicationMethod: domain.CommunicationMethodPhone,
+		HoldID:              &holdID,
+	})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("expired hold: err = %v, want ErrInvalid", err)
+	}
+}
@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
+	"laps/internal/cache"
 	"laps/internal/domain"
 	"laps/internal/repository"
 	"laps/internal/storage"
@@ -15,22 +21,42 @@ type SpecialistServiceImpl struct {
 	repo        repository.SpecialistRepository
 	userRepo    repository.UserRepository
 	specRepo    repository.SpecializationRepository
+	auditRepo   repository.AuditRepository
+	typeRepo    repository.SpecialistTypeRepository
 	fileStorage storage.FileStorage
+	webhooks    WebhookPublisher
+	cache       cache.Cache
+	cacheTTL    time.Duration
 	logger      *zap.Logger
+
+	// types справочник типов специалистов, загруженный в typesLoadedAt
+	typesMu       sync.Mutex
+	types         []domain.SpecialistTypeInfo
+	typesLoadedAt time.Time
 }
 
 func NewSpecialistService(
 	repo repository.SpecialistRepository,
 	userRepo repository.UserRepository,
 	specRepo repository.SpecializationRepository,
+	auditRepo repository.AuditRepository,
+	typeRepo repository.SpecialistTypeRepository,
 	fileStorage storage.FileStorage,
+	webhooks WebhookPublisher,
+	c cache.Cache,
+	cacheTTL time.Duration,
 	logger *zap.Logger,
 ) *SpecialistServiceImpl {
 	return &SpecialistServiceImpl{
 		repo:        repo,
 		userRepo:    userRepo,
 		specRepo:    specRepo,
+		auditRepo:   auditRepo,
+		typeRepo:    typeRepo,
 		fileStorage: fileStorage,
+		webhooks:    webhooks,
+		cache:       c,
+		cacheTTL:    cacheTTL,
 		logger:      logger,
 	}
 }
@@ -48,9 +74,8 @@ func (s *SpecialistServiceImpl) Create(ctx context.Context, userID int64, dto do
 		return 0, errors.New("пользователь уже зарегистрирован как специалист")
 	}
 
-	if !dto.Type.IsValid() {
-		s.logger.Error("некорректный тип специалиста", zap.String("type", string(dto.Type)))
-		return 0, errors.New("некорректный тип специалиста")
+	if err := s.checkSpecialistType(ctx, dto.Type, true); err != nil {
+		return 0, err
 	}
 
 	_, err = s.specRepo.GetByID(ctx, dto.SpecializationID)
@@ -61,7 +86,16 @@ func (s *SpecialistServiceImpl) Create(ctx context.Context, userID int64, dto do
 		return 0, errors.New("указанная специализация не найдена")
 	}
 
+	languages, err := normalizeLanguageCodes(dto.Languages)
+	if err != nil {
+		return 0, err
+	}
+	dto.Languages = languages
+
 	id, err := s.repo.Create(ctx, userID, dto)
+	if errors.Is(err, repository.ErrSpecialistExists) {
+		return 0, fmt.Errorf("%w: профиль специалиста пользователя удален, восстановить его может администратор", ErrConflict)
+	}
 	if err != nil {
 		s.logger.Error("ошибка создания специалиста", zap.Error(err))
 		return 0, errors.New("ошибка при создании специалиста")
@@ -92,10 +126,15 @@ func (s *SpecialistServiceImpl) Create(ctx context.Context, userID int64, dto do
 		}
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
 	return id, nil
 }
 
 func (s *SpecialistServiceImpl) GetByID(ctx context.Context, id int64) (*domain.Specialist, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.GetByID")
+	defer span.End()
+
 	specialist, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("ошибка получения специалиста", zap.Int64("id", id), zap.Error(err))
@@ -104,7 +143,23 @@ func (s *SpecialistServiceImpl) GetByID(ctx context.Context, id int64) (*domain.
 	return specialist, nil
 }
 
+// GetByIDWithDeleted возвращает специалиста, в том числе удаленного; используется администратором
+func (s *SpecialistServiceImpl) GetByIDWithDeleted(ctx context.Context, id int64) (*domain.Specialist, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.GetByIDWithDeleted")
+	defer span.End()
+
+	specialist, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		s.logger.Error("ошибка получения специалиста", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New("специалист не найден")
+	}
+	return specialist, nil
+}
+
 func (s *SpecialistServiceImpl) GetByUserID(ctx context.Context, userID int64) (*domain.Specialist, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.GetByUserID")
+	defer span.End()
+
 	specialist, err := s.repo.GetByUserID(ctx, userID)
 	if err != nil {
 		s.logger.Error("ошибка получения специалиста по ID пользователя", zap.Int64("userID", userID), zap.Error(err))
@@ -114,15 +169,19 @@ func (s *SpecialistServiceImpl) GetByUserID(ctx context.Context, userID int64) (
 }
 
 func (s *SpecialistServiceImpl) Update(ctx context.Context, id int64, dto domain.UpdateSpecialistDTO) error {
+	ctx, span := tracer.Start(ctx, "SpecialistService.Update")
+	defer span.End()
+
 	specialist, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("специалист для обновления не найден", zap.Int64("id", id), zap.Error(err))
 		return errors.New("специалист не найден")
 	}
 
-	if dto.Type != nil && !dto.Type.IsValid() {
-		s.logger.Error("некорректный тип специалиста", zap.String("type", string(*dto.Type)))
-		return errors.New("некорректный тип специалиста")
+	if dto.Type != nil && *dto.Type != specialist.Type {
+		if err := s.checkSpecialistType(ctx, *dto.Type, true); err != nil {
+			return err
+		}
 	}
 
 	if dto.SpecializationID != nil {
@@ -135,67 +194,200 @@ func (s *SpecialistServiceImpl) Update(ctx context.Context, id int64, dto domain
 		}
 	}
 
+	if dto.Languages != nil {
+		languages, err := normalizeLanguageCodes(*dto.Languages)
+		if err != nil {
+			return err
+		}
+		dto.Languages = &languages
+	}
+
 	s.logger.Debug("обновление специалиста",
 		zap.Int64("id", id),
 		zap.Int64("userID", specialist.UserID),
 		zap.Any("data", dto))
 
 	err = s.repo.Update(ctx, id, dto)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return fmt.Errorf("%w: профиль специалиста был изменен, обновите данные и повторите", ErrVersionConflict)
+	}
 	if err != nil {
 		s.logger.Error("ошибка обновления специалиста", zap.Int64("id", id), zap.Error(err))
 		return errors.New("ошибка при обновлении специалиста")
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
 	return nil
 }
 
+func (s *SpecialistServiceImpl) GetPriceHistory(ctx context.Context, specialistID int64) ([]domain.SpecialistPriceChange, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.GetPriceHistory")
+	defer span.End()
+
+	history, err := s.repo.GetPriceHistory(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("ошибка получения истории цен специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении истории цен")
+	}
+
+	return history, nil
+}
+
+// Delete мягко удаляет специалиста: профиль пропадает из каталога и перестает принимать записи,
+// а история записей и отзывов сохраняется. Фото профиля остается, чтобы профиль можно было восстановить
 func (s *SpecialistServiceImpl) Delete(ctx context.Context, id int64) error {
-	_, err := s.repo.GetByID(ctx, id)
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, repository.ErrSpecialistNotFound) {
+		return fmt.Errorf("%w: специалист не найден", ErrNotFound)
+	}
 	if err != nil {
-		s.logger.Error("специалист для удаления не найден", zap.Int64("id", id), zap.Error(err))
-		return errors.New("специалист не найден")
+		s.logger.Error("ошибка удаления специалиста", zap.Int64("id", id), zap.Error(err))
+		return errors.New("ошибка при удалении специалиста")
+	}
+
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
+	return nil
+}
+
+// Restore восстанавливает мягко удаленного специалиста
+func (s *SpecialistServiceImpl) Restore(ctx context.Context, id int64) error {
+	err := s.repo.Restore(ctx, id)
+	if errors.Is(err, repository.ErrSpecialistNotFound) {
+		return fmt.Errorf("%w: удаленный специалист не найден", ErrNotFound)
 	}
+	if err != nil {
+		s.logger.Error("ошибка восстановления специалиста", zap.Int64("id", id), zap.Error(err))
+		return errors.New("ошибка при восстановлении специалиста")
+	}
+
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
+	return nil
+}
 
-	err = s.repo.Delete(ctx, id)
+// DeletePermanently удаляет специалиста без возможности восстановления вместе с его записями,
+// отзывами и фото профиля
+func (s *SpecialistServiceImpl) DeletePermanently(ctx context.Context, id int64) error {
+	specialist, err := s.repo.GetByIDWithDeleted(ctx, id)
 	if err != nil {
+		s.logger.Error("специалист для удаления не найден", zap.Int64("id", id), zap.Error(err))
+		return fmt.Errorf("%w: специалист не найден", ErrNotFound)
+	}
+
+	if err := s.repo.DeletePermanently(ctx, id); err != nil {
 		s.logger.Error("ошибка удаления специалиста", zap.Int64("id", id), zap.Error(err))
 		return errors.New("ошибка при удалении специалиста")
 	}
 
+	// Фото удаляется после записи в базе: если хранилище недоступно, остается лишний файл, а не битая ссылка
+	if specialist.ProfilePhotoURL != "" {
+		if err := s.fileStorage.DeleteFile(ctx, specialist.ProfilePhotoURL); err != nil {
+			s.logger.Error("ошибка удаления фото из хранилища",
+				zap.String("photoURL", specialist.ProfilePhotoURL), zap.Error(err))
+		}
+	}
+
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
 	return nil
 }
 
-func (s *SpecialistServiceImpl) List(ctx context.Context, specialistType *domain.SpecialistType, specializationID *int64, limit, offset int) ([]domain.Specialist, int, error) {
-	if specialistType != nil && !specialistType.IsValid() {
-		s.logger.Error("некорректный тип специалиста", zap.String("type", string(*specialistType)))
-		return nil, 0, errors.New("некорректный тип специалиста")
+type specialistListCacheEntry struct {
+	Items []domain.Specialist `json:"items"`
+	Total int                 `json:"total"`
+}
+
+func (s *SpecialistServiceImpl) List(ctx context.Context, filter domain.SpecialistFilter) ([]domain.Specialist, int, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.List")
+	defer span.End()
+
+	if filter.Type != nil {
+		if err := s.checkSpecialistType(ctx, *filter.Type, false); err != nil {
+			return nil, 0, err
+		}
 	}
 
-	if specializationID != nil {
-		_, err := s.specRepo.GetByID(ctx, *specializationID)
+	if filter.SpecializationID != nil {
+		_, err := s.specRepo.GetByID(ctx, *filter.SpecializationID)
 		if err != nil {
 			s.logger.Error("указанная специализация не найдена",
-				zap.Int64("specializationID", *specializationID),
+				zap.Int64("specializationID", *filter.SpecializationID),
 				zap.Error(err))
 			return nil, 0, errors.New("указанная специализация не найдена")
 		}
 	}
 
-	total, err := s.repo.CountByFilter(ctx, specialistType, specializationID)
+	if filter.Language != nil {
+		language := strings.ToLower(strings.TrimSpace(*filter.Language))
+		if !domain.IsKnownLanguageCode(language) {
+			return nil, 0, fmt.Errorf("%w: неизвестный код языка %q", ErrInvalid, *filter.Language)
+		}
+		filter.Language = &language
+	}
+
+	if len(filter.Tags) > 0 {
+		tags, err := normalizeTags(filter.Tags)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.Tags = tags
+	}
+
+	// Кэшируется только первая страница: она запрашивается чаще всего
+	var cacheKey string
+	if filter.Offset == 0 {
+		cacheKey = specialistListCacheKey(filter)
+		var cached specialistListCacheEntry
+		if found, err := s.cache.Get(ctx, cacheKey, &cached); err != nil {
+			s.logger.Warn("ошибка чтения кэша специалистов", zap.Error(err))
+		} else if found {
+			return cached.Items, cached.Total, nil
+		}
+	}
+
+	total, err := s.repo.CountByFilter(ctx, filter)
 	if err != nil {
 		s.logger.Error("ошибка подсчета количества специалистов", zap.Error(err))
 		return nil, 0, errors.New("ошибка при получении списка специалистов")
 	}
 
-	specialists, err := s.repo.List(ctx, specialistType, specializationID, limit, offset)
+	specialists, err := s.repo.List(ctx, filter)
 	if err != nil {
 		s.logger.Error("ошибка получения списка специалистов", zap.Error(err))
 		return nil, 0, errors.New("ошибка при получении списка специалистов")
 	}
 
+	if cacheKey != "" {
+		entry := specialistListCacheEntry{Items: specialists, Total: total}
+		if err := s.cache.Set(ctx, cacheKey, entry, s.cacheTTL); err != nil {
+			s.logger.Warn("ошибка записи кэша специалистов", zap.Error(err))
+		}
+	}
+
 	return specialists, total, nil
 }
 
+// normalizeLanguageCodes приводит коды языков к нижнему регистру, убирает повторы
+// и проверяет их по списку известных кодов
+func normalizeLanguageCodes(codes []string) ([]string, error) {
+	normalized := make([]string, 0, len(codes))
+	seen := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if !domain.IsKnownLanguageCode(code) {
+			return nil, fmt.Errorf("%w: неизвестный код языка %q", ErrInvalid, code)
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+		normalized = append(normalized, code)
+	}
+	return normalized, nil
+}
+
 func (s *SpecialistServiceImpl) AddSpecialization(ctx context.Context, specialistID, specializationID int64) error {
 	_, err := s.repo.GetByID(ctx, specialistID)
 	if err != nil {
@@ -215,6 +407,8 @@ func (s *SpecialistServiceImpl) AddSpecialization(ctx context.Context, specialis
 		return errors.New("ошибка при добавлении специализации")
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
 	return nil
 }
 
@@ -231,14 +425,61 @@ func (s *SpecialistServiceImpl) RemoveSpecialization(ctx context.Context, specia
 		return errors.New("ошибка при удалении специализации")
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
 	return nil
 }
 
+// SetSpecializations атомарно заменяет дополнительные специализации специалиста набором ids и возвращает
+// новый список. Повторы в ids отбрасываются; если какой-то специализации нет, возвращается ErrInvalid
+func (s *SpecialistServiceImpl) SetSpecializations(ctx context.Context, specialistID int64, ids []int64) ([]domain.Specialization, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.SetSpecializations")
+	defer span.End()
+
+	if _, err := s.repo.GetByID(ctx, specialistID); err != nil {
+		s.logger.Warn("специалист не найден при замене специализаций", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, fmt.Errorf("%w: специалист не найден", ErrNotFound)
+	}
+
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	err := s.repo.SetSpecializations(ctx, specialistID, unique)
+	if errors.Is(err, repository.ErrSpecializationNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+	}
+	if err != nil {
+		s.logger.Error("ошибка замены специализаций", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при замене специализаций")
+	}
+
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
+	specializations, err := s.repo.GetSpecializationsBySpecialistID(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("ошибка получения специализаций после замены", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при замене специализаций")
+	}
+
+	return specializations, nil
+}
+
+// GetSpecializationsBySpecialistID возвращает все специализации, которые ведет специалист
 func (s *SpecialistServiceImpl) GetSpecializationsBySpecialistID(ctx context.Context, specialistID int64) ([]domain.Specialization, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.GetSpecializationsBySpecialistID")
+	defer span.End()
+
 	_, err := s.repo.GetByID(ctx, specialistID)
 	if err != nil {
 		s.logger.Error("специалист не найден при получении специализаций", zap.Int64("specialistID", specialistID), zap.Error(err))
-		return nil, errors.New("специалист не найден")
+		return nil, fmt.Errorf("%w: специалист не найден", ErrNotFound)
 	}
 
 	specializations, err := s.repo.GetSpecializationsBySpecialistID(ctx, specialistID)
@@ -281,6 +522,8 @@ func (s *SpecialistServiceImpl) UploadProfilePhoto(ctx context.Context, speciali
 		return errors.New("ошибка сохранения информации о фотографии")
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
 	return nil
 }
 
@@ -308,5 +551,98 @@ func (s *SpecialistServiceImpl) DeleteProfilePhoto(ctx context.Context, speciali
 		return errors.New("ошибка удаления информации о фотографии")
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
+	return nil
+}
+
+// SetVerified меняет верификацию специалиста в обход проверки документов и пишет событие в журнал аудита.
+// При подтверждении непроверенного специалиста публикуется событие specialist.verified
+func (s *SpecialistServiceImpl) SetVerified(ctx context.Context, adminID, specialistID int64, isVerified bool) error {
+	oldValue, err := s.repo.SetVerified(ctx, specialistID, isVerified)
+	if err != nil {
+		s.logger.Error("ошибка изменения верификации специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return errors.New("ошибка при изменении верификации специалиста")
+	}
+
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
+	if isVerified && !oldValue {
+		s.webhooks.Publish(ctx, domain.WebhookEventSpecialistVerified, domain.SpecialistVerifiedWebhookData{
+			SpecialistID: specialistID,
+			VerifiedAt:   time.Now(),
+		})
+	}
+
+	oldJSON, _ := json.Marshal(map[string]bool{"is_verified": oldValue})
+	newJSON, _ := json.Marshal(map[string]bool{"is_verified": isVerified})
+
+	err = s.auditRepo.Log(ctx, domain.AuditEntry{
+		ActorID:    &adminID,
+		Action:     domain.AuditActionSpecialistVerify,
+		EntityType: domain.AuditEntitySpecialist,
+		EntityID:   specialistID,
+		OldValue:   oldJSON,
+		NewValue:   newJSON,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("ошибка записи события аудита",
+			zap.Int64("adminUserID", adminID),
+			zap.Int64("targetSpecialistID", specialistID),
+			zap.Bool("oldValue", oldValue),
+			zap.Bool("newValue", isVerified),
+			zap.Error(err))
+	}
+
+	s.logger.Info("верификация специалиста изменена администратором",
+		zap.Int64("adminUserID", adminID),
+		zap.Int64("targetSpecialistID", specialistID),
+		zap.Bool("oldValue", oldValue),
+		zap.Bool("newValue", isVerified))
+
 	return nil
 }
+
+const (
+	activityStatsPeriodDays    = 90
+	minActivityStatsSampleSize = 5
+)
+
+// GetActivityStats возвращает показатели активности специалиста за последние 90 дней.
+// Показатели с выборкой меньше 5 скрываются, чтобы единичные случаи не искажали профиль
+func (s *SpecialistServiceImpl) GetActivityStats(ctx context.Context, specialistID int64) (*domain.SpecialistActivityStats, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.GetActivityStats")
+	defer span.End()
+
+	cacheKey := activityStatsCacheKey(specialistID)
+	var cached domain.SpecialistActivityStats
+	if found, err := s.cache.Get(ctx, cacheKey, &cached); err != nil {
+		s.logger.Warn("ошибка чтения кэша статистики активности", zap.Error(err))
+	} else if found {
+		return &cached, nil
+	}
+
+	since := time.Now().AddDate(0, 0, -activityStatsPeriodDays)
+	stats, err := s.repo.GetActivityStats(ctx, specialistID, since)
+	if err != nil {
+		s.logger.Error("ошибка расчета статистики активности", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении статистики активности специалиста")
+	}
+
+	if stats.ResponseSampleSize < minActivityStatsSampleSize {
+		stats.MedianFirstResponseSeconds = nil
+	}
+	if stats.ConfirmationSampleSize < minActivityStatsSampleSize {
+		stats.ConfirmationRate = nil
+	}
+	if stats.CancellationSampleSize < minActivityStatsSampleSize {
+		stats.SpecialistCancellationRate = nil
+	}
+
+	if err := s.cache.Set(ctx, cacheKey, stats, s.cacheTTL); err != nil {
+		s.logger.Warn("ошибка записи кэша статистики активности", zap.Error(err))
+	}
+
+	return stats, nil
+}
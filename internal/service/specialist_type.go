@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+)
+
+// Как долго справочник типов специалистов хранится в памяти, прежде чем будет перечитан из базы
+const specialistTypesCacheTTL = 5 * time.Minute
+
+// specialistTypes возвращает справочник типов специалистов, перечитывая его из базы не чаще раза в specialistTypesCacheTTL
+func (s *SpecialistServiceImpl) specialistTypes(ctx context.Context) ([]domain.SpecialistTypeInfo, error) {
+	s.typesMu.Lock()
+	defer s.typesMu.Unlock()
+
+	if s.types != nil && time.Since(s.typesLoadedAt) < specialistTypesCacheTTL {
+		return s.types, nil
+	}
+
+	types, err := s.typeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if types == nil {
+		types = []domain.SpecialistTypeInfo{}
+	}
+
+	s.types = types
+	s.typesLoadedAt = time.Now()
+
+	return types, nil
+}
+
+// checkSpecialistType проверяет, что тип есть в справочнике. Для нового или измененного профиля
+// (requireActive) тип должен быть еще и активным
+func (s *SpecialistServiceImpl) checkSpecialistType(ctx context.Context, specialistType domain.SpecialistType, requireActive bool) error {
+	types, err := s.specialistTypes(ctx)
+	if err != nil {
+		s.logger.Error("ошибка получения типов специалистов", zap.Error(err))
+		return errors.New("ошибка при проверке типа специалиста")
+	}
+
+	for _, t := range types {
+		if t.Code == specialistType && (t.IsActive || !requireActive) {
+			return nil
+		}
+	}
+
+	s.logger.Warn("некорректный тип специалиста", zap.String("type", string(specialistType)))
+	return fmt.Errorf("%w: некорректный тип специалиста", ErrInvalid)
+}
+
+// ListTypes возвращает активные типы специалистов для выбора в анкете и фильтрах
+func (s *SpecialistServiceImpl) ListTypes(ctx context.Context) ([]domain.SpecialistTypeInfo, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.ListTypes")
+	defer span.End()
+
+	types, err := s.specialistTypes(ctx)
+	if err != nil {
+		s.logger.Error("ошибка получения типов специалистов", zap.Error(err))
+		return nil, errors.New("ошибка при получении типов специалистов")
+	}
+
+	active := make([]domain.SpecialistTypeInfo, 0, len(types))
+	for _, t := range types {
+		if t.IsActive {
+			active = append(active, t)
+		}
+	}
+
+	return active, nil
+}
@@ -4,32 +4,49 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
+	"laps/internal/cache"
 	"laps/internal/domain"
 	"laps/internal/repository"
 )
 
 type SpecializationServiceImpl struct {
-	repo   repository.SpecializationRepository
-	logger *zap.Logger
+	repo     repository.SpecializationRepository
+	cache    cache.Cache
+	cacheTTL time.Duration
+	logger   *zap.Logger
 }
 
-func NewSpecializationService(repo repository.SpecializationRepository, logger *zap.Logger) *SpecializationServiceImpl {
+func NewSpecializationService(repo repository.SpecializationRepository, c cache.Cache, cacheTTL time.Duration, logger *zap.Logger) *SpecializationServiceImpl {
 	return &SpecializationServiceImpl{
-		repo:   repo,
-		logger: logger,
+		repo:     repo,
+		cache:    c,
+		cacheTTL: cacheTTL,
+		logger:   logger,
 	}
 }
 
 func (s *SpecializationServiceImpl) Create(ctx context.Context, dto domain.CreateSpecializationDTO) (int64, error) {
+	if dto.ParentID != nil {
+		if err := s.checkParent(ctx, *dto.ParentID, dto.Type); err != nil {
+			return 0, err
+		}
+	}
+
 	id, err := s.repo.Create(ctx, dto)
+	if errors.Is(err, repository.ErrUnknownSpecialistType) {
+		return 0, fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+	}
 	if err != nil {
 		s.logger.Error("ошибка создания специализации", zap.Error(err))
 		return 0, errors.New("ошибка при создании специализации")
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specializationsCachePrefix)
+
 	return id, nil
 }
 
@@ -44,18 +61,32 @@ func (s *SpecializationServiceImpl) GetByID(ctx context.Context, id int64) (*dom
 }
 
 func (s *SpecializationServiceImpl) Update(ctx context.Context, id int64, dto domain.UpdateSpecializationDTO) error {
-	_, err := s.repo.GetByID(ctx, id)
+	specialization, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("специализация для обновления не найдена", zap.Int64("id", id), zap.Error(err))
 		return errors.New("специализация не найдена")
 	}
 
+	if dto.ParentID != nil && !dto.RemoveParent {
+		if *dto.ParentID == id {
+			return fmt.Errorf("%w: специализация не может быть родителем самой себя", ErrInvalid)
+		}
+		if err := s.checkParent(ctx, *dto.ParentID, specialization.Type); err != nil {
+			return err
+		}
+	}
+
 	err = s.repo.Update(ctx, id, dto)
+	if errors.Is(err, repository.ErrSpecializationCycle) {
+		return fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+	}
 	if err != nil {
 		s.logger.Error("ошибка обновления специализации", zap.Int64("id", id), zap.Error(err))
 		return errors.New("ошибка при обновлении специализации")
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specializationsCachePrefix, specialistsCachePrefix)
+
 	return nil
 }
 
@@ -66,16 +97,40 @@ func (s *SpecializationServiceImpl) Delete(ctx context.Context, id int64) error
 		return errors.New("специализация не найдена")
 	}
 
+	hasChildren, err := s.repo.HasChildren(ctx, id)
+	if err != nil {
+		s.logger.Error("ошибка проверки дочерних специализаций", zap.Int64("id", id), zap.Error(err))
+		return errors.New("ошибка при удалении специализации")
+	}
+	if hasChildren {
+		return fmt.Errorf("%w: нельзя удалить специализацию с дочерними специализациями", ErrConflict)
+	}
+
 	err = s.repo.Delete(ctx, id)
 	if err != nil {
 		s.logger.Error("ошибка удаления специализации", zap.Int64("id", id), zap.Error(err))
 		return errors.New("ошибка при удалении специализации")
 	}
 
+	invalidateCache(ctx, s.cache, s.logger, specializationsCachePrefix, specialistsCachePrefix)
+
 	return nil
 }
 
+type specializationListCacheEntry struct {
+	Items []domain.Specialization `json:"items"`
+	Total int                     `json:"total"`
+}
+
 func (s *SpecializationServiceImpl) List(ctx context.Context, filter domain.SpecializationFilter) ([]domain.Specialization, int, error) {
+	cacheKey := specializationListCacheKey(filter)
+	var cached specializationListCacheEntry
+	if found, err := s.cache.Get(ctx, cacheKey, &cached); err != nil {
+		s.logger.Warn("ошибка чтения кэша специализаций", zap.Error(err))
+	} else if found {
+		return cached.Items, cached.Total, nil
+	}
+
 	total, err := s.repo.CountByFilter(ctx, filter)
 	if err != nil {
 		s.logger.Error("ошибка подсчета специализаций", zap.Error(err))
@@ -88,5 +143,82 @@ func (s *SpecializationServiceImpl) List(ctx context.Context, filter domain.Spec
 		return nil, 0, fmt.Errorf("ошибка при получении списка специализаций: %w", err)
 	}
 
+	entry := specializationListCacheEntry{Items: specializations, Total: total}
+	if err := s.cache.Set(ctx, cacheKey, entry, s.cacheTTL); err != nil {
+		s.logger.Warn("ошибка записи кэша специализаций", zap.Error(err))
+	}
+
 	return specializations, total, nil
 }
+
+// checkParent проверяет, что родительская специализация существует и относится к тому же типу специалистов
+func (s *SpecializationServiceImpl) checkParent(ctx context.Context, parentID int64, specType domain.SpecialistType) error {
+	parent, err := s.repo.GetByID(ctx, parentID)
+	if err != nil {
+		s.logger.Warn("родительская специализация не найдена", zap.Int64("parent_id", parentID), zap.Error(err))
+		return fmt.Errorf("%w: родительская специализация не найдена", ErrInvalid)
+	}
+	if parent.Type != specType {
+		return fmt.Errorf("%w: родительская специализация относится к другому типу специалистов", ErrInvalid)
+	}
+	return nil
+}
+
+// Tree возвращает специализации в виде дерева. Пагинация фильтра не применяется.
+// Если задан filter.ParentID, возвращаются поддеревья его непосредственных потомков.
+// Специализации, родитель которых не прошел фильтр, становятся корнями.
+func (s *SpecializationServiceImpl) Tree(ctx context.Context, filter domain.SpecializationFilter) ([]*domain.SpecializationNode, error) {
+	parentID := filter.ParentID
+	filter.ParentID = nil
+	filter.Limit = 0
+	filter.Offset = 0
+
+	cacheKey := specializationTreeCacheKey(filter)
+	var specializations []domain.Specialization
+	found, err := s.cache.Get(ctx, cacheKey, &specializations)
+	if err != nil {
+		s.logger.Warn("ошибка чтения кэша специализаций", zap.Error(err))
+	}
+
+	if !found {
+		specializations, err = s.repo.List(ctx, filter)
+		if err != nil {
+			s.logger.Error("ошибка получения дерева специализаций", zap.Error(err))
+			return nil, fmt.Errorf("ошибка при получении списка специализаций: %w", err)
+		}
+		if err := s.cache.Set(ctx, cacheKey, specializations, s.cacheTTL); err != nil {
+			s.logger.Warn("ошибка записи кэша специализаций", zap.Error(err))
+		}
+	}
+
+	return buildSpecializationTree(specializations, parentID), nil
+}
+
+// buildSpecializationTree собирает дерево из плоского списка; при rootParentID != nil
+// корнями становятся непосредственные потомки rootParentID
+func buildSpecializationTree(specializations []domain.Specialization, rootParentID *int64) []*domain.SpecializationNode {
+	nodes := make(map[int64]*domain.SpecializationNode, len(specializations))
+	for _, sp := range specializations {
+		nodes[sp.ID] = &domain.SpecializationNode{Specialization: sp, Children: []*domain.SpecializationNode{}}
+	}
+
+	roots := make([]*domain.SpecializationNode, 0)
+	for _, sp := range specializations {
+		node := nodes[sp.ID]
+		if rootParentID != nil && sp.ParentID != nil && *sp.ParentID == *rootParentID {
+			roots = append(roots, node)
+			continue
+		}
+		if sp.ParentID != nil {
+			if parent, ok := nodes[*sp.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		if rootParentID == nil {
+			roots = append(roots, node)
+		}
+	}
+
+	return roots
+}
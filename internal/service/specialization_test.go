@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+)
+
+func ptrInt64(v int64) *int64 {
+	return &v
+}
+
+// specializationFixture: Психология → Детский психолог → Подростковый психолог,
+// Психология → Семейный психолог и отдельная категория Право для юристов
+func specializationFixture() []domain.Specialization {
+	psychologist, lawyer := domain.SpecialistTypePsychologist, domain.SpecialistTypeLawyer
+	return []domain.Specialization{
+		{ID: 1, Name: "Психология", Type: psychologist},
+		{ID: 2, ParentID: ptrInt64(1), Name: "Детский психолог", Type: psychologist},
+		{ID: 3, ParentID: ptrInt64(2), Name: "Подростковый психолог", Type: psychologist},
+		{ID: 4, ParentID: ptrInt64(1), Name: "Семейный психолог", Type: psychologist},
+		{ID: 5, Name: "Право", Type: lawyer},
+	}
+}
+
+// renderTree выводит дерево в виде "Психология(Детский психолог(...),...)"
+func renderTree(nodes []*domain.SpecializationNode) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		part := node.Name
+		if len(node.Children) > 0 {
+			part += "(" + renderTree(node.Children) + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestBuildSpecializationTree(t *testing.T) {
+	all := specializationFixture()
+
+	tests := []struct {
+		name            string
+		specializations []domain.Specialization
+		rootParentID    *int64
+		want            string
+	}{
+		{
+			name:            "whole tree",
+			specializations: all,
+			want:            "Психология(Детский психолог(Подростковый психолог),Семейный психолог),Право",
+		},
+		{
+			name:            "subtree of a parent",
+			specializations: all,
+			rootParentID:    ptrInt64(1),
+			want:            "Детский психолог(Подростковый психолог),Семейный психолог",
+		},
+		{
+			name:            "leaf has no subtree",
+			specializations: all,
+			rootParentID:    ptrInt64(3),
+			want:            "",
+		},
+		{
+			// Родитель не прошел фильтр, поэтому его потомок становится корнем
+			name:            "filtered out parent",
+			specializations: []domain.Specialization{all[2], all[3], all[4]},
+			want:            "Подростковый психолог,Семейный психолог,Право",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTree(buildSpecializationTree(tt.specializations, tt.rootParentID)); got != tt.want {
+				t.Errorf("tree = %s\nwant   %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func newSpecializationTestService(repo *fakeSpecializationRepo) *SpecializationServiceImpl {
+	return NewSpecializationService(repo, cache.NewMemoryCache(100), time.Minute, zap.NewNop())
+}
+
+func TestSpecializationUpdatePreventsCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int64
+		parentID int64
+		wantErr  error
+	}{
+		{"parent of itself", 1, 1, ErrInvalid},
+		{"under its child", 1, 2, ErrInvalid},
+		{"under its grandchild", 1, 3, ErrInvalid},
+		{"parent of another type", 2, 5, ErrInvalid},
+		{"missing parent", 2, 42, ErrInvalid},
+		{"move to a sibling", 3, 4, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSpecializationRepo{items: specializationFixture()}
+			err := newSpecializationTestService(repo).Update(context.Background(), tt.id, domain.UpdateSpecializationDTO{ParentID: &tt.parentID})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			tree := renderTree(buildSpecializationTree(repo.items, nil))
+			if tt.wantErr != nil && tree != renderTree(buildSpecializationTree(specializationFixture(), nil)) {
+				t.Errorf("rejected move changed the tree: %s", tree)
+			}
+		})
+	}
+}
+
+func TestSpecializationTreeFromService(t *testing.T) {
+	repo := &fakeSpecializationRepo{items: specializationFixture()}
+	specializations := newSpecializationTestService(repo)
+	psychologist := domain.SpecialistTypePsychologist
+
+	nodes, err := specializations.Tree(context.Background(), domain.SpecializationFilter{Type: &psychologist, ParentID: ptrInt64(1), Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := renderTree(nodes); got != "Детский психолог(Подростковый психолог),Семейный психолог" {
+		t.Errorf("tree = %s", got)
+	}
+}
+
+func TestSpecializationDeleteWithChildren(t *testing.T) {
+	repo := &fakeSpecializationRepo{items: specializationFixture()}
+	specializations := newSpecializationTestService(repo)
+
+	for _, id := range []int64{1, 2} {
+		if err := specializations.Delete(context.Background(), id); !errors.Is(err, ErrConflict) {
+			t.Errorf("delete %d: err = %v, want ErrConflict", id, err)
+		}
+	}
+	if err := specializations.Delete(context.Background(), 3); err != nil {
+		t.Errorf("delete leaf: %v", err)
+	}
+	if len(repo.items) != 4 {
+		t.Errorf("%d specializations left, want 4", len(repo.items))
+	}
+}
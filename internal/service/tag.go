@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+const (
+	maxSpecialistTags  = 15
+	maxTagLength       = 50
+	defaultTagsLimit   = 10
+	maxTagsSearchLimit = 50
+)
+
+type TagServiceImpl struct {
+	repo   repository.TagRepository
+	cache  cache.Cache
+	logger *zap.Logger
+}
+
+func NewTagService(repo repository.TagRepository, c cache.Cache, logger *zap.Logger) *TagServiceImpl {
+	return &TagServiceImpl{
+		repo:   repo,
+		cache:  c,
+		logger: logger,
+	}
+}
+
+// SetSpecialistTags заменяет метки специалиста и возвращает сохраненный набор
+func (s *TagServiceImpl) SetSpecialistTags(ctx context.Context, specialistID int64, tags []string) ([]domain.Tag, error) {
+	ctx, span := tracer.Start(ctx, "TagService.SetSpecialistTags")
+	defer span.End()
+
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > maxSpecialistTags {
+		return nil, fmt.Errorf("%w: можно указать не более %d меток", ErrInvalid, maxSpecialistTags)
+	}
+
+	if err := s.repo.SetSpecialistTags(ctx, specialistID, names); err != nil {
+		s.logger.Error("ошибка сохранения меток специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении меток")
+	}
+
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
+	return s.GetSpecialistTags(ctx, specialistID)
+}
+
+func (s *TagServiceImpl) GetSpecialistTags(ctx context.Context, specialistID int64) ([]domain.Tag, error) {
+	ctx, span := tracer.Start(ctx, "TagService.GetSpecialistTags")
+	defer span.End()
+
+	tags, err := s.repo.GetSpecialistTags(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("ошибка получения меток специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при получении меток")
+	}
+
+	return tags, nil
+}
+
+// Search подбирает популярные метки для автодополнения
+func (s *TagServiceImpl) Search(ctx context.Context, query string, limit int) ([]domain.Tag, error) {
+	ctx, span := tracer.Start(ctx, "TagService.Search")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = defaultTagsLimit
+	}
+	if limit > maxTagsSearchLimit {
+		limit = maxTagsSearchLimit
+	}
+
+	tags, err := s.repo.Search(ctx, normalizeTag(query), limit)
+	if err != nil {
+		s.logger.Error("ошибка поиска меток", zap.String("query", query), zap.Error(err))
+		return nil, errors.New("ошибка при поиске меток")
+	}
+
+	return tags, nil
+}
+
+// normalizeTag приводит метку к нижнему регистру и схлопывает пробелы: «КПТ» и « кпт » — одна метка
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// normalizeTags нормализует метки, убирает повторы и проверяет длину
+func normalizeTags(tags []string) ([]string, error) {
+	names := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		name := normalizeTag(tag)
+		if name == "" {
+			return nil, fmt.Errorf("%w: метка не может быть пустой", ErrInvalid)
+		}
+		if utf8.RuneCountInString(name) > maxTagLength {
+			return nil, fmt.Errorf("%w: метка не должна превышать %d символов", ErrInvalid, maxTagLength)
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names, nil
+}
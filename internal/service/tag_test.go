@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/cache"
+	"laps/internal/domain"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "case and duplicates", in: []string{"КПТ", "кпт", " Кпт "}, want: []string{"кпт"}},
+		{name: "inner spaces collapse", in: []string{"Работа  с\tТревожностью", "работа с тревожностью"}, want: []string{"работа с тревожностью"}},
+		{name: "order of first use is kept", in: []string{"Семья", "КПТ", "семья"}, want: []string{"семья", "кпт"}},
+		{name: "blank tag", in: []string{"кпт", "  "}, wantErr: true},
+		{name: "too long", in: []string{strings.Repeat("я", maxTagLength+1)}, wantErr: true},
+		{name: "longest allowed", in: []string{strings.Repeat("Я", maxTagLength)}, want: []string{strings.Repeat("я", maxTagLength)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("err = %v, want ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil || strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("normalizeTags() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func numberedTags(n int) []string {
+	tags := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		tags = append(tags, fmt.Sprintf("метка %d", i))
+	}
+	return tags
+}
+
+func TestSetSpecialistTagsCap(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		wantErr bool
+	}{
+		{name: "at the cap", tags: numberedTags(maxSpecialistTags)},
+		{name: "over the cap", tags: numberedTags(maxSpecialistTags + 1), wantErr: true},
+		// Повторы схлопываются до проверки лимита
+		{name: "duplicates do not count", tags: append(numberedTags(maxSpecialistTags), "МЕТКА 0", "Метка 1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTagRepo{}
+			tags, err := NewTagService(repo, cache.NewMemoryCache(10), zap.NewNop()).SetSpecialistTags(context.Background(), 7, tt.tags)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) || repo.saved != nil {
+					t.Errorf("err = %v, saved = %v; want ErrInvalid and nothing saved", err, repo.saved)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tags) != maxSpecialistTags {
+				t.Errorf("saved %d tags, want %d", len(tags), maxSpecialistTags)
+			}
+		})
+	}
+}
+
+func TestSpecialistListNormalizesTagFilter(t *testing.T) {
+	repo := &fakeSpecialistRepo{}
+	specialists := NewSpecialistService(repo, &fakeUserRepo{}, nil, nil, nil, nil, nil, cache.NewMemoryCache(10), time.Minute, zap.NewNop())
+
+	_, _, err := specialists.List(context.Background(), domain.SpecialistFilter{Limit: 10, Tags: []string{"КПТ", "кпт", "Семья"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(repo.listFilter.Tags, ","); got != "кпт,семья" {
+		t.Errorf("repository filter tags = %s, want кпт,семья", got)
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// Максимальная длина переведенного названия, как у основного поля specializations.name
+const maxTranslatedNameLength = 255
+
+// normalizeTranslations проверяет коды языков и обрезает пробелы в полях. Возвращает переводы для сохранения
+// и языки, переводы на которые нужно удалить, потому что все их поля пустые. Если allowName false,
+// перевод названия запрещен
+func normalizeTranslations(translations domain.Translations, allowName bool) (domain.Translations, []string, error) {
+	if len(translations) == 0 {
+		return nil, nil, fmt.Errorf("%w: не передано ни одного перевода", ErrInvalid)
+	}
+
+	upsert := make(domain.Translations, len(translations))
+	removed := make([]string, 0)
+	for code, translation := range translations {
+		lang := strings.ToLower(strings.TrimSpace(code))
+		if lang == domain.DefaultContentLanguage {
+			return nil, nil, fmt.Errorf("%w: русский вариант хранится в основных полях, перевод на %q не нужен", ErrInvalid, code)
+		}
+		if !domain.IsTranslationLanguage(lang) {
+			return nil, nil, fmt.Errorf("%w: перевод на язык %q не поддерживается", ErrInvalid, code)
+		}
+		if _, ok := upsert[lang]; ok {
+			return nil, nil, fmt.Errorf("%w: язык %q указан несколько раз", ErrInvalid, lang)
+		}
+
+		translation.Name = strings.TrimSpace(translation.Name)
+		translation.Description = strings.TrimSpace(translation.Description)
+		if translation.Name != "" && !allowName {
+			return nil, nil, fmt.Errorf("%w: для профиля специалиста переводится только description", ErrInvalid)
+		}
+		if utf8.RuneCountInString(translation.Name) > maxTranslatedNameLength {
+			return nil, nil, fmt.Errorf("%w: название длиннее %d символов", ErrInvalid, maxTranslatedNameLength)
+		}
+
+		if translation.IsEmpty() {
+			removed = append(removed, lang)
+			continue
+		}
+		upsert[lang] = translation
+	}
+
+	return upsert, removed, nil
+}
+
+// SetTranslations сохраняет переводы названия и описания специализации. Переводы на другие языки не меняются,
+// язык с пустыми полями удаляет свой перевод
+func (s *SpecializationServiceImpl) SetTranslations(ctx context.Context, id int64, translations domain.Translations) (*domain.Specialization, error) {
+	ctx, span := tracer.Start(ctx, "SpecializationService.SetTranslations")
+	defer span.End()
+
+	upsert, removed, err := normalizeTranslations(translations, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		s.logger.Warn("специализация для перевода не найдена", zap.Int64("id", id), zap.Error(err))
+		return nil, fmt.Errorf("%w: специализация не найдена", ErrNotFound)
+	}
+
+	if err := s.repo.SetTranslations(ctx, id, upsert, removed); err != nil {
+		s.logger.Error("ошибка сохранения переводов специализации", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении переводов")
+	}
+
+	invalidateCache(ctx, s.cache, s.logger, specializationsCachePrefix, specialistsCachePrefix)
+
+	specialization, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("ошибка получения специализации после перевода", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении переводов")
+	}
+
+	return specialization, nil
+}
+
+// SetTranslations сохраняет переводы описания профиля специалиста. Переводы на другие языки не меняются,
+// язык с пустым описанием удаляет свой перевод
+func (s *SpecialistServiceImpl) SetTranslations(ctx context.Context, specialistID int64, translations domain.Translations) (*domain.Specialist, error) {
+	ctx, span := tracer.Start(ctx, "SpecialistService.SetTranslations")
+	defer span.End()
+
+	upsert, removed, err := normalizeTranslations(translations, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.SetTranslations(ctx, specialistID, upsert, removed)
+	if errors.Is(err, repository.ErrSpecialistNotFound) {
+		return nil, fmt.Errorf("%w: специалист не найден", ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error("ошибка сохранения переводов специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении переводов")
+	}
+
+	invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+
+	specialist, err := s.repo.GetByID(ctx, specialistID)
+	if err != nil {
+		s.logger.Error("ошибка получения специалиста после перевода", zap.Int64("specialistID", specialistID), zap.Error(err))
+		return nil, errors.New("ошибка при сохранении переводов")
+	}
+
+	return specialist, nil
+}
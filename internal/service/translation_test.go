@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"laps/internal/domain"
+)
+
+func TestNormalizeTranslations(t *testing.T) {
+	upsert, removed, err := normalizeTranslations(domain.Translations{
+		" EN ": {Name: "  Psychologist ", Description: " About "},
+		"kk":   {Name: " ", Description: ""},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (domain.Translations{"en": {Name: "Psychologist", Description: "About"}}); !reflect.DeepEqual(upsert, want) {
+		t.Errorf("upsert = %+v, want %+v", upsert, want)
+	}
+	if !reflect.DeepEqual(removed, []string{"kk"}) {
+		t.Errorf("removed = %v, want [kk]", removed)
+	}
+}
+
+func TestNormalizeTranslationsInvalid(t *testing.T) {
+	tests := []struct {
+		name         string
+		translations domain.Translations
+		allowName    bool
+	}{
+		{"empty", domain.Translations{}, true},
+		{"russian", domain.Translations{"ru": {Name: "Психолог"}}, true},
+		{"unsupported language", domain.Translations{"de": {Name: "Psychologe"}}, true},
+		{"duplicate language", domain.Translations{"en": {Name: "A"}, "EN": {Name: "B"}}, true},
+		{"specialist name", domain.Translations{"en": {Name: "Anna", Description: "About"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := normalizeTranslations(tt.translations, tt.allowName); !errors.Is(err, ErrInvalid) {
+				t.Errorf("err = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
@@ -2,28 +2,73 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 
+	"laps/internal/cache"
 	"laps/internal/domain"
 	"laps/internal/repository"
 )
 
+// Время последней активности записывается в базу не чаще раза в минуту на пользователя
+const lastSeenWriteInterval = time.Minute
+
 type UserServiceImpl struct {
-	repo   repository.UserRepository
-	logger *zap.Logger
+	repo      repository.UserRepository
+	authRepo  repository.AuthRepository
+	auditRepo repository.AuditRepository
+	cache     cache.Cache
+	logger    *zap.Logger
+
+	// lastSeenWrites время последней записи last_seen_at для подключенных пользователей
+	lastSeenMu     sync.Mutex
+	lastSeenWrites map[int64]time.Time
 }
 
-func NewUserService(repo repository.UserRepository, logger *zap.Logger) *UserServiceImpl {
+func NewUserService(repo repository.UserRepository, authRepo repository.AuthRepository, auditRepo repository.AuditRepository, c cache.Cache, logger *zap.Logger) *UserServiceImpl {
 	return &UserServiceImpl{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		authRepo:  authRepo,
+		auditRepo: auditRepo,
+		cache:     c,
+		logger:    logger,
+
+		lastSeenWrites: make(map[int64]time.Time),
 	}
 }
 
+// TouchLastSeen отмечает активность пользователя. Запись в базу пропускается, если с прошлой прошло меньше минуты.
+// final — пользователь отключился: время записывается всегда, и троттлинг для него сбрасывается
+func (s *UserServiceImpl) TouchLastSeen(ctx context.Context, userID int64, final bool) error {
+	now := time.Now()
+
+	s.lastSeenMu.Lock()
+	last, seen := s.lastSeenWrites[userID]
+	if !final && seen && now.Sub(last) < lastSeenWriteInterval {
+		s.lastSeenMu.Unlock()
+		return nil
+	}
+	if final {
+		delete(s.lastSeenWrites, userID)
+	} else {
+		s.lastSeenWrites[userID] = now
+	}
+	s.lastSeenMu.Unlock()
+
+	if err := s.repo.UpdateLastSeen(ctx, userID, now); err != nil {
+		s.logger.Error("ошибка обновления времени активности", zap.Int64("userID", userID), zap.Error(err))
+		return errors.New("ошибка при обновлении времени активности")
+	}
+
+	return nil
+}
+
 func (s *UserServiceImpl) Create(ctx context.Context, dto domain.CreateUserDTO) (int64, error) {
 	existingUser, err := s.repo.GetByEmail(ctx, dto.Email)
 	if err == nil && existingUser != nil {
@@ -114,7 +159,25 @@ func (s *UserServiceImpl) UpdatePassword(ctx context.Context, id int64, dto doma
 		return errors.New("неверный текущий пароль")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(dto.NewPassword), bcrypt.DefaultCost)
+	return s.setPassword(ctx, id, dto.NewPassword)
+}
+
+// ResetPassword задает новый пароль без проверки текущего, например по ссылке из письма для восстановления.
+// Все сессии пользователя отзываются, чтобы тот, кто знал старый пароль, больше не мог войти
+func (s *UserServiceImpl) ResetPassword(ctx context.Context, id int64, newPassword string) error {
+	if err := s.setPassword(ctx, id, newPassword); err != nil {
+		return err
+	}
+
+	if err := s.authRepo.DeleteSessionsByUserID(ctx, id); err != nil {
+		s.logger.Error("ошибка отзыва сессий после восстановления пароля", zap.Int64("id", id), zap.Error(err))
+	}
+
+	return nil
+}
+
+func (s *UserServiceImpl) setPassword(ctx context.Context, id int64, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		s.logger.Error("ошибка при хешировании нового пароля", zap.Error(err))
 		return errors.New("ошибка при обновлении пароля")
@@ -129,6 +192,47 @@ func (s *UserServiceImpl) UpdatePassword(ctx context.Context, id int64, dto doma
 	return nil
 }
 
+// DeleteAccount удаляет аккаунт по запросу самого пользователя: после проверки пароля персональные данные
+// заменяются заглушками, профиль специалиста скрывается из каталога, а все сессии отзываются.
+// Отзывы и записи остаются и учитываются в рейтингах
+func (s *UserServiceImpl) DeleteAccount(ctx context.Context, id int64, dto domain.DeleteAccountDTO) error {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("пользователь для удаления аккаунта не найден", zap.Int64("id", id), zap.Error(err))
+		return fmt.Errorf("%w: пользователь не найден", ErrNotFound)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(dto.Password)); err != nil {
+		return fmt.Errorf("%w: неверный пароль", ErrInvalid)
+	}
+
+	removedSpecialistID, err := s.repo.Anonymize(ctx, id)
+	if err != nil {
+		s.logger.Error("ошибка анонимизации пользователя", zap.Int64("id", id), zap.Error(err))
+		return errors.New("ошибка при удалении аккаунта")
+	}
+	if removedSpecialistID != nil {
+		invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+	}
+
+	// Access-токены не отзываются и истекают сами; без refresh-сессий продлить их нельзя
+	if err := s.authRepo.DeleteSessionsByUserID(ctx, id); err != nil {
+		s.logger.Error("ошибка отзыва сессий удаленного пользователя", zap.Int64("id", id), zap.Error(err))
+	}
+
+	err = s.auditRepo.Log(ctx, domain.AuditEntry{
+		ActorID:    &id,
+		Action:     domain.AuditActionUserAnonymize,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   id,
+	})
+	if err != nil {
+		s.logger.Error("ошибка записи анонимизации в журнал аудита", zap.Int64("id", id), zap.Error(err))
+	}
+
+	return nil
+}
+
 func (s *UserServiceImpl) Delete(ctx context.Context, id int64) error {
 	_, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -162,3 +266,76 @@ func (s *UserServiceImpl) List(ctx context.Context, limit, offset int) ([]domain
 
 	return users, nil
 }
+
+// userRoleAudit роль пользователя, сохраняемая в журнал аудита до и после изменения
+type userRoleAudit struct {
+	Role domain.UserRole `json:"role"`
+}
+
+// ChangeRole меняет роль пользователя по решению администратора actorID. Профиль специалиста при назначении
+// роли specialist не создается, а при снятии этой роли мягко удаляется. Изменение записывается в журнал аудита.
+// Сессии пользователя отзываются, чтобы прежнюю роль нельзя было продлить refresh-токеном: новая роль
+// попадает в токены при следующем входе
+func (s *UserServiceImpl) ChangeRole(ctx context.Context, actorID, id int64, role domain.UserRole) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ChangeRole")
+	defer span.End()
+
+	if !role.IsValid() {
+		return nil, fmt.Errorf("%w: неизвестная роль %q", ErrInvalid, role)
+	}
+	if actorID == id {
+		return nil, fmt.Errorf("%w: нельзя изменить собственную роль", ErrInvalid)
+	}
+
+	previous, removedSpecialistID, err := s.repo.ChangeRole(ctx, id, role)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: пользователь не найден", ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error("ошибка изменения роли пользователя", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New("ошибка при изменении роли пользователя")
+	}
+
+	if previous != role {
+		if removedSpecialistID != nil {
+			invalidateCache(ctx, s.cache, s.logger, specialistsCachePrefix)
+		}
+		s.auditRoleChange(ctx, actorID, id, previous, role)
+
+		if err := s.authRepo.DeleteSessionsByUserID(ctx, id); err != nil {
+			s.logger.Error("ошибка отзыва сессий после изменения роли", zap.Int64("id", id), zap.Error(err))
+		}
+
+		s.logger.Info("роль пользователя изменена",
+			zap.Int64("id", id),
+			zap.String("from", string(previous)),
+			zap.String("to", string(role)),
+			zap.Int64("actorID", actorID))
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("ошибка получения пользователя после изменения роли", zap.Int64("id", id), zap.Error(err))
+		return nil, errors.New("ошибка при изменении роли пользователя")
+	}
+
+	return user, nil
+}
+
+// auditRoleChange записывает изменение роли в журнал аудита. Роль уже изменена, поэтому ошибка только логируется
+func (s *UserServiceImpl) auditRoleChange(ctx context.Context, actorID, id int64, previous, role domain.UserRole) {
+	oldValue, _ := json.Marshal(userRoleAudit{Role: previous})
+	newValue, _ := json.Marshal(userRoleAudit{Role: role})
+
+	err := s.auditRepo.Log(ctx, domain.AuditEntry{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionUserRoleChange,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   id,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+	})
+	if err != nil {
+		s.logger.Error("ошибка записи изменения роли в журнал аудита", zap.Int64("id", id), zap.Error(err))
+	}
+}
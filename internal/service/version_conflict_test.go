@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// Два сохранения недели с одной и той же прочитанной версией: второе не должно затереть первое
+func TestScheduleUpdateLostUpdate(t *testing.T) {
+	repo := &fakeScheduleRepo{weekVersion: 3}
+	schedules := NewScheduleService(repo, &fakeSpecialistRepo{}, newFakeAppointmentRepo(), &fakeCalendarRepo{}, zap.NewNop())
+
+	seen := 3
+	week := func(start string) domain.UpdateScheduleDTO {
+		return domain.UpdateScheduleDTO{
+			WeekSchedule: domain.WeekSchedule{Monday: &domain.DaySchedule{
+				WorkTime: []domain.WorkTimeSlot{{StartTime: start, EndTime: "18:00"}},
+			}},
+			ExpectedVersion: &seen,
+		}
+	}
+
+	if err := schedules.Update(context.Background(), 7, week("09:00")); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	err := schedules.Update(context.Background(), 7, week("11:00"))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale save: err = %v, want ErrVersionConflict", err)
+	}
+	if len(repo.replaced) != 1 || repo.replaced[0].StartTime != "09:00" {
+		t.Errorf("stored week = %+v, want the first save", repo.replaced)
+	}
+}
+
+func TestSpecialistUpdateVersionConflict(t *testing.T) {
+	specialistRepo := &fakeSpecialistRepo{
+		specialist: &domain.Specialist{ID: 7, UserID: 70, Version: 2},
+		updateErr:  repository.ErrVersionConflict,
+	}
+	specialists := NewSpecialistService(specialistRepo, &fakeUserRepo{}, nil, nil, nil, nil, nil, nil, time.Minute, zap.NewNop())
+
+	seen := 1
+	description := "stale"
+	err := specialists.Update(context.Background(), 7, domain.UpdateSpecialistDTO{Description: &description, ExpectedVersion: &seen})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("err = %v, want ErrVersionConflict", err)
+	}
+}
+
+func TestAppointmentUpdateVersionConflict(t *testing.T) {
+	f := newAppointmentFixture()
+	f.repo.appointments[1] = &domain.Appointment{ID: 1, ClientID: 1, SpecialistID: 7, Status: domain.AppointmentStatusPending}
+	f.repo.updateErr = repository.ErrVersionConflict
+
+	seen := time.Now().Add(-time.Minute)
+	cancelled := domain.AppointmentStatusCancelled
+	_, err := f.service.Update(context.Background(), 1, domain.UpdateAppointmentDTO{Status: &cancelled, ExpectedUpdatedAt: &seen})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("err = %v, want ErrVersionConflict", err)
+	}
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+const (
+	// Доставленные события хранятся для разбора инцидентов, затем удаляются
+	deliveredEventRetention = 7 * 24 * time.Hour
+	// Запас времени на отправку пачки событий, в течение которого их не выберет другой экземпляр
+	outboxClaimLease = 5 * time.Minute
+
+	// Доставка на зарегистрированный адрес прекращается после стольких неудачных попыток
+	webhookDeliveryMaxAttempts = 5
+
+	webhookSignatureHeader = "X-Webhook-Signature"
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookEventIDHeader   = "X-Webhook-Event-ID"
+)
+
+// webhookOutboxNamespace пространство имен для ID событий webhook, полученных из outbox
+var webhookOutboxNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("laps:outbox_events"))
+
+// WebhookServiceImpl отправляет события записей из outbox на адреса из конфигурации и доменные события
+// на адреса, зарегистрированные администратором. Доставка «хотя бы один раз»: получатель отбрасывает
+// повторы по X-Webhook-Event-ID
+type WebhookServiceImpl struct {
+	repo      repository.OutboxRepository
+	endpoints repository.WebhookRepository
+	cfg       config.WebhookConfig
+	client    *http.Client
+	logger    *zap.Logger
+}
+
+func NewWebhookService(repo repository.OutboxRepository, endpoints repository.WebhookRepository, cfg config.WebhookConfig, logger *zap.Logger) *WebhookServiceImpl {
+	return &WebhookServiceImpl{
+		repo:      repo,
+		endpoints: endpoints,
+		cfg:       cfg,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		logger:    logger,
+	}
+}
+
+// CreateEndpoint регистрирует адрес внешней системы для событий dto.EventTypes
+func (s *WebhookServiceImpl) CreateEndpoint(ctx context.Context, dto domain.CreateWebhookEndpointDTO) (*domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.CreateEndpoint")
+	defer span.End()
+
+	endpointURL := strings.TrimSpace(dto.URL)
+	parsed, err := url.Parse(endpointURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: адрес webhook должен быть абсолютным URL http или https", ErrInvalid)
+	}
+
+	eventTypes := make([]domain.WebhookEventType, 0, len(dto.EventTypes))
+	seen := make(map[domain.WebhookEventType]bool, len(dto.EventTypes))
+	for _, eventType := range dto.EventTypes {
+		if !eventType.IsValid() {
+			return nil, fmt.Errorf("%w: неизвестное событие %q", ErrInvalid, eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	endpoint := &domain.WebhookEndpoint{
+		URL:        endpointURL,
+		Secret:     dto.Secret,
+		EventTypes: eventTypes,
+		IsActive:   true,
+	}
+	if err := s.endpoints.CreateEndpoint(ctx, endpoint); err != nil {
+		s.logger.Error("ошибка регистрации адреса webhook", zap.String("url", endpointURL), zap.Error(err))
+		return nil, errors.New("ошибка при регистрации адреса webhook")
+	}
+
+	s.logger.Info("зарегистрирован адрес webhook", zap.Int64("endpointID", endpoint.ID), zap.String("url", endpointURL))
+
+	return endpoint, nil
+}
+
+func (s *WebhookServiceImpl) ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.ListEndpoints")
+	defer span.End()
+
+	endpoints, err := s.endpoints.ListEndpoints(ctx)
+	if err != nil {
+		s.logger.Error("ошибка получения адресов webhook", zap.Error(err))
+		return nil, errors.New("ошибка при получении адресов webhook")
+	}
+
+	return endpoints, nil
+}
+
+// DeleteEndpoint удаляет адрес; недоставленные на него события больше не отправляются
+func (s *WebhookServiceImpl) DeleteEndpoint(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.DeleteEndpoint")
+	defer span.End()
+
+	err := s.endpoints.DeleteEndpoint(ctx, id)
+	if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+		return fmt.Errorf("%w: адрес webhook не найден", ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error("ошибка удаления адреса webhook", zap.Int64("endpointID", id), zap.Error(err))
+		return errors.New("ошибка при удалении адреса webhook")
+	}
+
+	return nil
+}
+
+// ListDeliveries возвращает доставки на адрес от новых к старым для разбора ошибок интеграции
+func (s *WebhookServiceImpl) ListDeliveries(ctx context.Context, endpointID int64, limit, offset int) ([]domain.WebhookDelivery, int, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.ListDeliveries")
+	defer span.End()
+
+	_, err := s.endpoints.GetEndpoint(ctx, endpointID)
+	if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+		return nil, 0, fmt.Errorf("%w: адрес webhook не найден", ErrNotFound)
+	}
+	if err != nil {
+		s.logger.Error("ошибка получения адреса webhook", zap.Int64("endpointID", endpointID), zap.Error(err))
+		return nil, 0, errors.New("ошибка при получении доставок webhook")
+	}
+
+	deliveries, total, err := s.endpoints.ListDeliveries(ctx, endpointID, limit, offset)
+	if err != nil {
+		s.logger.Error("ошибка получения доставок webhook", zap.Int64("endpointID", endpointID), zap.Error(err))
+		return nil, 0, errors.New("ошибка при получении доставок webhook")
+	}
+
+	return deliveries, total, nil
+}
+
+// Publish ставит доменное событие в очередь доставки на подписанные адреса. Вызывается из WebhookBus;
+// постановка не зависит от отмены ctx запроса, породившего событие
+func (s *WebhookServiceImpl) Publish(ctx context.Context, eventType domain.WebhookEventType, data interface{}) {
+	ctx, span := tracer.Start(context.WithoutCancel(ctx), "WebhookService.Publish")
+	defer span.End()
+
+	if err := s.enqueue(ctx, uuid.NewString(), eventType, time.Now(), data); err != nil {
+		s.logger.Error("ошибка постановки события webhook в очередь", zap.String("event", string(eventType)), zap.Error(err))
+	}
+}
+
+func (s *WebhookServiceImpl) enqueue(ctx context.Context, eventID string, eventType domain.WebhookEventType, occurredAt time.Time, data interface{}) error {
+	payload, err := json.Marshal(domain.WebhookEventPayload{
+		ID:         eventID,
+		Event:      eventType,
+		OccurredAt: occurredAt,
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации события: %w", err)
+	}
+
+	queued, err := s.endpoints.Enqueue(ctx, eventID, eventType, payload)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		s.logger.Debug("событие webhook поставлено в очередь",
+			zap.String("event", string(eventType)), zap.String("eventID", eventID), zap.Int64("endpoints", queued))
+	}
+
+	return nil
+}
+
+// enqueueAppointmentEvent ставит событие записи из outbox в очередь зарегистрированных адресов. ID события
+// выводится из ID события outbox, поэтому повторная обработка того же события не создает новых доставок
+func (s *WebhookServiceImpl) enqueueAppointmentEvent(ctx context.Context, event domain.OutboxEvent) error {
+	eventType, ok := domain.WebhookEventForAppointmentEvent(event.EventType)
+	if !ok {
+		return nil
+	}
+
+	var payload domain.AppointmentEventPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("ошибка разбора события записи: %w", err)
+	}
+
+	data := domain.AppointmentWebhookData{Appointment: payload.Appointment}
+	if eventType == domain.WebhookEventAppointmentStatusChanged {
+		data.Status = payload.Appointment.Status
+	}
+
+	eventID := uuid.NewSHA1(webhookOutboxNamespace, []byte(strconv.FormatInt(event.ID, 10))).String()
+	return s.enqueue(ctx, eventID, eventType, payload.OccurredAt, data)
+}
+
+// RunDispatcher отправляет накопившиеся события с интервалом cfg.PollInterval, пока не отменен ctx
+func (s *WebhookServiceImpl) RunDispatcher(ctx context.Context) {
+	if s.cfg.PollInterval <= 0 {
+		return
+	}
+
+	if len(s.cfg.URLs) == 0 {
+		s.logger.Info("адреса webhook в конфигурации не заданы, события отправляются только на зарегистрированные адреса")
+	}
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDue(ctx)
+		s.deliverDue(ctx)
+
+		before := time.Now().Add(-deliveredEventRetention)
+		if purged, err := s.repo.PurgeDelivered(ctx, before); err != nil && ctx.Err() == nil {
+			s.logger.Error("ошибка удаления доставленных событий", zap.Error(err))
+		} else if purged > 0 {
+			s.logger.Debug("удалены доставленные события", zap.Int64("count", purged))
+		}
+		if purged, err := s.endpoints.PurgeDelivered(ctx, before); err != nil && ctx.Err() == nil {
+			s.logger.Error("ошибка удаления доставленных webhook", zap.Error(err))
+		} else if purged > 0 {
+			s.logger.Debug("удалены доставленные webhook", zap.Int64("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WebhookServiceImpl) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := s.repo.ClaimDue(ctx, s.cfg.BatchSize, outboxClaimLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("ошибка выборки событий для отправки", zap.Error(err))
+			}
+			return
+		}
+
+		for _, event := range events {
+			s.dispatch(ctx, event)
+		}
+
+		if len(events) < s.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// dispatch ставит событие в очередь зарегистрированных адресов и отправляет его на все адреса из конфигурации;
+// при ошибке хотя бы одного шага событие обрабатывается повторно после паузы
+func (s *WebhookServiceImpl) dispatch(ctx context.Context, event domain.OutboxEvent) {
+	deliveryErr := s.enqueueAppointmentEvent(ctx, event)
+	if deliveryErr == nil {
+		for _, url := range s.cfg.URLs {
+			if err := s.send(ctx, url, event); err != nil {
+				deliveryErr = fmt.Errorf("%s: %w", url, err)
+				break
+			}
+		}
+	}
+
+	if deliveryErr == nil {
+		if err := s.repo.MarkDelivered(ctx, event.ID); err != nil {
+			s.logger.Error("ошибка отметки доставки события", zap.Int64("eventID", event.ID), zap.Error(err))
+		}
+		return
+	}
+
+	attempt := event.Attempts + 1
+	var nextAttemptAt *time.Time
+	if attempt < s.cfg.MaxAttempts {
+		next := time.Now().Add(s.backoff(attempt))
+		nextAttemptAt = &next
+	}
+
+	s.logger.Warn("ошибка отправки события webhook",
+		zap.Int64("eventID", event.ID),
+		zap.String("event", string(event.EventType)),
+		zap.Int("attempt", attempt),
+		zap.Bool("willRetry", nextAttemptAt != nil),
+		zap.Error(deliveryErr))
+
+	if err := s.repo.MarkFailed(ctx, event.ID, deliveryErr.Error(), nextAttemptAt); err != nil {
+		s.logger.Error("ошибка сохранения ошибки отправки события", zap.Int64("eventID", event.ID), zap.Error(err))
+	}
+}
+
+func (s *WebhookServiceImpl) send(ctx context.Context, url string, event domain.OutboxEvent) error {
+	_, err := s.post(ctx, url, s.cfg.Secret, string(event.EventType), strconv.FormatInt(event.ID, 10), event.Payload)
+	return err
+}
+
+func (s *WebhookServiceImpl) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		jobs, err := s.endpoints.ClaimDue(ctx, s.cfg.BatchSize, outboxClaimLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("ошибка выборки доставок webhook", zap.Error(err))
+			}
+			return
+		}
+
+		for _, job := range jobs {
+			s.deliver(ctx, job)
+		}
+
+		if len(jobs) < s.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// deliver отправляет событие на зарегистрированный адрес, подписывая тело секретом адреса. После
+// webhookDeliveryMaxAttempts неудачных попыток доставка прекращается
+func (s *WebhookServiceImpl) deliver(ctx context.Context, job domain.WebhookDeliveryJob) {
+	statusCode, deliveryErr := s.post(ctx, job.URL, job.Secret, string(job.EventType), job.EventID, job.Payload)
+	if deliveryErr == nil {
+		if err := s.endpoints.MarkDelivered(ctx, job.ID, statusCode); err != nil {
+			s.logger.Error("ошибка отметки доставки webhook", zap.Int64("deliveryID", job.ID), zap.Error(err))
+		}
+		return
+	}
+
+	attempt := job.Attempts + 1
+	var nextAttemptAt *time.Time
+	if attempt < webhookDeliveryMaxAttempts {
+		next := time.Now().Add(s.backoff(attempt))
+		nextAttemptAt = &next
+	}
+
+	var lastStatusCode *int
+	if statusCode != 0 {
+		lastStatusCode = &statusCode
+	}
+
+	s.logger.Warn("ошибка доставки webhook",
+		zap.Int64("deliveryID", job.ID),
+		zap.String("event", string(job.EventType)),
+		zap.String("url", job.URL),
+		zap.Int("attempt", attempt),
+		zap.Bool("willRetry", nextAttemptAt != nil),
+		zap.Error(deliveryErr))
+
+	if err := s.endpoints.MarkFailed(ctx, job.ID, lastStatusCode, deliveryErr.Error(), nextAttemptAt); err != nil {
+		s.logger.Error("ошибка сохранения ошибки доставки webhook", zap.Int64("deliveryID", job.ID), zap.Error(err))
+	}
+}
+
+// post отправляет подписанное тело события и возвращает код ответа; 0, если ответ не получен
+func (s *WebhookServiceImpl) post(ctx context.Context, url, secret, eventType, eventID string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, eventType)
+	req.Header.Set(webhookEventIDHeader, eventID)
+	req.Header.Set(webhookSignatureHeader, "sha256="+signWebhookPayload(secret, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, errors.New("статус ответа " + strconv.Itoa(resp.StatusCode))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff экспоненциально увеличивает паузу между попытками, начиная с InitialBackoff, но не больше MaxBackoff
+func (s *WebhookServiceImpl) backoff(attempt int) time.Duration {
+	delay := s.cfg.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= s.cfg.MaxBackoff {
+			return s.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// signWebhookPayload возвращает HMAC-SHA256 тела запроса в hex
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"laps/internal/domain"
+)
+
+// WebhookPublisher принимает доменное событие для внешних систем. Ошибки доставки не возвращаются:
+// событие уже произошло, и операция, которая его породила, не должна из-за них откатываться
+type WebhookPublisher interface {
+	Publish(ctx context.Context, eventType domain.WebhookEventType, data interface{})
+}
+
+// WebhookBus передает доменные события сервисов подписчикам. Сервисы публикуют события в шину,
+// а не вызывают доставку напрямую, поэтому им не нужно знать об адресах и очереди доставки
+type WebhookBus struct {
+	mu          sync.RWMutex
+	subscribers []WebhookPublisher
+}
+
+func NewWebhookBus() *WebhookBus {
+	return &WebhookBus{}
+}
+
+// Subscribe добавляет подписчика, которому передаются все последующие события
+func (b *WebhookBus) Subscribe(subscriber WebhookPublisher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+func (b *WebhookBus) Publish(ctx context.Context, eventType domain.WebhookEventType, data interface{}) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber.Publish(ctx, eventType, data)
+	}
+}
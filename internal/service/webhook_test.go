@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/repository"
+)
+
+// fakeWebhookRepo отдает очередь доставок из памяти и запоминает результат каждой попытки
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+
+	mu        sync.Mutex
+	due       []domain.WebhookDeliveryJob
+	delivered map[int64]int
+	failed    map[int64]webhookFailure
+}
+
+type webhookFailure struct {
+	statusCode    *int
+	message       string
+	nextAttemptAt *time.Time
+}
+
+func newFakeWebhookRepo(jobs ...domain.WebhookDeliveryJob) *fakeWebhookRepo {
+	return &fakeWebhookRepo{
+		due:       jobs,
+		delivered: make(map[int64]int),
+		failed:    make(map[int64]webhookFailure),
+	}
+}
+
+func (r *fakeWebhookRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDeliveryJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.due) > limit {
+		jobs := r.due[:limit]
+		r.due = r.due[limit:]
+		return jobs, nil
+	}
+	jobs := r.due
+	r.due = nil
+	return jobs, nil
+}
+
+func (r *fakeWebhookRepo) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delivered[id] = statusCode
+	return nil
+}
+
+func (r *fakeWebhookRepo) MarkFailed(ctx context.Context, id int64, statusCode *int, message string, nextAttemptAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed[id] = webhookFailure{statusCode: statusCode, message: message, nextAttemptAt: nextAttemptAt}
+	return nil
+}
+
+func newTestWebhookService(endpoints repository.WebhookRepository) *WebhookServiceImpl {
+	return NewWebhookService(nil, endpoints, config.WebhookConfig{
+		Timeout:        time.Second,
+		BatchSize:      10,
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}, zap.NewNop())
+}
+
+func TestWebhookDeliverSignsPayload(t *testing.T) {
+	const secret = "endpoint-secret"
+	payload := []byte(`{"id":"evt-1","event":"review.created"}`)
+
+	type received struct {
+		body      []byte
+		signature string
+		event     string
+		eventID   string
+	}
+	got := make(chan received, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{
+			body:      body,
+			signature: r.Header.Get(webhookSignatureHeader),
+			event:     r.Header.Get(webhookEventHeader),
+			eventID:   r.Header.Get(webhookEventIDHeader),
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := newFakeWebhookRepo(domain.WebhookDeliveryJob{
+		ID:        1,
+		EventID:   "evt-1",
+		EventType: domain.WebhookEventReviewCreated,
+		Payload:   payload,
+		URL:       receiver.URL,
+		Secret:    secret,
+	})
+	newTestWebhookService(repo).deliverDue(context.Background())
+
+	req := <-got
+	if string(req.body) != string(payload) {
+		t.Fatalf("body = %s, want %s", req.body, payload)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); req.signature != want {
+		t.Errorf("signature = %q, want %q", req.signature, want)
+	}
+	if req.event != "review.created" {
+		t.Errorf("event header = %q", req.event)
+	}
+	if req.eventID != "evt-1" {
+		t.Errorf("event id header = %q", req.eventID)
+	}
+
+	if status, ok := repo.delivered[1]; !ok || status != http.StatusNoContent {
+		t.Errorf("delivered = %v, want status %d", repo.delivered, http.StatusNoContent)
+	}
+	if len(repo.failed) != 0 {
+		t.Errorf("unexpected failures: %v", repo.failed)
+	}
+}
+
+func TestWebhookDeliverSchedulesRetryOnError(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	repo := newFakeWebhookRepo(
+		domain.WebhookDeliveryJob{ID: 1, EventID: "evt-1", Payload: []byte(`{}`), URL: receiver.URL, Attempts: 0},
+		domain.WebhookDeliveryJob{ID: 2, EventID: "evt-2", Payload: []byte(`{}`), URL: receiver.URL, Attempts: webhookDeliveryMaxAttempts - 1},
+	)
+	before := time.Now()
+	newTestWebhookService(repo).deliverDue(context.Background())
+
+	if len(repo.delivered) != 0 {
+		t.Fatalf("unexpected deliveries: %v", repo.delivered)
+	}
+
+	first, ok := repo.failed[1]
+	if !ok {
+		t.Fatal("first delivery was not marked failed")
+	}
+	if first.statusCode == nil || *first.statusCode != http.StatusServiceUnavailable {
+		t.Errorf("status code = %v, want %d", first.statusCode, http.StatusServiceUnavailable)
+	}
+	if first.nextAttemptAt == nil {
+		t.Fatal("first delivery should be retried")
+	}
+	if delay := first.nextAttemptAt.Sub(before); delay < time.Second || delay > 2*time.Second {
+		t.Errorf("retry delay = %s, want about InitialBackoff", delay)
+	}
+
+	last, ok := repo.failed[2]
+	if !ok {
+		t.Fatal("last delivery was not marked failed")
+	}
+	if last.nextAttemptAt != nil {
+		t.Errorf("delivery after %d attempts should not be retried", webhookDeliveryMaxAttempts)
+	}
+}
+
+func TestWebhookDeliverUnreachableReceiver(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	repo := newFakeWebhookRepo(domain.WebhookDeliveryJob{ID: 1, EventID: "evt-1", Payload: []byte(`{}`), URL: url})
+	newTestWebhookService(repo).deliverDue(context.Background())
+
+	failure, ok := repo.failed[1]
+	if !ok {
+		t.Fatal("delivery was not marked failed")
+	}
+	if failure.statusCode != nil {
+		t.Errorf("status code = %d, want none", *failure.statusCode)
+	}
+	if failure.nextAttemptAt == nil {
+		t.Error("delivery should be retried")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	s := newTestWebhookService(nil)
+	s.cfg.InitialBackoff = 10 * time.Second
+	s.cfg.MaxBackoff = time.Minute
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{4, time.Minute},
+		{10, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := s.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -158,3 +158,15 @@ func (s *S3Storage) GetPresignedURL(ctx context.Context, fileURL string, expiry
 
 	return presignedURL.String(), nil
 }
+
+// Ping проверяет, что бакет доступен с текущими учетными данными (HEAD bucket)
+func (s *S3Storage) Ping(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.cfg.Bucket)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки бакета: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("бакет %s не найден", s.cfg.Bucket)
+	}
+	return nil
+}
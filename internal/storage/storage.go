@@ -13,4 +13,7 @@ type FileStorage interface {
 	GetFile(ctx context.Context, fileURL string) ([]byte, error)
 
 	GetPresignedURL(ctx context.Context, fileURL string, expiry time.Duration) (string, error)
+
+	// Ping проверяет доступность хранилища
+	Ping(ctx context.Context) error
 }
@@ -0,0 +1,376 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Журнал аудита
+// @Description Возвращает изменения, внесенные администраторами, от новых к старым. Доступно только администраторам
+// @Tags Администрирование
+// @Produce json
+// @Param entity_type query string false "Тип сущности (например, specialist)"
+// @Param entity_id query int false "ID сущности"
+// @Param actor_id query int false "ID пользователя, внесшего изменение"
+// @Param action query string false "Действие (например, specialist.verify)"
+// @Param start_date query string false "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string false "Конечная дата включительно (YYYY-MM-DD)"
+// @Param limit query int false "Количество записей (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} paginatedResponse{data=[]domain.AuditEntry} "Записи журнала аудита с пагинацией"
+// @Failure 400 {object} errorResponseBody "Неверный формат параметров"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/audit-log [get]
+func (h *Handler) getAuditLog(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
+
+	filter := domain.AuditFilter{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if entityType := c.Query("entity_type"); entityType != "" {
+		value := domain.AuditEntityType(entityType)
+		filter.EntityType = &value
+	}
+
+	if action := c.Query("action"); action != "" {
+		value := domain.AuditAction(action)
+		filter.Action = &value
+	}
+
+	if entityIDStr := c.Query("entity_id"); entityIDStr != "" {
+		entityID, err := strconv.ParseInt(entityIDStr, 10, 64)
+		if err != nil {
+			badRequestResponse(c, "неверный формат entity_id")
+			return
+		}
+		filter.EntityID = &entityID
+	}
+
+	if actorIDStr := c.Query("actor_id"); actorIDStr != "" {
+		actorID, err := strconv.ParseInt(actorIDStr, 10, 64)
+		if err != nil {
+			badRequestResponse(c, "неверный формат actor_id")
+			return
+		}
+		filter.ActorID = &actorID
+	}
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			badRequestResponse(c, "неверный формат start_date, ожидается YYYY-MM-DD")
+			return
+		}
+		filter.StartDate = &startDate
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			badRequestResponse(c, "неверный формат end_date, ожидается YYYY-MM-DD")
+			return
+		}
+		filter.EndDate = &endDate
+	}
+
+	entries, total, err := h.services.Audit.List(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("ошибка получения журнала аудита", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка получения журнала аудита")
+		return
+	}
+
+	page := offset/limit + 1
+	paginatedSuccessResponse(c, entries, total, page, limit)
+}
+
+// @Summary История изменений специалиста
+// @Description Возвращает события изменения профиля специалиста от старых к новым. В payload события
+// @Description specialist_updated перечислены только изменившиеся поля со старым и новым значением. Доступно только администраторам
+// @Tags Администрирование
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Param limit query int false "Количество записей (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} paginatedResponse{data=[]domain.Event} "События специалиста с пагинацией"
+// @Failure 400 {object} errorResponseBody "Неверный ID специалиста"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/specialists/{id}/events [get]
+func (h *Handler) getSpecialistEvents(c *gin.Context) {
+	specialistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный ID специалиста")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
+
+	events, total, err := h.services.Event.ListSpecialistEvents(c.Request.Context(), specialistID, limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(c, "специалист не найден")
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := offset/limit + 1
+	paginatedSuccessResponse(c, events, total, page, limit)
+}
+
+// @Summary Поиск записей
+// @Description Ищет записи всех клиентов и специалистов. search — слова из ФИО клиента или специалиста (без учета регистра)
+// @Description или дата в формате YYYY-MM-DD либо ДД.ММ.ГГГГ. Остальные параметры совпадают с фильтрами списка записей.
+// @Description Доступно только администраторам
+// @Tags Администрирование
+// @Produce json
+// @Param search query string false "ФИО клиента или специалиста, либо дата записи"
+// @Param client_id query int false "ID клиента"
+// @Param specialist_id query int false "ID специалиста"
+// @Param status query string false "Статус записи" Enums(pending,paid,completed,cancelled)
+// @Param start_date query string false "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string false "Конечная дата включительно (YYYY-MM-DD)"
+// @Param limit query int false "Количество записей (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} paginatedResponse{data=[]domain.Appointment} "Найденные записи с пагинацией"
+// @Failure 400 {object} errorResponseBody "Неверный формат параметров"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/appointments [get]
+func (h *Handler) searchAppointments(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
+
+	filter := domain.AppointmentFilter{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if clientIDStr := c.Query("client_id"); clientIDStr != "" {
+		clientID, err := strconv.ParseInt(clientIDStr, 10, 64)
+		if err != nil {
+			badRequestResponse(c, "неверный формат client_id")
+			return
+		}
+		filter.ClientID = &clientID
+	}
+
+	if specialistIDStr := c.Query("specialist_id"); specialistIDStr != "" {
+		specialistID, err := strconv.ParseInt(specialistIDStr, 10, 64)
+		if err != nil {
+			badRequestResponse(c, "неверный формат specialist_id")
+			return
+		}
+		filter.SpecialistID = &specialistID
+	}
+
+	if statusStr := c.Query("status"); statusStr != "" {
+		status := domain.AppointmentStatus(statusStr)
+		filter.Status = &status
+	}
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			badRequestResponse(c, "неверный формат start_date, ожидается YYYY-MM-DD")
+			return
+		}
+		filter.StartDate = &startDate
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			badRequestResponse(c, "неверный формат end_date, ожидается YYYY-MM-DD")
+			return
+		}
+		endDate = endDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		filter.EndDate = &endDate
+	}
+
+	// Дата в строке поиска сужает выборку до этого дня, остальное ищется по ФИО
+	search := strings.TrimSpace(c.Query("search"))
+	if day, ok := parseSearchDate(search); ok {
+		dayEnd := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		filter.StartDate, filter.EndDate = &day, &dayEnd
+	} else {
+		filter.Search = search
+	}
+
+	appointments, total, err := h.services.Appointment.List(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("ошибка поиска записей", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка поиска записей")
+		return
+	}
+
+	page := offset/limit + 1
+	paginatedSuccessResponse(c, appointments, total, page, limit)
+}
+
+// parseSearchDate распознает дату в строке поиска в формате YYYY-MM-DD или ДД.ММ.ГГГГ
+func parseSearchDate(search string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if day, err := time.Parse(layout, search); err == nil {
+			return day, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// @Summary Восстановить специалиста
+// @Description Возвращает удаленного специалиста в каталог и снова открывает запись к нему. Доступно только администраторам
+// @Tags Администрирование
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Success 200 {object} domain.Specialist "Восстановленный специалист"
+// @Failure 400 {object} errorResponseBody "Неверный ID специалиста"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Удаленный специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/specialists/{id}/restore [post]
+func (h *Handler) restoreSpecialist(c *gin.Context) {
+	specialistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный ID специалиста")
+		return
+	}
+
+	err = h.services.Specialist.Restore(c.Request.Context(), specialistID)
+	if errors.Is(err, service.ErrNotFound) {
+		notFoundResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("ошибка восстановления специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	specialist, err := h.services.Specialist.GetByID(c.Request.Context(), specialistID)
+	if err != nil {
+		h.logger.Error("ошибка получения восстановленного специалиста", zap.Int64("specialistID", specialistID), zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, specialist)
+}
+
+// @Summary Чаты пользователя
+// @Description Возвращает чаты, в которых пользователь участвует как клиент или как специалист. Доступно только администраторам
+// @Tags Администрирование
+// @Produce json
+// @Param user_id query int true "ID пользователя"
+// @Param status query string false "Статус чата" Enums(pending,active,ended)
+// @Param limit query int false "Количество записей (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} paginatedResponse{data=[]domain.ChatSession} "Чаты пользователя с пагинацией"
+// @Failure 400 {object} errorResponseBody "Неверный формат параметров"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/chat-sessions [get]
+func (h *Handler) getUserChatSessions(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "необходимо указать корректный user_id")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
+
+	filter := domain.ChatSessionFilter{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if status := c.Query("status"); status != "" {
+		value := domain.ChatSessionStatus(status)
+		filter.Status = &value
+	}
+
+	sessions, total, err := h.services.Chat.ListChatSessionsByUserID(c.Request.Context(), userID, filter)
+	if err != nil {
+		h.logger.Error("ошибка получения чатов пользователя", zap.Int64("userID", userID), zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка получения чатов пользователя")
+		return
+	}
+
+	page := offset/limit + 1
+	paginatedSuccessResponse(c, sessions, int(total), page, limit)
+}
+
+// @Summary Пригласить пользователя
+// @Description Создает пользователя без пароля и отправляет ему на email ссылку для установки пароля, действующую 7 дней.
+// @Description Повторное приглашение пользователя, который еще не задал пароль, создает новую ссылку, а старая перестает действовать.
+// @Description Доступно только администраторам
+// @Tags Администрирование
+// @Accept json
+// @Produce json
+// @Param input body domain.InviteUserDTO true "Данные приглашаемого пользователя"
+// @Success 201 {object} domain.UserInviteResult "ID пользователя и срок действия приглашения"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 409 {object} errorResponseBody "Пользователь уже зарегистрирован или телефон уже используется"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/users/invite [post]
+func (h *Handler) inviteUser(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	var req domain.InviteUserDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	result, err := h.services.Invite.Invite(c.Request.Context(), adminID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrConflict) {
+			errorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	createdResponse(c, result)
+}
@@ -1,6 +1,8 @@
 package rest
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,17 +11,24 @@ import (
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
+	"laps/internal/service"
 )
 
 // @Summary Создать запись на консультацию
-// @Description Создает новую запись на консультацию к специалисту
+// @Description Создает новую запись на консультацию к специалисту.
+// @Description Дата записи должна быть в будущем и не дальше 90 дней от текущего момента.
+// @Description Чтобы оформить удержанный слот, передайте hold_id из POST /appointments/hold или reservation_token из POST /specialists/{id}/slots/reserve.
+// @Description Тип консультации определяется автоматически: secondary, если у клиента уже была завершенная запись к специалисту, иначе primary.
 // @Tags Записи
 // @Accept json
 // @Produce json
 // @Param input body domain.CreateAppointmentDTO true "Данные для записи на консультацию"
-// @Success 201 {object} map[string]interface{} "ID созданной записи"
-// @Failure 400 {object} errorResponseBody "Ошибка валидации или выбранное время недоступно"
+// @Success 201 {object} map[string]interface{} "ID созданной записи и примененный тип консультации"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации, дата в прошлом или дальше 90 дней, выбранное время вне расписания специалиста или недоступно, удержание истекло; error_code=client_blocked, если запись к специалисту недоступна"
 // @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 409 {object} errorResponseBody "Слот уже занят другой записью или удержанием; error_code=not_accepting_new_clients, если специалист не принимает новых клиентов"
+// @Failure 410 {object} errorResponseBody "Профиль специалиста удален (error_code=specialist_unavailable)"
+// @Failure 422 {object} errorResponseBody "Превышено число активных записей клиента (error_code=limit_exceeded)"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
 // @Router /appointments [post]
@@ -38,23 +47,49 @@ func (h *Handler) createAppointment(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.Appointment.Create(c.Request.Context(), userID, req)
+	id, consultationType, err := h.services.Appointment.Create(c.Request.Context(), userID, req)
+	if errors.Is(err, service.ErrClientBlocked) {
+		clientBlockedResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrSpecialistUnavailable) {
+		specialistUnavailableResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrNotAcceptingClients) {
+		notAcceptingClientsResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrConflict) {
+		h.logger.Warn("слот уже занят", zap.Error(err))
+		errorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrInvalid) {
+		h.logger.Warn("некорректная дата записи", zap.Error(err))
+		badRequestResponse(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrLimitExceeded) {
+		codedErrorResponse(c, http.StatusUnprocessableEntity, "limit_exceeded", err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка создания записи на консультацию", zap.Error(err))
 		badRequestResponse(c, "ошибка создания записи на консультацию")
 		return
 	}
 
-	createdResponse(c, gin.H{"id": id})
+	createdResponse(c, gin.H{"id": id, "consultation_type": consultationType})
 }
 
 // @Summary Получить запись по ID
-// @Description Возвращает информацию о записи на консультацию по указанному ID
+// @Description Возвращает информацию о записи на консультацию по указанному ID, включая имена клиента и специалиста, цену, статус оплаты и ID чат-сессии
 // @Tags Записи
 // @Accept json
 // @Produce json
 // @Param id path int true "ID записи"
-// @Success 200 {object} domain.Appointment "Данные записи"
+// @Success 200 {object} domain.AppointmentDetails "Данные записи с именами участников, оплатой и ID чата"
 // @Failure 400 {object} errorResponseBody "Неверный формат ID"
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 403 {object} errorResponseBody "Доступ запрещен"
@@ -96,21 +131,144 @@ func (h *Handler) getAppointmentByID(c *gin.Context) {
 		return
 	}
 
-	successResponse(c, http.StatusOK, appointment)
+	details := domain.AppointmentDetails{
+		Appointment:   *appointment,
+		PaymentStatus: appointment.PaymentStatus(),
+	}
+
+	if isSpecialist && specialist.ID == appointment.SpecialistID {
+		profile, err := h.services.ClientProfile.Get(c.Request.Context(), appointment.ClientID)
+		if err != nil {
+			h.logger.Warn("не удалось получить профиль клиента для записи", zap.Int64("appointmentID", id), zap.Error(err))
+		} else {
+			details.ClientProfile = profile
+		}
+	}
+
+	successResponse(c, http.StatusOK, details)
+}
+
+// @Summary Счет за консультацию
+// @Description Возвращает счет по записи: участники, услуга, цена с учетом типа консультации, валюта и статус оплаты.
+// @Description Доступен клиенту, специалисту записи и администратору.
+// @Tags Записи
+// @Produce json
+// @Param id path int true "ID записи"
+// @Success 200 {object} domain.AppointmentInvoice "Счет за консультацию"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Запись не найдена"
+// @Security ApiKeyAuth
+// @Router /appointments/{id}/invoice [get]
+func (h *Handler) getAppointmentInvoice(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Warn("ошибка получения ID пользователя", zap.Error(err))
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	appointment, err := h.services.Appointment.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("ошибка получения записи", zap.Error(err), zap.Int64("id", id))
+		notFoundResponse(c, "запись не найдена")
+		return
+	}
+
+	userRole, _ := getUserRole(c)
+	if appointment.ClientID != userID && userRole != domain.UserRoleAdmin {
+		specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+		if err != nil || specialist == nil || specialist.ID != appointment.SpecialistID {
+			h.logger.Warn("попытка несанкционированного доступа к счету", zap.Int64("userID", userID))
+			forbiddenResponse(c)
+			return
+		}
+	}
+
+	successResponse(c, http.StatusOK, appointment.Invoice(h.config.Billing.Currency))
+}
+
+// @Summary Запись в формате iCalendar
+// @Description Возвращает запись как событие календаря (.ics) для импорта в календарь.
+// @Description Доступна клиенту, специалисту записи и администратору.
+// @Tags Записи
+// @Produce text/calendar
+// @Param id path int true "ID записи"
+// @Success 200 {string} string "Календарь с событием записи"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Запись не найдена"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /appointments/{id}/calendar.ics [get]
+func (h *Handler) getAppointmentCalendar(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Warn("ошибка получения ID пользователя", zap.Error(err))
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	appointment, err := h.services.Appointment.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("ошибка получения записи", zap.Error(err), zap.Int64("id", id))
+		notFoundResponse(c, "запись не найдена")
+		return
+	}
+
+	userRole, _ := getUserRole(c)
+	if appointment.ClientID != userID && userRole != domain.UserRoleAdmin {
+		specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+		if err != nil || specialist == nil || specialist.ID != appointment.SpecialistID {
+			h.logger.Warn("попытка несанкционированного доступа к календарю записи", zap.Int64("userID", userID))
+			forbiddenResponse(c)
+			return
+		}
+	}
+
+	data, err := h.services.Appointment.CalendarFile(c.Request.Context(), appointment)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="appointment-%d.ics"`, id))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
 }
 
 // @Summary Обновить запись
-// @Description Обновляет информацию о записи на консультацию
+// @Description Обновляет информацию о записи на консультацию. При подтверждении (status=paid) у записи автоматически
+// @Description появляется чат, и в ответе возвращается его chat_session_id; повторное подтверждение возвращает тот же чат.
+// @Description Чтобы не затереть чужие изменения, передайте updated_at записи в expected_updated_at или заголовок
+// @Description If-Unmodified-Since: если запись уже изменили, возвращается 409 с error_code=version_conflict и актуальной записью в current
 // @Tags Записи
 // @Accept json
 // @Produce json
 // @Param id path int true "ID записи"
+// @Param If-Unmodified-Since header string false "Дата последнего известного изменения записи (HTTP-дата), если expected_updated_at не передан"
 // @Param input body domain.UpdateAppointmentDTO true "Данные для обновления записи"
 // @Success 200 {object} messageResponseType "Сообщение об успешном обновлении"
-// @Failure 400 {object} errorResponseBody "Ошибка валидации или выбранное время недоступно"
+// @Success 200 {object} successResponseBody{data=domain.AppointmentConfirmation} "При подтверждении — ID чата записи"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации; при переносе — дата в прошлом или дальше 90 дней, время вне расписания специалиста или недоступно; error_code=client_blocked, если запись к специалисту недоступна"
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 403 {object} errorResponseBody "Доступ запрещен"
 // @Failure 404 {object} errorResponseBody "Запись не найдена"
+// @Failure 409 {object} errorResponseBody "Запись изменена другим пользователем (error_code=version_conflict) или консультация уже начата; error_code=not_accepting_new_clients, если специалист не принимает новых клиентов"
+// @Failure 410 {object} errorResponseBody "Профиль специалиста удален (error_code=specialist_unavailable)"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
 // @Router /appointments/{id} [put]
@@ -155,13 +313,54 @@ func (h *Handler) updateAppointment(c *gin.Context) {
 		return
 	}
 
-	err = h.services.Appointment.Update(c.Request.Context(), id, req)
+	expected, ok := expectedUpdatedAt(c, req.ExpectedUpdatedAt, appointment.UpdatedAt)
+	if !ok {
+		return
+	}
+	req.ExpectedUpdatedAt = expected
+
+	confirmation, err := h.services.Appointment.Update(c.Request.Context(), id, req)
+	if errors.Is(err, service.ErrVersionConflict) {
+		var current interface{}
+		if actual, getErr := h.services.Appointment.GetByID(c.Request.Context(), id); getErr == nil {
+			current = actual
+		} else {
+			h.logger.Error("ошибка получения актуальной записи после конфликта версий", zap.Int64("id", id), zap.Error(getErr))
+		}
+		versionConflictResponse(c, err.Error(), current)
+		return
+	}
+	if errors.Is(err, service.ErrClientBlocked) {
+		clientBlockedResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrSpecialistUnavailable) {
+		specialistUnavailableResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrNotAcceptingClients) {
+		notAcceptingClientsResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrConflict) {
+		errorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка обновления записи", zap.Error(err))
 		badRequestResponse(c, "ошибка обновления записи")
 		return
 	}
 
+	if confirmation != nil {
+		successResponse(c, http.StatusOK, confirmation)
+		return
+	}
+
 	messageResponse(c, http.StatusOK, "запись успешно обновлена")
 }
 
@@ -213,7 +412,15 @@ func (h *Handler) cancelAppointment(c *gin.Context) {
 		return
 	}
 
-	err = h.services.Appointment.Cancel(c.Request.Context(), id)
+	cancelledBy := domain.UserRoleSpecialist
+	switch {
+	case appointment.ClientID == userID:
+		cancelledBy = domain.UserRoleClient
+	case userRole == domain.UserRoleAdmin:
+		cancelledBy = domain.UserRoleAdmin
+	}
+
+	err = h.services.Appointment.Cancel(c.Request.Context(), id, cancelledBy)
 	if err != nil {
 		h.logger.Error("ошибка отмены записи", zap.Error(err))
 		badRequestResponse(c, "ошибка отмены записи")
@@ -223,6 +430,168 @@ func (h *Handler) cancelAppointment(c *gin.Context) {
 	messageResponse(c, http.StatusOK, "запись успешно отменена")
 }
 
+// @Summary Передать запись другому специалисту
+// @Description Назначает запись другому специалисту, например когда текущий недоступен. Новый специалист должен вести
+// @Description специализацию записи и быть свободен в ее время с учетом длительности своих слотов. Тип консультации и цена
+// @Description пересчитываются, клиент и оба специалиста получают уведомление, передача попадает в журнал аудита.
+// @Description Завершенные и отмененные записи передать нельзя. Доступно только администраторам
+// @Tags Записи
+// @Accept json
+// @Produce json
+// @Param id path int true "ID записи"
+// @Param input body domain.TransferAppointmentDTO true "Новый специалист"
+// @Success 200 {object} domain.Appointment "Переданная запись"
+// @Failure 400 {object} errorResponseBody "Неверные данные или специалист не ведет специализацию записи"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Запись или специалист не найдены"
+// @Failure 409 {object} errorResponseBody "Запись завершена или отменена либо специалист занят в это время"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /appointments/{id}/transfer [post]
+func (h *Handler) transferAppointment(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	var req domain.TransferAppointmentDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	appointment, err := h.services.Appointment.Transfer(c.Request.Context(), id, adminID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalid):
+			badRequestResponse(c, err.Error())
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(c, err.Error())
+		case errors.Is(err, service.ErrConflict):
+			errorResponse(c, http.StatusConflict, err.Error())
+		default:
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	successResponse(c, http.StatusOK, appointment)
+}
+
+// @Summary Отметиться перед консультацией
+// @Description Клиент сообщает, что готов к консультации. Отметиться можно в оплаченной записи не раньше чем
+// @Description за 15 минут до начала; повторная отметка не меняет время первой. Доступно только клиенту записи
+// @Tags Записи
+// @Produce json
+// @Param id path int true "ID записи"
+// @Success 200 {object} domain.Appointment "Запись с временем отметки"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Запись не найдена"
+// @Failure 409 {object} errorResponseBody "Запись не оплачена, уже начата или время отметки еще не наступило"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /appointments/{id}/check-in [post]
+func (h *Handler) checkInAppointment(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	appointment, err := h.services.Appointment.CheckIn(c.Request.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(c, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			forbiddenResponse(c)
+		case errors.Is(err, service.ErrConflict):
+			errorResponse(c, http.StatusConflict, err.Error())
+		default:
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	successResponse(c, http.StatusOK, appointment)
+}
+
+// @Summary Начать консультацию
+// @Description Специалист записи начинает консультацию: запись переходит в статус in_progress, фиксируется фактическое
+// @Description время начала, в чат записи отправляется системное сообщение. Если клиент еще не отметился, консультацию
+// @Description можно начать только с force=true. Начать можно не раньше чем за 15 минут до времени записи
+// @Tags Записи
+// @Produce json
+// @Param id path int true "ID записи"
+// @Param force query bool false "Начать, даже если клиент не отметился"
+// @Success 200 {object} domain.Appointment "Начатая запись"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID или параметра force"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Запись не найдена"
+// @Failure 409 {object} errorResponseBody "Клиент не отметился (error_code=client_not_checked_in), запись не оплачена или уже начата"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /appointments/{id}/start [post]
+func (h *Handler) startAppointment(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	force := false
+	if forceStr := c.Query("force"); forceStr != "" {
+		force, err = strconv.ParseBool(forceStr)
+		if err != nil {
+			badRequestResponse(c, "неверный формат параметра force")
+			return
+		}
+	}
+
+	appointment, err := h.services.Appointment.Start(c.Request.Context(), id, userID, force)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(c, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			forbiddenResponse(c)
+		case errors.Is(err, service.ErrNotCheckedIn):
+			codedErrorResponse(c, http.StatusConflict, "client_not_checked_in", err.Error())
+		case errors.Is(err, service.ErrConflict):
+			errorResponse(c, http.StatusConflict, err.Error())
+		default:
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	successResponse(c, http.StatusOK, appointment)
+}
+
 // @Summary Получить список записей
 // @Description Возвращает список записей на консультации с фильтрацией и пагинацией
 // @Tags Записи
@@ -248,24 +617,15 @@ func (h *Handler) getAppointments(c *gin.Context) {
 		return
 	}
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	if err != nil || limit < 0 {
-		limit = 20
-	}
-
-	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
-	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
 
 	filter := domain.AppointmentFilter{
 		Limit:  limit,
 		Offset: offset,
 	}
 
-	specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
-	isSpecialist := err == nil && specialist != nil
-
 	if clientIDStr := c.Query("client_id"); clientIDStr != "" {
 		clientID, err := strconv.ParseInt(clientIDStr, 10, 64)
 		if err == nil {
@@ -281,11 +641,7 @@ func (h *Handler) getAppointments(c *gin.Context) {
 	}
 
 	if filter.ClientID == nil && filter.SpecialistID == nil {
-		if isSpecialist {
-			filter.SpecialistID = &specialist.ID
-		} else {
-			filter.ClientID = &userID
-		}
+		h.setOwnAppointmentsFilter(c, userID, &filter)
 	}
 
 	if statusStr := c.Query("status"); statusStr != "" {
@@ -323,13 +679,70 @@ func (h *Handler) getAppointments(c *gin.Context) {
 	paginatedSuccessResponse(c, appointments, total, page, limit)
 }
 
+// setOwnAppointmentsFilter ограничивает filter записями текущего пользователя: записями к нему,
+// если у пользователя есть профиль специалиста, иначе его записями как клиента
+func (h *Handler) setOwnAppointmentsFilter(c *gin.Context, userID int64, filter *domain.AppointmentFilter) {
+	specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+	if err == nil && specialist != nil {
+		filter.SpecialistID = &specialist.ID
+		return
+	}
+	filter.ClientID = &userID
+}
+
+// @Summary Получить предстоящие записи
+// @Description Возвращает неотмененные записи текущего пользователя, время которых еще не наступило, от ближайшей к поздней.
+// @Description Для специалиста возвращаются записи к нему, для клиента — его записи. В записях есть имена клиента и специалиста
+// @Tags Записи
+// @Accept json
+// @Produce json
+// @Param limit query int false "Лимит записей на странице (по умолчанию 20)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {object} paginatedResponse "Предстоящие записи с пагинацией"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /appointments/upcoming [get]
+func (h *Handler) getUpcomingAppointments(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Warn("ошибка получения ID пользователя", zap.Error(err))
+		unauthorizedResponse(c)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
+
+	filter := domain.AppointmentFilter{
+		Limit:  limit,
+		Offset: offset,
+	}
+	h.setOwnAppointmentsFilter(c, userID, &filter)
+
+	appointments, total, err := h.services.Appointment.ListUpcoming(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("ошибка получения предстоящих записей", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка получения предстоящих записей")
+		return
+	}
+
+	page := offset/limit + 1
+	paginatedSuccessResponse(c, appointments, total, page, limit)
+}
+
 // @Summary Проверить тип консультации
-// @Description Проверяет, является ли консультация первичной или вторичной для клиента у указанного специалиста
+// @Description Проверяет, является ли консультация сейчас первичной или вторичной для клиента у указанного специалиста.
+// @Description Вторичной считается консультация в пределах окна (по умолчанию 6 месяцев) после последней завершенной записи;
+// @Description с specialization_id учитываются только записи по этой специализации. В ответе — тип, ID завершенной записи,
+// @Description на основании которой консультация вторичная, и момент окончания окна
 // @Tags Записи
 // @Accept json
 // @Produce json
 // @Param specialist_id query int true "ID специалиста"
-// @Success 200 {object} map[string]string "Тип консультации"
+// @Param specialization_id query int false "ID специализации, по которой планируется консультация"
+// @Success 200 {object} domain.ConsultationTypeCheck "Тип консультации и его основание"
 // @Failure 400 {object} errorResponseBody "Ошибка валидации"
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
@@ -357,14 +770,152 @@ func (h *Handler) checkConsultationType(c *gin.Context) {
 		return
 	}
 
-	consultationType, err := h.services.Appointment.CheckConsultationType(c.Request.Context(), userID, specialistID)
+	var specializationID *int64
+	if specializationIDStr := c.Query("specialization_id"); specializationIDStr != "" {
+		id, err := strconv.ParseInt(specializationIDStr, 10, 64)
+		if err != nil {
+			h.logger.Warn("неверный формат ID специализации", zap.Error(err))
+			badRequestResponse(c, "неверный формат ID специализации")
+			return
+		}
+		specializationID = &id
+	}
+
+	check, err := h.services.Appointment.CheckConsultationType(c.Request.Context(), userID, specialistID, specializationID, time.Now())
 	if err != nil {
 		h.logger.Error("ошибка при определении типа консультации", zap.Error(err))
 		internalServerErrorResponse(c)
 		return
 	}
 
-	successResponse(c, http.StatusOK, gin.H{
-		"consultation_type": string(consultationType),
-	})
+	successResponse(c, http.StatusOK, check)
+}
+
+// @Summary Ближайшая запись
+// @Description Возвращает ближайшую предстоящую запись текущего пользователя как клиента в статусе pending или paid
+// @Description с именем и фото специалиста. Если предстоящих записей нет, data равно null
+// @Tags Записи
+// @Produce json
+// @Success 200 {object} domain.Appointment "Ближайшая запись или null"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/me/appointments/upcoming [get]
+func (h *Handler) getMyNextAppointment(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	appointment, err := h.services.Appointment.GetNextUpcoming(c.Request.Context(), userID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, appointment)
+}
+
+// @Summary Записи без отзыва
+// @Description Возвращает завершенные записи текущего пользователя как клиента, о которых он еще не оставил отзыв,
+// @Description чтобы приложение могло напомнить об отзыве. Записи удаленных специалистов не возвращаются
+// @Tags Записи
+// @Produce json
+// @Param limit query int false "Лимит записей на странице (по умолчанию 20)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {object} paginatedResponse "Список записей с пагинацией"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/me/pending-reviews [get]
+func (h *Handler) getMyPendingReviews(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
+
+	appointments, total, err := h.services.Appointment.ListPendingReviews(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	page := offset/limit + 1
+	paginatedSuccessResponse(c, appointments, total, page, limit)
+}
+
+// @Summary Отменить записи специалиста за период
+// @Description Отменяет все неотмененные записи специалиста с from по to (включительно) и уведомляет клиентов.
+// @Description Освободившееся время снова доступно для записи. Администратор указывает specialist_id в теле запроса.
+// @Tags Записи
+// @Accept json
+// @Produce json
+// @Param input body domain.CancelAppointmentRangeDTO true "Период отмены (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "ID отмененных записей"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/appointments/cancel-range [post]
+func (h *Handler) cancelSpecialistAppointmentRange(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	userRole, err := getUserRole(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	var req domain.CancelAppointmentRangeDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	var specialistID int64
+	switch userRole {
+	case domain.UserRoleAdmin:
+		if req.SpecialistID == nil {
+			badRequestResponse(c, "необходимо указать specialist_id")
+			return
+		}
+		specialistID = *req.SpecialistID
+	case domain.UserRoleSpecialist:
+		specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+		if err != nil {
+			h.logger.Error("ошибка при получении данных специалиста", zap.Error(err))
+			notFoundResponse(c, "профиль специалиста не найден")
+			return
+		}
+		specialistID = specialist.ID
+	default:
+		forbiddenResponse(c, "доступ запрещен")
+		return
+	}
+
+	ids, err := h.services.Appointment.CancelRange(c.Request.Context(), specialistID, req)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("ошибка массовой отмены записей", zap.Int64("specialistID", specialistID), zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, gin.H{"cancelled_ids": ids})
 }
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Максимальный диапазон выгрузки записей
+const maxExportRangeYears = 1
+
+// @Summary Выгрузить записи специалиста в CSV
+// @Description Возвращает CSV (UTF-8 с BOM) с записями специалиста за период: дата, клиент, тип консультации, статус, цена, статус оплаты.
+// @Description Диапазон не больше 1 года; по умолчанию последний месяц.
+// @Tags Специалисты
+// @Produce text/csv
+// @Param from query string false "Начальная дата (YYYY-MM-DD, по умолчанию месяц назад)"
+// @Param to query string false "Конечная дата включительно (YYYY-MM-DD, по умолчанию сегодня)"
+// @Param format query string false "Формат выгрузки (поддерживается только csv)"
+// @Success 200 {file} file "CSV-файл"
+// @Failure 400 {object} errorResponseBody "Неверный формат параметров или диапазон больше года"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Security ApiKeyAuth
+// @Router /specialists/me/appointments/export [get]
+func (h *Handler) exportSpecialistAppointments(c *gin.Context) {
+	from, to, ok := parseExportRange(c)
+	if !ok {
+		return
+	}
+
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	h.streamAppointmentsCSV(c, &specialist.ID, from, to)
+}
+
+// @Summary Выгрузить все записи платформы в CSV
+// @Description Возвращает CSV (UTF-8 с BOM) с записями всех специалистов за период; в отличие от выгрузки специалиста добавлена колонка со специалистом.
+// @Description Диапазон не больше 1 года; по умолчанию последний месяц. Доступно только администраторам
+// @Tags Администрирование
+// @Produce text/csv
+// @Param from query string false "Начальная дата (YYYY-MM-DD, по умолчанию месяц назад)"
+// @Param to query string false "Конечная дата включительно (YYYY-MM-DD, по умолчанию сегодня)"
+// @Param format query string false "Формат выгрузки (поддерживается только csv)"
+// @Success 200 {file} file "CSV-файл"
+// @Failure 400 {object} errorResponseBody "Неверный формат параметров или диапазон больше года"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Security ApiKeyAuth
+// @Router /admin/appointments/export [get]
+func (h *Handler) exportAllAppointments(c *gin.Context) {
+	from, to, ok := parseExportRange(c)
+	if !ok {
+		return
+	}
+
+	h.streamAppointmentsCSV(c, nil, from, to)
+}
+
+// parseExportRange разбирает format, from и to; возвращает полуинтервал [from, to) с включенным днем to
+func parseExportRange(c *gin.Context) (time.Time, time.Time, bool) {
+	if format := c.DefaultQuery("format", "csv"); format != "csv" {
+		badRequestResponse(c, "поддерживается только формат csv")
+		return time.Time{}, time.Time{}, false
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toStr, now.Location())
+		if err != nil {
+			badRequestResponse(c, "неверный формат параметра to, ожидается YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, -1, 0)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromStr, now.Location())
+		if err != nil {
+			badRequestResponse(c, "неверный формат параметра from, ожидается YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		badRequestResponse(c, "дата to не может быть раньше from")
+		return time.Time{}, time.Time{}, false
+	}
+	if to.After(from.AddDate(maxExportRangeYears, 0, 0)) {
+		badRequestResponse(c, "диапазон не должен превышать 1 год")
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to.AddDate(0, 0, 1), true
+}
+
+func (h *Handler) streamAppointmentsCSV(c *gin.Context, specialistID *int64, from, to time.Time) {
+	filename := fmt.Sprintf("appointments_%s_%s.csv", from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"))
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	err := h.services.Appointment.ExportCSV(c.Request.Context(), c.Writer, specialistID, from, to)
+	if err == nil {
+		return
+	}
+
+	h.logger.Error("ошибка выгрузки записей в CSV", zap.Error(err))
+
+	// После начала передачи файла статус уже отправлен, остается оборвать ответ
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		internalServerErrorResponse(c)
+	}
+}
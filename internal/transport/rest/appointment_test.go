@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// fakeAppointmentService хранит одну запись и отклоняет обновление, если ее updated_at
+// не совпадает с переданным, как это делает хранилище
+type fakeAppointmentService struct {
+	service.AppointmentService
+
+	mu          sync.Mutex
+	appointment domain.Appointment
+}
+
+func (s *fakeAppointmentService) GetByID(ctx context.Context, id int64) (*domain.Appointment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id != s.appointment.ID {
+		return nil, service.ErrNotFound
+	}
+	appointment := s.appointment
+	return &appointment, nil
+}
+
+func (s *fakeAppointmentService) Update(ctx context.Context, id int64, dto domain.UpdateAppointmentDTO) (*domain.AppointmentConfirmation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if dto.ExpectedUpdatedAt != nil && !s.appointment.UpdatedAt.Equal(*dto.ExpectedUpdatedAt) {
+		return nil, fmt.Errorf("%w: запись была изменена", service.ErrVersionConflict)
+	}
+	if dto.Status != nil {
+		s.appointment.Status = *dto.Status
+	}
+	if dto.AppointmentDate != nil {
+		s.appointment.AppointmentDate = *dto.AppointmentDate
+	}
+	s.appointment.UpdatedAt = s.appointment.UpdatedAt.Add(time.Minute)
+	return nil, nil
+}
+
+func newAppointmentTestRouter(appointments service.AppointmentService, userID int64) *gin.Engine {
+	h := &Handler{
+		services: &service.Services{Appointment: appointments, Specialist: &fakeSpecialistService{}},
+		logger:   zap.NewNop(),
+	}
+
+	router := gin.New()
+	router.PUT("/api/v1/appointments/:id", h.apiVersionMiddleware(apiV1), func(c *gin.Context) {
+		c.Set(userIDCtx, userID)
+		c.Set(userRoleCtx, domain.UserRoleClient)
+	}, h.updateAppointment)
+	return router
+}
+
+// Клиент открыл запись в двух вкладках: перенос из второй вкладки после отмены в первой
+// получает 409 с актуальной записью вместо того, чтобы вернуть отмененную запись в расписание
+func TestUpdateAppointmentLostUpdate(t *testing.T) {
+	readAt := time.Date(2026, 3, 2, 9, 15, 30, 0, time.UTC)
+	appointments := &fakeAppointmentService{appointment: domain.Appointment{
+		ID:              5,
+		ClientID:        1,
+		SpecialistID:    7,
+		AppointmentDate: time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC),
+		Status:          domain.AppointmentStatusPending,
+		UpdatedAt:       readAt,
+	}}
+	router := newAppointmentTestRouter(appointments, 1)
+
+	first := putJSON(router, "/api/v1/appointments/5",
+		`{"status":"cancelled","expected_updated_at":"2026-03-02T09:15:30Z"}`, nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("first save: status = %d, body = %s", first.Code, first.Body)
+	}
+
+	second := putJSON(router, "/api/v1/appointments/5", `{"appointment_date":"2026-03-11T10:00:00Z"}`,
+		map[string]string{"If-Unmodified-Since": readAt.Format(http.TimeFormat)})
+	if second.Code != http.StatusConflict {
+		t.Fatalf("second save: status = %d, want 409; body = %s", second.Code, second.Body)
+	}
+
+	var body struct {
+		ErrorCode string             `json:"error_code"`
+		Current   domain.Appointment `json:"current"`
+	}
+	if err := json.Unmarshal(second.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.ErrorCode != "version_conflict" || body.Current.Status != domain.AppointmentStatusCancelled {
+		t.Errorf("response = %s, want version_conflict with the cancelled appointment", second.Body)
+	}
+
+	stored, _ := appointments.GetByID(context.Background(), 5)
+	if !stored.AppointmentDate.Equal(time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("appointment moved to %s by a stale request", stored.AppointmentDate)
+	}
+}
+
+func TestUpdateAppointmentRejectsInvalidPrecondition(t *testing.T) {
+	appointments := &fakeAppointmentService{appointment: domain.Appointment{ID: 5, ClientID: 1, SpecialistID: 7}}
+	router := newAppointmentTestRouter(appointments, 1)
+
+	w := putJSON(router, "/api/v1/appointments/5", `{"status":"cancelled"}`,
+		map[string]string{"If-Unmodified-Since": "last week"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	if stored, _ := appointments.GetByID(context.Background(), 5); stored.Status != "" {
+		t.Errorf("status = %s, appointment updated despite an invalid precondition", stored.Status)
+	}
+}
@@ -1,12 +1,14 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
+	"laps/internal/service"
 )
 
 // @Summary Регистрация нового пользователя
@@ -17,6 +19,7 @@ import (
 // @Param input body domain.RegisterRequest true "Данные для регистрации"
 // @Success 201 {object} domain.Tokens "Токены доступа и обновления"
 // @Failure 400 {object} errorResponseBody "Ошибка валидации"
+// @Failure 409 {object} errorResponseBody "Email уже зарегистрирован или телефон уже используется"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Router /auth/register [post]
 func (h *Handler) register(c *gin.Context) {
@@ -29,6 +32,11 @@ func (h *Handler) register(c *gin.Context) {
 	}
 
 	id, err := h.services.Auth.Register(c.Request.Context(), input)
+	if errors.Is(err, service.ErrConflict) {
+		h.logger.Info("регистрация с занятыми данными", zap.Error(err))
+		errorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка при регистрации", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, err.Error())
@@ -65,7 +73,7 @@ func (h *Handler) register(c *gin.Context) {
 // @Param input body domain.LoginRequest true "Данные для входа"
 // @Success 200 {object} domain.Tokens "Токены доступа и обновления"
 // @Failure 400 {object} errorResponseBody "Ошибка валидации"
-// @Failure 401 {object} errorResponseBody "Неверные учетные данные"
+// @Failure 401 {object} errorResponseBody "Неверные учетные данные; код password_not_set, если приглашенный пользователь еще не задал пароль"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Router /auth/login [post]
 func (h *Handler) login(c *gin.Context) {
@@ -81,6 +89,10 @@ func (h *Handler) login(c *gin.Context) {
 	ip := c.ClientIP()
 
 	tokens, err := h.services.Auth.Login(c.Request.Context(), input, userAgent, ip)
+	if errors.Is(err, service.ErrPasswordNotSet) {
+		codedErrorResponse(c, http.StatusUnauthorized, "password_not_set", err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка при входе", zap.Error(err))
 		errorResponse(c, http.StatusUnauthorized, err.Error())
@@ -90,6 +102,109 @@ func (h *Handler) login(c *gin.Context) {
 	successResponse(c, http.StatusOK, tokens)
 }
 
+// @Summary Принятие приглашения
+// @Description Задает пароль пользователю, приглашенному администратором, и выполняет вход. Ссылка из приглашения одноразовая
+// @Tags Авторизация
+// @Accept json
+// @Produce json
+// @Param input body domain.AcceptInviteRequest true "Токен из приглашения и новый пароль"
+// @Success 200 {object} domain.Tokens "Токены доступа и обновления"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации, приглашение не найдено, уже использовано или истекло"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /auth/accept-invite [post]
+func (h *Handler) acceptInvite(c *gin.Context) {
+	var input domain.AcceptInviteRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	user, err := h.services.Invite.Accept(c.Request.Context(), input)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("ошибка при принятии приглашения", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tokens, err := h.services.Auth.Login(c.Request.Context(), domain.LoginRequest{
+		Login:    user.Email,
+		Password: input.Password,
+	}, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		h.logger.Error("ошибка при входе после принятия приглашения", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, tokens)
+}
+
+// @Summary Запрос восстановления пароля
+// @Description Отправляет на email ссылку для установки нового пароля. Ссылка действует 1 час.
+// @Description Ответ одинаковый независимо от того, зарегистрирован ли email
+// @Tags Авторизация
+// @Accept json
+// @Produce json
+// @Param input body domain.ForgotPasswordRequest true "Email пользователя"
+// @Success 200 {object} messageResponseType "Ссылка отправлена, если email зарегистрирован"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /auth/forgot-password [post]
+func (h *Handler) forgotPassword(c *gin.Context) {
+	var input domain.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	if err := h.services.PasswordReset.ForgotPassword(c.Request.Context(), input); err != nil {
+		h.logger.Error("ошибка при запросе восстановления пароля", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "если email зарегистрирован, на него отправлена ссылка для восстановления пароля")
+}
+
+// @Summary Восстановление пароля
+// @Description Задает новый пароль по токену из письма без проверки текущего пароля. Ссылка одноразовая,
+// @Description все сессии пользователя после смены пароля завершаются
+// @Tags Авторизация
+// @Accept json
+// @Produce json
+// @Param input body domain.ResetPasswordRequest true "Токен из письма и новый пароль"
+// @Success 200 {object} messageResponseType "Пароль изменен"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации, ссылка недействительна, уже использована или истекла"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /auth/reset-password [post]
+func (h *Handler) resetPassword(c *gin.Context) {
+	var input domain.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	err := h.services.PasswordReset.ResetPassword(c.Request.Context(), input)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("ошибка при восстановлении пароля", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "пароль успешно изменен")
+}
+
 // @Summary Обновление токена
 // @Description Обновляет токены доступа и обновления
 // @Tags Авторизация
@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Заблокировать клиента
+// @Description Запрещает клиенту записываться к специалисту, начинать с ним новые чаты и звонить. Клиент видит только, что запись недоступна; причина ему не сообщается
+// @Tags Специалисты
+// @Accept json
+// @Produce json
+// @Param input body domain.BlockClientDTO true "Клиент и необязательная причина"
+// @Success 201 {object} messageResponseType "Клиент заблокирован"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/blocked-clients [post]
+func (h *Handler) blockClient(c *gin.Context) {
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	var req domain.BlockClientDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	if err := h.services.BlockList.BlockClient(c.Request.Context(), specialist.ID, req); err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	messageResponse(c, http.StatusCreated, "клиент заблокирован")
+}
+
+// @Summary Разблокировать клиента
+// @Description Снимает блокировку, после чего клиент снова может записываться, писать и звонить специалисту
+// @Tags Специалисты
+// @Produce json
+// @Param clientId path int true "ID клиента"
+// @Success 204 "Клиент разблокирован"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Клиент не заблокирован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/blocked-clients/{clientId} [delete]
+func (h *Handler) unblockClient(c *gin.Context) {
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	clientID, err := strconv.ParseInt(c.Param("clientId"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID клиента")
+		return
+	}
+
+	if err := h.services.BlockList.UnblockClient(c.Request.Context(), specialist.ID, clientID); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(c, "клиент не заблокирован")
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	noContentResponse(c)
+}
+
+// @Summary Заблокированные клиенты
+// @Description Возвращает клиентов, заблокированных специалистом, новые первыми
+// @Tags Специалисты
+// @Produce json
+// @Success 200 {object} []domain.BlockedClient "Заблокированные клиенты"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/blocked-clients [get]
+func (h *Handler) getBlockedClients(c *gin.Context) {
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	blocked, err := h.services.BlockList.ListBlockedClients(c.Request.Context(), specialist.ID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, blocked)
+}
+
+// currentSpecialist возвращает профиль специалиста текущего пользователя
+// или отправляет ответ с ошибкой и возвращает false
+func (h *Handler) currentSpecialist(c *gin.Context) (*domain.Specialist, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return nil, false
+	}
+
+	specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("ошибка при получении данных специалиста", zap.Error(err))
+		notFoundResponse(c, "профиль специалиста не найден")
+		return nil, false
+	}
+
+	return specialist, true
+}
@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Состояние звонка
+// @Description Возвращает текущий звонок по ID сессии сигналинга: статус, участников, длительность
+// @Description и последнее состояние медиа каждого участника (микрофон, камера, демонстрация экрана).
+// @Description Используется переподключившимся участником для восстановления интерфейса. Доступно только участникам звонка
+// @Tags Звонки
+// @Produce json
+// @Param session_id path string true "ID сессии звонка"
+// @Success 200 {object} websocket.CallSession "Состояние звонка"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 404 {object} errorResponseBody "Активный звонок не найден"
+// @Security ApiKeyAuth
+// @Router /calls/{session_id}/state [get]
+func (h *Handler) getCallState(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	session := h.signalingHub.GetActiveCallBySessionID(c.Param("session_id"))
+	if session == nil || (session.ClientID != userID && session.SpecialistID != userID) {
+		notFoundResponse(c, "активный звонок не найден")
+		return
+	}
+
+	successResponse(c, http.StatusOK, session)
+}
+
+// @Summary Оценить качество звонка
+// @Description Сохраняет оценку завершенного звонка от 1 до 5, отмеченные проблемы со связью (audio, video, dropped,
+// @Description latency, echo, connecting) и комментарий. После звонка участники получают по WebSocket сообщение
+// @Description call-feedback-request. Оценить звонок может только его участник и только один раз
+// @Tags Звонки
+// @Accept json
+// @Produce json
+// @Param session_id path string true "ID сессии звонка"
+// @Param input body domain.CallFeedbackDTO true "Оценка звонка"
+// @Success 201 {object} domain.CallFeedback "Сохраненная оценка"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Пользователь не участвовал в звонке"
+// @Failure 404 {object} errorResponseBody "Завершенный звонок не найден"
+// @Failure 409 {object} errorResponseBody "Оценка уже оставлена"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /calls/{session_id}/feedback [post]
+func (h *Handler) submitCallFeedback(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	var req domain.CallFeedbackDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	feedback, err := h.services.Call.SubmitFeedback(c.Request.Context(), userID, c.Param("session_id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalid):
+			badRequestResponse(c, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			forbiddenResponse(c, err.Error())
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(c, err.Error())
+		case errors.Is(err, service.ErrConflict):
+			errorResponse(c, http.StatusConflict, err.Error())
+		default:
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	createdResponse(c, feedback)
+}
+
+// @Summary Сводка оценок звонков
+// @Description Возвращает число оценок звонков, среднюю оценку, распределение по баллам и частоту проблем со связью
+// @Description за период для наблюдения за качеством WebRTC. Без дат берется последняя неделя. Только для администраторов
+// @Tags Администрирование
+// @Produce json
+// @Param start_date query string false "Начальная дата (YYYY-MM-DD)"
+// @Param end_date query string false "Конечная дата включительно (YYYY-MM-DD)"
+// @Success 200 {object} domain.CallFeedbackSummary "Сводка оценок"
+// @Failure 400 {object} errorResponseBody "Неверный формат даты или период"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/call-feedback/summary [get]
+func (h *Handler) getCallFeedbackSummary(c *gin.Context) {
+	var from, to *time.Time
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			badRequestResponse(c, "неверный формат start_date, ожидается YYYY-MM-DD")
+			return
+		}
+		from = &startDate
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			badRequestResponse(c, "неверный формат end_date, ожидается YYYY-MM-DD")
+			return
+		}
+		// Конечная дата включается в период целиком
+		endDate = endDate.AddDate(0, 0, 1)
+		to = &endDate
+	}
+
+	summary, err := h.services.Call.GetFeedbackSummary(c.Request.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		h.logger.Error("ошибка получения сводки оценок звонков", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка получения сводки оценок звонков")
+		return
+	}
+
+	successResponse(c, http.StatusOK, summary)
+}
@@ -1,6 +1,9 @@
 package rest
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -21,7 +24,9 @@ func NewChatHandler(chatService service.ChatService) *ChatHandler {
 }
 
 // @Summary Create chat session
-// @Description Create a new chat session for an appointment
+// @Description Create a chat session for an appointment, or return the existing one. The caller must be the client
+// @Description or the specialist of the appointment; participants are taken from the appointment, and client_id or
+// @Description specialist_id pointing to other users are rejected. A chat is also created automatically on confirmation
 // @Tags Chat
 // @Accept json
 // @Produce json
@@ -30,16 +35,46 @@ func NewChatHandler(chatService service.ChatService) *ChatHandler {
 // @Success 201 {object} successResponse{data=domain.ChatSession}
 // @Failure 400 {object} errorResponse
 // @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse "Not an appointment participant, forged participant IDs, or call_not_allowed: the appointment is cancelled, finished or outside its call window"
+// @Failure 404 {object} errorResponse "Appointment not found"
+// @Failure 410 {object} errorResponse "specialist_unavailable: the specialist's profile has been removed"
 // @Failure 500 {object} errorResponse
 // @Router /chat/sessions [post]
 func (h *ChatHandler) CreateChatSession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
 	var dto domain.CreateChatSessionDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
 		badRequestResponse(c, "Invalid request body: " + err.Error())
 		return
 	}
 
-	session, err := h.chatService.CreateChatSession(c.Request.Context(), dto)
+	session, err := h.chatService.CreateChatSessionForUser(c.Request.Context(), dto, userID)
+	if errors.Is(err, service.ErrNotFound) {
+		notFoundResponse(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrForbidden) {
+		forbiddenResponse(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrClientBlocked) {
+		clientBlockedResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrSpecialistUnavailable) {
+		specialistUnavailableResponse(c)
+		return
+	}
+	var notAllowed *service.CallNotAllowedError
+	if errors.As(err, &notAllowed) {
+		codedErrorResponse(c, http.StatusForbidden, "call_not_allowed", err.Error())
+		return
+	}
 	if err != nil {
 		errorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -152,6 +187,7 @@ func (h *ChatHandler) ListChatSessions(c *gin.Context) {
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
 	filter.Limit = limit
 	filter.Offset = offset
 
@@ -206,6 +242,52 @@ func (h *ChatHandler) UpdateChatSession(c *gin.Context) {
 	successResponse(c, http.StatusOK, session)
 }
 
+// @Summary Archive chat session
+// @Description End a chat session. Available to the session specialist and admins; pending sessions cannot be archived
+// @Tags Chat
+// @Security BearerAuth
+// @Param id path int true "Chat session ID"
+// @Success 204
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Router /chat/sessions/{id} [delete]
+func (h *ChatHandler) ArchiveChatSession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "Invalid session ID")
+		return
+	}
+
+	err = h.chatService.ArchiveChatSessionByID(c.Request.Context(), id, userID, role)
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		notFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		forbiddenResponse(c)
+	case errors.Is(err, service.ErrConflict):
+		errorResponse(c, http.StatusConflict, err.Error())
+	case err != nil:
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+	default:
+		noContentResponse(c)
+	}
+}
+
 // @Summary Send message
 // @Description Send a message in a chat session
 // @Tags Chat
@@ -235,6 +317,10 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	dto.SenderID = userID
 
 	message, err := h.chatService.CreateChatMessage(c.Request.Context(), dto, userID)
+	if errors.Is(err, service.ErrClientBlocked) {
+		clientBlockedResponse(c)
+		return
+	}
 	if err != nil {
 		errorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -243,6 +329,99 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	createdResponse(c, message)
 }
 
+// @Summary Add reaction
+// @Description React to a chat message with a single emoji. Available to participants of the message's session;
+// @Description reacting twice with the same emoji changes nothing. Returns the reaction counts of the message
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Message ID"
+// @Param request body domain.ChatReactionDTO true "Reaction"
+// @Success 200 {object} successResponse{data=map[string]int}
+// @Failure 400 {object} errorResponse "Not a single emoji"
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse "Message not found"
+// @Failure 500 {object} errorResponse
+// @Router /chat/messages/{id}/reactions [post]
+func (h *ChatHandler) AddReaction(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	messageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	var dto domain.ChatReactionDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		badRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	reactions, err := h.chatService.AddReaction(c.Request.Context(), messageID, userID, dto.Emoji)
+	if err != nil {
+		reactionErrorResponse(c, err)
+		return
+	}
+
+	successResponse(c, http.StatusOK, reactions)
+}
+
+// @Summary Remove reaction
+// @Description Remove the caller's own emoji reaction from a chat message. Returns the reaction counts of the message
+// @Tags Chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Message ID"
+// @Param emoji path string true "URL-encoded emoji"
+// @Success 200 {object} successResponse{data=map[string]int}
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse "Message or reaction not found"
+// @Failure 500 {object} errorResponse
+// @Router /chat/messages/{id}/reactions/{emoji} [delete]
+func (h *ChatHandler) RemoveReaction(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	messageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	reactions, err := h.chatService.RemoveReaction(c.Request.Context(), messageID, userID, c.Param("emoji"))
+	if err != nil {
+		reactionErrorResponse(c, err)
+		return
+	}
+
+	successResponse(c, http.StatusOK, reactions)
+}
+
+func reactionErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalid):
+		badRequestResponse(c, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		notFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		forbiddenResponse(c)
+	default:
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
 // @Summary Get messages
 // @Description Get messages for a chat session
 // @Tags Chat
@@ -279,6 +458,7 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
 	filter.Limit = limit
 	filter.Offset = offset
 
@@ -358,6 +538,45 @@ func (h *ChatHandler) GetUnreadMessageCount(c *gin.Context) {
 	successResponse(c, http.StatusOK, count)
 }
 
+// @Summary Get chat session statistics
+// @Description Get message counts per participant, session duration and average response time.
+// @Description System messages are not counted
+// @Tags Chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Chat session ID"
+// @Success 200 {object} successResponse{data=domain.ChatSessionStats}
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /chat/sessions/{id}/stats [get]
+func (h *ChatHandler) GetChatSessionStats(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "Invalid session ID")
+		return
+	}
+
+	stats, err := h.chatService.GetSessionStats(c.Request.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(c, "Chat session not found")
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, stats)
+}
+
 // @Summary Get user chat summary
 // @Description Get summary of user's chat sessions with unread counts
 // @Tags Chat
@@ -381,4 +600,73 @@ func (h *ChatHandler) GetChatSummary(c *gin.Context) {
 	}
 
 	successResponse(c, http.StatusOK, summary)
-}
\ No newline at end of file
+}
+// @Summary Export chat transcript
+// @Description Download the full message history of a chat session as plain text. Available to participants and admins
+// @Tags Chat
+// @Produce plain
+// @Security BearerAuth
+// @Param id path int true "Chat session ID"
+// @Success 200 {string} string "Chat transcript"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /chat/sessions/{id}/transcript [get]
+func (h *ChatHandler) ExportTranscript(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	role, err := getUserRole(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "Invalid session ID")
+		return
+	}
+
+	if _, err := h.chatService.GetChatSessionForTranscript(c.Request.Context(), sessionID, userID, role); err != nil {
+		notFoundResponse(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=chat-%d.txt", sessionID))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure mid-stream can only truncate the transcript
+	w := bufio.NewWriter(c.Writer)
+	err = h.chatService.StreamChatMessages(c.Request.Context(), sessionID, func(message domain.ChatMessage) error {
+		_, err := w.WriteString(formatTranscriptLine(message))
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	w.Flush()
+}
+
+// formatTranscriptLine renders a message as "[timestamp] SENDER_NAME: content"
+func formatTranscriptLine(message domain.ChatMessage) string {
+	sender := "SYSTEM"
+	if message.SenderName != nil && *message.SenderName != "" {
+		sender = *message.SenderName
+	}
+
+	content := message.Content
+	if message.Type == domain.MessageTypeFile || message.Type == domain.MessageTypeImage {
+		fileName := content
+		if message.FileName != nil && *message.FileName != "" {
+			fileName = *message.FileName
+		}
+		content = fmt.Sprintf("[attachment: %s]", fileName)
+	}
+
+	return fmt.Sprintf("[%s] %s: %s\n", message.CreatedAt.Format("2006-01-02 15:04:05"), sender, content)
+}
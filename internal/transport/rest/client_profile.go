@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Получить свой профиль клиента
+// @Description Возвращает дату рождения, пол, город и предпочтительный способ связи текущего пользователя. Незаполненные поля равны null
+// @Tags Пользователи
+// @Produce json
+// @Success 200 {object} domain.ClientProfile "Профиль клиента"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/me/profile [get]
+func (h *Handler) getMyClientProfile(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	profile, err := h.services.ClientProfile.Get(c.Request.Context(), userID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, profile)
+}
+
+// @Summary Обновить свой профиль клиента
+// @Description Заменяет профиль текущего пользователя целиком; все поля необязательны, не переданные поля очищаются.
+// @Description Возраст по дате рождения должен быть от 16 до 120 лет
+// @Tags Пользователи
+// @Accept json
+// @Produce json
+// @Param input body domain.UpdateClientProfileDTO true "Профиль клиента"
+// @Success 200 {object} domain.ClientProfile "Сохраненный профиль"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/me/profile [put]
+func (h *Handler) updateMyClientProfile(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	var req domain.UpdateClientProfileDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	profile, err := h.services.ClientProfile.Update(c.Request.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, profile)
+}
+
+// @Summary Получить профиль клиента
+// @Description Возвращает профиль клиента. Специалисту доступен только профиль клиента, у которого есть неотмененная запись к нему
+// @Tags Пользователи
+// @Produce json
+// @Param id path int true "ID клиента"
+// @Success 200 {object} domain.ClientProfile "Профиль клиента"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Клиент не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /clients/{id}/profile [get]
+func (h *Handler) getClientProfile(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	userRole, err := getUserRole(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	clientID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	profile, err := h.services.ClientProfile.GetForViewer(c.Request.Context(), clientID, userID, userRole)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			forbiddenResponse(c)
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(c, "клиент не найден")
+		default:
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	successResponse(c, http.StatusOK, profile)
+}
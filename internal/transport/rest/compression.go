@@ -0,0 +1,221 @@
+package rest
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Пути, ответы которых никогда не сжимаются
+var compressionSkipPaths = map[string]bool{
+	"/ws/signaling":            true,
+	"/metrics":                 true,
+	"/api/v1/admin/debug/vars": true,
+	"/api/v2/admin/debug/vars": true,
+}
+
+// Типы содержимого, которые уже сжаты и не выигрывают от повторного сжатия
+var compressionSkipContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/pdf",
+	"application/octet-stream",
+}
+
+// compressionMiddleware сжимает ответы gzip или deflate в зависимости от Accept-Encoding.
+// Тело буферизуется до MinSizeBytes: маленькие ответы, уже сжатые типы и ответы
+// с выставленным Content-Encoding отдаются как есть.
+func (h *Handler) compressionMiddleware() gin.HandlerFunc {
+	cfg := h.config.HTTP.Compression
+
+	return func(c *gin.Context) {
+		if !cfg.Enabled || compressionSkipPaths[c.Request.URL.Path] || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		c.Header("Vary", "Accept-Encoding")
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			level:          cfg.Level,
+			minSize:        cfg.MinSizeBytes,
+		}
+		c.Writer = writer
+		defer writer.finish()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding выбирает gzip, если клиент его принимает, иначе deflate.
+// Кодировки с q=0 считаются явно запрещенными.
+func negotiateEncoding(acceptEncoding string) string {
+	var deflateAccepted bool
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+
+		rejected := false
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if q, ok := strings.CutPrefix(param, "q="); ok {
+				if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+					rejected = true
+				}
+			}
+		}
+		if rejected {
+			continue
+		}
+
+		switch name {
+		case "gzip":
+			return "gzip"
+		case "deflate":
+			deflateAccepted = true
+		}
+	}
+
+	if deflateAccepted {
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter откладывает решение о сжатии, пока не наберется minSize байт
+// или обработчик не завершится
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	level    int
+	minSize  int
+
+	buf        bytes.Buffer
+	decided    bool
+	compressor io.WriteCloser
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.writeDecided(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Flush принудительно принимает решение, чтобы потоковые ответы не копились в буфере
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(w.buf.Len() >= w.minSize); err != nil {
+			return
+		}
+	}
+
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) writeDecided(data []byte) (int, error) {
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// decide выбирает режим вывода и сбрасывает накопленный буфер
+func (w *compressWriter) decide(largeEnough bool) error {
+	w.decided = true
+
+	if largeEnough && w.shouldCompress() {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+
+		var err error
+		if w.encoding == "gzip" {
+			w.compressor, err = gzip.NewWriterLevel(w.ResponseWriter, w.level)
+		} else {
+			w.compressor, err = flate.NewWriter(w.ResponseWriter, w.level)
+		}
+		if err != nil {
+			w.compressor = nil
+			header.Del("Content-Encoding")
+		}
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	_, err := w.writeDecided(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) shouldCompress() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	for _, skip := range compressionSkipContentTypes {
+		if strings.HasPrefix(contentType, skip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// finish вызывается после обработчиков: отдает оставшийся буфер и закрывает компрессор.
+// Ответы об ошибках, записанные после заголовков, проходят через тот же путь.
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide(w.buf.Len() >= w.minSize)
+	}
+
+	if w.compressor != nil {
+		w.compressor.Close()
+	}
+}
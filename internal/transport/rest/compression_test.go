@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/config"
+)
+
+// largeJSON тело заметно больше порога сжатия и хорошо сжимается
+var largeJSON = `{"data":[` + strings.Repeat(`{"name":"Петрова Анна","type":"lawyer"},`, 100) + `{}]}`
+
+func newCompressionTestRouter(cfg config.CompressionConfig) *gin.Engine {
+	h := &Handler{logger: zap.NewNop(), config: &config.Config{HTTP: config.HTTPConfig{Compression: cfg}}}
+
+	router := gin.New()
+	router.Use(h.compressionMiddleware())
+	large := func(c *gin.Context) { c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(largeJSON)) }
+	router.GET("/large", large)
+	router.GET("/ws/signaling", large)
+	router.GET("/api/v1/admin/debug/vars", large)
+	router.GET("/metrics", large)
+	router.GET("/small", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
+	router.GET("/image", func(c *gin.Context) { c.Data(http.StatusOK, "image/png", []byte(largeJSON)) })
+	router.GET("/failing", func(c *gin.Context) {
+		// Ответ об ошибке крупнее порога сжимается так же, как успешный
+		c.Data(http.StatusInternalServerError, "application/json; charset=utf-8", []byte(largeJSON))
+	})
+	return router
+}
+
+func enabledCompression() config.CompressionConfig {
+	return config.CompressionConfig{Enabled: true, Level: gzip.DefaultCompression, MinSizeBytes: 1024}
+}
+
+func request(router http.Handler, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var r io.Reader
+	switch encoding {
+	case "gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	case "deflate":
+		r = flate.NewReader(bytes.NewReader(body))
+	default:
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCompressionShrinksLargeResponses(t *testing.T) {
+	router := newCompressionTestRouter(enabledCompression())
+	plain := request(router, "/large", "")
+	if plain.Header().Get("Content-Encoding") != "" || plain.Body.String() != largeJSON {
+		t.Fatalf("response without Accept-Encoding was altered: encoding %q", plain.Header().Get("Content-Encoding"))
+	}
+
+	for _, tt := range []struct {
+		path           string
+		acceptEncoding string
+		want           string
+		status         int
+	}{
+		{"/large", "gzip, deflate, br", "gzip", http.StatusOK},
+		{"/large", "deflate", "deflate", http.StatusOK},
+		{"/large", "gzip;q=0, deflate", "deflate", http.StatusOK},
+		{"/failing", "gzip", "gzip", http.StatusInternalServerError},
+	} {
+		t.Run(tt.path+" "+tt.acceptEncoding, func(t *testing.T) {
+			w := request(router, tt.path, tt.acceptEncoding)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.want {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Vary = %q", w.Header().Get("Vary"))
+			}
+			if w.Body.Len() >= plain.Body.Len() {
+				t.Errorf("compressed body is %d bytes, plain is %d", w.Body.Len(), plain.Body.Len())
+			}
+			if got := decode(t, tt.want, w.Body.Bytes()); got != largeJSON {
+				t.Errorf("decoded body differs from the original")
+			}
+		})
+	}
+}
+
+func TestCompressionSkipList(t *testing.T) {
+	enabled := newCompressionTestRouter(enabledCompression())
+	disabled := enabledCompression()
+	disabled.Enabled = false
+
+	tests := []struct {
+		name           string
+		router         http.Handler
+		path           string
+		acceptEncoding string
+	}{
+		{"response under the threshold", enabled, "/small", "gzip"},
+		{"already compressed content type", enabled, "/image", "gzip"},
+		{"websocket upgrade path", enabled, "/ws/signaling", "gzip"},
+		{"metrics endpoint", enabled, "/api/v1/admin/debug/vars", "gzip"},
+		{"prometheus endpoint", enabled, "/metrics", "gzip"},
+		{"no supported encoding", enabled, "/large", "br"},
+		{"every encoding refused", enabled, "/large", "gzip;q=0, deflate;q=0"},
+		{"disabled in config", newCompressionTestRouter(disabled), "/large", "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.router, tt.path, tt.acceptEncoding)
+			if got := w.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("Content-Encoding = %q, want none", got)
+			}
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d", w.Code)
+			}
+			if strings.HasPrefix(w.Body.String(), "\x1f\x8b") {
+				t.Error("body is gzip-compressed")
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/service"
+)
+
+// @Summary Выгрузить свои данные
+// @Description Возвращает JSON со всеми данными текущего пользователя: профиль, профиль специалиста, записи, отзывы, чаты с сообщениями.
+// @Description Файл формируется потоково; выгрузка фиксируется в журнале аудита
+// @Tags Пользователи
+// @Produce json
+// @Success 200 {file} file "JSON-файл с данными пользователя"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/me/export [get]
+func (h *Handler) exportCurrentUserData(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	h.streamUserDataExport(c, userID, userID)
+}
+
+// @Summary Выгрузить данные пользователя
+// @Description Возвращает JSON со всеми данными пользователя по ID, как при выгрузке собственных данных. Доступно только администраторам
+// @Tags Администрирование
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {file} file "JSON-файл с данными пользователя"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Пользователь не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/export [get]
+func (h *Handler) exportUserData(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID пользователя")
+		return
+	}
+
+	h.streamUserDataExport(c, userID, adminID)
+}
+
+func (h *Handler) streamUserDataExport(c *gin.Context, userID, actorID int64) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user_%d_export.json"`, userID))
+
+	err := h.services.DataExport.ExportUserData(c.Request.Context(), c.Writer, userID, actorID)
+	if err == nil {
+		return
+	}
+
+	h.logger.Error("ошибка выгрузки данных пользователя", zap.Int64("userID", userID), zap.Error(err))
+
+	// После начала передачи файла статус уже отправлен, остается оборвать ответ
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(c, "пользователь не найден")
+			return
+		}
+		internalServerErrorResponse(c)
+	}
+}
@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etagResponse сериализует тело ответа и вычисляет ETag по его содержимому.
+// Тело включает updated_at сущности и вложенные коллекции, поэтому любое их
+// изменение меняет тег. При совпадении с If-None-Match отдается 304 без тела.
+func etagResponse(c *gin.Context, body interface{}, maxAge time.Duration) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		internalServerErrorResponse(c)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+func successResponseWithETag(c *gin.Context, data interface{}, maxAge time.Duration) {
+	etagResponse(c, newSuccessBody(c, data), maxAge)
+}
+
+func paginatedResponseWithETag(c *gin.Context, data interface{}, totalCount, page, pageSize int, maxAge time.Duration) {
+	etagResponse(c, newPaginatedBody(c, data, totalCount, page, pageSize), maxAge)
+}
+
+// etagMatches проверяет заголовок If-None-Match, который может содержать
+// несколько тегов через запятую, слабые теги (W/) или "*"
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expectedUpdatedAt возвращает updated_at, который должен остаться у сущности, чтобы обновление прошло:
+// expected_updated_at из тела запроса (updated_at из последнего прочитанного ответа) или заголовок
+// If-Unmodified-Since. Заголовок передается с точностью до секунды, поэтому сравнивается с current —
+// updated_at, прочитанным обработчиком: если сущность с тех пор не менялась, ожидается ровно current,
+// и хранилище отклонит изменение, сделанное между чтением и записью; иначе возвращается момент из
+// заголовка, который с updated_at не совпадет. Если ничего не передано, возвращает nil: проверка
+// не выполняется. При неверном заголовке отправляет 400
+func expectedUpdatedAt(c *gin.Context, fromBody *time.Time, current time.Time) (*time.Time, bool) {
+	if fromBody != nil {
+		return fromBody, true
+	}
+
+	header := strings.TrimSpace(c.GetHeader("If-Unmodified-Since"))
+	if header == "" {
+		return nil, true
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		badRequestResponse(c, "заголовок If-Unmodified-Since должен содержать дату в формате HTTP")
+		return nil, false
+	}
+
+	if current.Truncate(time.Second).After(since) {
+		return &since, true
+	}
+	return &current, true
+}
+
+// expectedVersion возвращает версию, которую видел клиент, для оптимистичной блокировки:
+// expected_version из тела запроса или номер версии из заголовка If-Match ("3" или W/"3").
+// Если версия не передана или имеет неверный формат, отправляет 400 и возвращает false
+func expectedVersion(c *gin.Context, fromBody *int) (*int, bool) {
+	if fromBody != nil {
+		return fromBody, true
+	}
+
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		badRequestResponse(c, "необходимо передать expected_version или заголовок If-Match")
+		return nil, false
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+	if err != nil {
+		badRequestResponse(c, "заголовок If-Match должен содержать номер версии")
+		return nil, false
+	}
+
+	return &version, true
+}
@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testContext создает контекст запроса с заголовками для проверки разбора условий запроса
+func testContext(headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+	for name, value := range headers {
+		c.Request.Header.Set(name, value)
+	}
+	return c, w
+}
+
+func TestEtagMatches(t *testing.T) {
+	const etag = `"abc"`
+	tests := []struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"old", "abc"`, true},
+		{`"old"`, false},
+		{"*", true},
+		{"abc", false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.ifNoneMatch, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.ifNoneMatch, got, tt.want)
+		}
+	}
+}
+
+func TestExpectedVersion(t *testing.T) {
+	fromBody := 5
+	tests := []struct {
+		name       string
+		body       *int
+		ifMatch    string
+		want       int
+		wantStatus int
+	}{
+		{name: "body wins over header", body: &fromBody, ifMatch: `"3"`, want: 5},
+		{name: "strong tag", ifMatch: `"3"`, want: 3},
+		{name: "weak tag", ifMatch: `W/"3"`, want: 3},
+		{name: "bare number", ifMatch: "3", want: 3},
+		{name: "missing", wantStatus: http.StatusBadRequest},
+		{name: "not a version", ifMatch: `"abc"`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.ifMatch != "" {
+				headers["If-Match"] = tt.ifMatch
+			}
+			c, w := testContext(headers)
+
+			version, ok := expectedVersion(c, tt.body)
+			if tt.wantStatus != 0 {
+				if ok || w.Code != tt.wantStatus {
+					t.Errorf("ok = %v, status = %d; want %d", ok, w.Code, tt.wantStatus)
+				}
+				return
+			}
+			if !ok || version == nil || *version != tt.want {
+				t.Errorf("expectedVersion() = %v, %v; want %d", version, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedUpdatedAt(t *testing.T) {
+	fromBody := time.Date(2026, 3, 2, 9, 15, 30, 123456000, time.UTC)
+	current := time.Date(2026, 3, 2, 9, 15, 30, 654321000, time.UTC)
+	tests := []struct {
+		name              string
+		body              *time.Time
+		ifUnmodifiedSince string
+		want              *time.Time
+		wantStatus        int
+	}{
+		{name: "nothing passed", want: nil},
+		{name: "body keeps full precision", body: &fromBody, ifUnmodifiedSince: "Mon, 02 Mar 2026 09:00:00 GMT", want: &fromBody},
+		{
+			// Заголовок с точностью до секунды покрывает изменение в течение этой секунды
+			name:              "header covers its whole second",
+			ifUnmodifiedSince: "Mon, 02 Mar 2026 09:15:30 GMT",
+			want:              &current,
+		},
+		{
+			name:              "modified after the header",
+			ifUnmodifiedSince: "Mon, 02 Mar 2026 09:15:29 GMT",
+			want:              ptrTime(time.Date(2026, 3, 2, 9, 15, 29, 0, time.UTC)),
+		},
+		{name: "invalid header", ifUnmodifiedSince: "yesterday", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.ifUnmodifiedSince != "" {
+				headers["If-Unmodified-Since"] = tt.ifUnmodifiedSince
+			}
+			c, w := testContext(headers)
+
+			got, ok := expectedUpdatedAt(c, tt.body, current)
+			if tt.wantStatus != 0 {
+				if ok || w.Code != tt.wantStatus {
+					t.Errorf("ok = %v, status = %d; want %d", ok, w.Code, tt.wantStatus)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expectedUpdatedAt() rejected the request: %s", w.Body)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) {
+				t.Errorf("expectedUpdatedAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
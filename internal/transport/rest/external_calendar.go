@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Подключить внешний календарь
+// @Description Сохраняет ссылку на ICS-календарь специалиста (http, https или webcal) и сразу импортирует занятость. Календарь обновляется периодически, занятое в нем время недоступно для записи. Пустой url отключает календарь
+// @Tags Специалисты
+// @Accept json
+// @Produce json
+// @Param input body domain.SetExternalCalendarDTO true "Ссылка на календарь"
+// @Success 200 {object} domain.ExternalCalendarFeed "Подключенный календарь"
+// @Success 204 "Календарь отключен"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/external-calendar [put]
+func (h *Handler) setExternalCalendar(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("ошибка при получении данных специалиста", zap.Error(err))
+		notFoundResponse(c, "профиль специалиста не найден")
+		return
+	}
+
+	var req domain.SetExternalCalendarDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	feed, err := h.services.Calendar.SetFeed(c.Request.Context(), specialist.ID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if feed == nil {
+		noContentResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, feed)
+}
+
+// @Summary Получить внешний календарь
+// @Description Возвращает подключенный ICS-календарь специалиста и состояние последней синхронизации
+// @Tags Специалисты
+// @Produce json
+// @Success 200 {object} domain.ExternalCalendarFeed "Подключенный календарь"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Календарь не подключен"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/external-calendar [get]
+func (h *Handler) getExternalCalendar(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("ошибка при получении данных специалиста", zap.Error(err))
+		notFoundResponse(c, "профиль специалиста не найден")
+		return
+	}
+
+	feed, err := h.services.Calendar.GetFeed(c.Request.Context(), specialist.ID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if feed == nil {
+		notFoundResponse(c, "внешний календарь не подключен")
+		return
+	}
+
+	successResponse(c, http.StatusOK, feed)
+}
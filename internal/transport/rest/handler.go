@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"expvar"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +11,9 @@ import (
 
 	"laps/config"
 	"laps/internal/domain"
+	"laps/internal/health"
+	"laps/internal/metrics"
+	"laps/internal/ratelimit"
 	"laps/internal/service"
 	"laps/internal/transport/websocket"
 )
@@ -19,179 +23,299 @@ type Handler struct {
 	logger       *zap.Logger
 	config       *config.Config
 	signalingHub *websocket.SignalingHub
+	rateLimiter  ratelimit.Store
+
+	// readinessChecks проверки зависимостей для /healthz/ready
+	readinessChecks []health.Check
 }
 
-func NewHandler(services *service.Services, logger *zap.Logger, config *config.Config, signalingHub *websocket.SignalingHub) *Handler {
+func NewHandler(services *service.Services, logger *zap.Logger, config *config.Config, signalingHub *websocket.SignalingHub, rateLimiter ratelimit.Store, readinessChecks []health.Check) *Handler {
 	return &Handler{
-		services:     services,
-		logger:       logger,
-		config:       config,
-		signalingHub: signalingHub,
+		services:        services,
+		logger:          logger,
+		config:          config,
+		signalingHub:    signalingHub,
+		rateLimiter:     rateLimiter,
+		readinessChecks: readinessChecks,
 	}
 }
 
 func (h *Handler) InitRoutes(router *gin.Engine) {
+	// Без списка доверенных прокси ClientIP берется из адреса соединения,
+	// чтобы клиент не мог подменить IP через X-Forwarded-For
+	if err := router.SetTrustedProxies(h.config.HTTP.TrustedProxies); err != nil {
+		h.logger.Error("неверный список доверенных прокси", zap.Error(err))
+	}
+
+	// Перехват паник должен быть первым, чтобы покрывать и остальные middleware
+	router.Use(h.recoveryMiddleware())
+
+	router.Use(h.tracingMiddleware())
+
 	router.Use(h.loggerMiddleware())
 
 	router.Use(h.errorMiddleware())
 
 	router.Use(h.corsMiddleware())
 
-	api := router.Group("/api/v1")
-	{
-		auth := api.Group("/auth")
-		{
-			auth.POST("/register", h.register)
-			auth.POST("/login", h.login)
-			auth.POST("/refresh", h.refreshTokens)
-			auth.POST("/logout", h.logout)
-		}
-
-		users := api.Group("/users")
-		users.Use(h.authMiddleware())
-		{
-			users.GET("/me", h.getCurrentUser)
-			users.GET("/:id", h.getUserByID)
-			users.PUT("/:id", h.updateUser)
-			users.PUT("/:id/password", h.updatePassword)
+	router.Use(h.compressionMiddleware())
 
-			admin := users.Group("/")
-			admin.Use(h.adminMiddleware())
-			{
-				admin.POST("/", h.createUser)
-				admin.GET("/", h.getUsers)
-				admin.DELETE("/:id", h.deleteUser)
-			}
-		}
+	// Версии API используют одни и те же обработчики; формат ответа
+	// определяется версией группы (см. apiVersionMiddleware)
+	h.registerAPIRoutes(router.Group("/api/v1", h.apiVersionMiddleware(apiV1), h.deprecationMiddleware()))
+	h.registerAPIRoutes(router.Group("/api/v2", h.apiVersionMiddleware(apiV2)))
 
-		specialists := api.Group("/specialists")
-		{
-			specialists.GET("/", h.getSpecialists)
-			specialists.GET("/:id", h.getSpecialistByID)
-			specialists.GET("/:id/reviews", h.getSpecialistReviewsRedirect)
-			specialists.GET("/me", h.authMiddleware(), h.getMySpecialistProfile)
+	// Test route to verify no auth middleware
+	router.GET("/test-no-auth", func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "no auth required", "path": c.Request.URL.Path})
+	})
 
-			auth := specialists.Group("/", h.authMiddleware())
-			{
-				auth.POST("/", h.createSpecialist)
-				auth.PUT("/:id", h.updateSpecialist)
-				auth.DELETE("/:id", h.deleteSpecialist)
+	// Карта сайта для поисковиков; при большом числе профилей /sitemap.xml ссылается на /sitemaps/{page}.xml
+	router.GET("/sitemap.xml", h.getSitemap)
+	router.GET("/sitemaps/:file", h.getSitemapPage)
 
-				auth.PUT("/:id/education/:eduId", h.updateSpecialistEducation)
-				auth.DELETE("/:id/education/:eduId", h.deleteSpecialistEducation)
+	// Метрики для Prometheus; закрываются от внешнего трафика на уровне прокси, как и /healthz
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
-				auth.PUT("/:id/work-experience/:expId", h.updateSpecialistWorkExperience)
-				auth.DELETE("/:id/work-experience/:expId", h.deleteSpecialistWorkExperience)
+	// WebSocket signaling route for WebRTC (no middleware - handles auth internally)
+	router.GET("/ws/signaling", h.signalingHub.HandleWebSocket)
+}
 
-				auth.POST("/:id/specializations/:specId", h.addSpecialistSpecialization)
-				auth.DELETE("/:id/specializations/:specId", h.removeSpecialistSpecialization)
+// registerAPIRoutes регистрирует все маршруты API в переданной группе версии
+func (h *Handler) registerAPIRoutes(api *gin.RouterGroup) {
+	api.GET("/healthz/ready", h.readinessCheck)
 
-				specialistRoutes := auth.Group("/specialist-actions")
-				specialistRoutes.Use(h.specialistMiddleware())
-				{
-					specialistRoutes.GET("/appointments", h.getSpecialistAppointments)
-				}
+	auth := api.Group("/auth", h.rateLimitMiddleware("auth"))
+	{
+		auth.POST("/register", h.register)
+		auth.POST("/login", h.login)
+		auth.POST("/refresh", h.refreshTokens)
+		auth.POST("/logout", h.logout)
+		auth.POST("/accept-invite", h.acceptInvite)
+		auth.POST("/forgot-password", h.forgotPassword)
+		auth.POST("/reset-password", h.resetPassword)
+	}
 
-				auth.POST("/:id/photo", h.uploadSpecialistPhoto)
-				auth.DELETE("/:id/photo", h.deleteSpecialistPhoto)
-			}
+	users := api.Group("/users", h.rateLimitMiddleware("users"))
+	users.Use(h.authMiddleware())
+	{
+		users.GET("/me", h.getCurrentUser)
+		users.GET("/me/export", h.exportCurrentUserData)
+		users.DELETE("/me", h.deleteCurrentUser)
+		users.GET("/me/profile", h.getMyClientProfile)
+		users.PUT("/me/profile", h.updateMyClientProfile)
+		users.GET("/me/notification-preferences", h.getMyNotificationPreferences)
+		users.GET("/me/appointments/upcoming", h.getMyNextAppointment)
+		users.GET("/me/pending-reviews", h.getMyPendingReviews)
+		users.PUT("/me/notification-preferences", h.updateMyNotificationPreferences)
+		users.GET("/:id", h.getUserByID)
+		users.PUT("/:id", h.updateUser)
+		users.PUT("/:id/password", h.updatePassword)
+
+		admin := users.Group("/")
+		admin.Use(h.adminMiddleware())
+		{
+			admin.POST("/", h.createUser)
+			admin.GET("/", h.getUsers)
+			admin.DELETE("/:id", h.deleteUser)
+			admin.PATCH("/:id/role", h.changeUserRole)
 		}
+	}
 
-		h.initScheduleRoutes(api)
+	clients := api.Group("/clients", h.rateLimitMiddleware("users"), h.authMiddleware())
+	{
+		clients.GET("/:id/profile", h.getClientProfile)
+	}
 
-		appointments := api.Group("/appointments")
+	specialists := api.Group("/specialists", h.rateLimitMiddleware("specialists"))
+	{
+		specialists.GET("/", h.optionalAuthMiddleware(), h.getSpecialists)
+		specialists.GET("/:id", h.optionalAuthMiddleware(), h.getSpecialistByID)
+		specialists.GET("/:id/reviews", h.getSpecialistReviewsRedirect)
+		specialists.GET("/:id/next-available", h.getSpecialistNextAvailable)
+		specialists.GET("/:id/availability", h.getSpecialistAvailability)
+		specialists.GET("/:id/calendar/:year/:month", h.getSpecialistMonthCalendar)
+		specialists.GET("/:id/price-history", h.getSpecialistPriceHistory)
+		specialists.GET("/:id/specializations", h.getSpecialistSpecializations)
+		specialists.GET("/:id/structured-data", h.getSpecialistStructuredData)
+		specialists.GET("/me", h.authMiddleware(), h.getMySpecialistProfile)
+
+		auth := specialists.Group("/", h.authMiddleware())
 		{
-			auth := appointments.Group("/")
-			auth.Use(h.authMiddleware())
+			auth.POST("/", h.createSpecialist)
+			auth.POST("/me/appointments/cancel-range", h.cancelSpecialistAppointmentRange)
+			auth.GET("/me/appointments/export", h.specialistMiddleware(), h.exportSpecialistAppointments)
+			auth.GET("/me/external-calendar", h.specialistMiddleware(), h.getExternalCalendar)
+			auth.PUT("/me/external-calendar", h.specialistMiddleware(), h.setExternalCalendar)
+			auth.GET("/me/blocked-clients", h.specialistMiddleware(), h.getBlockedClients)
+			auth.POST("/me/blocked-clients", h.specialistMiddleware(), h.blockClient)
+			auth.DELETE("/me/blocked-clients/:clientId", h.specialistMiddleware(), h.unblockClient)
+			auth.GET("/me/tags", h.specialistMiddleware(), h.getMySpecialistTags)
+			auth.PUT("/me/tags", h.specialistMiddleware(), h.setMySpecialistTags)
+			auth.PUT("/me/translations", h.specialistMiddleware(), h.setMySpecialistTranslations)
+			auth.GET("/me/onboarding", h.specialistMiddleware(), h.getMyOnboardingChecklist)
+			auth.PATCH("/me/availability", h.specialistMiddleware(), h.setMySpecialistAvailability)
+			auth.POST("/:id/slots/reserve", h.reserveSpecialistSlot)
+			auth.PUT("/:id", h.updateSpecialist)
+			auth.DELETE("/:id", h.deleteSpecialist)
+			auth.PATCH("/:id/verify", h.adminMiddleware(), h.verifySpecialist)
+
+			auth.PUT("/:id/education/:eduId", h.updateSpecialistEducation)
+			auth.DELETE("/:id/education/:eduId", h.deleteSpecialistEducation)
+
+			auth.PUT("/:id/work-experience/:expId", h.updateSpecialistWorkExperience)
+			auth.DELETE("/:id/work-experience/:expId", h.deleteSpecialistWorkExperience)
+
+			auth.PUT("/:id/specializations", h.setSpecialistSpecializations)
+			auth.POST("/:id/specializations/:specId", h.addSpecialistSpecialization)
+			auth.DELETE("/:id/specializations/:specId", h.removeSpecialistSpecialization)
+
+			specialistRoutes := auth.Group("/specialist-actions")
+			specialistRoutes.Use(h.specialistMiddleware())
 			{
-				auth.POST("/", h.createAppointment)
-				auth.GET("/:id", h.getAppointmentByID)
-				auth.PUT("/:id", h.updateAppointment)
-				auth.DELETE("/:id", h.cancelAppointment)
-				auth.GET("/", h.getAppointments)
-				auth.GET("/check-pay", h.checkConsultationType)
+				specialistRoutes.GET("/appointments", h.getSpecialistAppointments)
 			}
+
+			auth.POST("/:id/photo", h.uploadSpecialistPhoto)
+			auth.DELETE("/:id/photo", h.deleteSpecialistPhoto)
 		}
+	}
 
-		reviews := api.Group("/reviews")
-		{
-			reviews.GET("/", h.getReviews)
-			reviews.GET("/:id", h.getReviewByID)
-			reviews.GET("/:id/replies", h.getReviewReplies)
+	specialistTypes := api.Group("/specialist-types", h.rateLimitMiddleware("specialists"))
+	{
+		specialistTypes.GET("/", h.getSpecialistTypes)
+	}
 
-			auth := reviews.Group("/")
-			auth.Use(h.authMiddleware())
-			{
-				auth.POST("/", h.createReview)
-				auth.DELETE("/:id", h.deleteReview)
-				auth.POST("/:id/replies", h.createReviewReply)
-				auth.DELETE("/replies/:replyId", h.deleteReviewReply)
-			}
-		}
+	h.initScheduleRoutes(api)
 
-		specializations := api.Group("/specializations")
+	appointments := api.Group("/appointments", h.rateLimitMiddleware("appointments"))
+	{
+		auth := appointments.Group("/")
+		auth.Use(h.authMiddleware())
 		{
-			specializations.GET("/", h.getSpecializations)
-			specializations.GET("/:id", h.getSpecializationByID)
-
-			admin := specializations.Group("/")
-			admin.Use(h.authMiddleware(), h.adminMiddleware())
-			{
-				admin.POST("/", h.createSpecialization)
-				admin.PUT("/:id", h.updateSpecialization)
-				admin.DELETE("/:id", h.deleteSpecialization)
-			}
+			auth.POST("/", h.createAppointment)
+			auth.POST("/hold", h.holdSlot)
+			auth.DELETE("/hold/:id", h.releaseSlotHold)
+			auth.GET("/upcoming", h.getUpcomingAppointments)
+			auth.GET("/:id", h.getAppointmentByID)
+			auth.GET("/:id/invoice", h.getAppointmentInvoice)
+			auth.GET("/:id/calendar.ics", h.getAppointmentCalendar)
+			auth.PUT("/:id", h.updateAppointment)
+			auth.DELETE("/:id", h.cancelAppointment)
+			auth.POST("/:id/transfer", h.adminMiddleware(), h.transferAppointment)
+			auth.POST("/:id/check-in", h.checkInAppointment)
+			auth.POST("/:id/start", h.startAppointment)
+			auth.GET("/", h.getAppointments)
+			auth.GET("/check-pay", h.checkConsultationType)
 		}
+	}
 
-		education := api.Group("/education")
-		{
-			education.GET("/", h.getEducation)
-			education.GET("/:id", h.getEducationByID)
+	reviews := api.Group("/reviews", h.rateLimitMiddleware("reviews"))
+	{
+		reviews.GET("/", h.optionalAuthMiddleware(), h.getReviews)
+		reviews.GET("/summary", h.getReviewRatingSummary)
+		reviews.GET("/:id", h.getReviewByID)
+		reviews.GET("/:id/replies", h.getReviewReplies)
 
-			auth := education.Group("/")
-			auth.Use(h.authMiddleware())
-			{
-				auth.POST("/", h.addEducation)
-				auth.PUT("/:id", h.updateEducation)
-				auth.DELETE("/:id", h.deleteEducation)
-			}
+		auth := reviews.Group("/")
+		auth.Use(h.authMiddleware())
+		{
+			auth.POST("/", h.createReview)
+			auth.POST("/media", h.uploadReviewMedia)
+			auth.POST("/:id/photos", h.addReviewPhoto)
+			auth.PUT("/:id", h.updateReview)
+			auth.DELETE("/:id", h.deleteReview)
+			auth.POST("/:id/replies", h.createReviewReply)
+			auth.DELETE("/replies/:replyId", h.deleteReviewReply)
 		}
+	}
 
-		workExperience := api.Group("/work-experience")
+	specializations := api.Group("/specializations", h.rateLimitMiddleware("specializations"))
+	{
+		specializations.GET("/", h.getSpecializations)
+		specializations.GET("/:id", h.getSpecializationByID)
+
+		admin := specializations.Group("/")
+		admin.Use(h.authMiddleware(), h.adminMiddleware())
 		{
-			workExperience.GET("/", h.getWorkExperience)
-			workExperience.GET("/:id", h.getWorkExperienceByID)
+			admin.POST("/", h.createSpecialization)
+			admin.PUT("/:id", h.updateSpecialization)
+			admin.PUT("/:id/translations", h.setSpecializationTranslations)
+			admin.DELETE("/:id", h.deleteSpecialization)
+		}
+	}
 
-			auth := workExperience.Group("/")
-			auth.Use(h.authMiddleware())
-			{
-				auth.POST("/", h.addWorkExperience)
-				auth.PUT("/:id", h.updateWorkExperience)
-				auth.DELETE("/:id", h.deleteWorkExperience)
-			}
+	tags := api.Group("/tags", h.rateLimitMiddleware("tags"))
+	{
+		tags.GET("/", h.searchTags)
+	}
+
+	education := api.Group("/education", h.rateLimitMiddleware("education"))
+	{
+		education.GET("/", h.getEducation)
+		education.GET("/:id", h.getEducationByID)
+
+		auth := education.Group("/")
+		auth.Use(h.authMiddleware())
+		{
+			auth.POST("/", h.addEducation)
+			auth.PUT("/:id", h.updateEducation)
+			auth.DELETE("/:id", h.deleteEducation)
 		}
+	}
+
+	workExperience := api.Group("/work-experience", h.rateLimitMiddleware("work-experience"))
+	{
+		workExperience.GET("/", h.getWorkExperience)
+		workExperience.GET("/:id", h.getWorkExperienceByID)
 
-		// REST compliant routes for specialists
-		specialists.POST("/:id/work-experience", h.authMiddleware(), h.addWorkExperienceToSpecialist)
-		specialists.POST("/:id/education", h.authMiddleware(), h.addEducationToSpecialist)
+		auth := workExperience.Group("/")
+		auth.Use(h.authMiddleware())
+		{
+			auth.POST("/", h.addWorkExperience)
+			auth.PUT("/:id", h.updateWorkExperience)
+			auth.DELETE("/:id", h.deleteWorkExperience)
+		}
 	}
 
+	// REST compliant routes for specialists
+	specialists.POST("/:id/work-experience", h.authMiddleware(), h.addWorkExperienceToSpecialist)
+	specialists.POST("/:id/education", h.authMiddleware(), h.addEducationToSpecialist)
+
 	// Initialize chat routes
 	h.initChatRoutes(api)
 
-	// Test route to verify no auth middleware
-	router.GET("/test-no-auth", func(c *gin.Context) {
-		c.JSON(200, gin.H{"message": "no auth required", "path": c.Request.URL.Path})
-	})
+	calls := api.Group("/calls", h.rateLimitMiddleware("calls"), h.authMiddleware())
+	{
+		calls.GET("/:session_id/state", h.getCallState)
+		calls.POST("/:session_id/feedback", h.submitCallFeedback)
+	}
 
-	// WebSocket signaling route for WebRTC (no middleware - handles auth internally)
-	router.GET("/ws/signaling", h.signalingHub.HandleWebSocket)
+	admin := api.Group("/admin", h.rateLimitMiddleware("admin"), h.authMiddleware(), h.adminMiddleware())
+	{
+		admin.GET("/audit-log", h.getAuditLog)
+		// Счетчики expvar (кэш) и memstats процесса — только для администраторов
+		admin.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+		admin.GET("/call-feedback/summary", h.getCallFeedbackSummary)
+		admin.GET("/appointments", h.searchAppointments)
+		admin.GET("/appointments/export", h.exportAllAppointments)
+		admin.GET("/chat-sessions", h.getUserChatSessions)
+		admin.GET("/specialists/:id/events", h.getSpecialistEvents)
+		admin.POST("/specialists/:id/restore", h.restoreSpecialist)
+		admin.GET("/users/:id/export", h.exportUserData)
+		admin.POST("/users/invite", h.inviteUser)
+		admin.POST("/webhooks", h.createWebhookEndpoint)
+		admin.GET("/webhooks", h.getWebhookEndpoints)
+		admin.DELETE("/webhooks/:id", h.deleteWebhookEndpoint)
+		admin.GET("/webhooks/:id/deliveries", h.getWebhookDeliveries)
+	}
 }
 
 func (h *Handler) initScheduleRoutes(api *gin.RouterGroup) {
-	schedules := api.Group("/schedules")
+	schedules := api.Group("/schedules", h.rateLimitMiddleware("schedules"))
 	{
 		schedules.GET("/free-slots", h.getFreeSlots)
+		schedules.POST("/free-slots/batch", h.getFreeSlotsBatch)
 		schedules.GET("/week", h.getScheduleWeek)
 		schedules.GET("/", h.getSchedules)
 		schedules.GET("/:id", h.getScheduleByID)
@@ -203,6 +327,8 @@ func (h *Handler) initScheduleRoutes(api *gin.RouterGroup) {
 				specialistRoutes.POST("/", h.createSchedule)
 				specialistRoutes.PUT("/", h.updateSchedule)
 				specialistRoutes.DELETE("/:id", h.deleteSchedule)
+				specialistRoutes.PUT("/overrides/:date", h.setScheduleOverride)
+				specialistRoutes.DELETE("/overrides/:date", h.deleteScheduleOverride)
 			}
 		}
 	}
@@ -211,7 +337,7 @@ func (h *Handler) initScheduleRoutes(api *gin.RouterGroup) {
 func (h *Handler) initChatRoutes(api *gin.RouterGroup) {
 	chatHandler := NewChatHandler(h.services.Chat)
 	
-	chat := api.Group("/chat")
+	chat := api.Group("/chat", h.rateLimitMiddleware("chat"))
 	chat.Use(h.authMiddleware())
 	{
 		// Chat sessions
@@ -221,6 +347,9 @@ func (h *Handler) initChatRoutes(api *gin.RouterGroup) {
 			sessions.GET("/", chatHandler.ListChatSessions)
 			sessions.GET("/:id", chatHandler.GetChatSession)
 			sessions.PATCH("/:id", chatHandler.UpdateChatSession)
+			sessions.DELETE("/:id", chatHandler.ArchiveChatSession)
+			sessions.GET("/:id/transcript", chatHandler.ExportTranscript)
+			sessions.GET("/:id/stats", chatHandler.GetChatSessionStats)
 			sessions.GET("/appointment/:appointment_id", chatHandler.GetChatSessionByAppointment)
 		}
 		
@@ -233,6 +362,8 @@ func (h *Handler) initChatRoutes(api *gin.RouterGroup) {
 		messages := chat.Group("/messages")
 		{
 			messages.POST("/", chatHandler.SendMessage)
+			messages.POST("/:id/reactions", chatHandler.AddReaction)
+			messages.DELETE("/:id/reactions/:emoji", chatHandler.RemoveReaction)
 		}
 		
 		// Chat summary
@@ -283,14 +414,9 @@ func (h *Handler) getSpecialistAppointments(c *gin.Context) {
 		}
 	}
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	if err != nil || limit < 0 {
-		limit = 20
-	}
-	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
-	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
 
 	filter := domain.AppointmentFilter{
 		SpecialistID: &specialist.ID,
@@ -352,5 +478,5 @@ func (h *Handler) getChatCallStatus(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	rawResponse(c, http.StatusOK, response)
 }
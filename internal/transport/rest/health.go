@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/health"
+)
+
+// Время, за которое должна ответить каждая зависимость
+const readinessTimeout = time.Second
+
+// @Summary Проверка готовности
+// @Description Проверяет доступность базы данных, файлового хранилища и Redis (если используется). Каждая зависимость должна ответить за 1 секунду
+// @Tags Служебные
+// @Produce json
+// @Success 200 {object} health.Report "Все зависимости доступны"
+// @Failure 503 {object} health.Report "Часть зависимостей недоступна, см. failed"
+// @Router /healthz/ready [get]
+func (h *Handler) readinessCheck(c *gin.Context) {
+	report := health.Run(c.Request.Context(), h.readinessChecks, readinessTimeout)
+	if !report.Healthy() {
+		h.logger.Warn("проверка готовности не пройдена", zap.Strings("failed", report.Failed))
+		rawResponse(c, http.StatusServiceUnavailable, report)
+		return
+	}
+
+	rawResponse(c, http.StatusOK, report)
+}
@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
@@ -19,6 +22,11 @@ const (
 	userRoleCtx         = "user_role"
 )
 
+// tracingMiddleware создает серверный span на каждый запрос с маршрутом и статусом ответа
+func (h *Handler) tracingMiddleware() gin.HandlerFunc {
+	return otelgin.Middleware(h.config.Name)
+}
+
 func (h *Handler) loggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -63,30 +71,43 @@ func (h *Handler) errorMiddleware() gin.HandlerFunc {
 	}
 }
 
+// corsMiddleware разрешает кросс-доменные запросы только с источников из CORS_ALLOWED_ORIGINS ("*" разрешает любой).
+// Preflight-запрос с другого источника отклоняется с 403, остальные запросы выполняются без CORS-заголовков,
+// и браузер не отдает ответ странице
 func (h *Handler) corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Ответ зависит от Origin, поэтому кэши не должны отдавать его другому источнику
+		c.Writer.Header().Add("Vary", "Origin")
+
 		origin := c.Request.Header.Get("Origin")
-		if origin != "" {
-			// Check if origin is in allowed origins list
-			allowed := false
-			for _, allowedOrigin := range h.config.CORS.AllowedOrigins {
-				if allowedOrigin == "*" || allowedOrigin == origin {
-					allowed = true
-					break
-				}
+		if origin == "" {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusNoContent)
+				return
 			}
-			
-			if allowed {
-				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-				c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-				c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-				c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type, Authorization")
-				c.Writer.Header().Set("Access-Control-Max-Age", "86400")
+			c.Next()
+			return
+		}
+
+		if !h.originAllowed(origin) {
+			if c.Request.Method == http.MethodOptions {
+				h.logger.Warn("отклонен preflight-запрос с неразрешенного источника", zap.String("origin", origin))
+				errorResponse(c, http.StatusForbidden, "источник запроса не разрешен")
+				c.Abort()
+				return
 			}
+			c.Next()
+			return
 		}
 
-		if c.Request.Method == "OPTIONS" {
+		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-None-Match, If-Match, If-Unmodified-Since")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type, Authorization, ETag, Deprecation, Sunset, Link, Retry-After, Content-Disposition")
+		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
+
+		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
@@ -95,6 +116,18 @@ func (h *Handler) corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+func (h *Handler) originAllowed(origin string) bool {
+	if h.config.CORS.AllowAllOrigins {
+		return true
+	}
+	for _, allowedOrigin := range h.config.CORS.AllowedOrigins {
+		if allowedOrigin == origin {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *Handler) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		header := c.GetHeader(authorizationHeader)
@@ -122,10 +155,28 @@ func (h *Handler) authMiddleware() gin.HandlerFunc {
 		c.Set(userIDCtx, userID)
 		c.Set(userRoleCtx, userRole)
 
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(
+			attribute.Int64("enduser.id", userID),
+			attribute.String("enduser.role", string(userRole)),
+		)
+
 		c.Next()
 	}
 }
 
+// optionalAuthMiddleware определяет пользователя по токену, если он передан, но не требует авторизации.
+// Некорректный токен отклоняется так же, как в authMiddleware
+func (h *Handler) optionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(authorizationHeader) == "" {
+			c.Next()
+			return
+		}
+
+		h.authMiddleware()(c)
+	}
+}
+
 func (h *Handler) adminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get(userRoleCtx)
@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Получить настройки уведомлений
+// @Description Возвращает канал доставки, группы уведомлений и разрешенные каналы текущего пользователя.
+// @Description По умолчанию уведомления отправляются на email, транзакционные уведомления включены, рекламные выключены
+// @Tags Пользователи
+// @Produce json
+// @Success 200 {object} domain.NotificationPreferences "Настройки уведомлений"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/me/notification-preferences [get]
+func (h *Handler) getMyNotificationPreferences(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	prefs, err := h.services.Notification.Get(c.Request.Context(), userID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, prefs)
+}
+
+// @Summary Обновить настройки уведомлений
+// @Description Задает предпочитаемый канал доставки (email, sms или push), включает и отключает группы уведомлений
+// @Description (напоминания, подтверждения, чат, рекламные) и каналы email и SMS. Не переданные поля не меняются
+// @Tags Пользователи
+// @Accept json
+// @Produce json
+// @Param input body domain.UpdateNotificationPreferencesDTO true "Настройки уведомлений"
+// @Success 200 {object} domain.NotificationPreferences "Сохраненные настройки"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/me/notification-preferences [put]
+func (h *Handler) updateMyNotificationPreferences(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	var req domain.UpdateNotificationPreferencesDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	prefs, err := h.services.Notification.Update(c.Request.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, prefs)
+}
@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Чек-лист онбординга специалиста
+// @Description Возвращает шаги заполнения профиля текущего специалиста (образование, опыт работы, фото, расписание, специализация, верификация)
+// @Description с признаком выполнения каждого; completed равен true, когда выполнены все шаги
+// @Tags Специалисты
+// @Produce json
+// @Success 200 {object} domain.OnboardingChecklist "Чек-лист онбординга"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/onboarding [get]
+func (h *Handler) getMyOnboardingChecklist(c *gin.Context) {
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	checklist, err := h.services.Onboarding.GetChecklist(c.Request.Context(), specialist)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, checklist)
+}
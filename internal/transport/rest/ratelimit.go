@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/metrics"
+	"laps/internal/ratelimit"
+)
+
+// rateLimitMiddleware ограничивает частоту запросов к группе маршрутов.
+// Клиент определяется по ID пользователя из access-токена, а без него по IP
+// (X-Forwarded-For учитывается только от доверенных прокси, см. HTTP_TRUSTED_PROXIES).
+func (h *Handler) rateLimitMiddleware(group string) gin.HandlerFunc {
+	cfg := h.config.RateLimit
+
+	return func(c *gin.Context) {
+		if !cfg.Enabled || h.rateLimiter == nil || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		class, limit := "write", cfg.Write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			class, limit = "read", cfg.Read
+		}
+
+		clientKey := h.rateLimitClientKey(c)
+		key := group + ":" + class + ":" + clientKey
+		result, err := h.rateLimiter.Allow(c.Request.Context(), key, ratelimit.Limit{Rate: limit.RPS, Burst: limit.Burst})
+		if err != nil {
+			// Недоступность хранилища лимитов не должна блокировать API
+			h.logger.Warn("ошибка проверки лимита запросов", zap.String("group", group), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if !result.Allowed {
+			keyType, _, _ := strings.Cut(clientKey, ":")
+			metrics.RateLimitThrottled.WithLabelValues(group, keyType).Inc()
+			c.Header("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(result.RetryAfter.Seconds())))))
+			errorResponse(c, http.StatusTooManyRequests, "слишком много запросов, повторите позже")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitClientKey возвращает user:<id> для запросов с валидным токеном и ip:<адрес> для остальных
+func (h *Handler) rateLimitClientKey(c *gin.Context) string {
+	if userID, err := getUserID(c); err == nil {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+
+	if token, ok := strings.CutPrefix(c.GetHeader(authorizationHeader), "Bearer "); ok {
+		if userID, _, err := h.services.Auth.ParseToken(c.Request.Context(), token); err == nil {
+			return "user:" + strconv.FormatInt(userID, 10)
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,205 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/metrics"
+	"laps/internal/ratelimit"
+	"laps/internal/service"
+)
+
+// fakeAuthService принимает токены вида token-<id>
+type fakeAuthService struct {
+	service.AuthService
+}
+
+func (s *fakeAuthService) ParseToken(ctx context.Context, token string) (int64, domain.UserRole, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(token, "token-"), 10, 64)
+	if err != nil || !strings.HasPrefix(token, "token-") {
+		return 0, "", errors.New("invalid token")
+	}
+	return id, domain.UserRoleClient, nil
+}
+
+type failingLimitStore struct{}
+
+func (failingLimitStore) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store is down")
+}
+
+const trustedProxy = "10.0.0.1"
+
+func newRateLimitTestRouter(t *testing.T, store ratelimit.Store) *gin.Engine {
+	t.Helper()
+	h := &Handler{
+		services: &service.Services{Auth: &fakeAuthService{}},
+		logger:   zap.NewNop(),
+		config: &config.Config{RateLimit: config.RateLimitConfig{
+			Enabled: true,
+			Read:    config.RateLimit{RPS: 1, Burst: 3},
+			Write:   config.RateLimit{RPS: 1, Burst: 1},
+		}},
+		rateLimiter: store,
+	}
+
+	router := gin.New()
+	if err := router.SetTrustedProxies([]string{trustedProxy}); err != nil {
+		t.Fatal(err)
+	}
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	specialists := router.Group("/specialists", h.rateLimitMiddleware("specialists"))
+	specialists.GET("", ok)
+	specialists.POST("", ok)
+	router.GET("/reviews", h.rateLimitMiddleware("reviews"), ok)
+	return router
+}
+
+type clientRequest struct {
+	method        string
+	path          string
+	remoteAddr    string
+	forwardedFor  string
+	authorization string
+}
+
+func (r clientRequest) send(router http.Handler) *httptest.ResponseRecorder {
+	method, path := r.method, r.path
+	if method == "" {
+		method = http.MethodGet
+	}
+	if path == "" {
+		path = "/specialists"
+	}
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = r.remoteAddr + ":40000"
+	if r.forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", r.forwardedFor)
+	}
+	if r.authorization != "" {
+		req.Header.Set("Authorization", r.authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func throttledCount(group, keyType string) float64 {
+	return testutil.ToFloat64(metrics.RateLimitThrottled.WithLabelValues(group, keyType))
+}
+
+// burst отправляет n одинаковых запросов и возвращает число пропущенных
+func burst(router http.Handler, r clientRequest, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		if r.send(router).Code == http.StatusOK {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestRateLimitBurst(t *testing.T) {
+	router := newRateLimitTestRouter(t, ratelimit.NewMemoryStore())
+	client := clientRequest{remoteAddr: "203.0.113.5"}
+	before := throttledCount("specialists", "ip")
+
+	if allowed := burst(router, client, 3); allowed != 3 {
+		t.Fatalf("allowed %d of the read burst, want 3", allowed)
+	}
+
+	w := client.send(router)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+	if after := throttledCount("specialists", "ip"); after != before+1 {
+		t.Errorf("throttled counter = %v, want %v", after, before+1)
+	}
+	exposed := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(exposed, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(exposed.Body.String(), `rate_limit_throttled_total{group="specialists",key_type="ip"}`) {
+		t.Errorf("/metrics does not expose the throttled counter:\n%s", exposed.Body.String())
+	}
+
+	// Лимиты записи и других групп маршрутов считаются отдельно
+	if w := (clientRequest{method: http.MethodPost, remoteAddr: client.remoteAddr}).send(router); w.Code != http.StatusOK {
+		t.Errorf("write after exhausted reads: status = %d", w.Code)
+	}
+	if w := (clientRequest{path: "/reviews", remoteAddr: client.remoteAddr}).send(router); w.Code != http.StatusOK {
+		t.Errorf("another route group: status = %d", w.Code)
+	}
+}
+
+func TestRateLimitKeysByUser(t *testing.T) {
+	router := newRateLimitTestRouter(t, ratelimit.NewMemoryStore())
+
+	// Два пользователя за одним NAT не делят лимит
+	first := clientRequest{remoteAddr: "203.0.113.5", authorization: "Bearer token-1"}
+	second := clientRequest{remoteAddr: "203.0.113.5", authorization: "Bearer token-2"}
+	before := throttledCount("specialists", "user")
+	if allowed := burst(router, first, 4); allowed != 3 {
+		t.Fatalf("first user: allowed %d, want 3", allowed)
+	}
+	if after := throttledCount("specialists", "user"); after != before+1 {
+		t.Errorf("throttled counter for users = %v, want %v", after, before+1)
+	}
+	if allowed := burst(router, second, 3); allowed != 3 {
+		t.Errorf("second user behind the same IP: allowed %d, want 3", allowed)
+	}
+
+	// Пользователь не получает новый лимит, сменив адрес
+	if w := (clientRequest{remoteAddr: "198.51.100.7", authorization: "Bearer token-1"}).send(router); w.Code != http.StatusTooManyRequests {
+		t.Errorf("first user from a new IP: status = %d, want 429", w.Code)
+	}
+
+	// С невалидным токеном клиент определяется по IP
+	invalid := clientRequest{remoteAddr: "198.51.100.8", authorization: "Bearer forged"}
+	if allowed := burst(router, invalid, 4); allowed != 3 {
+		t.Errorf("invalid token: allowed %d, want 3 by IP", allowed)
+	}
+}
+
+func TestRateLimitProxyHeaders(t *testing.T) {
+	router := newRateLimitTestRouter(t, ratelimit.NewMemoryStore())
+
+	// За доверенным прокси клиенты различаются по X-Forwarded-For
+	for _, client := range []string{"203.0.113.5", "203.0.113.6"} {
+		r := clientRequest{remoteAddr: trustedProxy, forwardedFor: client}
+		if allowed := burst(router, r, 3); allowed != 3 {
+			t.Errorf("client %s behind the proxy: allowed %d, want 3", client, allowed)
+		}
+	}
+
+	// Недоверенный клиент не может подменить адрес заголовком
+	attacker := "198.51.100.9"
+	for i := 0; i < 3; i++ {
+		r := clientRequest{remoteAddr: attacker, forwardedFor: "192.0.2." + strconv.Itoa(i)}
+		if w := r.send(router); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d", i+1, w.Code)
+		}
+	}
+	if w := (clientRequest{remoteAddr: attacker, forwardedFor: "192.0.2.100"}).send(router); w.Code != http.StatusTooManyRequests {
+		t.Errorf("spoofed X-Forwarded-For bypassed the limit: status = %d", w.Code)
+	}
+}
+
+func TestRateLimitStoreFailureAllowsRequests(t *testing.T) {
+	router := newRateLimitTestRouter(t, failingLimitStore{})
+	if allowed := burst(router, clientRequest{remoteAddr: "203.0.113.5"}, 10); allowed != 10 {
+		t.Errorf("allowed %d of 10 with the store down, want all", allowed)
+	}
+}
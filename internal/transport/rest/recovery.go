@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/metrics"
+)
+
+// recoveryMiddleware перехватывает панику обработчика, пишет стек в лог и отвечает 500
+// в общем формате ошибок вместо обрыва соединения
+func (h *Handler) recoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			metrics.PanicsRecovered.WithLabelValues(metrics.PanicSourceHTTP).Inc()
+
+			// Обрыв соединения клиентом: ответ отправить уже некуда
+			if isBrokenPipe(recovered) {
+				h.logger.Warn("соединение закрыто клиентом во время ответа",
+					zap.String("path", c.Request.URL.Path),
+					zap.Any("error", recovered))
+				c.Abort()
+				return
+			}
+
+			h.logger.Error("паника при обработке запроса",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Any("panic", recovered),
+				zap.ByteString("stack", debug.Stack()))
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			internalServerErrorResponse(c)
+		}()
+
+		c.Next()
+	}
+}
+
+func isBrokenPipe(recovered interface{}) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(err, http.ErrAbortHandler) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if !errors.As(opErr, &syscallErr) {
+		return false
+	}
+
+	message := strings.ToLower(syscallErr.Error())
+	return strings.Contains(message, "broken pipe") || strings.Contains(message, "connection reset by peer")
+}
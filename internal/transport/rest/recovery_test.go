@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+
+	"laps/internal/metrics"
+)
+
+func TestRecoveryCountsPanics(t *testing.T) {
+	h := &Handler{logger: zap.NewNop()}
+	router := gin.New()
+	router.Use(h.recoveryMiddleware())
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	counter := metrics.PanicsRecovered.WithLabelValues(metrics.PanicSourceHTTP)
+	before := testutil.ToFloat64(counter)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if after := testutil.ToFloat64(counter); after != before+1 {
+		t.Errorf("panics_recovered_total{source=\"http\"} = %v, want %v", after, before+1)
+	}
+}
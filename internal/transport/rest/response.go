@@ -7,9 +7,12 @@ import (
 )
 
 type errorResponseBody struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
-	Code    int    `json:"code,omitempty"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	Code      int    `json:"code,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+	// Current актуальное состояние сущности при конфликте версий, чтобы клиент мог объединить изменения
+	Current interface{} `json:"current,omitempty"`
 }
 
 type successResponseBody struct {
@@ -31,48 +34,148 @@ type paginatedResponse struct {
 	TotalPages int         `json:"total_pages"`
 }
 
+// Тела ответов строятся с учетом версии API: v1 сохраняет исторический формат,
+// v2 всегда отдает {data, meta} или {error}
+
 func successResponse(c *gin.Context, statusCode int, data interface{}) {
-	c.JSON(statusCode, successResponseBody{
-		Status: "success",
-		Data:   data,
-	})
+	c.JSON(statusCode, newSuccessBody(c, data))
 }
 
 func errorResponse(c *gin.Context, statusCode int, message string) {
+	codedErrorResponse(c, statusCode, "", message)
+}
+
+// codedErrorResponse добавляет к ошибке машиночитаемый код (error_code), по которому клиент
+// может отличить конкретную причину отказа
+func codedErrorResponse(c *gin.Context, statusCode int, errorCode, message string) {
+	if getAPIVersion(c) == apiV2 {
+		c.AbortWithStatusJSON(statusCode, v2ErrorBody{
+			Error: v2Error{Code: statusCode, ErrorCode: errorCode, Message: message},
+		})
+		return
+	}
+
 	c.AbortWithStatusJSON(statusCode, errorResponseBody{
-		Status:  "error",
-		Message: message,
-		Code:    statusCode,
+		Status:    "error",
+		Message:   message,
+		Code:      statusCode,
+		ErrorCode: errorCode,
+	})
+}
+
+// versionConflictResponse отвечает 409 с error_code=version_conflict и актуальным состоянием сущности
+// в поле current. Если актуальное состояние получить не удалось, current не передается
+func versionConflictResponse(c *gin.Context, message string, current interface{}) {
+	if getAPIVersion(c) == apiV2 {
+		c.AbortWithStatusJSON(http.StatusConflict, v2ErrorBody{
+			Error: v2Error{Code: http.StatusConflict, ErrorCode: "version_conflict", Message: message, Current: current},
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusConflict, errorResponseBody{
+		Status:    "error",
+		Message:   message,
+		Code:      http.StatusConflict,
+		ErrorCode: "version_conflict",
+		Current:   current,
 	})
 }
 
 func messageResponse(c *gin.Context, statusCode int, message string) {
+	if getAPIVersion(c) == apiV2 {
+		c.JSON(statusCode, v2Envelope{
+			Data: gin.H{"message": message},
+			Meta: gin.H{},
+		})
+		return
+	}
+
 	c.JSON(statusCode, messageResponseType{
 		Status:  "success",
 		Message: message,
 	})
 }
 
+// rawResponse отдает произвольное тело как есть в v1 и в конверте в v2
+func rawResponse(c *gin.Context, statusCode int, body interface{}) {
+	if getAPIVersion(c) == apiV2 {
+		c.JSON(statusCode, v2Envelope{Data: body, Meta: gin.H{}})
+		return
+	}
+
+	c.JSON(statusCode, body)
+}
+
+// Размер страницы списков по умолчанию и максимальный размер страницы
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// normalizePaging приводит limit и offset из запроса к допустимым значениям: неположительный limit
+// заменяется размером по умолчанию, слишком большой ограничивается maxPageLimit, отрицательный offset
+// становится нулем. После нормализации limit всегда положителен, и на него можно делить
+func normalizePaging(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
 func paginatedSuccessResponse(c *gin.Context, data interface{}, totalCount, page, pageSize int) {
-	totalPages := totalCount / pageSize
-	if totalCount%pageSize > 0 {
-		totalPages++
+	c.JSON(http.StatusOK, newPaginatedBody(c, data, totalCount, page, pageSize))
+}
+
+func newSuccessBody(c *gin.Context, data interface{}) interface{} {
+	if getAPIVersion(c) == apiV2 {
+		return v2Envelope{Data: data, Meta: gin.H{}}
 	}
 
-	c.JSON(http.StatusOK, paginatedResponse{
+	return successResponseBody{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func newPaginatedBody(c *gin.Context, data interface{}, totalCount, page, pageSize int) interface{} {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = totalCount / pageSize
+		if totalCount%pageSize > 0 {
+			totalPages++
+		}
+	}
+
+	if getAPIVersion(c) == apiV2 {
+		return v2Envelope{
+			Data: data,
+			Meta: paginationMeta{
+				TotalCount: totalCount,
+				Page:       page,
+				PageSize:   pageSize,
+				TotalPages: totalPages,
+			},
+		}
+	}
+
+	return paginatedResponse{
 		Data:       data,
 		TotalCount: totalCount,
 		Page:       page,
 		PageSize:   pageSize,
 		TotalPages: totalPages,
-	})
+	}
 }
 
 func createdResponse(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusCreated, successResponseBody{
-		Status: "success",
-		Data:   data,
-	})
+	c.JSON(http.StatusCreated, newSuccessBody(c, data))
 }
 
 func noContentResponse(c *gin.Context) {
@@ -83,6 +186,19 @@ func badRequestResponse(c *gin.Context, message string) {
 	errorResponse(c, http.StatusBadRequest, message)
 }
 
+// clientBlockedResponse не раскрывает клиенту, что и почему его заблокировали
+func clientBlockedResponse(c *gin.Context) {
+	codedErrorResponse(c, http.StatusBadRequest, "client_blocked", "запись к специалисту недоступна")
+}
+
+func notAcceptingClientsResponse(c *gin.Context) {
+	codedErrorResponse(c, http.StatusConflict, "not_accepting_new_clients", "специалист не принимает новых клиентов")
+}
+
+func specialistUnavailableResponse(c *gin.Context) {
+	codedErrorResponse(c, http.StatusGone, "specialist_unavailable", "профиль специалиста удален")
+}
+
 func unauthorizedResponse(c *gin.Context) {
 	errorResponse(c, http.StatusUnauthorized, "требуется авторизация")
 }
@@ -0,0 +1,29 @@
+package rest
+
+import "testing"
+
+func TestNormalizePaging(t *testing.T) {
+	tests := []struct {
+		name                  string
+		limit, offset         int
+		wantLimit, wantOffset int
+	}{
+		{name: "passed as is", limit: 50, offset: 40, wantLimit: 50, wantOffset: 40},
+		// limit=0 означает, что клиент не указал размер страницы, а не пустую страницу
+		{name: "zero limit", limit: 0, offset: 0, wantLimit: defaultPageLimit, wantOffset: 0},
+		{name: "negative limit", limit: -5, offset: 10, wantLimit: defaultPageLimit, wantOffset: 10},
+		{name: "limit above maximum", limit: 10000, offset: 0, wantLimit: maxPageLimit, wantOffset: 0},
+		{name: "maximum limit", limit: maxPageLimit, offset: 0, wantLimit: maxPageLimit, wantOffset: 0},
+		{name: "negative offset", limit: 10, offset: -1, wantLimit: 10, wantOffset: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, offset := normalizePaging(tt.limit, tt.offset)
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("normalizePaging(%d, %d) = %d, %d; want %d, %d",
+					tt.limit, tt.offset, limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,6 +9,7 @@ import (
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
+	"laps/internal/service"
 )
 
 // @Summary Получить отзыв по ID
@@ -48,7 +50,7 @@ func (h *Handler) getReviewByID(c *gin.Context) {
 // @Success 201 {object} map[string]interface{} "ID созданного отзыва"
 // @Failure 400 {object} errorResponseBody "Ошибка валидации"
 // @Failure 401 {object} errorResponseBody "Не авторизован"
-// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен, в том числе отзыв специалиста о самом себе"
 // @Failure 404 {object} errorResponseBody "Специалист не найден"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
@@ -94,6 +96,14 @@ func (h *Handler) createReview(c *gin.Context) {
 
 	id, err := h.services.Review.Create(c.Request.Context(), userID, req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			forbiddenResponse(c, err.Error())
+			return
+		}
 		h.logger.Error("ошибка при создании отзыва", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -104,6 +114,206 @@ func (h *Handler) createReview(c *gin.Context) {
 	})
 }
 
+// @Summary Загрузить изображение для отзыва
+// @Description Загружает изображение, которое затем можно приложить к отзыву через поле media_urls
+// @Tags Отзывы
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Изображение"
+// @Success 201 {object} domain.ReviewMedia "Загруженное изображение"
+// @Failure 400 {object} errorResponseBody "Файл не является изображением или слишком большой"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /reviews/media [post]
+func (h *Handler) uploadReviewMedia(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	userRole, err := getUserRole(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	if userRole != domain.UserRoleClient && userRole != domain.UserRoleAdmin {
+		forbiddenResponse(c)
+		return
+	}
+
+	fileData, filename, ok := h.readImageUpload(c, "file", h.config.ReviewMedia.MaxFileSize)
+	if !ok {
+		return
+	}
+
+	media, err := h.services.Review.UploadMedia(c.Request.Context(), userID, fileData, filename)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		h.logger.Error("ошибка загрузки изображения отзыва", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	createdResponse(c, media)
+}
+
+// @Summary Добавить фотографию к отзыву
+// @Description Загружает изображение и прикладывает его к опубликованному отзыву (только автор отзыва или администратор)
+// @Tags Отзывы
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Param photo formData file true "Изображение"
+// @Success 201 {object} domain.ReviewMedia "Добавленное изображение"
+// @Failure 400 {object} errorResponseBody "Файл не является изображением, слишком большой или превышено число изображений"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Отзыв не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /reviews/{id}/photos [post]
+func (h *Handler) addReviewPhoto(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	review, err := h.services.Review.GetByID(c.Request.Context(), id)
+	if err != nil {
+		notFoundResponse(c, "отзыв не найден")
+		return
+	}
+
+	userRole, _ := getUserRole(c)
+	if review.ClientID != userID && userRole != domain.UserRoleAdmin {
+		forbiddenResponse(c)
+		return
+	}
+
+	fileData, filename, ok := h.readImageUpload(c, "photo", h.config.ReviewMedia.MaxFileSize)
+	if !ok {
+		return
+	}
+
+	media, err := h.services.Review.AddMedia(c.Request.Context(), id, userID, fileData, filename)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalid):
+			badRequestResponse(c, err.Error())
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(c, "отзыв не найден")
+		default:
+			h.logger.Error("ошибка добавления фотографии к отзыву", zap.Int64("reviewID", id), zap.Error(err))
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	createdResponse(c, media)
+}
+
+// @Summary Обновить отзыв
+// @Description Изменяет оценку и текст отзыва (только автор или администратор). Чтобы не затереть чужие изменения,
+// @Description передайте updated_at отзыва в expected_updated_at или заголовок If-Unmodified-Since: если отзыв
+// @Description уже изменили, возвращается 409 с error_code=version_conflict и актуальным отзывом в current
+// @Tags Отзывы
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отзыва"
+// @Param If-Unmodified-Since header string false "Дата последнего известного изменения отзыва (HTTP-дата), если expected_updated_at не передан"
+// @Param input body domain.UpdateReviewDTO true "Новые оценка и текст отзыва"
+// @Success 200 {object} domain.Review "Обновленный отзыв"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Отзыв не найден"
+// @Failure 409 {object} errorResponseBody "Отзыв изменен другим запросом (error_code=version_conflict)"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /reviews/{id} [put]
+func (h *Handler) updateReview(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Warn("ошибка получения ID пользователя", zap.Error(err))
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("неверный формат ID", zap.Error(err))
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	review, err := h.services.Review.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("ошибка получения отзыва", zap.Error(err), zap.Int64("id", id))
+		notFoundResponse(c, "отзыв не найден")
+		return
+	}
+
+	userRole, _ := getUserRole(c)
+	if review.ClientID != userID && userRole != domain.UserRoleAdmin {
+		h.logger.Warn("попытка несанкционированного доступа", zap.Int64("userID", userID))
+		forbiddenResponse(c)
+		return
+	}
+
+	var req domain.UpdateReviewDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	expected, ok := expectedUpdatedAt(c, req.ExpectedUpdatedAt, review.UpdatedAt)
+	if !ok {
+		return
+	}
+	req.ExpectedUpdatedAt = expected
+
+	err = h.services.Review.Update(c.Request.Context(), id, req)
+	if errors.Is(err, service.ErrVersionConflict) {
+		var current interface{}
+		if actual, getErr := h.services.Review.GetByID(c.Request.Context(), id); getErr == nil {
+			current = actual
+		} else {
+			h.logger.Error("ошибка получения актуального отзыва после конфликта версий", zap.Int64("id", id), zap.Error(getErr))
+		}
+		versionConflictResponse(c, err.Error(), current)
+		return
+	}
+	if err != nil {
+		h.logger.Error("ошибка обновления отзыва", zap.Int64("id", id), zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	updated, err := h.services.Review.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("ошибка получения обновленного отзыва", zap.Int64("id", id), zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, updated)
+}
+
 // @Summary Удалить отзыв
 // @Description Удаляет отзыв (только автор или администратор)
 // @Tags Отзывы
@@ -253,11 +463,14 @@ func (h *Handler) deleteReviewReply(c *gin.Context) {
 }
 
 // @Summary Получить список отзывов
-// @Description Возвращает список отзывов с возможностью фильтрации и пагинацией
+// @Description Возвращает список отзывов с возможностью фильтрации и пагинацией.
+// @Description Без specialist_id список доступен только администратору; scope=public_recent возвращает
+// @Description публичную ленту последних положительных отзывов с сокращенным текстом и именем автора
 // @Tags Отзывы
 // @Accept json
 // @Produce json
-// @Param specialist_id query int true "ID специалиста"
+// @Param scope query string false "Режим выборки" Enums(public_recent)
+// @Param specialist_id query int false "ID специалиста (обязателен, кроме администратора и scope=public_recent)"
 // @Param client_id query int false "ID клиента"
 // @Param min_rating query int false "Минимальный рейтинг"
 // @Param max_rating query int false "Максимальный рейтинг"
@@ -265,29 +478,40 @@ func (h *Handler) deleteReviewReply(c *gin.Context) {
 // @Param offset query int false "Смещение (по умолчанию 0)"
 // @Success 200 {object} paginatedResponse "Список отзывов с пагинацией"
 // @Failure 400 {object} errorResponseBody "Ошибка валидации параметров"
+// @Failure 401 {object} errorResponseBody "Некорректный токен"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Router /reviews [get]
 func (h *Handler) getReviews(c *gin.Context) {
+	switch c.Query("scope") {
+	case "":
+	case domain.ReviewScopePublicRecent:
+		h.getPublicRecentReviews(c)
+		return
+	default:
+		badRequestResponse(c, "неизвестное значение параметра scope")
+		return
+	}
+
 	filter := domain.ReviewFilter{
 		Limit:  10,
 		Offset: 0,
 	}
 
-	specialistIDStr := c.Query("specialist_id")
-	if specialistIDStr == "" {
+	// Без specialist_id можно перебирать отзывы по client_id, поэтому такой запрос доступен только администратору
+	if specialistIDStr := c.Query("specialist_id"); specialistIDStr != "" {
+		specialistID, err := strconv.ParseInt(specialistIDStr, 10, 64)
+		if err != nil {
+			h.logger.Warn("неверный формат ID специалиста", zap.Error(err))
+			badRequestResponse(c, "неверный формат ID специалиста")
+			return
+		}
+		filter.SpecialistID = &specialistID
+	} else if userRole, err := getUserRole(c); err != nil || userRole != domain.UserRoleAdmin {
 		h.logger.Warn("отсутствует обязательный параметр specialist_id")
 		badRequestResponse(c, "отсутствует обязательный параметр specialist_id")
 		return
 	}
 
-	specialistID, err := strconv.ParseInt(specialistIDStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("неверный формат ID специалиста", zap.Error(err))
-		badRequestResponse(c, "неверный формат ID специалиста")
-		return
-	}
-	filter.SpecialistID = &specialistID
-
 	if clientIDStr := c.Query("client_id"); clientIDStr != "" {
 		clientID, err := strconv.ParseInt(clientIDStr, 10, 64)
 		if err == nil {
@@ -323,6 +547,8 @@ func (h *Handler) getReviews(c *gin.Context) {
 		}
 	}
 
+	filter.Limit, filter.Offset = normalizePaging(filter.Limit, filter.Offset)
+
 	reviews, total, err := h.services.Review.List(c.Request.Context(), filter)
 	if err != nil {
 		h.logger.Error("ошибка при получении отзывов", zap.Error(err))
@@ -334,6 +560,61 @@ func (h *Handler) getReviews(c *gin.Context) {
 	paginatedSuccessResponse(c, reviews, total, page, filter.Limit)
 }
 
+// getPublicRecentReviews отдает ленту последних положительных отзывов для главной страницы
+func (h *Handler) getPublicRecentReviews(c *gin.Context) {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			badRequestResponse(c, "неверный формат параметра limit")
+			return
+		}
+		limit = parsed
+	}
+
+	reviews, err := h.services.Review.ListPublicRecent(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("ошибка при получении публичной ленты отзывов", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка при получении отзывов")
+		return
+	}
+
+	successResponse(c, http.StatusOK, reviews)
+}
+
+// @Summary Получить сводку рейтинга специалиста
+// @Description Возвращает средние оценки, распределение рейтинга и процент рекомендаций по отзывам о специалисте
+// @Tags Отзывы
+// @Accept json
+// @Produce json
+// @Param specialist_id query int true "ID специалиста"
+// @Success 200 {object} domain.RatingSummary "Сводка рейтинга"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID специалиста"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /reviews/summary [get]
+func (h *Handler) getReviewRatingSummary(c *gin.Context) {
+	specialistID, err := strconv.ParseInt(c.Query("specialist_id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("неверный формат ID специалиста", zap.Error(err))
+		badRequestResponse(c, "неверный формат ID специалиста")
+		return
+	}
+
+	summary, err := h.services.Review.GetRatingSummary(c.Request.Context(), specialistID)
+	if err != nil {
+		if err.Error() == "специалист не найден" {
+			notFoundResponse(c, err.Error())
+			return
+		}
+		h.logger.Error("ошибка при получении сводки рейтинга", zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, summary)
+}
+
 // @Summary Получить ответы на отзыв
 // @Description Возвращает список ответов на конкретный отзыв
 // @Tags Отзывы
@@ -0,0 +1,197 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// fakeReviewService запоминает фильтр списка, чтобы тест видел, дошел ли запрос до сервиса,
+// и хранит один отзыв, обновление которого отклоняется при несовпадении updated_at, как в хранилище
+type fakeReviewService struct {
+	service.ReviewService
+
+	listed       *domain.ReviewFilter
+	publicLimit  int
+	publicCalled bool
+	review       domain.Review
+}
+
+func (s *fakeReviewService) GetByID(ctx context.Context, id int64) (*domain.Review, error) {
+	if id != s.review.ID {
+		return nil, service.ErrNotFound
+	}
+	review := s.review
+	return &review, nil
+}
+
+func (s *fakeReviewService) Update(ctx context.Context, id int64, dto domain.UpdateReviewDTO) error {
+	if dto.ExpectedUpdatedAt != nil && !s.review.UpdatedAt.Equal(*dto.ExpectedUpdatedAt) {
+		return fmt.Errorf("%w: отзыв был изменен", service.ErrVersionConflict)
+	}
+	if dto.Rating != nil {
+		s.review.Rating = *dto.Rating
+	}
+	if dto.Text != nil {
+		s.review.Text = *dto.Text
+	}
+	s.review.UpdatedAt = s.review.UpdatedAt.Add(time.Minute)
+	return nil
+}
+
+func (s *fakeReviewService) List(ctx context.Context, filter domain.ReviewFilter) ([]domain.Review, int, error) {
+	s.listed = &filter
+	return []domain.Review{}, 0, nil
+}
+
+func (s *fakeReviewService) ListPublicRecent(ctx context.Context, limit int) ([]domain.PublicReview, error) {
+	s.publicCalled, s.publicLimit = true, limit
+	return []domain.PublicReview{}, nil
+}
+
+func newReviewTestRouter(reviews service.ReviewService, role domain.UserRole) *gin.Engine {
+	h := &Handler{services: &service.Services{Review: reviews}, logger: zap.NewNop()}
+
+	router := gin.New()
+	router.GET("/api/v1/reviews", h.apiVersionMiddleware(apiV1), func(c *gin.Context) {
+		if role != "" {
+			c.Set(userIDCtx, int64(1))
+			c.Set(userRoleCtx, role)
+		}
+	}, h.getReviews)
+	return router
+}
+
+func TestGetReviewsEnumerationGuard(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       domain.UserRole
+		query      string
+		wantStatus int
+	}{
+		{"anonymous by specialist", "", "specialist_id=7", http.StatusOK},
+		{"client by specialist and client", domain.UserRoleClient, "specialist_id=7&client_id=3", http.StatusOK},
+		{"anonymous without specialist", "", "", http.StatusBadRequest},
+		{"client enumerates by client_id", domain.UserRoleClient, "client_id=3", http.StatusBadRequest},
+		{"specialist enumerates by client_id", domain.UserRoleSpecialist, "client_id=3", http.StatusBadRequest},
+		{"admin by client_id", domain.UserRoleAdmin, "client_id=3", http.StatusOK},
+		{"anonymous public feed", "", "scope=public_recent&limit=5", http.StatusOK},
+		{"unknown scope", "", "scope=all", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reviews := &fakeReviewService{}
+			w := httptest.NewRecorder()
+			newReviewTestRouter(reviews, tt.role).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/reviews?"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK && (reviews.listed != nil || reviews.publicCalled) {
+				t.Error("rejected request reached the service")
+			}
+		})
+	}
+}
+
+func TestGetReviewsPublicFeedIgnoresFilters(t *testing.T) {
+	reviews := &fakeReviewService{}
+	w := httptest.NewRecorder()
+	newReviewTestRouter(reviews, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/reviews?scope=public_recent&client_id=3&limit=5", nil))
+
+	if w.Code != http.StatusOK || !reviews.publicCalled || reviews.publicLimit != 5 {
+		t.Fatalf("status = %d, public feed called = %v with limit %d", w.Code, reviews.publicCalled, reviews.publicLimit)
+	}
+	if reviews.listed != nil {
+		t.Error("public feed request was filtered by client_id")
+	}
+}
+
+func TestGetReviewsPaging(t *testing.T) {
+	tests := []struct {
+		query                 string
+		wantLimit, wantOffset int
+	}{
+		{"specialist_id=7", 10, 0},
+		{"specialist_id=7&limit=0", 10, 0},
+		{"specialist_id=7&limit=500&offset=20", maxPageLimit, 20},
+		{"specialist_id=7&limit=abc&offset=-3", 10, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			reviews := &fakeReviewService{}
+			w := httptest.NewRecorder()
+			newReviewTestRouter(reviews, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/reviews?"+tt.query, nil))
+
+			if w.Code != http.StatusOK || reviews.listed == nil {
+				t.Fatalf("status = %d; body = %s", w.Code, w.Body)
+			}
+			if reviews.listed.Limit != tt.wantLimit || reviews.listed.Offset != tt.wantOffset {
+				t.Errorf("limit, offset = %d, %d; want %d, %d", reviews.listed.Limit, reviews.listed.Offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+// Автор правит отзыв в двух вкладках: сохранение из второй вкладки после первого получает 409
+// с актуальным отзывом вместо того, чтобы молча вернуть прежнюю оценку
+func TestUpdateReviewLostUpdate(t *testing.T) {
+	readAt := time.Date(2026, 3, 2, 9, 15, 30, 250000000, time.UTC)
+	reviews := &fakeReviewService{review: domain.Review{ID: 4, ClientID: 1, SpecialistID: 7, Rating: 3, UpdatedAt: readAt}}
+	h := &Handler{services: &service.Services{Review: reviews}, logger: zap.NewNop()}
+	router := gin.New()
+	router.PUT("/api/v1/reviews/:id", h.apiVersionMiddleware(apiV1), func(c *gin.Context) {
+		c.Set(userIDCtx, int64(1))
+		c.Set(userRoleCtx, domain.UserRoleClient)
+	}, h.updateReview)
+
+	first := putJSON(router, "/api/v1/reviews/4", `{"rating":5,"expected_updated_at":"2026-03-02T09:15:30.25Z"}`, nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("first save: status = %d, body = %s", first.Code, first.Body)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		headers map[string]string
+	}{
+		{name: "stale expected_updated_at", body: `{"rating":2,"expected_updated_at":"2026-03-02T09:15:30.25Z"}`},
+		{name: "stale If-Unmodified-Since", body: `{"rating":2}`,
+			headers: map[string]string{"If-Unmodified-Since": readAt.Format(http.TimeFormat)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := putJSON(router, "/api/v1/reviews/4", tt.body, tt.headers)
+			if w.Code != http.StatusConflict {
+				t.Fatalf("status = %d, want 409; body = %s", w.Code, w.Body)
+			}
+
+			var body struct {
+				ErrorCode string        `json:"error_code"`
+				Current   domain.Review `json:"current"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.ErrorCode != "version_conflict" || body.Current.Rating != 5 {
+				t.Errorf("response = %s, want version_conflict with the rating of 5", w.Body)
+			}
+		})
+	}
+
+	if reviews.review.Rating != 5 {
+		t.Errorf("rating = %d, overwritten by a stale request", reviews.review.Rating)
+	}
+}
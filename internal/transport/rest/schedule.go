@@ -1,6 +1,8 @@
 package rest
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,10 +11,13 @@ import (
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
+	"laps/internal/service"
 )
 
 // @Summary Создать расписание
-// @Description Создает новое расписание для специалиста
+// @Description Создает новое расписание для специалиста.
+// @Description slot_alignment выравнивает начало слотов по сетке 5, 10, 15, 20, 30 или 60 минут от начала часа, slot_offset сдвигает сетку:
+// @Description при начале 09:07, слотах по 30 минут и slot_alignment=30 слоты начнутся в 09:30, 10:00 и т.д.
 // @Tags Расписание
 // @Accept json
 // @Produce json
@@ -80,6 +85,10 @@ func (h *Handler) createSchedule(c *gin.Context) {
 	}
 
 	scheduleID, err := h.services.Schedule.Create(c.Request.Context(), specialist.ID, req)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка создания расписания", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, "ошибка создания расписания")
@@ -127,7 +136,7 @@ func (h *Handler) getScheduleByID(c *gin.Context) {
 		startDate = date.AddDate(0, 0, -int(weekday)+1)
 	}
 
-	weekSchedule, slotTime, err := h.services.Schedule.GetWeekSchedule(c.Request.Context(), schedule.SpecialistID, startDate)
+	weekSchedule, slotTime, version, err := h.services.Schedule.GetWeekSchedule(c.Request.Context(), schedule.SpecialistID, startDate)
 	if err != nil {
 		h.logger.Error("ошибка получения недельного расписания", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, "ошибка получения недельного расписания")
@@ -137,6 +146,7 @@ func (h *Handler) getScheduleByID(c *gin.Context) {
 	successResponse(c, http.StatusOK, gin.H{
 		"week_schedule": weekSchedule,
 		"slot_time":     slotTime,
+		"version":       version,
 		"week_start":    startDate.Format("2006-01-02"),
 		"specialist_id": schedule.SpecialistID,
 		"schedule_id":   schedule.ID,
@@ -144,15 +154,18 @@ func (h *Handler) getScheduleByID(c *gin.Context) {
 }
 
 // @Summary Обновить расписание
-// @Description Обновляет расписание специалиста на неделю
+// @Description Обновляет расписание специалиста на текущую неделю. Выравнивание слотов задается так же, как при создании.
+// @Description Требуется версия недели из GET /schedules/week: поле expected_version или заголовок If-Match с номером версии.
 // @Tags Расписание
 // @Accept json
 // @Produce json
+// @Param If-Match header string false "Версия недели, если expected_version не передана в теле"
 // @Param input body domain.UpdateScheduleDTO true "Данные для обновления расписания"
 // @Success 200 {object} messageResponseType "Сообщение об успешном обновлении"
 // @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 409 {object} errorResponseBody "Расписание изменено другим пользователем (error_code=version_conflict)"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
 // @Router /schedules [put]
@@ -211,7 +224,21 @@ func (h *Handler) updateSchedule(c *gin.Context) {
 		return
 	}
 
+	version, ok := expectedVersion(c, req.ExpectedVersion)
+	if !ok {
+		return
+	}
+	req.ExpectedVersion = version
+
 	err = h.services.Schedule.Update(c.Request.Context(), specialist.ID, req)
+	if errors.Is(err, service.ErrVersionConflict) {
+		codedErrorResponse(c, http.StatusConflict, "version_conflict", err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка обновления расписания", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, "ошибка обновления расписания")
@@ -327,7 +354,7 @@ func (h *Handler) getSchedules(c *gin.Context) {
 	}
 
 	if specialistID != nil && startDate != nil {
-		weekSchedule, slotTime, err := h.services.Schedule.GetWeekSchedule(c.Request.Context(), *specialistID, *startDate)
+		weekSchedule, slotTime, version, err := h.services.Schedule.GetWeekSchedule(c.Request.Context(), *specialistID, *startDate)
 		if err != nil {
 			h.logger.Error("ошибка получения недельного расписания", zap.Error(err))
 			errorResponse(c, http.StatusInternalServerError, "ошибка получения недельного расписания")
@@ -337,6 +364,7 @@ func (h *Handler) getSchedules(c *gin.Context) {
 		successResponse(c, http.StatusOK, gin.H{
 			"week_schedule": weekSchedule,
 			"slot_time":     slotTime,
+			"version":       version,
 			"week_start":    startDate.Format("2006-01-02"),
 			"specialist_id": *specialistID,
 		})
@@ -358,15 +386,9 @@ func (h *Handler) getSchedules(c *gin.Context) {
 		}
 	}
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	if err != nil || limit < 0 {
-		limit = 20
-	}
-
-	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
-	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
 
 	filter := domain.ScheduleFilter{
 		SpecialistID: specialistID,
@@ -433,13 +455,50 @@ func (h *Handler) getFreeSlots(c *gin.Context) {
 	})
 }
 
+// @Summary Получить свободные слоты нескольких специалистов
+// @Description Возвращает свободные слоты каждого из указанных специалистов на выбранную дату одним запросом.
+// @Description В запросе не больше 50 специалистов; специалист без расписания получает пустой список
+// @Tags Расписание
+// @Accept json
+// @Produce json
+// @Param input body domain.FreeSlotsBatchDTO true "ID специалистов и дата (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Свободные слоты по ID специалиста"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /schedules/free-slots/batch [post]
+func (h *Handler) getFreeSlotsBatch(c *gin.Context) {
+	var req domain.FreeSlotsBatchDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, fmt.Sprintf("необходимо указать дату и от 1 до %d ID специалистов", domain.MaxFreeSlotsBatchSize))
+		return
+	}
+
+	slots, err := h.services.Appointment.GetFreeSlotsBatch(c.Request.Context(), req.SpecialistIDs, req.Date)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, gin.H{
+		"date":       req.Date,
+		"free_slots": slots,
+	})
+}
+
 // @Summary Получить недельное расписание специалиста
 // @Description Возвращает расписание специалиста на неделю в структурированном виде
 // @Tags Расписание
 // @Produce json
 // @Param specialist_id query int true "ID специалиста"
 // @Param week_start query string false "Начало недели (YYYY-MM-DD), если не указано - текущая неделя"
+// @Param If-None-Match header string false "ETag из предыдущего ответа"
 // @Success 200 {object} map[string]interface{} "Недельное расписание"
+// @Success 304 "Данные не изменились"
 // @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Router /schedules/week [get]
@@ -480,16 +539,102 @@ func (h *Handler) getScheduleWeek(c *gin.Context) {
 		}
 	}
 
-	weekSchedule, slotTime, err := h.services.Schedule.GetWeekSchedule(c.Request.Context(), specialistID, startDate)
+	weekSchedule, slotTime, version, err := h.services.Schedule.GetWeekSchedule(c.Request.Context(), specialistID, startDate)
 	if err != nil {
 		h.logger.Error("ошибка получения недельного расписания", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, "ошибка получения недельного расписания")
 		return
 	}
 
-	successResponse(c, http.StatusOK, gin.H{
+	successResponseWithETag(c, gin.H{
 		"week_schedule": weekSchedule,
 		"slot_time":     slotTime,
+		"version":       version,
 		"week_start":    startDate.Format("2006-01-02"),
-	})
+	}, h.config.HTTP.CacheMaxAge.ScheduleWeek)
+}
+
+// @Summary Задать расписание на конкретную дату
+// @Description Создает или заменяет исключение из недельного расписания на указанную дату (другие часы работы или выходной)
+// @Tags Расписание
+// @Accept json
+// @Produce json
+// @Param date path string true "Дата (YYYY-MM-DD)"
+// @Param input body domain.SetScheduleOverrideDTO true "Часы работы на дату"
+// @Success 200 {object} domain.ScheduleOverride "Сохраненное исключение"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /schedules/overrides/{date} [put]
+func (h *Handler) setScheduleOverride(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("ошибка при получении данных специалиста", zap.Error(err))
+		notFoundResponse(c, "профиль специалиста не найден")
+		return
+	}
+
+	var req domain.SetScheduleOverrideDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	override, err := h.services.Schedule.SetOverride(c.Request.Context(), specialist.ID, c.Param("date"), req)
+	if err != nil {
+		h.logger.Warn("ошибка сохранения исключения расписания", zap.Error(err))
+		badRequestResponse(c, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, override)
+}
+
+// @Summary Удалить расписание на конкретную дату
+// @Description Удаляет исключение, после чего для даты снова действует недельное расписание
+// @Tags Расписание
+// @Produce json
+// @Param date path string true "Дата (YYYY-MM-DD)"
+// @Success 204 "Исключение удалено"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /schedules/overrides/{date} [delete]
+func (h *Handler) deleteScheduleOverride(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	specialist, err := h.services.Specialist.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("ошибка при получении данных специалиста", zap.Error(err))
+		notFoundResponse(c, "профиль специалиста не найден")
+		return
+	}
+
+	if _, err := time.Parse("2006-01-02", c.Param("date")); err != nil {
+		badRequestResponse(c, "неверный формат даты, ожидается YYYY-MM-DD")
+		return
+	}
+
+	if err := h.services.Schedule.DeleteOverride(c.Request.Context(), specialist.ID, c.Param("date")); err != nil {
+		h.logger.Error("ошибка удаления исключения расписания", zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	noContentResponse(c)
 }
@@ -0,0 +1,178 @@
+package rest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// seoMaxAge сколько sitemap и разметку schema.org могут хранить поисковики и промежуточные кэши
+const seoMaxAge = time.Hour
+
+const sitemapXMLNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc string `xml:"loc"`
+}
+
+// specialistProfileURL адрес страницы профиля специалиста на фронтенде; если APP_URL не задан, ссылка относительная
+func (h *Handler) specialistProfileURL(specialistID int64) string {
+	return fmt.Sprintf("%s/specialists/%d", strings.TrimRight(h.config.Appointment.AppURL, "/"), specialistID)
+}
+
+// setSEOCacheHeaders разрешает хранить ответ в общих кэшах: он одинаков для всех пользователей
+func setSEOCacheHeaders(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(seoMaxAge.Seconds())))
+}
+
+// @Summary Карта сайта
+// @Description Возвращает sitemap.xml со ссылками на профили проверенных специалистов и датой их последнего
+// @Description изменения. Если профилей больше 50000, возвращается индекс файлов /sitemaps/{page}.xml
+// @Tags SEO
+// @Produce xml
+// @Success 200 {string} string "sitemap.xml или индекс sitemap"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /sitemap.xml [get]
+func (h *Handler) getSitemap(c *gin.Context) {
+	page, err := h.services.Specialist.GetSitemapPage(c.Request.Context(), 1)
+	if err != nil {
+		h.seoErrorResponse(c, err)
+		return
+	}
+
+	if page.Total <= domain.SitemapMaxURLs {
+		h.sitemapURLSetResponse(c, page.Entries)
+		return
+	}
+
+	pages := (page.Total + domain.SitemapMaxURLs - 1) / domain.SitemapMaxURLs
+	baseURL := strings.TrimRight(h.config.Appointment.PublicURL, "/")
+	index := sitemapIndex{XMLNS: sitemapXMLNS, Sitemaps: make([]sitemapRef, 0, pages)}
+	for i := 1; i <= pages; i++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: fmt.Sprintf("%s/sitemaps/%d.xml", baseURL, i)})
+	}
+
+	xmlResponse(c, index)
+}
+
+// @Summary Страница карты сайта
+// @Description Возвращает одну страницу sitemap из индекса: до 50000 ссылок на профили специалистов
+// @Tags SEO
+// @Produce xml
+// @Param page path int true "Номер страницы с 1, с расширением .xml"
+// @Success 200 {string} string "sitemap.xml"
+// @Failure 404 {object} errorResponseBody "Страница не найдена"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /sitemaps/{page}.xml [get]
+func (h *Handler) getSitemapPage(c *gin.Context) {
+	number, ok := strings.CutSuffix(c.Param("file"), ".xml")
+	if !ok {
+		notFoundResponse(c, "страница sitemap не найдена")
+		return
+	}
+	pageNumber, err := strconv.Atoi(number)
+	if err != nil {
+		notFoundResponse(c, "страница sitemap не найдена")
+		return
+	}
+
+	page, err := h.services.Specialist.GetSitemapPage(c.Request.Context(), pageNumber)
+	if err != nil {
+		h.seoErrorResponse(c, err)
+		return
+	}
+
+	h.sitemapURLSetResponse(c, page.Entries)
+}
+
+func (h *Handler) sitemapURLSetResponse(c *gin.Context, entries []domain.SitemapEntry) {
+	urlSet := sitemapURLSet{XMLNS: sitemapXMLNS, URLs: make([]sitemapURL, 0, len(entries))}
+	for _, entry := range entries {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{
+			Loc:     h.specialistProfileURL(entry.SpecialistID),
+			LastMod: entry.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	xmlResponse(c, urlSet)
+}
+
+func xmlResponse(c *gin.Context, body interface{}) {
+	data, err := xml.Marshal(body)
+	if err != nil {
+		internalServerErrorResponse(c)
+		return
+	}
+
+	setSEOCacheHeaders(c)
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), data...))
+}
+
+func (h *Handler) seoErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrNotFound) {
+		notFoundResponse(c, err.Error())
+		return
+	}
+	errorResponse(c, http.StatusInternalServerError, err.Error())
+}
+
+// @Summary Разметка schema.org профиля специалиста
+// @Description Возвращает JSON-LD для страницы профиля: специалист как Person и его практика как MedicalBusiness
+// @Description с ценами консультаций и рейтингом по отзывам. Удаленные и непроверенные специалисты не отдаются
+// @Tags Специалисты
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Success 200 {object} domain.StructuredData "JSON-LD"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /specialists/{id}/structured-data [get]
+func (h *Handler) getSpecialistStructuredData(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	specialist, err := h.services.Specialist.GetPublicProfile(c.Request.Context(), id)
+	if err != nil {
+		h.seoErrorResponse(c, err)
+		return
+	}
+
+	data, err := json.Marshal(domain.NewSpecialistStructuredData(specialist, h.specialistProfileURL(specialist.ID), h.config.Billing.Currency))
+	if err != nil {
+		internalServerErrorResponse(c)
+		return
+	}
+
+	setSEOCacheHeaders(c)
+	c.Data(http.StatusOK, "application/ld+json; charset=utf-8", data)
+}
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Удержать слот
+// @Description Резервирует свободный слот специалиста за клиентом на 10 минут, пока оформляется запись.
+// @Description Удержанный слот не показывается свободным другим клиентам. Одновременно можно удерживать не больше 3 слотов.
+// @Description Запись по удержанию создается через POST /appointments с hold_id или reservation_token.
+// @Tags Записи
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateSlotHoldDTO true "Специалист и время слота"
+// @Success 201 {object} domain.SlotHold "Удержание слота"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации, время недоступно или превышен лимит удержаний; error_code=client_blocked, если запись к специалисту недоступна"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 409 {object} errorResponseBody "Слот уже занят другой записью или удержанием; error_code=not_accepting_new_clients, если специалист не принимает новых клиентов"
+// @Failure 410 {object} errorResponseBody "Профиль специалиста удален (error_code=specialist_unavailable)"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /appointments/hold [post]
+func (h *Handler) holdSlot(c *gin.Context) {
+	var req domain.CreateSlotHoldDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	h.createSlotHold(c, req)
+}
+
+// @Summary Зарезервировать слот специалиста
+// @Description Резервирует свободный слот специалиста на 10 минут, чтобы клиент успел оформить и оплатить запись.
+// @Description Пока резервирование активно, слот не показывается свободным. Запись создается через POST /appointments с reservation_token из ответа.
+// @Tags Специалисты
+// @Accept json
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Param input body domain.ReserveSlotDTO true "Время слота"
+// @Success 201 {object} domain.SlotHold "Резервирование слота с reservation_token"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации, время недоступно или превышен лимит резервирований; error_code=client_blocked, если запись к специалисту недоступна"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 409 {object} errorResponseBody "Слот уже занят другой записью или резервированием; error_code=not_accepting_new_clients, если специалист не принимает новых клиентов"
+// @Failure 410 {object} errorResponseBody "Профиль специалиста удален (error_code=specialist_unavailable)"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/{id}/slots/reserve [post]
+func (h *Handler) reserveSpecialistSlot(c *gin.Context) {
+	specialistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	var req domain.ReserveSlotDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	h.createSlotHold(c, domain.CreateSlotHoldDTO{
+		SpecialistID:    specialistID,
+		AppointmentDate: req.AppointmentDate,
+	})
+}
+
+func (h *Handler) createSlotHold(c *gin.Context, dto domain.CreateSlotHoldDTO) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Warn("ошибка получения ID пользователя", zap.Error(err))
+		unauthorizedResponse(c)
+		return
+	}
+
+	hold, err := h.services.Appointment.HoldSlot(c.Request.Context(), userID, dto)
+	if errors.Is(err, service.ErrClientBlocked) {
+		clientBlockedResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrNotAcceptingClients) {
+		notAcceptingClientsResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrSpecialistUnavailable) {
+		specialistUnavailableResponse(c)
+		return
+	}
+	if errors.Is(err, service.ErrConflict) {
+		errorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("ошибка удержания слота", zap.Error(err))
+		badRequestResponse(c, "ошибка удержания слота")
+		return
+	}
+
+	createdResponse(c, hold)
+}
+
+// @Summary Снять удержание слота
+// @Description Освобождает удержанный клиентом слот до истечения срока удержания
+// @Tags Записи
+// @Produce json
+// @Param id path int true "ID удержания"
+// @Success 204 "Удержание снято"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 404 {object} errorResponseBody "Удержание не найдено"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /appointments/hold/{id} [delete]
+func (h *Handler) releaseSlotHold(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Warn("ошибка получения ID пользователя", zap.Error(err))
+		unauthorizedResponse(c)
+		return
+	}
+
+	holdID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	err = h.services.Appointment.ReleaseHold(c.Request.Context(), userID, holdID)
+	if errors.Is(err, service.ErrNotFound) {
+		notFoundResponse(c, "удержание слота не найдено")
+		return
+	}
+	if err != nil {
+		h.logger.Error("ошибка снятия удержания слота", zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	noContentResponse(c)
+}
@@ -1,8 +1,8 @@
 package rest
 
 import (
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,8 +12,12 @@ import (
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
+	"laps/internal/service"
 )
 
+// Наибольшее значение фильтра по минимальному стажу специалиста
+const maxExperienceYearsFilter = 50
+
 // @Summary Получить список специалистов
 // @Description Возвращает список специалистов с фильтрацией и пагинацией
 // @Tags Специалисты
@@ -21,40 +25,81 @@ import (
 // @Produce json
 // @Param limit query int false "Лимит записей на странице (по умолчанию 20)"
 // @Param offset query int false "Смещение (по умолчанию 0)"
-// @Param type query string false "Тип специалиста (психолог, психотерапевт и т.д.)"
+// @Param type query string false "Код типа специалиста из справочника /specialist-types"
 // @Param specialization_id query integer false "ID специализации"
+// @Param language query string false "Код языка консультации (ISO 639-1, например ru, en)"
+// @Param tags query string false "Метки через запятую; специалист должен иметь все метки"
 // @Param date query string false "Дата для получения свободных слотов (YYYY-MM-DD)"
+// @Param accepting_clients query bool false "Только принимающие (true) или не принимающие (false) новых клиентов"
+// @Param min_experience_years query int false "Минимальный стаж в годах (от 0 до 50)"
+// @Param include_deleted query bool false "Включить удаленных специалистов (только для администратора)"
+// @Param Accept-Language header string false "Предпочтительные языки описаний (ru, en, kk); без заголовка — ru"
 // @Success 200 {object} paginatedResponse "Список специалистов с пагинацией"
+// @Failure 400 {object} errorResponseBody "Неизвестный код языка, некорректная метка или стаж"
+// @Failure 403 {object} errorResponseBody "Удаленных специалистов может запросить только администратор"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Router /specialists [get]
 func (h *Handler) getSpecialists(c *gin.Context) {
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	if err != nil || limit < 0 {
-		limit = 20
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
+
+	includeDeleted, ok := h.includeDeletedSpecialists(c)
+	if !ok {
+		return
 	}
 
-	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
+	filter := domain.SpecialistFilter{
+		IncludeDeleted: includeDeleted,
+		Limit:          limit,
+		Offset:         offset,
 	}
 
-	var specialistType *domain.SpecialistType
 	if typeStr := c.Query("type"); typeStr != "" {
 		t := domain.SpecialistType(typeStr)
-		specialistType = &t
+		filter.Type = &t
 	}
 
-	var specializationID *int64
 	if specializationIDStr := c.Query("specialization_id"); specializationIDStr != "" {
 		id, err := strconv.ParseInt(specializationIDStr, 10, 64)
 		if err == nil {
-			specializationID = &id
+			filter.SpecializationID = &id
 		} else {
 			h.logger.Warn("неверный формат specialization_id", zap.Error(err))
 		}
 	}
 
-	specialists, total, err := h.services.Specialist.List(c.Request.Context(), specialistType, specializationID, limit, offset)
+	if language := c.Query("language"); language != "" {
+		filter.Language = &language
+	}
+
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+
+	if acceptingStr := c.Query("accepting_clients"); acceptingStr != "" {
+		accepting, err := strconv.ParseBool(acceptingStr)
+		if err != nil {
+			badRequestResponse(c, "неверный формат accepting_clients, ожидается true или false")
+			return
+		}
+		filter.AcceptingClients = &accepting
+	}
+
+	if minExperienceStr := c.Query("min_experience_years"); minExperienceStr != "" {
+		minExperience, err := strconv.Atoi(minExperienceStr)
+		if err != nil || minExperience < 0 || minExperience > maxExperienceYearsFilter {
+			badRequestResponse(c, fmt.Sprintf("min_experience_years должен быть целым числом от 0 до %d", maxExperienceYearsFilter))
+			return
+		}
+		filter.MinExperienceYears = &minExperience
+	}
+
+	specialists, total, err := h.services.Specialist.List(c.Request.Context(), filter)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка при получении списка специалистов", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, "ошибка при получении списка специалистов")
@@ -84,18 +129,48 @@ func (h *Handler) getSpecialists(c *gin.Context) {
 		}
 	}
 
+	if langs := contentLanguages(c); len(langs) > 0 {
+		for i := range specialists {
+			specialists[i].Localize(langs)
+		}
+	}
+
 	page := offset/limit + 1
 	paginatedSuccessResponse(c, specialists, total, page, limit)
 }
 
+// @Summary Типы специалистов
+// @Description Возвращает активные типы специалистов из справочника для выпадающих списков
+// @Tags Специалисты
+// @Produce json
+// @Success 200 {array} domain.SpecialistTypeInfo "Типы специалистов"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /specialist-types [get]
+func (h *Handler) getSpecialistTypes(c *gin.Context) {
+	types, err := h.services.Specialist.ListTypes(c.Request.Context())
+	if err != nil {
+		h.logger.Error("ошибка получения типов специалистов", zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, types)
+}
+
 // @Summary Получить специалиста по ID
-// @Description Возвращает информацию о специалисте по указанному ID
+// @Description Возвращает информацию о специалисте по указанному ID, включая статистику скорости ответов в чате.
+// @Description activity_stats содержит показатели за 90 дней: медиану времени первого ответа, долю подтвержденных записей и долю отмен специалистом; при выборке меньше 5 показатель равен null
 // @Tags Специалисты
 // @Accept json
 // @Produce json
 // @Param id path int true "ID специалиста"
+// @Param If-None-Match header string false "ETag из предыдущего ответа"
+// @Param include_deleted query bool false "Вернуть специалиста, даже если он удален (только для администратора)"
+// @Param Accept-Language header string false "Предпочтительные языки (ru, en, kk); без заголовка — ru"
 // @Success 200 {object} domain.Specialist "Данные специалиста"
+// @Success 304 "Данные не изменились"
 // @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 403 {object} errorResponseBody "Удаленного специалиста может запросить только администратор"
 // @Failure 404 {object} errorResponseBody "Специалист не найден"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Router /specialists/{id} [get]
@@ -106,14 +181,261 @@ func (h *Handler) getSpecialistByID(c *gin.Context) {
 		return
 	}
 
-	specialist, err := h.services.Specialist.GetByID(c.Request.Context(), id)
+	includeDeleted, ok := h.includeDeletedSpecialists(c)
+	if !ok {
+		return
+	}
+
+	var specialist *domain.Specialist
+	if includeDeleted {
+		specialist, err = h.services.Specialist.GetByIDWithDeleted(c.Request.Context(), id)
+	} else {
+		specialist, err = h.services.Specialist.GetByID(c.Request.Context(), id)
+	}
 	if err != nil {
 		h.logger.Error("ошибка при получении специалиста", zap.Int64("id", id), zap.Error(err))
 		notFoundResponse(c, "специалист не найден")
 		return
 	}
 
-	successResponse(c, http.StatusOK, specialist)
+	responseStats, err := h.services.Chat.GetSpecialistResponseStats(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Warn("не удалось получить статистику ответов специалиста", zap.Int64("id", id), zap.Error(err))
+	} else {
+		specialist.ResponseStats = responseStats
+	}
+
+	activityStats, err := h.services.Specialist.GetActivityStats(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Warn("не удалось получить статистику активности специалиста", zap.Int64("id", id), zap.Error(err))
+	} else {
+		specialist.ActivityStats = activityStats
+	}
+
+	specialist.Localize(contentLanguages(c))
+	successResponseWithETag(c, specialist, h.config.HTTP.CacheMaxAge.Specialist)
+}
+
+// includeDeletedSpecialists разбирает параметр include_deleted. Удаленных специалистов видит только администратор,
+// остальным отвечает 403; при false ответ уже отправлен
+func (h *Handler) includeDeletedSpecialists(c *gin.Context) (bool, bool) {
+	if c.Query("include_deleted") != "true" {
+		return false, true
+	}
+
+	if userRole, err := getUserRole(c); err != nil || userRole != domain.UserRoleAdmin {
+		forbiddenResponse(c, "удаленных специалистов может запросить только администратор")
+		return false, false
+	}
+
+	return true, true
+}
+
+const (
+	defaultNextAvailableDays = 30
+	maxNextAvailableDays     = 90
+)
+
+// @Summary Ближайшее свободное время специалиста
+// @Description Возвращает первый свободный слот начиная с текущего момента. Выходные, исключения и полностью занятые дни пропускаются.
+// @Description Если свободного времени в пределах горизонта нет, data равно null.
+// @Tags Специалисты
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Param days query int false "Горизонт поиска в днях (по умолчанию 30, максимум 90)"
+// @Success 200 {object} domain.AvailableSlot "Ближайший свободный слот или null"
+// @Failure 400 {object} errorResponseBody "Неверный формат параметров"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /specialists/{id}/next-available [get]
+func (h *Handler) getSpecialistNextAvailable(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	days := defaultNextAvailableDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 1 {
+			badRequestResponse(c, "параметр days должен быть положительным числом")
+			return
+		}
+		if days > maxNextAvailableDays {
+			days = maxNextAvailableDays
+		}
+	}
+
+	if _, err := h.services.Specialist.GetByID(c.Request.Context(), id); err != nil {
+		h.logger.Error("ошибка при получении специалиста", zap.Int64("id", id), zap.Error(err))
+		notFoundResponse(c, "специалист не найден")
+		return
+	}
+
+	slot, err := h.services.Schedule.GetNextAvailableSlot(c.Request.Context(), id, days)
+	if err != nil {
+		h.logger.Error("ошибка поиска ближайшего свободного времени", zap.Int64("id", id), zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, slot)
+}
+
+// @Summary История цен специалиста
+// @Description Возвращает изменения цен первичной и повторной консультации, начиная с последнего
+// @Tags Специалисты
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Success 200 {array} domain.SpecialistPriceChange "История изменения цен"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /specialists/{id}/price-history [get]
+func (h *Handler) getSpecialistPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	if _, err := h.services.Specialist.GetByID(c.Request.Context(), id); err != nil {
+		h.logger.Error("ошибка при получении специалиста", zap.Int64("id", id), zap.Error(err))
+		notFoundResponse(c, "специалист не найден")
+		return
+	}
+
+	history, err := h.services.Specialist.GetPriceHistory(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("ошибка получения истории цен", zap.Int64("id", id), zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, history)
+}
+
+// Максимальная длина диапазона календаря доступности
+const maxAvailabilityRangeDays = 60
+
+// @Summary Календарь доступности специалиста
+// @Description Возвращает количество свободных слотов по дням в диапазоне дат (не больше 60 дней), учитывая расписание, исключения, записи и внешний календарь.
+// @Description Дни без расписания и выходные не возвращаются. С параметром slots=true для каждого дня возвращаются сами слоты.
+// @Tags Специалисты
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Param from query string false "Начальная дата (YYYY-MM-DD), по умолчанию сегодня"
+// @Param to query string false "Конечная дата включительно (YYYY-MM-DD), по умолчанию через 30 дней после from"
+// @Param slots query bool false "Вернуть список свободных слотов для каждого дня"
+// @Success 200 {object} []domain.DayAvailability "Свободное время по дням"
+// @Failure 400 {object} errorResponseBody "Неверный формат параметров или слишком большой диапазон"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /specialists/{id}/availability [get]
+func (h *Handler) getSpecialistAvailability(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err = time.ParseInLocation("2006-01-02", fromStr, now.Location())
+		if err != nil {
+			badRequestResponse(c, "неверный формат параметра from, ожидается YYYY-MM-DD")
+			return
+		}
+	}
+
+	to := from.AddDate(0, 0, defaultNextAvailableDays)
+	if toStr := c.Query("to"); toStr != "" {
+		to, err = time.ParseInLocation("2006-01-02", toStr, now.Location())
+		if err != nil {
+			badRequestResponse(c, "неверный формат параметра to, ожидается YYYY-MM-DD")
+			return
+		}
+	}
+
+	if to.Before(from) {
+		badRequestResponse(c, "дата to не может быть раньше from")
+		return
+	}
+	if to.After(from.AddDate(0, 0, maxAvailabilityRangeDays)) {
+		badRequestResponse(c, "диапазон не должен превышать 60 дней")
+		return
+	}
+
+	withSlots, _ := strconv.ParseBool(c.Query("slots"))
+
+	if _, err := h.services.Specialist.GetByID(c.Request.Context(), id); err != nil {
+		h.logger.Error("ошибка при получении специалиста", zap.Int64("id", id), zap.Error(err))
+		notFoundResponse(c, "специалист не найден")
+		return
+	}
+
+	days, err := h.services.Schedule.GetAvailability(c.Request.Context(), id, from, to, withSlots)
+	if err != nil {
+		h.logger.Error("ошибка получения календаря доступности", zap.Int64("id", id), zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, days)
+}
+
+// @Summary Месячный календарь специалиста
+// @Description Возвращает статус каждого дня месяца: free — есть свободный слот, booked — все слоты заняты или прошли,
+// @Description no_schedule — нет расписания или выходной. Ключ — дата в формате YYYY-MM-DD.
+// @Tags Специалисты
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Param year path int true "Год"
+// @Param month path int true "Месяц (1-12)"
+// @Success 200 {object} map[string]string "Статусы дней месяца"
+// @Failure 400 {object} errorResponseBody "Неверный формат параметров"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /specialists/{id}/calendar/{year}/{month} [get]
+func (h *Handler) getSpecialistMonthCalendar(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		badRequestResponse(c, "неверный формат года")
+		return
+	}
+
+	month, err := strconv.Atoi(c.Param("month"))
+	if err != nil {
+		badRequestResponse(c, "неверный формат месяца")
+		return
+	}
+
+	if _, err := h.services.Specialist.GetByID(c.Request.Context(), id); err != nil {
+		h.logger.Error("ошибка при получении специалиста", zap.Int64("id", id), zap.Error(err))
+		notFoundResponse(c, "специалист не найден")
+		return
+	}
+
+	calendar, err := h.services.Schedule.GetMonthCalendar(c.Request.Context(), id, year, month)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		h.logger.Error("ошибка получения месячного календаря", zap.Int64("id", id), zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, calendar)
 }
 
 // @Summary Создать специалиста
@@ -188,6 +510,14 @@ func (h *Handler) createSpecialist(c *gin.Context) {
 	}
 
 	id, err := h.services.Specialist.Create(c.Request.Context(), targetUserID, req)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrConflict) {
+		errorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка при создании специалиста", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, err.Error())
@@ -200,17 +530,21 @@ func (h *Handler) createSpecialist(c *gin.Context) {
 }
 
 // @Summary Обновить специалиста
-// @Description Обновляет информацию о специалисте
+// @Description Обновляет информацию о специалисте.
+// @Description Требуется версия профиля из GET-ответа: поле expected_version или заголовок If-Match с номером версии.
+// @Description Если профиль уже изменили, возвращается 409 с error_code=version_conflict и актуальным профилем в current.
 // @Tags Специалисты
 // @Accept json
 // @Produce json
 // @Param id path int true "ID специалиста"
+// @Param If-Match header string false "Версия профиля, если expected_version не передана в теле"
 // @Param input body domain.UpdateSpecialistDTO true "Новые данные специалиста"
 // @Success 204 {object} nil "Данные успешно обновлены"
 // @Failure 400 {object} errorResponseBody "Ошибка валидации"
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 403 {object} errorResponseBody "Доступ запрещен"
 // @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 409 {object} errorResponseBody "Профиль изменен другим пользователем (error_code=version_conflict)"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
 // @Router /specialists/{id} [put]
@@ -252,11 +586,33 @@ func (h *Handler) updateSpecialist(c *gin.Context) {
 		return
 	}
 
+	req.ChangedBy = &currentUserID
+
+	version, ok := expectedVersion(c, req.ExpectedVersion)
+	if !ok {
+		return
+	}
+	req.ExpectedVersion = version
+
 	h.logger.Debug("запрос на обновление специалиста",
 		zap.Int64("id", id),
 		zap.Any("request", req))
 
 	err = h.services.Specialist.Update(c.Request.Context(), id, req)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrVersionConflict) {
+		var current interface{}
+		if actual, getErr := h.services.Specialist.GetByID(c.Request.Context(), id); getErr == nil {
+			current = actual
+		} else {
+			h.logger.Error("ошибка получения актуального профиля после конфликта версий", zap.Int64("id", id), zap.Error(getErr))
+		}
+		versionConflictResponse(c, err.Error(), current)
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка при обновлении специалиста", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, err.Error())
@@ -320,6 +676,54 @@ func (h *Handler) getMySpecialistProfile(c *gin.Context) {
 	successResponse(c, http.StatusOK, specialist)
 }
 
+// @Summary Прием новых клиентов
+// @Description Открывает или закрывает запись для новых клиентов. Клиенты, у которых уже были записи к специалисту, могут записываться и при закрытом приеме
+// @Tags Специалисты
+// @Accept json
+// @Produce json
+// @Param input body domain.SetAcceptingClientsDTO true "Принимает ли специалист новых клиентов"
+// @Success 200 {object} domain.Specialist "Обновленный профиль специалиста"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/availability [patch]
+func (h *Handler) setMySpecialistAvailability(c *gin.Context) {
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetAcceptingClientsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	userID, _ := getUserID(c)
+	err := h.services.Specialist.Update(c.Request.Context(), specialist.ID, domain.UpdateSpecialistDTO{
+		AcceptingClients: req.AcceptingClients,
+		ChangedBy:        &userID,
+	})
+	if err != nil {
+		h.logger.Error("ошибка изменения приема новых клиентов", zap.Int64("specialistID", specialist.ID), zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	updated, err := h.services.Specialist.GetByID(c.Request.Context(), specialist.ID)
+	if err != nil {
+		h.logger.Error("ошибка при получении обновленного специалиста", zap.Int64("specialistID", specialist.ID), zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, updated)
+}
+
 // @Summary Загрузить фотографию профиля
 // @Description Загружает и устанавливает фотографию профиля специалиста
 // @Tags Специалисты
@@ -365,49 +769,13 @@ func (h *Handler) uploadSpecialistPhoto(c *gin.Context) {
 		return
 	}
 
-	file, header, err := c.Request.FormFile("photo")
-	if err != nil {
-		h.logger.Warn("ошибка получения файла из формы", zap.Error(err))
-		badRequestResponse(c, "не удалось получить файл")
-		return
-	}
-	defer file.Close()
-
 	const maxSize = 5 * 1024 * 1024
-	if header.Size > maxSize {
-		badRequestResponse(c, "файл слишком большой (максимальный размер 5 MB)")
+	fileData, filename, ok := h.readImageUpload(c, "photo", maxSize)
+	if !ok {
 		return
 	}
 
-	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
-	if err != nil {
-		h.logger.Error("ошибка чтения файла", zap.Error(err))
-		errorResponse(c, http.StatusInternalServerError, "ошибка чтения файла")
-		return
-	}
-
-	_, err = file.Seek(0, io.SeekStart)
-	if err != nil {
-		h.logger.Error("ошибка сброса указателя файла", zap.Error(err))
-		errorResponse(c, http.StatusInternalServerError, "ошибка чтения файла")
-		return
-	}
-
-	fileType := http.DetectContentType(buffer)
-	if !strings.HasPrefix(fileType, "image/") {
-		badRequestResponse(c, "файл не является изображением")
-		return
-	}
-
-	fileData, err := io.ReadAll(file)
-	if err != nil {
-		h.logger.Error("ошибка чтения файла", zap.Error(err))
-		errorResponse(c, http.StatusInternalServerError, "ошибка чтения файла")
-		return
-	}
-
-	err = h.services.Specialist.UploadProfilePhoto(c.Request.Context(), id, fileData, header.Filename)
+	err = h.services.Specialist.UploadProfilePhoto(c.Request.Context(), id, fileData, filename)
 	if err != nil {
 		h.logger.Error("ошибка загрузки фото в хранилище", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, "ошибка загрузки фотографии")
@@ -475,10 +843,13 @@ func (h *Handler) deleteSpecialistPhoto(c *gin.Context) {
 }
 
 // @Summary Удалить специалиста
-// @Description Удаляет профиль специалиста
+// @Description Скрывает профиль специалиста из каталога и закрывает запись к нему; записи и отзывы сохраняются,
+// @Description администратор может восстановить профиль. С permanent=true администратор удаляет профиль
+// @Description окончательно вместе с записями, отзывами и фото
 // @Tags Специалисты
 // @Produce json
 // @Param id path int true "ID специалиста"
+// @Param permanent query bool false "Удалить без возможности восстановления (только для администратора)"
 // @Success 204 {object} nil "Профиль специалиста удален"
 // @Failure 400 {object} errorResponseBody "Неверный формат ID"
 // @Failure 401 {object} errorResponseBody "Не авторизован"
@@ -494,21 +865,42 @@ func (h *Handler) deleteSpecialist(c *gin.Context) {
 		return
 	}
 
-	specialist, err := h.services.Specialist.GetByID(c.Request.Context(), id)
+	userID, err := getUserID(c)
 	if err != nil {
-		notFoundResponse(c, "специалист не найден")
+		unauthorizedResponse(c)
 		return
 	}
 
-	userID, err := getUserID(c)
+	userRole, err := getUserRole(c)
 	if err != nil {
 		unauthorizedResponse(c)
 		return
 	}
 
-	userRole, err := getUserRole(c)
+	if c.Query("permanent") == "true" {
+		if userRole != domain.UserRoleAdmin {
+			forbiddenResponse(c, "окончательно удалить профиль может только администратор")
+			return
+		}
+
+		err = h.services.Specialist.DeletePermanently(c.Request.Context(), id)
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(c, "специалист не найден")
+			return
+		}
+		if err != nil {
+			h.logger.Error("ошибка окончательного удаления специалиста", zap.Error(err))
+			errorResponse(c, http.StatusInternalServerError, "ошибка удаления специалиста")
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	specialist, err := h.services.Specialist.GetByID(c.Request.Context(), id)
 	if err != nil {
-		unauthorizedResponse(c)
+		notFoundResponse(c, "специалист не найден")
 		return
 	}
 
@@ -517,14 +909,11 @@ func (h *Handler) deleteSpecialist(c *gin.Context) {
 		return
 	}
 
-	if specialist.ProfilePhotoURL != "" {
-		err = h.services.Specialist.DeleteProfilePhoto(c.Request.Context(), id)
-		if err != nil {
-			h.logger.Error("ошибка удаления фото при удалении профиля", zap.Error(err))
-		}
-	}
-
 	err = h.services.Specialist.Delete(c.Request.Context(), id)
+	if errors.Is(err, service.ErrNotFound) {
+		notFoundResponse(c, "специалист не найден")
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка удаления специалиста", zap.Error(err))
 		errorResponse(c, http.StatusInternalServerError, "ошибка удаления специалиста")
@@ -533,3 +922,53 @@ func (h *Handler) deleteSpecialist(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// @Summary Верифицировать специалиста
+// @Description Администратор напрямую меняет признак верификации специалиста без проверки документов. Изменение записывается в журнал аудита.
+// @Tags Специалисты
+// @Accept json
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Param input body domain.VerifySpecialistDTO true "Новое значение верификации"
+// @Success 200 {object} map[string]interface{} "Текущее значение верификации"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/{id}/verify [patch]
+func (h *Handler) verifySpecialist(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	var req domain.VerifySpecialistDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	if _, err := h.services.Specialist.GetByID(c.Request.Context(), id); err != nil {
+		h.logger.Error("ошибка при получении специалиста", zap.Int64("id", id), zap.Error(err))
+		notFoundResponse(c, "специалист не найден")
+		return
+	}
+
+	if err := h.services.Specialist.SetVerified(c.Request.Context(), adminID, id, *req.IsVerified); err != nil {
+		h.logger.Error("ошибка изменения верификации специалиста", zap.Int64("id", id), zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, gin.H{"id": id, "is_verified": *req.IsVerified})
+}
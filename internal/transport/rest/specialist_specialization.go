@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,8 +9,115 @@ import (
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
+	"laps/internal/service"
 )
 
+// @Summary Получить специализации специалиста
+// @Description Возвращает все специализации, которые ведет специалист, а не только основную specialization_id
+// @Tags Специалисты
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Param Accept-Language header string false "Предпочтительные языки (ru, en, kk); без заголовка — ru"
+// @Success 200 {array} domain.Specialization "Специализации специалиста"
+// @Failure 400 {object} errorResponseBody "Неверный формат ID"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /specialists/{id}/specializations [get]
+func (h *Handler) getSpecialistSpecializations(c *gin.Context) {
+	specialistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID специалиста")
+		return
+	}
+
+	specializations, err := h.services.Specialist.GetSpecializationsBySpecialistID(c.Request.Context(), specialistID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			notFoundResponse(c, "специалист не найден")
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if langs := contentLanguages(c); len(langs) > 0 {
+		for i := range specializations {
+			specializations[i].Localize(langs)
+		}
+	}
+
+	successResponse(c, http.StatusOK, specializations)
+}
+
+// @Summary Заменить специализации специалиста
+// @Description Атомарно заменяет все дополнительные специализации специалиста переданным набором: старые удаляются
+// @Description и новые добавляются в одной транзакции. Пустой список удаляет все дополнительные специализации,
+// @Description основная specialization_id не меняется. Доступно самому специалисту и администраторам
+// @Tags Специалисты
+// @Accept json
+// @Produce json
+// @Param id path int true "ID специалиста"
+// @Param input body domain.SetSpecializationsDTO true "ID специализаций"
+// @Success 200 {array} domain.Specialization "Новый список специализаций"
+// @Failure 400 {object} errorResponseBody "Неверный формат данных или специализация не найдена"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/{id}/specializations [put]
+func (h *Handler) setSpecialistSpecializations(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		h.logger.Warn("ошибка получения ID пользователя", zap.Error(err))
+		unauthorizedResponse(c)
+		return
+	}
+
+	specialistID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID специалиста")
+		return
+	}
+
+	var req domain.SetSpecializationsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	userRole, _ := getUserRole(c)
+	specialist, err := h.services.Specialist.GetByID(c.Request.Context(), specialistID)
+	if err != nil {
+		notFoundResponse(c, "специалист не найден")
+		return
+	}
+
+	if specialist.UserID != userID && userRole != domain.UserRoleAdmin {
+		h.logger.Warn("попытка несанкционированного доступа",
+			zap.Int64("userID", userID),
+			zap.Int64("specialistID", specialistID))
+		forbiddenResponse(c)
+		return
+	}
+
+	specializations, err := h.services.Specialist.SetSpecializations(c.Request.Context(), specialistID, req.SpecializationIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalid):
+			badRequestResponse(c, err.Error())
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(c, "специалист не найден")
+		default:
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	successResponse(c, http.StatusOK, specializations)
+}
+
 func (h *Handler) addSpecialistSpecialization(c *gin.Context) {
 	userID, err := getUserID(c)
 	if err != nil {
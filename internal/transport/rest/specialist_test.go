@@ -0,0 +1,270 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// fakeSpecialistService хранит один профиль и обновляет его по правилам хранилища:
+// переданная версия должна совпасть с текущей, после обновления версия растет
+type fakeSpecialistService struct {
+	service.SpecialistService
+
+	mu         sync.Mutex
+	specialist domain.Specialist
+	listed     *domain.SpecialistFilter
+}
+
+func (s *fakeSpecialistService) GetByID(ctx context.Context, id int64) (*domain.Specialist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id != s.specialist.ID {
+		return nil, service.ErrNotFound
+	}
+	specialist := s.specialist
+	return &specialist, nil
+}
+
+func (s *fakeSpecialistService) GetByUserID(ctx context.Context, userID int64) (*domain.Specialist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if userID != s.specialist.UserID {
+		return nil, service.ErrNotFound
+	}
+	specialist := s.specialist
+	return &specialist, nil
+}
+
+func (s *fakeSpecialistService) List(ctx context.Context, filter domain.SpecialistFilter) ([]domain.Specialist, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listed = &filter
+	return []domain.Specialist{s.specialist}, 1, nil
+}
+
+func (s *fakeSpecialistService) GetActivityStats(ctx context.Context, specialistID int64) (*domain.SpecialistActivityStats, error) {
+	return &domain.SpecialistActivityStats{}, nil
+}
+
+func (s *fakeSpecialistService) Update(ctx context.Context, id int64, dto domain.UpdateSpecialistDTO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if dto.ExpectedVersion != nil && *dto.ExpectedVersion != s.specialist.Version {
+		return fmt.Errorf("%w: профиль специалиста был изменен", service.ErrVersionConflict)
+	}
+	if dto.Description != nil {
+		s.specialist.Description = *dto.Description
+	}
+	if dto.AcceptingClients != nil {
+		s.specialist.AcceptingClients = *dto.AcceptingClients
+	}
+	s.specialist.Version++
+	return nil
+}
+
+type fakeChatService struct {
+	service.ChatService
+}
+
+func (s *fakeChatService) GetSpecialistResponseStats(ctx context.Context, specialistID int64) (*domain.SpecialistResponseStats, error) {
+	return &domain.SpecialistResponseStats{}, nil
+}
+
+func newSpecialistTestRouter(specialists service.SpecialistService, userID int64, role domain.UserRole) *gin.Engine {
+	h := &Handler{
+		services: &service.Services{Specialist: specialists, Chat: &fakeChatService{}},
+		logger:   zap.NewNop(),
+		config:   &config.Config{HTTP: config.HTTPConfig{CacheMaxAge: config.CacheMaxAgeConfig{Specialist: time.Minute}}},
+	}
+
+	router := gin.New()
+	authenticated := func(c *gin.Context) {
+		c.Set(userIDCtx, userID)
+		c.Set(userRoleCtx, role)
+	}
+	router.GET("/api/v1/specialists", h.apiVersionMiddleware(apiV1), h.getSpecialists)
+	router.GET("/api/v1/specialists/:id", h.apiVersionMiddleware(apiV1), h.getSpecialistByID)
+	router.PATCH("/api/v1/specialists/me/availability", h.apiVersionMiddleware(apiV1), authenticated, h.setMySpecialistAvailability)
+	router.PUT("/api/v1/specialists/:id", h.apiVersionMiddleware(apiV1), authenticated, h.updateSpecialist)
+	router.PUT("/api/v2/specialists/:id", h.apiVersionMiddleware(apiV2), authenticated, h.updateSpecialist)
+	return router
+}
+
+func putJSON(router http.Handler, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	return sendJSON(router, http.MethodPut, path, body, headers)
+}
+
+func sendJSON(router http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Два администратора открыли профиль версии 1. Первое сохранение проходит, второе
+// с той же версией получает 409 и актуальный профиль вместо молчаливой перезаписи
+func TestUpdateSpecialistLostUpdate(t *testing.T) {
+	specialists := &fakeSpecialistService{specialist: domain.Specialist{ID: 7, UserID: 70, Version: 1}}
+	router := newSpecialistTestRouter(specialists, 1, domain.UserRoleAdmin)
+
+	first := putJSON(router, "/api/v1/specialists/7", `{"description":"first tab"}`, map[string]string{"If-Match": `"1"`})
+	if first.Code >= 300 {
+		t.Fatalf("first save: status = %d, body = %s", first.Code, first.Body)
+	}
+
+	second := putJSON(router, "/api/v1/specialists/7", `{"description":"second tab","expected_version":1}`, nil)
+	if second.Code != http.StatusConflict {
+		t.Fatalf("second save: status = %d, want 409; body = %s", second.Code, second.Body)
+	}
+
+	var body struct {
+		ErrorCode string            `json:"error_code"`
+		Current   domain.Specialist `json:"current"`
+	}
+	if err := json.Unmarshal(second.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.ErrorCode != "version_conflict" {
+		t.Errorf("error_code = %q, want version_conflict", body.ErrorCode)
+	}
+	if body.Current.Version != 2 || body.Current.Description != "first tab" {
+		t.Errorf("current = %+v, want the first tab's profile at version 2", body.Current)
+	}
+
+	stored, _ := specialists.GetByID(context.Background(), 7)
+	if stored.Description != "first tab" {
+		t.Errorf("stored description = %q, the first save was overwritten", stored.Description)
+	}
+}
+
+func TestUpdateSpecialistConflictV2Envelope(t *testing.T) {
+	specialists := &fakeSpecialistService{specialist: domain.Specialist{ID: 7, UserID: 70, Version: 4}}
+	router := newSpecialistTestRouter(specialists, 1, domain.UserRoleAdmin)
+
+	w := putJSON(router, "/api/v2/specialists/7", `{"description":"stale"}`, map[string]string{"If-Match": `W/"3"`})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409; body = %s", w.Code, w.Body)
+	}
+
+	var body struct {
+		Error struct {
+			ErrorCode string            `json:"error_code"`
+			Current   domain.Specialist `json:"current"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.ErrorCode != "version_conflict" || body.Error.Current.Version != 4 {
+		t.Errorf("error = %+v, want version_conflict with current version 4", body.Error)
+	}
+}
+
+func TestUpdateSpecialistRequiresVersion(t *testing.T) {
+	specialists := &fakeSpecialistService{specialist: domain.Specialist{ID: 7, UserID: 70, Version: 1}}
+	router := newSpecialistTestRouter(specialists, 70, domain.UserRoleSpecialist)
+
+	w := putJSON(router, "/api/v1/specialists/7", `{"description":"no version"}`, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	if stored, _ := specialists.GetByID(context.Background(), 7); stored.Version != 1 {
+		t.Errorf("version = %d, profile was updated without a version", stored.Version)
+	}
+}
+
+func getWithETag(router http.Handler, path, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetSpecialistETag(t *testing.T) {
+	specialists := &fakeSpecialistService{specialist: domain.Specialist{ID: 7, UserID: 70, Version: 1, Description: "Семейные споры"}}
+	router := newSpecialistTestRouter(specialists, 1, domain.UserRoleAdmin)
+
+	first := getWithETag(router, "/api/v1/specialists/7", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q", first.Code, etag)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "private, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	second := getWithETag(router, "/api/v1/specialists/7", etag)
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Fatalf("repeat request: status = %d, body = %q; want 304 without a body", second.Code, second.Body)
+	}
+	if second.Header().Get("ETag") != etag {
+		t.Errorf("304 carries ETag %q, want %q", second.Header().Get("ETag"), etag)
+	}
+
+	if w := putJSON(router, "/api/v1/specialists/7", `{"description":"Наследство"}`, map[string]string{"If-Match": `"1"`}); w.Code >= 300 {
+		t.Fatalf("update: status = %d, body = %s", w.Code, w.Body)
+	}
+
+	third := getWithETag(router, "/api/v1/specialists/7", etag)
+	if third.Code != http.StatusOK || third.Header().Get("ETag") == etag {
+		t.Errorf("after update: status = %d, ETag = %q; want 200 with a new tag", third.Code, third.Header().Get("ETag"))
+	}
+}
+
+func TestSetMySpecialistAvailability(t *testing.T) {
+	specialists := &fakeSpecialistService{specialist: domain.Specialist{ID: 7, UserID: 70, Version: 1, AcceptingClients: true}}
+	router := newSpecialistTestRouter(specialists, 70, domain.UserRoleSpecialist)
+
+	w := sendJSON(router, http.MethodPatch, "/api/v1/specialists/me/availability", `{"accepting_clients":false}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	var body struct {
+		Data domain.Specialist `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Data.AcceptingClients {
+		t.Errorf("response = %+v, want accepting_clients false", body.Data)
+	}
+	if stored, _ := specialists.GetByID(context.Background(), 7); stored.AcceptingClients {
+		t.Error("stored profile still accepts new clients")
+	}
+}
+
+func TestGetSpecialistsAcceptingFilter(t *testing.T) {
+	specialists := &fakeSpecialistService{specialist: domain.Specialist{ID: 7, UserID: 70, Version: 1}}
+	router := newSpecialistTestRouter(specialists, 1, domain.UserRoleClient)
+
+	if w := getWithETag(router, "/api/v1/specialists?accepting_clients=maybe", ""); w.Code != http.StatusBadRequest || specialists.listed != nil {
+		t.Errorf("invalid value: status = %d, want 400 before listing", w.Code)
+	}
+	if w := getWithETag(router, "/api/v1/specialists?accepting_clients=false", ""); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	if specialists.listed == nil || specialists.listed.AcceptingClients == nil || *specialists.listed.AcceptingClients {
+		t.Errorf("filter = %+v, want accepting_clients=false", specialists.listed)
+	}
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,10 +9,11 @@ import (
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
+	"laps/internal/service"
 )
 
 // @Summary Получить список специализаций
-// @Description Возвращает список специализаций с фильтрацией и пагинацией
+// @Description Возвращает список специализаций с фильтрацией и пагинацией. При tree=true возвращает дерево категорий без пагинации
 // @Tags Специализации
 // @Accept json
 // @Produce json
@@ -21,19 +23,19 @@ import (
 // @Param is_active query boolean false "Фильтр по активности"
 // @Param search query string false "Поисковый запрос"
 // @Param specialist_id query int false "ID специалиста для фильтрации специализаций"
+// @Param parent_id query int false "ID родительской специализации (только непосредственные потомки)"
+// @Param tree query boolean false "Вернуть вложенную структуру с дочерними специализациями"
+// @Param If-None-Match header string false "ETag из предыдущего ответа"
+// @Param Accept-Language header string false "Предпочтительные языки (ru, en, kk); без заголовка — ru"
 // @Success 200 {object} paginatedResponse "Список специализаций с пагинацией"
+// @Success 200 {array} domain.SpecializationNode "Дерево специализаций (tree=true)"
+// @Success 304 "Данные не изменились"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Router /specializations [get]
 func (h *Handler) getSpecializations(c *gin.Context) {
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	if err != nil || limit < 0 {
-		limit = 20
-	}
-
-	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
-	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
 
 	filter := domain.SpecializationFilter{
 		Limit:  limit,
@@ -61,6 +63,28 @@ func (h *Handler) getSpecializations(c *gin.Context) {
 		}
 	}
 
+	if parentIDStr := c.Query("parent_id"); parentIDStr != "" {
+		parentID, err := strconv.ParseInt(parentIDStr, 10, 64)
+		if err != nil {
+			badRequestResponse(c, "неверный формат parent_id")
+			return
+		}
+		filter.ParentID = &parentID
+	}
+
+	if c.Query("tree") == "true" {
+		tree, err := h.services.Specialization.Tree(c.Request.Context(), filter)
+		if err != nil {
+			h.logger.Error("ошибка получения дерева специализаций", zap.Error(err))
+			internalServerErrorResponse(c)
+			return
+		}
+
+		localizeSpecializationTree(tree, contentLanguages(c))
+		successResponseWithETag(c, tree, h.config.HTTP.CacheMaxAge.Specializations)
+		return
+	}
+
 	specializations, total, err := h.services.Specialization.List(c.Request.Context(), filter)
 	if err != nil {
 		h.logger.Error("ошибка получения списка специализаций", zap.Error(err))
@@ -68,8 +92,14 @@ func (h *Handler) getSpecializations(c *gin.Context) {
 		return
 	}
 
+	if langs := contentLanguages(c); len(langs) > 0 {
+		for i := range specializations {
+			specializations[i].Localize(langs)
+		}
+	}
+
 	page := offset/limit + 1
-	paginatedSuccessResponse(c, specializations, total, page, limit)
+	paginatedResponseWithETag(c, specializations, total, page, limit, h.config.HTTP.CacheMaxAge.Specializations)
 }
 
 // @Summary Получить специализацию по ID
@@ -78,6 +108,7 @@ func (h *Handler) getSpecializations(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID специализации"
+// @Param Accept-Language header string false "Предпочтительные языки (ru, en, kk); без заголовка — ru"
 // @Success 200 {object} domain.Specialization "Данные специализации"
 // @Failure 400 {object} errorResponseBody "Неверный формат ID"
 // @Failure 404 {object} errorResponseBody "Специализация не найдена"
@@ -98,6 +129,7 @@ func (h *Handler) getSpecializationByID(c *gin.Context) {
 		return
 	}
 
+	specialization.Localize(contentLanguages(c))
 	successResponse(c, http.StatusOK, specialization)
 }
 
@@ -123,6 +155,10 @@ func (h *Handler) createSpecialization(c *gin.Context) {
 	}
 
 	id, err := h.services.Specialization.Create(c.Request.Context(), req)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка создания специализации", zap.Error(err))
 		internalServerErrorResponse(c)
@@ -163,6 +199,10 @@ func (h *Handler) updateSpecialization(c *gin.Context) {
 	}
 
 	err = h.services.Specialization.Update(c.Request.Context(), id, req)
+	if errors.Is(err, service.ErrInvalid) {
+		badRequestResponse(c, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка обновления специализации", zap.Error(err), zap.Int64("id", id))
 		notFoundResponse(c, "специализация не найдена или ошибка обновления")
@@ -183,6 +223,7 @@ func (h *Handler) updateSpecialization(c *gin.Context) {
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 403 {object} errorResponseBody "Доступ запрещен"
 // @Failure 404 {object} errorResponseBody "Специализация не найдена"
+// @Failure 409 {object} errorResponseBody "У специализации есть дочерние специализации"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
 // @Router /specializations/{id} [delete]
@@ -195,6 +236,10 @@ func (h *Handler) deleteSpecialization(c *gin.Context) {
 	}
 
 	err = h.services.Specialization.Delete(c.Request.Context(), id)
+	if errors.Is(err, service.ErrConflict) {
+		errorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("ошибка удаления специализации", zap.Error(err), zap.Int64("id", id))
 		notFoundResponse(c, "специализация не найдена или ошибка удаления")
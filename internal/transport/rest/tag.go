@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Автодополнение меток
+// @Description Возвращает используемые метки специалистов, содержащие строку запроса: сначала совпадения по началу, затем более популярные
+// @Tags Метки
+// @Produce json
+// @Param q query string false "Строка поиска; без нее возвращаются самые популярные метки"
+// @Param limit query int false "Количество меток (по умолчанию 10, не более 50)"
+// @Success 200 {array} domain.Tag "Метки"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Router /tags [get]
+func (h *Handler) searchTags(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		limit = 0
+	}
+
+	tags, err := h.services.Tag.Search(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		h.logger.Error("ошибка поиска меток", zap.Error(err))
+		internalServerErrorResponse(c)
+		return
+	}
+
+	successResponse(c, http.StatusOK, tags)
+}
+
+// @Summary Метки текущего специалиста
+// @Description Возвращает метки профиля текущего специалиста
+// @Tags Специалисты
+// @Produce json
+// @Success 200 {array} domain.Tag "Метки специалиста"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/tags [get]
+func (h *Handler) getMySpecialistTags(c *gin.Context) {
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.services.Tag.GetSpecialistTags(c.Request.Context(), specialist.ID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, tags)
+}
+
+// @Summary Задать метки специалиста
+// @Description Заменяет метки профиля текущего специалиста. Метки приводятся к нижнему регистру, повторы удаляются; не более 15 меток
+// @Tags Специалисты
+// @Accept json
+// @Produce json
+// @Param input body domain.SetSpecialistTagsDTO true "Новый набор меток"
+// @Success 200 {array} domain.Tag "Сохраненные метки"
+// @Failure 400 {object} errorResponseBody "Ошибка валидации данных"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/tags [put]
+func (h *Handler) setMySpecialistTags(c *gin.Context) {
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetSpecialistTagsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	tags, err := h.services.Tag.SetSpecialistTags(c.Request.Context(), specialist.ID, req.Tags)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			badRequestResponse(c, err.Error())
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	successResponse(c, http.StatusOK, tags)
+}
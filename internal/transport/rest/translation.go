@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// contentLanguages возвращает предпочтительные языки контента из заголовка Accept-Language.
+// Без заголовка возвращается nil, и контент отдается на русском без изменений. Ответ зависит
+// от заголовка, поэтому он добавляется в Vary
+func contentLanguages(c *gin.Context) []string {
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return domain.PreferredContentLanguages(c.GetHeader("Accept-Language"))
+}
+
+// localizeSpecializationTree переводит названия и описания во всем дереве специализаций
+func localizeSpecializationTree(nodes []*domain.SpecializationNode, langs []string) {
+	if len(langs) == 0 {
+		return
+	}
+	for _, node := range nodes {
+		node.Localize(langs)
+		localizeSpecializationTree(node.Children, langs)
+	}
+}
+
+// @Summary Задать переводы специализации
+// @Description Добавляет или заменяет переводы названия и описания специализации на en и kk. Переводы на языки,
+// @Description которых нет в запросе, не меняются; язык с пустыми полями удаляет свой перевод. Русский вариант хранится
+// @Description в основных полях. Перевод выбирается по заголовку Accept-Language. Только для администраторов
+// @Tags Специализации
+// @Accept json
+// @Produce json
+// @Param id path int true "ID специализации"
+// @Param input body domain.SetTranslationsDTO true "Переводы по кодам языков"
+// @Success 200 {object} domain.Specialization "Специализация с переводами"
+// @Failure 400 {object} errorResponseBody "Неподдерживаемый язык или ошибка валидации"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Специализация не найдена"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specializations/{id}/translations [put]
+func (h *Handler) setSpecializationTranslations(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	var req domain.SetTranslationsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	specialization, err := h.services.Specialization.SetTranslations(c.Request.Context(), id, req.Translations)
+	if err != nil {
+		h.translationErrorResponse(c, err)
+		return
+	}
+
+	successResponse(c, http.StatusOK, specialization)
+}
+
+// @Summary Задать переводы профиля специалиста
+// @Description Добавляет или заменяет переводы описания профиля текущего специалиста на en и kk; поле name
+// @Description для профиля не переводится. Переводы на языки, которых нет в запросе, не меняются; язык с пустым
+// @Description описанием удаляет свой перевод. Перевод выбирается по заголовку Accept-Language
+// @Tags Специалисты
+// @Accept json
+// @Produce json
+// @Param input body domain.SetTranslationsDTO true "Переводы по кодам языков"
+// @Success 200 {object} domain.Specialist "Профиль с переводами"
+// @Failure 400 {object} errorResponseBody "Неподдерживаемый язык или ошибка валидации"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Профиль специалиста не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /specialists/me/translations [put]
+func (h *Handler) setMySpecialistTranslations(c *gin.Context) {
+	specialist, ok := h.currentSpecialist(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetTranslationsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	updated, err := h.services.Specialist.SetTranslations(c.Request.Context(), specialist.ID, req.Translations)
+	if err != nil {
+		h.translationErrorResponse(c, err)
+		return
+	}
+
+	successResponse(c, http.StatusOK, updated)
+}
+
+func (h *Handler) translationErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalid):
+		badRequestResponse(c, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		notFoundResponse(c, err.Error())
+	default:
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
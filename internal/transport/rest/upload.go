@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// readImageUpload читает изображение из multipart-поля формы, проверяя размер и тип содержимого.
+// При ошибке ответ клиенту уже записан и возвращается false
+func (h *Handler) readImageUpload(c *gin.Context, field string, maxSize int64) ([]byte, string, bool) {
+	file, header, err := c.Request.FormFile(field)
+	if err != nil {
+		h.logger.Warn("ошибка получения файла из формы", zap.Error(err))
+		badRequestResponse(c, "не удалось получить файл")
+		return nil, "", false
+	}
+	defer file.Close()
+
+	if header.Size > maxSize {
+		badRequestResponse(c, fmt.Sprintf("файл слишком большой (максимальный размер %d MB)", maxSize/(1024*1024)))
+		return nil, "", false
+	}
+
+	buffer := make([]byte, 512)
+	n, err := file.Read(buffer)
+	if err != nil && err != io.EOF {
+		h.logger.Error("ошибка чтения файла", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка чтения файла")
+		return nil, "", false
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		h.logger.Error("ошибка сброса указателя файла", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка чтения файла")
+		return nil, "", false
+	}
+
+	fileType := http.DetectContentType(buffer[:n])
+	if !strings.HasPrefix(fileType, "image/") {
+		badRequestResponse(c, "файл не является изображением")
+		return nil, "", false
+	}
+
+	fileData, err := io.ReadAll(file)
+	if err != nil {
+		h.logger.Error("ошибка чтения файла", zap.Error(err))
+		errorResponse(c, http.StatusInternalServerError, "ошибка чтения файла")
+		return nil, "", false
+	}
+
+	return fileData, header.Filename, true
+}
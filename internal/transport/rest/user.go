@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,6 +9,7 @@ import (
 	"go.uber.org/zap"
 
 	"laps/internal/domain"
+	"laps/internal/service"
 )
 
 // @Summary Создать пользователя
@@ -194,6 +196,50 @@ func (h *Handler) updatePassword(c *gin.Context) {
 	noContentResponse(c)
 }
 
+// @Summary Удалить свой аккаунт
+// @Description Удаляет аккаунт текущего пользователя по подтверждению паролем: имя, email и телефон заменяются заглушками,
+// @Description все сессии отзываются. Отзывы и записи сохраняются с обезличенным автором
+// @Tags Пользователи
+// @Accept json
+// @Produce json
+// @Param input body domain.DeleteAccountDTO true "Текущий пароль"
+// @Success 204 {object} nil "Аккаунт удален"
+// @Failure 400 {object} errorResponseBody "Неверный пароль"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/me [delete]
+func (h *Handler) deleteCurrentUser(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	var req domain.DeleteAccountDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	err = h.services.User.DeleteAccount(c.Request.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalid):
+			badRequestResponse(c, err.Error())
+		case errors.Is(err, service.ErrNotFound):
+			unauthorizedResponse(c)
+		default:
+			h.logger.Error("ошибка при удалении аккаунта", zap.Int64("userID", userID), zap.Error(err))
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	noContentResponse(c)
+}
+
 // @Summary Удалить пользователя
 // @Description Удаляет пользователя по ID (только для администраторов)
 // @Tags Пользователи
@@ -224,6 +270,59 @@ func (h *Handler) deleteUser(c *gin.Context) {
 	noContentResponse(c)
 }
 
+// @Summary Изменить роль пользователя
+// @Description Меняет роль пользователя (только для администраторов). Профиль специалиста при назначении роли
+// @Description specialist не создается автоматически, а при снятии этой роли удаляется; изменение записывается
+// @Description в журнал аудита. Сессии пользователя отзываются, новая роль попадает в токен при следующем входе
+// @Tags Пользователи
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param input body domain.ChangeRoleDTO true "Новая роль: client, specialist или admin"
+// @Success 200 {object} domain.User "Пользователь с новой ролью"
+// @Failure 400 {object} errorResponseBody "Неизвестная роль или попытка изменить собственную роль"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Пользователь не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /users/{id}/role [patch]
+func (h *Handler) changeUserRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный формат ID")
+		return
+	}
+
+	actorID, err := getUserID(c)
+	if err != nil {
+		unauthorizedResponse(c)
+		return
+	}
+
+	var req domain.ChangeRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	user, err := h.services.User.ChangeRole(c.Request.Context(), actorID, id, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalid):
+			badRequestResponse(c, err.Error())
+		case errors.Is(err, service.ErrNotFound):
+			notFoundResponse(c, err.Error())
+		default:
+			errorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	successResponse(c, http.StatusOK, user)
+}
+
 // @Summary Получить список пользователей
 // @Description Возвращает список пользователей с пагинацией (только для администраторов)
 // @Tags Пользователи
@@ -238,15 +337,9 @@ func (h *Handler) deleteUser(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router /users [get]
 func (h *Handler) getUsers(c *gin.Context) {
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	if err != nil || limit < 0 {
-		limit = 20
-	}
-
-	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
-	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
 
 	users, err := h.services.User.List(c.Request.Context(), limit, offset)
 	if err != nil {
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type apiVersion int
+
+const (
+	apiV1 apiVersion = 1
+	apiV2 apiVersion = 2
+
+	apiVersionCtx = "api_version"
+)
+
+// v2Envelope - единый формат успешного ответа API v2
+type v2Envelope struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta"`
+}
+
+// v2ErrorBody - единый формат ошибки API v2
+type v2ErrorBody struct {
+	Error v2Error `json:"error"`
+}
+
+type v2Error struct {
+	Code      int         `json:"code"`
+	ErrorCode string      `json:"error_code,omitempty"`
+	Message   string      `json:"message"`
+	Current   interface{} `json:"current,omitempty"`
+}
+
+type paginationMeta struct {
+	TotalCount int `json:"total_count"`
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	TotalPages int `json:"total_pages"`
+}
+
+// apiVersionMiddleware сохраняет версию API в контексте, чтобы хелперы ответов
+// выбирали формат без изменения логики обработчиков
+func (h *Handler) apiVersionMiddleware(version apiVersion) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionCtx, version)
+		c.Next()
+	}
+}
+
+func getAPIVersion(c *gin.Context) apiVersion {
+	if version, ok := c.Get(apiVersionCtx); ok {
+		if v, ok := version.(apiVersion); ok {
+			return v
+		}
+	}
+	return apiV1
+}
+
+// deprecationMiddleware помечает выбранные маршруты v1 заголовками Deprecation и Sunset
+// и указывает на соответствующий маршрут v2
+func (h *Handler) deprecationMiddleware() gin.HandlerFunc {
+	cfg := h.config.API
+
+	deprecated := make(map[string]bool, len(cfg.V1DeprecatedRoutes))
+	for _, route := range cfg.V1DeprecatedRoutes {
+		if route != "" {
+			deprecated[route] = true
+		}
+	}
+
+	var sunset string
+	if cfg.V1Sunset != "" {
+		if date, err := time.Parse("2006-01-02", cfg.V1Sunset); err == nil {
+			sunset = date.UTC().Format(http.TimeFormat)
+		}
+	}
+
+	return func(c *gin.Context) {
+		route := strings.TrimPrefix(c.FullPath(), "/api/v1")
+		if len(deprecated) == 0 || (!deprecated["*"] && !deprecated[route]) {
+			c.Next()
+			return
+		}
+
+		c.Header("Deprecation", "true")
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		successor := "/api/v2" + strings.TrimPrefix(c.Request.URL.Path, "/api/v1")
+		c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// newVersionTestRouter подключает обработчики к /api/v1 и /api/v2 так же, как InitRoutes
+func newVersionTestRouter(api config.APIConfig) *gin.Engine {
+	specialists := &fakeSpecialistService{specialist: domain.Specialist{ID: 7, UserID: 70, Version: 3, Description: "Семейные споры"}}
+	h := &Handler{
+		services: &service.Services{Specialist: specialists, Chat: &fakeChatService{}},
+		logger:   zap.NewNop(),
+		config:   &config.Config{API: api},
+	}
+
+	router := gin.New()
+	register := func(group *gin.RouterGroup) {
+		group.GET("/specialists", h.getSpecialists)
+		group.GET("/specialists/:id", h.getSpecialistByID)
+	}
+	register(router.Group("/api/v1", h.apiVersionMiddleware(apiV1), h.deprecationMiddleware()))
+	register(router.Group("/api/v2", h.apiVersionMiddleware(apiV2)))
+	return router
+}
+
+func getJSON(t *testing.T, router http.Handler, path string, dest interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if err := json.Unmarshal(w.Body.Bytes(), dest); err != nil {
+		t.Fatalf("%s: %v; body = %s", path, err, w.Body)
+	}
+	return w
+}
+
+func TestSpecialistListShapesPerVersion(t *testing.T) {
+	router := newVersionTestRouter(config.APIConfig{})
+
+	var v1 struct {
+		Data       []domain.Specialist `json:"data"`
+		TotalCount int                 `json:"total_count"`
+		Page       int                 `json:"page"`
+		PageSize   int                 `json:"page_size"`
+	}
+	getJSON(t, router, "/api/v1/specialists?limit=10", &v1)
+
+	var v2 struct {
+		Data []domain.Specialist `json:"data"`
+		Meta paginationMeta      `json:"meta"`
+	}
+	getJSON(t, router, "/api/v2/specialists?limit=10", &v2)
+
+	if len(v1.Data) != 1 || !reflect.DeepEqual(v1.Data, v2.Data) {
+		t.Errorf("versions serve different data:\nv1 = %+v\nv2 = %+v", v1.Data, v2.Data)
+	}
+	if v1.TotalCount != 1 || v1.Page != 1 || v1.PageSize != 10 {
+		t.Errorf("v1 pagination = %+v", v1)
+	}
+	want := paginationMeta{TotalCount: 1, Page: 1, PageSize: 10, TotalPages: 1}
+	if v2.Meta != want {
+		t.Errorf("v2 meta = %+v, want %+v", v2.Meta, want)
+	}
+}
+
+func TestSpecialistShapesPerVersion(t *testing.T) {
+	router := newVersionTestRouter(config.APIConfig{})
+
+	var v1 struct {
+		Status string            `json:"status"`
+		Data   domain.Specialist `json:"data"`
+	}
+	getJSON(t, router, "/api/v1/specialists/7", &v1)
+
+	var v2 struct {
+		Data domain.Specialist      `json:"data"`
+		Meta map[string]interface{} `json:"meta"`
+	}
+	getJSON(t, router, "/api/v2/specialists/7", &v2)
+
+	if v1.Status != "success" || v1.Data.ID != 7 {
+		t.Errorf("v1 = %+v", v1)
+	}
+	if v2.Meta == nil || !reflect.DeepEqual(v1.Data, v2.Data) {
+		t.Errorf("v2 = %+v, want the v1 specialist with meta", v2)
+	}
+}
+
+func TestErrorShapesPerVersion(t *testing.T) {
+	router := newVersionTestRouter(config.APIConfig{})
+	const path = "/specialists?accepting_clients=maybe"
+
+	var v1 errorResponseBody
+	if w := getJSON(t, router, "/api/v1"+path, &v1); w.Code != http.StatusBadRequest {
+		t.Fatalf("v1 status = %d", w.Code)
+	}
+	var v2 v2ErrorBody
+	if w := getJSON(t, router, "/api/v2"+path, &v2); w.Code != http.StatusBadRequest {
+		t.Fatalf("v2 status = %d", w.Code)
+	}
+
+	if v1.Status != "error" || v1.Code != http.StatusBadRequest || v1.Message == "" {
+		t.Errorf("v1 error = %+v", v1)
+	}
+	if v2.Error.Code != http.StatusBadRequest || v2.Error.Message != v1.Message {
+		t.Errorf("v2 error = %+v, want the v1 message %q", v2.Error, v1.Message)
+	}
+}
+
+func TestDeprecationHeaders(t *testing.T) {
+	router := newVersionTestRouter(config.APIConfig{V1DeprecatedRoutes: []string{"/specialists"}, V1Sunset: "2027-01-31"})
+
+	tests := []struct {
+		path       string
+		deprecated bool
+	}{
+		{"/api/v1/specialists", true},
+		{"/api/v1/specialists/7", false},
+		{"/api/v2/specialists", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if got := w.Header().Get("Deprecation") == "true"; got != tt.deprecated {
+				t.Fatalf("Deprecation = %q, want deprecated = %v", w.Header().Get("Deprecation"), tt.deprecated)
+			}
+			if !tt.deprecated {
+				return
+			}
+			if got := w.Header().Get("Sunset"); got != "Sun, 31 Jan 2027 00:00:00 GMT" {
+				t.Errorf("Sunset = %q", got)
+			}
+			if got := w.Header().Get("Link"); got != `</api/v2/specialists>; rel="successor-version"` {
+				t.Errorf("Link = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+// @Summary Зарегистрировать адрес webhook
+// @Description Регистрирует адрес внешней системы для событий appointment.created, appointment.status_changed,
+// @Description review.created и specialist.verified. Тело запроса подписывается HMAC-SHA256 секретом адреса
+// @Description в заголовке X-Webhook-Signature (sha256=<hex>), тип и ID события передаются в X-Webhook-Event
+// @Description и X-Webhook-Event-ID. Неудачная доставка повторяется с растущей паузой, всего до 5 попыток.
+// @Description Доступно только администраторам
+// @Tags Администрирование
+// @Accept json
+// @Produce json
+// @Param input body domain.CreateWebhookEndpointDTO true "Адрес, секрет не короче 16 символов и события"
+// @Success 201 {object} domain.WebhookEndpoint "Зарегистрированный адрес"
+// @Failure 400 {object} errorResponseBody "Неверный адрес или неизвестное событие"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/webhooks [post]
+func (h *Handler) createWebhookEndpoint(c *gin.Context) {
+	var req domain.CreateWebhookEndpointDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("неверный формат данных", zap.Error(err))
+		badRequestResponse(c, "неверный формат данных")
+		return
+	}
+
+	endpoint, err := h.services.Webhook.CreateEndpoint(c.Request.Context(), req)
+	if err != nil {
+		h.webhookErrorResponse(c, err)
+		return
+	}
+
+	createdResponse(c, endpoint)
+}
+
+// @Summary Адреса webhook
+// @Description Возвращает зарегистрированные адреса внешних систем без секретов. Доступно только администраторам
+// @Tags Администрирование
+// @Produce json
+// @Success 200 {array} domain.WebhookEndpoint "Адреса webhook"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/webhooks [get]
+func (h *Handler) getWebhookEndpoints(c *gin.Context) {
+	endpoints, err := h.services.Webhook.ListEndpoints(c.Request.Context())
+	if err != nil {
+		h.webhookErrorResponse(c, err)
+		return
+	}
+
+	successResponse(c, http.StatusOK, endpoints)
+}
+
+// @Summary Удалить адрес webhook
+// @Description Удаляет адрес вместе с историей доставок; недоставленные события на него больше не отправляются.
+// @Description Доступно только администраторам
+// @Tags Администрирование
+// @Param id path int true "ID адреса"
+// @Success 204 {object} nil "Адрес удален"
+// @Failure 400 {object} errorResponseBody "Неверный ID адреса"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Адрес не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/webhooks/{id} [delete]
+func (h *Handler) deleteWebhookEndpoint(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный ID адреса")
+		return
+	}
+
+	if err := h.services.Webhook.DeleteEndpoint(c.Request.Context(), id); err != nil {
+		h.webhookErrorResponse(c, err)
+		return
+	}
+
+	noContentResponse(c)
+}
+
+// @Summary Доставки webhook
+// @Description Возвращает доставки событий на адрес от новых к старым: тело запроса, статус (pending, delivered,
+// @Description failed), число попыток, код последнего ответа и текст ошибки. Доставленные события хранятся 7 дней.
+// @Description Доступно только администраторам
+// @Tags Администрирование
+// @Produce json
+// @Param id path int true "ID адреса"
+// @Param limit query int false "Количество записей (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} paginatedResponse{data=[]domain.WebhookDelivery} "Доставки с пагинацией"
+// @Failure 400 {object} errorResponseBody "Неверный ID адреса"
+// @Failure 401 {object} errorResponseBody "Не авторизован"
+// @Failure 403 {object} errorResponseBody "Доступ запрещен"
+// @Failure 404 {object} errorResponseBody "Адрес не найден"
+// @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (h *Handler) getWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequestResponse(c, "неверный ID адреса")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset = normalizePaging(limit, offset)
+
+	deliveries, total, err := h.services.Webhook.ListDeliveries(c.Request.Context(), id, limit, offset)
+	if err != nil {
+		h.webhookErrorResponse(c, err)
+		return
+	}
+
+	page := offset/limit + 1
+	paginatedSuccessResponse(c, deliveries, total, page, limit)
+}
+
+func (h *Handler) webhookErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalid):
+		badRequestResponse(c, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		notFoundResponse(c, err.Error())
+	default:
+		errorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
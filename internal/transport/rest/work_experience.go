@@ -52,7 +52,7 @@ func (h *Handler) getWorkExperience(c *gin.Context) {
 }
 
 // @Summary Добавить опыт работы специалисту
-// @Description Добавляет новую запись об опыте работы для специалиста
+// @Description Добавляет новую запись об опыте работы для специалиста. Для текущего места работы end_year не указывается
 // @Tags Опыт работы
 // @Accept json
 // @Produce json
@@ -63,6 +63,7 @@ func (h *Handler) getWorkExperience(c *gin.Context) {
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 403 {object} errorResponseBody "Доступ запрещен"
 // @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 422 {object} errorResponseBody "Год окончания работы раньше года начала"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
 // @Router /work-experience [post]
@@ -110,6 +111,11 @@ func (h *Handler) addWorkExperience(c *gin.Context) {
 		return
 	}
 
+	if !req.HasValidPeriod() {
+		errorResponse(c, http.StatusUnprocessableEntity, "год окончания работы не может быть раньше года начала")
+		return
+	}
+
 	workExperienceID, err := h.services.WorkExperience.AddWorkExperience(c.Request.Context(), specialistID, req)
 	if err != nil {
 		h.logger.Error("ошибка при добавлении опыта работы", zap.Error(err))
@@ -123,7 +129,7 @@ func (h *Handler) addWorkExperience(c *gin.Context) {
 }
 
 // @Summary Добавить опыт работы специалисту по ID
-// @Description Добавляет новую запись об опыте работы для специалиста
+// @Description Добавляет новую запись об опыте работы для специалиста. Для текущего места работы end_year не указывается
 // @Tags Опыт работы
 // @Accept json
 // @Produce json
@@ -134,6 +140,7 @@ func (h *Handler) addWorkExperience(c *gin.Context) {
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 403 {object} errorResponseBody "Доступ запрещен"
 // @Failure 404 {object} errorResponseBody "Специалист не найден"
+// @Failure 422 {object} errorResponseBody "Год окончания работы раньше года начала"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
 // @Router /specialists/{id}/work-experience [post]
@@ -175,6 +182,11 @@ func (h *Handler) addWorkExperienceToSpecialist(c *gin.Context) {
 		return
 	}
 
+	if !req.HasValidPeriod() {
+		errorResponse(c, http.StatusUnprocessableEntity, "год окончания работы не может быть раньше года начала")
+		return
+	}
+
 	workExperienceID, err := h.services.WorkExperience.AddWorkExperience(c.Request.Context(), specialistID, req)
 	if err != nil {
 		h.logger.Error("ошибка при добавлении опыта работы", zap.Error(err))
@@ -227,6 +239,7 @@ func (h *Handler) getWorkExperienceByID(c *gin.Context) {
 // @Failure 401 {object} errorResponseBody "Не авторизован"
 // @Failure 403 {object} errorResponseBody "Доступ запрещен"
 // @Failure 404 {object} errorResponseBody "Опыт работы не найден"
+// @Failure 422 {object} errorResponseBody "Год окончания работы раньше года начала"
 // @Failure 500 {object} errorResponseBody "Внутренняя ошибка сервера"
 // @Security ApiKeyAuth
 // @Router /work-experience/{id} [put]
@@ -275,6 +288,11 @@ func (h *Handler) updateWorkExperience(c *gin.Context) {
 		return
 	}
 
+	if !req.HasValidPeriod() {
+		errorResponse(c, http.StatusUnprocessableEntity, "год окончания работы не может быть раньше года начала")
+		return
+	}
+
 	err = h.services.WorkExperience.UpdateWorkExperience(c.Request.Context(), id, req)
 	if err != nil {
 		h.logger.Error("ошибка при обновлении опыта работы", zap.Error(err))
@@ -0,0 +1,97 @@
+package websocket
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ProtocolError identifies why the server closes a signaling connection.
+// Before closing, the server sends a final "error" frame carrying the code,
+// followed by a close frame with the matching close code and the code as reason
+type ProtocolError string
+
+const (
+	// ErrorAuthExpired: credentials are missing or invalid; log in again before reconnecting
+	ErrorAuthExpired ProtocolError = "auth_expired"
+	// ErrorRateLimited: the global connection limit is reached; retry later
+	ErrorRateLimited ProtocolError = "rate_limited"
+	// ErrorServerShutdown: the server is restarting; reconnect with backoff
+	ErrorServerShutdown ProtocolError = "server_shutdown"
+	// ErrorDuplicateConnection: a newer connection of the same user took over; do not reconnect
+	ErrorDuplicateConnection ProtocolError = "duplicate_connection"
+	// ErrorInvalidMessage: the client sent a message the server cannot accept (e.g. too large)
+	ErrorInvalidMessage ProtocolError = "invalid_message"
+	// ErrorSlowConsumer: the client did not read its messages fast enough and was disconnected
+	ErrorSlowConsumer ProtocolError = "slow_consumer"
+	// ErrorInternal: the connection failed on the server side
+	ErrorInternal ProtocolError = "internal_error"
+)
+
+// writeWait bounds writing the final error frame and close frame
+const writeWait = 10 * time.Second
+
+// Application close codes in the 4000-4999 range mirror the HTTP status of the error
+const (
+	CloseInvalidMessage      = 4400
+	CloseAuthExpired         = 4401
+	CloseDuplicateConnection = 4409
+	// CloseTooManyConnections is sent when a connection limit is exceeded (HTTP 429 analogue)
+	CloseTooManyConnections = 4429
+)
+
+// CloseCodes maps every protocol error to the WebSocket close code sent with it
+var CloseCodes = map[ProtocolError]int{
+	ErrorAuthExpired:         CloseAuthExpired,
+	ErrorRateLimited:         CloseTooManyConnections,
+	ErrorServerShutdown:      websocket.CloseGoingAway,
+	ErrorDuplicateConnection: CloseDuplicateConnection,
+	ErrorInvalidMessage:      CloseInvalidMessage,
+	ErrorSlowConsumer:        websocket.CloseTryAgainLater,
+	ErrorInternal:            websocket.CloseInternalServerErr,
+}
+
+// ErrorFrame is the data of the final "error" message sent before a close frame
+type ErrorFrame struct {
+	Code      ProtocolError `json:"code"`
+	Message   string        `json:"message"`
+	CloseCode int           `json:"close_code"`
+}
+
+var protocolErrorMessages = map[ProtocolError]string{
+	ErrorAuthExpired:         "authentication required",
+	ErrorRateLimited:         "too many connections",
+	ErrorServerShutdown:      "server is shutting down",
+	ErrorDuplicateConnection: "connection replaced by a newer one",
+	ErrorInvalidMessage:      "invalid message",
+	ErrorSlowConsumer:        "client is not reading messages fast enough",
+	ErrorInternal:            "internal server error",
+}
+
+// closeFrames returns the final error message and the close frame payload for code
+func closeFrames(code ProtocolError) ([]byte, []byte) {
+	closeCode := CloseCodes[code]
+	message, _ := json.Marshal(SignalingMessage{
+		Type: "error",
+		Data: ErrorFrame{
+			Code:      code,
+			Message:   protocolErrorMessages[code],
+			CloseCode: closeCode,
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+	return message, websocket.FormatCloseMessage(closeCode, string(code))
+}
+
+// writeClose sends the final error frame and the close frame. The caller must
+// be the only writer of conn. Errors are ignored: the connection is closed
+// right after anyway, and a broken connection cannot carry the frames
+func writeClose(conn *websocket.Conn, code ProtocolError) {
+	message, closeMessage := closeFrames(code)
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+		return
+	}
+	conn.WriteMessage(websocket.CloseMessage, closeMessage)
+}
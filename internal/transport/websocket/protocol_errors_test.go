@@ -0,0 +1,129 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"laps/config"
+	"laps/internal/domain"
+)
+
+// expectClose reads until the final error frame and checks that the close
+// frame that follows carries the matching close code
+func expectClose(t *testing.T, conn *websocket.Conn, code ProtocolError) {
+	t.Helper()
+
+	frame := readType(t, conn, "error")
+	data, _ := frame.Data.(map[string]interface{})
+	if data["code"] != string(code) {
+		t.Errorf("error frame code = %v, want %s", data["code"], code)
+	}
+	if data["close_code"] != float64(CloseCodes[code]) {
+		t.Errorf("error frame close_code = %v, want %d", data["close_code"], CloseCodes[code])
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("read after error frame: %v, want close frame", err)
+	}
+	if closeErr.Code != CloseCodes[code] || closeErr.Text != string(code) {
+		t.Errorf("close frame = %d %q, want %d %q", closeErr.Code, closeErr.Text, CloseCodes[code], code)
+	}
+}
+
+func TestCloseDuplicateConnection(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	first := dial(t, hub, url, 1, domain.UserRole("client"))
+
+	if _, _, err := websocket.DefaultDialer.Dial(url+"?user_id=1&role=client", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expectClose(t, first, ErrorDuplicateConnection)
+}
+
+// A user has one connection at a time, so reconnecting any number of times
+// is never refused: each new connection replaces the previous one
+func TestReconnectReplacesWithoutPerUserLimit(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+
+	var previous *websocket.Conn
+	for i := 0; i < 7; i++ {
+		conn := dial(t, hub, url, 1, domain.UserRole("client"))
+		if previous != nil {
+			expectClose(t, previous, ErrorDuplicateConnection)
+		}
+		previous = conn
+	}
+
+	hub.mutex.RLock()
+	registered := len(hub.clients)
+	hub.mutex.RUnlock()
+	if registered != 1 {
+		t.Errorf("registered clients = %d, want 1", registered)
+	}
+}
+
+func TestCloseMessageTooLarge(t *testing.T) {
+	hub, url := startTestHubWithConfig(t, newTestServices(), config.WebSocketConfig{
+		MaxMessageSizeBytes: 64,
+		MaxConsecutiveDrops: 1000,
+	})
+	client := dial(t, hub, url, 1, domain.UserRole("client"))
+
+	send(t, client, SignalingMessage{Type: "ping", SessionID: strings.Repeat("x", 100)})
+
+	expectClose(t, client, ErrorInvalidMessage)
+}
+
+func TestCloseWithoutCredentials(t *testing.T) {
+	_, url := startTestHub(t, newTestServices())
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	expectClose(t, conn, ErrorAuthExpired)
+}
+
+func TestCloseConnectionLimit(t *testing.T) {
+	hub, url := startTestHubWithConfig(t, newTestServices(), config.WebSocketConfig{
+		MaxConnections:      1,
+		MaxConsecutiveDrops: 1000,
+	})
+	dial(t, hub, url, 1, domain.UserRole("client"))
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?user_id=2&role=specialist", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	expectClose(t, conn, ErrorRateLimited)
+}
+
+func TestCloseOnShutdown(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	client := dial(t, hub, url, 1, domain.UserRole("client"))
+
+	shutdownDone := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownDone <- hub.Shutdown(ctx)
+	}()
+
+	expectClose(t, client, ErrorServerShutdown)
+	if err := <-shutdownDone; err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
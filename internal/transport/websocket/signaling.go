@@ -1,28 +1,47 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"runtime/debug"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 
+	"laps/config"
 	"laps/internal/domain"
+	"laps/internal/metrics"
 	"laps/internal/service"
 )
 
+var tracer = otel.Tracer("laps/internal/transport/websocket")
+
 // SignalingMessage represents a WebRTC signaling message
 type SignalingMessage struct {
 	Type      string      `json:"type"`
+	// Version of the signaling protocol, see ProtocolVersion
+	Version   int         `json:"version,omitempty"`
 	SessionID string      `json:"session_id"`
 	From      int64       `json:"from"`
 	To        int64       `json:"to"`
 	Data      interface{} `json:"data,omitempty"`
 	Timestamp string      `json:"timestamp"`
+
+	// appointmentID is the appointment resolved by the call check in readPump
+	// for call-invitation and call-offer; it never leaves the server
+	appointmentID *int64
 }
 
 // Client represents a connected WebSocket client
@@ -33,15 +52,54 @@ type Client struct {
 	Conn   *websocket.Conn
 	Send   chan []byte
 	Hub    *SignalingHub
+
+	// closeError is the reason sent in the final error frame and close frame
+	// once Send is closed; the first reason set wins. writerDone is closed
+	// when writePump exits
+	closeError atomic.Pointer[ProtocolError]
+	writerDone chan struct{}
+
+	// DroppedMessagesTotal counts messages dropped because Send was full
+	DroppedMessagesTotal atomic.Int64
+	// consecutiveDrops is reset on every successful send; evicting is set once
+	// the client has been scheduled for unregistration due to backpressure
+	consecutiveDrops int
+	evicting         bool
+
+	// replaced is set by the hub when a newer connection of the same user
+	// takes over, so the old readPump does not report a connection loss
+	replaced atomic.Bool
+	// lost is set by readPump when the connection dropped unexpectedly,
+	// so the hub keeps the user reachable for the reconnect grace period
+	lost atomic.Bool
+
+	// limiter throttles incoming messages; nil when the limit is disabled.
+	// consecutiveThrottled counts messages in a row that had to wait for it
+	// and is only touched by readPump
+	limiter              *rate.Limiter
+	consecutiveThrottled int
+
+	// lastSeenTouched is when readPump last reported activity of the user,
+	// see lastSeenTouchInterval; only used by readPump
+	lastSeenTouched time.Time
+
+	// disconnected is set by the hub while the client waits for a reconnect:
+	// Send is already closed and messages for the user go to buffer instead.
+	// graceTimer finalizes the disconnect once the grace period is over
+	disconnected bool
+	buffer       [][]byte
+	graceTimer   *time.Timer
 }
 
 // SignalingHub maintains the set of active clients and broadcasts messages
 type SignalingHub struct {
-	// Registered clients by user ID
+	// Registered clients by user ID. A user has at most one signaling
+	// connection: a new one replaces the previous connection, which is closed
+	// with duplicate_connection unless it is already waiting for a reconnect
 	clients map[int64]*Client
 
-	// Inbound messages from the clients
-	broadcast chan []byte
+	// Inbound messages from the clients, already validated by readPump
+	broadcast chan *SignalingMessage
 
 	// Register requests from the clients
 	register chan *Client
@@ -49,6 +107,12 @@ type SignalingHub struct {
 	// Unregister requests from clients
 	unregister chan *Client
 
+	// Clients whose reconnect grace period is over
+	expire chan *Client
+
+	// Server-originated messages for a single user, see NotifyUser
+	outbound chan *SignalingMessage
+
 	// Active call sessions by session ID
 	sessions map[string]*CallSession
 
@@ -58,8 +122,24 @@ type SignalingHub struct {
 	// Services
 	services *service.Services
 
+	// Limits for incoming message size, slow consumers and connection counts
+	config config.WebSocketConfig
+
+	// Open connections, guarded by connMutex. Counted from upgrade until
+	// readPump exits, so in-flight reconnects are included even before the
+	// hub registers them
+	connMutex        sync.Mutex
+	totalConnections int
+
 	// Mutex for thread safety
 	mutex sync.RWMutex
+
+	// Shutdown coordination: quit stops Run, done is closed once Run has exited,
+	// writers tracks running writePumps so shutdown can wait for them to drain
+	quit         chan struct{}
+	done         chan struct{}
+	shutdownOnce sync.Once
+	writers      sync.WaitGroup
 }
 
 // CallSession represents an active call session
@@ -71,6 +151,25 @@ type CallSession struct {
 	Status       string    `json:"status"` // waiting, active, ended
 	CreatedAt    time.Time `json:"created_at"`
 	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	// CallerID is the participant who placed the call
+	CallerID int64 `json:"caller_id"`
+	// Answered is set once the callee has answered, even if the call later reconnects
+	Answered bool `json:"answered"`
+	// AnsweredAt is the start of the current connected segment of the call
+	AnsweredAt *time.Time `json:"answered_at,omitempty"`
+	// DurationSeconds sums the finished segments across reconnects
+	DurationSeconds int `json:"duration_seconds"`
+	// MediaState holds the last media-state reported by each participant
+	MediaState map[int64]MediaState `json:"media_state,omitempty"`
+}
+
+// MediaState describes the local media of a call participant so the peer
+// can restore its UI after a reconnect
+type MediaState struct {
+	Muted         bool      `json:"muted"`
+	CameraOff     bool      `json:"camera_off"`
+	ScreenSharing bool      `json:"screen_sharing"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 var upgrader = websocket.Upgrader{
@@ -104,77 +203,233 @@ var upgrader = websocket.Upgrader{
 }
 
 // NewSignalingHub creates a new signaling hub
-func NewSignalingHub(logger *zap.Logger, services *service.Services) *SignalingHub {
+func NewSignalingHub(logger *zap.Logger, services *service.Services, cfg config.WebSocketConfig) *SignalingHub {
 	return &SignalingHub{
 		clients:    make(map[int64]*Client),
-		broadcast:  make(chan []byte),
+		broadcast:  make(chan *SignalingMessage),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		expire:     make(chan *Client),
+		outbound:   make(chan *SignalingMessage, outboundBufferSize),
 		sessions:   make(map[string]*CallSession),
 		logger:     logger,
 		services:   services,
+		config:     cfg,
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
 	}
 }
 
 // Run starts the signaling hub
 func (h *SignalingHub) Run() {
+	defer close(h.done)
+
 	for {
 		select {
+		case <-h.quit:
+			h.closeAllClients()
+			return
+
 		case client := <-h.register:
 			h.mutex.Lock()
+			// A reconnect replaces the previous connection of the same user;
+			// close the old one so its writePump exits instead of leaking
+			var buffered [][]byte
+			if previous, ok := h.clients[client.UserID]; ok && previous != client {
+				previous.replaced.Store(true)
+				if previous.disconnected {
+					// Reconnected within the grace period: calls stay alive and
+					// the messages collected meanwhile are delivered below
+					previous.graceTimer.Stop()
+					buffered = previous.buffer
+				} else {
+					previous.closeWith(ErrorDuplicateConnection)
+					close(previous.Send)
+				}
+			}
 			h.clients[client.UserID] = client
+			h.deliverBuffered(client, buffered)
 			h.mutex.Unlock()
+			go h.touchLastSeen(client.UserID, false)
 			h.logger.Info("Client connected", 
 				zap.Int64("user_id", client.UserID), 
 				zap.String("role", string(client.Role)))
 
 		case client := <-h.unregister:
 			h.mutex.Lock()
-			if _, ok := h.clients[client.UserID]; ok {
-				delete(h.clients, client.UserID)
+			if current, ok := h.clients[client.UserID]; ok && current == client && !client.disconnected {
 				close(client.Send)
+				if client.lost.Load() && !client.evicting && h.config.ReconnectGracePeriod > 0 {
+					h.awaitReconnect(client)
+				} else {
+					h.finalizeDisconnect(client)
+				}
 			}
 			h.mutex.Unlock()
 			h.logger.Info("Client disconnected", zap.Int64("user_id", client.UserID))
 
-		case message := <-h.broadcast:
-			var msg SignalingMessage
-			if err := json.Unmarshal(message, &msg); err != nil {
-				h.logger.Error("Failed to unmarshal message", zap.Error(err))
-				continue
+		case client := <-h.expire:
+			h.mutex.Lock()
+			if current, ok := h.clients[client.UserID]; ok && current == client && client.disconnected {
+				h.logger.Info("Reconnect grace period expired",
+					zap.Int64("user_id", client.UserID),
+					zap.Int("buffered_messages", len(client.buffer)))
+				h.finalizeDisconnect(client)
 			}
+			h.mutex.Unlock()
 
-			h.handleSignalingMessage(&msg)
+		case msg := <-h.outbound:
+			h.mutex.RLock()
+			if client, ok := h.clients[msg.To]; ok {
+				h.sendMessageToClient(client, msg)
+			}
+			h.mutex.RUnlock()
+
+		case msg := <-h.broadcast:
+			h.handleSignalingMessage(msg)
 		}
 	}
 }
 
+// outboundBufferSize bounds server-originated messages waiting for the hub loop
+const outboundBufferSize = 256
+
+// NotifySpecialist delivers a server-originated message to the specialist's
+// connection, such as "new-appointment" after a client books. See NotifyUser
+// for delivery guarantees
+func (h *SignalingHub) NotifySpecialist(specialistUserID int64, msg *SignalingMessage) {
+	h.NotifyUser(specialistUserID, msg)
+}
+
+// NotifyUser delivers a server-originated message to the user's connection.
+// Offline users are skipped; a user waiting for a reconnect gets the message
+// from the reconnect buffer. It never blocks the caller: when the hub is
+// stopped or overloaded, the message is dropped
+func (h *SignalingHub) NotifyUser(userID int64, msg *SignalingMessage) {
+	msg.To = userID
+	if msg.Timestamp == "" {
+		msg.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	select {
+	case h.outbound <- msg:
+	case <-h.done:
+	default:
+		h.logger.Warn("Outbound queue full, dropping notification",
+			zap.Int64("user_id", userID),
+			zap.String("message_type", msg.Type))
+	}
+}
+
+// Publish implements service.RealtimePublisher. Realtime events are addressed
+// to specialists, see service.RealtimeEventNewAppointment
+func (h *SignalingHub) Publish(userID int64, eventType string, data interface{}) {
+	h.NotifySpecialist(userID, &SignalingMessage{
+		Type: eventType,
+		To:   userID,
+		Data: data,
+	})
+}
+
+// Shutdown stops the hub loop, closes every client connection with a
+// server_shutdown error frame and close frame and waits for the write pumps to drain.
+// It returns ctx.Err() if the clients could not be drained in time.
+func (h *SignalingHub) Shutdown(ctx context.Context) error {
+	h.shutdownOnce.Do(func() {
+		close(h.quit)
+	})
+
+	select {
+	case <-h.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		h.logger.Info("Signaling hub stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// closeAllClients closes the send channel of every registered client.
+// Must only be called from the Run goroutine, which owns channel closing.
+func (h *SignalingHub) closeAllClients() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for userID, client := range h.clients {
+		if client.disconnected {
+			client.graceTimer.Stop()
+		} else {
+			client.closeWith(ErrorServerShutdown)
+			close(client.Send)
+		}
+		delete(h.clients, userID)
+	}
+}
+
+// acquireConnection reserves a connection slot for the user.
+// It returns false if the global limit is reached. There is no per-user
+// limit: the hub keeps one connection per user, see clients
+func (h *SignalingHub) acquireConnection(userID int64) bool {
+	h.connMutex.Lock()
+	defer h.connMutex.Unlock()
+
+	if h.config.MaxConnections > 0 && h.totalConnections >= h.config.MaxConnections {
+		h.logger.Warn("Global WebSocket connection limit reached",
+			zap.Int64("user_id", userID),
+			zap.Int("limit", h.config.MaxConnections))
+		return false
+	}
+
+	h.totalConnections++
+	return true
+}
+
+// releaseConnection frees a slot reserved by acquireConnection
+func (h *SignalingHub) releaseConnection() {
+	h.connMutex.Lock()
+	defer h.connMutex.Unlock()
+
+	h.totalConnections--
+}
+
 // handleSignalingMessage processes incoming signaling messages
 func (h *SignalingHub) handleSignalingMessage(msg *SignalingMessage) {
+	_, span := tracer.Start(context.Background(), "signaling "+msg.Type,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("signaling.type", msg.Type),
+			attribute.String("signaling.session_id", msg.SessionID),
+			attribute.Int64("signaling.from", msg.From),
+			attribute.Int64("signaling.to", msg.To),
+		))
+	defer span.End()
+
 	h.logger.Info("🔔 [BACKEND] Processing signaling message", 
 		zap.String("type", msg.Type),
 		zap.Int64("from", msg.From),
 		zap.Int64("to", msg.To),
 		zap.String("session_id", msg.SessionID))
 
-	// Check if target user is connected
-	if _, exists := h.clients[msg.To]; !exists {
-		h.logger.Warn("❌ [BACKEND] Target user not connected", 
-			zap.Int64("target_user_id", msg.To),
-			zap.String("message_type", msg.Type))
-	} else {
-		h.logger.Info("✅ [BACKEND] Target user is connected", 
-			zap.Int64("target_user_id", msg.To),
-			zap.String("message_type", msg.Type))
-	}
-
+	// Target membership is checked by each handler under the hub mutex;
+	// clients and sessions must never be accessed without holding it
 	switch msg.Type {
 	case "call-invitation":
 		h.logger.Info("📞 [BACKEND] Handling call-invitation message")
 		h.handleCallInvitation(msg)
 	case "call-offer":
 		h.logger.Info("📞 [BACKEND] Handling call-offer message")
-		h.handleCallOffer(msg)
+		h.handleCallOffer(msg, msg.appointmentID)
 	case "call-answer":
 		h.handleCallAnswer(msg)
 	case "ice-candidate":
@@ -185,8 +440,162 @@ func (h *SignalingHub) handleSignalingMessage(msg *SignalingMessage) {
 		h.handleCallEnd(msg)
 	case "ping":
 		h.handlePing(msg)
+	case "reconnecting":
+		// Raised by reportReconnecting; clients cannot send it, see messageRules
+		h.handleReconnecting(msg)
+	case "renegotiate-offer", "renegotiate-answer":
+		h.handleRenegotiation(msg)
+	case "media-state":
+		h.handleMediaState(msg)
 	default:
 		h.logger.Warn("Unknown message type", zap.String("type", msg.Type))
+		h.warnSender(msg, "unknown_message_type")
+	}
+}
+
+// blockCheckTimeout bounds the block list lookup, which runs on the sender's readPump
+const blockCheckTimeout = 2 * time.Second
+
+// isCallBlocked reports whether one of the call parties has blocked the other.
+// Lookup failures do not block the call
+func (h *SignalingHub) isCallBlocked(msg *SignalingMessage) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), blockCheckTimeout)
+	defer cancel()
+
+	blocked, err := h.services.BlockList.IsBlockedBetweenUsers(ctx, msg.From, msg.To)
+	if err != nil {
+		h.logger.Error("Failed to check block list for call",
+			zap.Int64("from", msg.From),
+			zap.Int64("to", msg.To),
+			zap.Error(err))
+		return false
+	}
+	return blocked
+}
+
+// rejectBlockedCall answers the caller with "call-error: blocked" without notifying the callee
+func (h *SignalingHub) rejectBlockedCall(msg *SignalingMessage) {
+	h.rejectCall(msg, map[string]string{"error": "blocked"})
+}
+
+// rejectCall answers the caller with a "call-error" carrying data without notifying the callee.
+// The reply goes through the outbound queue, so it is safe to call outside the hub goroutine
+func (h *SignalingHub) rejectCall(msg *SignalingMessage, data map[string]string) {
+	h.logger.Info("Call rejected",
+		zap.String("session_id", msg.SessionID),
+		zap.Int64("from", msg.From),
+		zap.Int64("to", msg.To),
+		zap.Any("data", data))
+
+	h.NotifyUser(msg.From, &SignalingMessage{
+		Type:      "call-error",
+		SessionID: msg.SessionID,
+		From:      msg.To,
+		Data:      data,
+	})
+}
+
+// lastSeenTimeout bounds a single last-seen update
+const lastSeenTimeout = 2 * time.Second
+
+// lastSeenTouchInterval is how often a connection reports activity of its user
+const lastSeenTouchInterval = time.Minute
+
+// touchLastSeen reports activity at most once per lastSeenTouchInterval and
+// without waiting for the database, so inbound frames are never slowed down
+func (c *Client) touchLastSeen() {
+	now := time.Now()
+	if now.Sub(c.lastSeenTouched) < lastSeenTouchInterval {
+		return
+	}
+	c.lastSeenTouched = now
+	go c.Hub.touchLastSeen(c.UserID, false)
+}
+
+// touchLastSeen records user activity. The user service throttles database
+// writes, except for the final update when the user disconnects
+func (h *SignalingHub) touchLastSeen(userID int64, final bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), lastSeenTimeout)
+	defer cancel()
+
+	if err := h.services.User.TouchLastSeen(ctx, userID, final); err != nil {
+		h.logger.Warn("Failed to update last seen", zap.Int64("user_id", userID), zap.Error(err))
+	}
+}
+
+// callCheckTimeout bounds the chat session and appointment lookups of a call
+// check, which runs on the sender's readPump
+const callCheckTimeout = 2 * time.Second
+
+// checkCallAllowed finds the appointment of the call and checks that both
+// parties belong to it and that a call is allowed for it now. A call-offer
+// must pass data.appointment_id; a call-invitation may pass the appointment
+// chat as data.chat_session_id instead. It returns the appointment ID and an
+// empty reason if the call may proceed. A failed lookup rejects the call
+func (h *SignalingHub) checkCallAllowed(msg *SignalingMessage) (*int64, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), callCheckTimeout)
+	defer cancel()
+
+	appointmentID, ok := idFromData(msg.Data, "appointment_id")
+	if !ok && msg.Type != "call-offer" {
+		appointmentID, ok = h.appointmentIDFromChatSession(ctx, msg)
+	}
+	if !ok {
+		return nil, domain.CallNotAllowedNoAppointment
+	}
+
+	err := h.services.Appointment.CheckCallAllowed(ctx, appointmentID, msg.From, msg.To)
+	var notAllowed *service.CallNotAllowedError
+	switch {
+	case errors.As(err, &notAllowed):
+		return nil, notAllowed.Reason
+	case errors.Is(err, service.ErrNotFound):
+		return nil, domain.CallNotAllowedNoAppointment
+	case err != nil:
+		h.logger.Error("Failed to check whether call is allowed",
+			zap.Int64("appointment_id", appointmentID),
+			zap.Error(err))
+		return nil, domain.CallNotAllowedCheckFailed
+	}
+
+	return &appointmentID, ""
+}
+
+// appointmentIDFromChatSession finds the appointment of the chat session
+// passed as data.chat_session_id if the sender has access to it
+func (h *SignalingHub) appointmentIDFromChatSession(ctx context.Context, msg *SignalingMessage) (int64, bool) {
+	chatSessionID, ok := idFromData(msg.Data, "chat_session_id")
+	if !ok {
+		return 0, false
+	}
+
+	chatSession, err := h.services.Chat.GetChatSessionByID(ctx, chatSessionID, msg.From)
+	if err != nil {
+		h.logger.Warn("Chat session for call not found or not accessible",
+			zap.Int64("chat_session_id", chatSessionID),
+			zap.Int64("from", msg.From),
+			zap.Error(err))
+		return 0, false
+	}
+
+	return chatSession.AppointmentID, true
+}
+
+// idFromData reads a positive ID field of the message data sent as a number or a numeric string
+func idFromData(data interface{}, key string) (int64, bool) {
+	fields, ok := data.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+
+	switch value := fields[key].(type) {
+	case float64:
+		return int64(value), value > 0
+	case string:
+		id, err := strconv.ParseInt(value, 10, 64)
+		return id, err == nil && id > 0
+	default:
+		return 0, false
 	}
 }
 
@@ -243,7 +652,7 @@ func (h *SignalingHub) handleCallInvitation(msg *SignalingMessage) {
 }
 
 // handleCallOffer processes call offer messages
-func (h *SignalingHub) handleCallOffer(msg *SignalingMessage) {
+func (h *SignalingHub) handleCallOffer(msg *SignalingMessage, appointmentID *int64) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
@@ -283,11 +692,24 @@ func (h *SignalingHub) handleCallOffer(msg *SignalingMessage) {
 	}
 
 	session := &CallSession{
-		ID:           msg.SessionID,
-		ClientID:     clientID,
-		SpecialistID: specialistID,
-		Status:       "waiting",
-		CreatedAt:    time.Now(),
+		ID:            msg.SessionID,
+		ClientID:      clientID,
+		SpecialistID:  specialistID,
+		AppointmentID: appointmentID,
+		CallerID:      msg.From,
+		Status:        "waiting",
+		CreatedAt:     time.Now(),
+	}
+
+	// A new offer for a known session is a renegotiation after a reconnect:
+	// close the interrupted segment and keep counting the same call
+	if previous, exists := h.sessions[msg.SessionID]; exists {
+		h.closeCallSegment(previous, time.Now())
+		session.CreatedAt = previous.CreatedAt
+		session.CallerID = previous.CallerID
+		session.Answered = previous.Answered
+		session.DurationSeconds = previous.DurationSeconds
+		session.MediaState = previous.MediaState
 	}
 
 	h.sessions[msg.SessionID] = session
@@ -337,6 +759,11 @@ func (h *SignalingHub) handleCallAnswer(msg *SignalingMessage) {
 	// Update session status
 	if session, exists := h.sessions[msg.SessionID]; exists {
 		session.Status = "active"
+		session.Answered = true
+		if session.AnsweredAt == nil {
+			now := time.Now()
+			session.AnsweredAt = &now
+		}
 	}
 
 	// Forward answer to caller
@@ -377,8 +804,13 @@ func (h *SignalingHub) handleCallReject(msg *SignalingMessage) {
 			zap.Int64("caller_id", msg.To))
 	}
 
-	// Remove session if it exists
-	if _, exists := h.sessions[msg.SessionID]; exists {
+	// Remove session if it exists; the rejected call still counts as offered
+	if session, exists := h.sessions[msg.SessionID]; exists {
+		now := time.Now()
+		h.closeCallSegment(session, now)
+		session.Status = "ended"
+		session.EndedAt = &now
+		h.persistEndedCall(session)
 		delete(h.sessions, msg.SessionID)
 		h.logger.Info("Session removed after rejection", 
 			zap.String("session_id", msg.SessionID))
@@ -392,9 +824,11 @@ func (h *SignalingHub) handleCallEnd(msg *SignalingMessage) {
 
 	// Update session status
 	if session, exists := h.sessions[msg.SessionID]; exists {
-		session.Status = "ended"
 		now := time.Now()
+		h.closeCallSegment(session, now)
+		session.Status = "ended"
 		session.EndedAt = &now
+		h.persistEndedCall(session)
 	}
 
 	// Forward end message to the other peer
@@ -405,6 +839,315 @@ func (h *SignalingHub) handleCallEnd(msg *SignalingMessage) {
 	h.logger.Info("Call ended", zap.String("session_id", msg.SessionID))
 }
 
+// handleReconnecting tells the other participant of every waiting or active
+// call of the sender that the sender lost its connection and may come back,
+// so the UI can show a reconnecting indicator instead of ending the call
+func (h *SignalingHub) handleReconnecting(msg *SignalingMessage) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, session := range h.sessions {
+		if session.Status != "active" && session.Status != "waiting" {
+			continue
+		}
+		if msg.SessionID != "" && session.ID != msg.SessionID {
+			continue
+		}
+
+		var peerID int64
+		switch msg.From {
+		case session.ClientID:
+			peerID = session.SpecialistID
+		case session.SpecialistID:
+			peerID = session.ClientID
+		default:
+			continue
+		}
+
+		if peer, exists := h.clients[peerID]; exists {
+			h.sendMessageToClient(peer, &SignalingMessage{
+				Type:      "reconnecting",
+				SessionID: session.ID,
+				From:      msg.From,
+				To:        peerID,
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
+			h.logger.Info("Peer notified about reconnecting participant",
+				zap.String("session_id", session.ID),
+				zap.Int64("user_id", msg.From),
+				zap.Int64("peer_id", peerID))
+		}
+	}
+}
+
+// activeSessionBetween returns the active call session with the given ID if
+// the sender and the target of msg are its participants.
+// Must be called with the hub mutex held
+func (h *SignalingHub) activeSessionBetween(msg *SignalingMessage) *CallSession {
+	session, exists := h.sessions[msg.SessionID]
+	if !exists || session.Status != "active" {
+		return nil
+	}
+
+	if (msg.From == session.ClientID && msg.To == session.SpecialistID) ||
+		(msg.From == session.SpecialistID && msg.To == session.ClientID) {
+		return session
+	}
+	return nil
+}
+
+// handleRenegotiation forwards mid-call SDP renegotiation (e.g. to start
+// screen sharing) only within an active session between the two users
+func (h *SignalingHub) handleRenegotiation(msg *SignalingMessage) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if h.activeSessionBetween(msg) == nil {
+		h.dropOutOfSession(msg)
+		return
+	}
+
+	if targetClient, exists := h.clients[msg.To]; exists {
+		h.sendMessageToClient(targetClient, msg)
+	}
+}
+
+// handleMediaState caches the mute, camera and screen-share flags of the
+// sender on the session and forwards them to the peer
+func (h *SignalingHub) handleMediaState(msg *SignalingMessage) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	session := h.activeSessionBetween(msg)
+	if session == nil {
+		h.dropOutOfSession(msg)
+		return
+	}
+
+	var state MediaState
+	raw, err := json.Marshal(msg.Data)
+	if err == nil {
+		err = json.Unmarshal(raw, &state)
+	}
+	if err != nil {
+		h.logger.Warn("Invalid media-state data",
+			zap.String("session_id", msg.SessionID),
+			zap.Int64("from", msg.From),
+			zap.Error(err))
+		h.sendWarning(msg, "invalid_media_state")
+		return
+	}
+
+	state.UpdatedAt = time.Now()
+	if session.MediaState == nil {
+		session.MediaState = make(map[int64]MediaState)
+	}
+	session.MediaState[msg.From] = state
+
+	if targetClient, exists := h.clients[msg.To]; exists {
+		h.sendMessageToClient(targetClient, msg)
+	}
+}
+
+// dropOutOfSession drops an in-call message sent without an active session
+// between the two users. Must be called with the hub mutex held
+func (h *SignalingHub) dropOutOfSession(msg *SignalingMessage) {
+	h.logger.Warn("Dropped message outside of an active call session",
+		zap.String("type", msg.Type),
+		zap.String("session_id", msg.SessionID),
+		zap.Int64("from", msg.From),
+		zap.Int64("to", msg.To))
+	h.sendWarning(msg, "no_active_session")
+}
+
+// warnSender tells the sender that its message was dropped
+func (h *SignalingHub) warnSender(msg *SignalingMessage, reason string) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	h.sendWarning(msg, reason)
+}
+
+// sendWarning answers the sender with a "warning" naming the dropped message
+// type and the reason. Must be called with the hub mutex held
+func (h *SignalingHub) sendWarning(msg *SignalingMessage, reason string) {
+	if senderClient, exists := h.clients[msg.From]; exists {
+		h.sendMessageToClient(senderClient, &SignalingMessage{
+			Type:      "warning",
+			SessionID: msg.SessionID,
+			To:        msg.From,
+			Data:      map[string]string{"error": reason, "message_type": msg.Type},
+			Timestamp: time.Now().Format(time.RFC3339),
+		})
+	}
+}
+
+// awaitReconnect keeps a client whose connection dropped unexpectedly in the
+// clients map for the grace period, so its calls survive a network flap and
+// messages for it are buffered. Must be called with the hub mutex held
+func (h *SignalingHub) awaitReconnect(client *Client) {
+	client.disconnected = true
+	client.graceTimer = time.AfterFunc(h.config.ReconnectGracePeriod, func() {
+		select {
+		case h.expire <- client:
+		case <-h.done:
+		}
+	})
+
+	h.logger.Info("Waiting for client to reconnect",
+		zap.Int64("user_id", client.UserID),
+		zap.Duration("grace_period", h.config.ReconnectGracePeriod))
+}
+
+// deliverBuffered sends the messages collected during a reconnect to the new
+// connection. Must be called with the hub mutex held
+func (h *SignalingHub) deliverBuffered(client *Client, buffered [][]byte) {
+	if len(buffered) == 0 {
+		return
+	}
+
+	for _, data := range buffered {
+		select {
+		case client.Send <- data:
+		default:
+			client.DroppedMessagesTotal.Add(1)
+		}
+	}
+
+	h.logger.Info("Client reconnected, delivered buffered messages",
+		zap.Int64("user_id", client.UserID),
+		zap.Int("messages", len(buffered)))
+}
+
+// finalizeDisconnect removes the client, ends its waiting and active calls and
+// tells the other participants with "peer-disconnected".
+// Must be called with the hub mutex held
+func (h *SignalingHub) finalizeDisconnect(client *Client) {
+	delete(h.clients, client.UserID)
+	go h.touchLastSeen(client.UserID, true)
+
+	now := time.Now()
+	for _, session := range h.sessions {
+		if session.Status != "active" && session.Status != "waiting" {
+			continue
+		}
+
+		var peerID int64
+		switch client.UserID {
+		case session.ClientID:
+			peerID = session.SpecialistID
+		case session.SpecialistID:
+			peerID = session.ClientID
+		default:
+			continue
+		}
+
+		h.closeCallSegment(session, now)
+		session.Status = "ended"
+		session.EndedAt = &now
+		h.persistEndedCall(session)
+
+		if peer, exists := h.clients[peerID]; exists {
+			h.sendMessageToClient(peer, &SignalingMessage{
+				Type:      "peer-disconnected",
+				SessionID: session.ID,
+				From:      client.UserID,
+				To:        peerID,
+				Timestamp: now.Format(time.RFC3339),
+			})
+		}
+	}
+}
+
+// callDurationTimeout bounds persisting a call segment duration
+const callDurationTimeout = 5 * time.Second
+
+// closeCallSegment adds the time since the call was answered to the session
+// duration and persists it on the appointment of the call, so segments split
+// by reconnects add up. Must be called with the hub mutex held
+func (h *SignalingHub) closeCallSegment(session *CallSession, now time.Time) {
+	if session.AnsweredAt == nil {
+		return
+	}
+
+	seconds := int(now.Sub(*session.AnsweredAt) / time.Second)
+	session.AnsweredAt = nil
+	if seconds <= 0 {
+		return
+	}
+	session.DurationSeconds += seconds
+
+	if session.AppointmentID != nil {
+		go h.recordCallDuration(session.ID, *session.AppointmentID, seconds)
+	}
+}
+
+// recordCallDuration persists a finished call segment outside the hub goroutine
+func (h *SignalingHub) recordCallDuration(sessionID string, appointmentID int64, seconds int) {
+	ctx, cancel := context.WithTimeout(context.Background(), callDurationTimeout)
+	defer cancel()
+
+	if err := h.services.Appointment.RecordCallDuration(ctx, appointmentID, seconds); err != nil {
+		h.logger.Error("Failed to record call duration",
+			zap.String("session_id", sessionID),
+			zap.Int64("appointment_id", appointmentID),
+			zap.Int("seconds", seconds),
+			zap.Error(err))
+	}
+}
+
+// callRecordTimeout bounds persisting an ended call
+const callRecordTimeout = 5 * time.Second
+
+// persistEndedCall saves an ended call, answered or not, so that the pickup
+// rate of the specialist can be computed, and asks the participants of a
+// connected call to rate it. Must be called with the hub mutex held
+func (h *SignalingHub) persistEndedCall(session *CallSession) {
+	if session.EndedAt == nil {
+		return
+	}
+	go h.recordEndedCall(session.snapshot())
+}
+
+// recordEndedCall saves the call outside the hub goroutine and only then sends
+// "call-feedback-request" to both participants of a connected call, so feedback
+// submitted right away finds the call
+func (h *SignalingHub) recordEndedCall(session *CallSession) {
+	ctx, cancel := context.WithTimeout(context.Background(), callRecordTimeout)
+	defer cancel()
+
+	err := h.services.Call.RecordCall(ctx, domain.CallRecord{
+		ID:               session.ID,
+		ClientID:         session.ClientID,
+		SpecialistUserID: session.SpecialistID,
+		CallerID:         session.CallerID,
+		AppointmentID:    session.AppointmentID,
+		Answered:         session.Answered,
+		StartedAt:        session.CreatedAt,
+		EndedAt:          *session.EndedAt,
+		DurationSeconds:  session.DurationSeconds,
+	})
+	if err != nil {
+		h.logger.Error("Failed to record ended call",
+			zap.String("session_id", session.ID),
+			zap.Error(err))
+		return
+	}
+	if session.DurationSeconds <= 0 {
+		return
+	}
+
+	for _, userID := range []int64{session.ClientID, session.SpecialistID} {
+		h.NotifyUser(userID, &SignalingMessage{
+			Type:      "call-feedback-request",
+			SessionID: session.ID,
+			Data: map[string]interface{}{
+				"duration_seconds": session.DurationSeconds,
+			},
+		})
+	}
+}
+
 // handlePing processes ping messages for connection keepalive
 func (h *SignalingHub) handlePing(msg *SignalingMessage) {
 	h.mutex.RLock()
@@ -424,7 +1167,8 @@ func (h *SignalingHub) handlePing(msg *SignalingMessage) {
 }
 
 // sendMessageToClient sends a message to a specific client
-// NOTE: This function should only be called when the mutex is already held
+// NOTE: This function should only be called from the Run goroutine with the
+// mutex already held; the per-client drop counters rely on that serialization
 func (h *SignalingHub) sendMessageToClient(client *Client, msg *SignalingMessage) {
 	h.logger.Info("📤 [BACKEND] Attempting to send message to client", 
 		zap.String("message_type", msg.Type),
@@ -439,19 +1183,51 @@ func (h *SignalingHub) sendMessageToClient(client *Client, msg *SignalingMessage
 		return
 	}
 
+	// The user is reconnecting: keep the message until the new connection arrives
+	if client.disconnected {
+		if len(client.buffer) >= h.config.ReconnectBufferSize {
+			total := client.DroppedMessagesTotal.Add(1)
+			h.logger.Warn("Reconnect buffer full, dropping message",
+				zap.Int64("user_id", client.UserID),
+				zap.String("message_type", msg.Type),
+				zap.Int64("dropped_total", total))
+			return
+		}
+		client.buffer = append(client.buffer, data)
+		return
+	}
+
 	select {
 	case client.Send <- data:
+		client.consecutiveDrops = 0
 		h.logger.Info("✅ [BACKEND] Message sent successfully to client", 
 			zap.String("message_type", msg.Type),
 			zap.Int64("target_user_id", client.UserID),
 			zap.String("session_id", msg.SessionID))
 	default:
-		h.logger.Warn("❌ [BACKEND] Failed to send message - client channel full or closed", 
+		client.consecutiveDrops++
+		total := client.DroppedMessagesTotal.Add(1)
+		h.logger.Warn("❌ [BACKEND] Failed to send message - client channel full", 
 			zap.Int64("user_id", client.UserID),
-			zap.String("message_type", msg.Type))
-		// Don't modify the clients map here - let the cleanup happen in the main hub loop
-		// This prevents race conditions and deadlocks
-		// The client will be removed when the connection closes naturally
+			zap.String("message_type", msg.Type),
+			zap.Int("consecutive_drops", client.consecutiveDrops),
+			zap.Int64("dropped_total", total))
+
+		if client.consecutiveDrops >= h.config.MaxConsecutiveDrops && !client.evicting {
+			client.evicting = true
+			client.closeWith(ErrorSlowConsumer)
+			h.logger.Warn("Disconnecting slow client after consecutive dropped messages",
+				zap.Int64("user_id", client.UserID),
+				zap.Int("consecutive_drops", client.consecutiveDrops))
+			// Handlers run inside the Run loop, which is the only reader of
+			// unregister, so the request has to be sent asynchronously
+			go func() {
+				select {
+				case client.Hub.unregister <- client:
+				case <-client.Hub.done:
+				}
+			}()
+		}
 	}
 }
 
@@ -480,29 +1256,35 @@ func (h *SignalingHub) HandleWebSocket(c *gin.Context) {
 			zap.String("token_present", func() string {
 				if tokenStr != "" { return "yes" } else { return "no" }
 			}()))
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id and role required"})
+		h.rejectConnection(c, http.StatusUnauthorized, ErrorAuthExpired, "user_id and role required")
 		return
 	}
 	
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
 		h.logger.Warn("Invalid user_id format", zap.String("user_id", userIDStr))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id format"})
+		h.rejectConnection(c, http.StatusBadRequest, ErrorAuthExpired, "Invalid user_id format")
 		return
 	}
 	
 	role := domain.UserRole(roleStr)
 	if role != "client" && role != "specialist" {
 		h.logger.Warn("Invalid role", zap.String("role", roleStr))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		h.rejectConnection(c, http.StatusBadRequest, ErrorAuthExpired, "Invalid role")
 		return
 	}
 	
 	h.logger.Info("WebSocket connection authorized", zap.Int64("user_id", userID), zap.String("role", string(role)))
 
+	if !h.acquireConnection(userID) {
+		h.rejectConnection(c, http.StatusTooManyRequests, ErrorRateLimited, "too many connections")
+		return
+	}
+
 	// Upgrade connection to WebSocket
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
+		h.releaseConnection()
 		h.logger.Error("Failed to upgrade connection", zap.Error(err))
 		return
 	}
@@ -514,37 +1296,129 @@ func (h *SignalingHub) HandleWebSocket(c *gin.Context) {
 		Conn:   conn,
 		Send:   make(chan []byte, 256),
 		Hub:    h,
+
+		writerDone: make(chan struct{}),
+	}
+	if h.config.MessageRate > 0 {
+		client.limiter = rate.NewLimiter(rate.Limit(h.config.MessageRate), max(h.config.MessageBurst, 1))
 	}
 
-	// Register client
-	h.register <- client
+	// Register client. The writer is counted before the hub can see the
+	// client, so Shutdown never waits on the writers while one is being added
+	h.writers.Add(1)
+	select {
+	case h.register <- client:
+	case <-h.quit:
+		h.writers.Done()
+		writeClose(conn, ErrorServerShutdown)
+		conn.Close()
+		h.releaseConnection()
+		return
+	}
 
 	// Start goroutines for reading and writing
 	go client.writePump()
 	go client.readPump()
 }
 
+// rejectConnection refuses a connection before it is registered. Browsers
+// cannot read the HTTP status of a failed upgrade, so WebSocket requests are
+// upgraded and closed with the error frame and close code instead; plain HTTP
+// requests get the status and message as JSON
+func (h *SignalingHub) rejectConnection(c *gin.Context, status int, code ProtocolError, message string) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.Error("Failed to upgrade connection", zap.Error(err))
+		return
+	}
+	writeClose(conn, code)
+	conn.Close()
+}
+
+// closeWith records why the server closes the connection, unless a reason
+// is already set
+func (c *Client) closeWith(code ProtocolError) {
+	c.closeError.CompareAndSwap(nil, &code)
+}
+
+// recoverPanic must be deferred directly (recover only works there). It stops a
+// panic in a client goroutine from crashing the server; the pump's earlier
+// deferred cleanup then closes the connection as usual
+func (c *Client) recoverPanic(pump string) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	metrics.PanicsRecovered.WithLabelValues(metrics.PanicSourceWebSocket).Inc()
+	c.Hub.logger.Error("Recovered panic in WebSocket client",
+		zap.String("pump", pump),
+		zap.Int64("user_id", c.UserID),
+		zap.Any("panic", recovered),
+		zap.ByteString("stack", debug.Stack()))
+}
+
 // readPump pumps messages from the websocket connection to the hub
 func (c *Client) readPump() {
 	defer func() {
-		c.Hub.unregister <- c
+		select {
+		case c.Hub.unregister <- c:
+		case <-c.Hub.done:
+		}
+		// Let writePump send the final error frame before the connection goes away
+		if c.closeError.Load() != nil {
+			select {
+			case <-c.writerDone:
+			case <-time.After(writeWait):
+			}
+		}
 		c.Conn.Close()
+		c.Hub.releaseConnection()
 	}()
+	defer c.recoverPanic("readPump")
 
-	// Allow large SDP payloads and batches of ICE candidates (up to 10MB)
-	c.Conn.SetReadLimit(10 * 1024 * 1024)
 	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.Conn.SetPongHandler(func(string) error {
 		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 		return nil
 	})
 
+	// Waiting for the rate limiter stops once the hub drops the client or shuts down
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.writerDone:
+		case <-c.Hub.done:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
 	for {
-		_, message, err := c.Conn.ReadMessage()
+		message, err := c.readMessage()
+		if errors.Is(err, errMessageTooLarge) {
+			c.Hub.logger.Warn("WebSocket message too large",
+				zap.Int64("user_id", c.UserID),
+				zap.Int64("limit_bytes", c.Hub.config.MaxMessageSizeBytes))
+			c.closeWith(ErrorInvalidMessage)
+			break
+		}
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				c.Hub.logger.Error("WebSocket error", zap.Error(err))
 			}
+			c.reportReconnecting(err)
+			break
+		}
+
+		// Malformed messages count against the limit too
+		if err := c.throttle(ctx); err != nil {
 			break
 		}
 
@@ -552,21 +1426,127 @@ func (c *Client) readPump() {
 		var msg SignalingMessage
 		if err := json.Unmarshal(message, &msg); err != nil {
 			c.Hub.logger.Error("Failed to unmarshal message", zap.Error(err))
+			c.Hub.NotifyUser(c.UserID, protocolErrorReply(&SignalingMessage{From: c.UserID}, &validationError{
+				Code:    ProtocolErrorInvalidField,
+				Message: "message is not a valid signaling JSON object",
+			}))
+			continue
+		}
+		msg.From = c.UserID
+		if verr := validateMessage(&msg); verr != nil {
+			c.Hub.logger.Warn("Rejected invalid signaling message",
+				zap.Int64("user_id", c.UserID),
+				zap.String("type", msg.Type),
+				zap.String("code", verr.Code),
+				zap.String("field", verr.Field))
+			c.Hub.NotifyUser(c.UserID, protocolErrorReply(&msg, verr))
+			continue
+		}
+
+		// A client blocked by the specialist cannot start calls in either direction.
+		// The lookup blocks only this connection, never the hub loop
+		if (msg.Type == "call-invitation" || msg.Type == "call-offer") && c.Hub.isCallBlocked(&msg) {
+			c.Hub.rejectBlockedCall(&msg)
 			continue
 		}
 
+		// Calls are only allowed for a confirmed video appointment around its start time
+		if msg.Type == "call-invitation" || msg.Type == "call-offer" {
+			appointmentID, reason := c.Hub.checkCallAllowed(&msg)
+			if reason != "" {
+				c.Hub.rejectCall(&msg, map[string]string{"error": "call_not_allowed", "reason": reason})
+				continue
+			}
+			msg.appointmentID = appointmentID
+		}
+
+		c.touchLastSeen()
+
 		// Set sender info
-		msg.From = c.UserID
+		msg.Version = ProtocolVersion
 		msg.Timestamp = time.Now().Format(time.RFC3339)
 
-		// Re-marshal with corrected info
-		correctedMessage, err := json.Marshal(msg)
-		if err != nil {
-			c.Hub.logger.Error("Failed to marshal corrected message", zap.Error(err))
-			continue
+		select {
+		case c.Hub.broadcast <- &msg:
+		case <-c.Hub.done:
+			return
 		}
+	}
+}
 
-		c.Hub.broadcast <- correctedMessage
+// throttledWarnAfter is how many messages in a row have to wait for the rate
+// limiter before the client is reported as flooding
+const throttledWarnAfter = 5
+
+// throttle blocks until the client's rate limit lets the next message through.
+// It returns an error once ctx is cancelled because the client went away
+func (c *Client) throttle(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+
+	if c.limiter.Tokens() >= 1 {
+		c.consecutiveThrottled = 0
+	} else {
+		c.consecutiveThrottled++
+		if c.consecutiveThrottled == throttledWarnAfter {
+			c.Hub.logger.Warn("WebSocket client exceeds message rate limit",
+				zap.Int64("user_id", c.UserID),
+				zap.Float64("rate", c.Hub.config.MessageRate),
+				zap.Int("consecutive_throttled", c.consecutiveThrottled))
+		}
+	}
+
+	return c.limiter.Wait(ctx)
+}
+
+var errMessageTooLarge = errors.New("websocket message too large")
+
+// readMessage reads the next message, allowing large SDP payloads and batches
+// of ICE candidates (10MB by default). The size is checked here instead of
+// with SetReadLimit, which would close the connection itself before the
+// invalid_message error frame could be sent
+func (c *Client) readMessage() ([]byte, error) {
+	_, reader, err := c.Conn.NextReader()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := c.Hub.config.MaxMessageSizeBytes
+	if limit <= 0 {
+		return io.ReadAll(reader)
+	}
+
+	message, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(message)) > limit {
+		return nil, errMessageTooLarge
+	}
+	return message, nil
+}
+
+// reportReconnecting asks the hub to warn call peers about a connection lost
+// for a recoverable reason (network flap, read timeout). It runs before the
+// deferred unregister, so peers are notified while the client is still known.
+// Deliberate closes by the client or by the server are not reported
+func (c *Client) reportReconnecting(err error) {
+	if c.replaced.Load() || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+		return
+	}
+	c.lost.Store(true)
+
+	message := &SignalingMessage{
+		Type:      "reconnecting",
+		From:      c.UserID,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	select {
+	case c.Hub.broadcast <- message:
+	case <-c.Hub.quit:
+	case <-c.Hub.done:
 	}
 }
 
@@ -576,14 +1556,21 @@ func (c *Client) writePump() {
 	defer func() {
 		ticker.Stop()
 		c.Conn.Close()
+		close(c.writerDone)
+		c.Hub.writers.Done()
 	}()
+	defer c.recoverPanic("writePump")
 
 	for {
 		select {
 		case message, ok := <-c.Send:
 			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
-				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				if code := c.closeError.Load(); code != nil {
+					writeClose(c.Conn, *code)
+				} else {
+					c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				}
 				return
 			}
 
@@ -592,6 +1579,9 @@ func (c *Client) writePump() {
 				c.Hub.logger.Error("Failed to write message to WebSocket",
 					zap.Int64("user_id", c.UserID),
 					zap.Error(err))
+				// Usually the connection is already broken, but a failed write
+				// is still reported to the client if it can be
+				writeClose(c.Conn, ErrorInternal)
 				return
 			}
 		case <-ticker.C:
@@ -603,6 +1593,19 @@ func (c *Client) writePump() {
 	}
 }
 
+// snapshot returns a copy of the session so callers can read it after the
+// mutex is released while the hub keeps updating the original
+func (s *CallSession) snapshot() *CallSession {
+	session := *s
+	if s.MediaState != nil {
+		session.MediaState = make(map[int64]MediaState, len(s.MediaState))
+		for userID, state := range s.MediaState {
+			session.MediaState[userID] = state
+		}
+	}
+	return &session
+}
+
 // GetActiveSessions returns all active call sessions
 func (h *SignalingHub) GetActiveSessions() map[string]*CallSession {
 	h.mutex.RLock()
@@ -611,7 +1614,7 @@ func (h *SignalingHub) GetActiveSessions() map[string]*CallSession {
 	sessions := make(map[string]*CallSession)
 	for id, session := range h.sessions {
 		if session.Status == "active" || session.Status == "waiting" {
-			sessions[id] = session
+			sessions[id] = session.snapshot()
 		}
 	}
 	return sessions
@@ -635,7 +1638,7 @@ func (h *SignalingHub) GetActiveCallForUsers(userID1, userID2 int64) *CallSessio
 		if session.Status == "active" || session.Status == "waiting" {
 			if (session.ClientID == userID1 && session.SpecialistID == userID2) ||
 				(session.ClientID == userID2 && session.SpecialistID == userID1) {
-				return session
+				return session.snapshot()
 			}
 		}
 	}
@@ -649,7 +1652,7 @@ func (h *SignalingHub) GetActiveCallBySessionID(sessionID string) *CallSession {
 
 	if session, exists := h.sessions[sessionID]; exists {
 		if session.Status == "active" || session.Status == "waiting" {
-			return session
+			return session.snapshot()
 		}
 	}
 	return nil
@@ -664,7 +1667,7 @@ func (h *SignalingHub) GetAllActiveCallsForUser(userID int64) []*CallSession {
 	for _, session := range h.sessions {
 		if session.Status == "active" || session.Status == "waiting" {
 			if session.ClientID == userID || session.SpecialistID == userID {
-				activeCalls = append(activeCalls, session)
+				activeCalls = append(activeCalls, session.snapshot())
 			}
 		}
 	}
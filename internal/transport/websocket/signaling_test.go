@@ -0,0 +1,631 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/domain"
+	"laps/internal/service"
+)
+
+type fakeUserService struct {
+	service.UserService
+	touched atomic.Int64
+}
+
+func (f *fakeUserService) TouchLastSeen(ctx context.Context, userID int64, final bool) error {
+	f.touched.Add(1)
+	return nil
+}
+
+type fakeBlockListService struct {
+	service.BlockListService
+	blocked bool
+}
+
+func (f *fakeBlockListService) IsBlockedBetweenUsers(ctx context.Context, userA, userB int64) (bool, error) {
+	return f.blocked, nil
+}
+
+type fakeAppointmentService struct {
+	service.AppointmentService
+	callErr error
+	// durations receives recorded call segments when set
+	durations chan int
+}
+
+func (f *fakeAppointmentService) CheckCallAllowed(ctx context.Context, appointmentID, callerID, calleeID int64) error {
+	return f.callErr
+}
+
+func (f *fakeAppointmentService) RecordCallDuration(ctx context.Context, appointmentID int64, seconds int) error {
+	if f.durations != nil {
+		f.durations <- seconds
+	}
+	return nil
+}
+
+type fakeCallService struct {
+	service.CallService
+	// calls receives recorded calls when set
+	calls chan domain.CallRecord
+}
+
+func (f *fakeCallService) RecordCall(ctx context.Context, call domain.CallRecord) error {
+	if f.calls != nil {
+		f.calls <- call
+	}
+	return nil
+}
+
+func newTestServices() *service.Services {
+	return &service.Services{
+		User:        &fakeUserService{},
+		BlockList:   &fakeBlockListService{},
+		Appointment: &fakeAppointmentService{},
+		Call:        &fakeCallService{},
+	}
+}
+
+// startTestHub runs a hub behind a test server; the hub is shut down before
+// the server closes
+func startTestHub(t *testing.T, services *service.Services) (*SignalingHub, string) {
+	t.Helper()
+
+	return startTestHubWithConfig(t, services, config.WebSocketConfig{
+		MaxConsecutiveDrops: 1000,
+		ReconnectBufferSize: 100,
+	})
+}
+
+func startTestHubWithConfig(t *testing.T, services *service.Services, cfg config.WebSocketConfig) (*SignalingHub, string) {
+	t.Helper()
+
+	hub := NewSignalingHub(zap.NewNop(), services, cfg)
+	go hub.Run()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", hub.HandleWebSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := hub.Shutdown(ctx); err != nil {
+			t.Errorf("hub shutdown: %v", err)
+		}
+	})
+
+	return hub, "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+// dial connects a user and waits until the hub has registered the connection
+func dial(t *testing.T, hub *SignalingHub, url string, userID int64, role domain.UserRole) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("%s?user_id=%d&role=%s", url, userID, role), nil)
+	if err != nil {
+		t.Fatalf("dial user %d: %v", userID, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	waitFor(t, func() bool { return hub.IsUserConnected(userID) })
+	return conn
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg SignalingMessage) {
+	t.Helper()
+
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatalf("write %s: %v", msg.Type, err)
+	}
+}
+
+// readType reads messages until one of the given type arrives
+func readType(t *testing.T, conn *websocket.Conn, msgType string) SignalingMessage {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+	for {
+		var msg SignalingMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for %s: %v", msgType, err)
+		}
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+// startCall connects a client and a specialist and brings a call between them to the active state
+func startCall(t *testing.T, hub *SignalingHub, url, sessionID string) (client, specialist *websocket.Conn) {
+	t.Helper()
+
+	client = dial(t, hub, url, 1, domain.UserRole("client"))
+	specialist = dial(t, hub, url, 2, domain.UserRole("specialist"))
+
+	send(t, client, SignalingMessage{
+		Type:      "call-offer",
+		SessionID: sessionID,
+		To:        2,
+		Data:      map[string]interface{}{"sdp": "offer", "appointment_id": 10},
+	})
+	readType(t, specialist, "call-offer")
+
+	send(t, specialist, SignalingMessage{
+		Type:      "call-answer",
+		SessionID: sessionID,
+		To:        1,
+		Data:      map[string]interface{}{"sdp": "answer"},
+	})
+	readType(t, client, "call-answer")
+
+	return client, specialist
+}
+
+func TestHubCallLifecycle(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	client, specialist := startCall(t, hub, url, "session-1")
+
+	session := hub.GetActiveCallForUsers(1, 2)
+	if session == nil {
+		t.Fatal("no active call between the users")
+	}
+	if session.Status != "active" || session.ClientID != 1 || session.SpecialistID != 2 {
+		t.Errorf("session = %+v", session)
+	}
+	if session.AppointmentID == nil || *session.AppointmentID != 10 {
+		t.Errorf("appointment id = %v, want 10", session.AppointmentID)
+	}
+
+	send(t, client, SignalingMessage{Type: "call-end", SessionID: "session-1", To: 2})
+	readType(t, specialist, "call-end")
+
+	waitFor(t, func() bool { return hub.GetActiveCallBySessionID("session-1") == nil })
+}
+
+// TestHubConcurrentTraffic drives the hub from many goroutines at once; run
+// with -race to check that clients and sessions are only touched under the mutex
+func TestHubConcurrentTraffic(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	client, specialist := startCall(t, hub, url, "session-1")
+
+	const messages = 100
+	var candidates, mediaStates atomic.Int64
+
+	var readers sync.WaitGroup
+	drain := func(conn *websocket.Conn, counter *atomic.Int64, msgType string) {
+		defer readers.Done()
+		for {
+			var msg SignalingMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Type == msgType {
+				counter.Add(1)
+			}
+		}
+	}
+	readers.Add(2)
+	go drain(specialist, &candidates, "ice-candidate")
+	go drain(client, &mediaStates, "media-state")
+
+	var writers sync.WaitGroup
+	writers.Add(5)
+	go func() {
+		defer writers.Done()
+		for i := 0; i < messages; i++ {
+			client.WriteJSON(SignalingMessage{
+				Type:      "ice-candidate",
+				SessionID: "session-1",
+				To:        2,
+				Data:      map[string]interface{}{"candidate": fmt.Sprintf("candidate-%d", i)},
+			})
+		}
+	}()
+	go func() {
+		defer writers.Done()
+		for i := 0; i < messages; i++ {
+			specialist.WriteJSON(SignalingMessage{
+				Type:      "media-state",
+				SessionID: "session-1",
+				To:        1,
+				Data:      map[string]interface{}{"muted": i%2 == 0},
+			})
+		}
+	}()
+	go func() {
+		defer writers.Done()
+		for i := 0; i < messages; i++ {
+			hub.GetActiveSessions()
+			hub.GetActiveCallForUsers(1, 2)
+			hub.GetAllActiveCallsForUser(2)
+			hub.IsUserConnected(3)
+		}
+	}()
+	go func() {
+		defer writers.Done()
+		for i := 0; i < messages; i++ {
+			hub.Publish(1, "appointment.updated", map[string]int{"id": i})
+			hub.NotifySpecialist(2, &SignalingMessage{Type: "notification"})
+		}
+	}()
+	go func() {
+		defer writers.Done()
+		for i := 0; i < 10; i++ {
+			conn, _, err := websocket.DefaultDialer.Dial(url+"?user_id=3&role=client", nil)
+			if err != nil {
+				continue
+			}
+			conn.WriteJSON(SignalingMessage{Type: "ping"})
+			conn.Close()
+		}
+	}()
+	writers.Wait()
+
+	waitFor(t, func() bool { return candidates.Load() == messages && mediaStates.Load() == messages })
+
+	session := hub.GetActiveCallBySessionID("session-1")
+	if session == nil {
+		t.Fatal("call ended unexpectedly")
+	}
+	if state, ok := session.MediaState[2]; !ok || state.Muted {
+		t.Errorf("media state of the specialist = %+v, want the last one (unmuted)", session.MediaState[2])
+	}
+
+	client.Close()
+	specialist.Close()
+	readers.Wait()
+}
+
+func TestHubDropsMessagesOutsideSession(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	client := dial(t, hub, url, 1, domain.UserRole("client"))
+	dial(t, hub, url, 2, domain.UserRole("specialist"))
+
+	send(t, client, SignalingMessage{
+		Type:      "media-state",
+		SessionID: "missing",
+		To:        2,
+		Data:      map[string]interface{}{"muted": true},
+	})
+
+	warning := readType(t, client, "warning")
+	data, _ := json.Marshal(warning.Data)
+	if !strings.Contains(string(data), "no_active_session") {
+		t.Errorf("warning data = %s, want no_active_session", data)
+	}
+}
+
+func TestHubRejectsBlockedCall(t *testing.T) {
+	services := newTestServices()
+	services.BlockList = &fakeBlockListService{blocked: true}
+	hub, url := startTestHub(t, services)
+	client := dial(t, hub, url, 1, domain.UserRole("client"))
+	specialist := dial(t, hub, url, 2, domain.UserRole("specialist"))
+
+	send(t, client, SignalingMessage{
+		Type:      "call-offer",
+		SessionID: "session-1",
+		To:        2,
+		Data:      map[string]interface{}{"sdp": "offer", "appointment_id": 10},
+	})
+
+	reply := readType(t, client, "call-error")
+	if reply.SessionID != "session-1" || reply.From != 2 {
+		t.Errorf("call-error = %+v", reply)
+	}
+	data, _ := json.Marshal(reply.Data)
+	if string(data) != `{"error":"blocked"}` {
+		t.Errorf("call-error data = %s", data)
+	}
+
+	if hub.GetActiveCallBySessionID("session-1") != nil {
+		t.Error("blocked call created a session")
+	}
+
+	// The callee must not learn about the call: the next thing it gets is the pong
+	send(t, specialist, SignalingMessage{Type: "ping"})
+	specialist.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg SignalingMessage
+	if err := specialist.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "pong" {
+		t.Errorf("callee got %q before pong", msg.Type)
+	}
+}
+
+func TestHubRejectsCallNotAllowed(t *testing.T) {
+	services := newTestServices()
+	services.Appointment = &fakeAppointmentService{
+		callErr: &service.CallNotAllowedError{Reason: domain.CallNotAllowedTooEarly},
+	}
+	hub, url := startTestHub(t, services)
+	client := dial(t, hub, url, 1, domain.UserRole("client"))
+	dial(t, hub, url, 2, domain.UserRole("specialist"))
+
+	send(t, client, SignalingMessage{
+		Type:      "call-invitation",
+		SessionID: "session-1",
+		To:        2,
+		Data:      map[string]interface{}{"appointment_id": "10"},
+	})
+
+	reply := readType(t, client, "call-error")
+	data, _ := json.Marshal(reply.Data)
+	want := fmt.Sprintf(`{"error":"call_not_allowed","reason":%q}`, domain.CallNotAllowedTooEarly)
+	if string(data) != want {
+		t.Errorf("call-error data = %s, want %s", data, want)
+	}
+}
+
+func TestHubRepliesWithProtocolError(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	client := dial(t, hub, url, 1, domain.UserRole("client"))
+
+	send(t, client, SignalingMessage{Type: "call-offer", SessionID: "session-1", To: 2})
+
+	reply := readType(t, client, "protocol-error")
+	data, _ := reply.Data.(map[string]interface{})
+	if data["code"] != ProtocolErrorMissingField || data["field"] != "data" || data["message_type"] != "call-offer" {
+		t.Errorf("protocol-error data = %v", data)
+	}
+
+	if err := client.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+	reply = readType(t, client, "protocol-error")
+	data, _ = reply.Data.(map[string]interface{})
+	if data["code"] != ProtocolErrorInvalidField {
+		t.Errorf("protocol-error data = %v", data)
+	}
+}
+
+// Only the server announces that a user is reconnecting; a client claiming it
+// about itself must not reach the peers
+func TestHubRejectsClientReconnecting(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	client := dial(t, hub, url, 1, domain.UserRole("client"))
+	specialist := dial(t, hub, url, 2, domain.UserRole("specialist"))
+
+	send(t, client, SignalingMessage{Type: "reconnecting", To: 2})
+
+	reply := readType(t, client, "protocol-error")
+	data, _ := reply.Data.(map[string]interface{})
+	if data["code"] != ProtocolErrorUnknownType || data["message_type"] != "reconnecting" {
+		t.Errorf("protocol-error data = %v", data)
+	}
+
+	// The reply to the ping is queued after anything forwarded from the client
+	send(t, specialist, SignalingMessage{Type: "ping"})
+	specialist.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg SignalingMessage
+		if err := specialist.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for pong: %v", err)
+		}
+		if msg.Type == "reconnecting" {
+			t.Fatalf("client-sent reconnecting reached the specialist: %+v", msg)
+		}
+		if msg.Type == "pong" {
+			break
+		}
+	}
+}
+
+func TestHubKeepsCallDuringReconnect(t *testing.T) {
+	hub, url := startTestHubWithConfig(t, newTestServices(), config.WebSocketConfig{
+		MaxConsecutiveDrops:  1000,
+		ReconnectGracePeriod: 5 * time.Second,
+		ReconnectBufferSize:  10,
+	})
+	client, specialist := startCall(t, hub, url, "session-1")
+
+	// Drop the connection without a close frame, as a network flap does
+	client.UnderlyingConn().Close()
+
+	notice := readType(t, specialist, "reconnecting")
+	if notice.SessionID != "session-1" || notice.From != 1 {
+		t.Errorf("reconnecting = %+v", notice)
+	}
+
+	send(t, specialist, SignalingMessage{
+		Type:      "ice-candidate",
+		SessionID: "session-1",
+		To:        1,
+		Data:      map[string]interface{}{"candidate": "buffered"},
+	})
+
+	reconnected := dial(t, hub, url, 1, domain.UserRole("client"))
+	candidate := readType(t, reconnected, "ice-candidate")
+	data, _ := candidate.Data.(map[string]interface{})
+	if data["candidate"] != "buffered" {
+		t.Errorf("buffered candidate = %v", data)
+	}
+
+	if session := hub.GetActiveCallBySessionID("session-1"); session == nil || session.Status != "active" {
+		t.Errorf("session after reconnect = %+v, want active", session)
+	}
+}
+
+func TestHubEndsCallAfterGracePeriod(t *testing.T) {
+	hub, url := startTestHubWithConfig(t, newTestServices(), config.WebSocketConfig{
+		MaxConsecutiveDrops:  1000,
+		ReconnectGracePeriod: 100 * time.Millisecond,
+		ReconnectBufferSize:  10,
+	})
+	client, specialist := startCall(t, hub, url, "session-1")
+
+	client.UnderlyingConn().Close()
+
+	notice := readType(t, specialist, "peer-disconnected")
+	if notice.SessionID != "session-1" || notice.From != 1 {
+		t.Errorf("peer-disconnected = %+v", notice)
+	}
+	if hub.IsUserConnected(1) {
+		t.Error("user is still connected after the grace period")
+	}
+	if hub.GetActiveCallBySessionID("session-1") != nil {
+		t.Error("call is still active after the grace period")
+	}
+}
+
+func TestHubForwardsRenegotiationInActiveCall(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	client, specialist := startCall(t, hub, url, "session-1")
+	dial(t, hub, url, 3, domain.UserRole("client"))
+
+	send(t, client, SignalingMessage{
+		Type:      "renegotiate-offer",
+		SessionID: "session-1",
+		To:        2,
+		Data:      map[string]interface{}{"sdp": "screen"},
+	})
+	offer := readType(t, specialist, "renegotiate-offer")
+	if data, _ := offer.Data.(map[string]interface{}); data["sdp"] != "screen" {
+		t.Errorf("renegotiate-offer data = %v", offer.Data)
+	}
+
+	// The session exists, but user 3 is not part of it
+	send(t, client, SignalingMessage{
+		Type:      "renegotiate-offer",
+		SessionID: "session-1",
+		To:        3,
+		Data:      map[string]interface{}{"sdp": "screen"},
+	})
+	warning := readType(t, client, "warning")
+	if data, _ := warning.Data.(map[string]interface{}); data["error"] != "no_active_session" || data["message_type"] != "renegotiate-offer" {
+		t.Errorf("warning data = %v", warning.Data)
+	}
+
+	send(t, specialist, SignalingMessage{
+		Type:      "media-state",
+		SessionID: "session-1",
+		To:        1,
+		Data:      map[string]interface{}{"screen_sharing": true},
+	})
+	readType(t, client, "media-state")
+
+	session := hub.GetActiveCallBySessionID("session-1")
+	if session == nil || !session.MediaState[2].ScreenSharing {
+		t.Errorf("media state = %+v, want screen sharing of the specialist", session)
+	}
+}
+
+func TestHubRecordsCallDuration(t *testing.T) {
+	appointments := &fakeAppointmentService{durations: make(chan int, 1)}
+	calls := &fakeCallService{calls: make(chan domain.CallRecord, 1)}
+	services := newTestServices()
+	services.Appointment = appointments
+	services.Call = calls
+	hub, url := startTestHub(t, services)
+	client, specialist := startCall(t, hub, url, "session-1")
+
+	// Durations are counted in whole seconds
+	time.Sleep(1100 * time.Millisecond)
+	send(t, client, SignalingMessage{Type: "call-end", SessionID: "session-1", To: 2})
+
+	select {
+	case seconds := <-appointments.durations:
+		if seconds < 1 {
+			t.Errorf("recorded duration = %d, want at least 1", seconds)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("call duration was not recorded on the appointment")
+	}
+
+	select {
+	case call := <-calls.calls:
+		if call.ID != "session-1" || call.ClientID != 1 || call.SpecialistUserID != 2 {
+			t.Errorf("recorded call = %+v", call)
+		}
+		if call.AppointmentID == nil || *call.AppointmentID != 10 {
+			t.Errorf("recorded call appointment = %v, want 10", call.AppointmentID)
+		}
+		if call.DurationSeconds < 1 {
+			t.Errorf("recorded call duration = %d", call.DurationSeconds)
+		}
+		if !call.Answered || call.CallerID != 1 {
+			t.Errorf("recorded call answered = %v, caller = %d; want answered call placed by the client", call.Answered, call.CallerID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ended call was not recorded")
+	}
+
+	readType(t, client, "call-feedback-request")
+	readType(t, specialist, "call-feedback-request")
+}
+
+// A rejected call is persisted too, so that it counts against the pickup rate
+func TestHubRecordsRejectedCall(t *testing.T) {
+	calls := &fakeCallService{calls: make(chan domain.CallRecord, 1)}
+	services := newTestServices()
+	services.Call = calls
+	hub, url := startTestHub(t, services)
+	client := dial(t, hub, url, 1, domain.UserRole("client"))
+	specialist := dial(t, hub, url, 2, domain.UserRole("specialist"))
+
+	send(t, client, SignalingMessage{
+		Type:      "call-offer",
+		SessionID: "session-1",
+		To:        2,
+		Data:      map[string]interface{}{"sdp": "offer", "appointment_id": 10},
+	})
+	readType(t, specialist, "call-offer")
+	send(t, specialist, SignalingMessage{Type: "call-reject", SessionID: "session-1", To: 1})
+	readType(t, client, "call-reject")
+
+	select {
+	case call := <-calls.calls:
+		if call.ID != "session-1" || call.Answered || call.CallerID != 1 || call.DurationSeconds != 0 {
+			t.Errorf("recorded call = %+v, want an unanswered call placed by the client", call)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("rejected call was not recorded")
+	}
+	if hub.GetActiveCallBySessionID("session-1") != nil {
+		t.Error("rejected call is still active")
+	}
+}
+
+func TestHubNotifiesSpecialistOfNewAppointment(t *testing.T) {
+	hub, url := startTestHub(t, newTestServices())
+	specialist := dial(t, hub, url, 2, domain.UserRole("specialist"))
+
+	// An offline specialist is skipped without blocking the booking
+	hub.Publish(5, service.RealtimeEventNewAppointment, map[string]interface{}{"appointment_id": 1})
+
+	hub.Publish(2, service.RealtimeEventNewAppointment, map[string]interface{}{"appointment_id": 2, "client_name": "Анна"})
+	msg := readType(t, specialist, service.RealtimeEventNewAppointment)
+	data, _ := msg.Data.(map[string]interface{})
+	if msg.To != 2 || msg.Timestamp == "" || data["appointment_id"] != float64(2) || data["client_name"] != "Анна" {
+		t.Errorf("notification = %+v, want appointment 2 for the specialist", msg)
+	}
+}
@@ -0,0 +1,138 @@
+package websocket
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProtocolVersion is the signaling protocol version spoken by the server.
+// Clients send it in the "version" field of every message; messages without
+// it are treated as version 1, which predates the field
+const ProtocolVersion = 1
+
+// supportedVersions lists the protocol versions the server accepts
+var supportedVersions = map[int]bool{1: true}
+
+// Codes carried in "protocol-error" replies
+const (
+	ProtocolErrorUnsupportedVersion = "unsupported_version"
+	ProtocolErrorUnknownType        = "unknown_message_type"
+	ProtocolErrorMissingField       = "missing_field"
+	ProtocolErrorInvalidField       = "invalid_field"
+)
+
+// messageRule describes the fields a message type must carry. dataFields are
+// keys required in the data object; emptyAllowed lists those of them that may
+// hold an empty string (an empty ICE candidate marks the end of candidates)
+type messageRule struct {
+	session      bool
+	target       bool
+	data         bool
+	dataFields   []string
+	emptyAllowed map[string]bool
+}
+
+// messageRules lists every message type a client may send. "reconnecting" is
+// not one of them: only the server raises it when a connection drops
+var messageRules = map[string]messageRule{
+	"call-invitation":    {session: true, target: true},
+	"call-offer":         {session: true, target: true, data: true, dataFields: []string{"sdp"}},
+	"call-answer":        {session: true, target: true, data: true, dataFields: []string{"sdp"}},
+	"renegotiate-offer":  {session: true, target: true, data: true, dataFields: []string{"sdp"}},
+	"renegotiate-answer": {session: true, target: true, data: true, dataFields: []string{"sdp"}},
+	"ice-candidate": {
+		session: true, target: true, data: true, dataFields: []string{"candidate"},
+		emptyAllowed: map[string]bool{"candidate": true},
+	},
+	"call-reject": {session: true, target: true},
+	"call-end":    {session: true, target: true},
+	"media-state": {session: true, target: true, data: true},
+	"ping":        {},
+}
+
+// validationError explains why a client message was rejected
+type validationError struct {
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *validationError) Error() string {
+	return e.Message
+}
+
+// validateMessage checks the protocol version and the fields required by the
+// message type. It returns nil for a valid message
+func validateMessage(msg *SignalingMessage) *validationError {
+	version := msg.Version
+	if version == 0 {
+		version = 1
+	}
+	if !supportedVersions[version] {
+		return &validationError{
+			Code:    ProtocolErrorUnsupportedVersion,
+			Field:   "version",
+			Message: fmt.Sprintf("protocol version %d is not supported, server speaks version %d", msg.Version, ProtocolVersion),
+		}
+	}
+
+	rule, ok := messageRules[msg.Type]
+	if !ok {
+		if msg.Type == "" {
+			return &validationError{Code: ProtocolErrorMissingField, Field: "type", Message: "message type is required"}
+		}
+		return &validationError{Code: ProtocolErrorUnknownType, Field: "type", Message: fmt.Sprintf("unknown message type %q", msg.Type)}
+	}
+
+	if rule.session && msg.SessionID == "" {
+		return &validationError{Code: ProtocolErrorMissingField, Field: "session_id", Message: msg.Type + " requires session_id"}
+	}
+	if rule.target && msg.To <= 0 {
+		return &validationError{Code: ProtocolErrorMissingField, Field: "to", Message: msg.Type + " requires the recipient user ID in to"}
+	}
+	if !rule.data {
+		return nil
+	}
+
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		if msg.Data == nil {
+			return &validationError{Code: ProtocolErrorMissingField, Field: "data", Message: msg.Type + " requires data"}
+		}
+		return &validationError{Code: ProtocolErrorInvalidField, Field: "data", Message: msg.Type + " data must be an object"}
+	}
+
+	for _, field := range rule.dataFields {
+		value, exists := data[field]
+		if !exists || value == nil {
+			return &validationError{Code: ProtocolErrorMissingField, Field: "data." + field, Message: fmt.Sprintf("%s requires data.%s", msg.Type, field)}
+		}
+		text, isString := value.(string)
+		if !isString {
+			return &validationError{Code: ProtocolErrorInvalidField, Field: "data." + field, Message: fmt.Sprintf("data.%s must be a string", field)}
+		}
+		if text == "" && !rule.emptyAllowed[field] {
+			return &validationError{Code: ProtocolErrorMissingField, Field: "data." + field, Message: fmt.Sprintf("data.%s must not be empty", field)}
+		}
+	}
+
+	return nil
+}
+
+// protocolErrorReply builds the "protocol-error" sent back to the sender of a
+// rejected message
+func protocolErrorReply(msg *SignalingMessage, verr *validationError) *SignalingMessage {
+	return &SignalingMessage{
+		Type:      "protocol-error",
+		Version:   ProtocolVersion,
+		SessionID: msg.SessionID,
+		To:        msg.From,
+		Data: map[string]interface{}{
+			"code":         verr.Code,
+			"field":        verr.Field,
+			"message":      verr.Message,
+			"message_type": msg.Type,
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
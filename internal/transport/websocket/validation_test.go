@@ -0,0 +1,114 @@
+package websocket
+
+import "testing"
+
+func TestValidateMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       SignalingMessage
+		wantCode  string
+		wantField string
+	}{
+		{
+			name: "ping without version",
+			msg:  SignalingMessage{Type: "ping"},
+		},
+		{
+			name: "offer",
+			msg: SignalingMessage{Type: "call-offer", Version: 1, SessionID: "s", To: 2,
+				Data: map[string]interface{}{"sdp": "v=0"}},
+		},
+		{
+			name: "end of ice candidates",
+			msg: SignalingMessage{Type: "ice-candidate", SessionID: "s", To: 2,
+				Data: map[string]interface{}{"candidate": ""}},
+		},
+		{
+			name:      "unsupported version",
+			msg:       SignalingMessage{Type: "ping", Version: 2},
+			wantCode:  ProtocolErrorUnsupportedVersion,
+			wantField: "version",
+		},
+		{
+			name:      "missing type",
+			msg:       SignalingMessage{},
+			wantCode:  ProtocolErrorMissingField,
+			wantField: "type",
+		},
+		{
+			name:      "unknown type",
+			msg:       SignalingMessage{Type: "call-transfer"},
+			wantCode:  ProtocolErrorUnknownType,
+			wantField: "type",
+		},
+		{
+			name:      "reconnecting is server-only",
+			msg:       SignalingMessage{Type: "reconnecting"},
+			wantCode:  ProtocolErrorUnknownType,
+			wantField: "type",
+		},
+		{
+			name:      "missing session",
+			msg:       SignalingMessage{Type: "call-end", To: 2},
+			wantCode:  ProtocolErrorMissingField,
+			wantField: "session_id",
+		},
+		{
+			name:      "missing recipient",
+			msg:       SignalingMessage{Type: "call-end", SessionID: "s"},
+			wantCode:  ProtocolErrorMissingField,
+			wantField: "to",
+		},
+		{
+			name:      "missing data",
+			msg:       SignalingMessage{Type: "call-answer", SessionID: "s", To: 2},
+			wantCode:  ProtocolErrorMissingField,
+			wantField: "data",
+		},
+		{
+			name:      "data is not an object",
+			msg:       SignalingMessage{Type: "call-answer", SessionID: "s", To: 2, Data: "v=0"},
+			wantCode:  ProtocolErrorInvalidField,
+			wantField: "data",
+		},
+		{
+			name: "missing sdp",
+			msg: SignalingMessage{Type: "call-offer", SessionID: "s", To: 2,
+				Data: map[string]interface{}{}},
+			wantCode:  ProtocolErrorMissingField,
+			wantField: "data.sdp",
+		},
+		{
+			name: "sdp is not a string",
+			msg: SignalingMessage{Type: "call-offer", SessionID: "s", To: 2,
+				Data: map[string]interface{}{"sdp": 1.0}},
+			wantCode:  ProtocolErrorInvalidField,
+			wantField: "data.sdp",
+		},
+		{
+			name: "empty sdp",
+			msg: SignalingMessage{Type: "renegotiate-offer", SessionID: "s", To: 2,
+				Data: map[string]interface{}{"sdp": ""}},
+			wantCode:  ProtocolErrorMissingField,
+			wantField: "data.sdp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verr := validateMessage(&tt.msg)
+			if tt.wantCode == "" {
+				if verr != nil {
+					t.Fatalf("validateMessage() = %+v, want nil", verr)
+				}
+				return
+			}
+			if verr == nil {
+				t.Fatalf("validateMessage() = nil, want %s", tt.wantCode)
+			}
+			if verr.Code != tt.wantCode || verr.Field != tt.wantField {
+				t.Errorf("validateMessage() = %s/%s, want %s/%s", verr.Code, verr.Field, tt.wantCode, tt.wantField)
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,12 +16,16 @@ import (
 
 	"laps/config"
 	_ "laps/docs"
+	"laps/internal/cache"
+	"laps/internal/health"
+	"laps/internal/ratelimit"
 	"laps/internal/repository"
 	"laps/internal/service"
 	"laps/internal/storage"
 	"laps/internal/transport/rest"
 	"laps/internal/transport/websocket"
 	"laps/pkg/database"
+	"laps/pkg/tracing"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -44,6 +50,9 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	rollback := flag.Bool("rollback", false, "откатить последнюю миграцию и завершить работу")
+	flag.Parse()
+
 	logger, err := zap.NewProduction()
 	if err != nil {
 		panic(err)
@@ -55,12 +64,29 @@ func main() {
 		logger.Fatal("Не удалось загрузить конфигурацию", zap.Error(err))
 	}
 
-	db, err := database.NewPostgresDB(cfg.Postgres)
+	shutdownTracing, err := tracing.NewTracerProvider(context.Background(), cfg.Tracing, cfg.Name, cfg.Version)
+	if err != nil {
+		logger.Fatal("Не удалось инициализировать трассировку", zap.Error(err))
+	}
+	if cfg.Tracing.OTLPEndpoint != "" {
+		logger.Info("Трассировка OpenTelemetry включена", zap.String("endpoint", cfg.Tracing.OTLPEndpoint))
+	}
+
+	db, err := database.NewPostgresDB(cfg.Postgres, logger)
 	if err != nil {
 		logger.Fatal("Не удалось подключиться к БД", zap.Error(err))
 	}
 	defer db.Close()
 
+	if *rollback {
+		if err := database.RollbackMigration(db, "./migrations", logger); err != nil {
+			logger.Fatal("Ошибка при откате миграции", zap.Error(err))
+		}
+		db.Close()
+		logger.Sync()
+		os.Exit(0)
+	}
+
 	logger.Info("Запуск миграций базы данных")
 	if err := database.RunMigrations(db, "./migrations", logger); err != nil {
 		logger.Fatal("Ошибка при выполнении миграций", zap.Error(err))
@@ -81,6 +107,17 @@ func main() {
 		// В данном случае просто пропускаем
 	}
 
+	responseCache, err := cache.New(cfg.Cache)
+	if err != nil {
+		logger.Fatal("Не удалось инициализировать кэш", zap.Error(err))
+	}
+	logger.Info("Кэш ответов инициализирован", zap.String("driver", cfg.Cache.Driver))
+
+	rateLimiter, err := ratelimit.New(cfg.RateLimit)
+	if err != nil {
+		logger.Fatal("Не удалось инициализировать ограничение запросов", zap.Error(err))
+	}
+
 	repos := repository.NewRepositories(db)
 
 	services := service.NewServices(service.Deps{
@@ -88,15 +125,61 @@ func main() {
 		Logger:      logger,
 		Config:      cfg,
 		FileStorage: fileStorage,
+		Cache:       responseCache,
 	})
 
 	// Initialize WebSocket signaling hub
-	signalingHub := websocket.NewSignalingHub(logger, services)
+	signalingHub := websocket.NewSignalingHub(logger, services, cfg.WebSocket)
 	go signalingHub.Run()
+	services.Realtime.Subscribe(signalingHub)
+
+	// Фоновые задачи останавливаются при выключении сервера; пул БД закрывается
+	// только после того, как все они завершились
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	runJob := func(job func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			job(jobsCtx)
+		}()
+	}
+
+	// Периодическая запись состояния пула подключений к БД для планирования нагрузки
+	runJob(func(ctx context.Context) { database.LogPoolStats(ctx, db, logger) })
+
+	// Периодический импорт внешних календарей специалистов
+	runJob(func(ctx context.Context) { services.Calendar.RunSync(ctx, cfg.Calendar.RefreshInterval) })
+
+	// Еженедельное копирование расписаний специалистов на следующую неделю
+	runJob(services.Schedule.RunWeeklyClone)
 
-	handler := rest.NewHandler(services, logger, cfg, signalingHub)
+	// Удаление истекших удержаний слотов
+	runJob(services.Appointment.RunHoldPurge)
 
-	router := gin.Default()
+	// Завершение консультаций, состоявшихся по видеозвонку
+	runJob(services.Appointment.RunCallCompletion)
+
+	// Просьбы оставить отзыв после завершенных консультаций
+	runJob(services.Appointment.RunReviewRequests)
+
+	// Отправка событий записей во внешние системы
+	runJob(services.Webhook.RunDispatcher)
+
+	readinessChecks := []health.Check{
+		{Name: "postgres", Run: db.Ping},
+	}
+	if fileStorage != nil {
+		readinessChecks = append(readinessChecks, health.Check{Name: "s3", Run: fileStorage.Ping})
+	}
+	if pinger, ok := responseCache.(cache.Pinger); ok {
+		readinessChecks = append(readinessChecks, health.Check{Name: "redis", Run: pinger.Ping})
+	}
+
+	handler := rest.NewHandler(services, logger, cfg, signalingHub, rateLimiter, readinessChecks)
+
+	// Вместо gin.Recovery паники перехватывает recoveryMiddleware, логи запросов пишет loggerMiddleware
+	router := gin.New()
 
 	handler.InitRoutes(router)
 
@@ -129,6 +212,8 @@ func main() {
 	<-quit
 	logger.Info("Выключение сервера...")
 
+	stopJobs()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -136,5 +221,25 @@ func main() {
 		logger.Fatal("Ошибка при остановке сервера", zap.Error(err))
 	}
 
+	if err := signalingHub.Shutdown(ctx); err != nil {
+		logger.Error("Ошибка при остановке сигнального хаба", zap.Error(err))
+	}
+
+	// Задачи, уже получившие отмену, дорабатывают текущую итерацию до закрытия пула БД
+	jobsDone := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(jobsDone)
+	}()
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		logger.Error("Фоновые задачи не завершились до закрытия БД", zap.Error(ctx.Err()))
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Ошибка при остановке трассировки", zap.Error(err))
+	}
+
 	logger.Info("Сервер успешно остановлен")
 }
@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS update_specialist_rating_delete_trigger ON reviews;
+DROP TRIGGER IF EXISTS update_specialist_rating_trigger ON reviews;
+DROP FUNCTION IF EXISTS update_specialist_rating();
+
+ALTER TABLE IF EXISTS reviews DROP CONSTRAINT IF EXISTS fk_reviews_reply_id;
+
+DROP TABLE IF EXISTS review_replies;
+DROP TABLE IF EXISTS reviews;
+DROP TABLE IF EXISTS appointments;
+DROP TABLE IF EXISTS work_experience;
+DROP TABLE IF EXISTS education;
+DROP TABLE IF EXISTS specialist_specializations;
+DROP TABLE IF EXISTS specialists;
+DROP TABLE IF EXISTS specializations;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
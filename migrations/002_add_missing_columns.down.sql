@@ -0,0 +1,2 @@
+ALTER TABLE specialists DROP COLUMN IF EXISTS experience_years;
+ALTER TABLE specialists DROP COLUMN IF EXISTS description;
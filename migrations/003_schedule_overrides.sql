@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS schedule_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    specialist_id BIGINT NOT NULL REFERENCES specialists(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    is_day_off BOOLEAN NOT NULL DEFAULT false,
+    start_time VARCHAR(5),
+    end_time VARCHAR(5),
+    slot_time INT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (specialist_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_overrides_specialist_date ON schedule_overrides(specialist_id, date);
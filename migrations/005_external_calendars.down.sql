@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS external_busy_blocks;
+DROP TABLE IF EXISTS external_calendar_feeds;
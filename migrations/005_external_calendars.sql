@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS external_calendar_feeds (
+    specialist_id BIGINT PRIMARY KEY REFERENCES specialists(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    last_synced_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS external_busy_blocks (
+    id BIGSERIAL PRIMARY KEY,
+    specialist_id BIGINT NOT NULL REFERENCES specialists(id) ON DELETE CASCADE,
+    uid VARCHAR(255) NOT NULL DEFAULT '',
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    imported_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_busy_blocks_specialist_time ON external_busy_blocks(specialist_id, starts_at, ends_at);
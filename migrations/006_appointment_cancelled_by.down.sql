@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_appointments_specialist_created;
+
+ALTER TABLE appointments DROP COLUMN IF EXISTS cancelled_by;
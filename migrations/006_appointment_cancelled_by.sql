@@ -0,0 +1,4 @@
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS cancelled_by VARCHAR(20)
+    CHECK (cancelled_by IN ('client', 'specialist', 'admin'));
+
+CREATE INDEX IF NOT EXISTS idx_appointments_specialist_created ON appointments(specialist_id, created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS blocked_clients (
+    id BIGSERIAL PRIMARY KEY,
+    specialist_id BIGINT NOT NULL REFERENCES specialists(id) ON DELETE CASCADE,
+    client_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (specialist_id, client_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blocked_clients_client_id ON blocked_clients(client_id);
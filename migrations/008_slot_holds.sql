@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS slot_holds (
+    id BIGSERIAL PRIMARY KEY,
+    client_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    specialist_id BIGINT NOT NULL REFERENCES specialists(id) ON DELETE CASCADE,
+    slot_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_slot_holds_specialist_slot ON slot_holds(specialist_id, slot_at);
+CREATE INDEX IF NOT EXISTS idx_slot_holds_client_id ON slot_holds(client_id);
+CREATE INDEX IF NOT EXISTS idx_slot_holds_expires_at ON slot_holds(expires_at);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS specialist_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    specialist_id BIGINT NOT NULL REFERENCES specialists(id) ON DELETE CASCADE,
+    old_primary_price DECIMAL(10, 2) NOT NULL,
+    new_primary_price DECIMAL(10, 2) NOT NULL,
+    old_secondary_price DECIMAL(10, 2) NOT NULL,
+    new_secondary_price DECIMAL(10, 2) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    changed_by_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_specialist_price_history_specialist ON specialist_price_history(specialist_id, changed_at DESC);
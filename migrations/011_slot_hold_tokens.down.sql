@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_slot_holds_token;
+
+ALTER TABLE slot_holds DROP COLUMN IF EXISTS token;
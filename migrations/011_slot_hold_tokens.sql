@@ -0,0 +1,6 @@
+-- Удержания живут минуты, поэтому существующие удержания без токена просто снимаются
+DELETE FROM slot_holds;
+
+ALTER TABLE slot_holds ADD COLUMN IF NOT EXISTS token VARCHAR(36) NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_slot_holds_token ON slot_holds(token);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_specializations_parent_id;
+
+ALTER TABLE specializations DROP COLUMN IF EXISTS parent_id;
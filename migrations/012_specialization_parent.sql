@@ -0,0 +1,4 @@
+ALTER TABLE specializations
+    ADD COLUMN IF NOT EXISTS parent_id BIGINT REFERENCES specializations(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_specializations_parent_id ON specializations(parent_id);
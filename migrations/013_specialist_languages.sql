@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS specialist_languages (
+    specialist_id BIGINT NOT NULL REFERENCES specialists(id) ON DELETE CASCADE,
+    language_code VARCHAR(8) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (specialist_id, language_code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_specialist_languages_code ON specialist_languages(language_code);
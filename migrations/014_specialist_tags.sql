@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    usage_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tags_name_pattern ON tags(name varchar_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_tags_usage_count ON tags(usage_count DESC);
+
+CREATE TABLE IF NOT EXISTS specialist_tags (
+    specialist_id BIGINT NOT NULL REFERENCES specialists(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (specialist_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_specialist_tags_tag_id ON specialist_tags(tag_id);
@@ -0,0 +1,11 @@
+-- Изображение загружается до создания отзыва (review_id IS NULL) и привязывается к нему при создании
+CREATE TABLE IF NOT EXISTS review_media (
+    id BIGSERIAL PRIMARY KEY,
+    review_id BIGINT REFERENCES reviews(id) ON DELETE CASCADE,
+    uploaded_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_media_review_id ON review_media(review_id);
+CREATE INDEX IF NOT EXISTS idx_review_media_uploaded_by ON review_media(uploaded_by) WHERE review_id IS NULL;
@@ -0,0 +1,10 @@
+-- Сведения о клиенте, которые видит специалист, у которого клиент записан на прием
+CREATE TABLE IF NOT EXISTS client_profiles (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    birth_date DATE,
+    gender VARCHAR(20) CHECK (gender IN ('male', 'female', 'other')),
+    city VARCHAR(100),
+    preferred_contact_method VARCHAR(20) CHECK (preferred_contact_method IN ('phone', 'whatsapp', 'video_call')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
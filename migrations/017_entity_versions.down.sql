@@ -0,0 +1,2 @@
+ALTER TABLE schedules DROP COLUMN IF EXISTS version;
+ALTER TABLE specialists DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+-- Версии строк для оптимистичной блокировки: обновление с устаревшей версией отклоняется
+ALTER TABLE specialists ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
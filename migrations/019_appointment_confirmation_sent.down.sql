@@ -0,0 +1 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS confirmation_sent_at;
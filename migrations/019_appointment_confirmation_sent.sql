@@ -0,0 +1,2 @@
+-- Момент отправки клиенту уведомления о подтверждении записи; повторное подтверждение уведомление не отправляет
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS confirmation_sent_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,8 @@
+-- Приглашения пользователей, созданных администратором без пароля.
+-- Хранится только хеш токена; у пользователя одно действующее приглашение, повторное приглашение заменяет старое
+CREATE TABLE IF NOT EXISTS user_invites (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS reminders,
+    DROP COLUMN IF EXISTS confirmations,
+    DROP COLUMN IF EXISTS chat,
+    DROP COLUMN IF EXISTS marketing,
+    DROP COLUMN IF EXISTS email_enabled,
+    DROP COLUMN IF EXISTS sms_enabled;
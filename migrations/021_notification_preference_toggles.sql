@@ -0,0 +1,8 @@
+-- Группы уведомлений и разрешенные каналы: транзакционные уведомления включены по умолчанию, рекламные выключены
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS reminders BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS confirmations BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS chat BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS marketing BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS sms_enabled BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,2 @@
+-- Время последней активности пользователя в WebSocket-соединении
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE;
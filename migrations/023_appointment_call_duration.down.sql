@@ -0,0 +1 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS call_duration_seconds;
@@ -0,0 +1,2 @@
+-- Суммарная длительность звонков по записи в секундах
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS call_duration_seconds INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Выравнивание начала слотов расписания: кратность в минутах от начала часа и сдвиг в минутах
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS slot_alignment INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS slot_offset INTEGER NOT NULL DEFAULT 0;
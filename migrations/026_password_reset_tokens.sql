@@ -0,0 +1,7 @@
+-- Токены восстановления пароля. У пользователя один действующий токен, повторный запрос заменяет старый
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token UUID NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
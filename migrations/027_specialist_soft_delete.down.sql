@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_specialists_not_deleted;
+ALTER TABLE specialists DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+-- Мягкое удаление специалистов: профиль скрывается из каталога, записи и отзывы сохраняются
+ALTER TABLE specialists ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_specialists_not_deleted ON specialists(id) WHERE deleted_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE specialists DROP COLUMN IF EXISTS accepting_clients;
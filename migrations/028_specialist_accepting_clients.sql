@@ -0,0 +1,2 @@
+-- Специалист может закрыть запись для новых клиентов; клиенты, у которых уже были записи, записываются как раньше
+ALTER TABLE specialists ADD COLUMN IF NOT EXISTS accepting_clients BOOLEAN NOT NULL DEFAULT TRUE;
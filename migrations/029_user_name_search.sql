@@ -0,0 +1,7 @@
+-- Поиск записей по ФИО клиента и специалиста: триграммный индекс ускоряет ILIKE с подстрокой.
+-- Выражение должно совпадать с userFullNameExpr в appointment_postgres.go, иначе индекс не используется.
+-- Регистронезависимость для кириллицы требует базы с UTF-8 локалью (LC_CTYPE не C)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_full_name_trgm ON users
+    USING gin ((last_name || ' ' || first_name || ' ' || COALESCE(middle_name, '')) gin_trgm_ops);
@@ -0,0 +1,10 @@
+-- Запросы отзыва после завершенных записей. Одна строка на запись: повторно запрос не планируется и не отправляется
+CREATE TABLE IF NOT EXISTS review_requests (
+    appointment_id BIGINT PRIMARY KEY REFERENCES appointments(id) ON DELETE CASCADE,
+    client_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    send_after TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_requests_due ON review_requests(send_after) WHERE sent_at IS NULL;
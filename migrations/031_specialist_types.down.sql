@@ -0,0 +1,7 @@
+ALTER TABLE specializations DROP CONSTRAINT IF EXISTS specializations_type_fkey;
+ALTER TABLE specializations ADD CONSTRAINT specializations_type_check CHECK (type IN ('lawyer', 'psychologist'));
+
+ALTER TABLE specialists DROP CONSTRAINT IF EXISTS specialists_type_fkey;
+ALTER TABLE specialists ADD CONSTRAINT specialists_type_check CHECK (type IN ('lawyer', 'psychologist'));
+
+DROP TABLE IF EXISTS specialist_types;
@@ -0,0 +1,17 @@
+-- Справочник типов специалистов вместо жестко заданного списка в CHECK-ограничениях
+CREATE TABLE IF NOT EXISTS specialist_types (
+    code TEXT PRIMARY KEY,
+    label TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+INSERT INTO specialist_types (code, label) VALUES
+    ('lawyer', 'Юрист'),
+    ('psychologist', 'Психолог')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE specialists DROP CONSTRAINT IF EXISTS specialists_type_check;
+ALTER TABLE specialists ADD CONSTRAINT specialists_type_fkey FOREIGN KEY (type) REFERENCES specialist_types(code);
+
+ALTER TABLE specializations DROP CONSTRAINT IF EXISTS specializations_type_check;
+ALTER TABLE specializations ADD CONSTRAINT specializations_type_fkey FOREIGN KEY (type) REFERENCES specialist_types(code);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_specialists_experience_years;
@@ -0,0 +1,2 @@
+-- Индекс для фильтра специалистов по минимальному стажу
+CREATE INDEX IF NOT EXISTS idx_specialists_experience_years ON specialists(experience_years);
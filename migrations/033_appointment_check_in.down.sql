@@ -0,0 +1,9 @@
+UPDATE appointments SET status = 'paid' WHERE status = 'in_progress';
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments ADD CONSTRAINT appointments_status_check
+    CHECK (status IN ('pending', 'paid', 'completed', 'cancelled'));
+
+ALTER TABLE appointments DROP COLUMN IF EXISTS actual_end_at;
+ALTER TABLE appointments DROP COLUMN IF EXISTS actual_start_at;
+ALTER TABLE appointments DROP COLUMN IF EXISTS checked_in_at;
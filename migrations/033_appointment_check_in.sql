@@ -0,0 +1,9 @@
+-- Статус in_progress: консультация начата специалистом после отметки клиента
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments ADD CONSTRAINT appointments_status_check
+    CHECK (status IN ('pending', 'paid', 'in_progress', 'completed', 'cancelled'));
+
+-- Время отметки клиента и фактические начало и окончание консультации
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS actual_start_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS actual_end_at TIMESTAMP WITH TIME ZONE;
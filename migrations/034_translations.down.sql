@@ -0,0 +1,2 @@
+ALTER TABLE specialists DROP COLUMN IF EXISTS translations;
+ALTER TABLE specializations DROP COLUMN IF EXISTS translations;
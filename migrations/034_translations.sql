@@ -0,0 +1,4 @@
+-- Переводы контента на другие языки: {"en": {"name": "...", "description": "..."}, "kk": {...}}.
+-- Основные поля хранятся на русском
+ALTER TABLE specializations ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE specialists ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,2 @@
+-- Аватар пользователя; показывается рядом с отзывами автора
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url TEXT;
@@ -0,0 +1,11 @@
+-- Реакции эмодзи на сообщения чата; один пользователь ставит каждый эмодзи на сообщение не больше одного раза.
+-- Эмодзи хранится в VARCHAR, а не в CHAR: последовательности с модификаторами тона и ZWJ длиннее 4 символов,
+-- а CHAR дополняет значение пробелами. Таблицы чата создаются вне этих миграций, поэтому внешнего ключа
+-- на chat_messages нет
+CREATE TABLE IF NOT EXISTS chat_message_reactions (
+    message_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chat_message_reactions_unique UNIQUE (message_id, user_id, emoji)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS call_feedback;
+DROP TABLE IF EXISTS call_sessions;
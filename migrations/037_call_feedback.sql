@@ -0,0 +1,25 @@
+-- Завершенные звонки: сессия сигналинга сохраняется при завершении, чтобы участники могли оценить качество связи.
+-- ID сессии генерирует клиент, specialist_user_id — пользователь специалиста, а не профиль
+CREATE TABLE IF NOT EXISTS call_sessions (
+    id VARCHAR(128) PRIMARY KEY,
+    client_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    specialist_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    appointment_id BIGINT REFERENCES appointments(id) ON DELETE SET NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_seconds INTEGER NOT NULL DEFAULT 0
+);
+
+-- Оценка качества звонка участником: одна на участника и звонок
+CREATE TABLE IF NOT EXISTS call_feedback (
+    id BIGSERIAL PRIMARY KEY,
+    call_session_id VARCHAR(128) NOT NULL REFERENCES call_sessions(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quality SMALLINT NOT NULL CHECK (quality BETWEEN 1 AND 5),
+    issues TEXT[] NOT NULL DEFAULT '{}',
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (call_session_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_call_feedback_created_at ON call_feedback(created_at);
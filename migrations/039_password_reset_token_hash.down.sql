@@ -0,0 +1,3 @@
+DELETE FROM password_reset_tokens;
+ALTER TABLE password_reset_tokens DROP COLUMN IF EXISTS token_hash;
+ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS token UUID NOT NULL UNIQUE;
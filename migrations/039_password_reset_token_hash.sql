@@ -0,0 +1,5 @@
+-- Токены восстановления пароля хранятся как sha256-хеш, как и токены приглашений.
+-- Выданные ранее токены удаляются: пользователю достаточно запросить ссылку заново
+DELETE FROM password_reset_tokens;
+ALTER TABLE password_reset_tokens DROP COLUMN IF EXISTS token;
+ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS token_hash VARCHAR(64) NOT NULL UNIQUE;
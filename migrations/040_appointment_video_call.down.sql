@@ -0,0 +1,5 @@
+UPDATE appointments SET communication_method = 'phone' WHERE communication_method = 'video_call';
+
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_communication_method_check;
+ALTER TABLE appointments ADD CONSTRAINT appointments_communication_method_check
+    CHECK (communication_method IN ('phone', 'whatsapp'));
@@ -0,0 +1,4 @@
+-- Способ связи video_call: по таким записям участники звонят друг другу через сигналинг
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_communication_method_check;
+ALTER TABLE appointments ADD CONSTRAINT appointments_communication_method_check
+    CHECK (communication_method IN ('phone', 'whatsapp', 'video_call'));
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_call_sessions_specialist_user_id;
+
+-- Раньше сохранялись только принятые звонки
+DELETE FROM call_sessions WHERE answered = FALSE;
+
+ALTER TABLE call_sessions DROP COLUMN IF EXISTS answered;
+ALTER TABLE call_sessions DROP COLUMN IF EXISTS caller_id;
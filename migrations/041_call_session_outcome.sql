@@ -0,0 +1,7 @@
+-- Неотвеченные звонки тоже сохраняются, чтобы считать долю звонков клиентов, принятых специалистом.
+-- caller_id — кто позвонил; у звонков, сохраненных раньше, он неизвестен, и все они были приняты
+ALTER TABLE call_sessions ADD COLUMN IF NOT EXISTS caller_id BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE call_sessions ADD COLUMN IF NOT EXISTS answered BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE call_sessions ALTER COLUMN answered DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_call_sessions_specialist_user_id ON call_sessions(specialist_user_id);
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -10,10 +11,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
+// Файлы отката лежат рядом с миграциями: 003_name.sql откатывается файлом 003_name.down.sql
+const downMigrationSuffix = ".down.sql"
+
 type MigrationRecord struct {
 	Version   string
 	Name      string
@@ -60,7 +65,7 @@ func RunMigrations(db *pgxpool.Pool, migrationsDir string, logger *zap.Logger) e
 
 	var migrationFiles []string
 	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") && !strings.HasSuffix(file.Name(), downMigrationSuffix) {
 			migrationFiles = append(migrationFiles, file.Name())
 		}
 	}
@@ -123,3 +128,54 @@ func RunMigrations(db *pgxpool.Pool, migrationsDir string, logger *zap.Logger) e
 
 	return nil
 }
+
+// RollbackMigration откатывает последнюю выполненную миграцию с помощью ее .down.sql файла.
+// Работает с той же таблицей migrations, что и RunMigrations; почему не golang-migrate — см. README
+func RollbackMigration(db *pgxpool.Pool, migrationsDir string, logger *zap.Logger) error {
+	ctx := context.Background()
+
+	var record MigrationRecord
+	err := db.QueryRow(ctx,
+		"SELECT version, name, applied_at FROM migrations ORDER BY version DESC LIMIT 1",
+	).Scan(&record.Version, &record.Name, &record.AppliedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		logger.Info("нет выполненных миграций для отката")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка при получении последней миграции: %w", err)
+	}
+
+	file := record.Version + "_" + record.Name + downMigrationSuffix
+	content, err := os.ReadFile(filepath.Join(migrationsDir, file))
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении файла отката %s: %w", file, err)
+	}
+
+	logger.Info("откат миграции", zap.String("version", record.Version), zap.String("name", record.Name))
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, string(content))
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("ошибка при откате миграции %s: %w", file, err)
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM migrations WHERE version = $1", record.Version)
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("ошибка при удалении информации о миграции: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+
+	logger.Info("миграция успешно откачена", zap.String("version", record.Version), zap.String("name", record.Name))
+
+	return nil
+}
@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
 
 	"laps/config"
 )
 
-func NewPostgresDB(cfg config.PostgresConfig) (*pgxpool.Pool, error) {
+// poolStatsInterval как часто LogPoolStats пишет в лог состояние пула
+const poolStatsInterval = time.Minute
+
+func NewPostgresDB(cfg config.PostgresConfig, logger *zap.Logger) (*pgxpool.Pool, error) {
 	connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
 		cfg.Username,
 		cfg.Password,
@@ -26,9 +31,10 @@ func NewPostgresDB(cfg config.PostgresConfig) (*pgxpool.Pool, error) {
 	}
 
 	poolConfig.MaxConns = int32(cfg.MaxConnections)
-	poolConfig.MinConns = int32(cfg.MaxIdleConnections)
+	poolConfig.MinConns = int32(cfg.MinConnections)
 	poolConfig.MaxConnLifetime = cfg.MaxLifetime
-	poolConfig.MaxConnIdleTime = 15 * time.Minute
+	poolConfig.MaxConnIdleTime = cfg.MaxIdleTime
+	poolConfig.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTrimSQLInSpanName())
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
@@ -40,6 +46,33 @@ func NewPostgresDB(cfg config.PostgresConfig) (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
 	}
 
-	fmt.Println("Успешное подключение к базе данных")
+	// Значения берутся из итоговой конфигурации пула: pgxpool подставляет свои значения вместо нулевых
+	logger.Info("Успешное подключение к базе данных",
+		zap.Int32("maxConns", poolConfig.MaxConns),
+		zap.Int32("minConns", poolConfig.MinConns),
+		zap.Duration("maxConnLifetime", poolConfig.MaxConnLifetime),
+		zap.Duration("maxConnIdleTime", poolConfig.MaxConnIdleTime))
+
 	return pool, nil
 }
+
+// LogPoolStats раз в минуту пишет в лог занятые, простаивающие и все подключения пула, пока не отменен ctx
+func LogPoolStats(ctx context.Context, pool *pgxpool.Pool, logger *zap.Logger) {
+	ticker := time.NewTicker(poolStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stat := pool.Stat()
+			logger.Info("Состояние пула подключений к БД",
+				zap.Int32("acquired", stat.AcquiredConns()),
+				zap.Int32("idle", stat.IdleConns()),
+				zap.Int32("total", stat.TotalConns()),
+				zap.Int32("max", stat.MaxConns()),
+				zap.Int64("emptyAcquire", stat.EmptyAcquireCount()))
+		}
+	}
+}
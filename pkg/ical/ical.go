@@ -0,0 +1,346 @@
+// Package ical разбирает календари iCalendar (RFC 5545) в интервалы занятости.
+// Поддерживается подмножество, нужное для внешних календарей специалистов:
+// VEVENT с DTSTART/DTEND/DURATION, TZID, событиями на весь день, RRULE
+// (DAILY, WEEKLY с BYDAY, MONTHLY, YEARLY; INTERVAL, COUNT, UNTIL), EXDATE и RECURRENCE-ID.
+// Write формирует календарь из готовых событий, например для добавления записи в календарь клиента.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ограничение на число разворачиваемых повторений одного события
+const maxOccurrences = 5000
+
+// Event отдельный интервал занятости (повторяющиеся события уже развернуты)
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type rawEvent struct {
+	uid          string
+	summary      string
+	start        time.Time
+	end          time.Time
+	allDay       bool
+	duration     time.Duration
+	hasEnd       bool
+	hasDuration  bool
+	rrule        string
+	exdates      []time.Time
+	recurrenceID *time.Time
+	cancelled    bool
+	transparent  bool
+}
+
+// Parse читает календарь и возвращает занятые интервалы, пересекающие [from, to).
+// Отмененные и прозрачные (TRANSP:TRANSPARENT) события пропускаются
+func Parse(r io.Reader, from, to time.Time) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []*rawEvent
+		current  *rawEvent
+		depth    int
+		calendar bool
+	)
+
+	for _, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			continue
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			switch strings.ToUpper(prop.value) {
+			case "VCALENDAR":
+				calendar = true
+			case "VEVENT":
+				current = &rawEvent{}
+			default:
+				if current != nil {
+					depth++
+				}
+			}
+			continue
+		case "END":
+			switch strings.ToUpper(prop.value) {
+			case "VEVENT":
+				if current != nil && !current.start.IsZero() {
+					events = append(events, current)
+				}
+				current = nil
+				depth = 0
+			default:
+				if current != nil && depth > 0 {
+					depth--
+				}
+			}
+			continue
+		}
+
+		// Свойства вложенных компонентов (например, VALARM) к событию не относятся
+		if current == nil || depth > 0 {
+			continue
+		}
+
+		if err := current.apply(prop); err != nil {
+			return nil, fmt.Errorf("событие %q: %w", current.uid, err)
+		}
+	}
+
+	if !calendar {
+		return nil, errors.New("данные не являются календарем iCalendar")
+	}
+
+	// RECURRENCE-ID заменяет одно повторение основного события
+	overridden := make(map[string][]time.Time)
+	for _, e := range events {
+		if e.recurrenceID != nil {
+			overridden[e.uid] = append(overridden[e.uid], *e.recurrenceID)
+		}
+	}
+
+	var result []Event
+	for _, e := range events {
+		if e.recurrenceID == nil {
+			e.exdates = append(e.exdates, overridden[e.uid]...)
+		}
+		if e.cancelled || e.transparent {
+			continue
+		}
+
+		occurrences, err := e.expand(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("событие %q: %w", e.uid, err)
+		}
+
+		length := e.length()
+		for _, start := range occurrences {
+			end := start.Add(length)
+			if end.After(from) && start.Before(to) {
+				result = append(result, Event{UID: e.uid, Summary: e.summary, Start: start, End: end})
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+
+	return result, nil
+}
+
+// unfold склеивает перенесенные строки (продолжение начинается с пробела или табуляции)
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения календаря: %w", err)
+	}
+
+	return lines, nil
+}
+
+func parseProperty(line string) (property, error) {
+	colon := -1
+	inQuotes := false
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, errors.New("нет значения свойства")
+	}
+
+	head := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(head[0]),
+		params: make(map[string]string, len(head)-1),
+		value:  line[colon+1:],
+	}
+	for _, param := range head[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+
+	return prop, nil
+}
+
+func (e *rawEvent) apply(prop property) error {
+	switch prop.name {
+	case "UID":
+		e.uid = prop.value
+	case "SUMMARY":
+		e.summary = prop.value
+	case "DTSTART":
+		start, allDay, err := parseTime(prop)
+		if err != nil {
+			return fmt.Errorf("DTSTART: %w", err)
+		}
+		e.start, e.allDay = start, allDay
+	case "DTEND":
+		end, _, err := parseTime(prop)
+		if err != nil {
+			return fmt.Errorf("DTEND: %w", err)
+		}
+		e.end, e.hasEnd = end, true
+	case "DURATION":
+		duration, err := parseDuration(prop.value)
+		if err != nil {
+			return fmt.Errorf("DURATION: %w", err)
+		}
+		e.duration, e.hasDuration = duration, true
+	case "RRULE":
+		e.rrule = prop.value
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			exdate, _, err := parseTime(property{name: prop.name, params: prop.params, value: value})
+			if err != nil {
+				return fmt.Errorf("EXDATE: %w", err)
+			}
+			e.exdates = append(e.exdates, exdate)
+		}
+	case "RECURRENCE-ID":
+		recurrenceID, _, err := parseTime(prop)
+		if err != nil {
+			return fmt.Errorf("RECURRENCE-ID: %w", err)
+		}
+		e.recurrenceID = &recurrenceID
+	case "STATUS":
+		e.cancelled = strings.EqualFold(prop.value, "CANCELLED")
+	case "TRANSP":
+		e.transparent = strings.EqualFold(prop.value, "TRANSPARENT")
+	}
+
+	return nil
+}
+
+// length длительность события; без DTEND и DURATION событие на весь день длится сутки
+func (e *rawEvent) length() time.Duration {
+	switch {
+	case e.hasEnd && e.end.After(e.start):
+		return e.end.Sub(e.start)
+	case e.hasDuration && e.duration > 0:
+		return e.duration
+	case e.allDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+func parseTime(prop property) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+
+	if prop.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.UTC)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	location := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t, false, err
+}
+
+// parseDuration разбирает длительность вида [+-]P[nW][nD][T[nH][nM][nS]]
+func parseDuration(value string) (time.Duration, error) {
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign, value = -1, value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("неверная длительность %q", value)
+	}
+	value = value[1:]
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("неверная длительность %q", value)
+		}
+		number = ""
+
+		switch {
+		case r == 'W':
+			total += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D':
+			total += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			total += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("неверная длительность %q", value)
+		}
+	}
+
+	return sign * total, nil
+}
@@ -0,0 +1,164 @@
+package ical
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func utc(value string) time.Time {
+	t, err := time.Parse("20060102T150405Z", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseFeed(t *testing.T) {
+	f, err := os.Open("testdata/feed.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	events, err := Parse(f, utc("20260101T000000Z"), utc("20260201T000000Z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Event{
+		{UID: "moscow@test", Summary: "Встреча в Москве", Start: utc("20260105T070000Z"), End: utc("20260105T080000Z")},
+		{UID: "weekly@test", Summary: "Planning", Start: utc("20260105T090000Z"), End: utc("20260105T093000Z")},
+		// Повторение 7 января исключено через EXDATE
+		{UID: "allday@test", Summary: "Conference", Start: utc("20260110T000000Z"), End: utc("20260111T000000Z")},
+		// Повторение 12 января перенесено через RECURRENCE-ID
+		{UID: "weekly@test", Summary: "Planning (moved)", Start: utc("20260112T140000Z"), End: utc("20260112T150000Z")},
+		{UID: "weekly@test", Summary: "Planning", Start: utc("20260114T090000Z"), End: utc("20260114T093000Z")},
+		// DURATION из VALARM не меняет длительность события
+		{UID: "alarm@test", Summary: "Court hearing", Start: utc("20260115T120000Z"), End: utc("20260115T130000Z")},
+		{UID: "weekly@test", Summary: "Planning", Start: utc("20260119T090000Z"), End: utc("20260119T093000Z")},
+		{UID: "weekly@test", Summary: "Planning", Start: utc("20260121T090000Z"), End: utc("20260121T093000Z")},
+	}
+
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i := range want {
+		got := events[i]
+		if got.UID != want[i].UID || got.Summary != want[i].Summary || !got.Start.Equal(want[i].Start) || !got.End.Equal(want[i].End) {
+			t.Errorf("event %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  string
+		from   string
+		to     string
+		starts []string
+	}{
+		{
+			name:   "daily until a date includes that whole day",
+			event:  "DTSTART:20260105T090000Z\nDURATION:PT1H\nRRULE:FREQ=DAILY;UNTIL=20260107",
+			from:   "20260101T000000Z",
+			to:     "20260201T000000Z",
+			starts: []string{"20260105T090000Z", "20260106T090000Z", "20260107T090000Z"},
+		},
+		{
+			name:   "long-running daily rule is clipped to the window",
+			event:  "DTSTART:20200101T090000Z\nDURATION:PT1H\nRRULE:FREQ=DAILY",
+			from:   "20260110T000000Z",
+			to:     "20260112T000000Z",
+			starts: []string{"20260110T090000Z", "20260111T090000Z"},
+		},
+		{
+			name:   "occurrence in progress at the window start",
+			event:  "DTSTART:20260101T230000Z\nDURATION:PT2H\nRRULE:FREQ=DAILY;COUNT=3",
+			from:   "20260103T000000Z",
+			to:     "20260104T000000Z",
+			starts: []string{"20260102T230000Z", "20260103T230000Z"},
+		},
+		{
+			name:   "every other week",
+			event:  "DTSTART:20260105T090000Z\nDURATION:PT1H\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			from:   "20260101T000000Z",
+			to:     "20260301T000000Z",
+			starts: []string{"20260105T090000Z", "20260119T090000Z", "20260202T090000Z"},
+		},
+		{
+			name:   "monthly on the 31st skips short months",
+			event:  "DTSTART:20260131T090000Z\nDURATION:PT1H\nRRULE:FREQ=MONTHLY;COUNT=3",
+			from:   "20260101T000000Z",
+			to:     "20270101T000000Z",
+			starts: []string{"20260131T090000Z", "20260331T090000Z", "20260531T090000Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:rule@test\n" + tt.event + "\nEND:VEVENT\nEND:VCALENDAR\n"
+			events, err := Parse(strings.NewReader(feed), utc(tt.from), utc(tt.to))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var starts []string
+			for _, event := range events {
+				starts = append(starts, event.Start.UTC().Format("20060102T150405Z"))
+			}
+			if strings.Join(starts, ",") != strings.Join(tt.starts, ",") {
+				t.Errorf("starts = %v, want %v", starts, tt.starts)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidFeeds(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+	}{
+		{"not a calendar", "<html><body>Not found</body></html>"},
+		{"unsupported frequency", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260105T090000Z\nRRULE:FREQ=HOURLY\nEND:VEVENT\nEND:VCALENDAR\n"},
+		{"broken start", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\nEND:VCALENDAR\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.feed), utc("20260101T000000Z"), utc("20260201T000000Z")); err == nil {
+				t.Error("Parse() error = nil")
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	event := Event{
+		UID:     "appointment-1@laps",
+		Summary: "Консультация юриста; кабинет №3, " + strings.Repeat("длинное описание ", 5),
+		Start:   utc("20260105T090000Z"),
+		End:     utc("20260105T100000Z"),
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, event); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line is %d octets long: %q", len(line), line)
+		}
+	}
+
+	events, err := Parse(&buf, utc("20260101T000000Z"), utc("20260201T000000Z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].UID != event.UID || !events[0].Start.Equal(event.Start) || !events[0].End.Equal(event.End) {
+		t.Errorf("events = %+v, want %+v", events, event)
+	}
+}
@@ -0,0 +1,207 @@
+package ical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type recurrence struct {
+	freq     string
+	interval int
+	count    int
+	until    *time.Time
+	byDay    []time.Weekday
+}
+
+func parseRRule(value string) (*recurrence, error) {
+	rule := &recurrence{interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("неверный INTERVAL %q", val)
+			}
+			rule.interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("неверный COUNT %q", val)
+			}
+			rule.count = count
+		case "UNTIL":
+			until, _, err := parseTime(property{value: val, params: map[string]string{}})
+			if err != nil {
+				return nil, fmt.Errorf("неверный UNTIL %q", val)
+			}
+			// Дата без времени включает весь день
+			if len(val) == 8 {
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			rule.until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				day = strings.ToUpper(strings.TrimLeft(day, "+-0123456789"))
+				if weekday, ok := weekdays[day]; ok {
+					rule.byDay = append(rule.byDay, weekday)
+				}
+			}
+		}
+	}
+
+	switch rule.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+		return rule, nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемая частота повторения %q", rule.freq)
+	}
+}
+
+// expand возвращает начала повторений события, начинающихся раньше to.
+// Повторения задолго до from пропускаются без перебора, если в правиле нет COUNT
+func (e *rawEvent) expand(from, to time.Time) ([]time.Time, error) {
+	if e.rrule == "" || e.recurrenceID != nil {
+		return []time.Time{e.start}, nil
+	}
+
+	rule, err := parseRRule(e.rrule)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[int64]bool, len(e.exdates))
+	for _, exdate := range e.exdates {
+		excluded[exdate.Unix()] = true
+	}
+
+	var (
+		occurrences []time.Time
+		generated   int
+	)
+
+	// emit учитывает повторение в COUNT даже если оно исключено через EXDATE
+	emit := func(start time.Time) bool {
+		if start.Before(e.start) {
+			return true
+		}
+		if rule.until != nil && start.After(*rule.until) {
+			return false
+		}
+		if !start.Before(to) {
+			return false
+		}
+		if rule.count > 0 && generated >= rule.count {
+			return false
+		}
+		generated++
+		if !excluded[start.Unix()] {
+			occurrences = append(occurrences, start)
+		}
+		return generated < maxOccurrences
+	}
+
+	first := 0
+	if rule.count == 0 {
+		first = rule.skipPeriods(e.start, from.Add(-e.length()))
+	}
+
+	for i := first; i < first+maxOccurrences; i++ {
+		var candidates []time.Time
+
+		switch rule.freq {
+		case "DAILY":
+			candidates = []time.Time{e.start.AddDate(0, 0, i*rule.interval)}
+		case "WEEKLY":
+			candidates = weeklyCandidates(e.start, i*rule.interval, rule.byDay)
+		case "MONTHLY":
+			next := e.start.AddDate(0, i*rule.interval, 0)
+			// 31-е число пропускается в коротких месяцах, как того требует RFC 5545
+			if next.Day() == e.start.Day() {
+				candidates = []time.Time{next}
+			}
+		case "YEARLY":
+			next := e.start.AddDate(i*rule.interval, 0, 0)
+			if next.Day() == e.start.Day() {
+				candidates = []time.Time{next}
+			}
+		}
+
+		for _, candidate := range candidates {
+			if !emit(candidate) {
+				return occurrences, nil
+			}
+		}
+	}
+
+	return occurrences, nil
+}
+
+// skipPeriods возвращает номер первого периода правила, который может пересечь from
+func (r *recurrence) skipPeriods(start, from time.Time) int {
+	if !from.After(start) {
+		return 0
+	}
+
+	var periods int
+	switch r.freq {
+	case "DAILY":
+		periods = int(from.Sub(start).Hours() / 24)
+	case "WEEKLY":
+		periods = int(from.Sub(start).Hours() / (24 * 7))
+	case "MONTHLY":
+		periods = (from.Year()-start.Year())*12 + int(from.Month()) - int(start.Month())
+	case "YEARLY":
+		periods = from.Year() - start.Year()
+	}
+
+	// Запас в один интервал покрывает погрешность перехода на летнее время и границы месяцев
+	skip := periods/r.interval - 1
+	if skip < 0 {
+		return 0
+	}
+	return skip
+}
+
+// weeklyCandidates возвращает повторения в неделе, отстоящей на weeks недель от начала события.
+// Неделя начинается с понедельника (WKST=MO)
+func weeklyCandidates(start time.Time, weeks int, byDay []time.Weekday) []time.Time {
+	if len(byDay) == 0 {
+		return []time.Time{start.AddDate(0, 0, 7*weeks)}
+	}
+
+	offset := (int(start.Weekday()) + 6) % 7
+	weekStart := start.AddDate(0, 0, 7*weeks-offset)
+
+	candidates := make([]time.Time, 0, len(byDay))
+	for day := 0; day < 7; day++ {
+		candidate := weekStart.AddDate(0, 0, day)
+		for _, weekday := range byDay {
+			if candidate.Weekday() == weekday {
+				candidates = append(candidates, candidate)
+				break
+			}
+		}
+	}
+
+	return candidates
+}
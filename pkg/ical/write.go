@@ -0,0 +1,62 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// Максимальная длина строки календаря в октетах без учета CRLF (RFC 5545, раздел 3.1)
+const maxLineOctets = 75
+
+// Write записывает события в формате iCalendar. Время событий выводится в UTC
+func Write(w io.Writer, events ...Event) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:-//laps//appointments//RU")
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	for _, event := range events {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+escapeText(event.UID))
+		writeLine(bw, "DTSTAMP:"+stamp)
+		writeLine(bw, "DTSTART:"+event.Start.UTC().Format("20060102T150405Z"))
+		writeLine(bw, "DTEND:"+event.End.UTC().Format("20060102T150405Z"))
+		writeLine(bw, "SUMMARY:"+escapeText(event.Summary))
+		writeLine(bw, "END:VEVENT")
+	}
+	writeLine(bw, "END:VCALENDAR")
+
+	return bw.Flush()
+}
+
+// writeLine пишет строку, перенося ее по границе символов так, чтобы часть не превышала 75 октетов
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Пробел в начале продолжения тоже занимает октет
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// escapeText экранирует значение типа TEXT
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,82 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"laps/config"
+	"laps/internal/cache"
+	"laps/internal/repository"
+	"laps/internal/service"
+	"laps/internal/transport/rest"
+)
+
+// Запрос профиля специалиста дает одну трассу: серверный span otelgin, внутри него span сервиса,
+// внутри — span репозитория. База не нужна: span репозитория открывается до запроса к ней,
+// поэтому пул указывает на закрытый порт и запрос завершается ошибкой
+func TestSpecialistRequestSpanHierarchy(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	pool, err := pgxpool.New(context.Background(), "postgres://laps@127.0.0.1:1/laps?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	specialists := service.NewSpecialistService(repository.NewSpecialistRepository(pool), nil, nil, nil, nil, nil, nil,
+		cache.NewMemoryCache(10), time.Minute, zap.NewNop())
+	handler := rest.NewHandler(&service.Services{Specialist: specialists}, zap.NewNop(), &config.Config{Name: "laps-test"}, nil, nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.InitRoutes(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/specialists/7", nil))
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	server, ok := spans["/api/v1/specialists/:id"]
+	if !ok {
+		t.Fatalf("spans = %v, want a server span for the route", spanNames(exporter.GetSpans()))
+	}
+	if server.SpanKind != trace.SpanKindServer || server.Parent.IsValid() {
+		t.Errorf("server span kind = %v, parent = %v; want a root server span", server.SpanKind, server.Parent.SpanID())
+	}
+
+	parent := server
+	for _, name := range []string{"SpecialistService.GetByID", "SpecialistRepo.GetByID"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("spans = %v, want %s", spanNames(exporter.GetSpans()), name)
+		}
+		if span.SpanContext.TraceID() != server.SpanContext.TraceID() || span.Parent.SpanID() != parent.SpanContext.SpanID() {
+			t.Errorf("%s parent = %s, want %s (%s)", name, span.Parent.SpanID(), parent.SpanContext.SpanID(), parent.Name)
+		}
+		parent = span
+	}
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name
+	}
+	return names
+}